@@ -0,0 +1,312 @@
+package datastructures
+
+import (
+	"math/bits"
+	"src/internal/models"
+)
+
+// TreeKey is any numeric type an AVLTree can be keyed by, such as a rating, BPM or duration
+type TreeKey interface {
+	~int | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+// TreeBalance describes the shape of a self-balancing tree
+type TreeBalance struct {
+	Nodes int `json:"nodes"`
+	// Height is the number of nodes on the longest root-to-leaf path, 0 for an empty tree
+	Height int `json:"height"`
+	// OptimalHeight is the height of a perfectly balanced tree with the same number of nodes
+	OptimalHeight int `json:"optimal_height"`
+	// MaxBalanceFactor is the largest height difference between the subtrees of any node
+	MaxBalanceFactor int `json:"max_balance_factor"`
+	// Balanced reports whether every node satisfies the AVL property (factor at most 1)
+	Balanced bool `json:"balanced"`
+}
+
+// optimalHeight returns the height of a perfectly balanced tree of n nodes, ceil(log2(n+1))
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func optimalHeight(n int) int {
+	return bits.Len(uint(n))
+}
+
+// avlNode holds the songs sharing one key
+type avlNode[K TreeKey] struct {
+	key    K
+	songs  []*models.Song
+	left   *avlNode[K]
+	right  *avlNode[K]
+	height int
+}
+
+// AVLTree indexes songs by a numeric key in an AVL tree: every node keeps the heights of its
+// subtrees within one of each other, rotating after inserts and removals, so lookups stay
+// O(log n) even when keys arrive in sorted order. Songs sharing a key share a node
+// Time Complexity: O(log n) for insert, remove and lookup where n is the number of distinct keys
+// Space Complexity: O(n + s) where s is the number of songs
+type AVLTree[K TreeKey] struct {
+	root  *avlNode[K]
+	nodes int
+	songs int
+}
+
+// NewAVLTree creates an empty AVL tree
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewAVLTree[K TreeKey]() *AVLTree[K] {
+	return &AVLTree[K]{}
+}
+
+// height returns the height of a subtree, 0 when it is empty
+func (t *AVLTree[K]) height(node *avlNode[K]) int {
+	if node == nil {
+		return 0
+	}
+	return node.height
+}
+
+// balanceFactor returns the height of the left subtree minus that of the right one
+func (t *AVLTree[K]) balanceFactor(node *avlNode[K]) int {
+	return t.height(node.left) - t.height(node.right)
+}
+
+// updateHeight recomputes a node's height from its children
+func (t *AVLTree[K]) updateHeight(node *avlNode[K]) {
+	node.height = 1 + max(t.height(node.left), t.height(node.right))
+}
+
+// rotateRight lifts the left child of node into its place
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (t *AVLTree[K]) rotateRight(node *avlNode[K]) *avlNode[K] {
+	pivot := node.left
+	node.left = pivot.right
+	pivot.right = node
+	t.updateHeight(node)
+	t.updateHeight(pivot)
+	return pivot
+}
+
+// rotateLeft lifts the right child of node into its place
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (t *AVLTree[K]) rotateLeft(node *avlNode[K]) *avlNode[K] {
+	pivot := node.right
+	node.right = pivot.left
+	pivot.left = node
+	t.updateHeight(node)
+	t.updateHeight(pivot)
+	return pivot
+}
+
+// rebalance restores the AVL property at node after one of its subtrees changed height,
+// using a single rotation for the left-left and right-right cases and a double one otherwise
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (t *AVLTree[K]) rebalance(node *avlNode[K]) *avlNode[K] {
+	t.updateHeight(node)
+	switch factor := t.balanceFactor(node); {
+	case factor > 1:
+		if t.balanceFactor(node.left) < 0 {
+			node.left = t.rotateLeft(node.left)
+		}
+		return t.rotateRight(node)
+	case factor < -1:
+		if t.balanceFactor(node.right) > 0 {
+			node.right = t.rotateRight(node.right)
+		}
+		return t.rotateLeft(node)
+	}
+	return node
+}
+
+// Insert files song under key
+// Time Complexity: O(log n)
+// Space Complexity: O(log n) due to recursion stack
+func (t *AVLTree[K]) Insert(key K, song *models.Song) {
+	if song == nil {
+		return
+	}
+	t.root = t.insert(t.root, key, song)
+	t.songs++
+}
+
+// insert is the recursive helper of Insert, returning the new root of the subtree
+func (t *AVLTree[K]) insert(node *avlNode[K], key K, song *models.Song) *avlNode[K] {
+	if node == nil {
+		t.nodes++
+		return &avlNode[K]{key: key, songs: []*models.Song{song}, height: 1}
+	}
+
+	switch {
+	case key < node.key:
+		node.left = t.insert(node.left, key, song)
+	case key > node.key:
+		node.right = t.insert(node.right, key, song)
+	default:
+		node.songs = append(node.songs, song)
+		return node
+	}
+	return t.rebalance(node)
+}
+
+// Remove drops the song with songID from key, deleting the node once it holds no songs
+// Returns false when no such song is filed under key
+// Time Complexity: O(log n + k) where k is the number of songs sharing key
+// Space Complexity: O(log n) due to recursion stack
+func (t *AVLTree[K]) Remove(key K, songID string) bool {
+	var removed bool
+	t.root = t.remove(t.root, key, songID, &removed)
+	if removed {
+		t.songs--
+	}
+	return removed
+}
+
+// remove is the recursive helper of Remove, returning the new root of the subtree
+func (t *AVLTree[K]) remove(node *avlNode[K], key K, songID string, removed *bool) *avlNode[K] {
+	if node == nil {
+		return nil
+	}
+
+	switch {
+	case key < node.key:
+		node.left = t.remove(node.left, key, songID, removed)
+	case key > node.key:
+		node.right = t.remove(node.right, key, songID, removed)
+	default:
+		for i, song := range node.songs {
+			if song.ID == songID {
+				// Shift rather than swap so songs sharing a key keep their insertion order
+				node.songs = append(node.songs[:i], node.songs[i+1:]...)
+				*removed = true
+				break
+			}
+		}
+		if len(node.songs) > 0 {
+			return node
+		}
+		t.nodes--
+		if node.left == nil {
+			return node.right
+		}
+		if node.right == nil {
+			return node.left
+		}
+
+		// Two children: move the inorder successor's songs here and unlink it
+		successor := node.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		node.key, node.songs = successor.key, successor.songs
+		node.right = t.removeMin(node.right)
+	}
+	return t.rebalance(node)
+}
+
+// removeMin unlinks the leftmost node of a subtree, returning the new root of the subtree
+func (t *AVLTree[K]) removeMin(node *avlNode[K]) *avlNode[K] {
+	if node.left == nil {
+		return node.right
+	}
+	node.left = t.removeMin(node.left)
+	return t.rebalance(node)
+}
+
+// Search returns the songs filed under key in insertion order
+// Time Complexity: O(log n)
+// Space Complexity: O(k) for the copied result
+func (t *AVLTree[K]) Search(key K) []*models.Song {
+	node := t.root
+	for node != nil {
+		switch {
+		case key < node.key:
+			node = node.left
+		case key > node.key:
+			node = node.right
+		default:
+			return append([]*models.Song(nil), node.songs...)
+		}
+	}
+	return []*models.Song{}
+}
+
+// Range returns the songs with min <= key <= max, lowest key first
+// Time Complexity: O(log n + r) where r is the number of songs returned
+// Space Complexity: O(r)
+func (t *AVLTree[K]) Range(min, max K) []*models.Song {
+	songs := make([]*models.Song, 0)
+	if min <= max {
+		t.collect(t.root, min, max, &songs)
+	}
+	return songs
+}
+
+// collect appends the songs of a subtree within [min, max] in key order, skipping
+// subtrees that lie entirely outside the range
+func (t *AVLTree[K]) collect(node *avlNode[K], min, max K, songs *[]*models.Song) {
+	if node == nil {
+		return
+	}
+	if min < node.key {
+		t.collect(node.left, min, max, songs)
+	}
+	if node.key >= min && node.key <= max {
+		*songs = append(*songs, node.songs...)
+	}
+	if max > node.key {
+		t.collect(node.right, min, max, songs)
+	}
+}
+
+// Len returns the number of songs in the tree
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (t *AVLTree[K]) Len() int {
+	return t.songs
+}
+
+// NodeCount returns the number of distinct keys in the tree
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (t *AVLTree[K]) NodeCount() int {
+	return t.nodes
+}
+
+// Height returns the number of nodes on the longest root-to-leaf path
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (t *AVLTree[K]) Height() int {
+	return t.height(t.root)
+}
+
+// Balance reports the shape of the tree, recomputing every subtree height instead of trusting
+// the cached ones so a broken invariant shows up
+// Time Complexity: O(n)
+// Space Complexity: O(log n) due to recursion stack
+func (t *AVLTree[K]) Balance() TreeBalance {
+	balance := TreeBalance{Nodes: t.nodes, OptimalHeight: optimalHeight(t.nodes)}
+	balance.Height = t.measure(t.root, &balance.MaxBalanceFactor)
+	balance.Balanced = balance.MaxBalanceFactor <= 1
+	return balance
+}
+
+// measure returns the real height of a subtree, raising maxFactor to its largest balance factor
+func (t *AVLTree[K]) measure(node *avlNode[K], maxFactor *int) int {
+	if node == nil {
+		return 0
+	}
+	left, right := t.measure(node.left, maxFactor), t.measure(node.right, maxFactor)
+	*maxFactor = max(*maxFactor, left-right, right-left)
+	return 1 + max(left, right)
+}
+
+// Clear removes every song from the tree
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (t *AVLTree[K]) Clear() {
+	t.root = nil
+	t.nodes = 0
+	t.songs = 0
+}
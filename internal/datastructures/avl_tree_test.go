@@ -0,0 +1,110 @@
+package datastructures
+
+import (
+	"fmt"
+	"src/internal/models"
+	"testing"
+)
+
+func newAVLSong(id string) *models.Song {
+	return models.NewSong(id, "Song "+id, "Artist", "Album", "Rock", "Alternative", "Chill", 200, 120)
+}
+
+// avlKeys returns the keys of a subtree in preorder, to check the shape after rotations
+func avlKeys(node *avlNode[int]) []int {
+	if node == nil {
+		return nil
+	}
+	keys := []int{node.key}
+	keys = append(keys, avlKeys(node.left)...)
+	return append(keys, avlKeys(node.right)...)
+}
+
+func TestAVLTree_Rotations(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []int
+		preord []int
+	}{
+		{"left-left", []int{3, 2, 1}, []int{2, 1, 3}},
+		{"right-right", []int{1, 2, 3}, []int{2, 1, 3}},
+		{"left-right", []int{3, 1, 2}, []int{2, 1, 3}},
+		{"right-left", []int{1, 3, 2}, []int{2, 1, 3}},
+	}
+	for _, tt := range tests {
+		tree := NewAVLTree[int]()
+		for _, key := range tt.keys {
+			tree.Insert(key, newAVLSong(fmt.Sprint(key)))
+		}
+		if got := avlKeys(tree.root); fmt.Sprint(got) != fmt.Sprint(tt.preord) {
+			t.Errorf("%s: preorder = %v, want %v", tt.name, got, tt.preord)
+		}
+		if tree.Height() != 2 {
+			t.Errorf("%s: height = %d, want 2", tt.name, tree.Height())
+		}
+	}
+}
+
+func TestAVLTree_StaysBalancedOnSortedInput(t *testing.T) {
+	tree := NewAVLTree[int]()
+	for key := 1; key <= 1000; key++ {
+		tree.Insert(key, newAVLSong(fmt.Sprint(key)))
+	}
+
+	balance := tree.Balance()
+	if !balance.Balanced || balance.Nodes != 1000 {
+		t.Fatalf("Expected a balanced tree of 1000 nodes, got %+v", balance)
+	}
+	// An AVL tree is at most about 1.44 times as high as a perfectly balanced one
+	if balance.Height > balance.OptimalHeight*3/2 {
+		t.Errorf("Height %d too far above optimal %d", balance.Height, balance.OptimalHeight)
+	}
+
+	for key := 1; key <= 1000; key += 2 {
+		if !tree.Remove(key, fmt.Sprint(key)) {
+			t.Fatalf("Remove(%d) should succeed", key)
+		}
+	}
+	balance = tree.Balance()
+	if !balance.Balanced || balance.Nodes != 500 || tree.Len() != 500 {
+		t.Errorf("Expected a balanced tree of 500 nodes after removals, got %+v", balance)
+	}
+	if balance.Height != tree.Height() {
+		t.Errorf("Cached height %d differs from measured %d", tree.Height(), balance.Height)
+	}
+}
+
+func TestAVLTree_SharedKeysAndRange(t *testing.T) {
+	tree := NewAVLTree[float64]()
+	tree.Insert(2.5, newAVLSong("a"))
+	tree.Insert(1.0, newAVLSong("b"))
+	tree.Insert(2.5, newAVLSong("c"))
+	tree.Insert(4.0, newAVLSong("d"))
+
+	if tree.NodeCount() != 3 || tree.Len() != 4 {
+		t.Errorf("Expected 3 keys holding 4 songs, got %d and %d", tree.NodeCount(), tree.Len())
+	}
+	if got := candidateIDs(tree.Range(2.0, 4.0)); fmt.Sprint(got) != "[a c d]" {
+		t.Errorf("Range(2, 4) = %v, want [a c d]", got)
+	}
+	if got := tree.Range(5, 1); len(got) != 0 {
+		t.Errorf("An inverted range should be empty, got %d songs", len(got))
+	}
+
+	if tree.Remove(2.5, "missing") {
+		t.Error("Removing an unknown song should fail")
+	}
+	tree.Remove(2.5, "a")
+	if got := candidateIDs(tree.Search(2.5)); fmt.Sprint(got) != "[c]" || tree.NodeCount() != 3 {
+		t.Errorf("Expected c to remain under 2.5, got %v", got)
+	}
+	tree.Remove(2.5, "c")
+	if tree.NodeCount() != 2 || len(tree.Search(2.5)) != 0 {
+		t.Error("An emptied key should be removed from the tree")
+	}
+
+	tree.Clear()
+	if tree.Len() != 0 || tree.Height() != 0 || tree.Balance().Nodes != 0 {
+		t.Error("Clear should empty the tree")
+	}
+}
@@ -0,0 +1,123 @@
+package datastructures
+
+import (
+	"sort"
+	"src/internal/models"
+)
+
+// BPMIndex keeps songs with a known BPM ordered by tempo, so BPM ranges can be read without
+// scanning the whole playlist
+// Songs are bucketed per BPM value and the distinct values are kept sorted; songs without a
+// BPM are not indexed
+// Time Complexity: O(log d + d) per add or removal where d is the number of distinct BPMs
+// Space Complexity: O(n + d)
+type BPMIndex struct {
+	buckets map[int][]*models.Song // BPM -> songs in insertion order
+	values  []int                  // Distinct BPMs in ascending order
+	bpms    map[string]int         // BPM each song ID is filed under, for removal
+}
+
+// NewBPMIndex creates an empty BPM index
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewBPMIndex() *BPMIndex {
+	return &BPMIndex{
+		buckets: make(map[int][]*models.Song),
+		bpms:    make(map[string]int),
+	}
+}
+
+// Add files a song under its current BPM, replacing where it was filed before
+// Call it again after changing a song's BPM; a song without a BPM is only removed
+// Time Complexity: O(log d) for an existing BPM, O(d) for a new one, plus O(b) when re-filed
+// Space Complexity: O(1)
+func (bi *BPMIndex) Add(song *models.Song) {
+	if _, exists := bi.bpms[song.ID]; exists {
+		bi.Remove(song.ID)
+	}
+	if song.BPM <= 0 {
+		return
+	}
+
+	if _, exists := bi.buckets[song.BPM]; !exists {
+		i := sort.SearchInts(bi.values, song.BPM)
+		bi.values = append(bi.values, 0)
+		copy(bi.values[i+1:], bi.values[i:])
+		bi.values[i] = song.BPM
+	}
+	bi.buckets[song.BPM] = append(bi.buckets[song.BPM], song)
+	bi.bpms[song.ID] = song.BPM
+}
+
+// Remove drops a song from the index
+// Time Complexity: O(b) where b is the number of songs sharing its BPM, plus O(d) when the bucket empties
+// Space Complexity: O(1)
+func (bi *BPMIndex) Remove(songID string) {
+	bpm, exists := bi.bpms[songID]
+	if !exists {
+		return
+	}
+	delete(bi.bpms, songID)
+
+	bucket := bi.buckets[bpm]
+	for i, song := range bucket {
+		if song.ID == songID {
+			bucket = append(bucket[:i], bucket[i+1:]...)
+			break
+		}
+	}
+	if len(bucket) > 0 {
+		bi.buckets[bpm] = bucket
+		return
+	}
+
+	delete(bi.buckets, bpm)
+	i := sort.SearchInts(bi.values, bpm)
+	bi.values = append(bi.values[:i], bi.values[i+1:]...)
+}
+
+// Range returns the songs with minBPM <= BPM <= maxBPM, slowest first and in insertion order
+// within a BPM; a non-positive maxBPM leaves the range open upwards
+// Time Complexity: O(log d + k) where k is the number of matching songs
+// Space Complexity: O(k)
+func (bi *BPMIndex) Range(minBPM, maxBPM int) []*models.Song {
+	songs := []*models.Song{}
+	for i := sort.SearchInts(bi.values, minBPM); i < len(bi.values); i++ {
+		bpm := bi.values[i]
+		if maxBPM > 0 && bpm > maxBPM {
+			break
+		}
+		songs = append(songs, bi.buckets[bpm]...)
+	}
+	return songs
+}
+
+// Count returns how many songs have minBPM <= BPM <= maxBPM, with Range's bounds
+// Time Complexity: O(log d + r) where r is the number of distinct BPMs in range
+// Space Complexity: O(1)
+func (bi *BPMIndex) Count(minBPM, maxBPM int) int {
+	count := 0
+	for i := sort.SearchInts(bi.values, minBPM); i < len(bi.values); i++ {
+		if maxBPM > 0 && bi.values[i] > maxBPM {
+			break
+		}
+		count += len(bi.buckets[bi.values[i]])
+	}
+	return count
+}
+
+// Len returns the number of indexed songs
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (bi *BPMIndex) Len() int {
+	return len(bi.bpms)
+}
+
+// Clear removes every song
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (bi *BPMIndex) Clear() {
+	bi.buckets = make(map[int][]*models.Song)
+	bi.values = nil
+	bi.bpms = make(map[string]int)
+}
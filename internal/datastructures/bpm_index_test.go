@@ -0,0 +1,85 @@
+package datastructures
+
+import (
+	"src/internal/models"
+	"testing"
+)
+
+func newBPMSong(id string, bpm int) *models.Song {
+	return models.NewSong(id, "Song "+id, "Artist", "Album", "Rock", "Alternative", "Chill", 200, bpm)
+}
+
+func TestBPMIndex_Range(t *testing.T) {
+	index := NewBPMIndex()
+	for _, song := range []*models.Song{
+		newBPMSong("1", 120), newBPMSong("2", 90), newBPMSong("3", 0), newBPMSong("4", 120), newBPMSong("5", 140),
+	} {
+		index.Add(song)
+	}
+
+	if index.Len() != 4 {
+		t.Errorf("Expected songs without a BPM to be skipped, got %d indexed", index.Len())
+	}
+
+	tests := []struct {
+		min, max int
+		want     []string
+	}{
+		{0, 0, []string{"2", "1", "4", "5"}},
+		{100, 130, []string{"1", "4"}},
+		{120, 0, []string{"1", "4", "5"}},
+		{141, 200, []string{}},
+		{90, 90, []string{"2"}},
+	}
+	for _, tt := range tests {
+		got := candidateIDs(index.Range(tt.min, tt.max))
+		if len(got) != len(tt.want) {
+			t.Errorf("Range(%d, %d) = %v, want %v", tt.min, tt.max, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Range(%d, %d) = %v, want %v", tt.min, tt.max, got, tt.want)
+				break
+			}
+		}
+		if count := index.Count(tt.min, tt.max); count != len(tt.want) {
+			t.Errorf("Count(%d, %d) = %d, want %d", tt.min, tt.max, count, len(tt.want))
+		}
+	}
+}
+
+func TestBPMIndex_RefileAndRemove(t *testing.T) {
+	index := NewBPMIndex()
+	song := newBPMSong("1", 100)
+	index.Add(song)
+	index.Add(newBPMSong("2", 100))
+
+	song.BPM = 150
+	index.Add(song)
+	if got := candidateIDs(index.Range(100, 100)); len(got) != 1 || got[0] != "2" {
+		t.Errorf("Expected only song 2 left at 100 BPM, got %v", got)
+	}
+	if got := candidateIDs(index.Range(150, 150)); len(got) != 1 || got[0] != "1" {
+		t.Errorf("Expected song 1 re-filed at 150 BPM, got %v", got)
+	}
+
+	// Clearing a song's BPM drops it from the index
+	song.BPM = 0
+	index.Add(song)
+	if index.Len() != 1 || index.Count(0, 0) != 1 {
+		t.Errorf("Expected 1 indexed song after clearing a BPM, got %d", index.Len())
+	}
+
+	index.Remove("2")
+	index.Remove("missing")
+	if index.Len() != 0 || len(index.Range(0, 0)) != 0 {
+		t.Error("Expected an empty index after removing every song")
+	}
+
+	index.Add(newBPMSong("3", 80))
+	index.Clear()
+	if index.Len() != 0 || len(index.Range(0, 0)) != 0 {
+		t.Error("Expected an empty index after Clear")
+	}
+}
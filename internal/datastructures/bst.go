@@ -3,6 +3,7 @@ package datastructures
 import (
 	"fmt"
 	"src/internal/models"
+	"sync"
 )
 
 // RatingBucket holds multiple songs with the same rating
@@ -60,22 +61,32 @@ func (rb *RatingBucket) GetSongs() []*models.Song {
 }
 
 // BSTNode represents a node in the Binary Search Tree
-// Each node contains a rating bucket and left/right children
+// Each node contains a rating bucket, left/right children and the height of its subtree
 // Time Complexity: O(1) for field access
 // Space Complexity: O(1) per node
 type BSTNode struct {
 	Bucket *RatingBucket
 	Left   *BSTNode
 	Right  *BSTNode
+	Height int
 }
 
 // SongRatingBST represents a Binary Search Tree for song ratings
 // Organizes songs by rating (1-5 stars) for fast lookup and manipulation
-// Time Complexity: O(log n) average, O(n) worst case for search/insert/delete
+// The tree rebalances itself like an AVL tree, so ratings inserted in order don't degrade it into a list
+// Time Complexity: O(log n) for search/insert/delete
 // Space Complexity: O(n) where n is the number of unique ratings
 type SongRatingBST struct {
 	Root      *BSTNode
 	NodeCount int
+
+	// Optional read cache for sorted output, invalidated on every mutation
+	// cacheMu lets concurrent readers fill the cache; mutations still need exclusive access
+	cacheMu      sync.Mutex
+	cacheEnabled bool
+	cacheValid   bool
+	sortedCache  []*models.Song
+	statsCache   map[int]int
 }
 
 // NewSongRatingBST creates a new song rating BST
@@ -89,7 +100,7 @@ func NewSongRatingBST() *SongRatingBST {
 }
 
 // InsertSong inserts a song with its rating into the BST
-// Time Complexity: O(log n) average, O(n) worst case
+// Time Complexity: O(log n)
 // Space Complexity: O(log n) due to recursion stack
 func (bst *SongRatingBST) InsertSong(song *models.Song, rating int) {
 	if song == nil || rating < 1 || rating > 5 {
@@ -98,10 +109,11 @@ func (bst *SongRatingBST) InsertSong(song *models.Song, rating int) {
 
 	song.SetRating(rating) // Update song's rating
 	bst.Root = bst.insertNode(bst.Root, song, rating)
+	bst.invalidateCache()
 }
 
-// insertNode is a recursive helper for inserting nodes
-// Time Complexity: O(log n) average, O(n) worst case
+// insertNode is a recursive helper for inserting nodes, returning the rebalanced subtree
+// Time Complexity: O(log n)
 // Space Complexity: O(log n) due to recursion stack
 func (bst *SongRatingBST) insertNode(node *BSTNode, song *models.Song, rating int) *BSTNode {
 	if node == nil {
@@ -113,12 +125,14 @@ func (bst *SongRatingBST) insertNode(node *BSTNode, song *models.Song, rating in
 			Bucket: bucket,
 			Left:   nil,
 			Right:  nil,
+			Height: 1,
 		}
 	}
 
 	if rating == node.Bucket.Rating {
 		// Same rating, add to existing bucket
 		node.Bucket.AddSong(song)
+		return node
 	} else if rating < node.Bucket.Rating {
 		// Insert in left subtree
 		node.Left = bst.insertNode(node.Left, song, rating)
@@ -127,11 +141,67 @@ func (bst *SongRatingBST) insertNode(node *BSTNode, song *models.Song, rating in
 		node.Right = bst.insertNode(node.Right, song, rating)
 	}
 
+	return bst.rebalance(node)
+}
+
+// nodeHeight returns the height of a subtree, 0 when it is empty
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func nodeHeight(node *BSTNode) int {
+	if node == nil {
+		return 0
+	}
+	return node.Height
+}
+
+// rebalance updates a node's height and rotates it when its subtrees differ in height by more than one
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (bst *SongRatingBST) rebalance(node *BSTNode) *BSTNode {
+	node.Height = 1 + max(nodeHeight(node.Left), nodeHeight(node.Right))
+	switch factor := nodeHeight(node.Left) - nodeHeight(node.Right); {
+	case factor > 1:
+		// Left-right case needs the left child rotated first
+		if nodeHeight(node.Left.Left) < nodeHeight(node.Left.Right) {
+			node.Left = bst.rotateLeft(node.Left)
+		}
+		return bst.rotateRight(node)
+	case factor < -1:
+		// Right-left case needs the right child rotated first
+		if nodeHeight(node.Right.Right) < nodeHeight(node.Right.Left) {
+			node.Right = bst.rotateRight(node.Right)
+		}
+		return bst.rotateLeft(node)
+	}
 	return node
 }
 
+// rotateRight lifts the left child of node into its place
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (bst *SongRatingBST) rotateRight(node *BSTNode) *BSTNode {
+	pivot := node.Left
+	node.Left = pivot.Right
+	pivot.Right = node
+	node.Height = 1 + max(nodeHeight(node.Left), nodeHeight(node.Right))
+	pivot.Height = 1 + max(nodeHeight(pivot.Left), nodeHeight(pivot.Right))
+	return pivot
+}
+
+// rotateLeft lifts the right child of node into its place
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (bst *SongRatingBST) rotateLeft(node *BSTNode) *BSTNode {
+	pivot := node.Right
+	node.Right = pivot.Left
+	pivot.Left = node
+	node.Height = 1 + max(nodeHeight(node.Left), nodeHeight(node.Right))
+	pivot.Height = 1 + max(nodeHeight(pivot.Left), nodeHeight(pivot.Right))
+	return pivot
+}
+
 // SearchByRating returns all songs with the specified rating
-// Time Complexity: O(log n) average, O(n) worst case
+// Time Complexity: O(log n)
 // Space Complexity: O(1)
 func (bst *SongRatingBST) SearchByRating(rating int) []*models.Song {
 	if rating < 1 || rating > 5 {
@@ -146,7 +216,7 @@ func (bst *SongRatingBST) SearchByRating(rating int) []*models.Song {
 }
 
 // searchNode is a recursive helper for searching nodes by rating
-// Time Complexity: O(log n) average, O(n) worst case
+// Time Complexity: O(log n)
 // Space Complexity: O(log n) due to recursion stack
 func (bst *SongRatingBST) searchNode(node *BSTNode, rating int) *BSTNode {
 	if node == nil || node.Bucket.Rating == rating {
@@ -176,6 +246,9 @@ func (bst *SongRatingBST) DeleteSong(songID string) bool {
 
 	// Remove song from bucket
 	removed := node.Bucket.RemoveSong(songID)
+	if removed {
+		bst.invalidateCache()
+	}
 	if removed && node.Bucket.IsEmpty() {
 		// If bucket is empty, remove the entire node
 		bst.Root = bst.deleteNode(bst.Root, rating)
@@ -185,8 +258,8 @@ func (bst *SongRatingBST) DeleteSong(songID string) bool {
 	return removed
 }
 
-// deleteNode is a recursive helper for deleting nodes
-// Time Complexity: O(log n) average, O(n) worst case
+// deleteNode is a recursive helper for deleting nodes, returning the rebalanced subtree
+// Time Complexity: O(log n)
 // Space Complexity: O(log n) due to recursion stack
 func (bst *SongRatingBST) deleteNode(node *BSTNode, rating int) *BSTNode {
 	if node == nil {
@@ -211,11 +284,11 @@ func (bst *SongRatingBST) deleteNode(node *BSTNode, rating int) *BSTNode {
 		node.Right = bst.deleteNode(node.Right, successor.Bucket.Rating)
 	}
 
-	return node
+	return bst.rebalance(node)
 }
 
 // findMinNode finds the node with minimum rating in a subtree
-// Time Complexity: O(log n) average, O(n) worst case
+// Time Complexity: O(log n)
 // Space Complexity: O(1)
 func (bst *SongRatingBST) findMinNode(node *BSTNode) *BSTNode {
 	for node.Left != nil {
@@ -256,14 +329,58 @@ func (bst *SongRatingBST) findSongInSubtree(node *BSTNode, songID string) *model
 }
 
 // GetAllSongs returns all songs in the BST sorted by rating (ascending)
-// Time Complexity: O(n * k) where n is nodes and k is average songs per bucket
+// With caching enabled the result is reused until the next mutation and must not be modified
+// Time Complexity: O(1) when cached, otherwise O(n * k) where n is nodes and k is average songs per bucket
 // Space Complexity: O(n * k) for result slice + O(log n) for recursion
 func (bst *SongRatingBST) GetAllSongs() []*models.Song {
+	bst.cacheMu.Lock()
+	defer bst.cacheMu.Unlock()
+
+	if bst.cacheEnabled && bst.cacheValid {
+		return bst.sortedCache
+	}
+
 	songs := make([]*models.Song, 0)
 	bst.inorderTraversal(bst.Root, &songs)
+
+	if bst.cacheEnabled {
+		// Cap the capacity so appends by callers can't write into the cache
+		bst.sortedCache = songs[:len(songs):len(songs)]
+		bst.cacheValid = true
+	}
 	return songs
 }
 
+// EnableCache turns the cache for GetAllSongs and GetRatingStats on or off
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (bst *SongRatingBST) EnableCache(enabled bool) {
+	bst.cacheMu.Lock()
+	defer bst.cacheMu.Unlock()
+
+	bst.cacheEnabled = enabled
+	bst.clearCache()
+}
+
+// invalidateCache drops cached results after a mutation
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (bst *SongRatingBST) invalidateCache() {
+	bst.cacheMu.Lock()
+	defer bst.cacheMu.Unlock()
+
+	bst.clearCache()
+}
+
+// clearCache drops cached results, the caller holds cacheMu
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (bst *SongRatingBST) clearCache() {
+	bst.cacheValid = false
+	bst.sortedCache = nil
+	bst.statsCache = nil
+}
+
 // inorderTraversal performs inorder traversal to get songs sorted by rating
 // Time Complexity: O(n * k) where n is nodes and k is average songs per bucket
 // Space Complexity: O(log n) due to recursion stack
@@ -275,7 +392,7 @@ func (bst *SongRatingBST) inorderTraversal(node *BSTNode, songs *[]*models.Song)
 	}
 }
 
-// GetSongsByRatingRange returns all songs within a rating range (inclusive)
+// GetSongsByRatingRange returns all songs within a rating range (inclusive), lowest rating first
 // Time Complexity: O(n * k) where n is nodes and k is average songs per bucket
 // Space Complexity: O(result_size)
 func (bst *SongRatingBST) GetSongsByRatingRange(minRating, maxRating int) []*models.Song {
@@ -288,7 +405,21 @@ func (bst *SongRatingBST) GetSongsByRatingRange(minRating, maxRating int) []*mod
 	return songs
 }
 
-// rangeSearch is a recursive helper for range searching
+// GetSongsByRatingRangeDesc returns all songs within a rating range (inclusive), highest rating first
+// Songs sharing a rating keep their bucket order
+// Time Complexity: O(n * k) where n is nodes and k is average songs per bucket
+// Space Complexity: O(result_size)
+func (bst *SongRatingBST) GetSongsByRatingRangeDesc(minRating, maxRating int) []*models.Song {
+	if minRating > maxRating || minRating < 1 || maxRating > 5 {
+		return []*models.Song{}
+	}
+
+	songs := make([]*models.Song, 0)
+	bst.reverseRangeSearch(bst.Root, minRating, maxRating, &songs)
+	return songs
+}
+
+// rangeSearch is a recursive inorder helper for range searching
 // Time Complexity: O(n * k) in worst case
 // Space Complexity: O(log n) due to recursion stack
 func (bst *SongRatingBST) rangeSearch(node *BSTNode, minRating, maxRating int, songs *[]*models.Song) {
@@ -296,28 +427,61 @@ func (bst *SongRatingBST) rangeSearch(node *BSTNode, minRating, maxRating int, s
 		return
 	}
 
-	// If current rating is in range, add songs
-	if node.Bucket.Rating >= minRating && node.Bucket.Rating <= maxRating {
-		*songs = append(*songs, node.Bucket.Songs...)
-	}
-
 	// Search left if there might be valid ratings
 	if minRating < node.Bucket.Rating {
 		bst.rangeSearch(node.Left, minRating, maxRating, songs)
 	}
 
+	// If current rating is in range, add songs
+	if node.Bucket.Rating >= minRating && node.Bucket.Rating <= maxRating {
+		*songs = append(*songs, node.Bucket.Songs...)
+	}
+
 	// Search right if there might be valid ratings
 	if maxRating > node.Bucket.Rating {
 		bst.rangeSearch(node.Right, minRating, maxRating, songs)
 	}
 }
 
+// reverseRangeSearch is a recursive reverse inorder helper for descending range searches
+// Time Complexity: O(n * k) in worst case
+// Space Complexity: O(log n) due to recursion stack
+func (bst *SongRatingBST) reverseRangeSearch(node *BSTNode, minRating, maxRating int, songs *[]*models.Song) {
+	if node == nil {
+		return
+	}
+
+	if maxRating > node.Bucket.Rating {
+		bst.reverseRangeSearch(node.Right, minRating, maxRating, songs)
+	}
+
+	if node.Bucket.Rating >= minRating && node.Bucket.Rating <= maxRating {
+		*songs = append(*songs, node.Bucket.Songs...)
+	}
+
+	if minRating < node.Bucket.Rating {
+		bst.reverseRangeSearch(node.Left, minRating, maxRating, songs)
+	}
+}
+
 // GetRatingStats returns statistics about song ratings
-// Time Complexity: O(n * k) where n is nodes and k is average songs per bucket
+// With caching enabled the result is reused until the next mutation and must not be modified
+// Time Complexity: O(1) when cached, otherwise O(n * k) where n is nodes and k is average songs per bucket
 // Space Complexity: O(1)
 func (bst *SongRatingBST) GetRatingStats() map[int]int {
+	bst.cacheMu.Lock()
+	defer bst.cacheMu.Unlock()
+
+	if bst.cacheEnabled && bst.statsCache != nil {
+		return bst.statsCache
+	}
+
 	stats := make(map[int]int)
 	bst.collectStats(bst.Root, stats)
+
+	if bst.cacheEnabled {
+		bst.statsCache = stats
+	}
 	return stats
 }
 
@@ -346,6 +510,35 @@ func (bst *SongRatingBST) GetNodeCount() int {
 	return bst.NodeCount
 }
 
+// GetHeight returns the number of nodes on the longest root-to-leaf path
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (bst *SongRatingBST) GetHeight() int {
+	return nodeHeight(bst.Root)
+}
+
+// GetBalance reports the shape of the tree, measuring every subtree instead of trusting the stored heights
+// Time Complexity: O(n)
+// Space Complexity: O(log n) due to recursion stack
+func (bst *SongRatingBST) GetBalance() TreeBalance {
+	balance := TreeBalance{Nodes: bst.NodeCount, OptimalHeight: optimalHeight(bst.NodeCount)}
+	balance.Height = bst.measure(bst.Root, &balance.MaxBalanceFactor)
+	balance.Balanced = balance.MaxBalanceFactor <= 1
+	return balance
+}
+
+// measure returns the real height of a subtree, raising maxFactor to its largest balance factor
+// Time Complexity: O(n)
+// Space Complexity: O(log n) due to recursion stack
+func (bst *SongRatingBST) measure(node *BSTNode, maxFactor *int) int {
+	if node == nil {
+		return 0
+	}
+	left, right := bst.measure(node.Left, maxFactor), bst.measure(node.Right, maxFactor)
+	*maxFactor = max(*maxFactor, left-right, right-left)
+	return 1 + max(left, right)
+}
+
 // GetTotalSongs returns the total number of songs across all ratings
 // Time Complexity: O(n)
 // Space Complexity: O(log n) due to recursion stack
@@ -369,6 +562,7 @@ func (bst *SongRatingBST) countSongs(node *BSTNode) int {
 func (bst *SongRatingBST) Clear() {
 	bst.Root = nil
 	bst.NodeCount = 0
+	bst.invalidateCache()
 }
 
 // String returns a string representation of the BST
@@ -340,6 +340,55 @@ func TestSongRatingBST_GetSongsByRatingRange(t *testing.T) {
 	}
 }
 
+func TestSongRatingBST_GetSongsByRatingRangeDesc(t *testing.T) {
+	bst := NewSongRatingBST()
+
+	// Insert out of order so the tree isn't a simple chain
+	for _, song := range []*models.Song{
+		createBSTTestSong("3", "Song 3", "Artist 3", 3),
+		createBSTTestSong("1", "Song 1", "Artist 1", 1),
+		createBSTTestSong("5", "Song 5", "Artist 5", 5),
+		createBSTTestSong("4a", "Song 4a", "Artist 4", 4),
+		createBSTTestSong("2", "Song 2", "Artist 2", 2),
+		createBSTTestSong("4b", "Song 4b", "Artist 4", 4),
+	} {
+		bst.InsertSong(song, song.Rating)
+	}
+
+	ascending := bst.GetSongsByRatingRange(2, 5)
+	descending := bst.GetSongsByRatingRangeDesc(2, 5)
+
+	if len(ascending) != 5 || len(descending) != len(ascending) {
+		t.Fatalf("Expected 5 songs both ways, got %d ascending and %d descending", len(ascending), len(descending))
+	}
+	for i := 1; i < len(ascending); i++ {
+		if ascending[i].Rating < ascending[i-1].Rating {
+			t.Errorf("Ascending range out of order at %d: %d after %d", i, ascending[i].Rating, ascending[i-1].Rating)
+		}
+		if descending[i].Rating > descending[i-1].Rating {
+			t.Errorf("Descending range out of order at %d: %d after %d", i, descending[i].Rating, descending[i-1].Rating)
+		}
+	}
+
+	// Same set of songs, only the rating order is reversed
+	seen := make(map[string]bool)
+	for _, song := range ascending {
+		seen[song.ID] = true
+	}
+	for _, song := range descending {
+		if !seen[song.ID] {
+			t.Errorf("Descending range has unexpected song %s", song.ID)
+		}
+	}
+	if descending[0].ID != "5" || descending[1].ID != "4a" || descending[2].ID != "4b" {
+		t.Errorf("Expected 5, 4a, 4b first, got %s, %s, %s", descending[0].ID, descending[1].ID, descending[2].ID)
+	}
+
+	if songs := bst.GetSongsByRatingRangeDesc(4, 2); len(songs) != 0 {
+		t.Errorf("Expected no songs for min > max, got %d", len(songs))
+	}
+}
+
 func TestSongRatingBST_GetRatingStats(t *testing.T) {
 	bst := NewSongRatingBST()
 
@@ -591,4 +640,97 @@ func TestSongRatingBST_TreeBalance(t *testing.T) {
 	if len(songs) != 3 {
 		t.Errorf("Range query in potentially unbalanced tree failed")
 	}
+
+	// Sorted inserts must not degrade the tree into a list
+	balance := bst.GetBalance()
+	if !balance.Balanced || balance.Height != 3 || bst.GetHeight() != 3 {
+		t.Errorf("GetBalance() after sorted inserts = %+v, want a balanced tree of height 3", balance)
+	}
+	if bst.Root.Bucket.Rating != 2 || bst.Root.Right.Bucket.Rating != 4 {
+		t.Errorf("Expected rotations to lift ratings 2 and 4, root is %d", bst.Root.Bucket.Rating)
+	}
+}
+
+func TestSongRatingBST_RebalancesOnDelete(t *testing.T) {
+	bst := NewSongRatingBST()
+	for _, rating := range []int{2, 1, 4, 3, 5} {
+		bst.InsertSong(createBSTTestSong(string(rune('0'+rating)), "Song", "Artist", rating), rating)
+	}
+
+	// Removing the only left leaf leaves the root right-heavy by two
+	bst.DeleteSong("1")
+	balance := bst.GetBalance()
+	if !balance.Balanced || balance.Nodes != 4 {
+		t.Errorf("GetBalance() after delete = %+v, want a balanced tree of 4 nodes", balance)
+	}
+	if bst.Root.Bucket.Rating != 4 {
+		t.Errorf("Expected rating 4 at the root after rebalancing, got %d", bst.Root.Bucket.Rating)
+	}
+
+	songs := bst.GetAllSongs()
+	for i := 1; i < len(songs); i++ {
+		if songs[i-1].Rating > songs[i].Rating {
+			t.Errorf("Inorder order broken after rebalancing delete")
+		}
+	}
+}
+
+func TestSongRatingBST_Cache(t *testing.T) {
+	bst := NewSongRatingBST()
+	bst.EnableCache(true)
+
+	song1 := createBSTTestSong("1", "Song 1", "Artist 1", 4)
+	song2 := createBSTTestSong("2", "Song 2", "Artist 2", 2)
+	song3 := createBSTTestSong("3", "Song 3", "Artist 3", 5)
+	bst.InsertSong(song1, 4)
+	bst.InsertSong(song2, 2)
+
+	first := bst.GetAllSongs()
+	if len(first) != 2 || first[0].ID != "2" || first[1].ID != "1" {
+		t.Fatalf("GetAllSongs() = %v, want songs 2,1", first)
+	}
+
+	// Repeated reads reuse the cached slice
+	second := bst.GetAllSongs()
+	if &first[0] != &second[0] {
+		t.Error("GetAllSongs() should return the cached slice until a mutation")
+	}
+	if stats := bst.GetRatingStats(); stats[4] != 1 || stats[2] != 1 {
+		t.Errorf("GetRatingStats() = %v, want one song at 2 and 4", stats)
+	}
+
+	// Insert invalidates the cache
+	bst.InsertSong(song3, 5)
+	if songs := bst.GetAllSongs(); len(songs) != 3 || songs[2].ID != "3" {
+		t.Errorf("GetAllSongs() after insert = %v, want 3 songs ending with 3", songs)
+	}
+	if stats := bst.GetRatingStats(); stats[5] != 1 {
+		t.Errorf("GetRatingStats() after insert = %v, want one song at 5", stats)
+	}
+
+	// Delete invalidates the cache
+	bst.DeleteSong("2")
+	if songs := bst.GetAllSongs(); len(songs) != 2 || songs[0].ID != "1" {
+		t.Errorf("GetAllSongs() after delete = %v, want songs 1,3", songs)
+	}
+	if stats := bst.GetRatingStats(); stats[2] != 0 {
+		t.Errorf("GetRatingStats() after delete = %v, want no songs at 2", stats)
+	}
+
+	// Clear invalidates the cache
+	bst.Clear()
+	if songs := bst.GetAllSongs(); len(songs) != 0 {
+		t.Errorf("GetAllSongs() after clear = %v, want empty", songs)
+	}
+	if stats := bst.GetRatingStats(); len(stats) != 0 {
+		t.Errorf("GetRatingStats() after clear = %v, want empty", stats)
+	}
+
+	// Disabling the cache returns fresh slices
+	bst.EnableCache(false)
+	bst.InsertSong(song1, 3)
+	a, b := bst.GetAllSongs(), bst.GetAllSongs()
+	if &a[0] == &b[0] {
+		t.Error("GetAllSongs() should not reuse slices when caching is disabled")
+	}
 }
@@ -17,12 +17,15 @@ type PlaylistNode struct {
 
 // DoublyLinkedList represents a playlist using doubly linked list
 // Supports efficient insertion, deletion, and traversal operations
+// A song ID → node index keeps ID-based lookups O(1) across structural changes
 // Time Complexity: O(1) for head/tail operations, O(n) for index-based operations
 // Space Complexity: O(n) where n is the number of songs
 type DoublyLinkedList struct {
 	Head   *PlaylistNode
 	Tail   *PlaylistNode
 	Length int
+
+	nodesByID map[string]*PlaylistNode
 }
 
 // NewDoublyLinkedList creates a new empty playlist
@@ -30,9 +33,29 @@ type DoublyLinkedList struct {
 // Space Complexity: O(1)
 func NewDoublyLinkedList() *DoublyLinkedList {
 	return &DoublyLinkedList{
-		Head:   nil,
-		Tail:   nil,
-		Length: 0,
+		Head:      nil,
+		Tail:      nil,
+		Length:    0,
+		nodesByID: make(map[string]*PlaylistNode),
+	}
+}
+
+// indexNode records a node in the ID index
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (dll *DoublyLinkedList) indexNode(node *PlaylistNode) {
+	if dll.nodesByID == nil {
+		dll.nodesByID = make(map[string]*PlaylistNode)
+	}
+	dll.nodesByID[node.Song.ID] = node
+}
+
+// unindexNode removes a node from the ID index if it is the indexed node for its ID
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (dll *DoublyLinkedList) unindexNode(node *PlaylistNode) {
+	if dll.nodesByID[node.Song.ID] == node {
+		delete(dll.nodesByID, node.Song.ID)
 	}
 }
 
@@ -40,6 +63,10 @@ func NewDoublyLinkedList() *DoublyLinkedList {
 // Time Complexity: O(1)
 // Space Complexity: O(1)
 func (dll *DoublyLinkedList) AddSong(song *models.Song) {
+	if song == nil {
+		return
+	}
+
 	newNode := &PlaylistNode{
 		Song: song,
 		Next: nil,
@@ -56,6 +83,7 @@ func (dll *DoublyLinkedList) AddSong(song *models.Song) {
 		dll.Tail = newNode
 	}
 
+	dll.indexNode(newNode)
 	dll.Length++
 }
 
@@ -63,6 +91,9 @@ func (dll *DoublyLinkedList) AddSong(song *models.Song) {
 // Time Complexity: O(n) where n is the index
 // Space Complexity: O(1)
 func (dll *DoublyLinkedList) AddSongAtIndex(song *models.Song, index int) error {
+	if song == nil {
+		return fmt.Errorf("song cannot be nil")
+	}
 	if index < 0 || index > dll.Length {
 		return fmt.Errorf("index out of bounds: %d", index)
 	}
@@ -87,6 +118,7 @@ func (dll *DoublyLinkedList) AddSongAtIndex(song *models.Song, index int) error
 	current.Prev.Next = newNode
 	current.Prev = newNode
 
+	dll.indexNode(newNode)
 	dll.Length++
 	return nil
 }
@@ -95,6 +127,10 @@ func (dll *DoublyLinkedList) AddSongAtIndex(song *models.Song, index int) error
 // Time Complexity: O(1)
 // Space Complexity: O(1)
 func (dll *DoublyLinkedList) AddSongToBeginning(song *models.Song) {
+	if song == nil {
+		return
+	}
+
 	newNode := &PlaylistNode{
 		Song: song,
 		Next: dll.Head,
@@ -110,6 +146,7 @@ func (dll *DoublyLinkedList) AddSongToBeginning(song *models.Song) {
 		dll.Head = newNode
 	}
 
+	dll.indexNode(newNode)
 	dll.Length++
 }
 
@@ -121,19 +158,33 @@ func (dll *DoublyLinkedList) DeleteSong(index int) (*models.Song, error) {
 		return nil, fmt.Errorf("index out of bounds: %d", index)
 	}
 
+	nodeToDelete := dll.getNodeAtIndex(index)
+	dll.removeNode(nodeToDelete)
+	return nodeToDelete.Song, nil
+}
+
+// DeleteSongByID removes a song by ID using the node index
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (dll *DoublyLinkedList) DeleteSongByID(songID string) (*models.Song, error) {
+	node, exists := dll.nodesByID[songID]
+	if !exists {
+		return nil, fmt.Errorf("song with ID %s not found", songID)
+	}
+
+	dll.removeNode(node)
+	return node.Song, nil
+}
+
+// removeNode unlinks a node from the list and the ID index
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (dll *DoublyLinkedList) removeNode(nodeToDelete *PlaylistNode) {
 	if dll.Length == 1 {
 		// Only one node
-		song := dll.Head.Song
 		dll.Head = nil
 		dll.Tail = nil
-		dll.Length = 0
-		return song, nil
-	}
-
-	nodeToDelete := dll.getNodeAtIndex(index)
-	song := nodeToDelete.Song
-
-	if nodeToDelete == dll.Head {
+	} else if nodeToDelete == dll.Head {
 		// Delete head
 		dll.Head = dll.Head.Next
 		dll.Head.Prev = nil
@@ -147,11 +198,14 @@ func (dll *DoublyLinkedList) DeleteSong(index int) (*models.Song, error) {
 		nodeToDelete.Next.Prev = nodeToDelete.Prev
 	}
 
+	nodeToDelete.Next = nil
+	nodeToDelete.Prev = nil
+	dll.unindexNode(nodeToDelete)
 	dll.Length--
-	return song, nil
 }
 
 // MoveSong moves a song from one index to another
+// toIndex is the song's final index in the resulting list
 // Time Complexity: O(n) where n is max(fromIndex, toIndex)
 // Space Complexity: O(1)
 func (dll *DoublyLinkedList) MoveSong(fromIndex, toIndex int) error {
@@ -169,13 +223,78 @@ func (dll *DoublyLinkedList) MoveSong(fromIndex, toIndex int) error {
 		return err
 	}
 
-	// Adjust toIndex if necessary (when moving forward, index shifts after deletion)
-	if toIndex > fromIndex {
-		toIndex--
+	// toIndex is the final index in the resulting list, which is also the insertion
+	// index in the shortened list whether moving forward or backward
+	return dll.AddSongAtIndex(song, toIndex)
+}
+
+// MoveBlock moves count consecutive songs starting at startIndex so they sit just before the song
+// currently at toIndex, keeping their relative order; toIndex == Length moves the block to the end
+// Unlike MoveSong, toIndex refers to the current list, so it can't fall inside the block itself
+// Time Complexity: O(n) to locate the nodes, O(1) to relink the block
+// Space Complexity: O(1)
+func (dll *DoublyLinkedList) MoveBlock(startIndex, count, toIndex int) error {
+	if count <= 0 {
+		return fmt.Errorf("block size must be positive: %d", count)
+	}
+	// Compared against the room left after startIndex so a huge count can't overflow
+	if startIndex < 0 || startIndex > dll.Length || count > dll.Length-startIndex {
+		return fmt.Errorf("block of %d songs at %d is out of bounds", count, startIndex)
+	}
+	if toIndex < 0 || toIndex > dll.Length {
+		return fmt.Errorf("index out of bounds: %d", toIndex)
+	}
+	if toIndex > startIndex && toIndex < startIndex+count {
+		return fmt.Errorf("target index %d is inside the block [%d, %d)", toIndex, startIndex, startIndex+count)
 	}
 
-	// Insert at new position
-	return dll.AddSongAtIndex(song, toIndex)
+	// Already in place
+	if toIndex == startIndex || toIndex == startIndex+count {
+		return nil
+	}
+
+	first := dll.getNodeAtIndex(startIndex)
+	last := first
+	for i := 1; i < count; i++ {
+		last = last.Next
+	}
+
+	// nil target means append after the tail
+	var target *PlaylistNode
+	if toIndex < dll.Length {
+		target = dll.getNodeAtIndex(toIndex)
+	}
+
+	// Detach the block
+	if first.Prev != nil {
+		first.Prev.Next = last.Next
+	} else {
+		dll.Head = last.Next
+	}
+	if last.Next != nil {
+		last.Next.Prev = first.Prev
+	} else {
+		dll.Tail = first.Prev
+	}
+
+	// Relink it before the target
+	if target == nil {
+		first.Prev = dll.Tail
+		last.Next = nil
+		dll.Tail.Next = first
+		dll.Tail = last
+		return nil
+	}
+
+	first.Prev = target.Prev
+	last.Next = target
+	if target.Prev != nil {
+		target.Prev.Next = first
+	} else {
+		dll.Head = first
+	}
+	target.Prev = last
+	return nil
 }
 
 // ReversePlaylist reverses the entire playlist
@@ -252,6 +371,15 @@ func (dll *DoublyLinkedList) ToSlice() []*models.Song {
 	return songs
 }
 
+// ForEach calls visit with every song from head to tail without copying the list
+// Time Complexity: O(n)
+// Space Complexity: O(1)
+func (dll *DoublyLinkedList) ForEach(visit func(*models.Song)) {
+	for current := dll.Head; current != nil; current = current.Next {
+		visit(current.Song)
+	}
+}
+
 // Size returns the number of songs in the playlist
 // Time Complexity: O(1)
 // Space Complexity: O(1)
@@ -273,6 +401,7 @@ func (dll *DoublyLinkedList) Clear() {
 	dll.Head = nil
 	dll.Tail = nil
 	dll.Length = 0
+	dll.nodesByID = make(map[string]*PlaylistNode)
 }
 
 // GetTotalDuration calculates total duration of all songs in playlist
@@ -291,21 +420,148 @@ func (dll *DoublyLinkedList) GetTotalDuration() int {
 }
 
 // FindSongByID searches for a song by ID and returns its index
-// Time Complexity: O(n)
+// The node is resolved through the ID index, then its index is counted back to the head
+// Time Complexity: O(1) for unknown IDs, O(i) where i is the song's index otherwise
 // Space Complexity: O(1)
 func (dll *DoublyLinkedList) FindSongByID(songID string) (int, error) {
-	current := dll.Head
+	node, exists := dll.nodesByID[songID]
+	if !exists {
+		return -1, fmt.Errorf("song with ID %s not found", songID)
+	}
+
 	index := 0
+	for current := node.Prev; current != nil; current = current.Prev {
+		index++
+	}
 
-	for current != nil {
-		if current.Song.ID == songID {
-			return index, nil
+	return index, nil
+}
+
+// GetNodeByID returns the list node holding a song in O(1)
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (dll *DoublyLinkedList) GetNodeByID(songID string) (*PlaylistNode, bool) {
+	node, exists := dll.nodesByID[songID]
+	return node, exists
+}
+
+// GetNeighbors returns the songs within radius positions of the given song in current order
+// center is the song's index within the returned slice, clamped at list boundaries
+// Time Complexity: O(r) where r is the radius
+// Space Complexity: O(r)
+func (dll *DoublyLinkedList) GetNeighbors(songID string, radius int) ([]*models.Song, int, error) {
+	node, exists := dll.nodesByID[songID]
+	if !exists {
+		return nil, -1, fmt.Errorf("song with ID %s not found", songID)
+	}
+	if radius < 0 {
+		return nil, -1, fmt.Errorf("radius cannot be negative: %d", radius)
+	}
+
+	// Walk back up to radius nodes to find the window start
+	start := node
+	center := 0
+	for center < radius && start.Prev != nil {
+		start = start.Prev
+		center++
+	}
+
+	songs := make([]*models.Song, 0, center+radius+1)
+	current := start
+	for i := 0; current != nil && i <= center+radius; i++ {
+		songs = append(songs, current.Song)
+		current = current.Next
+	}
+
+	return songs, center, nil
+}
+
+// GetPageAfter returns up to limit songs following the song with ID afterID, or from the start when afterID is empty
+// nextCursor is the ID of the last returned song when more songs follow, otherwise empty
+// Cursors stay valid while other songs are inserted or deleted, unlike index offsets
+// Time Complexity: O(k) where k is the limit, using the node index to find the cursor
+// Space Complexity: O(k)
+func (dll *DoublyLinkedList) GetPageAfter(afterID string, limit int) ([]*models.Song, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive: %d", limit)
+	}
+
+	current := dll.Head
+	if afterID != "" {
+		node, exists := dll.nodesByID[afterID]
+		if !exists {
+			return nil, "", fmt.Errorf("cursor song with ID %s not found", afterID)
 		}
+		current = node.Next
+	}
+
+	songs := make([]*models.Song, 0, min(limit, dll.Length))
+	for current != nil && len(songs) < limit {
+		songs = append(songs, current.Song)
 		current = current.Next
+	}
+
+	nextCursor := ""
+	if current != nil {
+		nextCursor = songs[len(songs)-1].ID
+	}
+	return songs, nextCursor, nil
+}
+
+// GetSongsRange returns up to count songs starting at index start, in playlist order
+// The walk starts from whichever end of the list is closer to the range; a start at or past
+// the end returns no songs
+// Time Complexity: O(min(start, n - start - count) + count)
+// Space Complexity: O(count)
+func (dll *DoublyLinkedList) GetSongsRange(start, count int) ([]*models.Song, error) {
+	if start < 0 {
+		return nil, fmt.Errorf("start cannot be negative: %d", start)
+	}
+	if count <= 0 {
+		return nil, fmt.Errorf("count must be positive: %d", count)
+	}
+	if start >= dll.Length {
+		return []*models.Song{}, nil
+	}
+
+	end := min(start+count, dll.Length) // Exclusive
+	songs := make([]*models.Song, end-start)
+	if start <= dll.Length-end {
+		current := dll.Head
+		for i := 0; i < start; i++ {
+			current = current.Next
+		}
+		for i := range songs {
+			songs[i] = current.Song
+			current = current.Next
+		}
+	} else {
+		current := dll.Tail
+		for i := dll.Length - 1; i >= end; i-- {
+			current = current.Prev
+		}
+		for i := len(songs) - 1; i >= 0; i-- {
+			songs[i] = current.Song
+			current = current.Prev
+		}
+	}
+	return songs, nil
+}
+
+// GetSongPositions returns the current index of every song keyed by ID
+// Useful for resolving many IDs to indices with a single traversal
+// Time Complexity: O(n)
+// Space Complexity: O(n)
+func (dll *DoublyLinkedList) GetSongPositions() map[string]int {
+	positions := make(map[string]int, dll.Length)
+	index := 0
+
+	for current := dll.Head; current != nil; current = current.Next {
+		positions[current.Song.ID] = index
 		index++
 	}
 
-	return -1, fmt.Errorf("song with ID %s not found", songID)
+	return positions
 }
 
 // String returns a string representation of the playlist
@@ -1,6 +1,8 @@
 package datastructures
 
 import (
+	"fmt"
+	"math"
 	"src/internal/models"
 	"testing"
 )
@@ -239,6 +241,50 @@ func TestDoublyLinkedList_MoveSong(t *testing.T) {
 	}
 }
 
+func TestDoublyLinkedList_MoveSongBoundaries(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     int
+		to       int
+		expected []string
+	}{
+		{"forward to last", 0, 3, []string{"2", "3", "4", "1"}},
+		{"forward by one", 1, 2, []string{"1", "3", "2", "4"}},
+		{"backward to first", 3, 0, []string{"4", "1", "2", "3"}},
+		{"backward by one", 2, 1, []string{"1", "3", "2", "4"}},
+		{"middle to last", 1, 3, []string{"1", "3", "4", "2"}},
+		{"middle to first", 2, 0, []string{"3", "1", "2", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dll := NewDoublyLinkedList()
+			for i := 1; i <= 4; i++ {
+				dll.AddSong(createTestSong(fmt.Sprintf("%d", i), fmt.Sprintf("Song %d", i), "Artist"))
+			}
+
+			if err := dll.MoveSong(tt.from, tt.to); err != nil {
+				t.Fatalf("MoveSong(%d, %d) error = %v, want nil", tt.from, tt.to, err)
+			}
+
+			for i, expectedID := range tt.expected {
+				song, _ := dll.GetSong(i)
+				if song.ID != expectedID {
+					t.Errorf("MoveSong(%d, %d) song at index %d = %v, want %v", tt.from, tt.to, i, song.ID, expectedID)
+				}
+			}
+
+			// Links must stay consistent in both directions
+			if dll.Head.Prev != nil || dll.Tail.Next != nil {
+				t.Error("Head.Prev and Tail.Next should be nil after move")
+			}
+			if dll.Tail.Song.ID != tt.expected[len(tt.expected)-1] {
+				t.Errorf("Tail = %v, want %v", dll.Tail.Song.ID, tt.expected[len(tt.expected)-1])
+			}
+		})
+	}
+}
+
 func TestDoublyLinkedList_ReversePlaylist(t *testing.T) {
 	dll := NewDoublyLinkedList()
 	songs := []*models.Song{
@@ -583,3 +629,278 @@ func TestDoublyLinkedList_EdgeCases(t *testing.T) {
 		t.Errorf("GetSong(8) from tail traversal failed")
 	}
 }
+
+func TestDoublyLinkedList_NodeIndex(t *testing.T) {
+	dll := NewDoublyLinkedList()
+	for i := 0; i < 5; i++ {
+		dll.AddSong(createTestSong(fmt.Sprintf("%d", i), fmt.Sprintf("Song %d", i), "Artist"))
+	}
+	dll.AddSongToBeginning(createTestSong("first", "First", "Artist"))
+	dll.AddSongAtIndex(createTestSong("mid", "Mid", "Artist"), 3)
+
+	assertPositions := func(stage string) {
+		t.Helper()
+		positions := dll.GetSongPositions()
+		if len(positions) != dll.Size() {
+			t.Errorf("%s: GetSongPositions() size = %d, want %d", stage, len(positions), dll.Size())
+		}
+		for i, song := range dll.ToSlice() {
+			index, err := dll.FindSongByID(song.ID)
+			if err != nil || index != i {
+				t.Errorf("%s: FindSongByID(%s) = %d, %v, want %d", stage, song.ID, index, err, i)
+			}
+			if positions[song.ID] != i {
+				t.Errorf("%s: GetSongPositions()[%s] = %d, want %d", stage, song.ID, positions[song.ID], i)
+			}
+			node, ok := dll.GetNodeByID(song.ID)
+			if !ok || node.Song != song {
+				t.Errorf("%s: GetNodeByID(%s) returned wrong node", stage, song.ID)
+			}
+		}
+	}
+
+	assertPositions("after add")
+
+	dll.DeleteSong(2)
+	assertPositions("after delete")
+
+	dll.MoveSong(0, dll.Size()-1)
+	assertPositions("after move")
+
+	dll.ReversePlaylist()
+	assertPositions("after reverse")
+
+	song, err := dll.DeleteSongByID("mid")
+	if err != nil || song.ID != "mid" {
+		t.Errorf("DeleteSongByID(mid) = %v, %v", song, err)
+	}
+	assertPositions("after delete by ID")
+
+	if _, err := dll.FindSongByID("mid"); err == nil {
+		t.Error("FindSongByID() should fail for deleted song")
+	}
+	if _, err := dll.DeleteSongByID("mid"); err == nil {
+		t.Error("DeleteSongByID() should fail for unknown song")
+	}
+
+	dll.Clear()
+	if _, ok := dll.GetNodeByID("first"); ok {
+		t.Error("GetNodeByID() should fail after Clear()")
+	}
+}
+
+func TestDoublyLinkedList_GetNeighbors(t *testing.T) {
+	dll := NewDoublyLinkedList()
+	for i := 0; i < 6; i++ {
+		dll.AddSong(createTestSong(fmt.Sprintf("%d", i), fmt.Sprintf("Song %d", i), "Artist"))
+	}
+
+	tests := []struct {
+		name       string
+		songID     string
+		radius     int
+		wantIDs    []string
+		wantCenter int
+	}{
+		{"middle", "3", 2, []string{"1", "2", "3", "4", "5"}, 2},
+		{"clamped at head", "0", 2, []string{"0", "1", "2"}, 0},
+		{"clamped at tail", "5", 2, []string{"3", "4", "5"}, 2},
+		{"zero radius", "2", 0, []string{"2"}, 0},
+		{"radius larger than list", "1", 10, []string{"0", "1", "2", "3", "4", "5"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			songs, center, err := dll.GetNeighbors(tt.songID, tt.radius)
+			if err != nil {
+				t.Fatalf("GetNeighbors() error = %v", err)
+			}
+			if center != tt.wantCenter {
+				t.Errorf("GetNeighbors() center = %d, want %d", center, tt.wantCenter)
+			}
+			if len(songs) != len(tt.wantIDs) {
+				t.Fatalf("GetNeighbors() returned %d songs, want %d", len(songs), len(tt.wantIDs))
+			}
+			for i, song := range songs {
+				if song.ID != tt.wantIDs[i] {
+					t.Errorf("GetNeighbors()[%d] = %s, want %s", i, song.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+
+	if _, _, err := dll.GetNeighbors("missing", 2); err == nil {
+		t.Error("GetNeighbors() should fail for unknown song")
+	}
+	if _, _, err := dll.GetNeighbors("0", -1); err == nil {
+		t.Error("GetNeighbors() should fail for negative radius")
+	}
+}
+
+func TestDoublyLinkedList_GetPageAfter(t *testing.T) {
+	dll := NewDoublyLinkedList()
+	for i := 0; i < 5; i++ {
+		dll.AddSong(createTestSong(fmt.Sprintf("%d", i), fmt.Sprintf("Song %d", i), "Artist"))
+	}
+
+	tests := []struct {
+		name     string
+		afterID  string
+		limit    int
+		wantIDs  []string
+		wantNext string
+	}{
+		{"start", "", 2, []string{"0", "1"}, "1"},
+		{"middle", "1", 2, []string{"2", "3"}, "3"},
+		{"last page", "3", 2, []string{"4"}, ""},
+		{"exact fit", "2", 2, []string{"3", "4"}, ""},
+		{"after tail", "4", 2, []string{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			songs, next, err := dll.GetPageAfter(tt.afterID, tt.limit)
+			if err != nil {
+				t.Fatalf("GetPageAfter() error = %v", err)
+			}
+			if next != tt.wantNext {
+				t.Errorf("GetPageAfter() next = %q, want %q", next, tt.wantNext)
+			}
+			if len(songs) != len(tt.wantIDs) {
+				t.Fatalf("GetPageAfter() returned %d songs, want %d", len(songs), len(tt.wantIDs))
+			}
+			for i, song := range songs {
+				if song.ID != tt.wantIDs[i] {
+					t.Errorf("GetPageAfter()[%d] = %s, want %s", i, song.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+
+	// Deleting a song before the cursor doesn't shift the next page
+	dll.DeleteSongByID("0")
+	if songs, _, _ := dll.GetPageAfter("1", 2); len(songs) != 2 || songs[0].ID != "2" {
+		t.Errorf("Expected page after 1 to start at 2 after a delete, got %v", songs)
+	}
+
+	// A deleted cursor can't be resumed
+	dll.DeleteSongByID("1")
+	if _, _, err := dll.GetPageAfter("1", 2); err == nil {
+		t.Error("GetPageAfter() should fail for a deleted cursor")
+	}
+	if _, _, err := dll.GetPageAfter("", 0); err == nil {
+		t.Error("GetPageAfter() should fail for a non-positive limit")
+	}
+}
+
+func TestDoublyLinkedList_GetSongsRange(t *testing.T) {
+	dll := NewDoublyLinkedList()
+	for i := 0; i < 6; i++ {
+		dll.AddSong(createTestSong(fmt.Sprintf("%d", i), fmt.Sprintf("Song %d", i), "Artist"))
+	}
+
+	tests := []struct {
+		name    string
+		start   int
+		count   int
+		wantIDs []string
+	}{
+		{"from head", 0, 2, []string{"0", "1"}},
+		{"near tail", 3, 2, []string{"3", "4"}},
+		{"runs past tail", 4, 5, []string{"4", "5"}},
+		{"whole list", 0, 6, []string{"0", "1", "2", "3", "4", "5"}},
+		{"past tail", 6, 2, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			songs, err := dll.GetSongsRange(tt.start, tt.count)
+			if err != nil {
+				t.Fatalf("GetSongsRange() error = %v", err)
+			}
+			if len(songs) != len(tt.wantIDs) {
+				t.Fatalf("GetSongsRange() returned %d songs, want %d", len(songs), len(tt.wantIDs))
+			}
+			for i, song := range songs {
+				if song.ID != tt.wantIDs[i] {
+					t.Errorf("GetSongsRange()[%d] = %s, want %s", i, song.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+
+	if _, err := dll.GetSongsRange(-1, 2); err == nil {
+		t.Error("GetSongsRange() should fail for a negative start")
+	}
+	if _, err := dll.GetSongsRange(0, 0); err == nil {
+		t.Error("GetSongsRange() should fail for a non-positive count")
+	}
+}
+
+func TestDoublyLinkedList_MoveBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   int
+		count   int
+		to      int
+		wantIDs string
+	}{
+		{"forward", 1, 2, 4, "03124"},
+		{"backward", 3, 2, 1, "03412"},
+		{"to start", 2, 3, 0, "23401"},
+		{"to end", 0, 2, 5, "23401"},
+		{"whole list", 0, 5, 5, "01234"},
+		{"no-op before block", 1, 2, 1, "01234"},
+		{"no-op after block", 1, 2, 3, "01234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dll := NewDoublyLinkedList()
+			for i := 0; i < 5; i++ {
+				dll.AddSong(createTestSong(fmt.Sprintf("%d", i), fmt.Sprintf("Song %d", i), "Artist"))
+			}
+
+			if err := dll.MoveBlock(tt.start, tt.count, tt.to); err != nil {
+				t.Fatalf("MoveBlock() error = %v", err)
+			}
+
+			got := ""
+			for _, song := range dll.ToSlice() {
+				got += song.ID
+			}
+			if got != tt.wantIDs {
+				t.Errorf("MoveBlock() order = %s, want %s", got, tt.wantIDs)
+			}
+
+			// Walking backwards from the tail must give the same order reversed
+			back := ""
+			for node := dll.Tail; node != nil; node = node.Prev {
+				back = node.Song.ID + back
+			}
+			if back != tt.wantIDs || dll.Head.Prev != nil || dll.Tail.Next != nil {
+				t.Errorf("MoveBlock() broke the back links, got %s", back)
+			}
+		})
+	}
+}
+
+func TestDoublyLinkedList_MoveBlockErrors(t *testing.T) {
+	dll := NewDoublyLinkedList()
+	for i := 0; i < 5; i++ {
+		dll.AddSong(createTestSong(fmt.Sprintf("%d", i), fmt.Sprintf("Song %d", i), "Artist"))
+	}
+
+	for _, args := range [][3]int{
+		{0, 0, 3},           // Empty block
+		{-1, 2, 3},          // Start before the list
+		{4, 2, 0},           // Block past the end
+		{1, math.MaxInt, 0}, // Block size that would overflow the end index
+		{0, 2, 6},           // Target past the end
+		{1, 3, 2},           // Target inside the block
+	} {
+		if err := dll.MoveBlock(args[0], args[1], args[2]); err == nil {
+			t.Errorf("MoveBlock(%d, %d, %d) should fail", args[0], args[1], args[2])
+		}
+	}
+}
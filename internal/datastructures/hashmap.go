@@ -44,6 +44,13 @@ func NewSongHashMap(capacity int) *SongHashMap {
 // Time Complexity: O(k) where k is the length of the key
 // Space Complexity: O(1)
 func (shm *SongHashMap) hash(key string) int {
+	return djb2(key, shm.Capacity)
+}
+
+// djb2 hashes key into one of capacity buckets
+// Time Complexity: O(k) where k is the length of the key
+// Space Complexity: O(1)
+func djb2(key string, capacity int) int {
 	hash := 5381
 	for _, c := range key {
 		hash = ((hash << 5) + hash) + int(c) // hash * 33 + c
@@ -51,7 +58,7 @@ func (shm *SongHashMap) hash(key string) int {
 	if hash < 0 {
 		hash = -hash
 	}
-	return hash % shm.Capacity
+	return hash % capacity
 }
 
 // Put inserts or updates a song in the hash map
@@ -353,20 +360,38 @@ func (shm *SongHashMap) Clear() {
 // Time Complexity: O(n)
 // Space Complexity: O(new_capacity)
 func (shm *SongHashMap) resize() {
-	oldBuckets := shm.Buckets
-	oldCapacity := shm.Capacity
-
 	// Double the capacity
-	shm.Capacity *= 2
-	shm.Buckets = make([]*HashMapEntry, shm.Capacity)
-	shm.Size = 0
+	shm.rehash(shm.Capacity * 2)
+}
 
-	// Rehash all entries
-	for i := 0; i < oldCapacity; i++ {
-		entry := oldBuckets[i]
+// ShrinkToFit reduces the capacity to one bucket per entry, but never below minCapacity
+// Returns the resulting capacity; a map that is already small enough is left unchanged
+// Time Complexity: O(n + capacity)
+// Space Complexity: O(new_capacity)
+func (shm *SongHashMap) ShrinkToFit(minCapacity int) int {
+	target := max(shm.Size, minCapacity, 1)
+	if target < shm.Capacity {
+		shm.rehash(target)
+	}
+	return shm.Capacity
+}
+
+// rehash moves every entry into a new bucket array of the given capacity
+// Entries are rehashed by their existing key, so title-indexed maps stay keyed by title
+// Time Complexity: O(n + capacity)
+// Space Complexity: O(new_capacity)
+func (shm *SongHashMap) rehash(capacity int) {
+	oldBuckets := shm.Buckets
+	shm.Capacity = capacity
+	shm.Buckets = make([]*HashMapEntry, capacity)
+
+	for _, entry := range oldBuckets {
 		for entry != nil {
-			shm.Put(entry.Value)
-			entry = entry.Next
+			next := entry.Next
+			index := shm.hash(entry.Key)
+			entry.Next = shm.Buckets[index]
+			shm.Buckets[index] = entry
+			entry = next
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package datastructures
 
 import (
+	"fmt"
 	"src/internal/models"
 	"testing"
 )
@@ -568,6 +569,26 @@ func TestSongHashMap_resize(t *testing.T) {
 	}
 }
 
+func TestSongHashMap_resizeByTitle(t *testing.T) {
+	hashMap := NewSongHashMap(4)
+
+	for i := 0; i < 9; i++ {
+		hashMap.PutByTitle(createHashMapTestSong(string(rune('1'+i)), "Title "+string(rune('a'+i)), "Artist"))
+	}
+
+	if hashMap.GetCapacity() != 8 || hashMap.GetSize() != 9 {
+		t.Errorf("resize() capacity %d size %d, want 8 and 9", hashMap.GetCapacity(), hashMap.GetSize())
+	}
+
+	// Entries keep their title keys after rehashing
+	for i := 0; i < 9; i++ {
+		title := "Title " + string(rune('a'+i))
+		if _, err := hashMap.GetByTitle(title); err != nil {
+			t.Errorf("resize() title %q not retrievable after resize: %v", title, err)
+		}
+	}
+}
+
 func TestSongHashMap_GetBucketDistribution(t *testing.T) {
 	hashMap := NewSongHashMap(8)
 
@@ -809,3 +830,28 @@ func TestSongHashMap_EdgeCases(t *testing.T) {
 		t.Errorf("EdgeCases: unicode characters failed: %v", err)
 	}
 }
+
+func TestSongHashMap_ShrinkToFit(t *testing.T) {
+	hashMap := NewSongHashMap(4)
+	for i := 0; i < 40; i++ {
+		hashMap.Put(createHashMapTestSong(fmt.Sprintf("id-%d", i), "Song", "Artist"))
+	}
+	for i := 5; i < 40; i++ {
+		hashMap.Delete(fmt.Sprintf("id-%d", i))
+	}
+	grown := hashMap.GetCapacity()
+
+	if capacity := hashMap.ShrinkToFit(2); capacity != 5 || capacity >= grown {
+		t.Errorf("ShrinkToFit() capacity = %d, want 5 (was %d)", capacity, grown)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := hashMap.Get(fmt.Sprintf("id-%d", i)); err != nil {
+			t.Errorf("ShrinkToFit() lost id-%d: %v", i, err)
+		}
+	}
+
+	// The minimum capacity wins over a smaller size, and growing never happens
+	if capacity := hashMap.ShrinkToFit(16); capacity != 5 {
+		t.Errorf("ShrinkToFit() should not grow the map, got capacity %d", capacity)
+	}
+}
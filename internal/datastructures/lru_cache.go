@@ -0,0 +1,193 @@
+package datastructures
+
+// lruNode is an entry in the cache's recency list
+// Time Complexity: O(1) for all field operations
+// Space Complexity: O(1) per node
+type lruNode[K comparable, V any] struct {
+	key   K
+	value V
+	prev  *lruNode[K, V]
+	next  *lruNode[K, V]
+}
+
+// LRUCache is a bounded key-value cache that evicts the least recently used entry when full
+// A doubly linked list keeps entries in recency order (head = most recent) and a map gives O(1) lookup
+// Time Complexity: O(1) average for get, put and remove operations
+// Space Complexity: O(c) where c is the capacity
+type LRUCache[K comparable, V any] struct {
+	head     *lruNode[K, V]
+	tail     *lruNode[K, V]
+	capacity int
+	entries  map[K]*lruNode[K, V]
+
+	// Called with each entry dropped to make room, not for Remove or Clear
+	onEvict func(K, V)
+}
+
+// NewLRUCache creates an empty cache holding at most capacity entries
+// onEvict may be nil
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewLRUCache[K comparable, V any](capacity int, onEvict func(K, V)) *LRUCache[K, V] {
+	if capacity <= 0 {
+		capacity = 64 // Default capacity
+	}
+	return &LRUCache[K, V]{
+		capacity: capacity,
+		entries:  make(map[K]*lruNode[K, V]),
+		onEvict:  onEvict,
+	}
+}
+
+// Get returns the value for a key and marks it as most recently used
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) Get(key K) (V, bool) {
+	node, exists := lru.entries[key]
+	if !exists {
+		var zero V
+		return zero, false
+	}
+
+	lru.moveToFront(node)
+	return node.value, true
+}
+
+// Peek returns the value for a key without changing its recency
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) Peek(key K) (V, bool) {
+	node, exists := lru.entries[key]
+	if !exists {
+		var zero V
+		return zero, false
+	}
+	return node.value, true
+}
+
+// Put stores a value as the most recently used entry, evicting the least recently used one when full
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) Put(key K, value V) {
+	if node, exists := lru.entries[key]; exists {
+		node.value = value
+		lru.moveToFront(node)
+		return
+	}
+
+	if len(lru.entries) >= lru.capacity {
+		lru.evict()
+	}
+
+	node := &lruNode[K, V]{key: key, value: value}
+	lru.pushFront(node)
+	lru.entries[key] = node
+}
+
+// Remove deletes a key and reports whether it was present
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) Remove(key K) bool {
+	node, exists := lru.entries[key]
+	if !exists {
+		return false
+	}
+
+	lru.unlink(node)
+	delete(lru.entries, key)
+	return true
+}
+
+// Len returns the number of cached entries
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) Len() int {
+	return len(lru.entries)
+}
+
+// Capacity returns the maximum number of cached entries
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) Capacity() int {
+	return lru.capacity
+}
+
+// Keys returns the cached keys from most to least recently used
+// Time Complexity: O(n)
+// Space Complexity: O(n)
+func (lru *LRUCache[K, V]) Keys() []K {
+	keys := make([]K, 0, len(lru.entries))
+	for node := lru.head; node != nil; node = node.next {
+		keys = append(keys, node.key)
+	}
+	return keys
+}
+
+// Clear removes every entry without calling the eviction callback
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) Clear() {
+	lru.head = nil
+	lru.tail = nil
+	lru.entries = make(map[K]*lruNode[K, V])
+}
+
+// pushFront links a detached node in as the most recently used entry
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) pushFront(node *lruNode[K, V]) {
+	node.prev = nil
+	node.next = lru.head
+	if lru.head != nil {
+		lru.head.prev = node
+	}
+	lru.head = node
+	if lru.tail == nil {
+		lru.tail = node
+	}
+}
+
+// unlink detaches a node from the recency list
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) unlink(node *lruNode[K, V]) {
+	if node.prev != nil {
+		node.prev.next = node.next
+	} else {
+		lru.head = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	} else {
+		lru.tail = node.prev
+	}
+	node.prev = nil
+	node.next = nil
+}
+
+// moveToFront marks a cached node as most recently used
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) moveToFront(node *lruNode[K, V]) {
+	if lru.head == node {
+		return
+	}
+	lru.unlink(node)
+	lru.pushFront(node)
+}
+
+// evict drops the least recently used entry and reports it to the callback
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (lru *LRUCache[K, V]) evict() {
+	node := lru.tail
+	if node == nil {
+		return
+	}
+
+	lru.unlink(node)
+	delete(lru.entries, node.key)
+	if lru.onEvict != nil {
+		lru.onEvict(node.key, node.value)
+	}
+}
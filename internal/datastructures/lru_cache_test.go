@@ -0,0 +1,134 @@
+package datastructures
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestNewLRUCache(t *testing.T) {
+	lru := NewLRUCache[string, int](0, nil)
+
+	if lru.Capacity() != 64 {
+		t.Errorf("Expected default capacity 64, got %d", lru.Capacity())
+	}
+	if lru.Len() != 0 || len(lru.Keys()) != 0 {
+		t.Errorf("Expected empty cache, got %d entries", lru.Len())
+	}
+	if value, ok := lru.Get("missing"); ok || value != 0 {
+		t.Errorf("Get() on empty cache = %d, %v, want zero value and false", value, ok)
+	}
+}
+
+func TestLRUCache_GetPut(t *testing.T) {
+	lru := NewLRUCache[string, int](3, nil)
+
+	lru.Put("a", 1)
+	lru.Put("b", 2)
+	lru.Put("a", 10) // Update moves a to the front without growing
+
+	if value, ok := lru.Get("a"); !ok || value != 10 {
+		t.Errorf("Get(a) = %d, %v, want 10, true", value, ok)
+	}
+	if lru.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", lru.Len())
+	}
+
+	if !lru.Remove("a") || lru.Remove("a") {
+		t.Error("Remove(a) should succeed once")
+	}
+	if _, ok := lru.Get("a"); ok || lru.Len() != 1 {
+		t.Error("a should be gone after Remove")
+	}
+}
+
+func TestLRUCache_EvictionOrder(t *testing.T) {
+	var evicted []string
+	lru := NewLRUCache[string, int](3, func(key string, value int) {
+		evicted = append(evicted, key)
+	})
+
+	lru.Put("a", 1)
+	lru.Put("b", 2)
+	lru.Put("c", 3)
+	lru.Get("a")     // Order is now a, c, b
+	lru.Peek("b")    // Peek doesn't refresh b
+	lru.Put("d", 4)  // Evicts b
+	lru.Put("c", 30) // Refreshes c, order is c, d, a
+	lru.Put("e", 5)  // Evicts a
+
+	if len(evicted) != 2 || evicted[0] != "b" || evicted[1] != "a" {
+		t.Errorf("Expected evictions b, a, got %v", evicted)
+	}
+
+	want := []string{"e", "c", "d"}
+	keys := lru.Keys()
+	if len(keys) != len(want) {
+		t.Fatalf("Expected keys %v, got %v", want, keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("Keys()[%d] = %s, want %s", i, keys[i], want[i])
+		}
+	}
+}
+
+func TestLRUCache_RemoveAndClearDontCallback(t *testing.T) {
+	evictions := 0
+	lru := NewLRUCache[int, string](2, func(int, string) { evictions++ })
+
+	lru.Put(1, "one")
+	lru.Put(2, "two")
+	lru.Remove(1)
+	lru.Clear()
+
+	if evictions != 0 {
+		t.Errorf("Remove and Clear should not call the eviction callback, got %d calls", evictions)
+	}
+	if lru.Len() != 0 || len(lru.Keys()) != 0 {
+		t.Error("Cache should be empty after Clear()")
+	}
+
+	// Still usable after Clear
+	lru.Put(3, "three")
+	if value, ok := lru.Get(3); !ok || value != "three" {
+		t.Errorf("Get(3) = %s, %v after Clear", value, ok)
+	}
+}
+
+func TestLRUCache_SingleEntry(t *testing.T) {
+	lru := NewLRUCache[string, int](1, nil)
+
+	lru.Put("a", 1)
+	lru.Put("b", 2)
+
+	if _, ok := lru.Get("a"); ok {
+		t.Error("a should be evicted from a single-entry cache")
+	}
+	if keys := lru.Keys(); len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("Expected only b, got %v", keys)
+	}
+}
+
+// Benchmark tests
+func BenchmarkLRUCache_Put(b *testing.B) {
+	lru := NewLRUCache[int, int](1000, nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.Put(i, i) // Evicts once full
+	}
+}
+
+func BenchmarkLRUCache_Get(b *testing.B) {
+	lru := NewLRUCache[string, int](1000, nil)
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		lru.Put(keys[i], i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.Get(keys[i%len(keys)])
+	}
+}
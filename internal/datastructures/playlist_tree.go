@@ -107,6 +107,24 @@ func (node *PlaylistTreeNode) GetPath() []string {
 	return path
 }
 
+// UnknownLabels holds the fallback node names used when a song has an empty category
+type UnknownLabels struct {
+	Genre    string
+	Subgenre string
+	Mood     string
+	Artist   string
+}
+
+// DefaultUnknownLabels returns the English fallback labels
+func DefaultUnknownLabels() UnknownLabels {
+	return UnknownLabels{
+		Genre:    "Unknown Genre",
+		Subgenre: "Unknown Subgenre",
+		Mood:     "Unknown Mood",
+		Artist:   "Unknown Artist",
+	}
+}
+
 // PlaylistExplorerTree represents the hierarchical song organization
 // Structure: Genre → Subgenre → Mood → Artist → Songs
 // Time Complexity: O(1) for root access, O(d) for traversal where d is depth
@@ -115,15 +133,39 @@ type PlaylistExplorerTree struct {
 	Root       *PlaylistTreeNode
 	TotalSongs int
 	Stats      map[string]int // Statistics for each level
+	Labels     UnknownLabels  // Fallback names for empty categories
 }
 
 // NewPlaylistExplorerTree creates a new playlist explorer tree
 // Time Complexity: O(1)
 // Space Complexity: O(1)
 func NewPlaylistExplorerTree() *PlaylistExplorerTree {
+	return NewPlaylistExplorerTreeWithLabels(DefaultUnknownLabels())
+}
+
+// NewPlaylistExplorerTreeWithLabels creates a new playlist explorer tree with custom fallback labels
+// Empty labels fall back to the defaults
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewPlaylistExplorerTreeWithLabels(labels UnknownLabels) *PlaylistExplorerTree {
+	defaults := DefaultUnknownLabels()
+	if labels.Genre == "" {
+		labels.Genre = defaults.Genre
+	}
+	if labels.Subgenre == "" {
+		labels.Subgenre = defaults.Subgenre
+	}
+	if labels.Mood == "" {
+		labels.Mood = defaults.Mood
+	}
+	if labels.Artist == "" {
+		labels.Artist = defaults.Artist
+	}
+
 	return &PlaylistExplorerTree{
 		Root:       NewPlaylistTreeNode("Root", GenreNode, nil),
 		TotalSongs: 0,
+		Labels:     labels,
 		Stats: map[string]int{
 			"genres":    0,
 			"subgenres": 0,
@@ -149,16 +191,16 @@ func (pet *PlaylistExplorerTree) AddSong(song *models.Song) {
 
 	// Handle empty categories
 	if genre == "" {
-		genre = "Unknown Genre"
+		genre = pet.Labels.Genre
 	}
 	if subgenre == "" {
-		subgenre = "Unknown Subgenre"
+		subgenre = pet.Labels.Subgenre
 	}
 	if mood == "" {
-		mood = "Unknown Mood"
+		mood = pet.Labels.Mood
 	}
 	if artist == "" {
-		artist = "Unknown Artist"
+		artist = pet.Labels.Artist
 	}
 
 	// Navigate/create the hierarchy: Root -> Genre -> Subgenre -> Mood -> Artist
@@ -415,63 +457,148 @@ func (pet *PlaylistExplorerTree) FindSongPath(songID string) ([]string, error) {
 // Time Complexity: O(n) worst case to find the song
 // Space Complexity: O(d) for recursion stack
 func (pet *PlaylistExplorerTree) RemoveSong(songID string) error {
-	var removed bool
+	var artistNode *PlaylistTreeNode
 
 	pet.DepthFirstSearch(func(node *PlaylistTreeNode) {
-		if node.NodeType == ArtistNode && !removed {
+		if node.NodeType == ArtistNode && artistNode == nil {
 			for i, song := range node.Songs {
 				if song.ID == songID {
 					// Remove song from slice
 					node.Songs = append(node.Songs[:i], node.Songs[i+1:]...)
 					pet.TotalSongs--
-					removed = true
-
-					// If artist has no more songs, consider removing the artist node
-					// (Implementation could be extended to clean up empty branches)
+					artistNode = node
 					return
 				}
 			}
 		}
 	})
 
-	if !removed {
+	if artistNode == nil {
 		return fmt.Errorf("song with ID %s not found", songID)
 	}
 
+	pet.pruneEmptyBranch(artistNode)
 	return nil
 }
 
+// pruneEmptyBranch removes a node left without songs or children, then repeats for its parent
+// Keeps GetGenres and friends from listing categories that no longer hold any songs
+// Time Complexity: O(d) where d is the tree depth
+// Space Complexity: O(1)
+func (pet *PlaylistExplorerTree) pruneEmptyBranch(node *PlaylistTreeNode) {
+	for node != nil && node.Parent != nil && len(node.Songs) == 0 && !node.HasChildren() {
+		pet.detach(node)
+		node = node.Parent
+	}
+}
+
+// detach removes a node from its parent and updates the level statistics
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pet *PlaylistExplorerTree) detach(node *PlaylistTreeNode) {
+	delete(node.Parent.Children, node.Name)
+	switch node.NodeType {
+	case GenreNode:
+		pet.Stats["genres"]--
+	case SubgenreNode:
+		pet.Stats["subgenres"]--
+	case MoodNode:
+		pet.Stats["moods"]--
+	case ArtistNode:
+		pet.Stats["artists"]--
+	}
+}
+
+// Compact removes every branch left without songs and trims each artist's song slice to its length
+// Returns the number of nodes removed
+// Time Complexity: O(t) where t is the number of tree nodes
+// Space Complexity: O(d) for recursion stack where d is depth
+func (pet *PlaylistExplorerTree) Compact() int {
+	removed := 0
+	var compact func(node *PlaylistTreeNode)
+	compact = func(node *PlaylistTreeNode) {
+		for _, child := range node.Children {
+			compact(child)
+		}
+
+		if len(node.Songs) < cap(node.Songs) {
+			node.Songs = append(make([]*models.Song, 0, len(node.Songs)), node.Songs...)
+		}
+		if node.Parent != nil && len(node.Songs) == 0 && !node.HasChildren() {
+			pet.detach(node)
+			removed++
+		}
+	}
+	compact(pet.Root)
+	return removed
+}
+
+// NodeCount returns the number of category nodes in the tree, excluding the root
+// Time Complexity: O(t) where t is the number of tree nodes
+// Space Complexity: O(d) for recursion stack where d is depth
+func (pet *PlaylistExplorerTree) NodeCount() int {
+	count := -1 // Don't count the root
+	pet.DepthFirstSearch(func(*PlaylistTreeNode) {
+		count++
+	})
+	return count
+}
+
+// MaxTreeDepth is the depth of the full explorer tree: genre, subgenre, mood, artist
+const MaxTreeDepth = 4
+
 // GetTreeStructure returns a structured representation of the tree
 // Time Complexity: O(n) where n is the total number of nodes
 // Space Complexity: O(n)
 func (pet *PlaylistExplorerTree) GetTreeStructure() map[string]interface{} {
-	structure := make(map[string]interface{})
-
-	for genreName, genreNode := range pet.Root.Children {
-		genreMap := make(map[string]interface{})
-
-		for subgenreName, subgenreNode := range genreNode.Children {
-			subgenreMap := make(map[string]interface{})
-
-			for moodName, moodNode := range subgenreNode.Children {
-				moodMap := make(map[string]interface{})
+	return pet.GetTreeStructureDepth(MaxTreeDepth)
+}
 
-				for artistName, artistNode := range moodNode.Children {
-					moodMap[artistName] = len(artistNode.Songs)
-				}
+// GetTreeStructureDepth returns nested maps limited to maxDepth levels below the root
+// Depth 1 is genres only and MaxTreeDepth is the full genre → subgenre → mood → artist tree
+// The deepest included level maps each name to the number of songs beneath it
+// maxDepth is clamped to the range 1..MaxTreeDepth
+// Time Complexity: O(n) where n is the total number of nodes
+// Space Complexity: O(m) where m is the number of nodes within maxDepth
+func (pet *PlaylistExplorerTree) GetTreeStructureDepth(maxDepth int) map[string]interface{} {
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+	if maxDepth > MaxTreeDepth {
+		maxDepth = MaxTreeDepth
+	}
 
-				subgenreMap[moodName] = moodMap
-			}
+	return pet.buildStructure(pet.Root, maxDepth)
+}
 
-			genreMap[subgenreName] = subgenreMap
+// buildStructure recursively maps a node's children, stopping at song counts once depth runs out
+// Time Complexity: O(n) where n is the number of nodes under node
+// Space Complexity: O(m) where m is the number of nodes within depth
+func (pet *PlaylistExplorerTree) buildStructure(node *PlaylistTreeNode, depth int) map[string]interface{} {
+	structure := make(map[string]interface{}, len(node.Children))
+
+	for childName, child := range node.Children {
+		if depth == 1 {
+			structure[childName] = countSongs(child)
+		} else {
+			structure[childName] = pet.buildStructure(child, depth-1)
 		}
-
-		structure[genreName] = genreMap
 	}
 
 	return structure
 }
 
+// countSongs returns the number of songs stored at or below a node
+// Time Complexity: O(n) where n is the number of nodes under node
+// Space Complexity: O(h) for the recursion stack where h is the tree height
+func countSongs(node *PlaylistTreeNode) int {
+	count := len(node.Songs)
+	for _, child := range node.Children {
+		count += countSongs(child)
+	}
+	return count
+}
+
 // String returns a string representation of the tree
 // Time Complexity: O(n)
 // Space Complexity: O(n)
@@ -745,3 +745,152 @@ func BenchmarkFindSongPath(b *testing.B) {
 		tree.FindSongPath(songIDs[i%len(songIDs)])
 	}
 }
+
+func TestPlaylistExplorerTree_CustomUnknownLabels(t *testing.T) {
+	tree := NewPlaylistExplorerTreeWithLabels(UnknownLabels{
+		Genre:    "Género desconocido",
+		Subgenre: "Subgénero desconocido",
+		Mood:     "Ánimo desconocido",
+	})
+
+	song := models.NewSong("1", "Canción", "", "Álbum", "", "", "", 200, 120)
+	tree.AddSong(song)
+
+	genres := tree.GetGenres()
+	if len(genres) != 1 || genres[0] != "Género desconocido" {
+		t.Errorf("Expected custom genre label, got %v", genres)
+	}
+
+	subgenres := tree.GetSubgenres("Género desconocido")
+	if len(subgenres) != 1 || subgenres[0] != "Subgénero desconocido" {
+		t.Errorf("Expected custom subgenre label, got %v", subgenres)
+	}
+
+	moods := tree.GetMoods("Género desconocido", "Subgénero desconocido")
+	if len(moods) != 1 || moods[0] != "Ánimo desconocido" {
+		t.Errorf("Expected custom mood label, got %v", moods)
+	}
+
+	// Labels left empty fall back to the defaults
+	artists := tree.GetArtists("Género desconocido", "Subgénero desconocido", "Ánimo desconocido")
+	if len(artists) != 1 || artists[0] != "Unknown Artist" {
+		t.Errorf("Expected default artist label, got %v", artists)
+	}
+
+	// Default tree keeps the original labels
+	defaultTree := NewPlaylistExplorerTree()
+	defaultTree.AddSong(models.NewSong("2", "Song", "Artist", "Album", "", "", "", 200, 120))
+	if genres := defaultTree.GetGenres(); len(genres) != 1 || genres[0] != "Unknown Genre" {
+		t.Errorf("Expected default genre label, got %v", genres)
+	}
+}
+
+func TestGetTreeStructureDepth(t *testing.T) {
+	tree := NewPlaylistExplorerTree()
+	tree.AddSong(createPlaylistTestSong("1", "Song 1", "Artist 1", "Rock", "Alternative", "Energetic"))
+	tree.AddSong(createPlaylistTestSong("2", "Song 2", "Artist 2", "Rock", "Alternative", "Energetic"))
+	tree.AddSong(createPlaylistTestSong("3", "Song 3", "Artist 1", "Rock", "Grunge", "Dark"))
+	tree.AddSong(createPlaylistTestSong("4", "Song 4", "Artist 1", "Pop", "Mainstream", "Happy"))
+
+	// Depth 1: genres mapped to song counts
+	genres := tree.GetTreeStructureDepth(1)
+	if len(genres) != 2 || genres["Rock"] != 3 || genres["Pop"] != 1 {
+		t.Errorf("Depth 1 = %v, want Rock:3 Pop:1", genres)
+	}
+
+	// Depth 2: genres → subgenres → counts
+	subgenres := tree.GetTreeStructureDepth(2)
+	rock := subgenres["Rock"].(map[string]interface{})
+	if len(rock) != 2 || rock["Alternative"] != 2 || rock["Grunge"] != 1 {
+		t.Errorf("Depth 2 Rock = %v, want Alternative:2 Grunge:1", rock)
+	}
+
+	// Depth 3: genres → subgenres → moods → counts
+	moods := tree.GetTreeStructureDepth(3)
+	alternative := moods["Rock"].(map[string]interface{})["Alternative"].(map[string]interface{})
+	if len(alternative) != 1 || alternative["Energetic"] != 2 {
+		t.Errorf("Depth 3 Rock/Alternative = %v, want Energetic:2", alternative)
+	}
+
+	// Depth 4: the full tree down to artist counts, same as GetTreeStructure
+	full := tree.GetTreeStructureDepth(4)
+	energetic := full["Rock"].(map[string]interface{})["Alternative"].(map[string]interface{})["Energetic"].(map[string]interface{})
+	if len(energetic) != 2 || energetic["Artist 1"] != 1 || energetic["Artist 2"] != 1 {
+		t.Errorf("Depth 4 Rock/Alternative/Energetic = %v, want Artist 1:1 Artist 2:1", energetic)
+	}
+	if fmt.Sprint(full) != fmt.Sprint(tree.GetTreeStructure()) {
+		t.Error("GetTreeStructure() should match depth 4")
+	}
+
+	// Out-of-range depths are clamped
+	if fmt.Sprint(tree.GetTreeStructureDepth(0)) != fmt.Sprint(genres) {
+		t.Error("Depth 0 should clamp to genres only")
+	}
+	if fmt.Sprint(tree.GetTreeStructureDepth(10)) != fmt.Sprint(full) {
+		t.Error("Depth 10 should clamp to the full tree")
+	}
+
+	if empty := NewPlaylistExplorerTree().GetTreeStructureDepth(2); len(empty) != 0 {
+		t.Errorf("Expected empty structure for empty tree, got %v", empty)
+	}
+}
+
+func TestRemoveSong_PrunesEmptyBranches(t *testing.T) {
+	tree := NewPlaylistExplorerTree()
+	tree.AddSong(createPlaylistTestSong("1", "Song 1", "Artist 1", "Rock", "Alternative", "Energetic"))
+	tree.AddSong(createPlaylistTestSong("2", "Song 2", "Artist 2", "Rock", "Alternative", "Energetic"))
+	tree.AddSong(createPlaylistTestSong("3", "Song 3", "Artist 3", "Jazz", "Smooth", "Relaxed"))
+
+	// Removing one of two artists under a mood only drops the artist
+	tree.RemoveSong("1")
+	if artists := tree.GetArtists("Rock", "Alternative", "Energetic"); len(artists) != 1 || artists[0] != "Artist 2" {
+		t.Errorf("Expected only Artist 2 left, got %v", artists)
+	}
+
+	// Removing the last song in a genre drops the whole branch
+	tree.RemoveSong("3")
+	if genres := tree.GetGenres(); len(genres) != 1 || genres[0] != "Rock" {
+		t.Errorf("Expected only Rock left, got %v", genres)
+	}
+
+	want := map[string]int{"genres": 1, "subgenres": 1, "moods": 1, "artists": 1}
+	for key, count := range want {
+		if tree.Stats[key] != count {
+			t.Errorf("Stats[%s] = %d, want %d", key, tree.Stats[key], count)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	tree := NewPlaylistExplorerTree()
+	tree.AddSong(createPlaylistTestSong("1", "Song 1", "Artist 1", "Rock", "Alternative", "Energetic"))
+	tree.AddSong(createPlaylistTestSong("2", "Song 2", "Artist 2", "Rock", "Alternative", "Energetic"))
+	tree.AddSong(createPlaylistTestSong("3", "Song 3", "Artist 3", "Jazz", "Smooth", "Relaxed"))
+	if nodes := tree.NodeCount(); nodes != 9 {
+		t.Fatalf("Expected 9 nodes, got %d", nodes)
+	}
+
+	// Empty songs directly, leaving branches behind as older trees could
+	tree.Root.GetChild("Jazz").GetChild("Smooth").GetChild("Relaxed").GetChild("Artist 3").Songs = nil
+	tree.Root.GetChild("Rock").GetChild("Alternative").GetChild("Energetic").GetChild("Artist 1").Songs = nil
+
+	if removed := tree.Compact(); removed != 5 {
+		t.Errorf("Expected 5 empty nodes removed, got %d", removed)
+	}
+	if nodes := tree.NodeCount(); nodes != 4 {
+		t.Errorf("Expected 4 nodes left, got %d", nodes)
+	}
+	if genres := tree.GetGenres(); len(genres) != 1 || genres[0] != "Rock" {
+		t.Errorf("Expected only Rock left, got %v", genres)
+	}
+
+	want := map[string]int{"genres": 1, "subgenres": 1, "moods": 1, "artists": 1}
+	for key, count := range want {
+		if tree.Stats[key] != count {
+			t.Errorf("Stats[%s] = %d, want %d", key, tree.Stats[key], count)
+		}
+	}
+	if songs := tree.GetSongs("Rock", "Alternative", "Energetic", "Artist 2"); len(songs) != 1 {
+		t.Errorf("Expected Song 2 to remain, got %v", songs)
+	}
+}
@@ -0,0 +1,160 @@
+package datastructures
+
+import (
+	"fmt"
+	"src/internal/models"
+)
+
+// PlayQueueNode represents a node in the play queue
+// Time Complexity: O(1) for all field operations
+// Space Complexity: O(1) per node
+type PlayQueueNode struct {
+	Song *models.Song
+	Next *PlayQueueNode
+}
+
+// PlayQueue represents a FIFO queue of songs waiting to be played
+// A per-ID count keeps membership checks O(1) even when a song is queued more than once
+// Time Complexity: O(1) for enqueue, dequeue, peek and contains operations
+// Space Complexity: O(n) where n is the number of queued songs
+type PlayQueue struct {
+	Head *PlayQueueNode
+	Tail *PlayQueueNode
+	Size int
+
+	queuedIDs map[string]int
+}
+
+// NewPlayQueue creates a new empty play queue
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewPlayQueue() *PlayQueue {
+	return &PlayQueue{
+		queuedIDs: make(map[string]int),
+	}
+}
+
+// Enqueue adds a song to the back of the queue
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pq *PlayQueue) Enqueue(song *models.Song) error {
+	if song == nil {
+		return fmt.Errorf("song cannot be nil")
+	}
+
+	newNode := &PlayQueueNode{Song: song}
+	if pq.Tail == nil {
+		pq.Head = newNode
+	} else {
+		pq.Tail.Next = newNode
+	}
+	pq.Tail = newNode
+
+	pq.queuedIDs[song.ID]++
+	pq.Size++
+	return nil
+}
+
+// Dequeue removes and returns the song at the front of the queue
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pq *PlayQueue) Dequeue() (*models.Song, error) {
+	if pq.Head == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+
+	node := pq.Head
+	pq.Head = node.Next
+	if pq.Head == nil {
+		pq.Tail = nil
+	}
+
+	pq.forget(node.Song.ID)
+	pq.Size--
+	return node.Song, nil
+}
+
+// Peek returns the song at the front of the queue without removing it
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pq *PlayQueue) Peek() (*models.Song, error) {
+	if pq.Head == nil {
+		return nil, fmt.Errorf("queue is empty")
+	}
+	return pq.Head.Song, nil
+}
+
+// Contains reports whether a song is queued at least once
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pq *PlayQueue) Contains(songID string) bool {
+	return pq.queuedIDs[songID] > 0
+}
+
+// Remove removes every queued occurrence of a song and returns how many were removed
+// Time Complexity: O(n)
+// Space Complexity: O(1)
+func (pq *PlayQueue) Remove(songID string) int {
+	removed := 0
+	var prev *PlayQueueNode
+
+	for current := pq.Head; current != nil; current = current.Next {
+		if current.Song.ID != songID {
+			prev = current
+			continue
+		}
+
+		if prev == nil {
+			pq.Head = current.Next
+		} else {
+			prev.Next = current.Next
+		}
+		if current == pq.Tail {
+			pq.Tail = prev
+		}
+		removed++
+	}
+
+	pq.Size -= removed
+	delete(pq.queuedIDs, songID)
+	return removed
+}
+
+// ToSlice returns queued songs from front to back
+// Time Complexity: O(n)
+// Space Complexity: O(n)
+func (pq *PlayQueue) ToSlice() []*models.Song {
+	songs := make([]*models.Song, 0, pq.Size)
+	for current := pq.Head; current != nil; current = current.Next {
+		songs = append(songs, current.Song)
+	}
+	return songs
+}
+
+// IsEmpty checks if the queue is empty
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pq *PlayQueue) IsEmpty() bool {
+	return pq.Size == 0
+}
+
+// Clear removes all songs from the queue
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pq *PlayQueue) Clear() {
+	pq.Head = nil
+	pq.Tail = nil
+	pq.Size = 0
+	pq.queuedIDs = make(map[string]int)
+}
+
+// forget decrements the queued count for a song ID
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pq *PlayQueue) forget(songID string) {
+	if pq.queuedIDs[songID] <= 1 {
+		delete(pq.queuedIDs, songID)
+		return
+	}
+	pq.queuedIDs[songID]--
+}
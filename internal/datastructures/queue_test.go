@@ -0,0 +1,100 @@
+package datastructures
+
+import (
+	"src/internal/models"
+	"testing"
+)
+
+func TestNewPlayQueue(t *testing.T) {
+	pq := NewPlayQueue()
+
+	if !pq.IsEmpty() || pq.Size != 0 {
+		t.Errorf("Expected empty queue, got size %d", pq.Size)
+	}
+	if _, err := pq.Dequeue(); err == nil {
+		t.Error("Dequeue() on empty queue should fail")
+	}
+	if _, err := pq.Peek(); err == nil {
+		t.Error("Peek() on empty queue should fail")
+	}
+}
+
+func TestPlayQueue_EnqueueDequeue(t *testing.T) {
+	pq := NewPlayQueue()
+	song1 := createTestSong("1", "Song 1", "Artist")
+	song2 := createTestSong("2", "Song 2", "Artist")
+
+	if err := pq.Enqueue(nil); err == nil {
+		t.Error("Enqueue(nil) should fail")
+	}
+
+	pq.Enqueue(song1)
+	pq.Enqueue(song2)
+	pq.Enqueue(song1)
+
+	if pq.Size != 3 {
+		t.Errorf("Expected size 3, got %d", pq.Size)
+	}
+	if front, _ := pq.Peek(); front != song1 {
+		t.Error("Peek() should return the first enqueued song")
+	}
+
+	for i, want := range []*models.Song{song1, song2, song1} {
+		got, err := pq.Dequeue()
+		if err != nil || got != want {
+			t.Errorf("Dequeue() #%d = %v, %v, want %s", i, got, err, want.ID)
+		}
+		// Song 1 stays queued until both occurrences are dequeued
+		if i == 0 && !pq.Contains("1") {
+			t.Error("Contains(1) should be true while a second occurrence is queued")
+		}
+	}
+
+	if !pq.IsEmpty() || pq.Contains("1") || pq.Contains("2") {
+		t.Error("Queue should be empty after dequeuing everything")
+	}
+	if pq.Tail != nil {
+		t.Error("Tail should be nil for an empty queue")
+	}
+}
+
+func TestPlayQueue_Remove(t *testing.T) {
+	pq := NewPlayQueue()
+	for _, id := range []string{"1", "2", "1", "3", "1"} {
+		pq.Enqueue(createTestSong(id, "Song "+id, "Artist"))
+	}
+
+	if removed := pq.Remove("1"); removed != 3 {
+		t.Errorf("Remove(1) = %d, want 3", removed)
+	}
+	if pq.Size != 2 || pq.Contains("1") {
+		t.Errorf("Expected 2 songs without song 1, got size %d", pq.Size)
+	}
+
+	songs := pq.ToSlice()
+	if len(songs) != 2 || songs[0].ID != "2" || songs[1].ID != "3" {
+		t.Errorf("Expected queue 2,3, got %v", songs)
+	}
+
+	// Tail is updated so enqueueing still appends at the back
+	pq.Remove("3")
+	pq.Enqueue(createTestSong("4", "Song 4", "Artist"))
+	songs = pq.ToSlice()
+	if len(songs) != 2 || songs[1].ID != "4" {
+		t.Errorf("Expected queue 2,4, got %v", songs)
+	}
+
+	if removed := pq.Remove("missing"); removed != 0 {
+		t.Errorf("Remove(missing) = %d, want 0", removed)
+	}
+}
+
+func TestPlayQueue_Clear(t *testing.T) {
+	pq := NewPlayQueue()
+	pq.Enqueue(createTestSong("1", "Song 1", "Artist"))
+	pq.Clear()
+
+	if !pq.IsEmpty() || pq.Contains("1") || len(pq.ToSlice()) != 0 {
+		t.Error("Queue should be empty after Clear()")
+	}
+}
@@ -0,0 +1,91 @@
+package datastructures
+
+import (
+	"src/internal/models"
+	"sync"
+)
+
+// SearchCache is a bounded least-recently-used cache of search results keyed by query
+// It wraps an LRUCache and counts hits and misses
+// Safe for concurrent use, since lookups reorder the LRU list even under a reader's lock
+// Time Complexity: O(1) average for get, put and clear operations
+// Space Complexity: O(c * k) where c is the capacity and k the average result size
+type SearchCache struct {
+	mu      sync.Mutex
+	results *LRUCache[string, []*models.Song]
+	hits    int
+	misses  int
+}
+
+// NewSearchCache creates an empty search cache holding at most capacity queries
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewSearchCache(capacity int) *SearchCache {
+	return &SearchCache{
+		results: NewLRUCache[string, []*models.Song](capacity, nil),
+	}
+}
+
+// Get returns the cached result for a query and marks it as most recently used
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (sc *SearchCache) Get(query string) ([]*models.Song, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	songs, exists := sc.results.Get(query)
+	if !exists {
+		sc.misses++
+		return nil, false
+	}
+
+	sc.hits++
+	return songs, true
+}
+
+// Put stores a result for a query, evicting the least recently used query when full
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (sc *SearchCache) Put(query string, songs []*models.Song) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.results.Put(query, songs)
+}
+
+// Len returns the number of cached queries
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (sc *SearchCache) Len() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	return sc.results.Len()
+}
+
+// Capacity returns the maximum number of cached queries
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (sc *SearchCache) Capacity() int {
+	return sc.results.Capacity()
+}
+
+// Stats returns the number of cache hits and misses since creation
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (sc *SearchCache) Stats() (hits, misses int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	return sc.hits, sc.misses
+}
+
+// Clear removes every cached result, hit and miss counts are kept
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (sc *SearchCache) Clear() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.results.Clear()
+}
@@ -0,0 +1,69 @@
+package datastructures
+
+import (
+	"src/internal/models"
+	"testing"
+)
+
+func TestSearchCache_GetPut(t *testing.T) {
+	sc := NewSearchCache(2)
+	songs := []*models.Song{createTestSong("1", "Song 1", "Artist")}
+
+	if _, ok := sc.Get("song"); ok {
+		t.Error("Get() on empty cache should miss")
+	}
+
+	sc.Put("song", songs)
+	got, ok := sc.Get("song")
+	if !ok || len(got) != 1 || got[0] != songs[0] {
+		t.Errorf("Expected cached result, got %v, %v", got, ok)
+	}
+
+	if hits, misses := sc.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %d, %d", hits, misses)
+	}
+
+	// Putting an existing query replaces its result without growing the cache
+	sc.Put("song", nil)
+	if got, _ := sc.Get("song"); got != nil || sc.Len() != 1 {
+		t.Errorf("Expected replaced result and 1 entry, got %v and %d entries", got, sc.Len())
+	}
+}
+
+func TestSearchCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	sc := NewSearchCache(2)
+
+	sc.Put("a", nil)
+	sc.Put("b", nil)
+	sc.Get("a") // "b" is now least recently used
+	sc.Put("c", nil)
+
+	if _, ok := sc.Get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+	for _, query := range []string{"a", "c"} {
+		if _, ok := sc.Get(query); !ok {
+			t.Errorf("Expected %s to stay cached", query)
+		}
+	}
+	if sc.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", sc.Len())
+	}
+	if keys := sc.results.Keys(); len(keys) != 2 || keys[0] != "c" || keys[1] != "a" {
+		t.Errorf("Expected recency order c, a, got %v", keys)
+	}
+}
+
+func TestSearchCache_Clear(t *testing.T) {
+	sc := NewSearchCache(0)
+	if sc.Capacity() != 64 {
+		t.Errorf("Expected default capacity 64, got %d", sc.Capacity())
+	}
+
+	sc.Put("a", nil)
+	sc.Clear()
+
+	if _, ok := sc.Get("a"); ok || sc.Len() != 0 {
+		t.Error("Cache should be empty after Clear()")
+	}
+}
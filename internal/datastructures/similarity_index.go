@@ -0,0 +1,116 @@
+package datastructures
+
+import (
+	"src/internal/models"
+	"strconv"
+	"strings"
+)
+
+// SimilarityIndex buckets songs by the attributes the similarity modes compare, so the songs
+// similar to a given one can be found without scanning the whole playlist
+// Each song is filed under its genre and mood (default mode), its subgenre and mood (strict mode)
+// and its genre (loose mode); default mode also compares durations, which callers check on the
+// bucket they get back
+// Time Complexity: O(1) average per add, O(b) per removal where b is the bucket size
+// Space Complexity: O(n)
+type SimilarityIndex struct {
+	buckets map[string][]*models.Song // Bucket key -> songs in insertion order
+	keys    map[string][]string       // Bucket keys each song ID is filed under, for removal
+}
+
+// NewSimilarityIndex creates an empty similarity index
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewSimilarityIndex() *SimilarityIndex {
+	return &SimilarityIndex{
+		buckets: make(map[string][]*models.Song),
+		keys:    make(map[string][]string),
+	}
+}
+
+// similarityKey builds the bucket key for a similarity mode from the attribute values it compares
+// Time Complexity: O(k) where k is the total length of the values
+// Space Complexity: O(k)
+func similarityKey(mode models.SimilarityMode, values ...string) string {
+	return strconv.Itoa(int(mode)) + "\x00" + strings.Join(values, "\x00")
+}
+
+// bucketKeys returns the bucket keys a song is filed under, one per similarity mode
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func bucketKeys(song *models.Song) []string {
+	return []string{
+		similarityKey(models.SimilarityDefault, song.Genre, song.Mood),
+		similarityKey(models.SimilarityStrict, song.SubGenre, song.Mood),
+		similarityKey(models.SimilarityLoose, song.Genre),
+	}
+}
+
+// Add files a song under its current attributes, replacing where it was filed before
+// Call it again after changing a song's genre, subgenre or mood
+// Time Complexity: O(1) average for a new song, O(b) when the song is re-filed
+// Space Complexity: O(1)
+func (si *SimilarityIndex) Add(song *models.Song) {
+	if _, exists := si.keys[song.ID]; exists {
+		si.Remove(song.ID)
+	}
+
+	keys := bucketKeys(song)
+	for _, key := range keys {
+		si.buckets[key] = append(si.buckets[key], song)
+	}
+	si.keys[song.ID] = keys
+}
+
+// Remove drops a song from every bucket it was filed under
+// Time Complexity: O(b) where b is the size of the song's buckets
+// Space Complexity: O(1)
+func (si *SimilarityIndex) Remove(songID string) {
+	for _, key := range si.keys[songID] {
+		bucket := si.buckets[key]
+		for i, song := range bucket {
+			if song.ID == songID {
+				bucket = append(bucket[:i], bucket[i+1:]...)
+				break
+			}
+		}
+		if len(bucket) == 0 {
+			delete(si.buckets, key)
+		} else {
+			si.buckets[key] = bucket
+		}
+	}
+	delete(si.keys, songID)
+}
+
+// Candidates returns the songs sharing a bucket with song under mode, in insertion order
+// Every song similar to song under mode is included; in default mode durations still need
+// checking with IsSimilarWithMode. The song itself is included when it is indexed.
+// The returned slice belongs to the index and must not be modified
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (si *SimilarityIndex) Candidates(song *models.Song, mode models.SimilarityMode) []*models.Song {
+	switch mode {
+	case models.SimilarityStrict:
+		return si.buckets[similarityKey(models.SimilarityStrict, song.SubGenre, song.Mood)]
+	case models.SimilarityLoose:
+		return si.buckets[similarityKey(models.SimilarityLoose, song.Genre)]
+	default:
+		return si.buckets[similarityKey(models.SimilarityDefault, song.Genre, song.Mood)]
+	}
+}
+
+// Len returns the number of indexed songs
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (si *SimilarityIndex) Len() int {
+	return len(si.keys)
+}
+
+// Clear removes every song
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (si *SimilarityIndex) Clear() {
+	si.buckets = make(map[string][]*models.Song)
+	si.keys = make(map[string][]string)
+}
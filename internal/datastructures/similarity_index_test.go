@@ -0,0 +1,81 @@
+package datastructures
+
+import (
+	"src/internal/models"
+	"testing"
+)
+
+func candidateIDs(songs []*models.Song) []string {
+	ids := make([]string, 0, len(songs))
+	for _, song := range songs {
+		ids = append(ids, song.ID)
+	}
+	return ids
+}
+
+func TestSimilarityIndex_Candidates(t *testing.T) {
+	index := NewSimilarityIndex()
+	calm := models.NewSong("1", "Calm", "Artist", "Album", "Rock", "Alternative", "Chill", 200, 90)
+	loud := models.NewSong("2", "Loud", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 140)
+	indie := models.NewSong("3", "Indie", "Artist", "Album", "Rock", "Indie", "Chill", 200, 100)
+	jazz := models.NewSong("4", "Jazz", "Artist", "Album", "Jazz", "Alternative", "Chill", 200, 100)
+	for _, song := range []*models.Song{calm, loud, indie, jazz} {
+		index.Add(song)
+	}
+
+	tests := []struct {
+		mode models.SimilarityMode
+		want []string
+	}{
+		{models.SimilarityDefault, []string{"1", "3"}},
+		{models.SimilarityStrict, []string{"1", "4"}},
+		{models.SimilarityLoose, []string{"1", "2", "3"}},
+	}
+	for _, tt := range tests {
+		got := candidateIDs(index.Candidates(calm, tt.mode))
+		if len(got) != len(tt.want) {
+			t.Errorf("Candidates(mode %d) = %v, want %v", tt.mode, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Candidates(mode %d) = %v, want %v", tt.mode, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSimilarityIndex_RefileAndRemove(t *testing.T) {
+	index := NewSimilarityIndex()
+	song := models.NewSong("1", "Song", "Artist", "Album", "Rock", "Alternative", "Chill", 200, 90)
+	other := models.NewSong("2", "Other", "Artist", "Album", "Jazz", "Bebop", "Chill", 200, 90)
+	index.Add(song)
+	index.Add(other)
+
+	// Re-adding after a change moves the song to its new buckets
+	song.Genre = "Jazz"
+	index.Add(song)
+	if index.Len() != 2 {
+		t.Errorf("Expected 2 indexed songs, got %d", index.Len())
+	}
+	if got := candidateIDs(index.Candidates(other, models.SimilarityLoose)); len(got) != 2 {
+		t.Errorf("Expected both songs under Jazz, got %v", got)
+	}
+	if got := index.Candidates(&models.Song{Genre: "Rock"}, models.SimilarityLoose); len(got) != 0 {
+		t.Errorf("Expected nothing left under Rock, got %v", candidateIDs(got))
+	}
+
+	index.Remove("1")
+	if got := candidateIDs(index.Candidates(other, models.SimilarityLoose)); len(got) != 1 || got[0] != "2" {
+		t.Errorf("Expected only song 2 after removal, got %v", got)
+	}
+	if index.Len() != 1 {
+		t.Errorf("Expected 1 indexed song, got %d", index.Len())
+	}
+
+	index.Clear()
+	if index.Len() != 0 || len(index.Candidates(other, models.SimilarityLoose)) != 0 {
+		t.Error("Expected an empty index after Clear")
+	}
+}
@@ -1,6 +1,7 @@
 package datastructures
 
 import (
+	"fmt"
 	"src/internal/models"
 	"strings"
 	"time"
@@ -18,13 +19,52 @@ const (
 	SortByOldestAdded
 	SortByRating
 	SortByPlayCount
+	SortByAlbum
 )
 
+// sortCriteriaNames maps the API name of each criteria to its value
+var sortCriteriaNames = map[string]SortCriteria{
+	"title":          SortByTitle,
+	"artist":         SortByArtist,
+	"album":          SortByAlbum,
+	"duration_asc":   SortByDurationAsc,
+	"duration_desc":  SortByDurationDesc,
+	"recently_added": SortByRecentlyAdded,
+	"oldest_added":   SortByOldestAdded,
+	"rating":         SortByRating,
+	"play_count":     SortByPlayCount,
+}
+
+// SortCriteriaName returns the API name of a criteria, the inverse of ParseSortCriteria
+// Time Complexity: O(c) where c is the number of criteria
+// Space Complexity: O(1)
+func SortCriteriaName(criteria SortCriteria) string {
+	for name, value := range sortCriteriaNames {
+		if value == criteria {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// ParseSortCriteria converts an API criteria name such as "artist" or "duration_desc" into a SortCriteria
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func ParseSortCriteria(name string) (SortCriteria, error) {
+	criteria, ok := sortCriteriaNames[name]
+	if !ok {
+		return SortByTitle, fmt.Errorf("unknown sort criteria: %q", name)
+	}
+	return criteria, nil
+}
+
 // PlaylistSorter provides various sorting algorithms for playlists
+// Songs that compare equal on the primary criteria are ordered by the tie-breaker (title by default)
 // Time Complexity varies by algorithm: Merge Sort O(n log n), Quick Sort O(n log n) average
 // Space Complexity: Merge Sort O(n), Quick Sort O(log n) average
 type PlaylistSorter struct {
-	criteria SortCriteria
+	criteria   SortCriteria
+	tieBreaker SortCriteria
 }
 
 // NewPlaylistSorter creates a new playlist sorter with specified criteria
@@ -32,7 +72,8 @@ type PlaylistSorter struct {
 // Space Complexity: O(1)
 func NewPlaylistSorter(criteria SortCriteria) *PlaylistSorter {
 	return &PlaylistSorter{
-		criteria: criteria,
+		criteria:   criteria,
+		tieBreaker: SortByTitle,
 	}
 }
 
@@ -222,22 +263,45 @@ func (ps *PlaylistSorter) heapify(songs []*models.Song, n, i int) {
 	}
 }
 
-// compare compares two songs based on the current sorting criteria
+// TopN returns the first n songs in the order defined by less without sorting the whole slice
+// less(a, b) reports whether a ranks before b; ties keep their input order, matching a stable sort
+// A TopK heap holds the best n songs seen so far with the weakest at the root
+// Time Complexity: O(m log n) where m is the number of songs
+// Space Complexity: O(n)
+func TopN(songs []*models.Song, n int, less func(a, b *models.Song) bool) []*models.Song {
+	top := NewTopK(n, less)
+	for _, song := range songs {
+		top.Push(song)
+	}
+	return top.Results()
+}
+
+// compare compares two songs based on the current sorting criteria, then the tie-breaker
 // Returns: < 0 if song1 < song2, 0 if song1 == song2, > 0 if song1 > song2
 // Time Complexity: O(1) for most criteria, O(k) for string comparisons
 // Space Complexity: O(1)
 func (ps *PlaylistSorter) compare(song1, song2 *models.Song) int {
-	switch ps.criteria {
+	result := compareByCriteria(ps.criteria, song1, song2)
+	if result != 0 || ps.tieBreaker == ps.criteria {
+		return result
+	}
+	return compareByCriteria(ps.tieBreaker, song1, song2)
+}
+
+// compareByCriteria compares two songs on a single criteria without any tie-breaking
+// Returns: < 0 if song1 < song2, 0 if song1 == song2, > 0 if song1 > song2
+// Time Complexity: O(1) for most criteria, O(k) for string comparisons
+// Space Complexity: O(1)
+func compareByCriteria(criteria SortCriteria, song1, song2 *models.Song) int {
+	switch criteria {
 	case SortByTitle:
 		return strings.Compare(strings.ToLower(song1.Title), strings.ToLower(song2.Title))
 
 	case SortByArtist:
-		artistCmp := strings.Compare(strings.ToLower(song1.Artist), strings.ToLower(song2.Artist))
-		if artistCmp == 0 {
-			// If same artist, sort by title
-			return strings.Compare(strings.ToLower(song1.Title), strings.ToLower(song2.Title))
-		}
-		return artistCmp
+		return strings.Compare(strings.ToLower(song1.Artist), strings.ToLower(song2.Artist))
+
+	case SortByAlbum:
+		return strings.Compare(strings.ToLower(song1.Album), strings.ToLower(song2.Album))
 
 	case SortByDurationAsc:
 		return song1.Duration - song2.Duration
@@ -262,20 +326,10 @@ func (ps *PlaylistSorter) compare(song1, song2 *models.Song) int {
 		return 0
 
 	case SortByRating:
-		ratingDiff := song2.Rating - song1.Rating // Higher ratings first
-		if ratingDiff == 0 {
-			// If same rating, sort by title
-			return strings.Compare(strings.ToLower(song1.Title), strings.ToLower(song2.Title))
-		}
-		return ratingDiff
+		return song2.Rating - song1.Rating // Higher ratings first
 
 	case SortByPlayCount:
-		playCountDiff := song2.PlayCount - song1.PlayCount // Higher play counts first
-		if playCountDiff == 0 {
-			// If same play count, sort by title
-			return strings.Compare(strings.ToLower(song1.Title), strings.ToLower(song2.Title))
-		}
-		return playCountDiff
+		return song2.PlayCount - song1.PlayCount // Higher play counts first
 
 	default:
 		return strings.Compare(strings.ToLower(song1.Title), strings.ToLower(song2.Title))
@@ -296,12 +350,46 @@ func (ps *PlaylistSorter) GetCriteria() SortCriteria {
 	return ps.criteria
 }
 
+// SetTieBreaker sets the secondary criteria used to order songs equal on the primary criteria
+// Setting it to the primary criteria leaves equal songs in their input order for stable sorts
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (ps *PlaylistSorter) SetTieBreaker(criteria SortCriteria) {
+	ps.tieBreaker = criteria
+}
+
+// GetTieBreaker returns the secondary sorting criteria
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (ps *PlaylistSorter) GetTieBreaker() SortCriteria {
+	return ps.tieBreaker
+}
+
+// DefaultSortAlgorithm is used when an unknown algorithm name is requested
+const DefaultSortAlgorithm = "merge"
+
+// IsSortAlgorithm reports whether name is a supported sorting algorithm
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func IsSortAlgorithm(name string) bool {
+	switch name {
+	case "merge", "quick", "heap":
+		return true
+	}
+	return false
+}
+
 // SortPlaylist sorts a doubly linked list playlist using the specified algorithm
+// Unknown algorithms fall back to DefaultSortAlgorithm; the algorithm actually used is returned
 // Time Complexity: O(n) to convert + O(n log n) to sort + O(n) to reconstruct
 // Space Complexity: O(n)
-func (ps *PlaylistSorter) SortPlaylist(playlist *DoublyLinkedList, algorithm string) {
+func (ps *PlaylistSorter) SortPlaylist(playlist *DoublyLinkedList, algorithm string) string {
+	if !IsSortAlgorithm(algorithm) {
+		algorithm = DefaultSortAlgorithm
+	}
+
 	if playlist.IsEmpty() {
-		return
+		return algorithm
 	}
 
 	// Convert playlist to slice
@@ -310,14 +398,12 @@ func (ps *PlaylistSorter) SortPlaylist(playlist *DoublyLinkedList, algorithm str
 	// Sort using specified algorithm
 	var sortedSongs []*models.Song
 	switch algorithm {
-	case "merge":
-		sortedSongs = ps.MergeSort(songs)
 	case "quick":
 		sortedSongs = ps.QuickSort(songs)
 	case "heap":
 		sortedSongs = ps.HeapSort(songs)
 	default:
-		sortedSongs = ps.MergeSort(songs) // Default to merge sort
+		sortedSongs = ps.MergeSort(songs)
 	}
 
 	// Reconstruct the playlist with sorted songs
@@ -325,6 +411,8 @@ func (ps *PlaylistSorter) SortPlaylist(playlist *DoublyLinkedList, algorithm str
 	for _, song := range sortedSongs {
 		playlist.AddSong(song)
 	}
+
+	return algorithm
 }
 
 // MultiCriteriaSort sorts songs using multiple criteria with priority
@@ -338,9 +426,15 @@ func (ps *PlaylistSorter) MultiCriteriaSort(songs []*models.Song, criteria []Sor
 	result := make([]*models.Song, len(songs))
 	copy(result, songs)
 
+	// Each pass must keep the order of the previous one for equal songs,
+	// so the tie-breaker is disabled while sorting and restored afterwards
+	tieBreaker := ps.tieBreaker
+	defer func() { ps.tieBreaker = tieBreaker }()
+
 	// Sort by each criterion in reverse order (last criterion first)
 	for i := len(criteria) - 1; i >= 0; i-- {
 		ps.criteria = criteria[i]
+		ps.tieBreaker = criteria[i]
 		result = ps.MergeSort(result) // Use stable sort for multi-criteria
 	}
 
@@ -401,6 +495,8 @@ func (ps *PlaylistSorter) GetSortCriteriaString() string {
 		return "Title (A-Z)"
 	case SortByArtist:
 		return "Artist (A-Z)"
+	case SortByAlbum:
+		return "Album (A-Z)"
 	case SortByDurationAsc:
 		return "Duration (Shortest First)"
 	case SortByDurationDesc:
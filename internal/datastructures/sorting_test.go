@@ -1,7 +1,9 @@
 package datastructures
 
 import (
+	"sort"
 	"src/internal/models"
+	"strings"
 	"testing"
 	"time"
 )
@@ -375,6 +377,28 @@ func TestSortPlaylist(t *testing.T) {
 	}
 }
 
+func TestSortPlaylist_UnknownAlgorithmFallsBack(t *testing.T) {
+	playlist := NewDoublyLinkedList()
+	for _, song := range createTestSongs() {
+		playlist.AddSong(song)
+	}
+	sorter := NewPlaylistSorter(SortByTitle)
+
+	if used := sorter.SortPlaylist(playlist, "bogo"); used != DefaultSortAlgorithm {
+		t.Errorf("Expected fallback to %s, got %s", DefaultSortAlgorithm, used)
+	}
+	for _, algorithm := range []string{"merge", "quick", "heap"} {
+		if used := sorter.SortPlaylist(playlist, algorithm); used != algorithm {
+			t.Errorf("Expected %s to be used as requested, got %s", algorithm, used)
+		}
+	}
+
+	// Fallback is reported even when there is nothing to sort
+	if used := sorter.SortPlaylist(NewDoublyLinkedList(), ""); used != DefaultSortAlgorithm {
+		t.Errorf("Expected fallback on empty playlist, got %s", used)
+	}
+}
+
 func TestSortPlaylistEmpty(t *testing.T) {
 	playlist := NewDoublyLinkedList()
 	sorter := NewPlaylistSorter(SortByTitle)
@@ -499,7 +523,73 @@ func TestCompareEdgeCases(t *testing.T) {
 	}
 }
 
+func TestTopN(t *testing.T) {
+	songs := createLargeSongDataset(500)
+	byDuration := func(a, b *models.Song) bool { return a.Duration > b.Duration }
+
+	// Must match sort-then-slice, including the order of ties
+	for _, n := range []int{1, 10, 499, 500} {
+		expected := make([]*models.Song, len(songs))
+		copy(expected, songs)
+		sort.SliceStable(expected, func(i, j int) bool { return byDuration(expected[i], expected[j]) })
+
+		got := TopN(songs, n, byDuration)
+		if len(got) != n {
+			t.Fatalf("TopN(%d) returned %d songs", n, len(got))
+		}
+		for i := range got {
+			if got[i] != expected[i] {
+				t.Errorf("TopN(%d)[%d] = %s (%ds), want %s (%ds)", n, i, got[i].Title, got[i].Duration, expected[i].Title, expected[i].Duration)
+				break
+			}
+		}
+	}
+
+	// Input is left untouched
+	if songs[0].Duration != 120 {
+		t.Error("TopN should not reorder its input")
+	}
+}
+
+func TestTopN_EdgeCases(t *testing.T) {
+	songs := createTestSongs()
+	byPlays := func(a, b *models.Song) bool { return a.PlayCount > b.PlayCount }
+
+	if got := TopN(songs, 0, byPlays); len(got) != 0 {
+		t.Errorf("Expected no songs for n=0, got %d", len(got))
+	}
+	if got := TopN(nil, 3, byPlays); len(got) != 0 {
+		t.Errorf("Expected no songs for empty input, got %d", len(got))
+	}
+	if got := TopN(songs, 100, byPlays); len(got) != len(songs) || got[0].PlayCount != 25 {
+		t.Errorf("Expected every song most played first, got %d songs", len(got))
+	}
+}
+
 // Benchmark tests
+func BenchmarkTopN(b *testing.B) {
+	songs := createLargeSongDataset(10000)
+	byPlays := func(a, c *models.Song) bool { return a.PlayCount > c.PlayCount }
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TopN(songs, 10, byPlays)
+	}
+}
+
+func BenchmarkSortThenSlice(b *testing.B) {
+	songs := createLargeSongDataset(10000)
+	byPlays := func(a, c *models.Song) bool { return a.PlayCount > c.PlayCount }
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sorted := make([]*models.Song, len(songs))
+		copy(sorted, songs)
+		sort.SliceStable(sorted, func(x, y int) bool { return byPlays(sorted[x], sorted[y]) })
+		_ = sorted[:10]
+	}
+}
+
 func BenchmarkMergeSort(b *testing.B) {
 	sorter := NewPlaylistSorter(SortByTitle)
 	songs := createLargeSongDataset(1000)
@@ -569,3 +659,103 @@ func generateRandomArtist(seed int) string {
 	}
 	return artists[seed%len(artists)]
 }
+
+func TestSetTieBreaker(t *testing.T) {
+	songs := []*models.Song{
+		{ID: "1", Title: "Alpha", Rating: 5, PlayCount: 1},
+		{ID: "2", Title: "Bravo", Rating: 5, PlayCount: 9},
+		{ID: "3", Title: "Charlie", Rating: 3, PlayCount: 4},
+	}
+
+	sorter := NewPlaylistSorter(SortByRating)
+	if sorter.GetTieBreaker() != SortByTitle {
+		t.Errorf("Expected default tie-breaker SortByTitle, got %v", sorter.GetTieBreaker())
+	}
+
+	// Default: equal ratings ordered by title
+	sorted := sorter.MergeSort(songs)
+	if sorted[0].ID != "1" || sorted[1].ID != "2" || sorted[2].ID != "3" {
+		t.Errorf("Expected title tie-break order 1,2,3, got %s,%s,%s", sorted[0].ID, sorted[1].ID, sorted[2].ID)
+	}
+
+	// Play count tie-breaker puts the more played song first
+	sorter.SetTieBreaker(SortByPlayCount)
+	for name, sortFn := range map[string]func([]*models.Song) []*models.Song{
+		"merge": sorter.MergeSort,
+		"quick": sorter.QuickSort,
+		"heap":  sorter.HeapSort,
+	} {
+		sorted := sortFn(songs)
+		if sorted[0].ID != "2" || sorted[1].ID != "1" || sorted[2].ID != "3" {
+			t.Errorf("%s: expected play count tie-break order 2,1,3, got %s,%s,%s", name, sorted[0].ID, sorted[1].ID, sorted[2].ID)
+		}
+	}
+
+	// A tie-breaker equal to the primary criteria keeps equal songs in input order
+	sorter.SetTieBreaker(SortByRating)
+	reversed := []*models.Song{songs[1], songs[0], songs[2]}
+	sorted = sorter.MergeSort(reversed)
+	if sorted[0].ID != "2" || sorted[1].ID != "1" {
+		t.Errorf("Expected stable input order 2,1 for equal ratings, got %s,%s", sorted[0].ID, sorted[1].ID)
+	}
+}
+
+func TestMultiCriteriaSortKeepsTieBreaker(t *testing.T) {
+	songs := []*models.Song{
+		{ID: "1", Title: "Alpha", Duration: 200, Rating: 3},
+		{ID: "2", Title: "Bravo", Duration: 200, Rating: 5},
+		{ID: "3", Title: "Charlie", Duration: 100, Rating: 4},
+	}
+
+	sorter := NewPlaylistSorter(SortByTitle)
+	sorter.SetTieBreaker(SortByPlayCount)
+
+	// Equal durations keep the rating order from the previous pass
+	sorted := sorter.MultiCriteriaSort(songs, []SortCriteria{SortByDurationAsc, SortByRating})
+	if sorted[0].ID != "3" || sorted[1].ID != "2" || sorted[2].ID != "1" {
+		t.Errorf("Expected order 3,2,1, got %s,%s,%s", sorted[0].ID, sorted[1].ID, sorted[2].ID)
+	}
+
+	if sorter.GetTieBreaker() != SortByPlayCount {
+		t.Errorf("MultiCriteriaSort should restore the tie-breaker, got %v", sorter.GetTieBreaker())
+	}
+}
+
+func TestParseSortCriteria(t *testing.T) {
+	for name, want := range map[string]SortCriteria{
+		"title":         SortByTitle,
+		"album":         SortByAlbum,
+		"duration_desc": SortByDurationDesc,
+		"play_count":    SortByPlayCount,
+	} {
+		got, err := ParseSortCriteria(name)
+		if err != nil || got != want {
+			t.Errorf("ParseSortCriteria(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+
+	if _, err := ParseSortCriteria("genre"); err == nil || !strings.Contains(err.Error(), "genre") {
+		t.Errorf("Expected an error naming the unknown criteria, got %v", err)
+	}
+}
+
+func TestMultiCriteriaSortArtistAlbumTitle(t *testing.T) {
+	sorter := NewPlaylistSorter(SortByTitle)
+	songs := []*models.Song{
+		models.NewSong("1", "Track B", "Artist 2", "Album X", "Rock", "", "", 200, 0),
+		models.NewSong("2", "Track C", "Artist 1", "Album Y", "Rock", "", "", 200, 0),
+		models.NewSong("3", "Track A", "Artist 1", "Album Y", "Rock", "", "", 200, 0),
+		models.NewSong("4", "Track D", "Artist 1", "Album X", "Rock", "", "", 200, 0),
+		models.NewSong("5", "Track A", "Artist 2", "Album X", "Rock", "", "", 200, 0),
+	}
+
+	sorted := sorter.MultiCriteriaSort(songs, []SortCriteria{SortByArtist, SortByAlbum, SortByTitle})
+
+	var ids []string
+	for _, song := range sorted {
+		ids = append(ids, song.ID)
+	}
+	if got := strings.Join(ids, ","); got != "4,3,2,5,1" {
+		t.Errorf("Expected artist, album, title order 4,3,2,5,1, got %s", got)
+	}
+}
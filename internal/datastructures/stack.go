@@ -3,15 +3,23 @@ package datastructures
 import (
 	"fmt"
 	"src/internal/models"
+	"time"
 )
 
 // PlaybackHistoryNode represents a node in the stack for playback history
-// Each node contains a song and pointer to the next node below it
+// Each node contains a song, the time it was played and pointer to the next node below it
 // Time Complexity: O(1) for all field operations
 // Space Complexity: O(1) per node
 type PlaybackHistoryNode struct {
-	Song *models.Song
-	Next *PlaybackHistoryNode
+	Song     *models.Song
+	PlayedAt time.Time
+	Next     *PlaybackHistoryNode
+}
+
+// PlayedEntry pairs a song with the time it was played
+type PlayedEntry struct {
+	Song     *models.Song `json:"song"`
+	PlayedAt time.Time    `json:"played_at"`
 }
 
 // PlaybackHistoryStack represents a LIFO stack for managing playback history
@@ -43,12 +51,34 @@ func NewPlaybackHistoryStack(maxSize int) *PlaybackHistoryStack {
 // Time Complexity: O(1) amortized, O(n) worst case when removing old entries
 // Space Complexity: O(1)
 func (phs *PlaybackHistoryStack) Push(song *models.Song) {
+	phs.PushAt(song, time.Now())
+}
+
+// PushAt records a play at the given time, keeping the stack ordered by play time
+// Plays older than the top are inserted below newer entries, which makes backfilling history possible
+// Time Complexity: O(1) for plays newer than the top, O(k) otherwise where k is the number of newer entries
+// Space Complexity: O(1)
+func (phs *PlaybackHistoryStack) PushAt(song *models.Song, playedAt time.Time) {
+	if song == nil {
+		return
+	}
+
 	newNode := &PlaybackHistoryNode{
-		Song: song,
-		Next: phs.Top,
+		Song:     song,
+		PlayedAt: playedAt,
 	}
 
-	phs.Top = newNode
+	if phs.Top == nil || !playedAt.Before(phs.Top.PlayedAt) {
+		newNode.Next = phs.Top
+		phs.Top = newNode
+	} else {
+		current := phs.Top
+		for current.Next != nil && playedAt.Before(current.Next.PlayedAt) {
+			current = current.Next
+		}
+		newNode.Next = current.Next
+		current.Next = newNode
+	}
 	phs.Size++
 
 	// If we exceed max size, remove the oldest entry (bottom of stack)
@@ -138,6 +168,27 @@ func (phs *PlaybackHistoryStack) Clear() {
 	phs.Size = 0
 }
 
+// RemoveWhere drops every history entry whose song matches the predicate and returns how many were removed
+// Time Complexity: O(n)
+// Space Complexity: O(1)
+func (phs *PlaybackHistoryStack) RemoveWhere(match func(*models.Song) bool) int {
+	removed := 0
+
+	// Unlink matching entries by rewiring the pointer that leads to them
+	link := &phs.Top
+	for *link != nil {
+		if match((*link).Song) {
+			*link = (*link).Next
+			removed++
+			continue
+		}
+		link = &(*link).Next
+	}
+
+	phs.Size -= removed
+	return removed
+}
+
 // ToSlice returns all songs in history as a slice (top to bottom)
 // Time Complexity: O(n)
 // Space Complexity: O(n)
@@ -174,6 +225,78 @@ func (phs *PlaybackHistoryStack) GetRecentSongs(n int) []*models.Song {
 	return songs
 }
 
+// GetRecentSongsPaged returns up to count songs starting offset entries below the top
+// Out-of-range offsets return an empty page
+// Time Complexity: O(offset + count)
+// Space Complexity: O(min(count, size))
+func (phs *PlaybackHistoryStack) GetRecentSongsPaged(offset, count int) []*models.Song {
+	if offset < 0 || count <= 0 || offset >= phs.Size {
+		return []*models.Song{}
+	}
+
+	songs := make([]*models.Song, 0, min(count, phs.Size-offset))
+	current := phs.Top
+
+	// Skip entries before the requested page
+	for i := 0; i < offset && current != nil; i++ {
+		current = current.Next
+	}
+
+	for current != nil && len(songs) < count {
+		songs = append(songs, current.Song)
+		current = current.Next
+	}
+
+	return songs
+}
+
+// GetEntriesSince returns history entries played at or after the given time, most recent first
+// Stops at the first older entry since the stack is ordered by play time
+// Time Complexity: O(k) where k is the number of matching entries
+// Space Complexity: O(k)
+func (phs *PlaybackHistoryStack) GetEntriesSince(since time.Time) []PlayedEntry {
+	entries := make([]PlayedEntry, 0)
+	current := phs.Top
+
+	for current != nil && !current.PlayedAt.Before(since) {
+		entries = append(entries, PlayedEntry{Song: current.Song, PlayedAt: current.PlayedAt})
+		current = current.Next
+	}
+
+	return entries
+}
+
+// GetSessions splits the history into listening sessions, starting a new session whenever
+// the time between consecutive plays exceeds gap
+// Sessions and the entries within them are ordered oldest first
+// Time Complexity: O(n)
+// Space Complexity: O(n)
+func (phs *PlaybackHistoryStack) GetSessions(gap time.Duration) [][]PlayedEntry {
+	sessions := make([][]PlayedEntry, 0)
+	if phs.IsEmpty() {
+		return sessions
+	}
+
+	// Collect entries newest first, then walk them in chronological order
+	entries := make([]PlayedEntry, 0, phs.Size)
+	for current := phs.Top; current != nil; current = current.Next {
+		entries = append(entries, PlayedEntry{Song: current.Song, PlayedAt: current.PlayedAt})
+	}
+
+	session := make([]PlayedEntry, 0)
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if len(session) > 0 && entry.PlayedAt.Sub(session[len(session)-1].PlayedAt) > gap {
+			sessions = append(sessions, session)
+			session = make([]PlayedEntry, 0)
+		}
+		session = append(session, entry)
+	}
+	sessions = append(sessions, session)
+
+	return sessions
+}
+
 // ContainsSong checks if a specific song is in the playback history
 // Time Complexity: O(n)
 // Space Complexity: O(1)
@@ -223,6 +346,35 @@ func (phs *PlaybackHistoryStack) GetPlaybackStats() map[string]interface{} {
 	}
 }
 
+// GetPlaybackStatsRange returns playback statistics for entries played between from and to inclusive
+// Walks down from the top and stops at the first entry older than from since the stack is ordered by play time
+// Time Complexity: O(k) where k is the number of entries played at or after from
+// Space Complexity: O(a + g) where a and g are the distinct artists and genres in range
+func (phs *PlaybackHistoryStack) GetPlaybackStatsRange(from, to time.Time) map[string]interface{} {
+	totalSongs := 0
+	totalDuration := 0
+	artistSet := make(map[string]bool)
+	genreSet := make(map[string]bool)
+
+	for current := phs.Top; current != nil && !current.PlayedAt.Before(from); current = current.Next {
+		if current.PlayedAt.After(to) {
+			continue
+		}
+
+		totalSongs++
+		totalDuration += current.Song.Duration
+		artistSet[current.Song.Artist] = true
+		genreSet[current.Song.Genre] = true
+	}
+
+	return map[string]interface{}{
+		"total_songs":    totalSongs,
+		"total_duration": totalDuration,
+		"unique_artists": len(artistSet),
+		"unique_genres":  len(genreSet),
+	}
+}
+
 // removeBottom is a helper method to remove the bottom (oldest) entry
 // Used when stack exceeds maximum size
 // Time Complexity: O(n)
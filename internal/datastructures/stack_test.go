@@ -3,6 +3,7 @@ package datastructures
 import (
 	"src/internal/models"
 	"testing"
+	"time"
 )
 
 // Test helper function to create a test song for stack tests
@@ -373,6 +374,172 @@ func TestPlaybackHistoryStack_GetRecentSongs(t *testing.T) {
 	}
 }
 
+func TestPlaybackHistoryStack_GetRecentSongsPaged(t *testing.T) {
+	stack := NewPlaybackHistoryStack(10)
+
+	// Test with empty stack
+	page := stack.GetRecentSongsPaged(0, 5)
+	if len(page) != 0 {
+		t.Errorf("GetRecentSongsPaged() empty stack length = %v, want %v", len(page), 0)
+	}
+
+	// Add songs 1..7, so the stack reads 7,6,5,4,3,2,1 from the top
+	for i := 0; i < 7; i++ {
+		stack.Push(createStackTestSong(string(rune('1'+i)), "Song", "Artist"))
+	}
+
+	tests := []struct {
+		name        string
+		offset      int
+		count       int
+		expectedIDs string
+	}{
+		{"First page", 0, 3, "765"},
+		{"Second page", 3, 3, "432"},
+		{"Partial last page", 6, 3, "1"},
+		{"Offset at size", 7, 3, ""},
+		{"Offset past size", 20, 3, ""},
+		{"Negative offset", -1, 3, ""},
+		{"Zero count", 2, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := stack.GetRecentSongsPaged(tt.offset, tt.count)
+			ids := ""
+			for _, song := range page {
+				ids += song.ID
+			}
+			if ids != tt.expectedIDs {
+				t.Errorf("GetRecentSongsPaged(%d, %d) IDs = %q, want %q", tt.offset, tt.count, ids, tt.expectedIDs)
+			}
+		})
+	}
+}
+
+func TestPlaybackHistoryStack_GetEntriesSince(t *testing.T) {
+	stack := NewPlaybackHistoryStack(10)
+
+	// Test with empty stack
+	if entries := stack.GetEntriesSince(time.Now().Add(-time.Hour)); len(entries) != 0 {
+		t.Errorf("GetEntriesSince() empty stack length = %v, want %v", len(entries), 0)
+	}
+
+	for i := 0; i < 4; i++ {
+		stack.Push(createStackTestSong(string(rune('1'+i)), "Song", "Artist"))
+	}
+
+	// Backdate the two oldest plays
+	base := time.Now()
+	stack.Top.Next.Next.PlayedAt = base.Add(-2 * time.Hour)
+	stack.Top.Next.Next.Next.PlayedAt = base.Add(-3 * time.Hour)
+
+	entries := stack.GetEntriesSince(base.Add(-time.Hour))
+	if len(entries) != 2 {
+		t.Fatalf("GetEntriesSince() length = %v, want %v", len(entries), 2)
+	}
+	if entries[0].Song.ID != "4" || entries[1].Song.ID != "3" {
+		t.Errorf("GetEntriesSince() order = %v,%v, want 4,3", entries[0].Song.ID, entries[1].Song.ID)
+	}
+	if entries[0].PlayedAt.IsZero() {
+		t.Error("GetEntriesSince() entries should carry their play time")
+	}
+
+	if entries := stack.GetEntriesSince(base.Add(-4 * time.Hour)); len(entries) != 4 {
+		t.Errorf("GetEntriesSince() all entries length = %v, want %v", len(entries), 4)
+	}
+}
+
+func TestPlaybackHistoryStack_GetSessions(t *testing.T) {
+	stack := NewPlaybackHistoryStack(10)
+
+	if sessions := stack.GetSessions(30 * time.Minute); len(sessions) != 0 {
+		t.Errorf("GetSessions() empty stack length = %v, want %v", len(sessions), 0)
+	}
+
+	for i := 0; i < 5; i++ {
+		stack.Push(createStackTestSong(string(rune('1'+i)), "Song", "Artist"))
+	}
+
+	// Songs 1-2 in a morning session, 3-5 in an evening session
+	base := time.Now().Add(-12 * time.Hour)
+	playTimes := []time.Duration{0, 5 * time.Minute, 8 * time.Hour, 8*time.Hour + 10*time.Minute, 8*time.Hour + 35*time.Minute}
+	node := stack.Top
+	for i := len(playTimes) - 1; i >= 0; i-- {
+		node.PlayedAt = base.Add(playTimes[i])
+		node = node.Next
+	}
+
+	sessions := stack.GetSessions(30 * time.Minute)
+	if len(sessions) != 2 {
+		t.Fatalf("GetSessions() length = %v, want %v", len(sessions), 2)
+	}
+	if len(sessions[0]) != 2 || sessions[0][0].Song.ID != "1" || sessions[0][1].Song.ID != "2" {
+		t.Errorf("GetSessions() first session = %v, want songs 1,2", sessions[0])
+	}
+	if len(sessions[1]) != 3 || sessions[1][0].Song.ID != "3" || sessions[1][2].Song.ID != "5" {
+		t.Errorf("GetSessions() second session = %v, want songs 3,4,5", sessions[1])
+	}
+
+	// A smaller gap splits the 25 minute pause as well
+	if sessions := stack.GetSessions(20 * time.Minute); len(sessions) != 3 {
+		t.Errorf("GetSessions() with 20m gap length = %v, want %v", len(sessions), 3)
+	}
+}
+
+func TestPlaybackHistoryStack_PushAt(t *testing.T) {
+	stack := NewPlaybackHistoryStack(10)
+	base := time.Now()
+
+	stack.PushAt(createStackTestSong("2", "Song", "Artist"), base.Add(-2*time.Hour))
+	stack.PushAt(createStackTestSong("4", "Song", "Artist"), base)
+	stack.PushAt(createStackTestSong("1", "Song", "Artist"), base.Add(-3*time.Hour))
+	stack.PushAt(createStackTestSong("3", "Song", "Artist"), base.Add(-time.Hour))
+	stack.PushAt(nil, base)
+
+	if stack.GetSize() != 4 {
+		t.Fatalf("PushAt() size = %v, want %v", stack.GetSize(), 4)
+	}
+
+	// Entries stay ordered newest first regardless of insertion order
+	expected := []string{"4", "3", "2", "1"}
+	for i, song := range stack.GetRecentSongs(4) {
+		if song.ID != expected[i] {
+			t.Errorf("PushAt() order[%d] = %v, want %v", i, song.ID, expected[i])
+		}
+	}
+}
+
+func TestPlaybackHistoryStack_GetPlaybackStatsRange(t *testing.T) {
+	stack := NewPlaybackHistoryStack(10)
+	base := time.Date(2024, 1, 8, 12, 0, 0, 0, time.UTC)
+
+	lastWeek := createStackTestSong("1", "Song 1", "Artist 1")
+	thisWeek := models.NewSong("2", "Song 2", "Artist 2", "Album", "Jazz", "Smooth", "Relaxed", 300, 90)
+	stack.PushAt(lastWeek, base.Add(-7*24*time.Hour))
+	stack.PushAt(thisWeek, base)
+	stack.PushAt(thisWeek, base.Add(time.Hour))
+	stack.PushAt(lastWeek, base.Add(48*time.Hour)) // After the range
+
+	stats := stack.GetPlaybackStatsRange(base, base.Add(24*time.Hour))
+	if stats["total_songs"] != 2 || stats["total_duration"] != 600 {
+		t.Errorf("GetPlaybackStatsRange() = %v, want 2 songs and 600 seconds", stats)
+	}
+	if stats["unique_artists"] != 1 || stats["unique_genres"] != 1 {
+		t.Errorf("GetPlaybackStatsRange() = %v, want 1 artist and 1 genre", stats)
+	}
+
+	// Bounds are inclusive
+	stats = stack.GetPlaybackStatsRange(base.Add(-7*24*time.Hour), base)
+	if stats["total_songs"] != 2 {
+		t.Errorf("GetPlaybackStatsRange() with inclusive bounds = %v, want 2 songs", stats["total_songs"])
+	}
+
+	if stats := stack.GetPlaybackStatsRange(base.Add(-time.Hour), base.Add(-time.Minute)); stats["total_songs"] != 0 {
+		t.Errorf("GetPlaybackStatsRange() empty range = %v, want 0 songs", stats["total_songs"])
+	}
+}
+
 func TestPlaybackHistoryStack_ContainsSong(t *testing.T) {
 	stack := NewPlaybackHistoryStack(5)
 
@@ -576,3 +743,30 @@ func TestPlaybackHistoryStack_StressTest(t *testing.T) {
 		t.Errorf("Stress test: Popped %v songs, want %v", count, 1000)
 	}
 }
+
+func TestPlaybackHistoryStack_RemoveWhere(t *testing.T) {
+	stack := NewPlaybackHistoryStack(10)
+	song1 := createStackTestSong("song1", "Song 1", "Artist 1")
+	song2 := createStackTestSong("song2", "Song 2", "Artist 2")
+
+	// Matching entries at the top, middle and bottom
+	stack.Push(song1)
+	stack.Push(song2)
+	stack.Push(song1)
+	stack.Push(song2)
+	stack.Push(song1)
+
+	removed := stack.RemoveWhere(func(song *models.Song) bool { return song.ID == "song1" })
+	if removed != 3 || stack.GetSize() != 2 {
+		t.Errorf("Expected 3 removed and 2 left, got %d removed and size %d", removed, stack.GetSize())
+	}
+	for _, song := range stack.ToSlice() {
+		if song.ID != "song2" {
+			t.Errorf("Expected only song2 left, got %s", song.ID)
+		}
+	}
+
+	if removed := stack.RemoveWhere(func(*models.Song) bool { return false }); removed != 0 {
+		t.Errorf("Expected nothing removed, got %d", removed)
+	}
+}
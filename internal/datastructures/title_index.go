@@ -0,0 +1,223 @@
+package datastructures
+
+import (
+	"src/internal/models"
+	"strings"
+)
+
+// titleEntry holds every song sharing one normalized title
+type titleEntry struct {
+	key   string
+	songs []*models.Song
+	next  *titleEntry // For handling collisions using chaining
+}
+
+// TitleIndex maps normalized titles to the songs carrying them, so songs sharing a title
+// are all found instead of the last one added overwriting the others
+// Uses separate chaining like SongHashMap, with one entry per distinct title
+// Time Complexity: O(1) average for lookups, O(k) for removal where k is the songs sharing a title
+// Space Complexity: O(n + capacity) where n is the number of songs
+type TitleIndex struct {
+	buckets  []*titleEntry
+	titles   int // Number of distinct titles
+	songs    int // Number of songs
+	capacity int
+}
+
+// NewTitleIndex creates an empty title index with the given initial number of buckets
+// Time Complexity: O(capacity)
+// Space Complexity: O(capacity)
+func NewTitleIndex(capacity int) *TitleIndex {
+	if capacity <= 0 {
+		capacity = 16 // Default capacity
+	}
+	return &TitleIndex{
+		buckets:  make([]*titleEntry, capacity),
+		capacity: capacity,
+	}
+}
+
+// NormalizeTitle folds a title to the key it is indexed under: lower case, with surrounding
+// whitespace trimmed and inner runs of whitespace collapsed to one space
+// Time Complexity: O(k) where k is the length of the title
+// Space Complexity: O(k)
+func NormalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// find returns the entry for a normalized title, nil when no song carries it
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (ti *TitleIndex) find(key string) *titleEntry {
+	for entry := ti.buckets[djb2(key, ti.capacity)]; entry != nil; entry = entry.next {
+		if entry.key == key {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Add indexes a song under its title; adding a song that is already indexed does nothing
+// Time Complexity: O(1) average, O(k) to skip a song that is already indexed
+// Space Complexity: O(1)
+func (ti *TitleIndex) Add(song *models.Song) {
+	if song == nil {
+		return
+	}
+	key := NormalizeTitle(song.Title)
+	if key == "" {
+		return
+	}
+
+	if entry := ti.find(key); entry != nil {
+		for _, indexed := range entry.songs {
+			if indexed.ID == song.ID {
+				return
+			}
+		}
+		entry.songs = append(entry.songs, song)
+		ti.songs++
+		return
+	}
+
+	index := djb2(key, ti.capacity)
+	ti.buckets[index] = &titleEntry{key: key, songs: []*models.Song{song}, next: ti.buckets[index]}
+	ti.titles++
+	ti.songs++
+
+	if ti.titles > ti.capacity*2 {
+		ti.rehash(ti.capacity * 2)
+	}
+}
+
+// Remove drops a song from the index, looking it up under its current title
+// The title's entry is removed with its last song. Returns false when the song isn't indexed there
+// Time Complexity: O(k) where k is the number of songs sharing the title
+// Space Complexity: O(1)
+func (ti *TitleIndex) Remove(song *models.Song) bool {
+	key := NormalizeTitle(song.Title)
+	if key == "" {
+		return false
+	}
+
+	index := djb2(key, ti.capacity)
+	var prev *titleEntry
+	for entry := ti.buckets[index]; entry != nil; prev, entry = entry, entry.next {
+		if entry.key != key {
+			continue
+		}
+		for i, indexed := range entry.songs {
+			if indexed.ID != song.ID {
+				continue
+			}
+			// Shift rather than swap so the remaining songs keep the order they were added in
+			entry.songs = append(entry.songs[:i], entry.songs[i+1:]...)
+			ti.songs--
+			if len(entry.songs) == 0 {
+				if prev == nil {
+					ti.buckets[index] = entry.next
+				} else {
+					prev.next = entry.next
+				}
+				ti.titles--
+			}
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// Get returns every song carrying title, in the order they were added
+// Time Complexity: O(1) average plus O(k) to copy the k matches
+// Space Complexity: O(k)
+func (ti *TitleIndex) Get(title string) []*models.Song {
+	if entry := ti.find(NormalizeTitle(title)); entry != nil {
+		return append([]*models.Song(nil), entry.songs...)
+	}
+	return []*models.Song{}
+}
+
+// Contains reports whether song is indexed under its current title
+// Time Complexity: O(k) where k is the number of songs sharing the title
+// Space Complexity: O(1)
+func (ti *TitleIndex) Contains(song *models.Song) bool {
+	if entry := ti.find(NormalizeTitle(song.Title)); entry != nil {
+		for _, indexed := range entry.songs {
+			if indexed == song {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Len returns the number of indexed songs
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (ti *TitleIndex) Len() int {
+	return ti.songs
+}
+
+// TitleCount returns the number of distinct titles
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (ti *TitleIndex) TitleCount() int {
+	return ti.titles
+}
+
+// GetCapacity returns the current number of buckets
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (ti *TitleIndex) GetCapacity() int {
+	return ti.capacity
+}
+
+// GetLoadFactor returns the number of distinct titles per bucket
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (ti *TitleIndex) GetLoadFactor() float64 {
+	return float64(ti.titles) / float64(ti.capacity)
+}
+
+// ShrinkToFit reduces the capacity to one bucket per title, but never below minCapacity
+// Returns the resulting capacity; an index that is already small enough is left unchanged
+// Time Complexity: O(t + capacity) where t is the number of titles
+// Space Complexity: O(new_capacity)
+func (ti *TitleIndex) ShrinkToFit(minCapacity int) int {
+	target := max(ti.titles, minCapacity, 1)
+	if target < ti.capacity {
+		ti.rehash(target)
+	}
+	return ti.capacity
+}
+
+// rehash moves every entry into a new bucket array of the given capacity
+// Time Complexity: O(t + capacity)
+// Space Complexity: O(new_capacity)
+func (ti *TitleIndex) rehash(capacity int) {
+	oldBuckets := ti.buckets
+	ti.capacity = capacity
+	ti.buckets = make([]*titleEntry, capacity)
+
+	for _, entry := range oldBuckets {
+		for entry != nil {
+			next := entry.next
+			index := djb2(entry.key, capacity)
+			entry.next = ti.buckets[index]
+			ti.buckets[index] = entry
+			entry = next
+		}
+	}
+}
+
+// Clear removes every song from the index, keeping its capacity
+// Time Complexity: O(capacity)
+// Space Complexity: O(1)
+func (ti *TitleIndex) Clear() {
+	for i := range ti.buckets {
+		ti.buckets[i] = nil
+	}
+	ti.titles = 0
+	ti.songs = 0
+}
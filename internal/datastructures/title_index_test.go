@@ -0,0 +1,75 @@
+package datastructures
+
+import (
+	"fmt"
+	"src/internal/models"
+	"testing"
+)
+
+func newTitleSong(id, title string) *models.Song {
+	return models.NewSong(id, title, "Artist", "Album", "Rock", "Alternative", "Chill", 200, 120)
+}
+
+func TestTitleIndex_SharedTitles(t *testing.T) {
+	index := NewTitleIndex(4)
+	first := newTitleSong("1", "Hurt")
+	second := newTitleSong("2", "  HURT ")
+	index.Add(first)
+	index.Add(second)
+	index.Add(newTitleSong("3", "Hurt  Me"))
+	index.Add(first)
+
+	if index.Len() != 3 || index.TitleCount() != 2 {
+		t.Errorf("Expected 3 songs under 2 titles, got %d under %d", index.Len(), index.TitleCount())
+	}
+	if got := candidateIDs(index.Get("hurt")); fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Get(hurt) = %v, want [1 2]", got)
+	}
+	if got := candidateIDs(index.Get("hurt me")); fmt.Sprint(got) != "[3]" {
+		t.Errorf("Expected inner whitespace to be collapsed, got %v", got)
+	}
+	if got := index.Get("Missing"); len(got) != 0 {
+		t.Errorf("Expected no songs for an unknown title, got %v", got)
+	}
+
+	if !index.Remove(first) || index.Remove(first) {
+		t.Error("A song should be removed exactly once")
+	}
+	if got := candidateIDs(index.Get("Hurt")); fmt.Sprint(got) != "[2]" || index.Contains(first) || !index.Contains(second) {
+		t.Errorf("Expected only the second song left under Hurt, got %v", got)
+	}
+	index.Remove(second)
+	if index.TitleCount() != 1 || len(index.Get("Hurt")) != 0 {
+		t.Error("A title should be dropped with its last song")
+	}
+}
+
+func TestTitleIndex_ResizeAndShrink(t *testing.T) {
+	index := NewTitleIndex(4)
+	songs := make([]*models.Song, 0, 100)
+	for i := 0; i < 100; i++ {
+		song := newTitleSong(fmt.Sprint(i), fmt.Sprintf("Song %d", i))
+		songs = append(songs, song)
+		index.Add(song)
+	}
+	if index.GetCapacity() <= 4 || index.GetLoadFactor() > 2 {
+		t.Errorf("Expected the index to grow, capacity %d load %.2f", index.GetCapacity(), index.GetLoadFactor())
+	}
+
+	for _, song := range songs[10:] {
+		index.Remove(song)
+	}
+	if capacity := index.ShrinkToFit(4); capacity != 10 {
+		t.Errorf("Expected one bucket per remaining title, got %d", capacity)
+	}
+	for _, song := range songs[:10] {
+		if !index.Contains(song) {
+			t.Errorf("Song %s lost while shrinking", song.ID)
+		}
+	}
+
+	index.Clear()
+	if index.Len() != 0 || index.TitleCount() != 0 || len(index.Get("Song 1")) != 0 {
+		t.Error("Clear should empty the index")
+	}
+}
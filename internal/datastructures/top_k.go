@@ -0,0 +1,121 @@
+package datastructures
+
+import "src/internal/models"
+
+// rankedSong pairs a song with its arrival order so TopK breaks ties like a stable sort
+type rankedSong struct {
+	song  *models.Song
+	index int
+}
+
+// TopK keeps the k best songs pushed so far in a bounded min-heap, weakest kept song at the root,
+// so the best k of m songs are found in one pass without sorting them all
+// less(a, b) reports whether a ranks before b; ties keep their push order, matching a stable sort
+// Time Complexity: O(log k) per push, O(k log k) for Results
+// Space Complexity: O(k)
+type TopK struct {
+	k      int
+	less   func(a, b *models.Song) bool
+	heap   []rankedSong
+	pushed int
+}
+
+// NewTopK creates an empty collector for the k best songs under less
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewTopK(k int, less func(a, b *models.Song) bool) *TopK {
+	return &TopK{k: max(k, 0), less: less}
+}
+
+// ranksBefore orders songs by less, equal songs by push order
+// Time Complexity: O(1) plus two calls to less
+// Space Complexity: O(1)
+func (tk *TopK) ranksBefore(a, b rankedSong) bool {
+	if tk.less(a.song, b.song) {
+		return true
+	}
+	if tk.less(b.song, a.song) {
+		return false
+	}
+	return a.index < b.index
+}
+
+// Push offers a song, keeping it when fewer than k songs are held or it ranks before the weakest one
+// Time Complexity: O(log k)
+// Space Complexity: O(1)
+func (tk *TopK) Push(song *models.Song) {
+	candidate := rankedSong{song: song, index: tk.pushed}
+	tk.pushed++
+
+	if len(tk.heap) < tk.k {
+		tk.heap = append(tk.heap, candidate)
+		tk.siftUp(len(tk.heap) - 1)
+		return
+	}
+
+	// Replace the weakest kept song when the candidate ranks before it
+	if tk.k > 0 && tk.ranksBefore(candidate, tk.heap[0]) {
+		tk.heap[0] = candidate
+		tk.siftDown(0)
+	}
+}
+
+// Len returns the number of songs currently held, at most k
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (tk *TopK) Len() int {
+	return len(tk.heap)
+}
+
+// Results returns the held songs best first without changing the collector
+// Time Complexity: O(k log k)
+// Space Complexity: O(k)
+func (tk *TopK) Results() []*models.Song {
+	drained := &TopK{k: tk.k, less: tk.less, heap: append([]rankedSong(nil), tk.heap...)}
+
+	// Pop the weakest song into the back of the result until the heap is empty
+	result := make([]*models.Song, len(drained.heap))
+	for i := len(drained.heap) - 1; i >= 0; i-- {
+		result[i] = drained.heap[0].song
+		last := len(drained.heap) - 1
+		drained.heap[0] = drained.heap[last]
+		drained.heap = drained.heap[:last]
+		drained.siftDown(0)
+	}
+	return result
+}
+
+// siftUp moves the entry at i towards the root while it is weaker than its parent
+// Time Complexity: O(log k)
+// Space Complexity: O(1)
+func (tk *TopK) siftUp(child int) {
+	for child > 0 {
+		parent := (child - 1) / 2
+		if !tk.ranksBefore(tk.heap[parent], tk.heap[child]) {
+			return
+		}
+		tk.heap[parent], tk.heap[child] = tk.heap[child], tk.heap[parent]
+		child = parent
+	}
+}
+
+// siftDown restores the heap below i, where every parent ranks after its children
+// Time Complexity: O(log k)
+// Space Complexity: O(1)
+func (tk *TopK) siftDown(i int) {
+	for {
+		weakest := i
+		left, right := 2*i+1, 2*i+2
+		if left < len(tk.heap) && tk.ranksBefore(tk.heap[weakest], tk.heap[left]) {
+			weakest = left
+		}
+		if right < len(tk.heap) && tk.ranksBefore(tk.heap[weakest], tk.heap[right]) {
+			weakest = right
+		}
+		if weakest == i {
+			return
+		}
+		tk.heap[i], tk.heap[weakest] = tk.heap[weakest], tk.heap[i]
+		i = weakest
+	}
+}
@@ -0,0 +1,46 @@
+package datastructures
+
+import (
+	"src/internal/models"
+	"testing"
+)
+
+func TestTopK_StreamsBestSongs(t *testing.T) {
+	byPlays := func(a, b *models.Song) bool { return a.PlayCount > b.PlayCount }
+	top := NewTopK(3, byPlays)
+
+	for i, plays := range []int{5, 1, 9, 5, 7, 0, 9} {
+		song := models.NewSong(string(rune('a'+i)), "Song", "Artist", "Album", "Rock", "Alternative", "Chill", 200, 120)
+		song.PlayCount = plays
+		top.Push(song)
+		if top.Len() > 3 {
+			t.Fatalf("Expected at most 3 held songs, got %d", top.Len())
+		}
+	}
+
+	// Equal play counts keep their push order
+	got := candidateIDs(top.Results())
+	want := []string{"c", "g", "e"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+
+	// Results leaves the collector intact
+	if again := top.Results(); len(again) != 3 || top.Len() != 3 {
+		t.Errorf("Expected Results to be repeatable, got %v", candidateIDs(again))
+	}
+}
+
+func TestTopK_ZeroK(t *testing.T) {
+	top := NewTopK(0, func(a, b *models.Song) bool { return a.Duration > b.Duration })
+	top.Push(models.NewSong("1", "Song", "Artist", "Album", "Rock", "Alternative", "Chill", 200, 120))
+	if top.Len() != 0 || len(top.Results()) != 0 {
+		t.Error("Expected a zero-sized TopK to hold nothing")
+	}
+}
@@ -0,0 +1,180 @@
+package datastructures
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TrigramMatch is one result of a TrigramIndex search
+// Score is the fraction of the query's trigrams found in the field, from 0 to 1
+type TrigramMatch struct {
+	ID    string
+	Field string
+	Score float64
+}
+
+// trigramKey identifies one indexed field of one entry
+type trigramKey struct {
+	id    string
+	field string
+}
+
+// TrigramIndex is an inverted index from character trigrams to the entry fields containing them
+// Each word is padded with two leading spaces and one trailing space before it is split, so word
+// prefixes and short queries still share trigrams with the text; matching is case-insensitive
+// Time Complexity: O(k) per indexed field of length k, O(g * p) per search where g is the number of
+// query trigrams and p the average posting list length
+// Space Complexity: O(t) where t is the total number of trigrams across indexed fields
+type TrigramIndex struct {
+	postings map[string]map[trigramKey]struct{}
+	grams    map[trigramKey][]string // Distinct trigrams of each field, for removal and tie-breaks
+	fields   map[string][]trigramKey // Fields indexed for each entry ID
+}
+
+// NewTrigramIndex creates an empty trigram index
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewTrigramIndex() *TrigramIndex {
+	return &TrigramIndex{
+		postings: make(map[string]map[trigramKey]struct{}),
+		grams:    make(map[trigramKey][]string),
+		fields:   make(map[string][]trigramKey),
+	}
+}
+
+// Add indexes text under the entry's field, replacing what was indexed for that field before
+// Time Complexity: O(k) where k is the text length
+// Space Complexity: O(k)
+func (ti *TrigramIndex) Add(id, field, text string) {
+	key := trigramKey{id: id, field: field}
+	if _, exists := ti.grams[key]; exists {
+		ti.removeField(key)
+	} else {
+		ti.fields[id] = append(ti.fields[id], key)
+	}
+
+	grams := Trigrams(text)
+	for _, gram := range grams {
+		entries, ok := ti.postings[gram]
+		if !ok {
+			entries = make(map[trigramKey]struct{})
+			ti.postings[gram] = entries
+		}
+		entries[key] = struct{}{}
+	}
+	ti.grams[key] = grams
+}
+
+// Remove drops every field indexed for the entry
+// Time Complexity: O(k) where k is the total length of the entry's fields
+// Space Complexity: O(1)
+func (ti *TrigramIndex) Remove(id string) {
+	for _, key := range ti.fields[id] {
+		ti.removeField(key)
+		delete(ti.grams, key)
+	}
+	delete(ti.fields, id)
+}
+
+// removeField unlinks one field from the posting lists, dropping lists that become empty
+// Time Complexity: O(k) where k is the field length
+// Space Complexity: O(1)
+func (ti *TrigramIndex) removeField(key trigramKey) {
+	for _, gram := range ti.grams[key] {
+		entries := ti.postings[gram]
+		delete(entries, key)
+		if len(entries) == 0 {
+			delete(ti.postings, gram)
+		}
+	}
+}
+
+// Len returns the number of indexed entries
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (ti *TrigramIndex) Len() int {
+	return len(ti.fields)
+}
+
+// Clear removes every entry
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (ti *TrigramIndex) Clear() {
+	ti.postings = make(map[string]map[trigramKey]struct{})
+	ti.grams = make(map[trigramKey][]string)
+	ti.fields = make(map[string][]trigramKey)
+}
+
+// Search returns the entries whose fields share at least minScore of the query's trigrams
+// Each entry appears once with its best field; results are ordered by score, then by how
+// little extra text the field has, then by ID
+// Time Complexity: O(g * p + m log m) where m is the number of matches
+// Space Complexity: O(m)
+func (ti *TrigramIndex) Search(query string, minScore float64) []TrigramMatch {
+	grams := Trigrams(query)
+	if len(grams) == 0 {
+		return []TrigramMatch{}
+	}
+
+	shared := make(map[trigramKey]int)
+	for _, gram := range grams {
+		for key := range ti.postings[gram] {
+			shared[key]++
+		}
+	}
+
+	best := make(map[string]TrigramMatch)
+	extra := make(map[string]int)
+	for key, count := range shared {
+		score := float64(count) / float64(len(grams))
+		if score < minScore {
+			continue
+		}
+		unmatched := len(ti.grams[key]) - count
+		if current, ok := best[key.id]; ok && (score < current.Score || (score == current.Score && unmatched >= extra[key.id])) {
+			continue
+		}
+		best[key.id] = TrigramMatch{ID: key.id, Field: key.field, Score: score}
+		extra[key.id] = unmatched
+	}
+
+	matches := make([]TrigramMatch, 0, len(best))
+	for _, match := range best {
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if extra[matches[i].ID] != extra[matches[j].ID] {
+			return extra[matches[i].ID] < extra[matches[j].ID]
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// Trigrams returns the distinct padded trigrams of the words in text, lowercased
+// Anything other than letters and digits separates words
+// Time Complexity: O(k) where k is the text length
+// Space Complexity: O(k)
+func Trigrams(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	seen := make(map[string]bool)
+	grams := make([]string, 0)
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			gram := string(padded[i : i+3])
+			if !seen[gram] {
+				seen[gram] = true
+				grams = append(grams, gram)
+			}
+		}
+	}
+	return grams
+}
@@ -0,0 +1,70 @@
+package datastructures
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrigrams(t *testing.T) {
+	got := Trigrams("Go, go!")
+	want := []string{"  g", " go", "go "}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Trigrams() = %q, want %q", got, want)
+	}
+	if len(Trigrams("  ...  ")) != 0 {
+		t.Error("Expected no trigrams without letters or digits")
+	}
+}
+
+func TestTrigramIndex_Search(t *testing.T) {
+	index := NewTrigramIndex()
+	index.Add("1", "title", "Bohemian Rhapsody")
+	index.Add("1", "artist", "Queen")
+	index.Add("2", "title", "Killer Queen")
+	index.Add("2", "artist", "Queen")
+	index.Add("3", "title", "Hotel California")
+
+	matches := index.Search("bohemain", 0.5)
+	if len(matches) != 1 || matches[0].ID != "1" || matches[0].Field != "title" {
+		t.Errorf("Expected the misspelled title to match song 1, got %+v", matches)
+	}
+
+	// Both artists match fully with no extra text, so the tie falls back to the ID
+	matches = index.Search("queen", 0.5)
+	if len(matches) != 2 || matches[0].ID != "1" || matches[0].Score != 1 || matches[0].Field != "artist" {
+		t.Errorf("Expected both Queen songs by artist, got %+v", matches)
+	}
+
+	// Word prefixes match
+	if matches = index.Search("rhap", 0.5); len(matches) != 1 || matches[0].ID != "1" {
+		t.Errorf("Expected a prefix match on song 1, got %+v", matches)
+	}
+
+	if matches = index.Search("zzz", 0.5); len(matches) != 0 {
+		t.Errorf("Expected no matches, got %+v", matches)
+	}
+}
+
+func TestTrigramIndex_ReplaceAndRemove(t *testing.T) {
+	index := NewTrigramIndex()
+	index.Add("1", "title", "Yesterday")
+	index.Add("1", "title", "Tomorrow")
+
+	if matches := index.Search("yesterday", 0.5); len(matches) != 0 {
+		t.Errorf("Expected the replaced text to be gone, got %+v", matches)
+	}
+	if matches := index.Search("tomorrow", 0.5); len(matches) != 1 {
+		t.Errorf("Expected the new text to match, got %+v", matches)
+	}
+
+	index.Remove("1")
+	if index.Len() != 0 || len(index.postings) != 0 {
+		t.Errorf("Expected an empty index after removal, got %d entries and %d trigrams", index.Len(), len(index.postings))
+	}
+
+	index.Add("2", "title", "Help")
+	index.Clear()
+	if index.Len() != 0 || len(index.Search("help", 0)) != 0 {
+		t.Error("Expected Clear to remove every entry")
+	}
+}
@@ -0,0 +1,199 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"src/internal/services"
+	"time"
+)
+
+// Default endpoints of the public APIs, overridable in Config
+const (
+	DefaultSpotifyAPIURL = "https://api.spotify.com/v1"
+	DefaultLastFMAPIURL  = "https://ws.audioscrobbler.com/2.0/"
+)
+
+// DefaultTimeout bounds each request to an external API
+const DefaultTimeout = 15 * time.Second
+
+var (
+	// ErrNotConfigured is returned when the token or API key a source needs isn't set
+	ErrNotConfigured = errors.New("integration not configured")
+	// ErrInvalidSource is returned when a request names no source, both sources or a malformed one
+	ErrInvalidSource = errors.New("invalid import source")
+)
+
+// Config holds the credentials and endpoints of the external music services
+type Config struct {
+	SpotifyToken  string // OAuth bearer token sent to the Spotify Web API
+	LastFMAPIKey  string
+	SpotifyAPIURL string       // Empty uses DefaultSpotifyAPIURL
+	LastFMAPIURL  string       // Empty uses DefaultLastFMAPIURL
+	HTTPClient    *http.Client // Nil uses a client with DefaultTimeout
+	MaxTracks     int          // Stops fetching a Spotify playlist after this many tracks, 0 means unlimited
+}
+
+// ConfigFromEnv reads SPOTIFY_TOKEN and LASTFM_API_KEY; unset values leave that source disabled
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func ConfigFromEnv() Config {
+	return Config{
+		SpotifyToken: os.Getenv("SPOTIFY_TOKEN"),
+		LastFMAPIKey: os.Getenv("LASTFM_API_KEY"),
+	}
+}
+
+// Track is one song as described by an external service, before it is mapped to a models.Song
+type Track struct {
+	Title    string
+	Artist   string
+	Album    string
+	Duration int // in seconds, 0 when the service doesn't report it
+}
+
+// ImportRequest names the source to import from; exactly one of URL and LastFMUser must be set
+type ImportRequest struct {
+	URL        string `json:"url"`         // Spotify playlist URL, spotify:playlist: URI or bare playlist ID
+	LastFMUser string `json:"lastfm_user"` // Imports the user's top tracks
+	Limit      int    `json:"limit"`       // Last.fm only, 0 uses LastFMDefaultLimit
+}
+
+// Progress stages reported while importing
+const (
+	StageFetching  = "fetching"
+	StageImporting = "importing"
+)
+
+// Progress holds running counts for an integration import
+// Fetched counts tracks received from the service; the embedded counts cover tracks added to the engine
+type Progress struct {
+	Stage   string `json:"stage"`
+	Fetched int    `json:"fetched"`
+	services.ImportProgress
+}
+
+// ProgressFunc receives progress after every page fetched and every
+// services.CSVImportProgressInterval tracks imported, and once more when the import ends
+type ProgressFunc func(Progress)
+
+// Client fetches tracks from Spotify and Last.fm and loads them into a playlist
+type Client struct {
+	config Config
+	http   *http.Client
+}
+
+// NewClient creates a client for the given config, filling in the default endpoints
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewClient(config Config) *Client {
+	if config.SpotifyAPIURL == "" {
+		config.SpotifyAPIURL = DefaultSpotifyAPIURL
+	}
+	if config.LastFMAPIURL == "" {
+		config.LastFMAPIURL = DefaultLastFMAPIURL
+	}
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &Client{config: config, http: httpClient}
+}
+
+// Import fetches every track of the requested source and adds them to the engine in order
+// Genre, subgenre and mood are unknown to both services, so imported songs land in the tree's Unknown buckets
+// Tracks that can't be added (duplicates, invalid fields) are skipped and recorded in errs instead of aborting;
+// err is set when the source can't be fetched, in which case nothing is added;
+// if ctx is cancelled while adding, the import stops and returns the counts so far along with ctx.Err()
+// progress may be nil
+// Time Complexity: O(t * k) where t is the number of tracks and k the number of songs sharing a title (duplicate check)
+// Space Complexity: O(t)
+func (cl *Client) Import(ctx context.Context, engine *services.PlaylistEngine, req ImportRequest, progress ProgressFunc) (result Progress, errs []error, err error) {
+	report := func() {
+		if progress != nil {
+			progress(result)
+		}
+	}
+	fetched := func(page int) {
+		result.Fetched += page
+		report()
+	}
+
+	result.Stage = StageFetching
+	var tracks []Track
+	switch {
+	case req.URL != "" && req.LastFMUser != "":
+		return result, nil, fmt.Errorf("%w: set either url or lastfm_user, not both", ErrInvalidSource)
+	case req.URL != "":
+		tracks, err = cl.FetchSpotifyPlaylist(ctx, req.URL, fetched)
+	case req.LastFMUser != "":
+		tracks, err = cl.FetchLastFMTopTracks(ctx, req.LastFMUser, req.Limit, fetched)
+	default:
+		return result, nil, fmt.Errorf("%w: url or lastfm_user is required", ErrInvalidSource)
+	}
+	if err != nil {
+		return result, nil, err
+	}
+
+	result.Stage = StageImporting
+	for i, track := range tracks {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			report()
+			return result, errs, ctxErr
+		}
+		result.Processed++
+		if _, addErr := engine.AddSong(track.Title, track.Artist, track.Album, "", "", "", track.Duration, 0); addErr != nil {
+			result.Skipped++
+			errs = append(errs, fmt.Errorf("track %d (%s - %s): %v", i+1, track.Artist, track.Title, addErr))
+		} else {
+			result.Added++
+		}
+		if result.Processed%services.CSVImportProgressInterval == 0 {
+			report()
+		}
+	}
+
+	// Final counts, unless the last interval report already covered them
+	if result.Processed == 0 || result.Processed%services.CSVImportProgressInterval != 0 {
+		report()
+	}
+	return result, errs, nil
+}
+
+// getJSON sends a GET request and decodes a successful JSON response into out
+// Non-2xx responses are returned as errors carrying the status and apiError's message when it can be decoded
+// Time Complexity: O(b) where b is the response size
+// Space Complexity: O(b)
+func (cl *Client) getJSON(ctx context.Context, url string, header http.Header, out interface{}, apiError func([]byte) string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cl.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var body json.RawMessage
+		json.NewDecoder(resp.Body).Decode(&body)
+		if message := apiError(body); message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, message)
+		}
+		return errors.New(resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %v", err)
+	}
+	return nil
+}
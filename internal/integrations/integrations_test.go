@@ -0,0 +1,89 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"src/internal/services"
+)
+
+func TestImport_Spotify(t *testing.T) {
+	server := newSpotifyServer(t)
+	client := NewClient(Config{SpotifyToken: "token", SpotifyAPIURL: server.URL})
+	engine := services.NewPlaylistEngine("Imported")
+	engine.AddSong("Breathe", "Pink Floyd", "The Dark Side of the Moon", "Rock", "Progressive", "Calm", 163, 0)
+
+	var reports []Progress
+	result, errs, err := client.Import(context.Background(), engine, ImportRequest{URL: testPlaylistID}, func(p Progress) {
+		reports = append(reports, p)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Fetched != 3 || result.Processed != 3 || result.Added != 2 || result.Skipped != 1 || len(errs) != 1 {
+		t.Errorf("Expected 2 added and the duplicate skipped, got %+v, %v", result, errs)
+	}
+	if engine.GetPlaylistSize() != 3 {
+		t.Errorf("Expected 3 songs in the playlist, got %d", engine.GetPlaylistSize())
+	}
+
+	// One report per fetched page, then the final counts
+	if len(reports) != 3 || reports[0].Stage != StageFetching || reports[1].Fetched != 3 || reports[2].Stage != StageImporting {
+		t.Errorf("Unexpected progress reports %+v", reports)
+	}
+
+	songs, _ := engine.SearchSongByTitle("Under Pressure")
+	if len(songs) != 1 || songs[0].Artist != "Queen, David Bowie" || songs[0].Album != "Hot Space" || songs[0].Duration != 248 {
+		t.Errorf("Expected the track mapped to a song, got %+v", songs)
+	}
+}
+
+func TestImport_LastFM(t *testing.T) {
+	server := newLastFMServer(t)
+	client := NewClient(Config{LastFMAPIKey: "key", LastFMAPIURL: server.URL})
+	engine := services.NewPlaylistEngine("Imported")
+
+	result, errs, err := client.Import(context.Background(), engine, ImportRequest{LastFMUser: "listener", Limit: 10}, nil)
+	if err != nil || len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %v, %v", err, errs)
+	}
+	if result.Added != 2 || engine.GetPlaylistSize() != 2 {
+		t.Errorf("Expected 2 songs added, got %+v", result)
+	}
+}
+
+func TestImport_CancelledWhileAdding(t *testing.T) {
+	server := newSpotifyServer(t)
+	client := NewClient(Config{SpotifyToken: "token", SpotifyAPIURL: server.URL})
+	engine := services.NewPlaylistEngine("Imported")
+
+	// Cancel once every page is fetched, before any track is added
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result, _, err := client.Import(ctx, engine, ImportRequest{URL: testPlaylistID}, func(p Progress) {
+		if p.Fetched == 3 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if result.Fetched != 3 || result.Processed != 0 || engine.GetPlaylistSize() != 0 {
+		t.Errorf("Expected the import to stop before adding, got %+v with %d songs", result, engine.GetPlaylistSize())
+	}
+}
+
+func TestImport_InvalidRequest(t *testing.T) {
+	client := NewClient(Config{SpotifyToken: "token", LastFMAPIKey: "key"})
+	engine := services.NewPlaylistEngine("Imported")
+
+	for _, req := range []ImportRequest{{}, {URL: testPlaylistID, LastFMUser: "listener"}} {
+		if _, _, err := client.Import(context.Background(), engine, req, nil); !errors.Is(err, ErrInvalidSource) {
+			t.Errorf("Expected ErrInvalidSource for %+v, got %v", req, err)
+		}
+	}
+	if engine.GetPlaylistSize() != 0 {
+		t.Error("Expected nothing imported from an invalid request")
+	}
+}
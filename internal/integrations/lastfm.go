@@ -0,0 +1,85 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Last.fm top track limits: the default when a request leaves it unset and the most one request may ask for
+const (
+	LastFMDefaultLimit = 50
+	LastFMMaxLimit     = 1000
+)
+
+// lastFMTopTracks is the response of user.gettoptracks
+// Last.fm reports some errors with a 200 status, so the error fields are decoded alongside the tracks
+type lastFMTopTracks struct {
+	Error     int    `json:"error"`
+	Message   string `json:"message"`
+	TopTracks struct {
+		Track []struct {
+			Name     string `json:"name"`
+			Duration string `json:"duration"` // Seconds, as a string
+			Artist   struct {
+				Name string `json:"name"`
+			} `json:"artist"`
+		} `json:"track"`
+	} `json:"toptracks"`
+}
+
+// FetchLastFMTopTracks returns a Last.fm user's most played tracks, most played first
+// limit is clamped to LastFMMaxLimit, 0 or less uses LastFMDefaultLimit
+// Last.fm doesn't report the album of top tracks, so Album is left empty
+// fetched, when not nil, receives the number of tracks returned
+// Time Complexity: O(t) where t is the number of tracks, in one request
+// Space Complexity: O(t)
+func (cl *Client) FetchLastFMTopTracks(ctx context.Context, user string, limit int, fetched func(int)) ([]Track, error) {
+	if cl.config.LastFMAPIKey == "" {
+		return nil, fmt.Errorf("%w: LASTFM_API_KEY is not set", ErrNotConfigured)
+	}
+	if limit <= 0 {
+		limit = LastFMDefaultLimit
+	}
+	limit = min(limit, LastFMMaxLimit)
+
+	query := url.Values{}
+	query.Set("method", "user.gettoptracks")
+	query.Set("user", user)
+	query.Set("api_key", cl.config.LastFMAPIKey)
+	query.Set("format", "json")
+	query.Set("limit", strconv.Itoa(limit))
+
+	var response lastFMTopTracks
+	if err := cl.getJSON(ctx, cl.config.LastFMAPIURL+"?"+query.Encode(), nil, &response, lastFMErrorMessage); err != nil {
+		return nil, fmt.Errorf("lastfm: %w", err)
+	}
+	if response.Error != 0 {
+		return nil, fmt.Errorf("lastfm: error %d: %s", response.Error, response.Message)
+	}
+
+	tracks := make([]Track, 0, len(response.TopTracks.Track))
+	for _, track := range response.TopTracks.Track {
+		duration, _ := strconv.Atoi(track.Duration)
+		tracks = append(tracks, Track{
+			Title:    track.Name,
+			Artist:   track.Artist.Name,
+			Duration: max(duration, 0),
+		})
+	}
+	if fetched != nil {
+		fetched(len(tracks))
+	}
+	return tracks, nil
+}
+
+// lastFMErrorMessage reads the message of an API error body: {"error": 6, "message": "..."}
+func lastFMErrorMessage(body []byte) string {
+	var apiError struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(body, &apiError)
+	return apiError.Message
+}
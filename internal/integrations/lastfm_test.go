@@ -0,0 +1,65 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newLastFMServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("method") != "user.gettoptracks" || query.Get("api_key") != "key" || query.Get("format") != "json" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+
+		switch query.Get("user") {
+		case "listener":
+			fmt.Fprintf(w, `{"toptracks": {"track": [
+				{"name": "Karma Police", "duration": "264", "artist": {"name": "Radiohead"}},
+				{"name": "Unknown Length", "duration": "0", "artist": {"name": "Radiohead"}}
+			], "@attr": {"user": "listener", "perPage": %q}}}`, query.Get("limit"))
+		case "private":
+			fmt.Fprint(w, `{"error": 17, "message": "Login: User required to be logged in"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": 6, "message": "User not found"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchLastFMTopTracks(t *testing.T) {
+	server := newLastFMServer(t)
+	client := NewClient(Config{LastFMAPIKey: "key", LastFMAPIURL: server.URL})
+
+	fetched := 0
+	tracks, err := client.FetchLastFMTopTracks(context.Background(), "listener", 0, func(n int) { fetched += n })
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := Track{Title: "Karma Police", Artist: "Radiohead", Duration: 264}
+	if len(tracks) != 2 || tracks[0] != want || tracks[1].Duration != 0 || fetched != 2 {
+		t.Errorf("Expected 2 mapped tracks, got %+v (fetched %d)", tracks, fetched)
+	}
+}
+
+func TestFetchLastFMTopTracks_Errors(t *testing.T) {
+	server := newLastFMServer(t)
+	client := NewClient(Config{LastFMAPIKey: "key", LastFMAPIURL: server.URL})
+
+	if _, err := NewClient(Config{}).FetchLastFMTopTracks(context.Background(), "listener", 0, nil); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Expected ErrNotConfigured without an API key, got %v", err)
+	}
+	if _, err := client.FetchLastFMTopTracks(context.Background(), "nobody", 0, nil); err == nil || !strings.Contains(err.Error(), "User not found") {
+		t.Errorf("Expected the API's error message, got %v", err)
+	}
+	if _, err := client.FetchLastFMTopTracks(context.Background(), "private", 0, nil); err == nil || !strings.Contains(err.Error(), "error 17") {
+		t.Errorf("Expected an error reported with a 200 status to fail, got %v", err)
+	}
+}
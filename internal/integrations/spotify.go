@@ -0,0 +1,156 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// SpotifyPageSize is the number of playlist items requested per page, the Web API's maximum
+const SpotifyPageSize = 100
+
+// SpotifyMaxPages bounds the pages fetched for one playlist; Spotify playlists hold at most 10,000 items
+const SpotifyMaxPages = 10000 / SpotifyPageSize
+
+// spotifyIDPattern matches a base62 Spotify ID
+var spotifyIDPattern = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+
+// spotifyPage is one page of GET /playlists/{id}/tracks
+type spotifyPage struct {
+	Items []struct {
+		Track *struct {
+			Type       string `json:"type"`
+			Name       string `json:"name"`
+			DurationMS int    `json:"duration_ms"`
+			Album      struct {
+				Name string `json:"name"`
+			} `json:"album"`
+			Artists []struct {
+				Name string `json:"name"`
+			} `json:"artists"`
+		} `json:"track"`
+	} `json:"items"`
+	Next string `json:"next"` // Absolute URL of the next page, empty on the last one
+}
+
+// ParseSpotifyPlaylistID extracts the playlist ID from an open.spotify.com playlist URL,
+// a spotify:playlist: URI or a bare ID
+// Time Complexity: O(k) where k is the length of the input
+// Space Complexity: O(k)
+func ParseSpotifyPlaylistID(source string) (string, error) {
+	source = strings.TrimSpace(source)
+	id := source
+
+	switch {
+	case strings.HasPrefix(source, "spotify:playlist:"):
+		id = strings.TrimPrefix(source, "spotify:playlist:")
+	case strings.Contains(source, "://"):
+		parsed, err := url.Parse(source)
+		if err != nil || parsed.Host != "open.spotify.com" {
+			return "", fmt.Errorf("%w: %q is not an open.spotify.com playlist URL", ErrInvalidSource, source)
+		}
+		// Localized links carry a prefix such as /intl-de/playlist/<id>
+		segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+		if len(segments) < 2 || segments[len(segments)-2] != "playlist" {
+			return "", fmt.Errorf("%w: %q is not an open.spotify.com playlist URL", ErrInvalidSource, source)
+		}
+		id = segments[len(segments)-1]
+	}
+
+	if !spotifyIDPattern.MatchString(id) {
+		return "", fmt.Errorf("%w: invalid Spotify playlist ID %q", ErrInvalidSource, id)
+	}
+	return id, nil
+}
+
+// FetchSpotifyPlaylist returns every track of a Spotify playlist in playlist order, following the API's pages
+// Removed tracks and podcast episodes are left out; several artists are joined with ", "
+// Fetching stops once Config.MaxTracks tracks are kept; a next page on another host than SpotifyAPIURL,
+// which would receive the token, or more than SpotifyMaxPages pages fail the fetch
+// fetched, when not nil, receives the number of tracks kept from each page
+// Time Complexity: O(t) where t is the number of tracks, in ceil(t / SpotifyPageSize) requests
+// Space Complexity: O(t)
+func (cl *Client) FetchSpotifyPlaylist(ctx context.Context, playlistURL string, fetched func(int)) ([]Track, error) {
+	if cl.config.SpotifyToken == "" {
+		return nil, fmt.Errorf("%w: SPOTIFY_TOKEN is not set", ErrNotConfigured)
+	}
+	id, err := ParseSpotifyPlaylistID(playlistURL)
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL, err := url.Parse(cl.config.SpotifyAPIURL)
+	if err != nil {
+		return nil, fmt.Errorf("spotify: invalid API URL: %v", err)
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+cl.config.SpotifyToken)
+
+	tracks := []Track{}
+	next := fmt.Sprintf("%s/playlists/%s/tracks?limit=%d", strings.TrimSuffix(cl.config.SpotifyAPIURL, "/"), id, SpotifyPageSize)
+	for pages := 0; next != ""; pages++ {
+		if pages == SpotifyMaxPages {
+			return nil, fmt.Errorf("spotify: playlist has more than %d pages", SpotifyMaxPages)
+		}
+		nextURL, err := url.Parse(next)
+		if err != nil || nextURL.Scheme != apiURL.Scheme || nextURL.Host != apiURL.Host {
+			return nil, fmt.Errorf("spotify: refusing to follow next page %q outside %s", next, cl.config.SpotifyAPIURL)
+		}
+
+		var page spotifyPage
+		if err := cl.getJSON(ctx, next, header, &page, spotifyErrorMessage); err != nil {
+			return nil, fmt.Errorf("spotify: %w", err)
+		}
+
+		kept := 0
+		for _, item := range page.Items {
+			track := item.Track
+			if track == nil || track.Type == "episode" || track.Name == "" {
+				continue
+			}
+			artists := make([]string, 0, len(track.Artists))
+			for _, artist := range track.Artists {
+				artists = append(artists, artist.Name)
+			}
+			tracks = append(tracks, Track{
+				Title:    track.Name,
+				Artist:   strings.Join(artists, ", "),
+				Album:    track.Album.Name,
+				Duration: (track.DurationMS + 500) / 1000,
+			})
+			kept++
+			if cl.full(tracks) {
+				break
+			}
+		}
+		if fetched != nil {
+			fetched(kept)
+		}
+		if cl.full(tracks) {
+			break
+		}
+		next = page.Next
+	}
+	return tracks, nil
+}
+
+// full reports whether tracks has reached Config.MaxTracks
+func (cl *Client) full(tracks []Track) bool {
+	return cl.config.MaxTracks > 0 && len(tracks) >= cl.config.MaxTracks
+}
+
+// spotifyErrorMessage reads the message of a Web API error body: {"error": {"status": 401, "message": "..."}}
+func spotifyErrorMessage(body []byte) string {
+	var apiError struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	json.Unmarshal(body, &apiError)
+	return apiError.Error.Message
+}
@@ -0,0 +1,148 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testPlaylistID = "37i9dQZF1DXcBWIGoYBM5M"
+
+func TestParseSpotifyPlaylistID(t *testing.T) {
+	valid := []string{
+		"https://open.spotify.com/playlist/" + testPlaylistID,
+		"https://open.spotify.com/playlist/" + testPlaylistID + "?si=abc123",
+		"https://open.spotify.com/intl-de/playlist/" + testPlaylistID,
+		"spotify:playlist:" + testPlaylistID,
+		" " + testPlaylistID + " ",
+	}
+	for _, source := range valid {
+		if id, err := ParseSpotifyPlaylistID(source); err != nil || id != testPlaylistID {
+			t.Errorf("ParseSpotifyPlaylistID(%q) = %q, %v", source, id, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"https://example.com/playlist/" + testPlaylistID,
+		"https://open.spotify.com/album/" + testPlaylistID,
+		"spotify:playlist:short",
+	}
+	for _, source := range invalid {
+		if _, err := ParseSpotifyPlaylistID(source); !errors.Is(err, ErrInvalidSource) {
+			t.Errorf("Expected ErrInvalidSource for %q, got %v", source, err)
+		}
+	}
+}
+
+// newSpotifyServer serves a two-page playlist, the second page holding a removed track and an episode
+func newSpotifyServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": {"status": 401, "message": "Invalid access token"}}`)
+			return
+		}
+		if r.URL.Path != "/playlists/"+testPlaylistID+"/tracks" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("offset") == "" {
+			fmt.Fprintf(w, `{"items": [
+				{"track": {"type": "track", "name": "Time", "duration_ms": 413200, "album": {"name": "The Dark Side of the Moon"}, "artists": [{"name": "Pink Floyd"}]}},
+				{"track": {"type": "track", "name": "Under Pressure", "duration_ms": 248000, "album": {"name": "Hot Space"}, "artists": [{"name": "Queen"}, {"name": "David Bowie"}]}}
+			], "next": "%s/playlists/%s/tracks?offset=2"}`, server.URL, testPlaylistID)
+			return
+		}
+		fmt.Fprint(w, `{"items": [
+			{"track": null},
+			{"track": {"type": "episode", "name": "Podcast", "duration_ms": 3600000}},
+			{"track": {"type": "track", "name": "Breathe", "duration_ms": 163000, "album": {"name": "The Dark Side of the Moon"}, "artists": [{"name": "Pink Floyd"}]}}
+		], "next": null}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchSpotifyPlaylist(t *testing.T) {
+	server := newSpotifyServer(t)
+	client := NewClient(Config{SpotifyToken: "token", SpotifyAPIURL: server.URL})
+
+	var pages []int
+	tracks, err := client.FetchSpotifyPlaylist(context.Background(), "spotify:playlist:"+testPlaylistID, func(n int) {
+		pages = append(pages, n)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tracks) != 3 || fmt.Sprint(pages) != "[2 1]" {
+		t.Fatalf("Expected 3 tracks over pages [2 1], got %v over %v", tracks, pages)
+	}
+
+	want := Track{Title: "Time", Artist: "Pink Floyd", Album: "The Dark Side of the Moon", Duration: 413}
+	if tracks[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, tracks[0])
+	}
+	if tracks[1].Artist != "Queen, David Bowie" || tracks[2].Title != "Breathe" {
+		t.Errorf("Expected joined artists and the second page in order, got %+v", tracks)
+	}
+}
+
+func TestFetchSpotifyPlaylist_Errors(t *testing.T) {
+	server := newSpotifyServer(t)
+
+	if _, err := NewClient(Config{}).FetchSpotifyPlaylist(context.Background(), testPlaylistID, nil); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Expected ErrNotConfigured without a token, got %v", err)
+	}
+
+	_, err := NewClient(Config{SpotifyToken: "expired", SpotifyAPIURL: server.URL}).FetchSpotifyPlaylist(context.Background(), testPlaylistID, nil)
+	if err == nil || err.Error() != "spotify: 401 Unauthorized: Invalid access token" {
+		t.Errorf("Expected the API's error message, got %v", err)
+	}
+}
+
+func TestFetchSpotifyPlaylist_MaxTracks(t *testing.T) {
+	server := newSpotifyServer(t)
+	client := NewClient(Config{SpotifyToken: "token", SpotifyAPIURL: server.URL, MaxTracks: 2})
+
+	var pages []int
+	tracks, err := client.FetchSpotifyPlaylist(context.Background(), testPlaylistID, func(n int) {
+		pages = append(pages, n)
+	})
+	if err != nil || len(tracks) != 2 || fmt.Sprint(pages) != "[2]" {
+		t.Errorf("Expected 2 tracks from the first page only, got %v over %v, %v", tracks, pages, err)
+	}
+}
+
+func TestFetchSpotifyPlaylist_Pagination(t *testing.T) {
+	// other would receive the token if a next URL on another host were followed
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected no request to another host, got %s with %q", r.URL, r.Header.Get("Authorization"))
+	}))
+	t.Cleanup(other.Close)
+
+	requests := 0
+	var next string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `{"items": [], "next": %q}`, next)
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(Config{SpotifyToken: "token", SpotifyAPIURL: server.URL})
+
+	next = other.URL + "/playlists/" + testPlaylistID + "/tracks?offset=100"
+	if _, err := client.FetchSpotifyPlaylist(context.Background(), testPlaylistID, nil); err == nil {
+		t.Error("Expected an error for a next page on another host")
+	}
+
+	// A server that never runs out of pages is cut off
+	requests = 0
+	next = server.URL + "/playlists/" + testPlaylistID + "/tracks?offset=100"
+	if _, err := client.FetchSpotifyPlaylist(context.Background(), testPlaylistID, nil); err == nil || requests != SpotifyMaxPages {
+		t.Errorf("Expected an error after %d pages, got %v after %d", SpotifyMaxPages, err, requests)
+	}
+}
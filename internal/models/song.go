@@ -2,10 +2,18 @@ package models
 
 import (
 	"fmt"
+	"math"
+	"net/url"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// MaxNoteLength is the maximum number of characters allowed in a song note
+const MaxNoteLength = 500
+
 // Song represents a music track with metadata
+// JSON field names are snake_case, matching the rest of the API's response bodies
 // Time Complexity: O(1) for all field access operations
 // Space Complexity: O(1) per song instance
 type Song struct {
@@ -19,9 +27,14 @@ type Song struct {
 	Mood       string     `json:"mood"`
 	BPM        int        `json:"bpm"`
 	Rating     int        `json:"rating"` // 1-5 stars
-	PlayCount  int        `json:"playcount"`
+	PlayCount  int        `json:"play_count"`
+	SkipCount  int        `json:"skip_count"`
 	AddedAt    time.Time  `json:"added_at"`
 	LastPlayed *time.Time `json:"last_played,omitempty"`
+	Notes      string     `json:"notes"`
+	Tags       []string   `json:"tags,omitempty"`
+	ArtworkURL string     `json:"artwork_url,omitempty"`
+	SourceURL  string     `json:"source_url,omitempty"`
 }
 
 // NewSong creates a new song instance
@@ -44,13 +57,67 @@ func NewSong(id, title, artist, album, genre, subgenre, mood string, duration, b
 	}
 }
 
+// Clone returns a copy of the song that shares no mutable state with the original
+// LastPlayed is only ever replaced, never written through, so the pointer is shared; a nil song clones to nil
+// Time Complexity: O(t) where t is the number of tags
+// Space Complexity: O(t)
+func (s *Song) Clone() *Song {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	clone.Tags = append([]string(nil), s.Tags...)
+	return &clone
+}
+
 // Play increments play count and updates last played time
 // Time Complexity: O(1)
 // Space Complexity: O(1)
 func (s *Song) Play() {
+	s.PlayAt(time.Now())
+}
+
+// PlayAt increments play count and sets last played time to playedAt
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (s *Song) PlayAt(playedAt time.Time) {
 	s.PlayCount++
-	now := time.Now()
-	s.LastPlayed = &now
+	s.LastPlayed = &playedAt
+}
+
+// Skip increments the skip count when the song is skipped before finishing
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (s *Song) Skip() {
+	s.SkipCount++
+}
+
+// PlayRatio returns plays / (plays + skips), or 0 when the song has no interactions
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (s *Song) PlayRatio() float64 {
+	interactions := s.PlayCount + s.SkipCount
+	if interactions == 0 {
+		return 0
+	}
+	return float64(s.PlayCount) / float64(interactions)
+}
+
+// DecayedPlayScore returns the play count weighted by how recently the song was last played
+// The weight halves every halfLife since LastPlayed, so old favourites fade behind recent plays
+// A non-positive halfLife disables decay and returns the raw play count
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (s *Song) DecayedPlayScore(now time.Time, halfLife time.Duration) float64 {
+	if s.PlayCount == 0 || s.LastPlayed == nil {
+		return 0
+	}
+	if halfLife <= 0 {
+		return float64(s.PlayCount)
+	}
+
+	age := max(now.Sub(*s.LastPlayed), 0)
+	return float64(s.PlayCount) * math.Pow(0.5, float64(age)/float64(halfLife))
 }
 
 // SetRating sets the song rating (1-5)
@@ -62,6 +129,89 @@ func (s *Song) SetRating(rating int) {
 	}
 }
 
+// SetNote sets the song's free-form note, trimming surrounding whitespace
+// An empty note clears it; notes longer than MaxNoteLength characters are rejected
+// Time Complexity: O(k) where k is the note length
+// Space Complexity: O(k)
+func (s *Song) SetNote(note string) error {
+	note = strings.TrimSpace(note)
+	if length := utf8.RuneCountInString(note); length > MaxNoteLength {
+		return fmt.Errorf("note is %d characters, maximum is %d", length, MaxNoteLength)
+	}
+
+	s.Notes = note
+	return nil
+}
+
+// ValidateURL checks that a link is an absolute http or https URL
+// An empty link is valid and means "no link"
+// Time Complexity: O(k) where k is the URL length
+// Space Complexity: O(k)
+func ValidateURL(link string) error {
+	if link == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid URL %q: must be an absolute http or https URL", link)
+	}
+	return nil
+}
+
+// SetLinks sets the song's artwork and source URLs after trimming and validating both
+// Empty values clear a link; if either URL is invalid neither is changed
+// Time Complexity: O(k) where k is the URL length
+// Space Complexity: O(k)
+func (s *Song) SetLinks(artworkURL, sourceURL string) error {
+	artworkURL = strings.TrimSpace(artworkURL)
+	sourceURL = strings.TrimSpace(sourceURL)
+
+	if err := ValidateURL(artworkURL); err != nil {
+		return fmt.Errorf("artwork: %w", err)
+	}
+	if err := ValidateURL(sourceURL); err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+
+	s.ArtworkURL = artworkURL
+	s.SourceURL = sourceURL
+	return nil
+}
+
+// NormalizeTag lowercases and trims a tag so "Favorites " and "favorites" are the same tag
+// Time Complexity: O(k) where k is the tag length
+// Space Complexity: O(k)
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// HasTag reports whether the song carries the given tag after normalization
+// Time Complexity: O(t) where t is the number of tags on the song
+// Space Complexity: O(1)
+func (s *Song) HasTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	for _, existing := range s.Tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag adds a normalized tag to the song, returning false for empty or already present tags
+// Time Complexity: O(t) where t is the number of tags on the song
+// Space Complexity: O(1)
+func (s *Song) AddTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	if tag == "" || s.HasTag(tag) {
+		return false
+	}
+
+	s.Tags = append(s.Tags, tag)
+	return true
+}
+
 // IsSimilar checks if two songs are similar based on genre, mood, and duration
 // Time Complexity: O(1)
 // Space Complexity: O(1)
@@ -77,6 +227,62 @@ func (s *Song) IsSimilar(other *Song) bool {
 	return false
 }
 
+// SimilarityMode controls how closely two songs must match to be considered similar
+type SimilarityMode int
+
+const (
+	// SimilarityDefault requires the same genre and mood with durations within 30 seconds
+	SimilarityDefault SimilarityMode = iota
+	// SimilarityStrict requires the same subgenre and mood
+	SimilarityStrict
+	// SimilarityLoose requires only the same genre
+	SimilarityLoose
+)
+
+// ParseSimilarityMode converts a mode name ("default", "strict", "loose") into a SimilarityMode
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func ParseSimilarityMode(mode string) (SimilarityMode, error) {
+	switch mode {
+	case "default":
+		return SimilarityDefault, nil
+	case "strict":
+		return SimilarityStrict, nil
+	case "loose":
+		return SimilarityLoose, nil
+	default:
+		return SimilarityDefault, fmt.Errorf("unknown similarity mode: %s", mode)
+	}
+}
+
+// String returns the name of the similarity mode
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (m SimilarityMode) String() string {
+	switch m {
+	case SimilarityStrict:
+		return "strict"
+	case SimilarityLoose:
+		return "loose"
+	default:
+		return "default"
+	}
+}
+
+// IsSimilarWithMode checks if two songs are similar under the given similarity mode
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (s *Song) IsSimilarWithMode(other *Song, mode SimilarityMode) bool {
+	switch mode {
+	case SimilarityStrict:
+		return s.SubGenre == other.SubGenre && s.Mood == other.Mood
+	case SimilarityLoose:
+		return s.Genre == other.Genre
+	default:
+		return s.IsSimilar(other)
+	}
+}
+
 // DurationString returns formatted duration as MM:SS
 // Time Complexity: O(1)
 // Space Complexity: O(1)
@@ -101,8 +307,27 @@ func (s *Song) GetMetadata() map[string]interface{} {
 		"mood":        s.Mood,
 		"bpm":         s.BPM,
 		"rating":      s.Rating,
-		"playcount":   s.PlayCount,
+		"play_count":  s.PlayCount,
+		"skip_count":  s.SkipCount,
 		"added_at":    s.AddedAt,
 		"last_played": s.LastPlayed,
+		"notes":       s.Notes,
+		"tags":        s.Tags,
+		"artwork_url": s.ArtworkURL,
+		"source_url":  s.SourceURL,
+	}
+}
+
+// Project returns only the named metadata fields, keyed as in GetMetadata; unknown names are skipped
+// Time Complexity: O(f) where f is the number of fields
+// Space Complexity: O(f)
+func (s *Song) Project(fields []string) map[string]interface{} {
+	metadata := s.GetMetadata()
+	projected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := metadata[field]; ok {
+			projected[field] = value
+		}
 	}
+	return projected
 }
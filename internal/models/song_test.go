@@ -1,6 +1,11 @@
 package models
 
 import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 )
@@ -107,6 +112,35 @@ func TestSong_Play(t *testing.T) {
 	}
 }
 
+func TestSong_PlayAt(t *testing.T) {
+	song := NewSong("test-1", "Test Song", "Test Artist", "Test Album", "Rock", "Alt", "Happy", 180, 120)
+	playedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	song.PlayAt(playedAt)
+	if song.PlayCount != 1 || song.LastPlayed == nil || !song.LastPlayed.Equal(playedAt) {
+		t.Errorf("Song.PlayAt() = count %v, last played %v, want 1 at %v", song.PlayCount, song.LastPlayed, playedAt)
+	}
+}
+
+func TestSong_Clone(t *testing.T) {
+	song := NewSong("1", "Title", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	song.AddTag("live")
+	clone := song.Clone()
+
+	song.Play()
+	song.SetRating(5)
+	song.Tags[0] = "studio"
+	if clone.PlayCount != 0 || clone.LastPlayed != nil || clone.Rating != 0 || clone.Tags[0] != "live" {
+		t.Errorf("Expected the clone to keep its own state, got %+v", clone)
+	}
+	if clone.ID != song.ID || clone.Title != song.Title {
+		t.Errorf("Expected the clone to copy every field, got %+v", clone)
+	}
+	if (*Song)(nil).Clone() != nil {
+		t.Error("Expected a nil song to clone to nil")
+	}
+}
+
 func TestSong_SetRating(t *testing.T) {
 	song := NewSong("test-1", "Test Song", "Test Artist", "Test Album", "Rock", "Alt", "Happy", 180, 120)
 
@@ -188,6 +222,50 @@ func TestSong_IsSimilar(t *testing.T) {
 	}
 }
 
+func TestSong_IsSimilarWithMode(t *testing.T) {
+	baseSong := NewSong("base", "Base Song", "Base Artist", "Base Album", "Rock", "Alternative", "Energetic", 180, 120)
+
+	tests := []struct {
+		name     string
+		other    *Song
+		mode     SimilarityMode
+		expected bool
+	}{
+		{"Default matches genre and mood", NewSong("o1", "T", "A", "Al", "Rock", "Grunge", "Energetic", 200, 120), SimilarityDefault, true},
+		{"Default rejects long duration gap", NewSong("o2", "T", "A", "Al", "Rock", "Alternative", "Energetic", 600, 120), SimilarityDefault, false},
+		{"Strict matches subgenre and mood", NewSong("o3", "T", "A", "Al", "Rock", "Alternative", "Energetic", 600, 120), SimilarityStrict, true},
+		{"Strict rejects different subgenre", NewSong("o4", "T", "A", "Al", "Rock", "Grunge", "Energetic", 180, 120), SimilarityStrict, false},
+		{"Strict rejects different mood", NewSong("o5", "T", "A", "Al", "Rock", "Alternative", "Calm", 180, 120), SimilarityStrict, false},
+		{"Loose matches genre only", NewSong("o6", "T", "A", "Al", "Rock", "Grunge", "Calm", 600, 120), SimilarityLoose, true},
+		{"Loose rejects different genre", NewSong("o7", "T", "A", "Al", "Pop", "Alternative", "Energetic", 180, 120), SimilarityLoose, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := baseSong.IsSimilarWithMode(tt.other, tt.mode)
+			if result != tt.expected {
+				t.Errorf("Song.IsSimilarWithMode(%s) = %v, want %v", tt.mode, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseSimilarityMode(t *testing.T) {
+	for _, name := range []string{"default", "strict", "loose"} {
+		mode, err := ParseSimilarityMode(name)
+		if err != nil {
+			t.Errorf("ParseSimilarityMode(%q) unexpected error: %v", name, err)
+		}
+		if mode.String() != name {
+			t.Errorf("ParseSimilarityMode(%q).String() = %q", name, mode.String())
+		}
+	}
+
+	if _, err := ParseSimilarityMode("fuzzy"); err == nil {
+		t.Error("ParseSimilarityMode() should reject unknown modes")
+	}
+}
+
 func TestSong_DurationString(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -221,7 +299,7 @@ func TestSong_GetMetadata(t *testing.T) {
 
 	metadata := song.GetMetadata()
 
-	expectedKeys := []string{"id", "title", "artist", "album", "duration", "genre", "subgenre", "mood", "bpm", "rating", "playcount", "added_at", "last_played"}
+	expectedKeys := []string{"id", "title", "artist", "album", "duration", "genre", "subgenre", "mood", "bpm", "rating", "play_count", "skip_count", "added_at", "last_played", "notes", "tags"}
 
 	for _, key := range expectedKeys {
 		if _, exists := metadata[key]; !exists {
@@ -238,8 +316,64 @@ func TestSong_GetMetadata(t *testing.T) {
 	if metadata["rating"] != 4 {
 		t.Errorf("Song.GetMetadata() rating = %v, want %v", metadata["rating"], 4)
 	}
-	if metadata["playcount"] != 1 {
-		t.Errorf("Song.GetMetadata() playcount = %v, want %v", metadata["playcount"], 1)
+	if metadata["play_count"] != 1 {
+		t.Errorf("Song.GetMetadata() play_count = %v, want %v", metadata["play_count"], 1)
+	}
+}
+
+func TestSong_Project(t *testing.T) {
+	song := NewSong("test-id", "Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 180, 120)
+
+	projected := song.Project([]string{"id", "title", "unknown"})
+	if len(projected) != 2 || projected["id"] != "test-id" || projected["title"] != "Test Song" {
+		t.Errorf("Project() = %v, want only id and title", projected)
+	}
+	if projected := song.Project(nil); len(projected) != 0 {
+		t.Errorf("Project(nil) = %v, want no fields", projected)
+	}
+}
+
+func TestSong_SetNote(t *testing.T) {
+	song := createTestSong("test-id", "Test Song", "Test Artist")
+
+	if err := song.SetNote("  great for intros  "); err != nil {
+		t.Fatalf("SetNote() error = %v", err)
+	}
+	if song.Notes != "great for intros" {
+		t.Errorf("SetNote() Notes = %q, want %q", song.Notes, "great for intros")
+	}
+
+	// Length is counted in characters, not bytes
+	if err := song.SetNote(strings.Repeat("é", MaxNoteLength)); err != nil {
+		t.Errorf("SetNote() with %d characters should succeed, got %v", MaxNoteLength, err)
+	}
+	if err := song.SetNote(strings.Repeat("a", MaxNoteLength+1)); err == nil {
+		t.Error("SetNote() should reject notes over MaxNoteLength")
+	}
+	if song.Notes != strings.Repeat("é", MaxNoteLength) {
+		t.Error("SetNote() should keep the previous note when rejecting")
+	}
+
+	if err := song.SetNote(""); err != nil || song.Notes != "" {
+		t.Errorf("SetNote(\"\") should clear the note, got %q, %v", song.Notes, err)
+	}
+}
+
+func TestSong_NotesJSONRoundTrip(t *testing.T) {
+	song := createTestSong("test-id", "Test Song", "Test Artist")
+	song.SetNote("great for intros")
+
+	data, err := json.Marshal(song)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var loaded Song
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if loaded.Notes != "great for intros" {
+		t.Errorf("Notes after round trip = %q, want %q", loaded.Notes, "great for intros")
 	}
 }
 
@@ -365,3 +499,160 @@ func TestSongRatingBoundaryValues(t *testing.T) {
 		})
 	}
 }
+
+func TestSong_AddTag(t *testing.T) {
+	song := createTestSong("test-id", "Test Song", "Test Artist")
+
+	if !song.AddTag("  Road Trip ") {
+		t.Error("AddTag() should add a new tag")
+	}
+	if song.AddTag("road trip") {
+		t.Error("AddTag() should not add a duplicate after normalization")
+	}
+	if song.AddTag("") {
+		t.Error("AddTag() should reject an empty tag")
+	}
+	if len(song.Tags) != 1 || song.Tags[0] != "road trip" {
+		t.Errorf("Tags = %v, want [road trip]", song.Tags)
+	}
+	if !song.HasTag("ROAD TRIP") {
+		t.Error("HasTag() should match case-insensitively")
+	}
+}
+
+func TestSong_PlayRatio(t *testing.T) {
+	song := createTestSong("test-id", "Test Song", "Test Artist")
+
+	if song.PlayRatio() != 0 {
+		t.Errorf("PlayRatio() with no interactions = %f, want 0", song.PlayRatio())
+	}
+
+	song.Play()
+	song.Skip()
+	song.Skip()
+	song.Skip()
+
+	if song.SkipCount != 3 {
+		t.Errorf("SkipCount = %d, want 3", song.SkipCount)
+	}
+	if song.PlayRatio() != 0.25 {
+		t.Errorf("PlayRatio() = %f, want 0.25", song.PlayRatio())
+	}
+}
+
+func TestSong_JSONFieldNamesAreSnakeCase(t *testing.T) {
+	snakeCase := regexp.MustCompile(`^[a-z]+(_[a-z]+)*$`)
+
+	// Every field must declare an explicit snake_case JSON name, including future additions
+	songType := reflect.TypeOf(Song{})
+	for i := 0; i < songType.NumField(); i++ {
+		field := songType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !snakeCase.MatchString(name) {
+			t.Errorf("Song.%s JSON name %q is not snake_case", field.Name, name)
+		}
+	}
+
+	song := createTestSong("test-id", "Test Song", "Test Artist")
+	song.Play()
+	song.Skip()
+	song.SetNote("note")
+	song.AddTag("tag")
+
+	data, err := json.Marshal(song)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	for _, key := range []string{"play_count", "skip_count", "added_at", "last_played", "notes", "tags"} {
+		if _, exists := fields[key]; !exists {
+			t.Errorf("Song JSON missing %q: %s", key, data)
+		}
+	}
+	for key := range fields {
+		if !snakeCase.MatchString(key) {
+			t.Errorf("Song JSON key %q is not snake_case", key)
+		}
+	}
+}
+
+func TestSong_DecayedPlayScore(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	halfLife := 30 * 24 * time.Hour
+
+	oldFavourite := createTestSong("old", "Old Favourite", "Artist")
+	oldFavourite.PlayCount = 100
+	playedLongAgo := now.Add(-10 * halfLife)
+	oldFavourite.LastPlayed = &playedLongAgo
+
+	recent := createTestSong("recent", "Recent", "Artist")
+	recent.PlayCount = 10
+	playedYesterday := now.Add(-24 * time.Hour)
+	recent.LastPlayed = &playedYesterday
+
+	if oldFavourite.DecayedPlayScore(now, halfLife) >= recent.DecayedPlayScore(now, halfLife) {
+		t.Errorf("Old favourite scored %f, recent song %f, want old < recent",
+			oldFavourite.DecayedPlayScore(now, halfLife), recent.DecayedPlayScore(now, halfLife))
+	}
+
+	// One half-life halves the score
+	playedOneHalfLifeAgo := now.Add(-halfLife)
+	recent.LastPlayed = &playedOneHalfLifeAgo
+	if score := recent.DecayedPlayScore(now, halfLife); math.Abs(score-5) > 1e-9 {
+		t.Errorf("DecayedPlayScore() after one half-life = %f, want 5", score)
+	}
+
+	if score := recent.DecayedPlayScore(now, 0); score != 10 {
+		t.Errorf("DecayedPlayScore() without decay = %f, want 10", score)
+	}
+	if score := createTestSong("new", "New", "Artist").DecayedPlayScore(now, halfLife); score != 0 {
+		t.Errorf("DecayedPlayScore() for an unplayed song = %f, want 0", score)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		link    string
+		wantErr bool
+	}{
+		{"", false},
+		{"https://example.com/cover.jpg", false},
+		{"http://example.com:8080/a?b=c", false},
+		{"example.com/cover.jpg", true},
+		{"javascript:alert(1)", true},
+		{"ftp://example.com/cover.jpg", true},
+		{"https://", true},
+		{"://bad", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateURL(tt.link); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateURL(%q) error = %v, wantErr %v", tt.link, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSong_SetLinks(t *testing.T) {
+	song := createTestSong("test-id", "Test Song", "Test Artist")
+
+	if err := song.SetLinks(" https://example.com/cover.jpg ", "https://example.com/track"); err != nil {
+		t.Fatalf("SetLinks() error = %v", err)
+	}
+	if song.ArtworkURL != "https://example.com/cover.jpg" || song.SourceURL != "https://example.com/track" {
+		t.Errorf("SetLinks() stored %q and %q", song.ArtworkURL, song.SourceURL)
+	}
+
+	// An invalid source leaves both links untouched
+	if err := song.SetLinks("https://example.com/other.jpg", "not a url"); err == nil {
+		t.Error("SetLinks() should reject an invalid source URL")
+	}
+	if song.ArtworkURL != "https://example.com/cover.jpg" {
+		t.Errorf("Failed SetLinks() should not change the artwork, got %q", song.ArtworkURL)
+	}
+
+	if err := song.SetLinks("", ""); err != nil || song.ArtworkURL != "" || song.SourceURL != "" {
+		t.Errorf("SetLinks() with empty values should clear the links, got %q, %q, %v", song.ArtworkURL, song.SourceURL, err)
+	}
+}
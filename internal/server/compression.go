@@ -0,0 +1,30 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// GzipMinLength is the smallest response body in bytes worth compressing
+const GzipMinLength = 1024
+
+// newGzipMiddleware compresses responses of at least GzipMinLength bytes for clients accepting gzip
+// Streaming responses are skipped so every event reaches the client as soon as it is flushed
+func newGzipMiddleware() echo.MiddlewareFunc {
+	return middleware.GzipWithConfig(middleware.GzipConfig{
+		Skipper:   isStreamingRequest,
+		MinLength: GzipMinLength,
+	})
+}
+
+// isStreamingRequest reports whether a request asks for a streamed (SSE or NDJSON) response
+func isStreamingRequest(c echo.Context) bool {
+	if c.QueryParam("stream") == "true" {
+		return true
+	}
+
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	return strings.Contains(accept, "text/event-stream") || strings.Contains(accept, "application/x-ndjson")
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func setupGzipEcho(bodySize int) *echo.Echo {
+	e := echo.New()
+	e.Use(newGzipMiddleware())
+	e.GET("/data", func(c echo.Context) error {
+		return c.String(http.StatusOK, strings.Repeat("a", bodySize))
+	})
+	return e
+}
+
+func TestGzipMiddleware(t *testing.T) {
+	e := setupGzipEcho(GzipMinLength * 4)
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Header().Get(echo.HeaderContentEncoding) != "gzip" {
+		t.Fatalf("Expected gzip Content-Encoding, got %q", rec.Header().Get(echo.HeaderContentEncoding))
+	}
+	if rec.Body.Len() >= GzipMinLength*4 {
+		t.Errorf("Expected a compressed body, got %d bytes", rec.Body.Len())
+	}
+
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to open gzip body: %v", err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil || string(body) != strings.Repeat("a", GzipMinLength*4) {
+		t.Errorf("Decompressed body doesn't match, got %d bytes, err %v", len(body), err)
+	}
+}
+
+func TestGzipMiddlewareSkips(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		bodySize int
+		header   map[string]string
+	}{
+		{"no accept-encoding", "/data", GzipMinLength * 4, nil},
+		{"below threshold", "/data", GzipMinLength / 2, map[string]string{echo.HeaderAcceptEncoding: "gzip"}},
+		{"stream query", "/data?stream=true", GzipMinLength * 4, map[string]string{echo.HeaderAcceptEncoding: "gzip"}},
+		{"event stream", "/data", GzipMinLength * 4, map[string]string{echo.HeaderAcceptEncoding: "gzip", echo.HeaderAccept: "text/event-stream"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupGzipEcho(tt.bodySize)
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if encoding := rec.Header().Get(echo.HeaderContentEncoding); encoding != "" {
+				t.Errorf("Expected no Content-Encoding, got %q", encoding)
+			}
+			if rec.Body.Len() != tt.bodySize {
+				t.Errorf("Expected %d uncompressed bytes, got %d", tt.bodySize, rec.Body.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"src/internal/datastructures"
+)
+
+// IdempotencyKeyTTL is how long a successful response is replayed for a repeated Idempotency-Key
+const IdempotencyKeyTTL = 5 * time.Minute
+
+// MaxIdempotencyKeys bounds the cached responses, the least recently used key is dropped first
+const MaxIdempotencyKeys = 1000
+
+// idempotentResponse is a cached handler result replayed for repeated requests
+type idempotentResponse struct {
+	status    int
+	body      map[string]interface{}
+	expiresAt time.Time
+}
+
+// idempotencyCache maps Idempotency-Key header values, scoped by the caller, to the first successful response
+// Time Complexity: O(1) average per lookup, O(k) for the periodic expiry sweep
+// Space Complexity: O(k) where k is the number of live keys, at most MaxIdempotencyKeys
+type idempotencyCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	responses *datastructures.LRUCache[string, idempotentResponse]
+	now       func() time.Time
+}
+
+// newIdempotencyCache creates an empty cache whose entries live for ttl
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		ttl:       ttl,
+		responses: datastructures.NewLRUCache[string, idempotentResponse](MaxIdempotencyKeys, nil),
+		now:       time.Now,
+	}
+}
+
+// do returns the cached response for key, or runs fn and caches its result when it succeeds
+// The lock is held while fn runs so concurrent requests with the same key can't both execute
+// Error responses (status >= 400) are not cached so the client can retry
+// Time Complexity: O(k) for the expiry sweep plus the cost of fn
+// Space Complexity: O(1) per cached response
+func (ic *idempotencyCache) do(key string, fn func() (int, map[string]interface{})) (status int, body map[string]interface{}, replayed bool) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	now := ic.now()
+	for _, cachedKey := range ic.responses.Keys() {
+		if response, _ := ic.responses.Peek(cachedKey); !now.Before(response.expiresAt) {
+			ic.responses.Remove(cachedKey)
+		}
+	}
+
+	if response, exists := ic.responses.Get(key); exists {
+		return response.status, response.body, true
+	}
+
+	status, body = fn()
+	if status < 400 {
+		ic.responses.Put(key, idempotentResponse{
+			status:    status,
+			body:      body,
+			expiresAt: now.Add(ic.ttl),
+		})
+	}
+
+	return status, body, false
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyCache(t *testing.T) {
+	cache := newIdempotencyCache(time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	fn := func() (int, map[string]interface{}) {
+		calls++
+		return http.StatusCreated, map[string]interface{}{"call": calls}
+	}
+
+	status, body, replayed := cache.do("key", fn)
+	if status != http.StatusCreated || body["call"] != 1 || replayed {
+		t.Errorf("First call: got %d, %v, replayed=%v", status, body, replayed)
+	}
+
+	status, body, replayed = cache.do("key", fn)
+	if status != http.StatusCreated || body["call"] != 1 || !replayed || calls != 1 {
+		t.Errorf("Repeat call should replay the first response, got %d, %v, replayed=%v, calls=%d", status, body, replayed, calls)
+	}
+
+	// Different keys are independent
+	if _, body, _ := cache.do("other", fn); body["call"] != 2 {
+		t.Errorf("Different key should run fn again, got %v", body)
+	}
+
+	// Entries expire after the TTL
+	now = now.Add(time.Minute)
+	if _, body, replayed := cache.do("key", fn); replayed || body["call"] != 3 {
+		t.Errorf("Expired key should run fn again, got %v, replayed=%v", body, replayed)
+	}
+	if cache.responses.Len() != 1 {
+		t.Errorf("Expected expired entries to be swept, got %d entries", cache.responses.Len())
+	}
+}
+
+func TestIdempotencyCacheIsBounded(t *testing.T) {
+	cache := newIdempotencyCache(time.Minute)
+	fn := func() (int, map[string]interface{}) {
+		return http.StatusCreated, nil
+	}
+
+	for i := 0; i <= MaxIdempotencyKeys; i++ {
+		cache.do(strconv.Itoa(i), fn)
+	}
+
+	if cache.responses.Len() != MaxIdempotencyKeys {
+		t.Errorf("Expected at most %d cached keys, got %d", MaxIdempotencyKeys, cache.responses.Len())
+	}
+	// The oldest key was dropped and runs again
+	if _, _, replayed := cache.do("0", fn); replayed {
+		t.Error("Expected the least recently used key to be evicted")
+	}
+}
+
+func TestIdempotencyCacheSkipsErrors(t *testing.T) {
+	cache := newIdempotencyCache(time.Minute)
+
+	failing := func() (int, map[string]interface{}) {
+		return http.StatusInternalServerError, map[string]interface{}{"error": "boom"}
+	}
+	if _, _, replayed := cache.do("key", failing); replayed {
+		t.Error("First call should not be replayed")
+	}
+
+	succeeded := false
+	cache.do("key", func() (int, map[string]interface{}) {
+		succeeded = true
+		return http.StatusCreated, nil
+	})
+	if !succeeded {
+		t.Error("Error responses should not be cached so the request can be retried")
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// isHTMXRequest reports whether a request was issued by HTMX
+func isHTMXRequest(c echo.Context) bool {
+	return c.Request().Header.Get("HX-Request") == "true"
+}
+
+// isFormRequest reports whether a request body should be read as form fields rather than JSON
+func isFormRequest(c echo.Context) bool {
+	if isHTMXRequest(c) {
+		return true
+	}
+
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	return strings.HasPrefix(contentType, echo.MIMEApplicationForm) || strings.HasPrefix(contentType, echo.MIMEMultipartForm)
+}
+
+// wantsHTML reports whether a dual-purpose endpoint should answer with an HTML fragment
+// HTMX requests always get HTML; otherwise the Accept header decides, and JSON wins ties
+// or when neither type is listed, so API clients sending */* keep getting JSON
+// Time Complexity: O(m) where m is the number of media ranges in the Accept header
+// Space Complexity: O(1)
+func wantsHTML(c echo.Context) bool {
+	if isHTMXRequest(c) {
+		return true
+	}
+
+	htmlQuality, jsonQuality := acceptQuality(c.Request().Header.Get(echo.HeaderAccept))
+	return htmlQuality > jsonQuality
+}
+
+// acceptQuality returns the q-values an Accept header gives text/html and application/json
+// A type that is not listed explicitly gets 0
+func acceptQuality(accept string) (htmlQuality, jsonQuality float64) {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		switch mediaType {
+		case "text/html":
+			htmlQuality = max(htmlQuality, quality)
+		case "application/json":
+			jsonQuality = max(jsonQuality, quality)
+		}
+	}
+	return htmlQuality, jsonQuality
+}
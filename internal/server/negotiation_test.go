@@ -0,0 +1,117 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func setupVersionedEcho() (*echo.Echo, *PlaylistHandlers) {
+	e, handlers := setupTestEcho()
+	registerAPIRoutes(e.Group("/api/v1"), handlers)
+	registerAPIRoutes(e.Group("/api"), handlers)
+	return e, handlers
+}
+
+func TestWantsHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   bool
+	}{
+		{"no headers", nil, false},
+		{"htmx", map[string]string{"HX-Request": "true"}, true},
+		{"html", map[string]string{echo.HeaderAccept: "text/html"}, true},
+		{"json", map[string]string{echo.HeaderAccept: "application/json"}, false},
+		{"wildcard", map[string]string{echo.HeaderAccept: "*/*"}, false},
+		{"browser", map[string]string{echo.HeaderAccept: "text/html,application/xhtml+xml,*/*;q=0.8"}, true},
+		{"json preferred", map[string]string{echo.HeaderAccept: "text/html;q=0.5, application/json"}, false},
+		{"html preferred", map[string]string{echo.HeaderAccept: "application/json;q=0.4, text/html;q=0.9"}, true},
+		{"tie", map[string]string{echo.HeaderAccept: "text/html, application/json"}, false},
+		{"htmx overrides accept", map[string]string{"HX-Request": "true", echo.HeaderAccept: "application/json"}, true},
+	}
+
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			if got := wantsHTML(c); got != tt.want {
+				t.Errorf("wantsHTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIVersionPrefixes(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+
+	for _, prefix := range []string{"/api", "/api/v1"} {
+		req := httptest.NewRequest(http.MethodGet, prefix+"/playlist", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s/playlist: expected status 200, got %d", prefix, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), "Test Song") {
+			t.Errorf("%s/playlist: expected the song in the response, got %s", prefix, rec.Body.String())
+		}
+	}
+}
+
+func TestAcceptNegotiation(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+
+	for _, prefix := range []string{"/api", "/api/v1"} {
+		for _, accept := range []string{echo.MIMEApplicationJSON, echo.MIMETextHTML} {
+			req := httptest.NewRequest(http.MethodPost, prefix+"/playlist/reverse", nil)
+			req.Header.Set(echo.HeaderAccept, accept)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("%s with Accept %s: expected status 200, got %d", prefix, accept, rec.Code)
+			}
+
+			contentType := rec.Header().Get(echo.HeaderContentType)
+			if accept == echo.MIMETextHTML {
+				if !strings.HasPrefix(contentType, echo.MIMETextHTML) || !strings.Contains(rec.Body.String(), "Test Song") {
+					t.Errorf("%s: expected an HTML playlist fragment, got %s %q", prefix, contentType, rec.Body.String())
+				}
+			} else if !strings.HasPrefix(contentType, echo.MIMEApplicationJSON) {
+				t.Errorf("%s: expected a JSON response, got %s", prefix, contentType)
+			}
+		}
+	}
+}
+
+func TestAddSongFormWithoutHTMX(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+
+	form := "title=Form+Song&artist=Form+Artist&duration=200"
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/playlist/songs", strings.NewReader(form))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		t.Errorf("Expected a JSON response, got %s", rec.Header().Get(echo.HeaderContentType))
+	}
+	if handlers.playlists.Active().GetPlaylistSize() != 1 {
+		t.Errorf("Expected 1 song, got %d", handlers.playlists.Active().GetPlaylistSize())
+	}
+}
@@ -1,52 +1,370 @@
 package server
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"io"
+	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"src/internal/datastructures"
+	"src/internal/integrations"
 	"src/internal/models"
 	"src/internal/services"
+	"src/internal/storage"
 
 	"github.com/labstack/echo/v4"
 )
 
+// DefaultMaxResults caps how many items a single list request can ask for
+const DefaultMaxResults = 500
+
+// DefaultDataDir is the directory playlist snapshots are saved to when PLAYLIST_DATA_DIR is unset
+const DefaultDataDir = "data"
+
+// playlistEngineKey is the context key resolvePlaylist stores the requested playlist's engine under
+const playlistEngineKey = "playlist_engine"
+
+// userIDKey and userPlaylistsKey are the context keys resolveUser stores the requesting user under
+const (
+	userIDKey        = "user_id"
+	userPlaylistsKey = "user_playlists"
+)
+
+// UserIDHeader names the user a request acts as; it takes precedence over UserSessionCookie
+const UserIDHeader = "X-User-ID"
+
+// UserSessionCookie remembers the user a browser acts as, set by POST /api/users/:userId/session
+const UserSessionCookie = "playwise_user"
+
 // PlaylistHandlers contains all playlist-related HTTP handlers
 type PlaylistHandlers struct {
-	engine *services.PlaylistEngine
+	playlists   *services.PlaylistManager // The default user's playlists
+	users       *services.UserManager
+	addSongKeys *idempotencyCache
+	maxResults  int
+	store       *storage.FileStore
+	autoSaver   *storage.AutoSaver // nil unless auto-save is enabled
+	// Spotify and Last.fm imports, disabled per service unless its credentials are configured
+	integrations *integrations.Client
 }
 
 // NewPlaylistHandlers creates a new playlist handlers instance
+// PLAYLIST_MAX_SONGS caps the playlist size, unset or 0 means unlimited
+// PLAYLIST_MAX_RESULTS caps count and limit query params, unset or 0 uses DefaultMaxResults
+// PLAYLIST_DUPLICATES picks the duplicate policy (reject, allow or allow_different_album), unset or unknown rejects
+// PLAYLIST_DATA_DIR is where snapshots are saved, unset uses DefaultDataDir
+// PLAYLIST_AUTOSAVE=true loads every saved playlist on startup and saves every playlist after each change;
+// only the default user's playlists are auto-saved, other users' playlists are saved on request
+// SPOTIFY_TOKEN and LASTFM_API_KEY enable importing from Spotify playlists and Last.fm users;
+// Spotify imports stop fetching at PLAYLIST_MAX_SONGS tracks
 func NewPlaylistHandlers() *PlaylistHandlers {
-	return &PlaylistHandlers{
-		engine: services.NewPlaylistEngine("My Playlist"),
+	config := services.DefaultEngineConfig()
+	config.MaxSongs, _ = strconv.Atoi(os.Getenv("PLAYLIST_MAX_SONGS"))
+	config.DuplicatePolicy, _ = services.ParseDuplicatePolicy(os.Getenv("PLAYLIST_DUPLICATES"))
+
+	maxResults, _ := strconv.Atoi(os.Getenv("PLAYLIST_MAX_RESULTS"))
+	if maxResults <= 0 {
+		maxResults = DefaultMaxResults
+	}
+
+	dataDir := os.Getenv("PLAYLIST_DATA_DIR")
+	if dataDir == "" {
+		dataDir = DefaultDataDir
+	}
+
+	integrationConfig := integrations.ConfigFromEnv()
+	integrationConfig.MaxTracks = config.MaxSongs
+
+	ph := &PlaylistHandlers{
+		playlists:    services.NewPlaylistManager("My Playlist", config),
+		addSongKeys:  newIdempotencyCache(IdempotencyKeyTTL),
+		maxResults:   maxResults,
+		store:        storage.NewFileStore(dataDir),
+		integrations: integrations.NewClient(integrationConfig),
+	}
+	ph.users = services.NewUserManager(ph.playlists, config)
+
+	if os.Getenv("PLAYLIST_AUTOSAVE") == "true" {
+		ph.autoSaver = storage.NewAutoSaver(ph.store, ph.playlists.Get)
+		ph.loadSavedPlaylists(config)
+	}
+
+	return ph
+}
+
+// loadSavedPlaylists loads the default user's snapshots into ph.playlists and watches them for auto-save
+// The default playlist is loaded into the active engine; every other snapshot becomes an inactive playlist
+// Other users' snapshots, named "<userID>.<playlistID>" by snapshotKeyFor, are left for explicit loads
+func (ph *PlaylistHandlers) loadSavedPlaylists(config services.EngineConfig) {
+	activeID := ph.playlists.ActiveID()
+	if err := ph.store.Load(activeID, ph.playlists.Active()); err != nil && !errors.Is(err, storage.ErrNoSnapshot) {
+		log.Printf("failed to load saved playlist %s: %v", activeID, err)
+	}
+	ph.autoSaver.Watch(activeID, ph.playlists.Active())
+
+	ids, err := ph.store.List()
+	if err != nil {
+		log.Printf("failed to list saved playlists: %v", err)
+	}
+	for _, id := range ids {
+		if id == activeID || strings.Contains(id, ".") {
+			continue
+		}
+		engine := services.NewPlaylistEngineWithConfig(id, config)
+		if err := ph.store.Load(id, engine); err != nil {
+			log.Printf("failed to load saved playlist %s: %v", id, err)
+			continue
+		}
+		if err := ph.playlists.AttachWithID(id, engine); err != nil {
+			log.Printf("failed to load saved playlist %s: %v", id, err)
+			continue
+		}
+		ph.autoSaver.Watch(id, engine)
+	}
+}
+
+// engineFor returns the engine a request operates on: the playlist named in the path
+// under /playlists/:playlistId, otherwise the requesting user's active playlist
+func (ph *PlaylistHandlers) engineFor(c echo.Context) *services.PlaylistEngine {
+	if engine, ok := c.Get(playlistEngineKey).(*services.PlaylistEngine); ok {
+		return engine
+	}
+	return ph.playlistsFor(c).Active()
+}
+
+// playlistIDFor returns the ID of the playlist engineFor resolves to
+func (ph *PlaylistHandlers) playlistIDFor(c echo.Context) string {
+	if id := c.Param("playlistId"); id != "" {
+		return id
 	}
+	return ph.playlistsFor(c).ActiveID()
+}
+
+// playlistsFor returns the playlists of the user resolveUser found, or the default user's
+func (ph *PlaylistHandlers) playlistsFor(c echo.Context) *services.PlaylistManager {
+	if playlists, ok := c.Get(userPlaylistsKey).(*services.PlaylistManager); ok {
+		return playlists
+	}
+	return ph.playlists
+}
+
+// userIDFor returns the ID of the user playlistsFor resolves to
+func (ph *PlaylistHandlers) userIDFor(c echo.Context) string {
+	if id, ok := c.Get(userIDKey).(string); ok {
+		return id
+	}
+	return services.DefaultUserID
+}
+
+// snapshotKeyFor returns the name the playlist engineFor resolves to is saved under
+// Other users' snapshots are named "<userID>.<playlistID>"; slugs never contain a dot, so the
+// names can't collide with the default user's
+func (ph *PlaylistHandlers) snapshotKeyFor(c echo.Context) string {
+	if userID := ph.userIDFor(c); userID != services.DefaultUserID {
+		return userID + "." + ph.playlistIDFor(c)
+	}
+	return ph.playlistIDFor(c)
+}
+
+// requestedUserID returns the user named by the X-User-ID header, then the session cookie,
+// falling back to the default user
+func requestedUserID(c echo.Context) string {
+	if userID := c.Request().Header.Get(UserIDHeader); userID != "" {
+		return userID
+	}
+	if cookie, err := c.Cookie(UserSessionCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+	return services.DefaultUserID
+}
+
+// resolveUser is middleware that looks up the user named by the X-User-ID header or the session
+// cookie for playlistsFor; requests naming neither act as the default user
+// Unknown user IDs are answered with 404 before the handler runs
+func (ph *PlaylistHandlers) resolveUser(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID := requestedUserID(c)
+		playlists, err := ph.users.Playlists(userID)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		c.Set(userIDKey, userID)
+		c.Set(userPlaylistsKey, playlists)
+		return next(c)
+	}
+}
+
+// resolvePlaylist is middleware that looks up the :playlistId path param for engineFor
+// Unknown playlist IDs are answered with 404 before the handler runs
+func (ph *PlaylistHandlers) resolvePlaylist(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		engine, err := ph.playlistsFor(c).Get(c.Param("playlistId"))
+		if err != nil {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		c.Set(playlistEngineKey, engine)
+		return next(c)
+	}
+}
+
+// clampResults limits a requested result count to the handlers' maximum
+// Oversized requests are served up to the cap rather than rejected
+func (ph *PlaylistHandlers) clampResults(count int) int {
+	return min(count, ph.maxResults)
 }
 
 // GetPlaylist returns the current playlist
+// Query params after (a song ID cursor) and limit return one page with a next_cursor instead;
+// offset and limit return the page at that position with a next_offset
+// fields (e.g. fields=id,title,artist) returns only those song fields
 // GET /api/playlist
 func (ph *PlaylistHandlers) GetPlaylist(c echo.Context) error {
-	songs := ph.engine.GetCurrentPlaylist()
+	size := ph.engineFor(c).GetPlaylistSize()
+	data := map[string]interface{}{
+		"name":             ph.engineFor(c).GetPlaylistName(),
+		"size":             size,
+		"total_play_count": ph.engineFor(c).GetTotalPlayCount(),
+		"total_duration":   ph.engineFor(c).GetTotalDuration(),
+	}
 
-	response := map[string]interface{}{
-		"success": true,
-		"data": map[string]interface{}{
-			"name":  ph.engine.GetPlaylistName(),
-			"size":  ph.engine.GetPlaylistSize(),
-			"songs": songs,
-		},
+	fields, err := parseSongFields(c.QueryParam("fields"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	respond := func(songs []*models.Song) error {
+		data["songs"] = projectSongs(songs, fields)
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success": true,
+			"data":    data,
+		})
 	}
 
-	return c.JSON(http.StatusOK, response)
+	afterID := c.QueryParam("after")
+	offsetStr := c.QueryParam("offset")
+	limitStr := c.QueryParam("limit")
+	if afterID == "" && offsetStr == "" && limitStr == "" {
+		return respond(ph.engineFor(c).GetCurrentPlaylist())
+	}
+	if afterID != "" && offsetStr != "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "use either after or offset, not both",
+		})
+	}
+
+	limit := 50 // Default page size
+	if limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "limit must be a positive integer",
+			})
+		}
+		limit = ph.clampResults(parsedLimit)
+	}
+
+	// Offset pagination, for jumping straight to a page
+	if offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "offset must be a non-negative integer",
+			})
+		}
+
+		songs, err := ph.engineFor(c).GetSongsRange(offset, limit)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+
+		data["offset"] = offset
+		data["limit"] = limit
+		data["next_offset"] = nil
+		if next := offset + len(songs); len(songs) > 0 && next < size {
+			data["next_offset"] = next
+		}
+		return respond(songs)
+	}
+
+	// Cursor pagination, stable while songs elsewhere in the list change
+	songs, nextCursor, err := ph.engineFor(c).GetPlaylistPage(afterID, limit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error() + ", restart without a cursor",
+		})
+	}
+
+	data["next_cursor"] = nextCursor
+	return respond(songs)
+}
+
+// parseSongFields splits a comma-separated fields query param into song field names
+// An empty param returns nil, meaning every field; unknown names are an error
+func parseSongFields(param string) ([]string, error) {
+	if strings.TrimSpace(param) == "" {
+		return nil, nil
+	}
+
+	known := (&models.Song{}).GetMetadata()
+	var fields []string
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := known[field]; !ok {
+			return nil, fmt.Errorf("unknown song field '%s'", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// projectSongs keeps only the given fields of each song, or returns the songs unchanged when fields is nil
+func projectSongs(songs []*models.Song, fields []string) interface{} {
+	if fields == nil {
+		return songs
+	}
+
+	projected := make([]map[string]interface{}, 0, len(songs))
+	for _, song := range songs {
+		projected = append(projected, song.Project(fields))
+	}
+	return projected
 }
 
 // AddSong adds a new song to the playlist
+// An Idempotency-Key header makes retries within IdempotencyKeyTTL return the original response
 // POST /api/playlist/songs
 func (ph *PlaylistHandlers) AddSong(c echo.Context) error {
-	// Check if it's an HTMX request
-	isHTMX := c.Request().Header.Get("HX-Request") == "true"
+	// Respond with HTML for HTMX and browser requests, JSON otherwise
+	asHTML := wantsHTML(c)
 
 	// Parse request body
 	var req struct {
@@ -58,10 +376,15 @@ func (ph *PlaylistHandlers) AddSong(c echo.Context) error {
 		Mood     string `json:"mood"`
 		Duration int    `json:"duration" validate:"min=1"`
 		BPM      int    `json:"bpm"`
+
+		ArtworkURL string `json:"artwork_url"`
+		SourceURL  string `json:"source_url"`
 	}
 
 	// Handle form data for HTMX requests
-	if isHTMX {
+	if isFormRequest(c) {
+		req.ArtworkURL = c.FormValue("artwork_url")
+		req.SourceURL = c.FormValue("source_url")
 		req.Title = c.FormValue("title")
 		req.Artist = c.FormValue("artist")
 		req.Album = c.FormValue("album")
@@ -89,7 +412,7 @@ func (ph *PlaylistHandlers) AddSong(c echo.Context) error {
 
 	// Validate required fields
 	if req.Title == "" || req.Artist == "" {
-		if isHTMX {
+		if asHTML {
 			return c.HTML(http.StatusBadRequest, `<div class="text-red-500">Title and Artist are required</div>`)
 		}
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
@@ -98,37 +421,94 @@ func (ph *PlaylistHandlers) AddSong(c echo.Context) error {
 		})
 	}
 
+	// Reject oversized fields before they reach the engine, measured after trimming as the engine stores them
+	for _, field := range []string{req.Title, req.Artist, req.Album, req.Genre, req.SubGenre, req.Mood} {
+		if utf8.RuneCountInString(strings.TrimSpace(field)) > services.MaxSongFieldLength {
+			message := fmt.Sprintf("Song fields must be at most %d characters", services.MaxSongFieldLength)
+			if asHTML {
+				return c.HTML(http.StatusBadRequest, fmt.Sprintf(`<div class="text-red-500">%s</div>`, message))
+			}
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   message,
+			})
+		}
+	}
+
+	// Reject malformed links before they reach the engine
+	for _, link := range []string{req.ArtworkURL, req.SourceURL} {
+		if err := models.ValidateURL(strings.TrimSpace(link)); err != nil {
+			if asHTML {
+				return c.HTML(http.StatusBadRequest, fmt.Sprintf(`<div class="text-red-500">%s</div>`, html.EscapeString(err.Error())))
+			}
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+	}
+
 	// Set default duration if not provided
 	if req.Duration == 0 {
 		req.Duration = 180 // 3 minutes default
 	}
 
 	// Add song to playlist
-	err := ph.engine.AddSong(
-		req.Title, req.Artist, req.Album,
-		req.Genre, req.SubGenre, req.Mood,
-		req.Duration, req.BPM,
-	)
+	addSong := func() (int, map[string]interface{}) {
+		songID, err := ph.engineFor(c).AddSongWithLinks(
+			req.Title, req.Artist, req.Album,
+			req.Genre, req.SubGenre, req.Mood,
+			req.Duration, req.BPM,
+			req.ArtworkURL, req.SourceURL,
+		)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, services.ErrPlaylistFull) {
+				status = http.StatusConflict
+			}
+			return status, map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			}
+		}
 
-	if err != nil {
-		if isHTMX {
-			return c.HTML(http.StatusInternalServerError, fmt.Sprintf(`<div class="text-red-500">Error: %s</div>`, err.Error()))
+		return http.StatusCreated, map[string]interface{}{
+			"success": true,
+			"message": "Song added successfully",
+			"data": map[string]interface{}{
+				"id": songID,
+			},
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
 	}
 
-	if isHTMX {
+	// Repeated requests with the same Idempotency-Key replay the first response instead of adding again
+	// Keys are scoped to the user and playlist, so clients choosing the same key don't see each other's responses
+	var status int
+	var body map[string]interface{}
+	if key := c.Request().Header.Get("Idempotency-Key"); key != "" {
+		var replayed bool
+		scopedKey := ph.userIDFor(c) + "/" + ph.playlistIDFor(c) + "/" + key
+		status, body, replayed = ph.addSongKeys.do(scopedKey, addSong)
+		if replayed {
+			c.Response().Header().Set("Idempotent-Replayed", "true")
+		}
+	} else {
+		status, body = addSong()
+	}
+
+	if status >= http.StatusBadRequest {
+		if asHTML {
+			return c.HTML(status, fmt.Sprintf(`<div class="text-red-500">Error: %s</div>`, html.EscapeString(fmt.Sprint(body["error"]))))
+		}
+		return c.JSON(status, body)
+	}
+
+	if asHTML {
 		// Return updated playlist HTML
 		return ph.GetPlaylistHTML(c)
 	}
 
-	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"success": true,
-		"message": "Song added successfully",
-	})
+	return c.JSON(status, body)
 }
 
 // DeleteSong removes a song from the playlist by index
@@ -143,7 +523,30 @@ func (ph *PlaylistHandlers) DeleteSong(c echo.Context) error {
 		})
 	}
 
-	deletedSong, err := ph.engine.DeleteSong(index)
+	deletedSong, err := ph.engineFor(c).DeleteSong(index)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Song deleted successfully",
+		"data": map[string]interface{}{
+			"deleted_song":  deletedSong,
+			"playlist_size": ph.engineFor(c).GetPlaylistSize(),
+		},
+	})
+}
+
+// DeleteSongByID removes a song from the playlist by its ID
+// DELETE /api/playlist/songs/by-id/:songId
+func (ph *PlaylistHandlers) DeleteSongByID(c echo.Context) error {
+	songID := c.Param("songId")
+
+	deletedSong, err := ph.engineFor(c).DeleteSongByID(songID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]interface{}{
 			"success": false,
@@ -156,7 +559,62 @@ func (ph *PlaylistHandlers) DeleteSong(c echo.Context) error {
 		"message": "Song deleted successfully",
 		"data": map[string]interface{}{
 			"deleted_song":  deletedSong,
-			"playlist_size": ph.engine.GetPlaylistSize(),
+			"playlist_size": ph.engineFor(c).GetPlaylistSize(),
+		},
+	})
+}
+
+// GetTrash returns deleted songs that can still be restored, most recently deleted first
+// GET /api/playlist/trash
+func (ph *PlaylistHandlers) GetTrash(c echo.Context) error {
+	songs := ph.engineFor(c).GetTrash()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs": songs,
+			"count": len(songs),
+		},
+	})
+}
+
+// RestoreFromTrash puts a deleted song back at the end of the playlist
+// POST /api/playlist/trash/:songId/restore
+func (ph *PlaylistHandlers) RestoreFromTrash(c echo.Context) error {
+	songID := c.Param("songId")
+
+	if err := ph.engineFor(c).RestoreFromTrash(songID); err != nil {
+		status := http.StatusConflict
+		if errors.Is(err, services.ErrNotInTrash) {
+			status = http.StatusNotFound
+		}
+		return c.JSON(status, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	song, _ := ph.engineFor(c).SearchSongByID(songID)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Song restored successfully",
+		"data": map[string]interface{}{
+			"song":          song,
+			"playlist_size": ph.engineFor(c).GetPlaylistSize(),
+		},
+	})
+}
+
+// EmptyTrash permanently discards every deleted song
+// DELETE /api/playlist/trash
+func (ph *PlaylistHandlers) EmptyTrash(c echo.Context) error {
+	removed := ph.engineFor(c).EmptyTrash()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Trash emptied successfully",
+		"data": map[string]interface{}{
+			"removed": removed,
 		},
 	})
 }
@@ -183,7 +641,7 @@ func (ph *PlaylistHandlers) MoveSong(c echo.Context) error {
 		})
 	}
 
-	err = ph.engine.MoveSong(fromIndex, toIndex)
+	err = ph.engineFor(c).MoveSong(fromIndex, toIndex)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"success": false,
@@ -197,15 +655,77 @@ func (ph *PlaylistHandlers) MoveSong(c echo.Context) error {
 	})
 }
 
+// MoveBlock moves a run of consecutive songs, keeping their order
+// to is an index in the current playlist the block is placed before, the playlist size means the end
+// PUT /api/playlist/move-block
+func (ph *PlaylistHandlers) MoveBlock(c echo.Context) error {
+	var req struct {
+		Start *int `json:"start"`
+		Count int  `json:"count"`
+		To    *int `json:"to"`
+	}
+
+	if err := c.Bind(&req); err != nil || req.Start == nil || req.To == nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "start, count and to are required",
+		})
+	}
+
+	if err := ph.engineFor(c).MoveBlock(*req.Start, req.Count, *req.To); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Moved %d songs", req.Count),
+	})
+}
+
+// MoveSongToTop moves a song to the start of the playlist
+// POST /api/playlist/songs/:songId/top
+func (ph *PlaylistHandlers) MoveSongToTop(c echo.Context) error {
+	if err := ph.engineFor(c).MoveToTop(c.Param("songId")); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Song moved to top",
+	})
+}
+
+// MoveSongToBottom moves a song to the end of the playlist
+// POST /api/playlist/songs/:songId/bottom
+func (ph *PlaylistHandlers) MoveSongToBottom(c echo.Context) error {
+	if err := ph.engineFor(c).MoveToBottom(c.Param("songId")); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Song moved to bottom",
+	})
+}
+
 // ReversePlaylist reverses the order of songs in the playlist
 // POST /api/playlist/reverse
 func (ph *PlaylistHandlers) ReversePlaylist(c echo.Context) error {
-	ph.engine.ReversePlaylist()
+	ph.engineFor(c).ReversePlaylist()
 
-	// Check if it's an HTMX request
-	isHTMX := c.Request().Header.Get("HX-Request") == "true"
+	// Respond with HTML for HTMX and browser requests, JSON otherwise
+	asHTML := wantsHTML(c)
 
-	if isHTMX {
+	if asHTML {
 		// Return updated playlist HTML
 		return ph.GetPlaylistHTML(c)
 	}
@@ -228,7 +748,7 @@ func (ph *PlaylistHandlers) PlaySong(c echo.Context) error {
 		})
 	}
 
-	song, err := ph.engine.PlaySong(index)
+	song, err := ph.engineFor(c).PlaySong(index)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]interface{}{
 			"success": false,
@@ -245,10 +765,19 @@ func (ph *PlaylistHandlers) PlaySong(c echo.Context) error {
 	})
 }
 
-// UndoLastPlay undoes the last played song
-// POST /api/playlist/undo
-func (ph *PlaylistHandlers) UndoLastPlay(c echo.Context) error {
-	song, err := ph.engine.UndoLastPlay()
+// SkipSong records that a song was skipped
+// POST /api/playlist/songs/:index/skip
+func (ph *PlaylistHandlers) SkipSong(c echo.Context) error {
+	indexStr := c.Param("index")
+	index, err := strconv.Atoi(indexStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid index format",
+		})
+	}
+
+	song, err := ph.engineFor(c).SkipSong(index)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]interface{}{
 			"success": false,
@@ -258,32 +787,229 @@ func (ph *PlaylistHandlers) UndoLastPlay(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": "Last play undone successfully",
+		"message": "Song skipped",
 		"data": map[string]interface{}{
 			"song": song,
 		},
 	})
 }
 
-// RateSong assigns a rating to a song
-// POST /api/playlist/songs/:songId/rate
-func (ph *PlaylistHandlers) RateSong(c echo.Context) error {
-	songID := c.Param("songId")
+// GetSkippedSongs returns songs that are mostly skipped rather than played
+// Query param threshold is the play ratio below which a song counts as skippy (default 0.5)
+// GET /api/playlist/skippy
+func (ph *PlaylistHandlers) GetSkippedSongs(c echo.Context) error {
+	threshold := 0.5 // Default threshold
+	if thresholdStr := c.QueryParam("threshold"); thresholdStr != "" {
+		parsedThreshold, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil || parsedThreshold < 0 || parsedThreshold > 1 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Threshold must be a number between 0 and 1",
+			})
+		}
+		threshold = parsedThreshold
+	}
 
-	var req struct {
-		Rating int `json:"rating" validate:"required,min=1,max=5"`
+	songs := ph.engineFor(c).GetSkippedSongs(threshold)
+
+	results := make([]map[string]interface{}, 0, len(songs))
+	for _, song := range songs {
+		results = append(results, map[string]interface{}{
+			"song":       song,
+			"play_ratio": song.PlayRatio(),
+		})
 	}
 
-	if err := c.Bind(&req); err != nil {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs":            results,
+			"count":            len(results),
+			"threshold":        threshold,
+			"min_interactions": services.MinSkipInteractions,
+		},
+	})
+}
+
+// ImportPlays backfills playback history from an external listen log
+// Body: [{"song_id": "...", "played_at": "2024-01-02T15:04:05Z"}, ...]
+// POST /api/playlist/plays/import
+func (ph *PlaylistHandlers) ImportPlays(c echo.Context) error {
+	var records []services.PlayRecord
+	if err := c.Bind(&records); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"success": false,
 			"error":   "Invalid request format",
 		})
 	}
 
-	err := ph.engine.RateSong(songID, req.Rating)
+	errorMessages := joinedErrorMessages(ph.engineFor(c).RecordPlays(records))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Recorded %d plays", len(records)-len(errorMessages)),
+		"data": map[string]interface{}{
+			"recorded": len(records) - len(errorMessages),
+			"errors":   errorMessages,
+		},
+	})
+}
+
+// joinedErrorMessages flattens an errors.Join result into one message per error
+func joinedErrorMessages(err error) []string {
+	errorMessages := make([]string, 0)
+	if err == nil {
+		return errorMessages
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, joinedErr := range joined.Unwrap() {
+			errorMessages = append(errorMessages, joinedErr.Error())
+		}
+	} else {
+		errorMessages = append(errorMessages, err.Error())
+	}
+	return errorMessages
+}
+
+// VerifyIntegrity reports any drift between the playlist and its indexes
+// GET /api/playlist/integrity
+func (ph *PlaylistHandlers) VerifyIntegrity(c echo.Context) error {
+	issues := ph.engineFor(c).VerifyIntegrity()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"consistent": len(issues) == 0,
+			"issues":     issues,
+		},
+	})
+}
+
+// RepairIndexes rebuilds every index from the playlist and reports what was out of sync
+// POST /api/playlist/repair
+func (ph *PlaylistHandlers) RepairIndexes(c echo.Context) error {
+	repaired := ph.engineFor(c).RepairIndexes()
+	remaining := ph.engineFor(c).VerifyIntegrity()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Repaired %d issues", len(repaired)),
+		"data": map[string]interface{}{
+			"repaired":  repaired,
+			"remaining": remaining,
+		},
+	})
+}
+
+// Compact shrinks the engine's indexes after heavy churn and reports their sizes before and after
+// POST /api/playlist/compact
+func (ph *PlaylistHandlers) Compact(c echo.Context) error {
+	report := ph.engineFor(c).Compact()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Indexes compacted",
+		"data":    report,
+	})
+}
+
+// GetQueue returns the songs waiting to be played
+// GET /api/playlist/queue
+func (ph *PlaylistHandlers) GetQueue(c echo.Context) error {
+	queue := ph.engineFor(c).GetQueue()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs": queue,
+			"count": len(queue),
+		},
+	})
+}
+
+// EnqueueSong adds a song to the back of the play queue
+// POST /api/playlist/queue/:songId
+func (ph *PlaylistHandlers) EnqueueSong(c echo.Context) error {
+	if err := ph.engineFor(c).EnqueueSong(c.Param("songId")); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Song added to queue",
+	})
+}
+
+// ClearQueue empties the play queue
+// DELETE /api/playlist/queue
+func (ph *PlaylistHandlers) ClearQueue(c echo.Context) error {
+	ph.engineFor(c).ClearQueue()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Queue cleared",
+	})
+}
+
+// ExtendQueue appends recommended songs that aren't already queued
+// Query param count sets how many songs to add (default 5)
+// POST /api/playlist/queue/extend
+func (ph *PlaylistHandlers) ExtendQueue(c echo.Context) error {
+	count := 5 // Default count
+	if countStr := c.QueryParam("count"); countStr != "" {
+		parsedCount, err := strconv.Atoi(countStr)
+		if err != nil || parsedCount <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Count must be a positive integer",
+			})
+		}
+		count = ph.clampResults(parsedCount)
+	}
+
+	added := ph.engineFor(c).ExtendQueueSmart(count)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Added %d songs to queue", len(added)),
+		"data": map[string]interface{}{
+			"added": added,
+			"queue": ph.engineFor(c).GetQueue(),
+		},
+	})
+}
+
+// NextSong plays the next song: queued songs first, then by the shuffle and repeat settings
+// POST /api/playlist/queue/next
+func (ph *PlaylistHandlers) NextSong(c echo.Context) error {
+	song, err := ph.engineFor(c).NextSong()
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Playing next song",
+		"data": map[string]interface{}{
+			"song":   song,
+			"player": ph.engineFor(c).GetPlayerStatus(),
+		},
+	})
+}
+
+// PreviousSong goes back to the song played before the current one
+// POST /api/playlist/queue/previous
+func (ph *PlaylistHandlers) PreviousSong(c echo.Context) error {
+	song, err := ph.engineFor(c).PreviousSong()
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
 			"success": false,
 			"error":   err.Error(),
 		})
@@ -291,38 +1017,126 @@ func (ph *PlaylistHandlers) RateSong(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": "Song rated successfully",
+		"message": "Playing previous song",
+		"data": map[string]interface{}{
+			"song":   song,
+			"player": ph.engineFor(c).GetPlayerStatus(),
+		},
 	})
 }
 
-// SearchSong searches for a song by ID or title
-// GET /api/playlist/search
-func (ph *PlaylistHandlers) SearchSong(c echo.Context) error {
-	searchType := c.QueryParam("type") // "id" or "title"
-	query := c.QueryParam("q")
+// GetPlayerStatus returns the current song with the shuffle and repeat settings
+// GET /api/playlist/queue/player
+func (ph *PlaylistHandlers) GetPlayerStatus(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    ph.engineFor(c).GetPlayerStatus(),
+	})
+}
 
-	if query == "" {
+// SetPlayerMode updates shuffle and repeat from {"shuffle": true, "repeat": "off|one|all"}
+// Either field may be omitted to leave that setting unchanged
+// PUT /api/playlist/queue/mode
+func (ph *PlaylistHandlers) SetPlayerMode(c echo.Context) error {
+	var req struct {
+		Shuffle *bool   `json:"shuffle"`
+		Repeat  *string `json:"repeat"`
+	}
+
+	if err := c.Bind(&req); err != nil || (req.Shuffle == nil && req.Repeat == nil) {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"success": false,
-			"error":   "Search query is required",
+			"error":   "shuffle or repeat is required",
 		})
 	}
 
-	var song *models.Song
-	var err error
+	engine := ph.engineFor(c)
+	if req.Repeat != nil {
+		mode, err := services.ParseRepeatMode(*req.Repeat)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		engine.SetRepeatMode(mode)
+	}
+	if req.Shuffle != nil {
+		engine.SetShuffle(*req.Shuffle)
+	}
 
-	switch searchType {
-	case "id":
-		song, err = ph.engine.SearchSongByID(query)
-	case "title":
-		song, err = ph.engine.SearchSongByTitle(query)
-	default:
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Player mode updated",
+		"data":    engine.GetPlayerStatus(),
+	})
+}
+
+// GetNowPlaying returns the current song, whether it is playing or paused and the position in seconds
+// GET /api/playback
+func (ph *PlaylistHandlers) GetNowPlaying(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    ph.engineFor(c).GetNowPlaying(),
+	})
+}
+
+// PausePlayback pauses the current song
+// POST /api/playback/pause
+func (ph *PlaylistHandlers) PausePlayback(c echo.Context) error {
+	status, err := ph.engineFor(c).PausePlayback()
+	return ph.playbackResponse(c, status, err, "Playback paused")
+}
+
+// ResumePlayback continues the current song from where it was paused
+// POST /api/playback/resume
+func (ph *PlaylistHandlers) ResumePlayback(c echo.Context) error {
+	status, err := ph.engineFor(c).ResumePlayback()
+	return ph.playbackResponse(c, status, err, "Playback resumed")
+}
+
+// SeekPlayback moves to {"position": seconds} in the current song
+// POST /api/playback/seek
+func (ph *PlaylistHandlers) SeekPlayback(c echo.Context) error {
+	var req struct {
+		Position *int `json:"position"`
+	}
+
+	if err := c.Bind(&req); err != nil || req.Position == nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"success": false,
-			"error":   "Search type must be 'id' or 'title'",
+			"error":   "position in seconds is required",
+		})
+	}
+
+	status, err := ph.engineFor(c).SeekPlayback(*req.Position)
+	return ph.playbackResponse(c, status, err, "Playback position updated")
+}
+
+// playbackResponse answers a playback control: 409 when nothing is playing, 400 for other errors
+func (ph *PlaylistHandlers) playbackResponse(c echo.Context, status services.NowPlaying, err error, message string) error {
+	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, services.ErrNothingPlaying) {
+			code = http.StatusConflict
+		}
+		return c.JSON(code, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
 		})
 	}
 
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": message,
+		"data":    status,
+	})
+}
+
+// UndoLastPlay undoes the last played song
+// POST /api/playlist/undo
+func (ph *PlaylistHandlers) UndoLastPlay(c echo.Context) error {
+	song, err := ph.engineFor(c).UndoLastPlay()
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]interface{}{
 			"success": false,
@@ -332,353 +1146,2717 @@ func (ph *PlaylistHandlers) SearchSong(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
+		"message": "Last play undone successfully",
 		"data": map[string]interface{}{
 			"song": song,
 		},
 	})
 }
 
-// GetSongsByRating returns songs with a specific rating
-// GET /api/playlist/rating/:rating
-func (ph *PlaylistHandlers) GetSongsByRating(c echo.Context) error {
-	ratingStr := c.Param("rating")
-	rating, err := strconv.Atoi(ratingStr)
-	if err != nil || rating < 1 || rating > 5 {
+// PruneHistory removes playback history entries for deleted songs
+// POST /api/playlist/history/prune
+func (ph *PlaylistHandlers) PruneHistory(c echo.Context) error {
+	removed := ph.engineFor(c).PruneHistoryOfDeleted()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Removed %d history entries", removed),
+		"data": map[string]interface{}{
+			"removed": removed,
+			"total":   ph.engineFor(c).GetHistorySize(),
+		},
+	})
+}
+
+// RateSong assigns a rating to a song
+// POST /api/playlist/songs/:songId/rate
+func (ph *PlaylistHandlers) RateSong(c echo.Context) error {
+	songID := c.Param("songId")
+
+	var req struct {
+		Rating int `json:"rating" validate:"required,min=1,max=5"`
+	}
+
+	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"success": false,
-			"error":   "Rating must be between 1 and 5",
+			"error":   "Invalid request format",
 		})
 	}
 
-	songs := ph.engine.GetSongsByRating(rating)
+	err := ph.engineFor(c).RateSong(songID, req.Rating)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"data": map[string]interface{}{
-			"rating": rating,
-			"songs":  songs,
-			"count":  len(songs),
-		},
+		"message": "Song rated successfully",
 	})
 }
 
-// SortPlaylist sorts the playlist using specified criteria and algorithm
-// SortPlaylist sorts the playlist by specified criteria
-// POST /api/playlist/sort
-func (ph *PlaylistHandlers) SortPlaylist(c echo.Context) error {
-	// Check if it's an HTMX request
-	isHTMX := c.Request().Header.Get("HX-Request") == "true"
+// UnrateSong clears the rating of a song
+// DELETE /api/playlist/songs/:songId/rating
+func (ph *PlaylistHandlers) UnrateSong(c echo.Context) error {
+	songID := c.Param("songId")
 
-	var req struct {
-		Criteria  string `json:"criteria" validate:"required"`
-		Algorithm string `json:"algorithm"`
+	song, err := ph.engineFor(c).SearchSongByID(songID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
 	}
 
-	if isHTMX {
-		// Handle form data or URL params for HTMX requests
-		req.Criteria = c.FormValue("criteria")
-		if req.Criteria == "" {
-			req.Criteria = c.QueryParam("criteria")
-		}
-		req.Algorithm = c.FormValue("algorithm")
-		if req.Algorithm == "" {
-			req.Algorithm = c.QueryParam("algorithm")
-		}
-	} else {
-		if err := c.Bind(&req); err != nil {
-			return c.JSON(http.StatusBadRequest, map[string]interface{}{
-				"success": false,
-				"error":   "Invalid request format",
-			})
-		}
+	if song.Rating == 0 {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Song is already unrated",
+		})
+	}
+
+	if err := ph.engineFor(c).UnrateSong(songID); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
 	}
 
-	if req.Algorithm == "" {
-		req.Algorithm = "merge" // Default to merge sort
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Song rating removed successfully",
+	})
+}
+
+// UpdateSong edits any of a song's title, artist, album, genre, subgenre, mood, duration, bpm and rating
+// Omitted fields keep their values; the song keeps its ID, position and play statistics
+// PATCH /api/playlist/songs/:songId
+func (ph *PlaylistHandlers) UpdateSong(c echo.Context) error {
+	songID := c.Param("songId")
+
+	var req services.SongUpdate
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid request format",
+		})
+	}
+
+	if _, err := ph.engineFor(c).SearchSongByID(songID); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	song, err := ph.engineFor(c).UpdateSong(songID, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Song updated successfully",
+		"data":    song,
+	})
+}
+
+// SetSongNote attaches a note to a song
+// PUT /api/playlist/songs/:songId/note
+func (ph *PlaylistHandlers) SetSongNote(c echo.Context) error {
+	songID := c.Param("songId")
+
+	var req struct {
+		Note string `json:"note"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid request format",
+		})
+	}
+
+	if _, err := ph.engineFor(c).SearchSongByID(songID); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	if err := ph.engineFor(c).SetSongNote(songID, req.Note); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	song, _ := ph.engineFor(c).SearchSongByID(songID)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Song note updated successfully",
+		"data":    song,
+	})
+}
+
+// SetSongArtwork replaces a song's artwork and source URLs, empty values clear them
+// PUT /api/playlist/songs/:songId/artwork
+func (ph *PlaylistHandlers) SetSongArtwork(c echo.Context) error {
+	var req struct {
+		ArtworkURL string `json:"artwork_url"`
+		SourceURL  string `json:"source_url"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid request format",
+		})
+	}
+
+	for _, link := range []string{req.ArtworkURL, req.SourceURL} {
+		if err := models.ValidateURL(strings.TrimSpace(link)); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	songID := c.Param("songId")
+	if err := ph.engineFor(c).SetSongArtwork(songID, req.ArtworkURL, req.SourceURL); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	song, _ := ph.engineFor(c).SearchSongByID(songID)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Artwork updated successfully",
+		"data": map[string]interface{}{
+			"song": song,
+		},
+	})
+}
+
+// BulkTagSongs tags every song matching a filter
+// Filter fields are optional and combined with AND; string fields match case-insensitively
+// POST /api/playlist/tag/bulk
+func (ph *PlaylistHandlers) BulkTagSongs(c echo.Context) error {
+	var req struct {
+		Tag    string `json:"tag"`
+		Filter struct {
+			Genre     string `json:"genre"`
+			SubGenre  string `json:"subgenre"`
+			Mood      string `json:"mood"`
+			Artist    string `json:"artist"`
+			MinRating int    `json:"min_rating"`
+			MaxRating int    `json:"max_rating"`
+		} `json:"filter"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid request format",
+		})
+	}
+
+	tag := models.NormalizeTag(req.Tag)
+	if tag == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Tag is required",
+		})
+	}
+
+	filter := req.Filter
+	matches := func(song *models.Song) bool {
+		if filter.Genre != "" && !strings.EqualFold(song.Genre, filter.Genre) {
+			return false
+		}
+		if filter.SubGenre != "" && !strings.EqualFold(song.SubGenre, filter.SubGenre) {
+			return false
+		}
+		if filter.Mood != "" && !strings.EqualFold(song.Mood, filter.Mood) {
+			return false
+		}
+		if filter.Artist != "" && !strings.EqualFold(song.Artist, filter.Artist) {
+			return false
+		}
+		if filter.MinRating > 0 && song.Rating < filter.MinRating {
+			return false
+		}
+		if filter.MaxRating > 0 && song.Rating > filter.MaxRating {
+			return false
+		}
+		return true
+	}
+
+	tagged := ph.engineFor(c).TagSongsWhere(matches, tag)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Tagged %d songs as %q", tagged, tag),
+		"data": map[string]interface{}{
+			"tag":    tag,
+			"tagged": tagged,
+		},
+	})
+}
+
+// Deduplicate removes songs repeating an earlier song's title and artist
+// dryRun=true lists the songs that would be removed without removing them
+// POST /api/playlist/deduplicate
+func (ph *PlaylistHandlers) Deduplicate(c echo.Context) error {
+	dryRun := isDryRun(c)
+
+	var songs []*models.Song
+	if dryRun {
+		songs = ph.engineFor(c).PreviewDeduplicate()
+	} else {
+		songs = ph.engineFor(c).Deduplicate()
+	}
+
+	return c.JSON(http.StatusOK, bulkResult(dryRun, "removed", songs, nil))
+}
+
+// BulkDeleteSongs deletes every song in a list of IDs
+// dryRun=true lists the songs that would be deleted without deleting them
+// POST /api/playlist/delete/bulk
+func (ph *PlaylistHandlers) BulkDeleteSongs(c echo.Context) error {
+	var req struct {
+		SongIDs []string `json:"song_ids"`
+	}
+
+	if err := c.Bind(&req); err != nil || len(req.SongIDs) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "song_ids must list at least one song",
+		})
+	}
+
+	dryRun := isDryRun(c)
+
+	var songs []*models.Song
+	var err error
+	if dryRun {
+		songs, err = ph.engineFor(c).PreviewDeleteSongs(req.SongIDs)
+	} else {
+		songs, err = ph.engineFor(c).DeleteSongs(req.SongIDs)
+	}
+
+	return c.JSON(http.StatusOK, bulkResult(dryRun, "deleted", songs, err))
+}
+
+// BulkRateSongs gives every song in a list of IDs the same rating
+// dryRun=true lists the songs whose rating would change without changing them
+// POST /api/playlist/rate/bulk
+func (ph *PlaylistHandlers) BulkRateSongs(c echo.Context) error {
+	var req struct {
+		SongIDs []string `json:"song_ids"`
+		Rating  int      `json:"rating"`
+	}
+
+	if err := c.Bind(&req); err != nil || len(req.SongIDs) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "song_ids must list at least one song",
+		})
+	}
+	if req.Rating < 1 || req.Rating > 5 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Rating must be between 1 and 5",
+		})
+	}
+
+	dryRun := isDryRun(c)
+
+	var songs []*models.Song
+	var err error
+	if dryRun {
+		songs, err = ph.engineFor(c).PreviewRateSongs(req.SongIDs, req.Rating)
+	} else {
+		songs, err = ph.engineFor(c).RateSongs(req.SongIDs, req.Rating)
+	}
+
+	return c.JSON(http.StatusOK, bulkResult(dryRun, "rated", songs, err))
+}
+
+// isDryRun reports whether a request only previews its changes: dryRun=true, or dry_run=true as an alias
+func isDryRun(c echo.Context) bool {
+	return c.QueryParam("dryRun") == "true" || c.QueryParam("dry_run") == "true"
+}
+
+// bulkResult builds the shared response for bulk operations and their dry runs
+func bulkResult(dryRun bool, verb string, songs []*models.Song, err error) map[string]interface{} {
+	message := fmt.Sprintf("%d songs %s", len(songs), verb)
+	if dryRun {
+		message = fmt.Sprintf("Dry run: %d songs would be %s", len(songs), verb)
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"message": message,
+		"data": map[string]interface{}{
+			"dry_run": dryRun,
+			"songs":   songs,
+			"count":   len(songs),
+			"errors":  joinedErrorMessages(err),
+		},
+	}
+}
+
+// GetDistinctValues returns each distinct value of a song field with the number of songs holding it
+// Query param field is one of genre, subgenre, mood, artist or album
+// GET /api/playlist/distinct
+func (ph *PlaylistHandlers) GetDistinctValues(c echo.Context) error {
+	field := c.QueryParam("field")
+	if field == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Field is required",
+		})
+	}
+
+	values, err := ph.engineFor(c).GetDistinctValues(field)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"field":  strings.ToLower(field),
+			"values": values,
+			"count":  len(values),
+		},
+	})
+}
+
+// GetSongsByTag returns songs carrying a tag
+// GET /api/playlist/tags/:tag
+func (ph *PlaylistHandlers) GetSongsByTag(c echo.Context) error {
+	tag := c.Param("tag")
+	songs := ph.engineFor(c).GetSongsByTag(tag)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"tag":   models.NormalizeTag(tag),
+			"songs": songs,
+			"count": len(songs),
+		},
+	})
+}
+
+// GetSongDetail returns a song together with its explorer path and similar songs
+// Query param similar sets how many similar songs to include (default 5)
+// GET /api/playlist/songs/:songId/detail
+func (ph *PlaylistHandlers) GetSongDetail(c echo.Context) error {
+	songID := c.Param("songId")
+
+	song, err := ph.engineFor(c).SearchSongByID(songID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	similarCount := 5 // Default count
+	if countStr := c.QueryParam("similar"); countStr != "" {
+		if parsedCount, err := strconv.Atoi(countStr); err == nil && parsedCount >= 0 {
+			similarCount = parsedCount
+		}
+	}
+
+	path, err := ph.engineFor(c).FindSongPath(songID)
+	if err != nil {
+		path = []string{}
+	}
+
+	similar, err := ph.engineFor(c).GetSimilarSongs(songID, similarCount)
+	if err != nil {
+		similar = []*models.Song{}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"song":    song,
+			"path":    path,
+			"similar": similar,
+		},
+	})
+}
+
+// GetSongStats returns a song's play, skip and rating statistics
+// GET /api/playlist/songs/:songId/stats
+func (ph *PlaylistHandlers) GetSongStats(c echo.Context) error {
+	stats, err := ph.engineFor(c).GetSongStats(c.Param("songId"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// GetSongNeighbors returns the songs around a song in the current playlist order
+// Query param radius sets how many songs to include on each side (default 2)
+// GET /api/playlist/songs/:songId/neighbors
+func (ph *PlaylistHandlers) GetSongNeighbors(c echo.Context) error {
+	songID := c.Param("songId")
+
+	radius := 2 // Default radius
+	if radiusStr := c.QueryParam("radius"); radiusStr != "" {
+		parsedRadius, err := strconv.Atoi(radiusStr)
+		if err != nil || parsedRadius < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Radius must be a non-negative integer",
+			})
+		}
+		radius = parsedRadius
+	}
+
+	songs, center, err := ph.engineFor(c).GetNeighbors(songID, radius)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs":  songs,
+			"center": center,
+			"radius": radius,
+		},
+	})
+}
+
+// GetSongsWithSameMood returns every other song sharing the given song's mood
+// GET /api/playlist/songs/:songId/same-mood
+func (ph *PlaylistHandlers) GetSongsWithSameMood(c echo.Context) error {
+	songID := c.Param("songId")
+
+	songs, err := ph.engineFor(c).GetSongsWithSameMood(songID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs": songs,
+			"count": len(songs),
+		},
+	})
+}
+
+// SearchAll matches a query against every searchable song field, best matches first
+// Each hit names the field that matched; title matches rank above artist, album, tag and note matches
+// GET /api/playlist/search/all?q=...&limit=20
+func (ph *PlaylistHandlers) SearchAll(c echo.Context) error {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Search query is required",
+		})
+	}
+
+	hits := ph.engineFor(c).Search(query)
+	limit := ph.maxResults
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = ph.clampResults(parsedLimit)
+		}
+	}
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"hits":  hits,
+			"count": len(hits),
+		},
+	})
+}
+
+// fuzzySearch responds with ranked partial matches across title, artist and album
+// Query param limit caps the hits (default 10)
+func (ph *PlaylistHandlers) fuzzySearch(c echo.Context, query string) error {
+	limit := 10 // Default limit
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Limit must be a positive integer",
+			})
+		}
+		limit = ph.clampResults(parsedLimit)
+	}
+
+	hits := ph.engineFor(c).FuzzySearch(query, limit)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"hits":  hits,
+			"count": len(hits),
+		},
+	})
+}
+
+// SearchSong searches for a song by ID or title; type=title also lists every song sharing the title
+// type=fuzzy returns every close title match instead, capped by the limit query param (default 10)
+// type=artist returns every song by the artist
+// mode=fuzzy ranks partial matches across title, artist and album, capped by limit (default 10)
+// type=fuzzy is deprecated in favour of mode=fuzzy and keeps its response shape for existing clients
+// GET /api/playlist/search
+func (ph *PlaylistHandlers) SearchSong(c echo.Context) error {
+	searchType := c.QueryParam("type") // "id", "title", "fuzzy" or "artist"
+	query := c.QueryParam("q")
+
+	if query == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Search query is required",
+		})
+	}
+
+	switch mode := c.QueryParam("mode"); mode {
+	case "fuzzy":
+		return ph.fuzzySearch(c, query)
+	case "":
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Search mode must be 'fuzzy'",
+		})
+	}
+
+	if searchType == "fuzzy" || searchType == "artist" {
+		var songs []*models.Song
+		if searchType == "artist" {
+			songs = ph.engineFor(c).SearchSongsByArtist(query)
+		} else {
+			limit := 10 // Default limit
+			if limitStr := c.QueryParam("limit"); limitStr != "" {
+				if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+					limit = ph.clampResults(parsedLimit)
+				}
+			}
+			songs = ph.engineFor(c).FuzzySearchByTitle(query, limit)
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success": true,
+			"data": map[string]interface{}{
+				"songs": songs,
+				"count": len(songs),
+			},
+		})
+	}
+
+	var songs []*models.Song
+	var err error
+
+	switch searchType {
+	case "id":
+		var song *models.Song
+		if song, err = ph.engineFor(c).SearchSongByID(query); err == nil {
+			songs = []*models.Song{song}
+		}
+	case "title":
+		songs, err = ph.engineFor(c).SearchSongByTitle(query)
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Search type must be 'id', 'title', 'fuzzy' or 'artist'",
+		})
+	}
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	data := map[string]interface{}{
+		"song": songs[0],
+	}
+	// Several songs can share a title, song stays the first added for existing clients
+	if searchType == "title" {
+		data["songs"] = songs
+		data["count"] = len(songs)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// GetSongsByRating returns songs with a specific rating
+// GET /api/playlist/rating/:rating
+func (ph *PlaylistHandlers) GetSongsByRating(c echo.Context) error {
+	ratingStr := c.Param("rating")
+	rating, err := strconv.Atoi(ratingStr)
+	if err != nil || rating < 1 || rating > 5 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Rating must be between 1 and 5",
+		})
+	}
+
+	songs := ph.engineFor(c).GetSongsByRating(rating)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"rating": rating,
+			"songs":  songs,
+			"count":  len(songs),
+		},
+	})
+}
+
+// GetSongsByRatings returns songs matching any of several ratings, highest rating first
+// Query param values is a comma-separated list such as 3,4,5
+// GET /api/playlist/ratings
+func (ph *PlaylistHandlers) GetSongsByRatings(c echo.Context) error {
+	valuesStr := c.QueryParam("values")
+	if valuesStr == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "At least one rating value is required",
+		})
+	}
+
+	ratings := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, value := range strings.Split(valuesStr, ",") {
+		rating, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || rating < 1 || rating > 5 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("Invalid rating '%s': ratings must be between 1 and 5", strings.TrimSpace(value)),
+			})
+		}
+		if !seen[rating] {
+			seen[rating] = true
+			ratings = append(ratings, rating)
+		}
+	}
+
+	songs := ph.engineFor(c).GetSongsByRatings(ratings)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"ratings": ratings,
+			"songs":   songs,
+			"count":   len(songs),
+		},
+	})
+}
+
+// GetSongsByRatingRange returns songs rated between min and max inclusive
+// Query params min and max default to 1 and 5; order=desc lists the highest rating first
+// GET /api/playlist/rating-range
+func (ph *PlaylistHandlers) GetSongsByRatingRange(c echo.Context) error {
+	bounds := map[string]int{"min": 1, "max": 5}
+	for param := range bounds {
+		if valueStr := c.QueryParam(param); valueStr != "" {
+			value, err := strconv.Atoi(valueStr)
+			if err != nil || value < 1 || value > 5 {
+				return c.JSON(http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"error":   fmt.Sprintf("%s must be a rating between 1 and 5", param),
+				})
+			}
+			bounds[param] = value
+		}
+	}
+
+	minRating, maxRating := bounds["min"], bounds["max"]
+	if minRating > maxRating {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "min must not be greater than max",
+		})
+	}
+
+	order := c.QueryParam("order")
+	var songs []*models.Song
+	switch order {
+	case "asc", "":
+		order = "asc"
+		songs = ph.engineFor(c).GetSongsByRatingRange(minRating, maxRating)
+	case "desc":
+		songs = ph.engineFor(c).GetSongsByRatingRangeDesc(minRating, maxRating)
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "order must be 'asc' or 'desc'",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"min":   minRating,
+			"max":   maxRating,
+			"order": order,
+			"songs": songs,
+			"count": len(songs),
+		},
+	})
+}
+
+// FilterSongs returns the songs meeting every given criterion, oldest added first
+// Query params: genre, mood, min_rating, max_rating, min_bpm, max_bpm, min_duration, max_duration,
+// min_play_count and added_after (RFC 3339); fields limits each song to the listed fields
+// GET /api/playlist/filter?genre=rock&min_bpm=120&max_bpm=140
+func (ph *PlaylistHandlers) FilterSongs(c echo.Context) error {
+	filter := services.SongFilter{
+		Genre: strings.TrimSpace(c.QueryParam("genre")),
+		Mood:  strings.TrimSpace(c.QueryParam("mood")),
+	}
+
+	bounds := []struct {
+		param  string
+		target *int
+	}{
+		{"min_rating", &filter.MinRating},
+		{"max_rating", &filter.MaxRating},
+		{"min_bpm", &filter.MinBPM},
+		{"max_bpm", &filter.MaxBPM},
+		{"min_duration", &filter.MinDuration},
+		{"max_duration", &filter.MaxDuration},
+		{"min_play_count", &filter.MinPlayCount},
+	}
+	for _, bound := range bounds {
+		if valueStr := c.QueryParam(bound.param); valueStr != "" {
+			value, err := strconv.Atoi(valueStr)
+			if err != nil || value < 0 {
+				return c.JSON(http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"error":   fmt.Sprintf("%s must be a non-negative integer", bound.param),
+				})
+			}
+			*bound.target = value
+		}
+	}
+
+	if value := c.QueryParam("added_after"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "added_after must be an RFC 3339 timestamp such as 2024-01-02T15:04:05Z",
+			})
+		}
+		filter.AddedAfter = parsed
+	}
+
+	fields, err := parseSongFields(c.QueryParam("fields"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	songs, err := ph.engineFor(c).FilterSongs(filter)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs": projectSongs(songs, fields),
+			"count": len(songs),
+		},
+	})
+}
+
+// GetSortedView returns the playlist ordered by comma-separated criteria without changing it
+// Query param sort lists criteria by priority, e.g. sort=artist,album,title
+// GET /api/playlist/view
+func (ph *PlaylistHandlers) GetSortedView(c echo.Context) error {
+	var criteria []datastructures.SortCriteria
+	var names []string
+	if sortParam := c.QueryParam("sort"); sortParam != "" {
+		for _, name := range strings.Split(sortParam, ",") {
+			name = strings.TrimSpace(name)
+			parsed, err := datastructures.ParseSortCriteria(name)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"error":   err.Error(),
+				})
+			}
+			criteria = append(criteria, parsed)
+			names = append(names, name)
+		}
+	}
+
+	songs := ph.engineFor(c).GetSortedView(criteria)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs": songs,
+			"sort":  names,
+			"count": len(songs),
+		},
+	})
+}
+
+// GetSortState returns the sort the current playlist order comes from
+// Criteria is "custom" when songs were added or moved by hand since the last sort
+// GET /api/playlist/sort
+func (ph *PlaylistHandlers) GetSortState(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    ph.engineFor(c).GetSortState(),
+	})
+}
+
+// SortPlaylist sorts the playlist using specified criteria and algorithm
+// SortPlaylist sorts the playlist by specified criteria
+// POST /api/playlist/sort
+func (ph *PlaylistHandlers) SortPlaylist(c echo.Context) error {
+	// Respond with HTML for HTMX and browser requests, JSON otherwise
+	asHTML := wantsHTML(c)
+
+	var req struct {
+		Criteria  string `json:"criteria" validate:"required"`
+		Algorithm string `json:"algorithm"`
+	}
+
+	if isFormRequest(c) {
+		// Handle form data or URL params for HTMX requests
+		req.Criteria = c.FormValue("criteria")
+		if req.Criteria == "" {
+			req.Criteria = c.QueryParam("criteria")
+		}
+		req.Algorithm = c.FormValue("algorithm")
+		if req.Algorithm == "" {
+			req.Algorithm = c.QueryParam("algorithm")
+		}
+	} else {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Invalid request format",
+			})
+		}
+	}
+
+	if req.Algorithm == "" {
+		req.Algorithm = "merge" // Default to merge sort
+	}
+
+	// Map string criteria to enum
+	criteria, err := datastructures.ParseSortCriteria(req.Criteria)
+	if err != nil {
+		if asHTML {
+			return c.HTML(http.StatusBadRequest, `<div class="text-red-500">Invalid sort criteria</div>`)
+		}
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid sort criteria",
+		})
+	}
+
+	usedAlgorithm := ph.engineFor(c).SortPlaylist(criteria, req.Algorithm)
+
+	if asHTML {
+		// Return updated playlist HTML
+		return ph.GetPlaylistHTML(c)
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"message":   fmt.Sprintf("Playlist sorted by %s using %s sort", req.Criteria, usedAlgorithm),
+		"algorithm": usedAlgorithm,
+	}
+	if usedAlgorithm != req.Algorithm {
+		response["warning"] = fmt.Sprintf("Unknown sort algorithm '%s', fell back to %s sort", req.Algorithm, usedAlgorithm)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetPlaybackHistory returns the playback history
+// GET /api/playlist/history
+func (ph *PlaylistHandlers) GetPlaybackHistory(c echo.Context) error {
+	countStr := c.QueryParam("count")
+	count := 10 // Default count
+
+	if countStr != "" {
+		if parsedCount, err := strconv.Atoi(countStr); err == nil && parsedCount > 0 {
+			count = ph.clampResults(parsedCount)
+		}
+	}
+
+	offset := 0
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+
+	songs := ph.engineFor(c).GetRecentlyPlayedSongsPaged(offset, count)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"history": songs,
+			"count":   len(songs),
+			"offset":  offset,
+			"total":   ph.engineFor(c).GetHistorySize(),
+		},
+	})
+}
+
+// GetAllArtists returns the distinct artists across the playlist with sorting and pagination
+// Query params: sort ("name" or "-name"), offset and limit (default 50)
+// GET /api/artists
+func (ph *PlaylistHandlers) GetAllArtists(c echo.Context) error {
+	sortOrder := c.QueryParam("sort")
+	if sortOrder == "" {
+		sortOrder = "name"
+	}
+	if sortOrder != "name" && sortOrder != "-name" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Sort must be 'name' or '-name'",
+		})
+	}
+
+	limit := 50 // Default page size
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = ph.clampResults(parsedLimit)
+		}
+	}
+
+	offset := 0
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+
+	artists := ph.engineFor(c).GetAllArtists()
+	total := len(artists)
+
+	if sortOrder == "-name" {
+		for i, j := 0, len(artists)-1; i < j; i, j = i+1, j-1 {
+			artists[i], artists[j] = artists[j], artists[i]
+		}
+	}
+
+	page := make([]string, 0)
+	if offset < total {
+		page = artists[offset:min(offset+limit, total)]
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"artists": page,
+			"count":   len(page),
+			"offset":  offset,
+			"limit":   limit,
+			"total":   total,
+		},
+	})
+}
+
+// GetArtistProfile returns every song by an artist across the playlist with play counts, average rating,
+// dominant moods and the most played track
+// GET /api/artists/:artist
+func (ph *PlaylistHandlers) GetArtistProfile(c echo.Context) error {
+	profile, err := ph.engineFor(c).GetArtistProfile(c.Param("artist"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// GetAlbums lists every album with its song count, total duration and average rating
+// Query params: sort is name, -name, duration (longest first) or rating (highest first), default name;
+// limit (default 50) and offset page through the albums
+// GET /api/albums
+func (ph *PlaylistHandlers) GetAlbums(c echo.Context) error {
+	sortOrder := c.QueryParam("sort")
+	if sortOrder == "" {
+		sortOrder = "name"
+	}
+	if sortOrder != "name" && sortOrder != "-name" && sortOrder != "duration" && sortOrder != "rating" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Sort must be 'name', '-name', 'duration' or 'rating'",
+		})
+	}
+
+	limit := 50 // Default page size
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = ph.clampResults(parsedLimit)
+		}
+	}
+
+	offset := 0
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+
+	albums := ph.engineFor(c).GetAlbums()
+	total := len(albums)
+
+	// Albums arrive sorted A-Z, so stable sorts keep ties alphabetical
+	switch sortOrder {
+	case "-name":
+		for i, j := 0, len(albums)-1; i < j; i, j = i+1, j-1 {
+			albums[i], albums[j] = albums[j], albums[i]
+		}
+	case "duration":
+		sort.SliceStable(albums, func(i, j int) bool {
+			return albums[i].TotalDuration > albums[j].TotalDuration
+		})
+	case "rating":
+		sort.SliceStable(albums, func(i, j int) bool {
+			return albums[i].AverageRating > albums[j].AverageRating
+		})
+	}
+
+	page := make([]services.AlbumSummary, 0)
+	if offset < total {
+		page = albums[offset:min(offset+limit, total)]
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"albums": page,
+			"count":  len(page),
+			"offset": offset,
+			"limit":  limit,
+			"total":  total,
+		},
+	})
+}
+
+// GetAlbumSongs returns an album's songs in the order they were added, with the album's stats
+// Query param artist picks between albums sharing the name; without it a shared name is a 409
+// GET /api/albums/:album/songs
+func (ph *PlaylistHandlers) GetAlbumSongs(c echo.Context) error {
+	album, songs, err := ph.engineFor(c).GetAlbum(c.Param("album"), c.QueryParam("artist"))
+	if err != nil {
+		return c.JSON(albumErrorStatus(err), map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"album": album,
+			"songs": songs,
+			"count": len(songs),
+		},
+	})
+}
+
+// PlayAlbum appends an album's songs to the play queue in the order they were added
+// Query param artist picks between albums sharing the name; without it a shared name is a 409
+// POST /api/albums/:album/play
+func (ph *PlaylistHandlers) PlayAlbum(c echo.Context) error {
+	songs, err := ph.engineFor(c).PlayAlbum(c.Param("album"), c.QueryParam("artist"))
+	if err != nil {
+		return c.JSON(albumErrorStatus(err), map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Queued %d songs", len(songs)),
+		"data": map[string]interface{}{
+			"songs": songs,
+			"count": len(songs),
+		},
+	})
+}
+
+// albumErrorStatus maps an album lookup error to 409 for a name shared by several artists, 404 otherwise
+func albumErrorStatus(err error) int {
+	if errors.Is(err, services.ErrAmbiguousAlbum) {
+		return http.StatusConflict
+	}
+	return http.StatusNotFound
+}
+
+// GetRecentlyAdded returns the most recently added songs without reordering the playlist
+// GET /api/playlist/recent
+func (ph *PlaylistHandlers) GetRecentlyAdded(c echo.Context) error {
+	count := 10 // Default count
+	if countStr := c.QueryParam("count"); countStr != "" {
+		if parsedCount, err := strconv.Atoi(countStr); err == nil && parsedCount > 0 {
+			count = ph.clampResults(parsedCount)
+		}
+	}
+
+	songs := ph.engineFor(c).GetRecentlyAdded(count)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs": songs,
+			"count": len(songs),
+		},
+	})
+}
+
+// GetActivityLog returns recent playlist changes, newest first
+// Query param limit caps the number of entries (default 20)
+// GET /api/playlist/activity
+func (ph *PlaylistHandlers) GetActivityLog(c echo.Context) error {
+	limit := 20 // Default limit
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Limit must be a positive integer",
+			})
+		}
+		limit = ph.clampResults(parsedLimit)
+	}
+
+	activities := ph.engineFor(c).GetActivityLog(limit)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"activities": activities,
+			"count":      len(activities),
+		},
+	})
+}
+
+// GetSessions returns playback history grouped into listening sessions
+// Query param gap sets the idle time that starts a new session (default 30m)
+// GET /api/playlist/sessions
+func (ph *PlaylistHandlers) GetSessions(c echo.Context) error {
+	gap := 30 * time.Minute
+	if gapStr := c.QueryParam("gap"); gapStr != "" {
+		parsedGap, err := time.ParseDuration(gapStr)
+		if err != nil || parsedGap <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Gap must be a positive duration such as 30m or 1h",
+			})
+		}
+		gap = parsedGap
+	}
+
+	sessions := ph.engineFor(c).GetSessions(gap)
+
+	summaries := make([]map[string]interface{}, 0, len(sessions))
+	for _, session := range sessions {
+		totalDuration := 0
+		for _, entry := range session {
+			totalDuration += entry.Song.Duration
+		}
+
+		summaries = append(summaries, map[string]interface{}{
+			"start":          session[0].PlayedAt,
+			"end":            session[len(session)-1].PlayedAt,
+			"song_count":     len(session),
+			"total_duration": totalDuration,
+			"entries":        session,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"sessions": summaries,
+			"count":    len(summaries),
+			"gap":      gap.String(),
+		},
+	})
+}
+
+// GetGenres returns all available genres
+// GET /api/explorer/genres
+func (ph *PlaylistHandlers) GetGenres(c echo.Context) error {
+	genres := ph.engineFor(c).GetGenres()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"genres": genres,
+			"count":  len(genres),
+		},
+	})
+}
+
+// GetSubgenres returns subgenres for a specific genre
+// GET /api/explorer/genres/:genre/subgenres
+func (ph *PlaylistHandlers) GetSubgenres(c echo.Context) error {
+	genre := c.Param("genre")
+	subgenres := ph.engineFor(c).GetSubgenres(genre)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"genre":     genre,
+			"subgenres": subgenres,
+			"count":     len(subgenres),
+		},
+	})
+}
+
+// RenameGenre moves every song in a genre to a new genre name
+// The genre in the path is matched case-insensitively
+// PUT /api/explorer/genres/:genre/rename
+func (ph *PlaylistHandlers) RenameGenre(c echo.Context) error {
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	if err := c.Bind(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "New genre name is required",
+		})
+	}
+
+	genre := c.Param("genre")
+	renamed := ph.engineFor(c).RenameGenre(genre, req.Name)
+	if renamed == 0 {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("No songs in genre '%s' to rename", genre),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Moved %d songs to %s", renamed, strings.TrimSpace(req.Name)),
+		"data": map[string]interface{}{
+			"renamed": renamed,
+			"genres":  ph.engineFor(c).GetGenres(),
+		},
+	})
+}
+
+// GetMoods returns moods for a specific genre and subgenre
+// GET /api/explorer/genres/:genre/subgenres/:subgenre/moods
+func (ph *PlaylistHandlers) GetMoods(c echo.Context) error {
+	genre := c.Param("genre")
+	subgenre := c.Param("subgenre")
+	moods := ph.engineFor(c).GetMoods(genre, subgenre)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"genre":    genre,
+			"subgenre": subgenre,
+			"moods":    moods,
+			"count":    len(moods),
+		},
+	})
+}
+
+// GetArtists returns artists for a specific genre, subgenre, and mood
+// GET /api/explorer/genres/:genre/subgenres/:subgenre/moods/:mood/artists
+func (ph *PlaylistHandlers) GetArtists(c echo.Context) error {
+	genre := c.Param("genre")
+	subgenre := c.Param("subgenre")
+	mood := c.Param("mood")
+	artists := ph.engineFor(c).GetArtists(genre, subgenre, mood)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"genre":    genre,
+			"subgenre": subgenre,
+			"mood":     mood,
+			"artists":  artists,
+			"count":    len(artists),
+		},
+	})
+}
+
+// GetSongsByExplorer returns songs for a specific path in the explorer
+// GET /api/explorer/songs
+func (ph *PlaylistHandlers) GetSongsByExplorer(c echo.Context) error {
+	genre := c.QueryParam("genre")
+	subgenre := c.QueryParam("subgenre")
+	mood := c.QueryParam("mood")
+	artist := c.QueryParam("artist")
+
+	songs := ph.engineFor(c).GetPlaylistByExplorer(genre, subgenre, mood, artist)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"path": map[string]string{
+				"genre":    genre,
+				"subgenre": subgenre,
+				"mood":     mood,
+				"artist":   artist,
+			},
+			"songs": songs,
+			"count": len(songs),
+		},
+	})
+}
+
+// GetExplorerTree returns the explorer tree as nested JSON for collapsible tree UIs
+// Query param depth limits the levels returned: 1 = genres only, 4 = full tree (default)
+// GET /api/explorer/tree
+func (ph *PlaylistHandlers) GetExplorerTree(c echo.Context) error {
+	depth := datastructures.MaxTreeDepth
+	if depthStr := c.QueryParam("depth"); depthStr != "" {
+		parsedDepth, err := strconv.Atoi(depthStr)
+		if err != nil || parsedDepth < 1 || parsedDepth > datastructures.MaxTreeDepth {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("Depth must be between 1 and %d", datastructures.MaxTreeDepth),
+			})
+		}
+		depth = parsedDepth
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    ph.engineFor(c).GetTreeStructureDepth(depth),
+	})
+}
+
+// GetInterleavedGenres returns songs alternating between two genres without changing the playlist
+// GET /api/explorer/interleave?a=Rock&b=Pop
+func (ph *PlaylistHandlers) GetInterleavedGenres(c echo.Context) error {
+	a := strings.TrimSpace(c.QueryParam("a"))
+	b := strings.TrimSpace(c.QueryParam("b"))
+	if a == "" || b == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Both genres a and b are required",
+		})
+	}
+
+	songs := ph.engineFor(c).InterleaveGenres(a, b)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs": songs,
+			"count": len(songs),
+			"a":     a,
+			"b":     b,
+		},
+	})
+}
+
+// GetRecommendations returns smart recommendations
+// Query params: count (default 10) and window, the number of recent plays considered (default 20)
+// decay=true ranks candidates by time-decayed play count, with an optional half_life duration
+// GET /api/playlist/recommendations
+func (ph *PlaylistHandlers) GetRecommendations(c echo.Context) error {
+	countStr := c.QueryParam("count")
+	count := 10 // Default count
+
+	if countStr != "" {
+		if parsedCount, err := strconv.Atoi(countStr); err == nil && parsedCount > 0 {
+			count = ph.clampResults(parsedCount)
+		}
+	}
+
+	window := services.DefaultRecommendationWindow
+	if windowStr := c.QueryParam("window"); windowStr != "" {
+		parsedWindow, err := strconv.Atoi(windowStr)
+		if err != nil || parsedWindow <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Window must be a positive integer",
+			})
+		}
+		window = parsedWindow
+	}
+
+	opts := services.RecOptions{Count: count, HistoryWindow: window}
+	opts.DecayPlays = c.QueryParam("decay") == "true"
+	if halfLifeStr := c.QueryParam("half_life"); halfLifeStr != "" {
+		halfLife, err := time.ParseDuration(halfLifeStr)
+		if err != nil || halfLife <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "half_life must be a positive duration such as 168h",
+			})
+		}
+		opts.HalfLife = halfLife
+	}
+
+	recommendations := ph.engineFor(c).GetSmartRecommendationsWithOptions(opts)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"recommendations": recommendations,
+			"count":           len(recommendations),
+			"window":          window,
+		},
+	})
+}
+
+// SetRecommendationMode changes the similarity mode used for recommendations
+// PUT /api/playlist/recommendations/mode
+func (ph *PlaylistHandlers) SetRecommendationMode(c echo.Context) error {
+	var req struct {
+		Mode string `json:"mode" validate:"required"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid request format",
+		})
+	}
+
+	mode, err := models.ParseSimilarityMode(req.Mode)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Mode must be 'default', 'strict' or 'loose'",
+		})
+	}
+
+	ph.engineFor(c).SetSimilarityMode(mode)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Recommendation mode updated successfully",
+		"data": map[string]interface{}{
+			"mode": mode.String(),
+		},
+	})
+}
+
+// maxRecommendationBenchmarkIterations bounds how long a benchmark request can hold the playlist
+const maxRecommendationBenchmarkIterations = 10000
+
+// BenchmarkRecommendations compares finding similar songs with a playlist scan and with the similarity index
+// GET /api/playlist/recommendations/benchmark
+func (ph *PlaylistHandlers) BenchmarkRecommendations(c echo.Context) error {
+	iterations := 100
+	if iterationsStr := c.QueryParam("iterations"); iterationsStr != "" {
+		parsed, err := strconv.Atoi(iterationsStr)
+		if err != nil || parsed <= 0 || parsed > maxRecommendationBenchmarkIterations {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("iterations must be between 1 and %d", maxRecommendationBenchmarkIterations),
+			})
+		}
+		iterations = parsed
+	}
+
+	benchmark := ph.engineFor(c).BenchmarkRecommendations(iterations)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    benchmark,
+	})
+}
+
+// GetDashboard returns a comprehensive dashboard snapshot
+// GET /api/dashboard
+func (ph *PlaylistHandlers) GetDashboard(c echo.Context) error {
+	snapshot := ph.engineFor(c).ExportSnapshotAsync()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    snapshot,
+	})
+}
+
+// GetStats returns playlist statistics
+// GET /api/playlist/stats
+func (ph *PlaylistHandlers) GetStats(c echo.Context) error {
+	stats := ph.engineFor(c).GetPlaylistStats()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// GetTodayStats returns play count and listen time for songs played since local midnight
+// GET /api/playlist/stats/today
+func (ph *PlaylistHandlers) GetTodayStats(c echo.Context) error {
+	stats := ph.engineFor(c).GetTodayStats()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// GetPlaybackStatsRange returns playback statistics for plays between two RFC 3339 timestamps
+// from defaults to the start of history and to defaults to now
+// GET /api/playlist/stats/playback?from=2024-01-01T00:00:00Z&to=2024-01-08T00:00:00Z
+func (ph *PlaylistHandlers) GetPlaybackStatsRange(c echo.Context) error {
+	var from time.Time
+	to := time.Now()
+
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := c.QueryParam(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"error":   fmt.Sprintf("%s must be an RFC 3339 timestamp such as 2024-01-02T15:04:05Z", param),
+				})
+			}
+			*target = parsed
+		}
+	}
+
+	if from.After(to) {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "from must not be after to",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    ph.engineFor(c).GetPlaybackStatsRange(from, to),
+	})
+}
+
+// GetListeningTimeline returns plays and listening time per day, top artists and listening time per
+// week with the change from the week before, and the listening trend against the preceding days
+// Query params: days (default 30), weeks (default 4) and top artists per week (default 5)
+// GET /api/stats/timeline?days=30&weeks=4&top=5
+func (ph *PlaylistHandlers) GetListeningTimeline(c echo.Context) error {
+	engine := ph.engineFor(c)
+	retained := engine.TimelineDays()
+
+	// The trend compares with the days before, and the first week with the week before it
+	params := []struct {
+		name       string
+		value, max int
+	}{
+		{"days", 30, retained / 2},
+		{"weeks", 4, retained/7 - 1},
+		{"top", 5, 50},
+	}
+	for i, param := range params {
+		if valueStr := c.QueryParam(param.name); valueStr != "" {
+			value, err := strconv.Atoi(valueStr)
+			if err != nil || value < 1 || value > param.max {
+				return c.JSON(http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"error":   fmt.Sprintf("%s must be between 1 and %d", param.name, param.max),
+				})
+			}
+			params[i].value = value
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    engine.GetListeningTimeline(params[0].value, params[1].value, params[2].value),
+	})
+}
+
+// GetGenreStatistics returns song count, duration and rating aggregates per genre
+// GET /api/playlist/stats/by-genre
+func (ph *PlaylistHandlers) GetGenreStatistics(c echo.Context) error {
+	stats := ph.engineFor(c).GetGenreStatistics()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// GetBPMHistogram returns song counts per BPM range
+// Query param bucket sets the width of each range in BPM (default 20)
+// GET /api/playlist/stats/bpm
+func (ph *PlaylistHandlers) GetBPMHistogram(c echo.Context) error {
+	bucketSize := 20 // Default bucket width
+	if bucketStr := c.QueryParam("bucket"); bucketStr != "" {
+		parsedBucket, err := strconv.Atoi(bucketStr)
+		if err != nil || parsedBucket <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Bucket must be a positive integer",
+			})
+		}
+		bucketSize = parsedBucket
+	}
+
+	histogram := ph.engineFor(c).GetBPMHistogram(bucketSize)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"histogram":   histogram,
+			"labels":      services.SortHistogramLabels(histogram),
+			"bucket_size": bucketSize,
+		},
+	})
+}
+
+// GetDurationHistogram returns song counts per duration band
+// Query param bucket sets the width of each band in seconds (default 60)
+// GET /api/playlist/stats/duration
+func (ph *PlaylistHandlers) GetDurationHistogram(c echo.Context) error {
+	bucketSeconds := 60 // Default bucket width
+	if bucketStr := c.QueryParam("bucket"); bucketStr != "" {
+		parsedBucket, err := strconv.Atoi(bucketStr)
+		if err != nil || parsedBucket <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Bucket must be a positive number of seconds",
+			})
+		}
+		bucketSeconds = parsedBucket
+	}
+
+	histogram := ph.engineFor(c).GetDurationHistogram(bucketSeconds)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"histogram":   histogram,
+			"labels":      services.SortHistogramLabels(histogram),
+			"bucket_size": bucketSeconds,
+		},
+	})
+}
+
+// GetTopSongs returns the highest ranked songs without sorting the playlist
+// Query param by picks the ranking: duration, plays (alias playcount) or rating (default plays);
+// k (alias count) defaults to 10
+// GET /api/playlist/top
+func (ph *PlaylistHandlers) GetTopSongs(c echo.Context) error {
+	count := 10 // Default count
+	countStr := c.QueryParam("k")
+	if countStr == "" {
+		countStr = c.QueryParam("count")
+	}
+	if countStr != "" {
+		if parsedCount, err := strconv.Atoi(countStr); err == nil && parsedCount > 0 {
+			count = ph.clampResults(parsedCount)
+		}
+	}
+
+	by := c.QueryParam("by")
+	var songs []*models.Song
+	switch by {
+	case "duration":
+		songs = ph.engineFor(c).GetLongestSongs(count)
+	case "plays", "playcount", "":
+		by = "plays"
+		songs = ph.engineFor(c).GetMostPlayedSongs(count)
+	case "rating":
+		songs = ph.engineFor(c).GetTopRatedSongs(count)
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "by must be 'duration', 'plays', 'playcount' or 'rating'",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs": songs,
+			"count": len(songs),
+			"by":    by,
+		},
+	})
+}
+
+// GetFlowAnalysis flags jarring transitions between adjacent songs
+// Query params override the defaults: max_bpm_jump (0 disables), genre and mood (true/false)
+// GET /api/playlist/flow
+func (ph *PlaylistHandlers) GetFlowAnalysis(c echo.Context) error {
+	thresholds := services.DefaultFlowThresholds()
+
+	if jumpStr := c.QueryParam("max_bpm_jump"); jumpStr != "" {
+		jump, err := strconv.Atoi(jumpStr)
+		if err != nil || jump < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "max_bpm_jump must be a non-negative integer",
+			})
+		}
+		thresholds.MaxBPMJump = jump
+	}
+
+	for param, flag := range map[string]*bool{
+		"genre": &thresholds.FlagGenreChange,
+		"mood":  &thresholds.FlagMoodClash,
+	} {
+		if value := c.QueryParam(param); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"error":   fmt.Sprintf("%s must be true or false", param),
+				})
+			}
+			*flag = parsed
+		}
+	}
+
+	warnings := ph.engineFor(c).AnalyzeFlowWithThresholds(thresholds)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"warnings":   warnings,
+			"count":      len(warnings),
+			"thresholds": thresholds,
+		},
+	})
+}
+
+// OrderByBPMRamp reorders the playlist so BPM rises gradually, with unknown BPMs at the end
+// The response lists any BPM jumps still above the default flow threshold
+// POST /api/playlist/order/bpm-ramp
+func (ph *PlaylistHandlers) OrderByBPMRamp(c echo.Context) error {
+	songs := ph.engineFor(c).ApplyBPMRamp()
+
+	if wantsHTML(c) {
+		return ph.GetPlaylistHTML(c)
+	}
+
+	thresholds := services.FlowThresholds{MaxBPMJump: services.DefaultFlowThresholds().MaxBPMJump}
+	warnings := ph.engineFor(c).AnalyzeFlowWithThresholds(thresholds)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Playlist ordered by BPM",
+		"data": map[string]interface{}{
+			"songs":    songs,
+			"warnings": warnings,
+		},
+	})
+}
+
+// GenerateMoodArc assembles a journey through moods in order without changing the playlist
+// Body: {"moods": ["Calm", "Happy", "Energetic"], "per_mood": 3}
+// POST /api/playlist/generate/mood-arc
+func (ph *PlaylistHandlers) GenerateMoodArc(c echo.Context) error {
+	var req struct {
+		Moods   []string `json:"moods"`
+		PerMood int      `json:"per_mood"`
+	}
+
+	if err := c.Bind(&req); err != nil || len(req.Moods) == 0 || req.PerMood <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "moods and a positive per_mood are required",
+		})
+	}
+
+	perMood := ph.clampResults(req.PerMood)
+	songs := ph.engineFor(c).GenerateMoodArc(req.Moods, perMood)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"songs":    songs,
+			"count":    len(songs),
+			"moods":    req.Moods,
+			"per_mood": perMood,
+		},
+	})
+}
+
+// GenerateMix builds a DJ-style ordering where consecutive songs stay within max_bpm_step BPM
+// of each other without a mood clash, leaving the playlist unchanged
+// Query params: max_bpm_step (default 10), start (song ID to open with) and length (default as long as possible)
+// GET /api/playlist/mix?max_bpm_step=8&length=20
+func (ph *PlaylistHandlers) GenerateMix(c echo.Context) error {
+	options := services.MixOptions{StartSongID: c.QueryParam("start")}
+	for param, target := range map[string]*int{"max_bpm_step": &options.MaxBPMStep, "length": &options.Length} {
+		if valueStr := c.QueryParam(param); valueStr != "" {
+			value, err := strconv.Atoi(valueStr)
+			if err != nil || value < 1 {
+				return c.JSON(http.StatusBadRequest, map[string]interface{}{
+					"success": false,
+					"error":   fmt.Sprintf("%s must be a positive integer", param),
+				})
+			}
+			*target = value
+		}
+	}
+	if options.Length > 0 {
+		options.Length = ph.clampResults(options.Length)
+	}
+
+	mix, err := ph.engineFor(c).GenerateMix(options)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    mix,
+	})
+}
+
+// BenchmarkSort compares sorting algorithm performance
+// GET /api/playlist/benchmark
+func (ph *PlaylistHandlers) BenchmarkSort(c echo.Context) error {
+	benchmarks := ph.engineFor(c).BenchmarkSort()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"benchmarks": benchmarks,
+		},
+	})
+}
+
+// ClearPlaylist removes all songs from the playlist
+// DELETE /api/playlist
+func (ph *PlaylistHandlers) ClearPlaylist(c echo.Context) error {
+	ph.engineFor(c).ClearPlaylist()
+
+	// Respond with HTML for HTMX and browser requests, JSON otherwise
+	asHTML := wantsHTML(c)
+
+	if asHTML {
+		// Return updated playlist HTML
+		return ph.GetPlaylistHTML(c)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Playlist cleared successfully",
+	})
+}
+
+// SetPlaylistName updates the playlist name
+// PUT /api/playlist/name
+func (ph *PlaylistHandlers) SetPlaylistName(c echo.Context) error {
+	var req struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid request format",
+		})
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Playlist name cannot be empty",
+		})
+	}
+
+	ph.engineFor(c).SetPlaylistName(req.Name)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Playlist name updated successfully",
+		"data": map[string]interface{}{
+			"name": req.Name,
+		},
+	})
+}
+
+// ListPlaylists returns every playlist with its song count and which one is active
+// GET /api/playlists
+func (ph *PlaylistHandlers) ListPlaylists(c echo.Context) error {
+	playlists := ph.playlistsFor(c)
+	summaries := playlists.List()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"playlists": summaries,
+			"count":     len(summaries),
+			"active_id": playlists.ActiveID(),
+		},
+	})
+}
+
+// CreatePlaylist creates an empty playlist; it becomes active only when "activate" is true
+// Body: {"name": "Road Trip", "activate": false}
+// POST /api/playlists
+func (ph *PlaylistHandlers) CreatePlaylist(c echo.Context) error {
+	var req struct {
+		Name     string `json:"name"`
+		Activate bool   `json:"activate"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid request format",
+		})
+	}
+
+	playlists := ph.playlistsFor(c)
+	id, err := playlists.Create(req.Name)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	if req.Activate {
+		playlists.Switch(id)
+	}
+	if ph.autoSaver != nil && ph.userIDFor(c) == services.DefaultUserID {
+		engine, _ := playlists.Get(id)
+		ph.autoSaver.Watch(id, engine)
+		ph.autoSaver.Notify(id) // Saved right away so the empty playlist survives a restart
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"message": "Playlist created successfully",
+		"data": map[string]interface{}{
+			"id":     id,
+			"name":   strings.TrimSpace(req.Name),
+			"active": playlists.ActiveID() == id,
+		},
+	})
+}
+
+// DeletePlaylist deletes a playlist and all of its songs
+// Deleting the active playlist activates the oldest remaining one; the last playlist can't be deleted
+// DELETE /api/playlists/:playlistId
+func (ph *PlaylistHandlers) DeletePlaylist(c echo.Context) error {
+	playlists := ph.playlistsFor(c)
+	id := c.Param("playlistId")
+	if err := playlists.Delete(id); err != nil {
+		status := http.StatusConflict
+		if errors.Is(err, services.ErrPlaylistNotFound) {
+			status = http.StatusNotFound
+		}
+		return c.JSON(status, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	// Otherwise the auto-saved snapshot would bring the playlist back on restart
+	if ph.autoSaver != nil && ph.userIDFor(c) == services.DefaultUserID {
+		if err := ph.store.Delete(id); err != nil {
+			log.Printf("failed to delete saved playlist %s: %v", id, err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Playlist deleted successfully",
+		"data": map[string]interface{}{
+			"active_id": playlists.ActiveID(),
+		},
+	})
+}
+
+// ActivatePlaylist switches the playlist served by /api/playlist and the dashboard
+// POST /api/playlists/:playlistId/activate
+func (ph *PlaylistHandlers) ActivatePlaylist(c echo.Context) error {
+	playlists := ph.playlistsFor(c)
+	if err := playlists.Switch(c.Param("playlistId")); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Playlist activated",
+		"data": map[string]interface{}{
+			"active_id": playlists.ActiveID(),
+		},
+	})
+}
+
+// ListUsers returns every user with their playlist count, the default user first, and which user
+// the request acts as
+// GET /api/users
+func (ph *PlaylistHandlers) ListUsers(c echo.Context) error {
+	users := ph.users.List()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"users":   users,
+			"count":   len(users),
+			"current": requestedUserID(c),
+		},
+	})
+}
+
+// CreateUser creates a user with one empty playlist of their own
+// Body: {"name": "Alice"}; later requests act as the user by sending the returned ID in X-User-ID
+// POST /api/users
+func (ph *PlaylistHandlers) CreateUser(c echo.Context) error {
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid request format",
+		})
+	}
+
+	id, err := ph.users.Create(req.Name)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"message": "User created successfully",
+		"data": map[string]interface{}{
+			"id":   id,
+			"name": strings.TrimSpace(req.Name),
+		},
+	})
+}
+
+// StartUserSession sets the session cookie so later browser requests act as the user
+// POST /api/users/:userId/session
+func (ph *PlaylistHandlers) StartUserSession(c echo.Context) error {
+	userID := c.Param("userId")
+	if _, err := ph.users.Playlists(userID); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     UserSessionCookie,
+		Value:    userID,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Session started",
+		"data": map[string]interface{}{
+			"user_id": userID,
+		},
+	})
+}
+
+// EndUserSession clears the session cookie, so browser requests act as the default user again
+// DELETE /api/users/session
+func (ph *PlaylistHandlers) EndUserSession(c echo.Context) error {
+	c.SetCookie(&http.Cookie{
+		Name:     UserSessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Session ended",
+	})
+}
+
+// MigratePlaylist moves a playlist with its songs, ratings and history to another user
+// Body: {"to_user_id": "bob"}; the playlist's ID under its new owner is returned
+// POST /api/users/:userId/playlists/:playlistId/migrate
+func (ph *PlaylistHandlers) MigratePlaylist(c echo.Context) error {
+	var req struct {
+		ToUserID string `json:"to_user_id"`
 	}
 
-	// Map string criteria to enum
-	var criteria datastructures.SortCriteria
-	switch req.Criteria {
-	case "title":
-		criteria = datastructures.SortByTitle
-	case "artist":
-		criteria = datastructures.SortByArtist
-	case "duration_asc":
-		criteria = datastructures.SortByDurationAsc
-	case "duration_desc":
-		criteria = datastructures.SortByDurationDesc
-	case "recently_added":
-		criteria = datastructures.SortByRecentlyAdded
-	case "oldest_added":
-		criteria = datastructures.SortByOldestAdded
-	case "rating":
-		criteria = datastructures.SortByRating
-	case "play_count":
-		criteria = datastructures.SortByPlayCount
-	default:
-		if isHTMX {
-			return c.HTML(http.StatusBadRequest, `<div class="text-red-500">Invalid sort criteria</div>`)
-		}
+	if err := c.Bind(&req); err != nil || req.ToUserID == "" {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"success": false,
-			"error":   "Invalid sort criteria",
+			"error":   "to_user_id is required",
 		})
 	}
 
-	ph.engine.SortPlaylist(criteria, req.Algorithm)
+	fromUserID := c.Param("userId")
+	newID, err := ph.users.MigratePlaylist(fromUserID, c.Param("playlistId"), req.ToUserID)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrPlaylistNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrLastPlaylist):
+			status = http.StatusConflict
+		}
+		return c.JSON(status, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
 
-	if isHTMX {
-		// Return updated playlist HTML
-		return ph.GetPlaylistHTML(c)
+	// Only the default user's playlists are auto-saved
+	if ph.autoSaver != nil {
+		playlists, _ := ph.users.Playlists(req.ToUserID)
+		engine, _ := playlists.Get(newID)
+		if req.ToUserID == services.DefaultUserID {
+			ph.autoSaver.Watch(newID, engine)
+		} else if fromUserID == services.DefaultUserID {
+			engine.SetChangeHook(nil)
+			if err := ph.store.Delete(c.Param("playlistId")); err != nil {
+				log.Printf("failed to delete saved playlist %s: %v", c.Param("playlistId"), err)
+			}
+		}
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": fmt.Sprintf("Playlist sorted by %s using %s sort", req.Criteria, req.Algorithm),
+		"message": "Playlist migrated successfully",
+		"data": map[string]interface{}{
+			"user_id":     req.ToUserID,
+			"playlist_id": newID,
+		},
 	})
 }
 
-// GetPlaybackHistory returns the playback history
-// GET /api/playlist/history
-func (ph *PlaylistHandlers) GetPlaybackHistory(c echo.Context) error {
-	countStr := c.QueryParam("count")
-	count := 10 // Default count
+// smartPlaylistRequest is the body of CreateSmartPlaylist and UpdateSmartPlaylist
+// Rules come either as a list or as a query such as "rating >= 4 AND genre = Rock"
+type smartPlaylistRequest struct {
+	Name  string               `json:"name"`
+	Match services.SmartMatch  `json:"match"`
+	Rules []services.SmartRule `json:"rules"`
+	Query string               `json:"query"`
+}
 
-	if countStr != "" {
-		if parsedCount, err := strconv.Atoi(countStr); err == nil && parsedCount > 0 {
-			count = parsedCount
+// bindSmartPlaylist reads a smart playlist request, parsing its query into rules when one is given
+func bindSmartPlaylist(c echo.Context) (smartPlaylistRequest, error) {
+	var req smartPlaylistRequest
+	if err := c.Bind(&req); err != nil {
+		return req, fmt.Errorf("invalid request body")
+	}
+	if req.Query != "" {
+		if len(req.Rules) > 0 {
+			return req, fmt.Errorf("give either rules or query, not both")
+		}
+		rules, err := services.ParseSmartQuery(req.Query)
+		if err != nil {
+			return req, err
 		}
+		req.Rules = rules
+	}
+	return req, nil
+}
+
+// smartPlaylistError responds with 404 for an unknown smart playlist and 400 for anything else
+func smartPlaylistError(c echo.Context, err error) error {
+	status := http.StatusBadRequest
+	if errors.Is(err, services.ErrSmartPlaylistNotFound) {
+		status = http.StatusNotFound
 	}
+	return c.JSON(status, map[string]interface{}{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
 
-	songs := ph.engine.GetRecentlyPlayedSongs(count)
+// ListSmartPlaylists returns every smart playlist with its current song count
+// GET /api/smart-playlists
+func (ph *PlaylistHandlers) ListSmartPlaylists(c echo.Context) error {
+	smartPlaylists := ph.engineFor(c).ListSmartPlaylists()
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
 		"data": map[string]interface{}{
-			"history": songs,
-			"count":   len(songs),
+			"smart_playlists": smartPlaylists,
+			"count":           len(smartPlaylists),
 		},
 	})
 }
 
-// GetGenres returns all available genres
-// GET /api/explorer/genres
-func (ph *PlaylistHandlers) GetGenres(c echo.Context) error {
-	genres := ph.engine.GetGenres()
+// CreateSmartPlaylist adds a playlist whose songs are the ones meeting its rules, kept up to date as songs change
+// Body: {"name": "Fresh Favourites", "query": "rating >= 4 AND play_count < 3"} or
+// {"name": "...", "match": "all|any", "rules": [{"field": "rating", "operator": ">=", "value": 4}]}
+// POST /api/smart-playlists
+func (ph *PlaylistHandlers) CreateSmartPlaylist(c echo.Context) error {
+	req, err := bindSmartPlaylist(c)
+	if err != nil {
+		return smartPlaylistError(c, err)
+	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
+	smart, err := ph.engineFor(c).CreateSmartPlaylist(req.Name, req.Match, req.Rules)
+	if err != nil {
+		return smartPlaylistError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"success": true,
-		"data": map[string]interface{}{
-			"genres": genres,
-			"count":  len(genres),
-		},
+		"message": "Smart playlist created successfully",
+		"data":    smart,
 	})
 }
 
-// GetSubgenres returns subgenres for a specific genre
-// GET /api/explorer/genres/:genre/subgenres
-func (ph *PlaylistHandlers) GetSubgenres(c echo.Context) error {
-	genre := c.Param("genre")
-	subgenres := ph.engine.GetSubgenres(genre)
+// GetSmartPlaylist returns a smart playlist's definition and the songs currently meeting its rules
+// fields (e.g. fields=id,title,artist) returns only those song fields
+// GET /api/smart-playlists/:smartId
+func (ph *PlaylistHandlers) GetSmartPlaylist(c echo.Context) error {
+	fields, err := parseSongFields(c.QueryParam("fields"))
+	if err != nil {
+		return smartPlaylistError(c, err)
+	}
+
+	smart, songs, err := ph.engineFor(c).GetSmartPlaylist(c.Param("smartId"))
+	if err != nil {
+		return smartPlaylistError(c, err)
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
 		"data": map[string]interface{}{
-			"genre":     genre,
-			"subgenres": subgenres,
-			"count":     len(subgenres),
+			"smart_playlist": smart,
+			"songs":          projectSongs(songs, fields),
+			"count":          len(songs),
 		},
 	})
 }
 
-// GetMoods returns moods for a specific genre and subgenre
-// GET /api/explorer/genres/:genre/subgenres/:subgenre/moods
-func (ph *PlaylistHandlers) GetMoods(c echo.Context) error {
-	genre := c.Param("genre")
-	subgenre := c.Param("subgenre")
-	moods := ph.engine.GetMoods(genre, subgenre)
+// UpdateSmartPlaylist replaces a smart playlist's name, match mode and rules
+// Body: as for CreateSmartPlaylist
+// PUT /api/smart-playlists/:smartId
+func (ph *PlaylistHandlers) UpdateSmartPlaylist(c echo.Context) error {
+	req, err := bindSmartPlaylist(c)
+	if err != nil {
+		return smartPlaylistError(c, err)
+	}
+
+	smart, err := ph.engineFor(c).UpdateSmartPlaylist(c.Param("smartId"), req.Name, req.Match, req.Rules)
+	if err != nil {
+		return smartPlaylistError(c, err)
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"data": map[string]interface{}{
-			"genre":    genre,
-			"subgenre": subgenre,
-			"moods":    moods,
-			"count":    len(moods),
-		},
+		"message": "Smart playlist updated successfully",
+		"data":    smart,
 	})
 }
 
-// GetArtists returns artists for a specific genre, subgenre, and mood
-// GET /api/explorer/genres/:genre/subgenres/:subgenre/moods/:mood/artists
-func (ph *PlaylistHandlers) GetArtists(c echo.Context) error {
-	genre := c.Param("genre")
-	subgenre := c.Param("subgenre")
-	mood := c.Param("mood")
-	artists := ph.engine.GetArtists(genre, subgenre, mood)
+// DeleteSmartPlaylist removes a smart playlist, leaving its songs in the playlist
+// DELETE /api/smart-playlists/:smartId
+func (ph *PlaylistHandlers) DeleteSmartPlaylist(c echo.Context) error {
+	if err := ph.engineFor(c).DeleteSmartPlaylist(c.Param("smartId")); err != nil {
+		return smartPlaylistError(c, err)
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"data": map[string]interface{}{
-			"genre":    genre,
-			"subgenre": subgenre,
-			"mood":     mood,
-			"artists":  artists,
-			"count":    len(artists),
-		},
+		"message": "Smart playlist deleted successfully",
 	})
 }
 
-// GetSongsByExplorer returns songs for a specific path in the explorer
-// GET /api/explorer/songs
-func (ph *PlaylistHandlers) GetSongsByExplorer(c echo.Context) error {
-	genre := c.QueryParam("genre")
-	subgenre := c.QueryParam("subgenre")
-	mood := c.QueryParam("mood")
-	artist := c.QueryParam("artist")
-
-	songs := ph.engine.GetPlaylistByExplorer(genre, subgenre, mood, artist)
+// SavePlaylist writes the playlist's songs, ratings, history and name to its snapshot file
+// POST /api/playlist/save
+func (ph *PlaylistHandlers) SavePlaylist(c echo.Context) error {
+	playlistID := ph.playlistIDFor(c)
+	if err := ph.store.Save(ph.snapshotKeyFor(c), ph.engineFor(c)); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
+		"message": "Playlist saved successfully",
 		"data": map[string]interface{}{
-			"path": map[string]string{
-				"genre":    genre,
-				"subgenre": subgenre,
-				"mood":     mood,
-				"artist":   artist,
-			},
-			"songs": songs,
-			"count": len(songs),
+			"id":   playlistID,
+			"size": ph.engineFor(c).GetPlaylistSize(),
 		},
 	})
 }
 
-// GetRecommendations returns smart recommendations
-// GET /api/playlist/recommendations
-func (ph *PlaylistHandlers) GetRecommendations(c echo.Context) error {
-	countStr := c.QueryParam("count")
-	count := 10 // Default count
-
-	if countStr != "" {
-		if parsedCount, err := strconv.Atoi(countStr); err == nil && parsedCount > 0 {
-			count = parsedCount
+// LoadPlaylist replaces the playlist with its last saved snapshot
+// Returns 404 when the playlist has never been saved
+// POST /api/playlist/load
+func (ph *PlaylistHandlers) LoadPlaylist(c echo.Context) error {
+	playlistID := ph.playlistIDFor(c)
+	if err := ph.store.Load(ph.snapshotKeyFor(c), ph.engineFor(c)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrNoSnapshot) {
+			status = http.StatusNotFound
 		}
+		return c.JSON(status, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
 	}
 
-	recommendations := ph.engine.GetSmartRecommendations(count)
+	if wantsHTML(c) {
+		return ph.GetPlaylistHTML(c)
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
+		"message": "Playlist loaded successfully",
 		"data": map[string]interface{}{
-			"recommendations": recommendations,
-			"count":           len(recommendations),
+			"id":   playlistID,
+			"name": ph.engineFor(c).GetPlaylistName(),
+			"size": ph.engineFor(c).GetPlaylistSize(),
 		},
 	})
 }
 
-// GetDashboard returns a comprehensive dashboard snapshot
-// GET /api/dashboard
-func (ph *PlaylistHandlers) GetDashboard(c echo.Context) error {
-	snapshot := ph.engine.ExportSnapshot()
+// ImportM3U adds songs from an extended M3U playlist sent as the request body or a "file" upload
+// Unparseable lines are reported back without aborting the import
+// POST /api/playlist/import/m3u
+func (ph *PlaylistHandlers) ImportM3U(c echo.Context) error {
+	var reader io.Reader = c.Request().Body
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Failed to open uploaded file",
+			})
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	added, errs := services.ImportM3U(ph.engineFor(c), reader)
+
+	errorMessages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		errorMessages = append(errorMessages, err.Error())
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"data":    snapshot,
+		"message": fmt.Sprintf("Imported %d songs", added),
+		"data": map[string]interface{}{
+			"added":  added,
+			"errors": errorMessages,
+		},
 	})
 }
 
-// GetStats returns playlist statistics
-// GET /api/playlist/stats
-func (ph *PlaylistHandlers) GetStats(c echo.Context) error {
-	stats := ph.engine.GetPlaylistStats()
+// csvImportOptions parses CSV rows on one worker per available CPU
+var csvImportOptions = services.CSVImportOptions{Workers: runtime.GOMAXPROCS(0)}
+
+// ImportCSV adds songs from a CSV file sent as the request body or a "file" upload
+// With stream=true the response is a server-sent event stream of "progress" events
+// followed by a single "done" event carrying the final counts and errors
+// POST /api/playlist/import
+// POST /api/playlist/import/csv
+func (ph *PlaylistHandlers) ImportCSV(c echo.Context) error {
+	var reader io.Reader = c.Request().Body
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Failed to open uploaded file",
+			})
+		}
+		defer file.Close()
+		reader = file
+	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"success": true,
-		"data":    stats,
-	})
+	if c.QueryParam("stream") != "true" {
+		result, errs := services.ImportCSVWithOptions(ph.engineFor(c), reader, nil, csvImportOptions)
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("Imported %d songs", result.Added),
+			"data":    csvImportSummary(result, errs),
+		})
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.WriteHeader(http.StatusOK)
+
+	writeEvent := func(event string, data interface{}) {
+		payload, _ := json.Marshal(data)
+		fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload)
+		res.Flush()
+	}
+
+	result, errs := services.ImportCSVWithOptions(ph.engineFor(c), reader, func(progress services.ImportProgress) {
+		writeEvent("progress", progress)
+	}, csvImportOptions)
+	writeEvent("done", csvImportSummary(result, errs))
+
+	return nil
 }
 
-// BenchmarkSort compares sorting algorithm performance
-// GET /api/playlist/benchmark
-func (ph *PlaylistHandlers) BenchmarkSort(c echo.Context) error {
-	benchmarks := ph.engine.BenchmarkSort()
+// csvImportSummary builds the response data for a finished CSV import
+func csvImportSummary(result services.ImportProgress, errs []error) map[string]interface{} {
+	errorMessages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		errorMessages = append(errorMessages, err.Error())
+	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"success": true,
-		"data": map[string]interface{}{
-			"benchmarks": benchmarks,
-		},
-	})
+	return map[string]interface{}{
+		"processed": result.Processed,
+		"added":     result.Added,
+		"skipped":   result.Skipped,
+		"errors":    errorMessages,
+	}
 }
 
-// ClearPlaylist removes all songs from the playlist
-// DELETE /api/playlist
-func (ph *PlaylistHandlers) ClearPlaylist(c echo.Context) error {
-	ph.engine.ClearPlaylist()
+// ImportFromIntegration adds the tracks of a Spotify playlist or a Last.fm user's top tracks
+// Body: {"url": "<Spotify playlist URL>"} or {"lastfm_user": "<name>", "limit": 50}
+// With stream=true the response is a server-sent event stream of "progress" events, reporting
+// each fetched page and then the import counts, followed by a single "done" or "error" event
+// POST /api/integrations/spotify/import
+func (ph *PlaylistHandlers) ImportFromIntegration(c echo.Context) error {
+	var req integrations.ImportRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "Invalid request format",
+		})
+	}
+	engine := ph.engineFor(c)
+	ctx := c.Request().Context()
+
+	if c.QueryParam("stream") != "true" {
+		result, errs, err := ph.integrations.Import(ctx, engine, req, nil)
+		if err != nil {
+			return c.JSON(integrationErrorStatus(err), map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("Imported %d songs", result.Added),
+			"data":    integrationImportSummary(result, errs),
+		})
+	}
 
-	// Check if it's an HTMX request
-	isHTMX := c.Request().Header.Get("HX-Request") == "true"
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.WriteHeader(http.StatusOK)
 
-	if isHTMX {
-		// Return updated playlist HTML
-		return ph.GetPlaylistHTML(c)
+	writeEvent := func(event string, data interface{}) {
+		payload, _ := json.Marshal(data)
+		fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload)
+		res.Flush()
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"success": true,
-		"message": "Playlist cleared successfully",
+	result, errs, err := ph.integrations.Import(ctx, engine, req, func(progress integrations.Progress) {
+		writeEvent("progress", progress)
 	})
+	if err != nil {
+		writeEvent("error", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	writeEvent("done", integrationImportSummary(result, errs))
+
+	return nil
 }
 
-// SetPlaylistName updates the playlist name
-// PUT /api/playlist/name
-func (ph *PlaylistHandlers) SetPlaylistName(c echo.Context) error {
-	var req struct {
-		Name string `json:"name" validate:"required"`
+// integrationErrorStatus maps a failed integration fetch to a status code:
+// 400 for a bad source, 503 when the service isn't configured and 502 when the service itself failed
+func integrationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, integrations.ErrInvalidSource):
+		return http.StatusBadRequest
+	case errors.Is(err, integrations.ErrNotConfigured):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadGateway
 	}
+}
 
-	if err := c.Bind(&req); err != nil {
+// integrationImportSummary builds the response data for a finished integration import
+func integrationImportSummary(result integrations.Progress, errs []error) map[string]interface{} {
+	summary := csvImportSummary(result.ImportProgress, errs)
+	summary["fetched"] = result.Fetched
+	return summary
+}
+
+// ExportTemplate returns the playlist as a shareable JSON template without personal stats
+// GET /api/playlist/export/template
+func (ph *PlaylistHandlers) ExportTemplate(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="playlist-template.json"`)
+	return c.JSONBlob(http.StatusOK, ph.engineFor(c).ExportTemplate())
+}
+
+// ExportPlaylist downloads the whole playlist as an M3U/M3U8 playlist or a JSON document
+// The format query param picks m3u, m3u8 or json and defaults to json
+// GET /api/playlist/export?format=m3u
+func (ph *PlaylistHandlers) ExportPlaylist(c echo.Context) error {
+	name := c.QueryParam("format")
+	if name == "" {
+		name = string(services.ExportJSON)
+	}
+	format, err := services.ParseExportFormat(name)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"success": false,
-			"error":   "Invalid request format",
+			"error":   err.Error(),
 		})
 	}
 
-	if strings.TrimSpace(req.Name) == "" {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, format.ContentType())
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, ph.playlistIDFor(c), format))
+	res.WriteHeader(http.StatusOK)
+	return services.ExportPlaylist(ph.engineFor(c), res, format)
+}
+
+// GetTracklist returns the playlist as a numbered plain-text tracklist for sharing in chat
+// GET /api/playlist/tracklist
+func (ph *PlaylistHandlers) GetTracklist(c echo.Context) error {
+	return c.String(http.StatusOK, ph.engineFor(c).Tracklist())
+}
+
+// ImportTemplate adds the songs of a JSON playlist template sent as the request body
+// Imported songs start with zero play counts and no rating
+// POST /api/playlist/import/template
+func (ph *PlaylistHandlers) ImportTemplate(c echo.Context) error {
+	data, err := io.ReadAll(c.Request().Body)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"success": false,
-			"error":   "Playlist name cannot be empty",
+			"error":   "Failed to read request body",
 		})
 	}
 
-	ph.engine.SetPlaylistName(req.Name)
+	added, errs := services.ImportTemplate(ph.engineFor(c), data)
+
+	errorMessages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		errorMessages = append(errorMessages, err.Error())
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": "Playlist name updated successfully",
+		"message": fmt.Sprintf("Imported %d songs", added),
 		"data": map[string]interface{}{
-			"name": req.Name,
+			"added":  added,
+			"errors": errorMessages,
 		},
 	})
 }
 
 // LoadSampleData loads sample songs into the playlist for demonstration
+// Optional query params: genre (repeatable or comma-separated) and limit
 // POST /api/playlist/sample-data
 func (ph *PlaylistHandlers) LoadSampleData(c echo.Context) error {
-	// Check if it's an HTMX request
-	isHTMX := c.Request().Header.Get("HX-Request") == "true"
+	// Respond with HTML for HTMX and browser requests, JSON otherwise
+	asHTML := wantsHTML(c)
+
+	// Parse optional genre filter and song limit
+	var genres []string
+	for _, value := range c.QueryParams()["genre"] {
+		for _, genre := range strings.Split(value, ",") {
+			if strings.TrimSpace(genre) != "" {
+				genres = append(genres, strings.TrimSpace(genre))
+			}
+		}
+	}
+
+	limit := 0
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"success": false,
+				"error":   "Limit must be a non-negative integer",
+			})
+		}
+		limit = parsedLimit
+	}
 
 	// Clear existing playlist first
-	ph.engine.ClearPlaylist()
+	ph.engineFor(c).ClearPlaylist()
 
 	// Load sample data
 	sampleLoader := services.NewSampleDataLoader()
-	err := sampleLoader.LoadSampleData(ph.engine)
+	ignoredGenres, err := sampleLoader.LoadSampleDataFiltered(ph.engineFor(c), genres, limit)
 	if err != nil {
-		if isHTMX {
-			return c.HTML(http.StatusInternalServerError, fmt.Sprintf(`<div class="text-red-500">Failed to load sample data: %s</div>`, err.Error()))
+		if asHTML {
+			return c.HTML(http.StatusInternalServerError, fmt.Sprintf(`<div class="text-red-500">Failed to load sample data: %s</div>`, html.EscapeString(err.Error())))
 		}
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"success": false,
@@ -686,17 +3864,23 @@ func (ph *PlaylistHandlers) LoadSampleData(c echo.Context) error {
 		})
 	}
 
-	if isHTMX {
+	if asHTML {
 		// Return updated playlist HTML
 		return ph.GetPlaylistHTML(c)
 	}
 
+	data := map[string]interface{}{
+		"songs_loaded": ph.engineFor(c).GetPlaylistSize(),
+	}
+	if len(ignoredGenres) > 0 {
+		data["ignored_genres"] = ignoredGenres
+		data["note"] = "Unknown genres were ignored: " + strings.Join(ignoredGenres, ", ")
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
 		"message": "Sample data loaded successfully",
-		"data": map[string]interface{}{
-			"songsLoaded": ph.engine.GetPlaylistSize(),
-		},
+		"data":    data,
 	})
 }
 
@@ -704,24 +3888,25 @@ func (ph *PlaylistHandlers) LoadSampleData(c echo.Context) error {
 
 // GetPlaylistHTML returns the playlist as HTML for HTMX
 func (ph *PlaylistHandlers) GetPlaylistHTML(c echo.Context) error {
-	songs := ph.engine.GetCurrentPlaylist()
+	songs := ph.engineFor(c).GetCurrentPlaylist()
 
 	if len(songs) == 0 {
-		html := `
+		fragment := `
 		<div class="text-center py-8 text-gray-500">
 			<p class="mb-4">Your playlist is empty</p>
 			<button onclick="loadSampleData()" class="bg-blue-500 hover:bg-blue-600 text-white px-4 py-2 rounded-lg">
 				📦 Load Sample Data
 			</button>
 		</div>`
-		return c.HTML(http.StatusOK, html)
+		return c.HTML(http.StatusOK, fragment)
 	}
 
-	var html strings.Builder
+	var fragment strings.Builder
 	for i, song := range songs {
-		html.WriteString(fmt.Sprintf(`
+		fragment.WriteString(fmt.Sprintf(`
 		<div class="playlist-item bg-gray-50 p-3 rounded-lg border mb-2" data-index="%d">
 			<div class="flex justify-between items-start">
+				%s
 				<div class="flex-1 min-w-0">
 					<div class="flex items-center gap-2 mb-1">
 						<h4 class="font-semibold text-gray-800 truncate">%s</h4>
@@ -736,6 +3921,7 @@ func (ph *PlaylistHandlers) GetPlaylistHTML(c echo.Context) error {
 						%s
 					</div>
 					%s
+					%s
 				</div>
 				<div class="flex flex-col gap-1 ml-4">
 					<button
@@ -755,25 +3941,31 @@ func (ph *PlaylistHandlers) GetPlaylistHTML(c echo.Context) error {
 			</div>
 		</div>`,
 			i,
-			song.Title,
-			song.ID,
-			song.Artist,
+			func() string {
+				if song.ArtworkURL != "" {
+					return fmt.Sprintf(`<img src="%s" alt="" class="w-12 h-12 rounded object-cover mr-3">`, html.EscapeString(song.ArtworkURL))
+				}
+				return ""
+			}(),
+			html.EscapeString(song.Title),
+			html.EscapeString(song.ID),
+			html.EscapeString(song.Artist),
 			func() string {
 				if song.Album != "" {
-					return " • " + song.Album
+					return " • " + html.EscapeString(song.Album)
 				}
 				return ""
 			}(),
-			song.Genre,
+			html.EscapeString(song.Genre),
 			func() string {
 				if song.SubGenre != "" {
-					return "<span>• " + song.SubGenre + "</span>"
+					return "<span>• " + html.EscapeString(song.SubGenre) + "</span>"
 				}
 				return ""
 			}(),
 			func() string {
 				if song.Mood != "" {
-					return "<span>• " + song.Mood + "</span>"
+					return "<span>• " + html.EscapeString(song.Mood) + "</span>"
 				}
 				return ""
 			}(),
@@ -790,39 +3982,45 @@ func (ph *PlaylistHandlers) GetPlaylistHTML(c echo.Context) error {
 				}
 				return ""
 			}(),
+			func() string {
+				if song.Notes != "" {
+					return fmt.Sprintf(`<p class="mt-1 text-xs text-gray-500 italic">📝 %s</p>`, html.EscapeString(song.Notes))
+				}
+				return ""
+			}(),
 			i,
 			i,
 		))
 	}
 
-	return c.HTML(http.StatusOK, html.String())
+	return c.HTML(http.StatusOK, fragment.String())
 }
 
 // GetGenresHTML returns genres as HTML for HTMX
 func (ph *PlaylistHandlers) GetGenresHTML(c echo.Context) error {
-	genres := ph.engine.GetGenres()
+	genres := ph.engineFor(c).GetGenres()
 
 	if len(genres) == 0 {
 		return c.HTML(http.StatusOK, `<div class="text-gray-500 text-sm">No genres available</div>`)
 	}
 
-	var html strings.Builder
+	var fragment strings.Builder
 	for _, genre := range genres {
-		html.WriteString(fmt.Sprintf(`
+		fragment.WriteString(fmt.Sprintf(`
 		<button
 			hx-get="/api/explorer/genres/%s/subgenres-html"
 			hx-target="#subgenres-list"
 			class="block w-full text-left px-2 py-1 rounded hover:bg-gray-100 text-sm">
 			%s
-		</button>`, genre, genre))
+		</button>`, html.EscapeString(url.PathEscape(genre)), html.EscapeString(genre)))
 	}
 
-	return c.HTML(http.StatusOK, html.String())
+	return c.HTML(http.StatusOK, fragment.String())
 }
 
 // GetDashboardHTML returns dashboard stats as HTML for HTMX
 func (ph *PlaylistHandlers) GetDashboardHTML(c echo.Context) error {
-	snapshot := ph.engine.ExportSnapshot()
+	snapshot := ph.engineFor(c).ExportSnapshotAsync()
 
 	// Extract data from snapshot structure
 	playlistInfo := snapshot["playlist_info"].(map[string]interface{})
@@ -830,7 +4028,7 @@ func (ph *PlaylistHandlers) GetDashboardHTML(c echo.Context) error {
 	totalDuration := playlistInfo["total_duration"].(int)
 
 	// Get additional stats
-	stats := ph.engine.GetPlaylistStats()
+	stats := ph.engineFor(c).GetPlaylistStats()
 	uniqueArtists := stats["unique_artists"].(int)
 
 	// Get genre count from genre stats
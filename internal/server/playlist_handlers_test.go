@@ -4,11 +4,22 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"src/internal/datastructures"
+	"src/internal/integrations"
+	"src/internal/models"
+	"src/internal/services"
+	"src/internal/storage"
 
 	"github.com/labstack/echo/v4"
 )
@@ -19,7 +30,7 @@ func TestNewPlaylistHandlers(t *testing.T) {
 	if handlers == nil {
 		t.Fatal("Expected non-nil handlers")
 	}
-	if handlers.engine == nil {
+	if handlers.playlists.Active() == nil {
 		t.Error("Engine should be initialized")
 	}
 }
@@ -34,7 +45,7 @@ func TestGetPlaylist(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add a test song first
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist", nil)
 	rec := httptest.NewRecorder()
@@ -111,9 +122,15 @@ func TestAddSong(t *testing.T) {
 	}
 
 	// Verify song was actually added
-	if handlers.engine.GetPlaylistSize() != 1 {
+	if handlers.playlists.Active().GetPlaylistSize() != 1 {
 		t.Error("Song should have been added to engine")
 	}
+
+	// Response should carry the generated song ID
+	data, ok := response["data"].(map[string]interface{})
+	if !ok || data["id"] != handlers.playlists.Active().GetCurrentPlaylist()[0].ID {
+		t.Errorf("Expected response to include new song ID, got %v", response["data"])
+	}
 }
 
 func TestAddSongInvalidJSON(t *testing.T) {
@@ -163,7 +180,7 @@ func TestDeleteSong(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add a song first
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	req := httptest.NewRequest(http.MethodDelete, "/playlist/songs/0", nil)
 	rec := httptest.NewRecorder()
@@ -181,7 +198,7 @@ func TestDeleteSong(t *testing.T) {
 	}
 
 	// Verify song was deleted
-	if handlers.engine.GetPlaylistSize() != 0 {
+	if handlers.playlists.Active().GetPlaylistSize() != 0 {
 		t.Error("Song should have been deleted from engine")
 	}
 }
@@ -228,9 +245,9 @@ func TestMoveSong(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add multiple songs
-	handlers.engine.AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
-	handlers.engine.AddSong("Song 2", "Artist 2", "Album 2", "Pop", "Mainstream", "Happy", 200, 110)
-	handlers.engine.AddSong("Song 3", "Artist 3", "Album 3", "Jazz", "Smooth", "Relaxed", 300, 90)
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album 2", "Pop", "Mainstream", "Happy", 200, 110)
+	handlers.playlists.Active().AddSong("Song 3", "Artist 3", "Album 3", "Jazz", "Smooth", "Relaxed", 300, 90)
 
 	requestBody := map[string]interface{}{
 		"fromIndex": 0,
@@ -253,7 +270,7 @@ func TestMoveSong(t *testing.T) {
 	}
 
 	// Verify the move
-	songs := handlers.engine.GetCurrentPlaylist()
+	songs := handlers.playlists.Active().GetCurrentPlaylist()
 	if songs[2].Title != "Song 1" {
 		t.Error("Song should have been moved to new position")
 	}
@@ -281,10 +298,10 @@ func TestReversePlaylist(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add songs
-	handlers.engine.AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
-	handlers.engine.AddSong("Song 2", "Artist 2", "Album 2", "Pop", "Mainstream", "Happy", 200, 110)
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album 2", "Pop", "Mainstream", "Happy", 200, 110)
 
-	originalSongs := handlers.engine.GetCurrentPlaylist()
+	originalSongs := handlers.playlists.Active().GetCurrentPlaylist()
 	originalFirst := originalSongs[0].Title
 
 	req := httptest.NewRequest(http.MethodPut, "/playlist/reverse", nil)
@@ -301,7 +318,7 @@ func TestReversePlaylist(t *testing.T) {
 	}
 
 	// Verify reversal
-	reversedSongs := handlers.engine.GetCurrentPlaylist()
+	reversedSongs := handlers.playlists.Active().GetCurrentPlaylist()
 	if reversedSongs[0].Title == originalFirst {
 		t.Error("Playlist should have been reversed")
 	}
@@ -311,7 +328,7 @@ func TestPlaySong(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add a song
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	req := httptest.NewRequest(http.MethodPost, "/playlist/play/0", nil)
 	rec := httptest.NewRecorder()
@@ -329,7 +346,7 @@ func TestPlaySong(t *testing.T) {
 	}
 
 	// Verify song was played (check play count)
-	songs := handlers.engine.GetCurrentPlaylist()
+	songs := handlers.playlists.Active().GetCurrentPlaylist()
 	if songs[0].PlayCount != 1 {
 		t.Error("Song play count should have increased")
 	}
@@ -358,8 +375,8 @@ func TestUndoLastPlay(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add and play a song
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
-	handlers.engine.PlaySong(0)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().PlaySong(0)
 
 	req := httptest.NewRequest(http.MethodPost, "/playlist/undo", nil)
 	rec := httptest.NewRecorder()
@@ -396,8 +413,8 @@ func TestRateSong(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add a song
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
-	songs := handlers.engine.GetCurrentPlaylist()
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	songs := handlers.playlists.Active().GetCurrentPlaylist()
 	songID := songs[0].ID
 
 	requestBody := map[string]interface{}{
@@ -422,7 +439,7 @@ func TestRateSong(t *testing.T) {
 	}
 
 	// Verify rating was set
-	ratedSongs := handlers.engine.GetSongsByRating(4)
+	ratedSongs := handlers.playlists.Active().GetSongsByRating(4)
 	if len(ratedSongs) != 1 {
 		t.Error("Song should have been rated")
 	}
@@ -457,7 +474,7 @@ func TestSearchSong(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add a song
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	// Test search by title
 	req := httptest.NewRequest(http.MethodGet, "/playlist/search?type=title&q=Test+Song", nil)
@@ -504,6 +521,28 @@ func TestSearchSong(t *testing.T) {
 	}
 }
 
+func TestSearchSong_SharedTitle(t *testing.T) {
+	e, handlers := setupTestEcho()
+	handlers.playlists.Active().AddSong("Hurt", "Nine Inch Nails", "Album", "Rock", "Industrial", "Dark", 373, 90)
+	handlers.playlists.Active().AddSong("Hurt", "Johnny Cash", "Album", "Country", "Folk", "Sad", 218, 94)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/search?type=title&q=hurt", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	handlers.SearchSong(c)
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	songs := data["songs"].([]interface{})
+	if len(songs) != 2 || data["count"] != float64(2) {
+		t.Fatalf("Expected both songs titled Hurt, got %v", data)
+	}
+	if data["song"].(map[string]interface{})["artist"] != "Nine Inch Nails" {
+		t.Errorf("Expected song to be the first added match, got %v", data["song"])
+	}
+}
+
 func TestSearchSongInvalidType(t *testing.T) {
 	e, handlers := setupTestEcho()
 
@@ -521,6 +560,31 @@ func TestSearchSongInvalidType(t *testing.T) {
 	}
 }
 
+func TestSearchSongFuzzy(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Hotel California", "Eagles", "Album", "Rock", "Classic Rock", "Nostalgic", 391, 75)
+	handlers.playlists.Active().AddSong("Imagine", "John Lennon", "Album", "Pop", "Soft Rock", "Peaceful", 183, 76)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/search?type=fuzzy&q=hotel+califronia", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.SearchSong(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	songs := response["data"].(map[string]interface{})["songs"].([]interface{})
+	if len(songs) != 1 || songs[0].(map[string]interface{})["title"] != "Hotel California" {
+		t.Errorf("Expected Hotel California, got %v", songs)
+	}
+}
+
 func TestSearchSongNotFound(t *testing.T) {
 	e, handlers := setupTestEcho()
 
@@ -542,9 +606,9 @@ func TestGetSongsByRating(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add and rate a song
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
-	songs := handlers.engine.GetCurrentPlaylist()
-	handlers.engine.RateSong(songs[0].ID, 4)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	songs := handlers.playlists.Active().GetCurrentPlaylist()
+	handlers.playlists.Active().RateSong(songs[0].ID, 4)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/rating/4", nil)
 	rec := httptest.NewRecorder()
@@ -605,8 +669,8 @@ func TestSortPlaylist(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add songs in unsorted order
-	handlers.engine.AddSong("Zebra", "Artist Z", "Album Z", "Rock", "Alternative", "Energetic", 300, 120)
-	handlers.engine.AddSong("Alpha", "Artist A", "Album A", "Pop", "Mainstream", "Happy", 200, 110)
+	handlers.playlists.Active().AddSong("Zebra", "Artist Z", "Album Z", "Rock", "Alternative", "Energetic", 300, 120)
+	handlers.playlists.Active().AddSong("Alpha", "Artist A", "Album A", "Pop", "Mainstream", "Happy", 200, 110)
 
 	requestBody := map[string]interface{}{
 		"criteria":  "title",
@@ -629,12 +693,55 @@ func TestSortPlaylist(t *testing.T) {
 	}
 
 	// Verify sorting
-	songs := handlers.engine.GetCurrentPlaylist()
+	songs := handlers.playlists.Active().GetCurrentPlaylist()
 	if songs[0].Title != "Alpha" {
 		t.Error("Playlist should be sorted by title")
 	}
 }
 
+func TestSortPlaylistUnknownAlgorithm(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Zebra", "Artist Z", "Album Z", "Rock", "Alternative", "Energetic", 300, 120)
+	handlers.playlists.Active().AddSong("Alpha", "Artist A", "Album A", "Pop", "Mainstream", "Happy", 200, 110)
+
+	sortWith := func(algorithm string) map[string]interface{} {
+		jsonData, _ := json.Marshal(map[string]interface{}{"criteria": "title", "algorithm": algorithm})
+		req := httptest.NewRequest(http.MethodPost, "/playlist/sort", bytes.NewBuffer(jsonData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if err := handlers.SortPlaylist(c); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status 200 for algorithm %q, got %d", algorithm, rec.Code)
+		}
+
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return response
+	}
+
+	response := sortWith("bubble")
+	if response["algorithm"] != "merge" {
+		t.Errorf("Expected fallback to merge, got %v", response["algorithm"])
+	}
+	if _, ok := response["warning"]; !ok {
+		t.Error("Expected a warning when falling back to merge sort")
+	}
+	if songs := handlers.playlists.Active().GetCurrentPlaylist(); songs[0].Title != "Alpha" {
+		t.Error("Playlist should still be sorted after fallback")
+	}
+
+	// Known algorithms and the empty default don't warn
+	for _, algorithm := range []string{"quick", ""} {
+		if response := sortWith(algorithm); response["warning"] != nil {
+			t.Errorf("Expected no warning for algorithm %q, got %v", algorithm, response["warning"])
+		}
+	}
+}
+
 func TestSortPlaylistInvalidCriteria(t *testing.T) {
 	e, handlers := setupTestEcho()
 
@@ -663,10 +770,10 @@ func TestGetPlaybackHistory(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add and play songs
-	handlers.engine.AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
-	handlers.engine.AddSong("Song 2", "Artist 2", "Album 2", "Pop", "Mainstream", "Happy", 200, 110)
-	handlers.engine.PlaySong(0)
-	handlers.engine.PlaySong(1)
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album 2", "Pop", "Mainstream", "Happy", 200, 110)
+	handlers.playlists.Active().PlaySong(0)
+	handlers.playlists.Active().PlaySong(1)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/history?count=2", nil)
 	rec := httptest.NewRecorder()
@@ -706,8 +813,8 @@ func TestGetGenres(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add songs with different genres
-	handlers.engine.AddSong("Rock Song", "Rock Artist", "Album", "Rock", "Alternative", "Energetic", 240, 120)
-	handlers.engine.AddSong("Pop Song", "Pop Artist", "Album", "Pop", "Mainstream", "Happy", 200, 110)
+	handlers.playlists.Active().AddSong("Rock Song", "Rock Artist", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Pop Song", "Pop Artist", "Album", "Pop", "Mainstream", "Happy", 200, 110)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/genres", nil)
 	rec := httptest.NewRecorder()
@@ -747,8 +854,8 @@ func TestGetSubgenres(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add songs with subgenres
-	handlers.engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
-	handlers.engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Classic Rock", "Epic", 280, 115)
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Classic Rock", "Epic", 280, 115)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/subgenres?genre=Rock", nil)
 	rec := httptest.NewRecorder()
@@ -788,8 +895,8 @@ func TestGetMoods(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add songs with moods
-	handlers.engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
-	handlers.engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Melancholic", 250, 100)
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Melancholic", 250, 100)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/moods?genre=Rock&subgenre=Alternative", nil)
 	rec := httptest.NewRecorder()
@@ -829,8 +936,8 @@ func TestGetArtists(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add songs with same path but different artists
-	handlers.engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
-	handlers.engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 250, 125)
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 250, 125)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/artists?genre=Rock&subgenre=Alternative&mood=Energetic", nil)
 	rec := httptest.NewRecorder()
@@ -870,7 +977,7 @@ func TestGetSongsByExplorer(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add a song
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/explorer?genre=Rock&subgenre=Alternative&mood=Energetic&artist=Test Artist", nil)
 	rec := httptest.NewRecorder()
@@ -910,9 +1017,9 @@ func TestGetRecommendations(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add songs and play some
-	handlers.engine.AddSong("Rock Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
-	handlers.engine.AddSong("Rock Song 2", "Artist 2", "Album 2", "Rock", "Alternative", "Energetic", 250, 125)
-	handlers.engine.PlaySong(0)
+	handlers.playlists.Active().AddSong("Rock Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Rock Song 2", "Artist 2", "Album 2", "Rock", "Alternative", "Energetic", 250, 125)
+	handlers.playlists.Active().PlaySong(0)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/recommendations?count=3", nil)
 	rec := httptest.NewRecorder()
@@ -953,7 +1060,7 @@ func TestGetDashboard(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add some data for dashboard
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/dashboard", nil)
 	rec := httptest.NewRecorder()
@@ -985,7 +1092,7 @@ func TestGetStats(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add some data for stats
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/stats", nil)
 	rec := httptest.NewRecorder()
@@ -1017,7 +1124,7 @@ func TestBenchmarkSort(t *testing.T) {
 
 	// Add songs for benchmarking
 	for i := 0; i < 5; i++ {
-		handlers.engine.AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Genre", "Subgenre", "Mood", 200+i*10, 120)
+		handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Genre", "Subgenre", "Mood", 200+i*10, 120)
 	}
 
 	req := httptest.NewRequest(http.MethodGet, "/playlist/benchmark", nil)
@@ -1059,10 +1166,10 @@ func TestClearPlaylist(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add a song
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	// Verify song exists
-	if handlers.engine.GetPlaylistSize() != 1 {
+	if handlers.playlists.Active().GetPlaylistSize() != 1 {
 		t.Fatal("Song should exist before clear")
 	}
 
@@ -1080,7 +1187,7 @@ func TestClearPlaylist(t *testing.T) {
 	}
 
 	// Verify playlist is cleared
-	if handlers.engine.GetPlaylistSize() != 0 {
+	if handlers.playlists.Active().GetPlaylistSize() != 0 {
 		t.Error("Playlist should be empty after clear")
 	}
 }
@@ -1108,7 +1215,7 @@ func TestSetPlaylistName(t *testing.T) {
 	}
 
 	// Verify name was changed
-	if handlers.engine.GetPlaylistName() != "New Playlist Name" {
+	if handlers.playlists.Active().GetPlaylistName() != "New Playlist Name" {
 		t.Error("Playlist name should have been updated")
 	}
 }
@@ -1171,7 +1278,7 @@ func TestLoadSampleData(t *testing.T) {
 	}
 
 	// Verify sample data was loaded
-	if handlers.engine.GetPlaylistSize() == 0 {
+	if handlers.playlists.Active().GetPlaylistSize() == 0 {
 		t.Error("Sample data should have been loaded")
 	}
 }
@@ -1180,7 +1287,7 @@ func TestGetPlaylistHTML(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add a song
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
@@ -1212,7 +1319,7 @@ func TestGetGenresHTML(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add songs with genres
-	handlers.engine.AddSong("Rock Song", "Rock Artist", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Rock Song", "Rock Artist", "Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	req := httptest.NewRequest(http.MethodGet, "/genres", nil)
 	rec := httptest.NewRecorder()
@@ -1238,7 +1345,7 @@ func TestGetDashboardHTML(t *testing.T) {
 	e, handlers := setupTestEcho()
 
 	// Add some data for dashboard
-	handlers.engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 
 	req := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
 	rec := httptest.NewRecorder()
@@ -1286,7 +1393,7 @@ func TestHandlersIntegration(t *testing.T) {
 	}
 
 	// 2. Verify playlist size
-	if handlers.engine.GetPlaylistSize() != 3 {
+	if handlers.playlists.Active().GetPlaylistSize() != 3 {
 		t.Error("Should have 3 songs in playlist")
 	}
 
@@ -1303,7 +1410,7 @@ func TestHandlersIntegration(t *testing.T) {
 	}
 
 	// 4. Rate a song
-	playlistSongs := handlers.engine.GetCurrentPlaylist()
+	playlistSongs := handlers.playlists.Active().GetCurrentPlaylist()
 	songID := playlistSongs[0].ID
 	ratingData := map[string]interface{}{"rating": 5}
 	jsonData, _ := json.Marshal(ratingData)
@@ -1361,3 +1468,3270 @@ func TestHandlersIntegration(t *testing.T) {
 func intToString(i int) string {
 	return strconv.Itoa(i)
 }
+
+func TestAddSongFieldTooLong(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	requestBody := map[string]interface{}{
+		"title":    strings.Repeat("a", 257),
+		"artist":   "Test Artist",
+		"duration": 240,
+	}
+
+	jsonData, _ := json.Marshal(requestBody)
+	req := httptest.NewRequest(http.MethodPost, "/playlist/songs", bytes.NewBuffer(jsonData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handlers.AddSong(c)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+
+	if handlers.playlists.Active().GetPlaylistSize() != 0 {
+		t.Error("Over-length song should not have been added")
+	}
+
+	// Surrounding whitespace is trimmed before the length is checked, as the engine does
+	requestBody["title"] = "  " + strings.Repeat("a", services.MaxSongFieldLength) + "\n"
+	jsonData, _ = json.Marshal(requestBody)
+	req = httptest.NewRequest(http.MethodPost, "/playlist/songs", bytes.NewBuffer(jsonData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	if err := handlers.AddSong(e.NewContext(req, rec)); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status 201 for a title at the limit once trimmed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetPlaylistHTMLEscapesSongFields(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("<script>alert('x')</script>", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handlers.GetPlaylistHTML(c)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	body := rec.Body.String()
+	if strings.Contains(body, "<script>") {
+		t.Error("Song title should be HTML-escaped in playlist HTML")
+	}
+	if !strings.Contains(body, "&lt;script&gt;") {
+		t.Error("Escaped song title should be present in playlist HTML")
+	}
+}
+
+func TestHTMLFragmentsEscapeUserData(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong(`Say "Hi" <b>`, `O'Brien & <i>Co</i>`, `"Album"`, `Rock"><img src=x>`, "Alt'ernative", "<Mood>", 240, 120)
+
+	// Playlist fragment
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.GetPlaylistHTML(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	body := rec.Body.String()
+	for _, raw := range []string{`"Hi"`, "<b>", "<i>", `"><img`, "<Mood>", "O'Brien"} {
+		if strings.Contains(body, raw) {
+			t.Errorf("Playlist HTML should not contain unescaped %q", raw)
+		}
+	}
+	if !strings.Contains(body, "Say &#34;Hi&#34; &lt;b&gt;") {
+		t.Error("Playlist HTML should contain escaped title")
+	}
+
+	// Genres fragment
+	req = httptest.NewRequest(http.MethodGet, "/genres", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if err := handlers.GetGenresHTML(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	body = rec.Body.String()
+	if strings.Contains(body, "<img") || strings.Contains(body, `"><`) {
+		t.Error("Genres HTML should not contain unescaped genre markup")
+	}
+	if !strings.Contains(body, "/api/explorer/genres/Rock%22%3E%3CImg%20Src=X%3E/subgenres-html") {
+		t.Errorf("Genre should be URL-escaped in hx-get path, got %s", body)
+	}
+}
+
+func TestGetPlaybackHistoryPaged(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	for i := 0; i < 5; i++ {
+		handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		handlers.playlists.Active().PlaySong(i)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/history?offset=3&count=10", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetPlaybackHistory(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+
+	if data["count"].(float64) != 2 {
+		t.Errorf("Expected 2 songs in page, got %v", data["count"])
+	}
+	if data["total"].(float64) != 5 {
+		t.Errorf("Expected total history size 5, got %v", data["total"])
+	}
+
+	history := data["history"].([]interface{})
+	first := history[0].(map[string]interface{})
+	if first["title"] != "Song 1" {
+		t.Errorf("Expected 'Song 1' at offset 3, got %v", first["title"])
+	}
+
+	// Out-of-range offset returns an empty page
+	req = httptest.NewRequest(http.MethodGet, "/playlist/history?offset=50", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetPlaybackHistory(c)
+
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data = response["data"].(map[string]interface{})
+	if data["count"].(float64) != 0 {
+		t.Errorf("Expected empty page for out-of-range offset, got %v", data["count"])
+	}
+}
+
+func TestGetTodayStats(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().PlaySong(0)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/stats/today", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetTodayStats(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+
+	if data["songs_played"].(float64) != 1 {
+		t.Errorf("Expected 1 song played today, got %v", data["songs_played"])
+	}
+	if data["total_listen_time"].(float64) != 240 {
+		t.Errorf("Expected 240 seconds listened, got %v", data["total_listen_time"])
+	}
+}
+
+func TestSetRecommendationMode(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	jsonData, _ := json.Marshal(map[string]interface{}{"mode": "strict"})
+	req := httptest.NewRequest(http.MethodPut, "/playlist/recommendations/mode", bytes.NewBuffer(jsonData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.SetRecommendationMode(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+	if handlers.playlists.Active().GetSimilarityMode().String() != "strict" {
+		t.Error("Engine similarity mode should be strict")
+	}
+
+	// Unknown mode is rejected
+	jsonData, _ = json.Marshal(map[string]interface{}{"mode": "fuzzy"})
+	req = httptest.NewRequest(http.MethodPut, "/playlist/recommendations/mode", bytes.NewBuffer(jsonData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.SetRecommendationMode(c)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestBenchmarkRecommendations(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().PlaySong(0)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/recommendations/benchmark?iterations=5", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.BenchmarkRecommendations(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["iterations"].(float64) != 5 || data["songs"].(float64) != 2 || data["matches"].(float64) != 1 {
+		t.Errorf("Unexpected benchmark result %v", data)
+	}
+	for _, key := range []string{"scan_ns", "indexed_ns", "speedup"} {
+		if _, ok := data[key]; !ok {
+			t.Errorf("Expected %s in the benchmark result", key)
+		}
+	}
+
+	for _, iterations := range []string{"0", "abc", "10001"} {
+		req = httptest.NewRequest(http.MethodGet, "/playlist/recommendations/benchmark?iterations="+iterations, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		handlers.BenchmarkRecommendations(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for iterations=%s, got %d", iterations, rec.Code)
+		}
+	}
+}
+
+func TestGetPlaylistIncludesTotals(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Pop", "Mainstream", "Happy", 200, 110)
+	handlers.playlists.Active().PlaySong(0)
+	handlers.playlists.Active().PlaySong(0)
+	handlers.playlists.Active().PlaySong(1)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetPlaylist(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+
+	if data["total_play_count"].(float64) != 3 {
+		t.Errorf("Expected total play count 3, got %v", data["total_play_count"])
+	}
+	if data["total_duration"].(float64) != 440 {
+		t.Errorf("Expected total duration 440, got %v", data["total_duration"])
+	}
+	if len(data["songs"].([]interface{})) != 2 {
+		t.Error("Songs array should be unchanged")
+	}
+}
+
+func TestDeleteSongByID(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	songID := handlers.playlists.Active().GetCurrentPlaylist()[0].ID
+
+	req := httptest.NewRequest(http.MethodDelete, "/playlist/songs/by-id/"+songID, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(songID)
+
+	if err := handlers.DeleteSongByID(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+	if handlers.playlists.Active().GetPlaylistSize() != 0 {
+		t.Error("Song should have been deleted from engine")
+	}
+
+	// Unknown ID returns 404
+	req = httptest.NewRequest(http.MethodDelete, "/playlist/songs/by-id/missing", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues("missing")
+	handlers.DeleteSongByID(c)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestLoadSampleDataFiltered(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	req := httptest.NewRequest(http.MethodPost, "/playlist/sample-data?genre=jazz,Polka&limit=5", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.LoadSampleData(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	songs := handlers.playlists.Active().GetCurrentPlaylist()
+	if len(songs) != 5 {
+		t.Fatalf("Expected 5 songs, got %d", len(songs))
+	}
+	for _, song := range songs {
+		if song.Genre != "Jazz" {
+			t.Errorf("Expected only Jazz songs, got %s", song.Genre)
+		}
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	ignored, ok := data["ignored_genres"].([]interface{})
+	if !ok || len(ignored) != 1 || ignored[0] != "Polka" {
+		t.Errorf("Expected Polka to be reported as ignored, got %v", data["ignored_genres"])
+	}
+
+	// Invalid limit is rejected
+	req = httptest.NewRequest(http.MethodPost, "/playlist/sample-data?limit=abc", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.LoadSampleData(c)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetGenreStatistics(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Rock Song", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Jazz Song", "Artist 2", "Album", "Jazz", "Smooth Jazz", "Relaxed", 300, 90)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/stats/by-genre", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetGenreStatistics(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	rock, ok := data["Rock"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected Rock genre statistics")
+	}
+	if rock["song_count"].(float64) != 1 || rock["total_duration"].(float64) != 240 {
+		t.Errorf("Unexpected Rock statistics: %v", rock)
+	}
+}
+
+func TestUnrateSong(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	songID, _ := handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().RateSong(songID, 5)
+
+	req := httptest.NewRequest(http.MethodDelete, "/playlist/songs/"+songID+"/rating", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(songID)
+
+	if err := handlers.UnrateSong(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+	if len(handlers.playlists.Active().GetUnratedSongs()) != 1 {
+		t.Error("Song should be unrated")
+	}
+
+	// Already unrated song is a no-op
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(songID)
+	handlers.UnrateSong(c)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for unrated song, got %d", rec.Code)
+	}
+
+	// Unknown song returns 404
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues("missing")
+	handlers.UnrateSong(c)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestGetAllArtists(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Song 1", "Charlie", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Alpha", "Album", "Pop", "Mainstream", "Happy", 200, 120)
+	handlers.playlists.Active().AddSong("Song 3", "Bravo", "Album", "Jazz", "Smooth", "Relaxed", 200, 120)
+
+	req := httptest.NewRequest(http.MethodGet, "/artists?sort=-name&offset=1&limit=1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetAllArtists(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	artists := data["artists"].([]interface{})
+	if len(artists) != 1 || artists[0] != "Bravo" {
+		t.Errorf("Expected [Bravo], got %v", artists)
+	}
+	if data["total"].(float64) != 3 {
+		t.Errorf("Expected total 3, got %v", data["total"])
+	}
+
+	// Offset past the end returns an empty page
+	req = httptest.NewRequest(http.MethodGet, "/artists?offset=10", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetAllArtists(c)
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if len(response["data"].(map[string]interface{})["artists"].([]interface{})) != 0 {
+		t.Error("Expected empty page for offset past the end")
+	}
+
+	// Unknown sort is rejected
+	req = httptest.NewRequest(http.MethodGet, "/artists?sort=plays", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetAllArtists(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetRecentlyAdded(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("First", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Second", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	songs := handlers.playlists.Active().GetCurrentPlaylist()
+	songs[1].AddedAt = songs[0].AddedAt.Add(time.Minute)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/recent?count=1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetRecentlyAdded(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	recent := response["data"].(map[string]interface{})["songs"].([]interface{})
+	if len(recent) != 1 || recent[0].(map[string]interface{})["title"] != "Second" {
+		t.Errorf("Expected most recently added song Second, got %v", recent)
+	}
+}
+
+func TestImportM3U(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	body := "#EXTM3U\n#EXTINF:240,Test Artist - Test Song\ntest.mp3\nnot-an-entry.mp3\n"
+	req := httptest.NewRequest(http.MethodPost, "/playlist/import/m3u", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.ImportM3U(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["added"].(float64) != 1 {
+		t.Errorf("Expected 1 song added, got %v", data["added"])
+	}
+	if len(data["errors"].([]interface{})) != 1 {
+		t.Errorf("Expected 1 import error, got %v", data["errors"])
+	}
+	if handlers.playlists.Active().GetPlaylistSize() != 1 {
+		t.Error("Song should have been added to engine")
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	body := "title,artist,genre,duration\nTest Song,Test Artist,Rock,240\n,No Title,Rock,100\n"
+	req := httptest.NewRequest(http.MethodPost, "/playlist/import/csv", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.ImportCSV(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["added"].(float64) != 1 || data["skipped"].(float64) != 1 {
+		t.Errorf("Expected 1 added and 1 skipped, got %v", data)
+	}
+	if len(data["errors"].([]interface{})) != 1 {
+		t.Errorf("Expected 1 import error, got %v", data["errors"])
+	}
+}
+
+func TestImportCSVUpload(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "library.csv")
+	part.Write([]byte("title,artist,album,genre,subgenre,mood,duration,bpm,rating\n" +
+		"Song 1,Artist,Album,Rock,Alternative,Energetic,200,120,5\n" +
+		"Song 2,Artist,Album,Rock,Alternative,Energetic,long,120,3\n"))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/playlist/import", &body)
+	req.Header.Set(echo.HeaderContentType, form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["added"].(float64) != 1 || data["skipped"].(float64) != 1 {
+		t.Errorf("Expected 1 added and 1 skipped, got %v", data)
+	}
+	if errs := data["errors"].([]interface{}); len(errs) != 1 || !strings.HasPrefix(errs[0].(string), "line 3:") {
+		t.Errorf("Expected a duration error on line 3, got %v", errs)
+	}
+	if songs := handlers.playlists.Active().GetSongsByRating(5); len(songs) != 1 {
+		t.Errorf("Expected the imported song rated 5, got %v", songs)
+	}
+}
+
+func TestImportCSVStream(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	var sb strings.Builder
+	sb.WriteString("title,artist\n")
+	for i := 0; i < services.CSVImportProgressInterval+1; i++ {
+		fmt.Fprintf(&sb, "Song %d,Artist\n", i)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/playlist/import/csv?stream=true", strings.NewReader(sb.String()))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.ImportCSV(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if contentType := rec.Header().Get(echo.HeaderContentType); contentType != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %s", contentType)
+	}
+
+	stream := rec.Body.String()
+	if progress := strings.Count(stream, "event: progress\n"); progress != 2 {
+		t.Errorf("Expected 2 progress events, got %d:\n%s", progress, stream)
+	}
+	if !strings.Contains(stream, "event: done\n") || !strings.HasSuffix(stream, "\n\n") {
+		t.Errorf("Expected stream to end with a done event:\n%s", stream)
+	}
+	if handlers.playlists.Active().GetPlaylistSize() != services.CSVImportProgressInterval+1 {
+		t.Errorf("Expected every row imported, got %d songs", handlers.playlists.Active().GetPlaylistSize())
+	}
+}
+
+func TestGetSessions(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	// Empty history returns no sessions
+	req := httptest.NewRequest(http.MethodGet, "/playlist/sessions", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetSessions(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if response["data"].(map[string]interface{})["count"].(float64) != 0 {
+		t.Error("Expected no sessions for empty history")
+	}
+
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 100, 120)
+	handlers.playlists.Active().PlaySong(0)
+	handlers.playlists.Active().PlaySong(1)
+
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetSessions(c)
+
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	sessions := response["data"].(map[string]interface{})["sessions"].([]interface{})
+	if len(sessions) != 1 {
+		t.Fatalf("Expected 1 session, got %d", len(sessions))
+	}
+	session := sessions[0].(map[string]interface{})
+	if session["song_count"].(float64) != 2 || session["total_duration"].(float64) != 300 {
+		t.Errorf("Unexpected session summary: %v", session)
+	}
+
+	// Invalid gap is rejected
+	req = httptest.NewRequest(http.MethodGet, "/playlist/sessions?gap=soon", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetSessions(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestGetSongsByRatings(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	id1, _ := handlers.playlists.Active().AddSong("Song 1", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	id2, _ := handlers.playlists.Active().AddSong("Song 2", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().RateSong(id1, 4)
+	handlers.playlists.Active().RateSong(id2, 2)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/ratings?values=4,3,4", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetSongsByRatings(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["count"].(float64) != 1 {
+		t.Errorf("Expected 1 song, got %v", data["count"])
+	}
+	if len(data["ratings"].([]interface{})) != 2 {
+		t.Errorf("Expected deduplicated ratings, got %v", data["ratings"])
+	}
+
+	for _, values := range []string{"", "3,6", "abc"} {
+		req = httptest.NewRequest(http.MethodGet, "/playlist/ratings?values="+values, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		handlers.GetSongsByRatings(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for values=%q, got %d", values, rec.Code)
+		}
+	}
+}
+
+func TestGetSongDetail(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	songID, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 210, 125)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/songs/"+songID+"/detail", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(songID)
+
+	if err := handlers.GetSongDetail(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["song"].(map[string]interface{})["id"] != songID {
+		t.Error("Expected detail to contain the requested song")
+	}
+	if len(data["path"].([]interface{})) != 4 {
+		t.Errorf("Expected 4-level explorer path, got %v", data["path"])
+	}
+	if len(data["similar"].([]interface{})) != 1 {
+		t.Errorf("Expected 1 similar song, got %v", data["similar"])
+	}
+
+	// Unknown song returns 404
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues("missing")
+	handlers.GetSongDetail(c)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestGetRecommendationsWindow(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().PlaySong(0)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/recommendations?window=5", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetRecommendations(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if response["data"].(map[string]interface{})["window"].(float64) != 5 {
+		t.Errorf("Expected window 5 in response, got %v", response["data"])
+	}
+
+	for _, window := range []string{"0", "-3", "abc"} {
+		req = httptest.NewRequest(http.MethodGet, "/playlist/recommendations?window="+window, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		handlers.GetRecommendations(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for window=%s, got %d", window, rec.Code)
+		}
+	}
+}
+
+func TestImportPlays(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	songID, _ := handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+
+	body := fmt.Sprintf(`[{"song_id": %q, "played_at": "2024-01-02T15:04:05Z"}, {"song_id": "missing", "played_at": "2024-01-02T16:04:05Z"}]`, songID)
+	req := httptest.NewRequest(http.MethodPost, "/playlist/plays/import", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.ImportPlays(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["recorded"].(float64) != 1 || len(data["errors"].([]interface{})) != 1 {
+		t.Errorf("Expected 1 recorded play and 1 error, got %v", data)
+	}
+	if handlers.playlists.Active().GetHistorySize() != 1 {
+		t.Errorf("Expected 1 history entry, got %d", handlers.playlists.Active().GetHistorySize())
+	}
+}
+
+func TestGetSongNeighbors(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id, _ := handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		ids = append(ids, id)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/songs/"+ids[1]+"/neighbors?radius=2", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(ids[1])
+
+	if err := handlers.GetSongNeighbors(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if songs := data["songs"].([]interface{}); len(songs) != 4 {
+		t.Errorf("Expected 4 songs clamped at the head, got %d", len(songs))
+	}
+	if data["center"].(float64) != 1 {
+		t.Errorf("Expected center 1, got %v", data["center"])
+	}
+
+	// Invalid radius returns 400
+	req = httptest.NewRequest(http.MethodGet, "/playlist/songs/"+ids[1]+"/neighbors?radius=-1", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(ids[1])
+	handlers.GetSongNeighbors(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+
+	// Unknown song returns 404
+	req = httptest.NewRequest(http.MethodGet, "/playlist/songs/missing/neighbors", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues("missing")
+	handlers.GetSongNeighbors(c)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestSetSongNote(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	songID, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	req := httptest.NewRequest(http.MethodPut, "/playlist/songs/"+songID+"/note", strings.NewReader(`{"note":"<b>great</b> for intros"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(songID)
+
+	if err := handlers.SetSongNote(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if response["data"].(map[string]interface{})["notes"] != "<b>great</b> for intros" {
+		t.Errorf("Expected note in song response, got %v", response["data"])
+	}
+
+	// Note is escaped in the playlist fragment
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetPlaylistHTML(c)
+	body := rec.Body.String()
+	if strings.Contains(body, "<b>great</b>") || !strings.Contains(body, "&lt;b&gt;great&lt;/b&gt; for intros") {
+		t.Error("Song note should be HTML-escaped in playlist HTML")
+	}
+
+	// Over-long note returns 400
+	longNote := strings.Repeat("a", models.MaxNoteLength+1)
+	req = httptest.NewRequest(http.MethodPut, "/playlist/songs/"+songID+"/note", strings.NewReader(`{"note":"`+longNote+`"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(songID)
+	handlers.SetSongNote(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+
+	// Unknown song returns 404
+	req = httptest.NewRequest(http.MethodPut, "/playlist/songs/missing/note", strings.NewReader(`{"note":"hi"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues("missing")
+	handlers.SetSongNote(c)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestGetBPMHistogram(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Rock Song", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 125)
+	handlers.playlists.Active().AddSong("Jazz Song", "Artist 2", "Album", "Jazz", "Smooth Jazz", "Relaxed", 300, 0)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/stats/bpm?bucket=50", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetBPMHistogram(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	histogram := response["data"].(map[string]interface{})["histogram"].(map[string]interface{})
+	if histogram["100-150"].(float64) != 1 || histogram["unknown"].(float64) != 1 {
+		t.Errorf("Unexpected histogram: %v", histogram)
+	}
+
+	for _, bucket := range []string{"0", "-5", "abc"} {
+		req = httptest.NewRequest(http.MethodGet, "/playlist/stats/bpm?bucket="+bucket, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		handlers.GetBPMHistogram(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for bucket=%s, got %d", bucket, rec.Code)
+		}
+	}
+}
+
+func TestGetDurationHistogram(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Long Song", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 610, 125)
+	handlers.playlists.Active().AddSong("Short Song", "Artist 2", "Album", "Jazz", "Smooth Jazz", "Relaxed", 90, 100)
+	handlers.playlists.Active().AddSong("Mid Song", "Artist 3", "Album", "Pop", "Dance", "Happy", 200, 120)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/stats/duration?bucket=60", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetDurationHistogram(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	labels := data["labels"].([]interface{})
+	want := []string{"60-120", "180-240", "600-660"}
+	if len(labels) != len(want) {
+		t.Fatalf("Expected labels %v, got %v", want, labels)
+	}
+	for i, label := range labels {
+		if label != want[i] {
+			t.Errorf("Expected label %d to be %s, got %v", i, want[i], label)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/playlist/stats/duration?bucket=0", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetDurationHistogram(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestExportImportTemplate(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	songID, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().RateSong(songID, 4)
+	handlers.playlists.Active().PlaySong(0)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/export/template", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.ExportTemplate(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+	template := rec.Body.String()
+	if strings.Contains(template, "play_count") || strings.Contains(template, "rating") {
+		t.Errorf("Template should not contain personal stats: %s", template)
+	}
+
+	// Import the template into a fresh playlist
+	_, importHandlers := setupTestEcho()
+	req = httptest.NewRequest(http.MethodPost, "/playlist/import/template", strings.NewReader(template))
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if err := importHandlers.ImportTemplate(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if response["data"].(map[string]interface{})["added"].(float64) != 1 {
+		t.Errorf("Expected 1 song imported, got %v", response["data"])
+	}
+
+	songs := importHandlers.playlists.Active().GetCurrentPlaylist()
+	if len(songs) != 1 || songs[0].PlayCount != 0 || songs[0].Rating != 0 {
+		t.Errorf("Expected one fresh imported song, got %v", songs)
+	}
+}
+
+func TestAddSongIdempotencyKey(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	body := `{"title":"Song 1","artist":"Artist 1","duration":200}`
+	var ids []interface{}
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/playlist/songs", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Idempotency-Key", "abc-123")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := handlers.AddSong(c); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d", rec.Code)
+		}
+		if replayed := rec.Header().Get("Idempotent-Replayed") == "true"; replayed != (i == 1) {
+			t.Errorf("Request %d: unexpected Idempotent-Replayed header %q", i, rec.Header().Get("Idempotent-Replayed"))
+		}
+
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		ids = append(ids, response["data"].(map[string]interface{})["id"])
+	}
+
+	if handlers.playlists.Active().GetPlaylistSize() != 1 {
+		t.Errorf("Expected only one song added, got %d", handlers.playlists.Active().GetPlaylistSize())
+	}
+	if ids[0] != ids[1] {
+		t.Errorf("Expected repeated request to return the original song ID, got %v and %v", ids[0], ids[1])
+	}
+}
+
+func TestAddSongIdempotencyKeyPerUser(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+
+	request := func(path, userID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Idempotency-Key", "abc-123")
+		if userID != "" {
+			req.Header.Set(UserIDHeader, userID)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request("/api/users", "", `{"name": "Bob"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected user bob to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := request("/api/playlist/songs", "", `{"title":"Song A","artist":"Artist","duration":200}`); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Bob reusing the default user's key gets his own song added
+	rec := request("/api/playlist/songs", "bob", `{"title":"Song B","artist":"Artist","duration":200}`)
+	if rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("Expected bob's request to run, got %d with Idempotent-Replayed %q", rec.Code, rec.Header().Get("Idempotent-Replayed"))
+	}
+	bob, _ := handlers.users.Playlists("bob")
+	if songs := bob.Active().GetCurrentPlaylist(); len(songs) != 1 || songs[0].Title != "Song B" {
+		t.Errorf("Expected Song B in bob's playlist, got %v", songs)
+	}
+	if size := handlers.playlists.Active().GetPlaylistSize(); size != 1 {
+		t.Errorf("Expected the default playlist to keep 1 song, got %d", size)
+	}
+
+	// The same user repeating the key is still replayed
+	if rec := request("/api/playlist/songs", "bob", `{"title":"Song B","artist":"Artist","duration":200}`); rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Expected bob's repeated request to be replayed, got %d", rec.Code)
+	}
+}
+
+func TestBulkTagSongs(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	jazzID, _ := handlers.playlists.Active().AddSong("Jazz Song", "Artist 1", "Album", "Jazz", "Smooth Jazz", "Relaxed", 300, 90)
+	handlers.playlists.Active().AddSong("Jazz Song 2", "Artist 2", "Album", "Jazz", "Smooth Jazz", "Relaxed", 300, 90)
+	rockID, _ := handlers.playlists.Active().AddSong("Rock Song", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().RateSong(jazzID, 5)
+	handlers.playlists.Active().RateSong(rockID, 5)
+
+	body := `{"tag":"Favorites-Jazz","filter":{"genre":"jazz","min_rating":5}}`
+	req := httptest.NewRequest(http.MethodPost, "/playlist/tag/bulk", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.BulkTagSongs(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if response["data"].(map[string]interface{})["tagged"].(float64) != 1 {
+		t.Errorf("Expected 1 song tagged, got %v", response["data"])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/playlist/tags/favorites-jazz", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("tag")
+	c.SetParamValues("favorites-jazz")
+	handlers.GetSongsByTag(c)
+
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	songs := response["data"].(map[string]interface{})["songs"].([]interface{})
+	if len(songs) != 1 || songs[0].(map[string]interface{})["id"] != jazzID {
+		t.Errorf("Expected only the 5-star jazz song, got %v", songs)
+	}
+
+	// Missing tag returns 400
+	req = httptest.NewRequest(http.MethodPost, "/playlist/tag/bulk", strings.NewReader(`{"filter":{"genre":"Jazz"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.BulkTagSongs(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestSkipSongAndGetSkippedSongs(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Skippy", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	handlers.playlists.Active().AddSong("Loved", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	for i := 0; i < 3; i++ {
+		handlers.playlists.Active().PlaySong(1)
+	}
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/playlist/songs/0/skip", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("index")
+		c.SetParamValues("0")
+		if err := handlers.SkipSong(c); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/skippy?threshold=0.5", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.GetSkippedSongs(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	songs := response["data"].(map[string]interface{})["songs"].([]interface{})
+	if len(songs) != 1 {
+		t.Fatalf("Expected 1 skippy song, got %d", len(songs))
+	}
+	entry := songs[0].(map[string]interface{})
+	if entry["song"].(map[string]interface{})["title"] != "Skippy" || entry["play_ratio"].(float64) != 0 {
+		t.Errorf("Unexpected skippy entry: %v", entry)
+	}
+
+	// Invalid threshold returns 400
+	req = httptest.NewRequest(http.MethodGet, "/playlist/skippy?threshold=2", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetSkippedSongs(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+
+	// Out-of-range index returns 404
+	req = httptest.NewRequest(http.MethodPost, "/playlist/songs/9/skip", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("index")
+	c.SetParamValues("9")
+	handlers.SkipSong(c)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestMoveSongToTopAndBottom(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	firstID, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	lastID, _ := handlers.playlists.Active().AddSong("Song 3", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	req := httptest.NewRequest(http.MethodPost, "/playlist/songs/"+lastID+"/top", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(lastID)
+	if err := handlers.MoveSongToTop(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/playlist/songs/"+firstID+"/bottom", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(firstID)
+	if err := handlers.MoveSongToBottom(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	playlist := handlers.playlists.Active().GetCurrentPlaylist()
+	if playlist[0].ID != lastID || playlist[2].ID != firstID {
+		t.Errorf("Expected %s first and %s last, got %s and %s", lastID, firstID, playlist[0].ID, playlist[2].ID)
+	}
+
+	// Unknown song returns 404
+	for _, handler := range []echo.HandlerFunc{handlers.MoveSongToTop, handlers.MoveSongToBottom} {
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		c.SetParamNames("songId")
+		c.SetParamValues("missing")
+		handler(c)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rec.Code)
+		}
+	}
+}
+
+func TestGetInterleavedGenres(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Rock 1", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Rock 2", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Pop 1", "Artist", "Album", "Pop", "Dance", "Happy", 200, 120)
+
+	req := httptest.NewRequest(http.MethodGet, "/explorer/interleave?a=Rock&b=Pop", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.GetInterleavedGenres(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	songs := response["data"].(map[string]interface{})["songs"].([]interface{})
+	want := []string{"Rock 1", "Pop 1", "Rock 2"}
+	if len(songs) != len(want) {
+		t.Fatalf("Expected %d songs, got %d", len(want), len(songs))
+	}
+	for i, song := range songs {
+		if title := song.(map[string]interface{})["title"]; title != want[i] {
+			t.Errorf("Position %d: expected %s, got %v", i, want[i], title)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/explorer/interleave?a=Rock", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetInterleavedGenres(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestPlayQueueHandlers(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, _ := handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		ids = append(ids, id)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/playlist/queue/"+ids[0], nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues(ids[0])
+	if err := handlers.EnqueueSong(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/playlist/queue/extend?count=5", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if err := handlers.ExtendQueue(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if added := data["added"].([]interface{}); len(added) != 2 {
+		t.Errorf("Expected 2 songs added (one already queued), got %d", len(added))
+	}
+	if queue := data["queue"].([]interface{}); len(queue) != 3 {
+		t.Errorf("Expected queue of 3 songs, got %d", len(queue))
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/playlist/queue/extend?count=0", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.ExtendQueue(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/playlist/queue/missing", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("songId")
+	c.SetParamValues("missing")
+	handlers.EnqueueSong(c)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/playlist/queue", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.ClearQueue(c)
+
+	req = httptest.NewRequest(http.MethodGet, "/playlist/queue", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetQueue(c)
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if count := response["data"].(map[string]interface{})["count"].(float64); count != 0 {
+		t.Errorf("Expected empty queue after clear, got %v", count)
+	}
+}
+
+func TestGetExplorerTree(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	req := httptest.NewRequest(http.MethodGet, "/explorer/tree", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.GetExplorerTree(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if data, ok := response["data"].(map[string]interface{}); !ok || len(data) != 0 {
+		t.Errorf("Expected empty object for empty playlist, got %v", response["data"])
+	}
+
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 210, 120)
+
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetExplorerTree(c)
+	json.Unmarshal(rec.Body.Bytes(), &response)
+
+	data := response["data"].(map[string]interface{})
+	count := data["Rock"].(map[string]interface{})["Alternative"].(map[string]interface{})["Energetic"].(map[string]interface{})["Artist 1"]
+	if count.(float64) != 2 {
+		t.Errorf("Expected 2 songs under Rock/Alternative/Energetic/Artist 1, got %v", count)
+	}
+}
+
+func TestGetExplorerTreeDepth(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Grunge", "Dark", 210, 120)
+
+	req := httptest.NewRequest(http.MethodGet, "/explorer/tree?depth=1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.GetExplorerTree(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if count := response["data"].(map[string]interface{})["Rock"]; count != float64(2) {
+		t.Errorf("Expected genre-only tree with Rock:2, got %v", response["data"])
+	}
+
+	for _, depth := range []string{"0", "5", "x"} {
+		req = httptest.NewRequest(http.MethodGet, "/explorer/tree?depth="+depth, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		handlers.GetExplorerTree(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for depth=%s, got %d", depth, rec.Code)
+		}
+	}
+}
+
+func TestGetActivityLog(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	songID, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().RateSong(songID, 4)
+
+	req := httptest.NewRequest(http.MethodGet, "/activity?limit=1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.GetActivityLog(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	activities := response["data"].(map[string]interface{})["activities"].([]interface{})
+	if len(activities) != 1 {
+		t.Fatalf("Expected 1 activity, got %d", len(activities))
+	}
+	latest := activities[0].(map[string]interface{})
+	if latest["op"] != "rate" || latest["song_title"] != "Song 1" {
+		t.Errorf("Expected latest activity to rate Song 1, got %v", latest)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/activity?limit=0", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetActivityLog(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for limit=0, got %d", rec.Code)
+	}
+}
+
+func TestGetFlowAnalysis(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Jazz", "Smooth Jazz", "Relaxed", 200, 80)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/flow", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.GetFlowAnalysis(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	warnings := response["data"].(map[string]interface{})["warnings"].([]interface{})
+	if len(warnings) != 1 || len(warnings[0].(map[string]interface{})["reasons"].([]interface{})) != 3 {
+		t.Errorf("Expected one warning with BPM, genre and mood reasons, got %v", warnings)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/playlist/flow?max_bpm_jump=0&genre=false&mood=false", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetFlowAnalysis(c)
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if count := response["data"].(map[string]interface{})["count"]; count != float64(0) {
+		t.Errorf("Expected no warnings with every check disabled, got %v", count)
+	}
+
+	for _, query := range []string{"max_bpm_jump=-1", "max_bpm_jump=x", "genre=maybe"} {
+		req = httptest.NewRequest(http.MethodGet, "/playlist/flow?"+query, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		handlers.GetFlowAnalysis(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestGetTopSongs(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Short", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 120, 120)
+	handlers.playlists.Active().AddSong("Long", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 400, 120)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/top?by=duration&count=1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.GetTopSongs(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	songs := response["data"].(map[string]interface{})["songs"].([]interface{})
+	if len(songs) != 1 || songs[0].(map[string]interface{})["title"] != "Long" {
+		t.Errorf("Expected Long as the longest song, got %v", songs)
+	}
+
+	handlers.playlists.Active().PlaySong(0)
+	req = httptest.NewRequest(http.MethodGet, "/playlist/top?by=playcount&k=1", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetTopSongs(c)
+	response = nil
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	songs = data["songs"].([]interface{})
+	if data["by"] != "plays" || len(songs) != 1 || songs[0].(map[string]interface{})["title"] != "Short" {
+		t.Errorf("Expected Short as the most played song, got %v", data)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/playlist/top?by=loudness", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetTopSongs(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown ranking, got %d", rec.Code)
+	}
+}
+
+func TestGetSongsByRatingRange(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	for i, rating := range []int{2, 5, 4} {
+		songID, _ := handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		handlers.playlists.Active().RateSong(songID, rating)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/rating-range?min=3&max=5&order=desc", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.GetSongsByRatingRange(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	songs := response["data"].(map[string]interface{})["songs"].([]interface{})
+	if len(songs) != 2 || songs[0].(map[string]interface{})["rating"] != float64(5) {
+		t.Errorf("Expected ratings 5, 4, got %v", songs)
+	}
+
+	for _, query := range []string{"min=4&max=2", "min=0", "max=6", "order=sideways"} {
+		req = httptest.NewRequest(http.MethodGet, "/playlist/rating-range?"+query, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		handlers.GetSongsByRatingRange(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestFilterSongs(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	engine := handlers.playlists.Active()
+	engine.AddSong("Slow", "Artist", "Album", "Rock", "Alternative", "Chill", 200, 80)
+	fastID, _ := engine.AddSong("Fast", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 140)
+	engine.AddSong("Jazz", "Artist", "Album", "Jazz", "Bebop", "Energetic", 200, 130)
+	engine.RateSong(fastID, 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/filter?genre=rock&min_bpm=100&min_rating=4&fields=title,bpm", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.FilterSongs(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	songs := response["data"].(map[string]interface{})["songs"].([]interface{})
+	if len(songs) != 1 {
+		t.Fatalf("Expected only the fast rock song, got %v", songs)
+	}
+	if song := songs[0].(map[string]interface{}); song["title"] != "Fast" || len(song) != 2 {
+		t.Errorf("Expected the projected fast rock song, got %v", song)
+	}
+
+	for _, query := range []string{"min_bpm=abc", "min_bpm=150&max_bpm=100", "max_rating=9", "added_after=yesterday", "fields=nope"} {
+		req = httptest.NewRequest(http.MethodGet, "/playlist/filter?"+query, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		handlers.FilterSongs(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestGetPlaybackStatsRange(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().PlaySong(0)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/stats/playback?from=2000-01-01T00:00:00Z", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.GetPlaybackStatsRange(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if total := response["data"].(map[string]interface{})["total_songs"]; total != float64(1) {
+		t.Errorf("Expected 1 play in range, got %v", total)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/playlist/stats/playback?to=2000-01-01T00:00:00Z", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	handlers.GetPlaybackStatsRange(c)
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if total := response["data"].(map[string]interface{})["total_songs"]; total != float64(0) {
+		t.Errorf("Expected no plays before 2000, got %v", total)
+	}
+
+	for _, query := range []string{"from=yesterday", "from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z"} {
+		req = httptest.NewRequest(http.MethodGet, "/playlist/stats/playback?"+query, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		handlers.GetPlaybackStatsRange(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestGetListeningTimeline(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+
+	engine := handlers.playlists.Active()
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.PlaySong(0)
+	engine.PlaySong(0)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stats/timeline?days=7&weeks=2&top=1", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	days := data["plays_per_day"].([]interface{})
+	if len(days) != 7 || days[6].(map[string]interface{})["plays"] != float64(2) {
+		t.Errorf("Expected 7 days ending with 2 plays today, got %v", days)
+	}
+	weeks := data["weeks"].([]interface{})
+	top := weeks[1].(map[string]interface{})["top_artists"].([]interface{})
+	if len(weeks) != 2 || len(top) != 1 || top[0].(map[string]interface{})["artist"] != "Artist 1" {
+		t.Errorf("Expected Artist 1 on top this week, got %v", weeks)
+	}
+	if trend := data["trend"].(map[string]interface{}); trend["current_seconds"] != float64(400) || trend["direction"] != "up" {
+		t.Errorf("Expected 400 seconds of rising listening, got %v", trend)
+	}
+
+	for _, query := range []string{"days=0", "days=abc", "weeks=1000", "top=51"} {
+		req = httptest.NewRequest(http.MethodGet, "/api/stats/timeline?"+query, nil)
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestAddSongPlaylistFull(t *testing.T) {
+	e, handlers := setupTestEcho()
+	config := services.DefaultEngineConfig()
+	config.MaxSongs = 1
+	handlers.playlists = services.NewPlaylistManager("Test", config)
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{"title": "Song 2", "artist": "Artist 2"})
+	req := httptest.NewRequest(http.MethodPost, "/playlist/songs", bytes.NewBuffer(jsonData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.AddSong(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", rec.Code)
+	}
+	if handlers.playlists.Active().GetPlaylistSize() != 1 {
+		t.Error("Song should not have been added to a full playlist")
+	}
+}
+
+func TestPruneHistory(t *testing.T) {
+	e, handlers := setupTestEcho()
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().PlaySong(0)
+	handlers.playlists.Active().DeleteSong(0)
+
+	req := httptest.NewRequest(http.MethodPost, "/playlist/history/prune", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.PruneHistory(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["removed"] != float64(1) || data["total"] != float64(0) {
+		t.Errorf("Expected 1 removed and empty history, got %v", data)
+	}
+}
+
+func TestGetSongStats(t *testing.T) {
+	e, handlers := setupTestEcho()
+	songID, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().PlaySong(0)
+
+	for _, tc := range []struct {
+		songID string
+		status int
+	}{
+		{songID, http.StatusOK},
+		{"missing", http.StatusNotFound},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/playlist/songs/"+tc.songID+"/stats", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("songId")
+		c.SetParamValues(tc.songID)
+
+		if err := handlers.GetSongStats(c); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if rec.Code != tc.status {
+			t.Errorf("GetSongStats(%s) status = %d, want %d", tc.songID, rec.Code, tc.status)
+		}
+	}
+}
+
+func TestGetPlaylistCursor(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id, _ := handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		ids = append(ids, id)
+	}
+
+	getPage := func(query string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/playlist?"+query, nil)
+		rec := httptest.NewRecorder()
+		if err := handlers.GetPlaylist(e.NewContext(req, rec)); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		data, _ := response["data"].(map[string]interface{})
+		return rec.Code, data
+	}
+
+	// First page starts at the head
+	code, data := getPage("limit=2")
+	if code != http.StatusOK || len(data["songs"].([]interface{})) != 2 || data["next_cursor"] != ids[1] {
+		t.Fatalf("Unexpected first page %d %v", code, data)
+	}
+
+	// Middle page is unaffected by deleting an earlier song
+	handlers.playlists.Active().DeleteSongByID(ids[0])
+	code, data = getPage("after=" + ids[1] + "&limit=2")
+	songs := data["songs"].([]interface{})
+	if code != http.StatusOK || len(songs) != 2 || songs[0].(map[string]interface{})["id"] != ids[2] {
+		t.Errorf("Unexpected middle page %d %v", code, data)
+	}
+
+	// Last page has no next cursor
+	if _, data = getPage("after=" + ids[3] + "&limit=2"); data["next_cursor"] != "" {
+		t.Errorf("Expected empty next_cursor on the last page, got %v", data["next_cursor"])
+	}
+
+	// Deleted cursor and bad limit are rejected
+	if code, _ = getPage("after=" + ids[0]); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a deleted cursor, got %d", code)
+	}
+	if code, _ = getPage("limit=0"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for limit=0, got %d", code)
+	}
+}
+
+func TestGetPlaylistOffsetAndFields(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	for i := 0; i < 5; i++ {
+		handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	}
+
+	getPage := func(query string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/playlist?"+query, nil)
+		rec := httptest.NewRecorder()
+		if err := handlers.GetPlaylist(e.NewContext(req, rec)); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		data, _ := response["data"].(map[string]interface{})
+		return rec.Code, data
+	}
+
+	code, data := getPage("offset=3&limit=1")
+	songs := data["songs"].([]interface{})
+	if code != http.StatusOK || len(songs) != 1 || songs[0].(map[string]interface{})["title"] != "Song 3" || data["next_offset"] != float64(4) {
+		t.Fatalf("Unexpected offset page %d %v", code, data)
+	}
+	if _, data = getPage("offset=3"); len(data["songs"].([]interface{})) != 2 || data["next_offset"] != nil {
+		t.Errorf("Expected the last two songs without a next_offset, got %v", data)
+	}
+	if _, data = getPage("offset=10"); len(data["songs"].([]interface{})) != 0 {
+		t.Errorf("Expected no songs past the end, got %v", data["songs"])
+	}
+
+	// Projection keeps only the requested fields, in every mode
+	_, data = getPage("fields=id,%20title")
+	song := data["songs"].([]interface{})[0].(map[string]interface{})
+	if len(data["songs"].([]interface{})) != 5 || len(song) != 2 || song["title"] != "Song 0" {
+		t.Errorf("Expected id and title only, got %v", song)
+	}
+	_, data = getPage("offset=1&limit=1&fields=artist")
+	if song = data["songs"].([]interface{})[0].(map[string]interface{}); len(song) != 1 || song["artist"] != "Artist" {
+		t.Errorf("Expected artist only, got %v", song)
+	}
+
+	for _, query := range []string{"offset=-1", "offset=abc", "fields=id,nope", "offset=1&after=x"} {
+		if code, _ = getPage(query); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", query, code)
+		}
+	}
+}
+
+func TestSearchSongByArtist(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Hotel California", "Eagles", "Album", "Rock", "Classic Rock", "Nostalgic", 391, 75)
+	handlers.playlists.Active().AddSong("Take It Easy", "Eagles", "Album", "Rock", "Country Rock", "Happy", 211, 139)
+	handlers.playlists.Active().AddSong("Imagine", "John Lennon", "Album", "Pop", "Soft Rock", "Peaceful", 183, 76)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/search?type=artist&q=eagles", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handlers.SearchSong(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if count := response["data"].(map[string]interface{})["count"]; rec.Code != http.StatusOK || count != float64(2) {
+		t.Errorf("Expected 2 Eagles songs, got status %d and count %v", rec.Code, count)
+	}
+}
+
+func TestBulkDeleteSongsDryRun(t *testing.T) {
+	e, handlers := setupTestEcho()
+	id1, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	deleteSongs := func(query string) map[string]interface{} {
+		body := fmt.Sprintf(`{"song_ids": [%q, "missing"]}`, id1)
+		req := httptest.NewRequest(http.MethodPost, "/playlist/delete/bulk"+query, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := handlers.BulkDeleteSongs(e.NewContext(req, rec)); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rec.Code)
+		}
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return response["data"].(map[string]interface{})
+	}
+
+	// dry_run is accepted as an alias of dryRun
+	for _, query := range []string{"?dryRun=true", "?dry_run=true"} {
+		data := deleteSongs(query)
+		if data["dry_run"] != true || data["count"] != float64(1) || len(data["errors"].([]interface{})) != 1 {
+			t.Errorf("Unexpected dry run result for %s: %v", query, data)
+		}
+		if handlers.playlists.Active().GetPlaylistSize() != 2 {
+			t.Errorf("Dry run %s should not delete, size is %d", query, handlers.playlists.Active().GetPlaylistSize())
+		}
+	}
+
+	data := deleteSongs("")
+	if data["dry_run"] != false || data["count"] != float64(1) || handlers.playlists.Active().GetPlaylistSize() != 1 {
+		t.Errorf("Expected Song 1 deleted, got %v and size %d", data, handlers.playlists.Active().GetPlaylistSize())
+	}
+}
+
+func TestDeduplicateDryRun(t *testing.T) {
+	t.Setenv("PLAYLIST_DUPLICATES", "allow")
+	e, handlers := setupTestEcho()
+	engine := handlers.playlists.Active()
+	engine.AddSong("Song", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	req := httptest.NewRequest(http.MethodPost, "/playlist/deduplicate?dryRun=true", nil)
+	rec := httptest.NewRecorder()
+	if err := handlers.Deduplicate(e.NewContext(req, rec)); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["dry_run"] != true || data["count"] != float64(1) || engine.GetPlaylistSize() != 2 {
+		t.Errorf("Expected the repeat previewed but kept, got %v and size %d", data, engine.GetPlaylistSize())
+	}
+}
+
+func TestBulkRateSongsValidation(t *testing.T) {
+	e, handlers := setupTestEcho()
+	songID, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	for _, body := range []string{`{"song_ids": []}`, fmt.Sprintf(`{"song_ids": [%q], "rating": 9}`, songID)} {
+		req := httptest.NewRequest(http.MethodPost, "/playlist/rate/bulk", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		handlers.BulkRateSongs(e.NewContext(req, rec))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestRenameGenre(t *testing.T) {
+	e, handlers := setupTestEcho()
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Hiphop", "Trap", "Energetic", 200, 140)
+
+	rename := func(genre, body string) int {
+		req := httptest.NewRequest(http.MethodPut, "/explorer/genres/"+url.PathEscape(genre)+"/rename", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("genre")
+		c.SetParamValues(genre)
+		if err := handlers.RenameGenre(c); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		return rec.Code
+	}
+
+	if code := rename("hiphop", `{"name": "Hip Hop"}`); code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if genres := handlers.playlists.Active().GetGenres(); len(genres) != 1 || genres[0] != "Hip Hop" {
+		t.Errorf("Expected only Hip Hop, got %v", genres)
+	}
+	if code := rename("Polka", `{"name": "Folk"}`); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown genre, got %d", code)
+	}
+	if code := rename("Hip Hop", `{"name": " "}`); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an empty name, got %d", code)
+	}
+}
+
+func TestRepairIndexes(t *testing.T) {
+	e, handlers := setupTestEcho()
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	req := httptest.NewRequest(http.MethodPost, "/playlist/repair", nil)
+	rec := httptest.NewRecorder()
+	if err := handlers.RepairIndexes(e.NewContext(req, rec)); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if len(data["repaired"].([]interface{})) != 0 || len(data["remaining"].([]interface{})) != 0 {
+		t.Errorf("Expected nothing to repair on a healthy engine, got %v", data)
+	}
+}
+
+func TestSetSongArtwork(t *testing.T) {
+	e, handlers := setupTestEcho()
+	songID, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	setArtwork := func(songID, body string) int {
+		req := httptest.NewRequest(http.MethodPut, "/playlist/songs/"+songID+"/artwork", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("songId")
+		c.SetParamValues(songID)
+		if err := handlers.SetSongArtwork(c); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		return rec.Code
+	}
+
+	if code := setArtwork(songID, `{"artwork_url": "https://example.com/a.jpg?x=1&y=\"2\""}`); code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if code := setArtwork(songID, `{"artwork_url": "javascript:alert(1)"}`); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid URL, got %d", code)
+	}
+	if code := setArtwork("missing", `{"artwork_url": ""}`); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown song, got %d", code)
+	}
+
+	// The artwork URL is escaped inside the img tag
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handlers.GetPlaylistHTML(e.NewContext(req, rec))
+	if body := rec.Body.String(); !strings.Contains(body, `src="https://example.com/a.jpg?x=1&amp;y=&#34;2&#34;"`) {
+		t.Errorf("Expected an escaped artwork URL in the playlist HTML, got %s", body)
+	}
+}
+
+func TestAddSongInvalidArtwork(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	body := `{"title": "Song 1", "artist": "Artist 1", "artwork_url": "not a url"}`
+	req := httptest.NewRequest(http.MethodPost, "/playlist/songs", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handlers.AddSong(e.NewContext(req, rec)); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusBadRequest || handlers.playlists.Active().GetPlaylistSize() != 0 {
+		t.Errorf("Expected status 400 and no song added, got %d and size %d", rec.Code, handlers.playlists.Active().GetPlaylistSize())
+	}
+}
+
+func TestMoveBlock(t *testing.T) {
+	e, handlers := setupTestEcho()
+	for i := 0; i < 4; i++ {
+		handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	}
+
+	moveBlock := func(body string) int {
+		req := httptest.NewRequest(http.MethodPut, "/playlist/move-block", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := handlers.MoveBlock(e.NewContext(req, rec)); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		return rec.Code
+	}
+
+	if code := moveBlock(`{"start": 2, "count": 2, "to": 0}`); code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if first := handlers.playlists.Active().GetCurrentPlaylist()[0].Title; first != "Song 2" {
+		t.Errorf("Expected Song 2 first, got %s", first)
+	}
+	if code := moveBlock(`{"start": 0, "count": 2, "to": 1}`); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a target inside the block, got %d", code)
+	}
+	if code := moveBlock(`{"count": 2}`); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for missing fields, got %d", code)
+	}
+	if code := moveBlock(fmt.Sprintf(`{"start": 1, "count": %d, "to": 0}`, math.MaxInt)); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an oversized block, got %d", code)
+	}
+}
+
+func TestGetSongsWithSameMood(t *testing.T) {
+	e, handlers := setupTestEcho()
+	songID, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Chill", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Jazz", "Smooth", "Chill", 200, 90)
+	handlers.playlists.Active().AddSong("Song 3", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 200, 140)
+
+	for _, tc := range []struct {
+		songID string
+		status int
+	}{
+		{songID, http.StatusOK},
+		{"missing", http.StatusNotFound},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/playlist/songs/"+tc.songID+"/same-mood", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("songId")
+		c.SetParamValues(tc.songID)
+
+		if err := handlers.GetSongsWithSameMood(c); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if rec.Code != tc.status {
+			t.Errorf("GetSongsWithSameMood(%s) status = %d, want %d", tc.songID, rec.Code, tc.status)
+		}
+		if tc.status != http.StatusOK {
+			continue
+		}
+
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		if data["count"] != float64(1) {
+			t.Errorf("Expected 1 song sharing the mood, got %v", data["count"])
+		}
+	}
+}
+
+func TestListEndpointsClampResults(t *testing.T) {
+	e, handlers := setupTestEcho()
+	if handlers.maxResults != DefaultMaxResults {
+		t.Errorf("Expected default cap %d, got %d", DefaultMaxResults, handlers.maxResults)
+	}
+	handlers.maxResults = 3
+
+	for i := 0; i < 5; i++ {
+		handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		handlers.playlists.Active().PlaySong(i)
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		handler echo.HandlerFunc
+		key     string
+	}{
+		{"history", "/playlist/history?count=1000000", handlers.GetPlaybackHistory, "history"},
+		{"recent", "/playlist/recent?count=1000000", handlers.GetRecentlyAdded, "songs"},
+		{"fuzzy search", "/playlist/search?type=fuzzy&q=song&limit=1000000", handlers.SearchSong, "songs"},
+		{"playlist page", "/playlist?limit=1000000", handlers.GetPlaylist, "songs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rec := httptest.NewRecorder()
+			if err := tt.handler(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var response map[string]interface{}
+			json.Unmarshal(rec.Body.Bytes(), &response)
+			data := response["data"].(map[string]interface{})
+			if items := data[tt.key].([]interface{}); len(items) != 3 {
+				t.Errorf("Expected the cap of 3 items, got %d", len(items))
+			}
+		})
+	}
+}
+
+func TestOrderByBPMRamp(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 170)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Happy", 200, 0)
+	handlers.playlists.Active().AddSong("Song 3", "Artist 3", "Album", "Pop", "Dance", "Upbeat", 200, 100)
+
+	req := httptest.NewRequest(http.MethodPost, "/playlist/order/bpm-ramp", nil)
+	rec := httptest.NewRecorder()
+	if err := handlers.OrderByBPMRamp(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	songs := data["songs"].([]interface{})
+	var titles []string
+	for _, song := range songs {
+		titles = append(titles, song.(map[string]interface{})["title"].(string))
+	}
+	if strings.Join(titles, ",") != "Song 3,Song 1,Song 2" {
+		t.Errorf("Unexpected order %v", titles)
+	}
+	if warnings := data["warnings"].([]interface{}); len(warnings) != 1 {
+		t.Errorf("Expected the 100 to 170 BPM jump to be reported, got %v", warnings)
+	}
+}
+
+func TestTrashEndpoints(t *testing.T) {
+	e, handlers := setupTestEcho()
+	songID, _ := handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().RateSong(songID, 5)
+	handlers.playlists.Active().DeleteSongByID(songID)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/trash", nil)
+	rec := httptest.NewRecorder()
+	if err := handlers.GetTrash(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if count := response["data"].(map[string]interface{})["count"]; count != float64(1) {
+		t.Errorf("Expected 1 trashed song, got %v", count)
+	}
+
+	for _, tc := range []struct {
+		songID string
+		status int
+	}{
+		{songID, http.StatusOK},
+		{songID, http.StatusNotFound}, // Already restored
+	} {
+		req = httptest.NewRequest(http.MethodPost, "/playlist/trash/"+tc.songID+"/restore", nil)
+		rec = httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("songId")
+		c.SetParamValues(tc.songID)
+		if err := handlers.RestoreFromTrash(c); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if rec.Code != tc.status {
+			t.Errorf("RestoreFromTrash status = %d, want %d", rec.Code, tc.status)
+		}
+	}
+	if song, err := handlers.playlists.Active().SearchSongByID(songID); err != nil || song.Rating != 5 {
+		t.Errorf("Expected the restored song with its rating, got %v, %v", song, err)
+	}
+
+	handlers.playlists.Active().DeleteSongByID(songID)
+	req = httptest.NewRequest(http.MethodDelete, "/playlist/trash", nil)
+	rec = httptest.NewRecorder()
+	handlers.EmptyTrash(e.NewContext(req, rec))
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	if removed := response["data"].(map[string]interface{})["removed"]; removed != float64(1) {
+		t.Errorf("Expected 1 song removed, got %v", removed)
+	}
+	if len(handlers.playlists.Active().GetTrash()) != 0 {
+		t.Error("Expected an empty trash")
+	}
+}
+
+func TestGetSortedView(t *testing.T) {
+	e, handlers := setupTestEcho()
+	handlers.playlists.Active().AddSong("Track B", "Artist 2", "Album X", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Track C", "Artist 1", "Album Y", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Track A", "Artist 1", "Album Y", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Track D", "Artist 1", "Album X", "Rock", "Alternative", "Energetic", 200, 120)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/view?sort=artist,album,title", nil)
+	rec := httptest.NewRecorder()
+	if err := handlers.GetSortedView(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	var titles []string
+	for _, song := range response["data"].(map[string]interface{})["songs"].([]interface{}) {
+		titles = append(titles, song.(map[string]interface{})["title"].(string))
+	}
+	if got := strings.Join(titles, ","); got != "Track D,Track A,Track C,Track B" {
+		t.Errorf("Unexpected view order %s", got)
+	}
+
+	// The playlist itself keeps its order
+	if first := handlers.playlists.Active().GetCurrentPlaylist()[0]; first.Title != "Track B" {
+		t.Errorf("Expected the playlist to be unchanged, first song is %s", first.Title)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/playlist/view?sort=artist,mood", nil)
+	rec = httptest.NewRecorder()
+	handlers.GetSortedView(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "mood") {
+		t.Errorf("Expected 400 naming the bad criteria, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetDistinctValues(t *testing.T) {
+	e, handlers := setupTestEcho()
+	handlers.playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song 2", "Artist 2", "Album", "Jazz", "Smooth Jazz", "Energetic", 200, 90)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/distinct?field=mood", nil)
+	rec := httptest.NewRecorder()
+	if err := handlers.GetDistinctValues(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	values := response["data"].(map[string]interface{})["values"].(map[string]interface{})
+	if len(values) != 1 || values["Energetic"] != float64(2) {
+		t.Errorf("Expected Energetic with 2 songs, got %v", values)
+	}
+
+	for _, query := range []string{"", "?field=tempo"} {
+		req = httptest.NewRequest(http.MethodGet, "/playlist/distinct"+query, nil)
+		rec = httptest.NewRecorder()
+		handlers.GetDistinctValues(e.NewContext(req, rec))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %q, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	e, handlers := setupTestEcho()
+	for i := 0; i < 200; i++ {
+		handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	}
+	for i := 0; i < 190; i++ {
+		handlers.playlists.Active().DeleteSong(0)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/playlist/compact", nil)
+	rec := httptest.NewRecorder()
+	if err := handlers.Compact(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	before := data["before"].(map[string]interface{})["song_lookup_capacity"].(float64)
+	after := data["after"].(map[string]interface{})["song_lookup_capacity"].(float64)
+	if after >= before {
+		t.Errorf("Expected song lookup capacity to drop, got %v -> %v", before, after)
+	}
+	if handlers.playlists.Active().GetPlaylistSize() != 10 {
+		t.Errorf("Expected 10 songs to remain, got %d", handlers.playlists.Active().GetPlaylistSize())
+	}
+}
+
+func TestGetSortState(t *testing.T) {
+	e, handlers := setupTestEcho()
+	handlers.playlists.Active().AddSong("Song B", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	handlers.playlists.Active().AddSong("Song A", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	getState := func() map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/playlist/sort", nil)
+		rec := httptest.NewRecorder()
+		if err := handlers.GetSortState(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return response["data"].(map[string]interface{})
+	}
+
+	handlers.playlists.Active().SortPlaylist(datastructures.SortByTitle, "heap")
+	state := getState()
+	if state["criteria"] != "title" || state["algorithm"] != "heap" || state["description"] != "Title (A-Z)" {
+		t.Errorf("Expected title via heap, got %v", state)
+	}
+
+	handlers.playlists.Active().MoveToBottom(handlers.playlists.Active().GetCurrentPlaylist()[0].ID)
+	if state := getState(); state["criteria"] != "custom" {
+		t.Errorf("Expected custom after a move, got %v", state)
+	}
+}
+
+func TestGenerateMoodArc(t *testing.T) {
+	e, handlers := setupTestEcho()
+	handlers.playlists.Active().AddSong("Calm Song", "Artist 1", "Album", "Jazz", "Smooth Jazz", "Calm", 200, 70)
+	handlers.playlists.Active().AddSong("Energetic Song", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 140)
+	handlers.playlists.Active().AddSong("Happy Song", "Artist 3", "Album", "Pop", "Dance", "Happy", 200, 110)
+
+	body := `{"moods": ["Calm", "Happy", "Energetic"], "per_mood": 2}`
+	req := httptest.NewRequest(http.MethodPost, "/playlist/generate/mood-arc", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	if err := handlers.GenerateMoodArc(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	var titles []string
+	for _, song := range response["data"].(map[string]interface{})["songs"].([]interface{}) {
+		titles = append(titles, song.(map[string]interface{})["title"].(string))
+	}
+	if got := strings.Join(titles, ","); got != "Calm Song,Happy Song,Energetic Song" {
+		t.Errorf("Unexpected arc %s", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/playlist/generate/mood-arc", strings.NewReader(`{"moods": []}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	handlers.GenerateMoodArc(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without moods, got %d", rec.Code)
+	}
+}
+
+func TestGenerateMix(t *testing.T) {
+	e, handlers := setupTestEcho()
+
+	engine := handlers.playlists.Active()
+	engine.AddSong("Fast", "Artist", "Album", "House", "Deep", "Energetic", 200, 128)
+	startID, _ := engine.AddSong("Start", "Artist", "Album", "House", "Deep", "Upbeat", 200, 122)
+	engine.AddSong("Far", "Artist", "Album", "House", "Deep", "Energetic", 200, 160)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/mix?max_bpm_step=6&start="+startID, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handlers.GenerateMix(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	songs := data["songs"].([]interface{})
+	if len(songs) != 2 || songs[1].(map[string]interface{})["title"] != "Fast" {
+		t.Errorf("Expected Start then Fast, got %v", songs)
+	}
+	if data["unplaced"] != float64(1) {
+		t.Errorf("Expected the far song unplaced, got %v", data["unplaced"])
+	}
+
+	for _, query := range []string{"max_bpm_step=0", "length=abc", "start=missing"} {
+		req = httptest.NewRequest(http.MethodGet, "/playlist/mix?"+query, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		handlers.GenerateMix(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestSearchAll(t *testing.T) {
+	e, handlers := setupTestEcho()
+	noteID, _ := handlers.playlists.Active().AddSong("Quiet Evening", "Artist 1", "Album 1", "Jazz", "Smooth Jazz", "Relaxed", 200, 80)
+	handlers.playlists.Active().SetSongNote(noteID, "recorded at sunset")
+	handlers.playlists.Active().AddSong("Sunset Drive", "Artist 2", "Album 2", "Rock", "Alternative", "Energetic", 200, 120)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/search/all?q=sunset", nil)
+	rec := httptest.NewRecorder()
+	if err := handlers.SearchAll(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	hits := response["data"].(map[string]interface{})["hits"].([]interface{})
+	if len(hits) != 2 {
+		t.Fatalf("Expected 2 hits, got %d", len(hits))
+	}
+	first := hits[0].(map[string]interface{})
+	if first["field"] != "title" || first["song"].(map[string]interface{})["title"] != "Sunset Drive" {
+		t.Errorf("Expected the title match first, got %v", first)
+	}
+	if second := hits[1].(map[string]interface{}); second["field"] != "notes" {
+		t.Errorf("Expected the note match second, got %v", second)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/playlist/search/all", nil)
+	rec = httptest.NewRecorder()
+	handlers.SearchAll(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a query, got %d", rec.Code)
+	}
+}
+
+func TestGetTracklist(t *testing.T) {
+	e, handlers := setupTestEcho()
+	handlers.playlists.Active().AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 185, 120)
+
+	req := httptest.NewRequest(http.MethodGet, "/playlist/tracklist", nil)
+	rec := httptest.NewRecorder()
+	if err := handlers.GetTracklist(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), echo.MIMETextPlain) {
+		t.Errorf("Expected a text/plain response, got %s", rec.Header().Get(echo.HeaderContentType))
+	}
+	if !strings.Contains(rec.Body.String(), "1. Test Artist - Test Song (03:05)") {
+		t.Errorf("Expected a numbered track line, got %q", rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "1 song, 03:05 total") {
+		t.Errorf("Expected a totals footer, got %q", rec.Body.String())
+	}
+}
+
+func TestPlaylistManagement(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	handlers.playlists.Active().AddSong("Default Song", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodPost, "/api/playlists", `{"name": "Road Trip"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Songs added through the playlist ID stay in that playlist
+	rec = request(http.MethodPost, "/api/v1/playlists/road-trip/songs", `{"title": "Trip Song", "artist": "Artist"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = request(http.MethodGet, "/api/playlists/road-trip", "")
+	if !strings.Contains(rec.Body.String(), "Trip Song") || strings.Contains(rec.Body.String(), "Default Song") {
+		t.Errorf("Expected only the road trip song, got %s", rec.Body.String())
+	}
+	if rec = request(http.MethodGet, "/api/playlist", ""); strings.Contains(rec.Body.String(), "Trip Song") {
+		t.Errorf("Expected the active playlist to be unaffected, got %s", rec.Body.String())
+	}
+
+	// Rename reuses the per-playlist name endpoint
+	request(http.MethodPut, "/api/playlists/road-trip/name", `{"name": "Summer Trip"}`)
+
+	// Switching changes what /api/playlist serves
+	if rec = request(http.MethodPost, "/api/playlists/road-trip/activate", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if rec = request(http.MethodGet, "/api/playlist", ""); !strings.Contains(rec.Body.String(), "Trip Song") {
+		t.Errorf("Expected the switched playlist, got %s", rec.Body.String())
+	}
+
+	rec = request(http.MethodGet, "/api/playlists", "")
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["count"].(float64) != 2 || data["active_id"] != "road-trip" {
+		t.Errorf("Unexpected playlist listing %v", data)
+	}
+	second := data["playlists"].([]interface{})[1].(map[string]interface{})
+	if second["name"] != "Summer Trip" || second["size"].(float64) != 1 {
+		t.Errorf("Expected the renamed playlist with 1 song, got %v", second)
+	}
+
+	if rec = request(http.MethodGet, "/api/playlists/missing/songs/x/stats", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown playlist, got %d", rec.Code)
+	}
+
+	if rec = request(http.MethodDelete, "/api/playlists/road-trip", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if rec = request(http.MethodDelete, "/api/playlists/my-playlist", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 deleting the last playlist, got %d", rec.Code)
+	}
+	if handlers.playlists.Active().GetPlaylistSize() != 1 {
+		t.Errorf("Expected the default playlist to be active again with its song")
+	}
+}
+
+func TestSaveLoadPlaylist(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	handlers.store = storage.NewFileStore(t.TempDir())
+	handlers.playlists.Active().AddSong("Saved Song", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	request := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request("/api/playlist/load"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 before anything is saved, got %d", rec.Code)
+	}
+	if rec := request("/api/playlist/save"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	handlers.playlists.Active().ClearPlaylist()
+	rec := request("/api/playlist/load")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if handlers.playlists.Active().GetPlaylistSize() != 1 {
+		t.Errorf("Expected the saved song back, got %d songs", handlers.playlists.Active().GetPlaylistSize())
+	}
+
+	// Snapshots are kept per playlist
+	handlers.playlists.Create("Road Trip")
+	if rec := request("/api/playlists/road-trip/load"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a playlist that was never saved, got %d", rec.Code)
+	}
+}
+
+// TestConcurrentRequests serves overlapping reads and writes against two playlists; run with -race
+func TestConcurrentRequests(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	handlers.playlists.Create("Road Trip")
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/api/playlist/songs", `{"title": "Song %d", "artist": "Artist"}`},
+		{http.MethodPost, "/api/playlists/road-trip/songs", `{"title": "Trip %d", "artist": "Artist"}`},
+		{http.MethodGet, "/api/playlist", ""},
+		{http.MethodGet, "/api/playlist/search/all?q=song", ""},
+		{http.MethodPost, "/api/playlist/sort", `{"criteria": "title"}`},
+		{http.MethodGet, "/api/playlists", ""},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := requests[i%len(requests)]
+			body := r.body
+			if strings.Contains(body, "%d") {
+				body = fmt.Sprintf(body, i)
+			}
+			req := httptest.NewRequest(r.method, r.path, strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code >= http.StatusInternalServerError {
+				t.Errorf("%s %s returned %d: %s", r.method, r.path, rec.Code, rec.Body.String())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if size := handlers.playlists.Active().GetPlaylistSize(); size != 10 {
+		t.Errorf("Expected 10 songs in the active playlist, got %d", size)
+	}
+	road, _ := handlers.playlists.Get("road-trip")
+	if size := road.GetPlaylistSize(); size != 10 {
+		t.Errorf("Expected 10 songs in the road trip playlist, got %d", size)
+	}
+}
+
+func TestExportPlaylist(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	handlers.playlists.Active().AddSong("Song 1", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	request := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/playlist/export"+query, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("?format=m3u")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "#EXTM3U\n#EXTINF:200,Artist - Song 1\n") {
+		t.Errorf("Expected an M3U download, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if disposition := rec.Header().Get(echo.HeaderContentDisposition); disposition != `attachment; filename="my-playlist.m3u"` {
+		t.Errorf("Unexpected Content-Disposition %q", disposition)
+	}
+
+	rec = request("")
+	var document map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &document); err != nil || document["count"].(float64) != 1 {
+		t.Errorf("Expected a JSON export by default, got %s", rec.Body.String())
+	}
+
+	if rec = request("?format=xspf"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown format, got %d", rec.Code)
+	}
+}
+
+func TestPlayerQueueEndpoints(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	for i := 0; i < 3; i++ {
+		handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	}
+
+	request := func(method, path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return rec, response
+	}
+	songTitle := func(response map[string]interface{}) interface{} {
+		return response["data"].(map[string]interface{})["song"].(map[string]interface{})["title"]
+	}
+
+	if rec, _ := request(http.MethodPost, "/api/playlist/queue/previous", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 before anything is played, got %d", rec.Code)
+	}
+
+	request(http.MethodPost, "/api/playlist/queue/next", "")
+	rec, response := request(http.MethodPost, "/api/playlist/queue/next", "")
+	if rec.Code != http.StatusOK || songTitle(response) != "Song 1" {
+		t.Fatalf("Expected Song 1, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, response = request(http.MethodPost, "/api/playlist/queue/previous", ""); songTitle(response) != "Song 0" {
+		t.Errorf("Expected Song 0, got %v", response)
+	}
+
+	if rec, _ = request(http.MethodPut, "/api/playlist/queue/mode", `{"repeat": "twice"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown repeat mode, got %d", rec.Code)
+	}
+	if rec, _ = request(http.MethodPut, "/api/playlist/queue/mode", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without settings, got %d", rec.Code)
+	}
+	rec, response = request(http.MethodPut, "/api/playlist/queue/mode", `{"shuffle": true, "repeat": "all"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	_, response = request(http.MethodGet, "/api/playlist/queue/player", "")
+	status := response["data"].(map[string]interface{})
+	if status["shuffle"] != true || status["repeat"] != "all" || status["current"].(map[string]interface{})["title"] != "Song 0" {
+		t.Errorf("Unexpected player status %v", status)
+	}
+}
+
+func TestPlaybackEndpoints(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	for i := 0; i < 2; i++ {
+		handlers.playlists.Active().AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	}
+
+	request := func(method, path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return rec, response
+	}
+
+	if rec, _ := request(http.MethodPost, "/api/playback/pause", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 before anything is played, got %d", rec.Code)
+	}
+
+	handlers.playlists.Active().PlaySong(0)
+	rec, response := request(http.MethodPost, "/api/playback/pause", "")
+	data := response["data"].(map[string]interface{})
+	if rec.Code != http.StatusOK || data["state"] != "paused" {
+		t.Fatalf("Expected a paused player, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	_, response = request(http.MethodPost, "/api/playback/seek", `{"position": 120}`)
+	data = response["data"].(map[string]interface{})
+	if data["position"].(float64) != 120 || data["remaining"].(float64) != 80 || data["state"] != "paused" {
+		t.Errorf("Expected a paused song at 120 seconds, got %v", data)
+	}
+	for _, body := range []string{`{}`, `{"position": 201}`, `{"position": -5}`} {
+		if rec, _ := request(http.MethodPost, "/api/playback/seek", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+
+	if _, response = request(http.MethodPost, "/api/playback/resume", ""); response["data"].(map[string]interface{})["state"] != "playing" {
+		t.Errorf("Expected a playing player, got %v", response)
+	}
+
+	// Seeking to the end moves on to the next song
+	request(http.MethodPost, "/api/playback/seek", `{"position": 200}`)
+	_, response = request(http.MethodGet, "/api/v1/playback", "")
+	song := response["data"].(map[string]interface{})["song"].(map[string]interface{})
+	if song["title"] != "Song 1" {
+		t.Errorf("Expected Song 1 after seeking to the end, got %v", song["title"])
+	}
+}
+
+func TestSmartPlaylistEndpoints(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	engine := handlers.playlists.Active()
+	rockID, _ := engine.AddSong("Rock Song", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Jazz Song", "Artist", "Album", "Jazz", "Bebop", "Chill", 200, 100)
+	engine.RateSong(rockID, 5)
+
+	request := func(method, path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return rec, response
+	}
+
+	rec, response := request(http.MethodPost, "/api/smart-playlists", `{"name": "Top Rock", "query": "rating >= 4 AND genre = Rock"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	id := response["data"].(map[string]interface{})["id"].(string)
+
+	_, response = request(http.MethodGet, "/api/smart-playlists/"+id+"?fields=title", "")
+	songs := response["data"].(map[string]interface{})["songs"].([]interface{})
+	if len(songs) != 1 || songs[0].(map[string]interface{})["title"] != "Rock Song" {
+		t.Errorf("Expected only the rated rock song, got %v", songs)
+	}
+
+	// Unrating the song takes it out of the smart playlist
+	request(http.MethodDelete, "/api/playlist/songs/"+rockID+"/rating", "")
+	_, response = request(http.MethodGet, "/api/smart-playlists", "")
+	listed := response["data"].(map[string]interface{})["smart_playlists"].([]interface{})
+	if len(listed) != 1 || listed[0].(map[string]interface{})["count"] != float64(0) {
+		t.Errorf("Expected one empty smart playlist, got %v", listed)
+	}
+
+	body := `{"name": "Jazz", "rules": [{"field": "genre", "operator": "=", "value": "jazz"}]}`
+	if rec, response = request(http.MethodPut, "/api/smart-playlists/"+id, body); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 updating, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, response = request(http.MethodGet, "/api/smart-playlists/"+id, ""); response["data"].(map[string]interface{})["count"] != float64(1) {
+		t.Errorf("Expected the updated rules to match the jazz song, got %v", response["data"])
+	}
+
+	for _, body := range []string{
+		`{"name": "Bad", "query": "rating >="}`,
+		`{"name": "Bad", "query": "bpm > 1", "rules": [{"field": "bpm", "operator": ">", "value": 1}]}`,
+		`{"name": "", "query": "bpm > 1"}`,
+		`{"name": "Bad", "rules": [{"field": "colour", "operator": "=", "value": "red"}]}`,
+	} {
+		if rec, _ := request(http.MethodPost, "/api/smart-playlists", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+
+	if rec, _ := request(http.MethodDelete, "/api/smart-playlists/"+id, ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 deleting, got %d", rec.Code)
+	}
+	if rec, _ := request(http.MethodGet, "/api/smart-playlists/"+id, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after deleting, got %d", rec.Code)
+	}
+}
+
+func TestSearchSongFuzzyMode(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	handlers.playlists.Active().AddSong("Bohemian Rhapsody", "Queen", "A Night at the Opera", "Rock", "Classic Rock", "Epic", 354, 72)
+	handlers.playlists.Active().AddSong("Hotel California", "Eagles", "Hotel California", "Rock", "Soft Rock", "Calm", 391, 75)
+
+	request := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/playlist/search"+query, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("?mode=fuzzy&q=queen&limit=5")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	hits := response["data"].(map[string]interface{})["hits"].([]interface{})
+	if len(hits) != 1 || hits[0].(map[string]interface{})["field"] != "artist" {
+		t.Errorf("Expected one artist hit, got %v", hits)
+	}
+
+	if rec = request("?mode=fuzzy&q=queen&limit=zero"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid limit, got %d", rec.Code)
+	}
+	if rec = request("?mode=exact&q=queen"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown mode, got %d", rec.Code)
+	}
+}
+
+func TestUpdateSong(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+	songID, _ := handlers.playlists.Active().AddSong("Yesterdy", "The Beatles", "Help!", "Rock", "Pop Rock", "Sad", 125, 97)
+
+	request := func(songID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/playlist/songs/"+songID, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(songID, `{"title": "Yesterday", "mood": "Melancholic"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["id"] != songID || data["title"] != "Yesterday" || data["mood"] != "Melancholic" || data["album"] != "Help!" {
+		t.Errorf("Unexpected updated song %v", data)
+	}
+
+	if rec = request(songID, `{"rating": 9}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid rating, got %d", rec.Code)
+	}
+	if rec = request("missing", `{"title": "x"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown song, got %d", rec.Code)
+	}
+}
+
+func TestUserScopedPlaylists(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+
+	request := func(method, path, userID, body string, cookies ...*http.Cookie) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if userID != "" {
+			req.Header.Set(UserIDHeader, userID)
+		}
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return rec, response
+	}
+
+	rec, response := request(http.MethodPost, "/api/users", "", `{"name": "Alice"}`)
+	if rec.Code != http.StatusCreated || response["data"].(map[string]interface{})["id"] != "alice" {
+		t.Fatalf("Expected user alice to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Alice's songs, ratings and history stay out of the default user's playlist
+	song := `{"title": "Song 1", "artist": "Artist 1", "album": "Album", "genre": "Rock", "subgenre": "Alternative", "mood": "Energetic", "duration": 200, "bpm": 120}`
+	if rec, _ = request(http.MethodPost, "/api/playlist/songs", "alice", song); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the song to be added for alice, got %d: %s", rec.Code, rec.Body.String())
+	}
+	request(http.MethodPost, "/api/playlist/songs/0/play", "alice", "")
+	if handlers.playlists.Active().GetPlaylistSize() != 0 {
+		t.Error("Expected the default playlist to be unaffected")
+	}
+	_, response = request(http.MethodGet, "/api/playlist/history", "alice", "")
+	if total := response["data"].(map[string]interface{})["total"]; total != float64(1) {
+		t.Errorf("Expected one play in alice's history, got %v", total)
+	}
+	if handlers.playlists.Active().GetHistorySize() != 0 {
+		t.Error("Expected the default history to be unaffected")
+	}
+
+	if rec, _ = request(http.MethodGet, "/api/playlist", "missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown user, got %d", rec.Code)
+	}
+
+	// The session cookie selects the user when there is no header
+	rec, _ = request(http.MethodPost, "/api/users/alice/session", "", "")
+	cookies := rec.Result().Cookies()
+	if rec.Code != http.StatusOK || len(cookies) != 1 || cookies[0].Name != UserSessionCookie {
+		t.Fatalf("Expected a session cookie, got %d: %v", rec.Code, cookies)
+	}
+	_, response = request(http.MethodGet, "/api/v1/playlist", "", "", cookies[0])
+	if size := response["data"].(map[string]interface{})["size"]; size != float64(1) {
+		t.Errorf("Expected alice's playlist through the cookie, got size %v", size)
+	}
+
+	// Move alice's playlist to the default user after giving her another one
+	request(http.MethodPost, "/api/playlists", "alice", `{"name": "Spare"}`)
+	rec, response = request(http.MethodPost, "/api/users/alice/playlists/my-playlist/migrate", "", `{"to_user_id": "default"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the playlist to migrate, got %d: %s", rec.Code, rec.Body.String())
+	}
+	newID := response["data"].(map[string]interface{})["playlist_id"].(string)
+	if newID != "my-playlist-2" {
+		t.Errorf("Expected a numbered ID next to the default playlist, got %s", newID)
+	}
+	if engine, err := handlers.playlists.Get(newID); err != nil || engine.GetPlaylistSize() != 1 {
+		t.Errorf("Expected the default user to own the migrated songs, got %v", err)
+	}
+
+	if rec, _ = request(http.MethodPost, "/api/users/alice/playlists/spare/migrate", "", `{"to_user_id": "default"}`); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 moving a user's last playlist, got %d", rec.Code)
+	}
+	if rec, _ = request(http.MethodPost, "/api/users/alice/playlists/spare/migrate", "", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without to_user_id, got %d", rec.Code)
+	}
+
+	_, response = request(http.MethodGet, "/api/users", "alice", "")
+	data := response["data"].(map[string]interface{})
+	if data["count"] != float64(2) || data["current"] != "alice" {
+		t.Errorf("Expected two users with alice current, got %v", data)
+	}
+}
+
+func TestAlbumEndpoints(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+
+	engine := handlers.playlists.Active()
+	engine.AddSong("Come Together", "The Beatles", "Abbey Road", "Rock", "Classic Rock", "Upbeat", 259, 82)
+	engine.AddSong("Something", "The Beatles", "Abbey Road", "Rock", "Classic Rock", "Calm", 182, 66)
+	engine.AddSong("Time", "Pink Floyd", "The Dark Side of the Moon", "Rock", "Progressive", "Calm", 413, 123)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/albums?sort=duration", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	albums := response["data"].(map[string]interface{})["albums"].([]interface{})
+	if len(albums) != 2 || albums[0].(map[string]interface{})["album"] != "Abbey Road" || albums[0].(map[string]interface{})["total_duration"] != float64(441) {
+		t.Errorf("Expected Abbey Road as the longest album, got %v", albums)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/albums/abbey%20road/songs", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	response = nil
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["count"] != float64(2) || data["album"].(map[string]interface{})["song_count"] != float64(2) {
+		t.Errorf("Expected Abbey Road's 2 songs, got %v", data)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/albums/Abbey%20Road/play", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if queue := engine.GetQueue(); len(queue) != 2 || queue[0].Title != "Come Together" {
+		t.Errorf("Expected Abbey Road queued in order, got %v", queue)
+	}
+
+	// Albums sharing a name need the artist to pick one
+	engine.AddSong("Dancing Queen", "ABBA", "Greatest Hits", "Pop", "Disco", "Happy", 231, 101)
+	engine.AddSong("Bohemian Rhapsody", "Queen", "Greatest Hits", "Rock", "Classic Rock", "Epic", 354, 72)
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, "/api/albums/Unknown/songs", http.StatusNotFound},
+		{http.MethodPost, "/api/albums/Unknown/play", http.StatusNotFound},
+		{http.MethodGet, "/api/albums/Greatest%20Hits/songs", http.StatusConflict},
+		{http.MethodPost, "/api/albums/Greatest%20Hits/play", http.StatusConflict},
+		{http.MethodGet, "/api/albums/Greatest%20Hits/songs?artist=ABBA", http.StatusOK},
+		{http.MethodGet, "/api/albums/Greatest%20Hits/songs?artist=Eagles", http.StatusNotFound},
+		{http.MethodGet, "/api/albums?sort=plays", http.StatusBadRequest},
+	} {
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.status, rec.Code)
+		}
+	}
+}
+
+func TestGetArtistProfile(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+
+	engine := handlers.playlists.Active()
+	engine.AddSong("Creep", "Radiohead", "Pablo Honey", "Rock", "Alternative", "Sad", 238, 92)
+	engine.AddSong("Karma Police", "Radiohead", "OK Computer", "Rock", "Alternative", "Melancholic", 264, 75)
+	engine.AddSong("Yellow", "Coldplay", "Parachutes", "Rock", "Alternative", "Sad", 269, 87)
+	engine.PlaySong(1)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/artists/radiohead", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["artist"] != "Radiohead" || data["song_count"] != float64(2) || data["play_count"] != float64(1) {
+		t.Errorf("Unexpected profile %v", data)
+	}
+	if most := data["most_played"].(map[string]interface{}); most["title"] != "Karma Police" {
+		t.Errorf("Expected Karma Police as the most played track, got %v", most)
+	}
+	if songs := data["songs"].([]interface{}); len(songs) != 2 {
+		t.Errorf("Expected both Radiohead songs, got %v", songs)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/artists/Unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown artist, got %d", rec.Code)
+	}
+}
+
+func TestImportFromIntegration(t *testing.T) {
+	e, handlers := setupVersionedEcho()
+
+	spotify := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"items": [
+			{"track": {"type": "track", "name": "Paranoid Android", "duration_ms": 387000, "album": {"name": "OK Computer"}, "artists": [{"name": "Radiohead"}]}},
+			{"track": {"type": "track", "name": "Yellow", "duration_ms": 269000, "album": {"name": "Parachutes"}, "artists": [{"name": "Coldplay"}]}}
+		], "next": null}`)
+	}))
+	defer spotify.Close()
+
+	importBody := func(body string, stream bool) *httptest.ResponseRecorder {
+		target := "/api/integrations/spotify/import"
+		if stream {
+			target += "?stream=true"
+		}
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	playlistURL := `{"url": "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc"}`
+
+	// Without SPOTIFY_TOKEN the integration is unavailable
+	handlers.integrations = integrations.NewClient(integrations.Config{SpotifyAPIURL: spotify.URL})
+	if rec := importBody(playlistURL, false); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a token, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	handlers.integrations = integrations.NewClient(integrations.Config{SpotifyToken: "token", SpotifyAPIURL: spotify.URL})
+	if rec := importBody(`{}`, false); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a source, got %d", rec.Code)
+	}
+	if rec := importBody(`{"url": "https://example.com/playlist/1"}`, false); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a non-Spotify URL, got %d", rec.Code)
+	}
+
+	rec := importBody(playlistURL, false)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["fetched"] != float64(2) || data["added"] != float64(2) || data["skipped"] != float64(0) {
+		t.Errorf("Unexpected import summary %v", data)
+	}
+	songs, _ := handlers.playlists.Active().SearchSongByTitle("Paranoid Android")
+	if len(songs) != 1 || songs[0].Album != "OK Computer" || songs[0].Duration != 387 {
+		t.Errorf("Expected the track imported as a song, got %v", songs)
+	}
+
+	// Importing again streams progress and skips the duplicates
+	rec = importBody(playlistURL, true)
+	stream := rec.Body.String()
+	if rec.Header().Get(echo.HeaderContentType) != "text/event-stream" || strings.Count(stream, "event: progress\n") != 2 {
+		t.Errorf("Expected a fetch and an import progress event:\n%s", stream)
+	}
+	if !strings.Contains(stream, "event: done\n") || !strings.Contains(stream, `"skipped":2`) {
+		t.Errorf("Expected a done event with both tracks skipped:\n%s", stream)
+	}
+	if handlers.playlists.Active().GetPlaylistSize() != 2 {
+		t.Errorf("Expected 2 songs, got %d", handlers.playlists.Active().GetPlaylistSize())
+	}
+}
+
+func TestAutoSaveRestoresEveryPlaylist(t *testing.T) {
+	t.Setenv("PLAYLIST_AUTOSAVE", "true")
+	t.Setenv("PLAYLIST_DATA_DIR", t.TempDir())
+
+	start := func() (*echo.Echo, *PlaylistHandlers) {
+		e := echo.New()
+		handlers := NewPlaylistHandlers()
+		registerAPIRoutes(e.Group("/api"), handlers)
+		return e, handlers
+	}
+	request := func(e *echo.Echo, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	e, handlers := start()
+	for _, name := range []string{"Road Trip", "Empty Mix", "Scratch"} {
+		if rec := request(e, http.MethodPost, "/api/playlists", fmt.Sprintf(`{"name": %q}`, name)); rec.Code != http.StatusCreated {
+			t.Fatalf("Expected playlist %s to be created, got %d: %s", name, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := request(e, http.MethodPost, "/api/playlists/road-trip/songs", `{"title":"Song 1","artist":"Artist 1","duration":200}`); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the song to be added, got %d: %s", rec.Code, rec.Body.String())
+	}
+	handlers.autoSaver.Stop() // Flushes pending saves
+	if rec := request(e, http.MethodDelete, "/api/playlists/scratch", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the playlist to be deleted, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	_, restarted := start()
+	defer restarted.autoSaver.Stop()
+	var ids []string
+	for _, summary := range restarted.playlists.List() {
+		ids = append(ids, summary.ID)
+	}
+	if fmt.Sprint(ids) != "[my-playlist empty-mix road-trip]" {
+		t.Fatalf("Expected the saved playlists without the deleted one, got %v", ids)
+	}
+	roadTrip, _ := restarted.playlists.Get("road-trip")
+	if roadTrip.GetPlaylistName() != "Road Trip" || roadTrip.GetPlaylistSize() != 1 {
+		t.Errorf("Expected Road Trip with its song, got %s with %d songs", roadTrip.GetPlaylistName(), roadTrip.GetPlaylistSize())
+	}
+}
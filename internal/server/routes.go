@@ -13,11 +13,12 @@ func (s *Server) RegisterRoutes() http.Handler {
 	e := echo.New()
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
+	e.Use(newGzipMiddleware())
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"https://*", "http://*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key", UserIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -32,52 +33,208 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	playlistHandlers := NewPlaylistHandlers()
 
-	api := e.Group("/api")
+	// Versioned API; breaking changes go under a new prefix. /api stays as an alias of v1 for now
+	registerAPIRoutes(e.Group("/api/v1"), playlistHandlers)
+	registerAPIRoutes(e.Group("/api"), playlistHandlers)
 
-	playlist := api.Group("/playlist")
-	{
-		playlist.GET("", playlistHandlers.GetPlaylist)                             // Get current playlist
-		playlist.GET("/html", playlistHandlers.GetPlaylistHTML)                    // Get current playlist as HTML for HTMX
-		playlist.POST("/songs", playlistHandlers.AddSong)                          // Add song to playlist
-		playlist.DELETE("/songs/:index", playlistHandlers.DeleteSong)              // Delete song by index
-		playlist.PUT("/songs/:fromIndex/move/:toIndex", playlistHandlers.MoveSong) // Move song
-		playlist.POST("/reverse", playlistHandlers.ReversePlaylist)                // Reverse playlist order
-		playlist.DELETE("", playlistHandlers.ClearPlaylist)                        // Clear entire playlist
-		playlist.PUT("/name", playlistHandlers.SetPlaylistName)                    // Update playlist name
-
-		playlist.POST("/songs/:index/play", playlistHandlers.PlaySong) // Play song by index
-		playlist.POST("/undo", playlistHandlers.UndoLastPlay)          // Undo last play
-
-		playlist.POST("/songs/:songId/rate", playlistHandlers.RateSong)    // Rate a song
-		playlist.GET("/rating/:rating", playlistHandlers.GetSongsByRating) // Get songs by rating
-
-		playlist.GET("/search", playlistHandlers.SearchSong) // Search by ID or title
-
-		playlist.POST("/sort", playlistHandlers.SortPlaylist) // Sort playlist
+	return e
+}
 
-		playlist.GET("/history", playlistHandlers.GetPlaybackHistory)         // Get playback history
-		playlist.GET("/recommendations", playlistHandlers.GetRecommendations) // Get smart recommendations
+// registerAPIRoutes mounts every user, playlist, explorer and dashboard endpoint on the given group
+// /playlist and the unprefixed explorer and dashboard routes serve the active playlist;
+// the same routes under /playlists/:playlistId serve the named one
+// Everything but /users acts on the playlists of the user named by X-User-ID or the session cookie
+func registerAPIRoutes(api *echo.Group, playlistHandlers *PlaylistHandlers) {
+	users := api.Group("/users")
+	{
+		users.GET("", playlistHandlers.ListUsers)                                              // List users
+		users.POST("", playlistHandlers.CreateUser)                                            // Create a user with an empty playlist
+		users.POST("/:userId/session", playlistHandlers.StartUserSession)                      // Act as the user in this browser
+		users.DELETE("/session", playlistHandlers.EndUserSession)                              // Go back to the default user
+		users.POST("/:userId/playlists/:playlistId/migrate", playlistHandlers.MigratePlaylist) // Move a playlist to {"to_user_id"}
+	}
 
-		playlist.GET("/stats", playlistHandlers.GetStats)          // Get playlist statistics
-		playlist.GET("/benchmark", playlistHandlers.BenchmarkSort) // Benchmark sorting algorithms
+	api = api.Group("", playlistHandlers.resolveUser)
+	playlist := api.Group("/playlist")
+	playlist.DELETE("", playlistHandlers.ClearPlaylist) // Clear entire playlist
+	registerPlaylistRoutes(playlist, playlistHandlers)
+	registerExplorerRoutes(api, playlistHandlers)
+	registerPlaybackRoutes(api, playlistHandlers)
+	registerSmartPlaylistRoutes(api, playlistHandlers)
+	registerIntegrationRoutes(api, playlistHandlers)
+
+	playlists := api.Group("/playlists")
+	{
+		playlists.GET("", playlistHandlers.ListPlaylists)   // List playlists and which one is active
+		playlists.POST("", playlistHandlers.CreatePlaylist) // Create an empty playlist
+	}
 
-		playlist.POST("/sample-data", playlistHandlers.LoadSampleData) // Load sample data for demo
+	selected := playlists.Group("/:playlistId", playlistHandlers.resolvePlaylist)
+	{
+		selected.DELETE("", playlistHandlers.DeletePlaylist)          // Delete a playlist and its songs
+		selected.POST("/activate", playlistHandlers.ActivatePlaylist) // Make a playlist the active one
 	}
+	registerPlaylistRoutes(selected, playlistHandlers)
+	registerExplorerRoutes(selected, playlistHandlers)
+	registerPlaybackRoutes(selected, playlistHandlers)
+	registerSmartPlaylistRoutes(selected, playlistHandlers)
+	registerIntegrationRoutes(selected, playlistHandlers)
+}
 
+// registerPlaylistRoutes mounts the song, playback, sorting and import endpoints of one playlist
+func registerPlaylistRoutes(playlist *echo.Group, playlistHandlers *PlaylistHandlers) {
+	playlist.GET("", playlistHandlers.GetPlaylist)                             // Get current playlist
+	playlist.GET("/html", playlistHandlers.GetPlaylistHTML)                    // Get current playlist as HTML for HTMX
+	playlist.POST("/songs", playlistHandlers.AddSong)                          // Add song to playlist
+	playlist.DELETE("/songs/:index", playlistHandlers.DeleteSong)              // Delete song by index
+	playlist.DELETE("/songs/by-id/:songId", playlistHandlers.DeleteSongByID)   // Delete song by ID
+	playlist.GET("/trash", playlistHandlers.GetTrash)                          // Get deleted songs that can be restored
+	playlist.POST("/trash/:songId/restore", playlistHandlers.RestoreFromTrash) // Restore a deleted song to the end
+	playlist.DELETE("/trash", playlistHandlers.EmptyTrash)                     // Permanently discard deleted songs
+	playlist.PUT("/songs/:fromIndex/move/:toIndex", playlistHandlers.MoveSong) // Move song
+	playlist.PUT("/move-block", playlistHandlers.MoveBlock)                    // Move a run of consecutive songs
+	playlist.POST("/songs/:songId/top", playlistHandlers.MoveSongToTop)        // Move song to the start
+	playlist.POST("/songs/:songId/bottom", playlistHandlers.MoveSongToBottom)  // Move song to the end
+	playlist.POST("/reverse", playlistHandlers.ReversePlaylist)                // Reverse playlist order
+	playlist.DELETE("/songs", playlistHandlers.ClearPlaylist)                  // Clear entire playlist
+	playlist.PUT("/name", playlistHandlers.SetPlaylistName)                    // Update playlist name
+
+	playlist.POST("/songs/:index/play", playlistHandlers.PlaySong) // Play song by index
+	playlist.POST("/songs/:index/skip", playlistHandlers.SkipSong) // Record a skipped song
+	playlist.GET("/skippy", playlistHandlers.GetSkippedSongs)      // Get songs mostly skipped rather than played
+	playlist.POST("/undo", playlistHandlers.UndoLastPlay)          // Undo last play
+	playlist.POST("/plays/import", playlistHandlers.ImportPlays)   // Backfill history from a listen log
+
+	playlist.GET("/queue", playlistHandlers.GetQueue)               // Get queued songs
+	playlist.POST("/queue/extend", playlistHandlers.ExtendQueue)    // Append recommended songs to the queue
+	playlist.POST("/queue/next", playlistHandlers.NextSong)         // Play the next song (queue, then shuffle/repeat order)
+	playlist.POST("/queue/previous", playlistHandlers.PreviousSong) // Go back to the previously played song
+	playlist.GET("/queue/player", playlistHandlers.GetPlayerStatus) // Current song, shuffle and repeat settings
+	playlist.PUT("/queue/mode", playlistHandlers.SetPlayerMode)     // Set {"shuffle", "repeat": "off|one|all"}
+	playlist.POST("/queue/:songId", playlistHandlers.EnqueueSong)   // Add a song to the queue
+	playlist.DELETE("/queue", playlistHandlers.ClearQueue)          // Clear the queue
+
+	playlist.PATCH("/songs/:songId", playlistHandlers.UpdateSong)           // Edit a song's metadata
+	playlist.POST("/songs/:songId/rate", playlistHandlers.RateSong)         // Rate a song
+	playlist.DELETE("/songs/:songId/rating", playlistHandlers.UnrateSong)   // Remove a song's rating
+	playlist.PUT("/songs/:songId/note", playlistHandlers.SetSongNote)       // Attach a note to a song
+	playlist.PUT("/songs/:songId/artwork", playlistHandlers.SetSongArtwork) // Set a song's artwork and source URLs
+	playlist.GET("/rating/:rating", playlistHandlers.GetSongsByRating)      // Get songs by rating
+	playlist.GET("/ratings", playlistHandlers.GetSongsByRatings)            // Get songs matching several ratings
+	playlist.GET("/rating-range", playlistHandlers.GetSongsByRatingRange)   // Get songs within a rating range
+	playlist.GET("/filter", playlistHandlers.FilterSongs)                   // Get songs matching combined criteria
+
+	playlist.POST("/tag/bulk", playlistHandlers.BulkTagSongs)       // Tag every song matching a filter
+	playlist.POST("/rate/bulk", playlistHandlers.BulkRateSongs)     // Rate a list of songs (?dryRun=true previews)
+	playlist.POST("/delete/bulk", playlistHandlers.BulkDeleteSongs) // Delete a list of songs (?dryRun=true previews)
+	playlist.POST("/deduplicate", playlistHandlers.Deduplicate)     // Remove repeated title and artist pairs (?dryRun=true previews)
+	playlist.GET("/tags/:tag", playlistHandlers.GetSongsByTag)      // Get songs carrying a tag
+	playlist.GET("/distinct", playlistHandlers.GetDistinctValues)   // Get distinct values of a field with song counts
+
+	playlist.GET("/search", playlistHandlers.SearchSong)                            // Search by ID or title
+	playlist.GET("/search/all", playlistHandlers.SearchAll)                         // Ranked search across every song field
+	playlist.GET("/songs/:songId/detail", playlistHandlers.GetSongDetail)           // Get song with explorer path and similar songs
+	playlist.GET("/songs/:songId/neighbors", playlistHandlers.GetSongNeighbors)     // Get songs around a song in playlist order
+	playlist.GET("/songs/:songId/stats", playlistHandlers.GetSongStats)             // Get a song's play, skip and rating stats
+	playlist.GET("/songs/:songId/same-mood", playlistHandlers.GetSongsWithSameMood) // Get other songs sharing a song's mood
+
+	playlist.POST("/sort", playlistHandlers.SortPlaylist) // Sort playlist
+	playlist.GET("/sort", playlistHandlers.GetSortState)  // Current sort criteria, or custom after manual reordering
+	playlist.GET("/view", playlistHandlers.GetSortedView) // View playlist sorted by several criteria without reordering it
+
+	playlist.GET("/history", playlistHandlers.GetPlaybackHistory)                         // Get playback history
+	playlist.POST("/history/prune", playlistHandlers.PruneHistory)                        // Drop history entries for deleted songs
+	playlist.GET("/recent", playlistHandlers.GetRecentlyAdded)                            // Get most recently added songs
+	playlist.GET("/sessions", playlistHandlers.GetSessions)                               // Get history grouped into listening sessions
+	playlist.GET("/activity", playlistHandlers.GetActivityLog)                            // Get recent playlist changes
+	playlist.GET("/integrity", playlistHandlers.VerifyIntegrity)                          // Check indexes against the playlist
+	playlist.POST("/repair", playlistHandlers.RepairIndexes)                              // Rebuild indexes from the playlist
+	playlist.POST("/compact", playlistHandlers.Compact)                                   // Shrink indexes to fit the playlist
+	playlist.GET("/recommendations", playlistHandlers.GetRecommendations)                 // Get smart recommendations
+	playlist.PUT("/recommendations/mode", playlistHandlers.SetRecommendationMode)         // Set similarity mode
+	playlist.GET("/recommendations/benchmark", playlistHandlers.BenchmarkRecommendations) // Compare indexed and scanned similarity lookups
+
+	playlist.GET("/stats", playlistHandlers.GetStats)                       // Get playlist statistics
+	playlist.GET("/stats/today", playlistHandlers.GetTodayStats)            // Get plays since local midnight
+	playlist.GET("/stats/playback", playlistHandlers.GetPlaybackStatsRange) // Get playback stats for a time range
+	playlist.GET("/stats/by-genre", playlistHandlers.GetGenreStatistics)    // Get per-genre aggregates
+	playlist.GET("/stats/bpm", playlistHandlers.GetBPMHistogram)            // Get song counts per BPM range
+	playlist.GET("/stats/duration", playlistHandlers.GetDurationHistogram)  // Get song counts per duration band
+	playlist.GET("/flow", playlistHandlers.GetFlowAnalysis)                 // Flag jarring transitions between adjacent songs
+	playlist.POST("/order/bpm-ramp", playlistHandlers.OrderByBPMRamp)       // Reorder so BPM rises gradually
+	playlist.POST("/generate/mood-arc", playlistHandlers.GenerateMoodArc)   // Build a journey through moods without changing the playlist
+	playlist.GET("/mix", playlistHandlers.GenerateMix)                      // Build a DJ mix with small BPM steps and no mood clashes
+	playlist.GET("/top", playlistHandlers.GetTopSongs)                      // Get the longest, most played or top rated songs
+	playlist.GET("/benchmark", playlistHandlers.BenchmarkSort)              // Benchmark sorting algorithms
+
+	playlist.POST("/sample-data", playlistHandlers.LoadSampleData)     // Load sample data for demo
+	playlist.POST("/import/m3u", playlistHandlers.ImportM3U)           // Import songs from an M3U playlist
+	playlist.POST("/import", playlistHandlers.ImportCSV)               // Import songs from a CSV upload
+	playlist.POST("/import/csv", playlistHandlers.ImportCSV)           // Import songs from CSV (?stream=true for progress events)
+	playlist.POST("/import/template", playlistHandlers.ImportTemplate) // Import songs from a playlist template
+	playlist.GET("/export", playlistHandlers.ExportPlaylist)           // Download the playlist as M3U/M3U8 or JSON
+	playlist.GET("/export/template", playlistHandlers.ExportTemplate)  // Export playlist structure without personal stats
+	playlist.GET("/tracklist", playlistHandlers.GetTracklist)          // Numbered plain-text tracklist for sharing
+
+	playlist.POST("/save", playlistHandlers.SavePlaylist) // Save songs, ratings and history to disk
+	playlist.POST("/load", playlistHandlers.LoadPlaylist) // Replace the playlist with its saved snapshot
+}
+
+// registerExplorerRoutes mounts the explorer, dashboard, artist and album endpoints of one playlist
+func registerExplorerRoutes(api *echo.Group, playlistHandlers *PlaylistHandlers) {
 	explorer := api.Group("/explorer")
 	{
 		explorer.GET("/genres", playlistHandlers.GetGenres)                                                 // Get all genres
 		explorer.GET("/genres/html", playlistHandlers.GetGenresHTML)                                        // Get all genres as HTML for HTMX
 		explorer.GET("/genres/:genre/subgenres", playlistHandlers.GetSubgenres)                             // Get subgenres for genre
+		explorer.PUT("/genres/:genre/rename", playlistHandlers.RenameGenre)                                 // Move every song in a genre to a new name
 		explorer.GET("/genres/:genre/subgenres/:subgenre/moods", playlistHandlers.GetMoods)                 // Get moods for genre+subgenre
 		explorer.GET("/genres/:genre/subgenres/:subgenre/moods/:mood/artists", playlistHandlers.GetArtists) // Get artists for genre+subgenre+mood
 		explorer.GET("/songs", playlistHandlers.GetSongsByExplorer)                                         // Get songs by hierarchical path
+		explorer.GET("/tree", playlistHandlers.GetExplorerTree)                                             // Get the full tree as nested JSON
+		explorer.GET("/interleave", playlistHandlers.GetInterleavedGenres)                                  // Alternate songs from two genres
 	}
 
-	api.GET("/dashboard", playlistHandlers.GetDashboard)          // Get comprehensive dashboard snapshot
-	api.GET("/dashboard/html", playlistHandlers.GetDashboardHTML) // Get dashboard as HTML for HTMX
+	api.GET("/dashboard", playlistHandlers.GetDashboard)              // Get comprehensive dashboard snapshot
+	api.GET("/dashboard/html", playlistHandlers.GetDashboardHTML)     // Get dashboard as HTML for HTMX
+	api.GET("/stats/timeline", playlistHandlers.GetListeningTimeline) // Plays per day, top artists per week and listening trends
 
-	return e
+	api.GET("/artists", playlistHandlers.GetAllArtists)             // List distinct artists with sorting and pagination
+	api.GET("/artists/:artist", playlistHandlers.GetArtistProfile)  // Get an artist's songs and aggregate stats
+	api.GET("/albums", playlistHandlers.GetAlbums)                  // List albums with song count, duration and rating
+	api.GET("/albums/:album/songs", playlistHandlers.GetAlbumSongs) // Get an album's songs and stats
+	api.POST("/albums/:album/play", playlistHandlers.PlayAlbum)     // Queue an album's songs in order
+}
+
+// registerPlaybackRoutes mounts the now-playing controls of one playlist's player
+func registerPlaybackRoutes(api *echo.Group, playlistHandlers *PlaylistHandlers) {
+	playback := api.Group("/playback")
+	{
+		playback.GET("", playlistHandlers.GetNowPlaying)          // Get the current song, state and position
+		playback.POST("/pause", playlistHandlers.PausePlayback)   // Pause the current song
+		playback.POST("/resume", playlistHandlers.ResumePlayback) // Resume from the paused position
+		playback.POST("/seek", playlistHandlers.SeekPlayback)     // Jump to {"position": seconds}
+	}
+}
+
+// registerSmartPlaylistRoutes mounts the rule-based playlists defined over one playlist's songs
+func registerSmartPlaylistRoutes(api *echo.Group, playlistHandlers *PlaylistHandlers) {
+	smart := api.Group("/smart-playlists")
+	{
+		smart.GET("", playlistHandlers.ListSmartPlaylists)              // List smart playlists with their song counts
+		smart.POST("", playlistHandlers.CreateSmartPlaylist)            // Create from {"name", "query"} or {"name", "match", "rules"}
+		smart.GET("/:smartId", playlistHandlers.GetSmartPlaylist)       // Get the rules and the songs meeting them
+		smart.PUT("/:smartId", playlistHandlers.UpdateSmartPlaylist)    // Replace the name, match mode and rules
+		smart.DELETE("/:smartId", playlistHandlers.DeleteSmartPlaylist) // Delete, leaving the songs in the playlist
+	}
+}
+
+// registerIntegrationRoutes mounts the imports from external music services into one playlist
+func registerIntegrationRoutes(api *echo.Group, playlistHandlers *PlaylistHandlers) {
+	integrations := api.Group("/integrations")
+	{
+		integrations.POST("/spotify/import", playlistHandlers.ImportFromIntegration) // Import a Spotify playlist {"url"} or Last.fm top tracks {"lastfm_user"} (?stream=true for progress events)
+	}
 }
 
 func (s *Server) HelloWorldHandler(c echo.Context) error {
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"time"
+)
+
+// DefaultActivityLogSize is the number of recent operations kept when EngineConfig.ActivityLogSize is unset
+const DefaultActivityLogSize = 100
+
+// ActivityOp names a kind of engine operation recorded in the activity log
+type ActivityOp string
+
+const (
+	ActivityAdd            ActivityOp = "add"
+	ActivityDelete         ActivityOp = "delete"
+	ActivityBulkDelete     ActivityOp = "bulk_delete"
+	ActivityDeduplicate    ActivityOp = "deduplicate"
+	ActivityRestore        ActivityOp = "restore"
+	ActivityEmptyTrash     ActivityOp = "empty_trash"
+	ActivityMove           ActivityOp = "move"
+	ActivityReverse        ActivityOp = "reverse"
+	ActivitySort           ActivityOp = "sort"
+	ActivityPlay           ActivityOp = "play"
+	ActivityPlayerMode     ActivityOp = "player_mode"
+	ActivitySkip           ActivityOp = "skip"
+	ActivityUndo           ActivityOp = "undo"
+	ActivityPruneHistory   ActivityOp = "prune_history"
+	ActivityImportPlays    ActivityOp = "import_plays"
+	ActivityRate           ActivityOp = "rate"
+	ActivityBulkRate       ActivityOp = "bulk_rate"
+	ActivityUnrate         ActivityOp = "unrate"
+	ActivityNote           ActivityOp = "note"
+	ActivityArtwork        ActivityOp = "artwork"
+	ActivityEdit           ActivityOp = "edit"
+	ActivityTag            ActivityOp = "tag"
+	ActivityEnqueue        ActivityOp = "enqueue"
+	ActivityPlayAlbum      ActivityOp = "play_album"
+	ActivityExtendQueue    ActivityOp = "extend_queue"
+	ActivityClearQueue     ActivityOp = "clear_queue"
+	ActivityRename         ActivityOp = "rename"
+	ActivityRenameCategory ActivityOp = "rename_category"
+	ActivitySimilarityMode ActivityOp = "similarity_mode"
+	ActivitySmartPlaylist  ActivityOp = "smart_playlist"
+	ActivityClear          ActivityOp = "clear"
+	ActivityRestoreState   ActivityOp = "restore_state"
+)
+
+// Activity is a single entry in the engine's activity log
+// SongTitle is empty for operations that aren't about one song
+type Activity struct {
+	Timestamp time.Time  `json:"timestamp"`
+	Op        ActivityOp `json:"op"`
+	SongTitle string     `json:"song_title,omitempty"`
+}
+
+// activityLog is a fixed-capacity ring buffer of recent activities
+// Once full, each new entry overwrites the oldest so memory stays constant
+// Time Complexity: O(1) per record
+// Space Complexity: O(c) where c is the capacity
+type activityLog struct {
+	entries  []Activity
+	next     int // Index the next entry is written to
+	size     int
+	version  uint64 // Total activities ever recorded; caches of song state compare it to spot changes
+	now      func() time.Time
+	onRecord func() // Called after every recorded activity, nil when unset
+}
+
+// newActivityLog creates an empty activity log holding at most capacity entries
+// Time Complexity: O(c)
+// Space Complexity: O(c)
+func newActivityLog(capacity int) *activityLog {
+	if capacity <= 0 {
+		capacity = DefaultActivityLogSize
+	}
+	return &activityLog{
+		entries: make([]Activity, capacity),
+		now:     time.Now,
+	}
+}
+
+// record appends an activity, overwriting the oldest entry when the log is full
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (al *activityLog) record(op ActivityOp, songTitle string) {
+	al.entries[al.next] = Activity{
+		Timestamp: al.now(),
+		Op:        op,
+		SongTitle: songTitle,
+	}
+
+	al.next = (al.next + 1) % len(al.entries)
+	if al.size < len(al.entries) {
+		al.size++
+	}
+	al.version++
+
+	if al.onRecord != nil {
+		al.onRecord()
+	}
+}
+
+// recent returns up to limit activities, newest first
+// A non-positive limit returns every retained activity
+// Time Complexity: O(k) where k is the number of returned activities
+// Space Complexity: O(k)
+func (al *activityLog) recent(limit int) []Activity {
+	if limit <= 0 || limit > al.size {
+		limit = al.size
+	}
+
+	activities := make([]Activity, 0, limit)
+	for i := 1; i <= limit; i++ {
+		index := (al.next - i + len(al.entries)) % len(al.entries)
+		activities = append(activities, al.entries[index])
+	}
+
+	return activities
+}
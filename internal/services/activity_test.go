@@ -0,0 +1,108 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActivityLog_RingBuffer(t *testing.T) {
+	log := newActivityLog(3)
+
+	if got := log.recent(0); len(got) != 0 {
+		t.Errorf("Expected empty log, got %v", got)
+	}
+
+	for _, title := range []string{"A", "B", "C", "D", "E"} {
+		log.record(ActivityAdd, title)
+	}
+
+	// Only the last three survive, newest first
+	got := log.recent(0)
+	want := []string{"E", "D", "C"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d activities, got %d", len(want), len(got))
+	}
+	for i, title := range want {
+		if got[i].SongTitle != title {
+			t.Errorf("Activity %d = %s, want %s", i, got[i].SongTitle, title)
+		}
+	}
+
+	if got := log.recent(2); len(got) != 2 || got[0].SongTitle != "E" {
+		t.Errorf("Expected the 2 newest activities, got %v", got)
+	}
+	if got := log.recent(10); len(got) != 3 {
+		t.Errorf("Limit above size should return every entry, got %d", len(got))
+	}
+}
+
+func TestActivityLog_Timestamps(t *testing.T) {
+	log := newActivityLog(2)
+	fixed := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	log.now = func() time.Time { return fixed }
+
+	log.record(ActivityPlay, "Song")
+	if got := log.recent(1)[0]; !got.Timestamp.Equal(fixed) || got.Op != ActivityPlay {
+		t.Errorf("Unexpected activity %+v", got)
+	}
+
+	if len(newActivityLog(0).entries) != DefaultActivityLogSize {
+		t.Errorf("Expected default capacity %d for non-positive size", DefaultActivityLogSize)
+	}
+}
+
+func TestGetActivityLog(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	songID, _ := engine.AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album 2", "Jazz", "Smooth Jazz", "Relaxed", 300, 90)
+	engine.PlaySong(0)
+	engine.RateSong(songID, 5)
+	engine.MoveSong(0, 1)
+	engine.DeleteSongByID(songID)
+	engine.ReversePlaylist()
+
+	// Failed operations are not logged
+	engine.RateSong("missing", 3)
+	engine.DeleteSong(10)
+
+	got := engine.GetActivityLog(0)
+	want := []struct {
+		op    ActivityOp
+		title string
+	}{
+		{ActivityReverse, ""},
+		{ActivityDelete, "Song 1"},
+		{ActivityMove, "Song 1"},
+		{ActivityRate, "Song 1"},
+		{ActivityPlay, "Song 1"},
+		{ActivityAdd, "Song 2"},
+		{ActivityAdd, "Song 1"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d activities, got %d: %v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if got[i].Op != w.op || got[i].SongTitle != w.title {
+			t.Errorf("Activity %d = %s %q, want %s %q", i, got[i].Op, got[i].SongTitle, w.op, w.title)
+		}
+	}
+}
+
+func TestGetActivityLog_Cap(t *testing.T) {
+	config := DefaultEngineConfig()
+	config.ActivityLogSize = 5
+	engine := NewPlaylistEngineWithConfig("Test", config)
+
+	for i := 0; i < 8; i++ {
+		engine.AddSong(string(rune('A'+i)), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	}
+
+	got := engine.GetActivityLog(0)
+	if len(got) != 5 {
+		t.Fatalf("Expected log capped at 5 entries, got %d", len(got))
+	}
+	if got[0].SongTitle != "H" || got[4].SongTitle != "D" {
+		t.Errorf("Expected newest H through oldest retained D, got %s..%s", got[0].SongTitle, got[4].SongTitle)
+	}
+}
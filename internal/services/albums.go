@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"src/internal/models"
+	"strings"
+)
+
+// ErrAmbiguousAlbum is returned when an album is looked up by name alone and several artists have an album by that name
+var ErrAmbiguousAlbum = errors.New("album name is shared by several artists")
+
+// AlbumSummary aggregates the songs of one album
+// Albums are grouped by name and artist case-insensitively, keeping the spellings of the first song added,
+// so albums sharing a name such as "Greatest Hits" stay apart
+type AlbumSummary struct {
+	Album         string `json:"album"`
+	Artist        string `json:"artist"`
+	SongCount     int    `json:"song_count"`
+	TotalDuration int    `json:"total_duration"` // in seconds
+	RatedSongs    int    `json:"rated_songs"`
+	// Average over the rated songs only, 0 when none is rated
+	AverageRating float64 `json:"average_rating"`
+}
+
+// albumKey identifies an album in the album index by its normalized name and artist
+type albumKey struct {
+	album  string
+	artist string
+}
+
+// normalizeAlbum returns the normalized form of an album name used in album keys
+// Time Complexity: O(m) where m is the name length
+// Space Complexity: O(m)
+func normalizeAlbum(album string) string {
+	return strings.ToLower(strings.TrimSpace(album))
+}
+
+// albumKeyFor returns the album index key of a song
+// Time Complexity: O(m) where m is the length of the album and artist names
+// Space Complexity: O(m)
+func albumKeyFor(song *models.Song) albumKey {
+	return albumKey{album: normalizeAlbum(song.Album), artist: normalizeArtist(song.Artist)}
+}
+
+// indexAlbum appends a song to its album's bucket; songs without an album aren't indexed
+// Time Complexity: O(1) amortized
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) indexAlbum(song *models.Song) {
+	if key := albumKeyFor(song); key.album != "" {
+		pe.albumIndex[key] = append(pe.albumIndex[key], song)
+	}
+}
+
+// unindexAlbum removes a song from its album's bucket, dropping the bucket once empty
+// Time Complexity: O(k) where k is the number of songs on the album
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) unindexAlbum(song *models.Song) {
+	removeFromBucket(pe.albumIndex, albumKeyFor(song), song.ID)
+}
+
+// findAlbum returns the songs of the album with the given name by artist
+// An empty artist matches the album when only one artist has an album by that name
+// Time Complexity: O(1) average with an artist, O(a) without where a is the number of albums
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) findAlbum(album, artist string) ([]*models.Song, error) {
+	name := normalizeAlbum(album)
+	if artist != "" {
+		if songs, ok := pe.albumIndex[albumKey{album: name, artist: normalizeArtist(artist)}]; ok {
+			return songs, nil
+		}
+		return nil, fmt.Errorf("album '%s' by '%s' not found", album, artist)
+	}
+
+	var found []*models.Song
+	var artists []string
+	for key, songs := range pe.albumIndex {
+		if key.album == name {
+			found = songs
+			artists = append(artists, songs[0].Artist)
+		}
+	}
+	switch len(artists) {
+	case 0:
+		return nil, fmt.Errorf("album '%s' not found", album)
+	case 1:
+		return found, nil
+	default:
+		sort.Strings(artists)
+		return nil, fmt.Errorf("%w: '%s' is by %s, pass the artist", ErrAmbiguousAlbum, album, strings.Join(artists, ", "))
+	}
+}
+
+// summarizeAlbum aggregates the songs of one album bucket
+// Time Complexity: O(k) where k is the number of songs on the album
+// Space Complexity: O(1)
+func summarizeAlbum(songs []*models.Song) AlbumSummary {
+	summary := AlbumSummary{Album: songs[0].Album, Artist: songs[0].Artist, SongCount: len(songs)}
+	ratingTotal := 0
+	for _, song := range songs {
+		summary.TotalDuration += song.Duration
+		if song.Rating > 0 {
+			summary.RatedSongs++
+			ratingTotal += song.Rating
+		}
+	}
+	if summary.RatedSongs > 0 {
+		summary.AverageRating = float64(ratingTotal) / float64(summary.RatedSongs)
+	}
+	return summary
+}
+
+// GetAlbums summarizes every album in the playlist sorted A-Z by name, then artist; songs without an album are left out
+// Time Complexity: O(n + a log a) where a is the number of albums, using the album index
+// Space Complexity: O(a)
+func (pe *PlaylistEngine) GetAlbums() []AlbumSummary {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	albums := make([]AlbumSummary, 0, len(pe.albumIndex))
+	for _, songs := range pe.albumIndex {
+		albums = append(albums, summarizeAlbum(songs))
+	}
+
+	sort.Slice(albums, func(i, j int) bool {
+		if a, b := strings.ToLower(albums[i].Album), strings.ToLower(albums[j].Album); a != b {
+			return a < b
+		}
+		return strings.ToLower(albums[i].Artist) < strings.ToLower(albums[j].Artist)
+	})
+	return albums
+}
+
+// GetAlbum returns an album's summary and its songs in the order they were added
+// artist picks between albums sharing the name and may be empty when only one artist has an album by that name
+// Time Complexity: O(k) where k is the number of songs on the album, plus O(a) for a albums without an artist
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) GetAlbum(album, artist string) (AlbumSummary, []*models.Song, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs, err := pe.findAlbum(album, artist)
+	if err != nil {
+		return AlbumSummary{}, nil, err
+	}
+	return summarizeAlbum(songs), cloneSongs(songs), nil
+}
+
+// PlayAlbum appends every song of an album to the back of the play queue in the order they were added
+// artist picks between albums sharing the name as in GetAlbum; returns the queued songs
+// Time Complexity: O(k) where k is the number of songs on the album, plus O(a) for a albums without an artist
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) PlayAlbum(album, artist string) ([]*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	songs, err := pe.findAlbum(album, artist)
+	if err != nil {
+		return nil, err
+	}
+
+	queued := append([]*models.Song(nil), songs...)
+	for _, song := range queued {
+		if err := pe.playQueue.Enqueue(song); err != nil {
+			return nil, err
+		}
+	}
+
+	pe.activity.record(ActivityPlayAlbum, songs[0].Album)
+	return cloneSongs(queued), nil
+}
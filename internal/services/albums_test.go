@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func newAlbumTestEngine() *PlaylistEngine {
+	engine := NewPlaylistEngine("Albums")
+	songs := []struct {
+		title, artist, album string
+		duration, rating     int
+	}{
+		{"Speak to Me", "Pink Floyd", "The Dark Side of the Moon", 90, 4},
+		{"Breathe", "Pink Floyd", "The Dark Side of the Moon", 163, 0},
+		{"Time", "Pink Floyd", "the dark side of the moon ", 413, 5},
+		{"Come Together", "The Beatles", "Abbey Road", 259, 3},
+		{"Single", "Somebody", "", 200, 0},
+	}
+	for _, s := range songs {
+		id, _ := engine.AddSong(s.title, s.artist, s.album, "Rock", "Progressive", "Calm", s.duration, 100)
+		if s.rating > 0 {
+			engine.RateSong(id, s.rating)
+		}
+	}
+	return engine
+}
+
+func TestGetAlbums(t *testing.T) {
+	engine := newAlbumTestEngine()
+
+	albums := engine.GetAlbums()
+	if len(albums) != 2 || albums[0].Album != "Abbey Road" {
+		t.Fatalf("Expected 2 albums A-Z without the album-less song, got %+v", albums)
+	}
+
+	dark := albums[1]
+	if dark.Album != "The Dark Side of the Moon" || dark.SongCount != 3 || dark.TotalDuration != 666 {
+		t.Errorf("Expected the spellings to be merged into one album, got %+v", dark)
+	}
+	if dark.RatedSongs != 2 || dark.AverageRating != 4.5 {
+		t.Errorf("Expected the average over rated songs only, got %+v", dark)
+	}
+	if dark.Artist != "Pink Floyd" {
+		t.Errorf("Expected Pink Floyd, got %q", dark.Artist)
+	}
+	if engine.GetPlaylistStats()["unique_albums"] != 2 {
+		t.Errorf("Expected 2 unique albums in stats, got %v", engine.GetPlaylistStats()["unique_albums"])
+	}
+}
+
+func TestGetAlbum_FollowsEditsAndDeletes(t *testing.T) {
+	engine := newAlbumTestEngine()
+
+	_, songs, err := engine.GetAlbum("THE DARK SIDE OF THE MOON", "")
+	if err != nil || len(songs) != 3 || songs[0].Title != "Speak to Me" || songs[2].Title != "Time" {
+		t.Fatalf("Expected the album's songs in the order added, got %v, %v", songs, err)
+	}
+
+	// Moving a Pink Floyd song to Abbey Road makes a second Abbey Road, by Pink Floyd
+	album := "Abbey Road"
+	if _, err := engine.UpdateSong(songs[1].ID, SongUpdate{Album: &album}); err != nil {
+		t.Fatalf("Expected the edit to succeed, got %v", err)
+	}
+	engine.DeleteSongByID(songs[0].ID)
+
+	summary, remaining, _ := engine.GetAlbum("The Dark Side of the Moon", "")
+	if len(remaining) != 1 || summary.SongCount != 1 || summary.TotalDuration != 413 {
+		t.Errorf("Expected only Time left on the album, got %+v", summary)
+	}
+	if summary, _, _ := engine.GetAlbum("abbey road", "pink floyd"); summary.SongCount != 1 || summary.Artist != "Pink Floyd" {
+		t.Errorf("Expected the moved song on Pink Floyd's Abbey Road, got %+v", summary)
+	}
+	if summary, _, _ := engine.GetAlbum("abbey road", "The Beatles"); summary.SongCount != 1 {
+		t.Errorf("Expected The Beatles' Abbey Road unchanged, got %+v", summary)
+	}
+	if _, _, err := engine.GetAlbum("Abbey Road", ""); !errors.Is(err, ErrAmbiguousAlbum) {
+		t.Errorf("Expected ErrAmbiguousAlbum without an artist, got %v", err)
+	}
+
+	// Changing the artist moves the song to that artist's album
+	artist := "The Beatles"
+	if _, err := engine.UpdateSong(songs[1].ID, SongUpdate{Artist: &artist}); err != nil {
+		t.Fatalf("Expected the edit to succeed, got %v", err)
+	}
+	if summary, _, err := engine.GetAlbum("Abbey Road", ""); err != nil || summary.SongCount != 2 {
+		t.Errorf("Expected both songs on The Beatles' Abbey Road, got %+v, %v", summary, err)
+	}
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes, got %v", issues)
+	}
+
+	if _, _, err := engine.GetAlbum("Unknown", ""); err == nil {
+		t.Error("Expected an error for an unknown album")
+	}
+}
+
+func TestPlayAlbum(t *testing.T) {
+	engine := newAlbumTestEngine()
+
+	queued, err := engine.PlayAlbum("the dark side of the moon", "")
+	if err != nil || len(queued) != 3 {
+		t.Fatalf("Expected 3 queued songs, got %v, %v", queued, err)
+	}
+
+	queue := engine.GetQueue()
+	if len(queue) != 3 || queue[0].Title != "Speak to Me" || queue[1].Title != "Breathe" || queue[2].Title != "Time" {
+		t.Errorf("Expected the album queued in order, got %v", queue)
+	}
+	if _, err := engine.PlayAlbum("Unknown", ""); err == nil {
+		t.Error("Expected an error for an unknown album")
+	}
+}
+
+func TestAlbumsSharingAName(t *testing.T) {
+	engine := NewPlaylistEngine("Albums")
+	engine.AddSong("Bohemian Rhapsody", "Queen", "Greatest Hits", "Rock", "Classic Rock", "Epic", 354, 72)
+	engine.AddSong("Don't Stop Me Now", "Queen", "Greatest Hits", "Rock", "Classic Rock", "Happy", 209, 156)
+	engine.AddSong("Dancing Queen", "ABBA", "Greatest Hits", "Pop", "Disco", "Happy", 231, 101)
+
+	albums := engine.GetAlbums()
+	if len(albums) != 2 || albums[0].Artist != "ABBA" || albums[1].Artist != "Queen" || albums[1].SongCount != 2 {
+		t.Fatalf("Expected separate Greatest Hits albums for ABBA and Queen, got %+v", albums)
+	}
+
+	if _, err := engine.PlayAlbum("Greatest Hits", ""); !errors.Is(err, ErrAmbiguousAlbum) {
+		t.Errorf("Expected ErrAmbiguousAlbum without an artist, got %v", err)
+	}
+	queued, err := engine.PlayAlbum("greatest hits", "QUEEN")
+	if err != nil || len(queued) != 2 || queued[0].Artist != "Queen" || queued[1].Artist != "Queen" {
+		t.Errorf("Expected only Queen's songs queued, got %v, %v", queued, err)
+	}
+	if _, err := engine.PlayAlbum("Greatest Hits", "Eagles"); err == nil || errors.Is(err, ErrAmbiguousAlbum) {
+		t.Errorf("Expected not found for an artist without the album, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"src/internal/models"
+	"strings"
+)
+
+// ArtistDominantMoods is how many of an artist's most common moods a profile lists
+const ArtistDominantMoods = 3
+
+// MoodCount counts the songs of one mood
+type MoodCount struct {
+	Mood  string `json:"mood"`
+	Songs int    `json:"songs"`
+}
+
+// ArtistProfile aggregates every song by one artist across the whole playlist
+type ArtistProfile struct {
+	Artist        string `json:"artist"` // Spelling of the first song added
+	SongCount     int    `json:"song_count"`
+	TotalDuration int    `json:"total_duration"` // in seconds
+	PlayCount     int    `json:"play_count"`
+	RatedSongs    int    `json:"rated_songs"`
+	// Average over the rated songs only, 0 when none is rated
+	AverageRating float64 `json:"average_rating"`
+	// Most common moods first, ties A-Z, at most ArtistDominantMoods
+	DominantMoods []MoodCount `json:"dominant_moods"`
+	// Nil until one of the artist's songs has been played; ties go to the song added first
+	MostPlayed *models.Song   `json:"most_played"`
+	Albums     []string       `json:"albums"` // Distinct albums in the order their first track was added
+	Songs      []*models.Song `json:"songs"`  // In the order they were added
+}
+
+// GetArtistProfile returns the aggregate stats and songs of an artist, compared case-insensitively
+// Time Complexity: O(k + m log m) where k is the number of songs by the artist and m their distinct moods
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) GetArtistProfile(artist string) (*ArtistProfile, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs, ok := pe.artistIndex[normalizeArtist(artist)]
+	if !ok {
+		return nil, fmt.Errorf("artist '%s' not found", artist)
+	}
+
+	profile := &ArtistProfile{
+		Artist:    songs[0].Artist,
+		SongCount: len(songs),
+		Albums:    []string{},
+		Songs:     cloneSongs(songs),
+	}
+	moods := make(map[string]*MoodCount)
+	albums := make(map[string]bool)
+	ratingTotal := 0
+	for i, song := range songs {
+		profile.TotalDuration += song.Duration
+		profile.PlayCount += song.PlayCount
+		if song.Rating > 0 {
+			profile.RatedSongs++
+			ratingTotal += song.Rating
+		}
+		if song.PlayCount > 0 && (profile.MostPlayed == nil || song.PlayCount > profile.MostPlayed.PlayCount) {
+			profile.MostPlayed = profile.Songs[i]
+		}
+		if key := strings.ToLower(strings.TrimSpace(song.Mood)); key != "" {
+			if mood, ok := moods[key]; ok {
+				mood.Songs++
+			} else {
+				moods[key] = &MoodCount{Mood: song.Mood, Songs: 1}
+			}
+		}
+		if key := normalizeAlbum(song.Album); key != "" && !albums[key] {
+			albums[key] = true
+			profile.Albums = append(profile.Albums, song.Album)
+		}
+	}
+	if profile.RatedSongs > 0 {
+		profile.AverageRating = float64(ratingTotal) / float64(profile.RatedSongs)
+	}
+	profile.DominantMoods = rankMoods(moods, ArtistDominantMoods)
+
+	return profile, nil
+}
+
+// rankMoods orders moods by song count, most first and then by name, keeping the top limit
+// Time Complexity: O(m log m) where m is the number of moods
+// Space Complexity: O(m)
+func rankMoods(moods map[string]*MoodCount, limit int) []MoodCount {
+	ranked := make([]MoodCount, 0, len(moods))
+	for _, mood := range moods {
+		ranked = append(ranked, *mood)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Songs != ranked[j].Songs {
+			return ranked[i].Songs > ranked[j].Songs
+		}
+		return strings.ToLower(ranked[i].Mood) < strings.ToLower(ranked[j].Mood)
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
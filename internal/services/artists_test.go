@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetArtistProfile(t *testing.T) {
+	engine := NewPlaylistEngine("Artists")
+	songs := []struct {
+		title, artist, album, mood string
+		rating, plays              int
+	}{
+		{"Creep", "Radiohead", "Pablo Honey", "Sad", 4, 1},
+		{"Karma Police", "radiohead ", "OK Computer", "Melancholic", 5, 3},
+		{"No Surprises", "Radiohead", "OK Computer", "melancholic", 0, 3},
+		{"Airbag", "Radiohead", "OK Computer", "Energetic", 0, 0},
+		{"Yellow", "Coldplay", "Parachutes", "Sad", 3, 7},
+	}
+	for _, s := range songs {
+		id, _ := engine.AddSong(s.title, s.artist, s.album, "Rock", "Alternative", s.mood, 240, 100)
+		if s.rating > 0 {
+			engine.RateSong(id, s.rating)
+		}
+		for i := 0; i < s.plays; i++ {
+			song, _ := engine.songLookup.Get(id)
+			engine.recordPlay(song, time.Now())
+		}
+	}
+
+	profile, err := engine.GetArtistProfile("RADIOHEAD")
+	if err != nil {
+		t.Fatalf("Expected a profile, got %v", err)
+	}
+	if profile.Artist != "Radiohead" || profile.SongCount != 4 || profile.PlayCount != 7 || profile.TotalDuration != 960 {
+		t.Errorf("Unexpected totals %+v", profile)
+	}
+	if profile.RatedSongs != 2 || profile.AverageRating != 4.5 {
+		t.Errorf("Expected the average over rated songs only, got %d rated at %v", profile.RatedSongs, profile.AverageRating)
+	}
+	if profile.MostPlayed == nil || profile.MostPlayed.Title != "Karma Police" {
+		t.Errorf("Expected the first of the tied most played songs, got %v", profile.MostPlayed)
+	}
+	if len(profile.DominantMoods) != 3 || profile.DominantMoods[0].Mood != "Melancholic" || profile.DominantMoods[0].Songs != 2 ||
+		profile.DominantMoods[1].Mood != "Energetic" {
+		t.Errorf("Expected moods ranked by count then name, got %v", profile.DominantMoods)
+	}
+	if len(profile.Albums) != 2 || profile.Albums[0] != "Pablo Honey" || len(profile.Songs) != 4 {
+		t.Errorf("Expected 2 albums and 4 songs, got %v and %d", profile.Albums, len(profile.Songs))
+	}
+
+	// An unplayed artist has no most played track
+	engine.AddSong("Song", "Newcomer", "", "Pop", "Mainstream", "Happy", 200, 120)
+	if profile, _ := engine.GetArtistProfile("Newcomer"); profile.MostPlayed != nil || len(profile.Albums) != 0 {
+		t.Errorf("Expected no most played track or album, got %+v", profile)
+	}
+	if _, err := engine.GetArtistProfile("Unknown"); err == nil {
+		t.Error("Expected an error for an unknown artist")
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"src/internal/models"
+	"strings"
+)
+
+// Bulk operations come in pairs: PreviewX computes exactly what X would change without mutating
+// anything, and X applies that preview, so a dry run always matches the real run
+
+// PreviewDeduplicate returns the songs Deduplicate would remove
+// A song is a duplicate when an earlier song in playlist order has the same title and artist, ignoring case
+// Time Complexity: O(n) where n is the playlist size
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) PreviewDeduplicate() []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.previewDeduplicate())
+}
+
+// previewDeduplicate implements PreviewDeduplicate for callers already holding pe.mu
+func (pe *PlaylistEngine) previewDeduplicate() []*models.Song {
+	seen := make(map[string]bool)
+	duplicates := make([]*models.Song, 0)
+
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		key := strings.ToLower(strings.TrimSpace(song.Title)) + "\x00" + normalizeArtist(song.Artist)
+		if seen[key] {
+			duplicates = append(duplicates, song)
+			continue
+		}
+		seen[key] = true
+	}
+
+	return duplicates
+}
+
+// Deduplicate removes every song that repeats an earlier song's title and artist, keeping the first
+// Returns the removed songs
+// Time Complexity: O(n) for the preview plus O(d) average removals where d is the number of duplicates
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) Deduplicate() []*models.Song {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	duplicates := pe.previewDeduplicate()
+	pe.removeSongs(duplicates, ActivityDeduplicate)
+	return cloneSongs(duplicates)
+}
+
+// PreviewDeleteSongs returns the songs DeleteSongs would remove, in the order given
+// Unknown and repeated IDs are reported in the error and otherwise ignored
+// Time Complexity: O(k) average where k is the number of IDs
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) PreviewDeleteSongs(songIDs []string) ([]*models.Song, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs, err := pe.previewDeleteSongs(songIDs)
+	return cloneSongs(songs), err
+}
+
+// previewDeleteSongs implements PreviewDeleteSongs for callers already holding pe.mu
+func (pe *PlaylistEngine) previewDeleteSongs(songIDs []string) ([]*models.Song, error) {
+	return pe.lookupSongs(songIDs)
+}
+
+// lookupSongs resolves song IDs in order, reporting unknown and repeated IDs in the error
+// Time Complexity: O(k) average where k is the number of IDs
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) lookupSongs(songIDs []string) ([]*models.Song, error) {
+	songs := make([]*models.Song, 0, len(songIDs))
+	seen := make(map[string]bool, len(songIDs))
+	var errs []error
+
+	for _, songID := range songIDs {
+		if seen[songID] {
+			errs = append(errs, fmt.Errorf("song ID '%s' is listed more than once", songID))
+			continue
+		}
+		seen[songID] = true
+
+		song, err := pe.songLookup.Get(songID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		songs = append(songs, song)
+	}
+
+	return songs, errors.Join(errs...)
+}
+
+// DeleteSongs removes every listed song that exists and returns them
+// The returned error lists IDs that were skipped
+// Time Complexity: O(k) average where k is the number of IDs
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) DeleteSongs(songIDs []string) ([]*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	songs, err := pe.previewDeleteSongs(songIDs)
+	pe.removeSongs(songs, ActivityBulkDelete)
+	return cloneSongs(songs), err
+}
+
+// PreviewRateSongs returns the songs RateSongs would change, skipping songs that already have the rating
+// Time Complexity: O(k) average where k is the number of IDs
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) PreviewRateSongs(songIDs []string, rating int) ([]*models.Song, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs, err := pe.previewRateSongs(songIDs, rating)
+	return cloneSongs(songs), err
+}
+
+// previewRateSongs implements PreviewRateSongs for callers already holding pe.mu
+func (pe *PlaylistEngine) previewRateSongs(songIDs []string, rating int) ([]*models.Song, error) {
+	if rating < 1 || rating > 5 {
+		return nil, fmt.Errorf("rating must be between 1 and 5")
+	}
+
+	songs, err := pe.lookupSongs(songIDs)
+	changed := songs[:0]
+	for _, song := range songs {
+		if song.Rating != rating {
+			changed = append(changed, song)
+		}
+	}
+	return changed, err
+}
+
+// RateSongs gives every listed song the same rating and returns the songs whose rating changed
+// An invalid rating changes nothing; unknown IDs are skipped and reported in the error
+// Time Complexity: O(k log n) where k is the number of IDs, for the rating tree updates
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) RateSongs(songIDs []string, rating int) ([]*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	songs, err := pe.previewRateSongs(songIDs, rating)
+	if songs == nil {
+		return nil, err
+	}
+
+	for _, song := range songs {
+		pe.applyRating(song, rating)
+	}
+	if len(songs) > 0 {
+		pe.activity.record(ActivityBulkRate, "")
+	}
+	return cloneSongs(songs), err
+}
+
+// removeSongs unlinks each song from the playlist and every index into the trash, recording a single activity entry
+// Time Complexity: O(k) average where k is the number of songs
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) removeSongs(songs []*models.Song, op ActivityOp) {
+	for _, song := range songs {
+		if _, err := pe.currentPlaylist.DeleteSongByID(song.ID); err != nil {
+			continue
+		}
+		pe.removeFromIndexes(song)
+		pe.moveToTrash(song)
+	}
+
+	if len(songs) == 0 {
+		return
+	}
+	pe.activity.record(op, "")
+	if pe.config.PruneHistoryOnDelete {
+		pe.pruneHistoryOfDeleted()
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestDeduplicate(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	// AddSong rejects exact duplicates, so make one by editing a song in place
+	dupID, _ := engine.AddSong("Song 1 (Live)", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	dup, _ := engine.songLookup.Get(dupID)
+	dup.Title = "song 1"
+
+	preview := engine.PreviewDeduplicate()
+	if len(preview) != 1 || preview[0].ID != dupID {
+		t.Fatalf("Expected the later copy of Song 1 in the preview, got %v", preview)
+	}
+	if engine.GetPlaylistSize() != 3 {
+		t.Errorf("Preview should not change the playlist, size is %d", engine.GetPlaylistSize())
+	}
+
+	removed := engine.Deduplicate()
+	if len(removed) != 1 || removed[0].ID != dupID || engine.GetPlaylistSize() != 2 {
+		t.Errorf("Expected the duplicate removed, got %v and size %d", removed, engine.GetPlaylistSize())
+	}
+	if _, err := engine.SearchSongByID(dupID); err == nil {
+		t.Error("Removed duplicate should be gone from the lookup")
+	}
+	if len(engine.Deduplicate()) != 0 {
+		t.Error("A second Deduplicate should find nothing")
+	}
+}
+
+func TestDeleteSongs(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	id1, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	id2, _ := engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 300, 120)
+	engine.AddSong("Song 3", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 400, 120)
+
+	ids := []string{id2, "missing", id1, id2}
+	preview, err := engine.PreviewDeleteSongs(ids)
+	if len(preview) != 2 || preview[0].ID != id2 || preview[1].ID != id1 {
+		t.Errorf("Expected Song 2 and Song 1 in the preview, got %v", preview)
+	}
+	if err == nil {
+		t.Error("Expected errors for the missing and repeated IDs")
+	}
+	if engine.GetPlaylistSize() != 3 || engine.GetTotalDuration() != 900 {
+		t.Errorf("Preview should not change the playlist, size %d duration %d", engine.GetPlaylistSize(), engine.GetTotalDuration())
+	}
+
+	deleted, _ := engine.DeleteSongs(ids)
+	if len(deleted) != 2 || engine.GetPlaylistSize() != 1 || engine.GetTotalDuration() != 400 {
+		t.Errorf("Expected 2 deleted leaving Song 3, got %d deleted, size %d", len(deleted), engine.GetPlaylistSize())
+	}
+	if activity := engine.GetActivityLog(1); len(activity) != 1 || activity[0].Op != ActivityBulkDelete {
+		t.Errorf("Expected a single bulk delete activity, got %v", activity)
+	}
+}
+
+func TestRateSongs(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	id1, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	id2, _ := engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.RateSong(id2, 4)
+
+	preview, err := engine.PreviewRateSongs([]string{id1, id2}, 4)
+	if err != nil || len(preview) != 1 || preview[0].ID != id1 {
+		t.Errorf("Expected only Song 1 to change, got %v, %v", preview, err)
+	}
+	if len(engine.GetSongsByRating(4)) != 1 {
+		t.Error("Preview should not change ratings")
+	}
+
+	rated, _ := engine.RateSongs([]string{id1, id2}, 4)
+	if len(rated) != 1 || len(engine.GetSongsByRating(4)) != 2 {
+		t.Errorf("Expected both songs rated 4, got %d rated and %d in the tree", len(rated), len(engine.GetSongsByRating(4)))
+	}
+
+	if _, err := engine.RateSongs([]string{id1}, 6); err == nil {
+		t.Error("Expected an error for an invalid rating")
+	}
+	if song, _ := engine.SearchSongByID(id1); song.Rating != 4 {
+		t.Errorf("Invalid rating should change nothing, got %d", song.Rating)
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import "src/internal/models"
+
+// initialLookupCapacity is the bucket count the song lookups start with and never shrink below
+const initialLookupCapacity = 64
+
+// CompactStats describes how much room the engine's indexes take up
+type CompactStats struct {
+	SongLookupCapacity  int `json:"song_lookup_capacity"`
+	TitleLookupCapacity int `json:"title_lookup_capacity"`
+	TreeNodes           int `json:"tree_nodes"`
+	ArtistIndexSlots    int `json:"artist_index_slots"`
+}
+
+// CompactReport compares index sizes before and after Compact
+type CompactReport struct {
+	Before           CompactStats `json:"before"`
+	After            CompactStats `json:"after"`
+	TreeNodesRemoved int          `json:"tree_nodes_removed"`
+}
+
+// Compact shrinks the song and title lookups to fit, prunes empty explorer tree branches and
+// reallocates per-artist and per-node song slices to their lengths, reclaiming room left by deletes
+// Every song stays indexed and queryable
+// Time Complexity: O(n + c + t) where c is the lookup capacity and t the number of tree nodes
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) Compact() CompactReport {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	report := CompactReport{Before: pe.compactStats()}
+
+	pe.songLookup.ShrinkToFit(initialLookupCapacity)
+	pe.titleLookup.ShrinkToFit(initialLookupCapacity)
+	report.TreeNodesRemoved = pe.playlistTree.Compact()
+
+	for artist, songs := range pe.artistIndex {
+		if len(songs) < cap(songs) {
+			pe.artistIndex[artist] = append(make([]*models.Song, 0, len(songs)), songs...)
+		}
+	}
+	pe.searchCache.Clear()
+
+	report.After = pe.compactStats()
+	return report
+}
+
+// compactStats measures the current size of the indexes Compact can shrink
+// Time Complexity: O(a + t) where a is the number of artists and t the number of tree nodes
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) compactStats() CompactStats {
+	stats := CompactStats{
+		SongLookupCapacity:  pe.songLookup.GetCapacity(),
+		TitleLookupCapacity: pe.titleLookup.GetCapacity(),
+		TreeNodes:           pe.playlistTree.NodeCount(),
+	}
+	for _, songs := range pe.artistIndex {
+		stats.ArtistIndexSlots += cap(songs)
+	}
+	return stats
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	var ids []string
+	for i := 0; i < 600; i++ {
+		id, err := engine.AddSong(fmt.Sprintf("Song %d", i), fmt.Sprintf("Artist %d", i%3), "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		ids = append(ids, id)
+	}
+	for _, id := range ids[10:] {
+		engine.DeleteSongByID(id)
+	}
+
+	report := engine.Compact()
+	if report.After.SongLookupCapacity >= report.Before.SongLookupCapacity {
+		t.Errorf("Expected the ID lookup to shrink, got %d -> %d", report.Before.SongLookupCapacity, report.After.SongLookupCapacity)
+	}
+	if report.After.TitleLookupCapacity >= report.Before.TitleLookupCapacity {
+		t.Errorf("Expected the title lookup to shrink, got %d -> %d", report.Before.TitleLookupCapacity, report.After.TitleLookupCapacity)
+	}
+	if report.After.SongLookupCapacity != initialLookupCapacity {
+		t.Errorf("Expected capacity to stop at %d, got %d", initialLookupCapacity, report.After.SongLookupCapacity)
+	}
+	if report.After.ArtistIndexSlots > report.Before.ArtistIndexSlots || report.After.ArtistIndexSlots != 10 {
+		t.Errorf("Expected 10 artist index slots after compacting, got %d -> %d", report.Before.ArtistIndexSlots, report.After.ArtistIndexSlots)
+	}
+
+	// Every remaining song is still reachable through each index
+	for i, id := range ids[:10] {
+		if _, err := engine.SearchSongByID(id); err != nil {
+			t.Errorf("Song %s missing from the ID lookup: %v", id, err)
+		}
+		if _, err := engine.SearchSongByTitle(fmt.Sprintf("Song %d", i)); err != nil {
+			t.Errorf("Song %d missing from the title lookup: %v", i, err)
+		}
+	}
+	if songs := engine.GetPlaylistByExplorer("Rock", "Alternative", "Energetic", "Artist 0"); len(songs) != 4 {
+		t.Errorf("Expected 4 songs by Artist 0 in the explorer, got %d", len(songs))
+	}
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes, got %v", issues)
+	}
+
+	// New songs can still be added after shrinking
+	if _, err := engine.AddSong("Song 600", "Artist 0", "Album", "Rock", "Alternative", "Energetic", 200, 120); err != nil {
+		t.Errorf("Expected no error adding after compaction, got %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CSVImportProgressInterval is the number of rows processed between progress callbacks
+const CSVImportProgressInterval = 100
+
+// ImportProgress holds running counts for an import
+type ImportProgress struct {
+	Processed int `json:"processed"`
+	Added     int `json:"added"`
+	Skipped   int `json:"skipped"`
+}
+
+// ImportProgressFunc receives progress every CSVImportProgressInterval rows and once more when the import ends
+type ImportProgressFunc func(ImportProgress)
+
+// CSVImportOptions tunes how ImportCSVWithOptions processes rows
+type CSVImportOptions struct {
+	// Number of goroutines parsing and validating rows, 0 or 1 parses on the calling goroutine
+	Workers int
+}
+
+// csvRow is one CSV record waiting to be added, parsed by a worker
+type csvRow struct {
+	line   int
+	record []string
+	song   csvSong
+	err    error
+}
+
+// csvSong holds the song fields parsed from one CSV row
+type csvSong struct {
+	title, artist, album, genre, subgenre, mood string
+	duration, bpm, rating                       int
+}
+
+// ImportCSV reads songs from CSV with a header row naming the columns in any order
+// Recognised columns are title, artist, album, genre, subgenre, mood, duration, bpm and rating; others are ignored
+// A rating of 0 or an empty rating cell leaves the song unrated
+// Rows that can't be parsed or songs that can't be added are skipped and recorded in errs instead of aborting
+// progress may be nil
+// Time Complexity: O(r * n) where r is the number of rows and n the playlist size (duplicate check)
+// Space Complexity: O(e) where e is the number of errors
+func ImportCSV(engine *PlaylistEngine, r io.Reader, progress ImportProgressFunc) (result ImportProgress, errs []error) {
+	return ImportCSVWithOptions(engine, r, progress, CSVImportOptions{})
+}
+
+// ImportCSVWithOptions imports CSV like ImportCSV, optionally parsing rows on a pool of workers
+// Rows are read and parsed in batches of CSVImportProgressInterval; each batch is then added to the engine
+// in file order while holding the engine lock, so results, errors and progress match a serial import
+// A read error other than a malformed row stops the import, keeping the rows read before it
+// Time Complexity: O(r * n) where r is the number of rows and n the playlist size (duplicate check)
+// Space Complexity: O(e + b) where e is the number of errors and b the batch size
+func ImportCSVWithOptions(engine *PlaylistEngine, r io.Reader, progress ImportProgressFunc, opts CSVImportOptions) (result ImportProgress, errs []error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Short rows are reported per row rather than failing the import
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = fmt.Errorf("missing header row")
+		}
+		return result, []error{fmt.Errorf("failed to read CSV: %v", err)}
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "artist"} {
+		if _, ok := columns[required]; !ok {
+			return result, []error{fmt.Errorf("CSV header must include a %q column", required)}
+		}
+	}
+
+	report := func() {
+		if progress != nil {
+			progress(result)
+		}
+	}
+
+	var readErr error // Set when the input itself fails, which ends the import
+	batch := make([]csvRow, 0, CSVImportProgressInterval)
+	for done := false; !done; {
+		batch = batch[:0]
+		for len(batch) < CSVImportProgressInterval {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				done = true
+				break
+			}
+			// Malformed rows are skipped, but any other error (e.g. a dropped upload) would repeat forever
+			var parseErr *csv.ParseError
+			if err != nil && !errors.As(err, &parseErr) {
+				readErr = err
+				done = true
+				break
+			}
+			line, _ := reader.FieldPos(0)
+			batch = append(batch, csvRow{line: line, record: record, err: err})
+		}
+
+		parseCSVRows(batch, columns, opts.Workers)
+
+		engine.mu.Lock()
+		for _, row := range batch {
+			result.Processed++
+
+			err := row.err
+			if err == nil {
+				if err = addCSVSong(engine, row.song); err != nil {
+					err = fmt.Errorf("line %d: %w", row.line, err)
+				}
+			}
+			if err != nil {
+				result.Skipped++
+				errs = append(errs, err)
+			} else {
+				result.Added++
+			}
+		}
+		engine.mu.Unlock()
+
+		if len(batch) > 0 && result.Processed%CSVImportProgressInterval == 0 {
+			report()
+		}
+	}
+
+	if readErr != nil {
+		errs = append(errs, fmt.Errorf("failed to read CSV after %d rows: %v", result.Processed, readErr))
+	}
+
+	// Final counts, unless the last interval report already covered them
+	if result.Processed == 0 || result.Processed%CSVImportProgressInterval != 0 {
+		report()
+	}
+
+	return result, errs
+}
+
+// parseCSVRows parses every row that was read successfully, splitting the rows across workers goroutines
+// Rows with read errors keep them; parse errors are prefixed with the row's line number
+// Time Complexity: O(b) where b is the number of rows
+// Space Complexity: O(1)
+func parseCSVRows(rows []csvRow, columns map[string]int, workers int) {
+	parse := func(row *csvRow) {
+		if row.err != nil {
+			return // Read errors already carry their line number
+		}
+		if row.song, row.err = parseCSVRecord(columns, row.record); row.err != nil {
+			row.err = fmt.Errorf("line %d: %w", row.line, row.err)
+		}
+	}
+
+	if workers <= 1 {
+		for i := range rows {
+			parse(&rows[i])
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				parse(&rows[i])
+			}
+		}()
+	}
+	for i := range rows {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// parseCSVRecord reads the song fields from one CSV row and validates them
+// Time Complexity: O(k) where k is the row length
+// Space Complexity: O(k)
+func parseCSVRecord(columns map[string]int, record []string) (csvSong, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	number := func(name string) (int, error) {
+		value := field(name)
+		if value == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid %s %q", name, value)
+		}
+		return n, nil
+	}
+
+	song := csvSong{
+		title:    field("title"),
+		artist:   field("artist"),
+		album:    field("album"),
+		genre:    field("genre"),
+		subgenre: field("subgenre"),
+		mood:     field("mood"),
+	}
+
+	var err error
+	if song.duration, err = number("duration"); err != nil {
+		return song, err
+	}
+	if song.bpm, err = number("bpm"); err != nil {
+		return song, err
+	}
+	if song.rating, err = number("rating"); err != nil {
+		return song, err
+	}
+	if song.rating > 5 {
+		return song, fmt.Errorf("invalid rating %q, must be between 0 and 5", field("rating"))
+	}
+	return song, validateSongFieldLengths(song.title, song.artist, song.album, song.genre, song.subgenre, song.mood)
+}
+
+// addCSVSong adds a parsed CSV song to the engine, whose lock the caller holds
+// Time Complexity: O(n) for the engine's duplicate check
+// Space Complexity: O(1)
+func addCSVSong(engine *PlaylistEngine, song csvSong) error {
+	songID, err := engine.addSongWithLinks(
+		song.title, song.artist, song.album,
+		song.genre, song.subgenre, song.mood,
+		song.duration, song.bpm, "", "",
+	)
+	if err != nil || song.rating == 0 {
+		return err
+	}
+
+	added, err := engine.songLookup.Get(songID)
+	if err != nil {
+		return err
+	}
+	engine.applyRating(added, song.rating)
+	return nil
+}
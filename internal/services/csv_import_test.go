@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportCSV(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	data := "Artist,Title,Genre,Duration,BPM,Extra\n" +
+		"Artist 1,Song 1,Rock,200,120,x\n" +
+		",Missing Artist,Rock,200,120,x\n" +
+		"Artist 2,Song 2,Jazz,abc,90,x\n" +
+		"Artist 3,Song 3,Jazz,300,90,x\n"
+
+	result, errs := ImportCSV(engine, strings.NewReader(data), nil)
+	if result.Processed != 4 || result.Added != 2 || result.Skipped != 2 {
+		t.Errorf("Expected 4 processed, 2 added, 2 skipped, got %+v", result)
+	}
+	if len(errs) != 2 || !strings.HasPrefix(errs[0].Error(), "line 3:") {
+		t.Errorf("Expected 2 errors starting at line 3, got %v", errs)
+	}
+
+	songs, err := engine.SearchSongByTitle("Song 3")
+	if err != nil {
+		t.Fatalf("Expected Song 3 to be imported, got %v", err)
+	}
+	if song := songs[0]; song.Artist != "Artist 3" || song.Genre != "Jazz" || song.Duration != 300 || song.BPM != 90 {
+		t.Errorf("Expected Song 3 imported with its columns, got %+v", song)
+	}
+}
+
+func TestImportCSV_Rating(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	data := "title,artist,rating\n" +
+		"Rated,Artist,4\n" +
+		"Unrated,Artist,0\n" +
+		"Too High,Artist,6\n"
+
+	result, errs := ImportCSV(engine, strings.NewReader(data), nil)
+	if result.Added != 2 || len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "line 4:") {
+		t.Errorf("Expected 2 added and a rating error on line 4, got %+v, %v", result, errs)
+	}
+
+	rated := engine.GetSongsByRating(4)
+	if len(rated) != 1 || rated[0].Title != "Rated" {
+		t.Errorf("Expected the rated song in the rating index, got %v", rated)
+	}
+	if songs, _ := engine.SearchSongByTitle("Unrated"); len(songs) != 1 || songs[0].Rating != 0 {
+		t.Errorf("Expected a zero rating to leave the song unrated, got %+v", songs)
+	}
+}
+
+func TestImportCSV_InvalidHeader(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	for _, data := range []string{"", "title,album\nSong,Album\n"} {
+		result, errs := ImportCSV(engine, strings.NewReader(data), nil)
+		if len(errs) != 1 || result.Processed != 0 {
+			t.Errorf("Expected a single header error for %q, got %+v, %v", data, result, errs)
+		}
+	}
+}
+
+// failingReader returns err on every read, like a connection dropped mid-upload
+type failingReader struct{ err error }
+
+func (fr failingReader) Read([]byte) (int, error) { return 0, fr.err }
+
+func TestImportCSV_ReadError(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	dropped := errors.New("connection reset by peer")
+	data := io.MultiReader(strings.NewReader("title,artist\nSong 1,Artist\nSong 2,Artist\n"), failingReader{dropped})
+
+	done := make(chan struct{})
+	var result ImportProgress
+	var errs []error
+	go func() {
+		result, errs = ImportCSV(engine, data, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the import to stop on a read error")
+	}
+
+	if result.Added != 2 || engine.GetPlaylistSize() != 2 {
+		t.Errorf("Expected the rows before the error to be added, got %+v", result)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), dropped.Error()) {
+		t.Errorf("Expected a single read error, got %v", errs)
+	}
+}
+
+func TestImportCSV_Progress(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	var sb strings.Builder
+	sb.WriteString("title,artist\n")
+	rows := 2*CSVImportProgressInterval + 50
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&sb, "Song %d,Artist\n", i)
+	}
+
+	var events []ImportProgress
+	result, errs := ImportCSV(engine, strings.NewReader(sb.String()), func(p ImportProgress) {
+		events = append(events, p)
+	})
+	if len(errs) != 0 || result.Added != rows {
+		t.Fatalf("Expected %d songs added without errors, got %+v, %v", rows, result, errs)
+	}
+
+	// One event per full interval plus the final counts
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 progress events, got %d: %v", len(events), events)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Processed <= events[i-1].Processed || events[i].Added < events[i-1].Added {
+			t.Errorf("Progress should increase, got %+v after %+v", events[i], events[i-1])
+		}
+	}
+	if last := events[len(events)-1]; last != result {
+		t.Errorf("Last progress event %+v should match the result %+v", last, result)
+	}
+}
+
+func TestImportCSV_MaxSongs(t *testing.T) {
+	config := DefaultEngineConfig()
+	config.MaxSongs = 2
+	engine := NewPlaylistEngineWithConfig("Test", config)
+
+	data := "title,artist\nSong 1,Artist 1\nSong 2,Artist 2\nSong 3,Artist 3\nSong 4,Artist 4\n"
+
+	result, errs := ImportCSV(engine, strings.NewReader(data), nil)
+	if result.Added != 2 || result.Skipped != 2 {
+		t.Errorf("Expected 2 added and 2 rejected, got %+v", result)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], ErrPlaylistFull) {
+		t.Errorf("Expected 2 playlist full errors, got %v", errs)
+	}
+	if engine.GetPlaylistSize() != 2 {
+		t.Errorf("Expected 2 songs, got %d", engine.GetPlaylistSize())
+	}
+}
+
+// buildImportCSV returns a CSV with rows songs, mixing in duplicates, bad numbers and missing artists
+func buildImportCSV(rows int) string {
+	var sb strings.Builder
+	sb.WriteString("title,artist,album,genre,subgenre,mood,duration,bpm\n")
+	for i := 0; i < rows; i++ {
+		switch {
+		case i%50 == 7:
+			fmt.Fprintf(&sb, "Song %d,Artist %d,Album,Rock,Alternative,Energetic,abc,120\n", i, i%20)
+		case i%50 == 13:
+			fmt.Fprintf(&sb, "Song %d,,Album,Rock,Alternative,Energetic,200,120\n", i)
+		case i%50 == 21:
+			fmt.Fprintf(&sb, "Song %d,Artist %d,Album,Rock,Alternative,Energetic,200,120\n", i-1, (i-1)%20)
+		default:
+			fmt.Fprintf(&sb, "Song %d,Artist %d,Album %d,Rock,Alternative,Energetic,%d,%d\n", i, i%20, i%7, 120+i%200, 60+i%100)
+		}
+	}
+	return sb.String()
+}
+
+func TestImportCSVWithOptions_MatchesSerial(t *testing.T) {
+	data := buildImportCSV(3*CSVImportProgressInterval + 37)
+
+	serial := NewPlaylistEngine("Serial")
+	var serialEvents []ImportProgress
+	serialResult, serialErrs := ImportCSV(serial, strings.NewReader(data), func(p ImportProgress) {
+		serialEvents = append(serialEvents, p)
+	})
+
+	concurrent := NewPlaylistEngine("Concurrent")
+	var concurrentEvents []ImportProgress
+	concurrentResult, concurrentErrs := ImportCSVWithOptions(concurrent, strings.NewReader(data), func(p ImportProgress) {
+		concurrentEvents = append(concurrentEvents, p)
+	}, CSVImportOptions{Workers: 4})
+
+	if serialResult != concurrentResult {
+		t.Errorf("Results differ: serial %+v, concurrent %+v", serialResult, concurrentResult)
+	}
+	if serialResult.Skipped == 0 {
+		t.Error("Expected the fixture to produce skipped rows")
+	}
+	if fmt.Sprint(serialErrs) != fmt.Sprint(concurrentErrs) {
+		t.Errorf("Errors differ:\nserial     %v\nconcurrent %v", serialErrs, concurrentErrs)
+	}
+	if fmt.Sprint(serialEvents) != fmt.Sprint(concurrentEvents) {
+		t.Errorf("Progress differs: serial %v, concurrent %v", serialEvents, concurrentEvents)
+	}
+
+	serialSongs := serial.GetCurrentPlaylist()
+	concurrentSongs := concurrent.GetCurrentPlaylist()
+	if len(serialSongs) != len(concurrentSongs) {
+		t.Fatalf("Expected %d songs, got %d", len(serialSongs), len(concurrentSongs))
+	}
+	for i := range serialSongs {
+		a, b := serialSongs[i], concurrentSongs[i]
+		if a.Title != b.Title || a.Artist != b.Artist || a.Album != b.Album || a.Duration != b.Duration || a.BPM != b.BPM {
+			t.Fatalf("Position %d differs: %+v vs %+v", i, a, b)
+		}
+	}
+	if issues := concurrent.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes, got %v", issues)
+	}
+}
+
+func BenchmarkImportCSV10k(b *testing.B) {
+	data := buildImportCSV(10000)
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				engine := NewPlaylistEngine("Bench")
+				ImportCSVWithOptions(engine, strings.NewReader(data), nil, CSVImportOptions{Workers: workers})
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"src/internal/models"
+	"strings"
+)
+
+// SongUpdate lists the fields UpdateSong changes; nil fields are left as they are
+// A rating of 0 clears the song's rating
+type SongUpdate struct {
+	Title    *string `json:"title"`
+	Artist   *string `json:"artist"`
+	Album    *string `json:"album"`
+	Genre    *string `json:"genre"`
+	SubGenre *string `json:"subgenre"`
+	Mood     *string `json:"mood"`
+	Duration *int    `json:"duration"`
+	BPM      *int    `json:"bpm"`
+	Rating   *int    `json:"rating"`
+}
+
+// UpdateSong edits a song's metadata in place, keeping its ID, play statistics and position
+// Every index follows the change: the title lookup and fuzzy index are re-keyed, the rating tree
+// re-buckets the song and the explorer tree and similarity index re-file it under its new genre,
+// subgenre, mood and artist
+// The update is validated as a whole first, so an invalid field leaves the song untouched
+// Changing anything resets the sort state to custom, since the order may no longer match it
+// Time Complexity: O(n) for the duplicate check when title, artist or album change, plus O(t) to
+// re-file the song in an explorer tree of size t
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) UpdateSong(songID string, update SongUpdate) (*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, err := pe.songLookup.Get(songID)
+	if err != nil {
+		return nil, fmt.Errorf("song not found: %v", err)
+	}
+
+	updated := *song
+	text := func(value *string, field *string) {
+		if value != nil {
+			*field = strings.TrimSpace(*value)
+		}
+	}
+	text(update.Title, &updated.Title)
+	text(update.Artist, &updated.Artist)
+	text(update.Album, &updated.Album)
+	text(update.Genre, &updated.Genre)
+	text(update.SubGenre, &updated.SubGenre)
+	text(update.Mood, &updated.Mood)
+	if update.Duration != nil {
+		updated.Duration = *update.Duration
+	}
+	if update.BPM != nil {
+		updated.BPM = *update.BPM
+	}
+	if update.Rating != nil {
+		updated.Rating = *update.Rating
+	}
+
+	if updated.Title == "" || updated.Artist == "" {
+		return nil, fmt.Errorf("title and artist are required")
+	}
+	if err := validateSongFieldLengths(updated.Title, updated.Artist, updated.Album, updated.Genre, updated.SubGenre, updated.Mood); err != nil {
+		return nil, err
+	}
+	if updated.Duration < 0 || updated.BPM < 0 {
+		return nil, fmt.Errorf("duration and bpm cannot be negative")
+	}
+	if updated.Rating < 0 || updated.Rating > 5 {
+		return nil, fmt.Errorf("rating must be between 0 and 5")
+	}
+
+	renamed := updated.Title != song.Title || updated.Artist != song.Artist || updated.Album != song.Album
+	if renamed {
+		if err := pe.checkDuplicate(updated.Title, updated.Artist, updated.Album, song.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	changed := renamed || updated.Genre != song.Genre || updated.SubGenre != song.SubGenre || updated.Mood != song.Mood ||
+		updated.Duration != song.Duration || updated.BPM != song.BPM || updated.Rating != song.Rating
+	if !changed {
+		return song.Clone(), nil
+	}
+
+	// Unfile the song under its old keys before any of them change
+	refile := song.Genre != updated.Genre || song.SubGenre != updated.SubGenre || song.Mood != updated.Mood || song.Artist != updated.Artist
+	if refile {
+		pe.playlistTree.RemoveSong(song.ID)
+	}
+	if song.Artist != updated.Artist {
+		pe.unindexArtist(song)
+	}
+	if song.Album != updated.Album || song.Artist != updated.Artist {
+		pe.unindexAlbum(song)
+	}
+	if song.Title != updated.Title {
+		pe.titleLookup.Remove(song)
+	}
+	if song.Rating != updated.Rating && song.Rating > 0 {
+		pe.ratingTree.DeleteSong(song.ID)
+	}
+	if song.Duration != updated.Duration {
+		pe.durationIndex.Remove(song.Duration, song.ID)
+	}
+	pe.totalPlayTime += updated.Duration - song.Duration
+
+	oldArtist, oldAlbum, oldTitle, oldRating, oldBPM, oldDuration := song.Artist, song.Album, song.Title, song.Rating, song.BPM, song.Duration
+	*song = updated
+
+	if refile {
+		pe.playlistTree.AddSong(song)
+		pe.similarityIndex.Add(song)
+	}
+	if oldArtist != song.Artist {
+		pe.indexArtist(song)
+	}
+	if oldAlbum != song.Album || oldArtist != song.Artist {
+		pe.indexAlbum(song)
+	}
+	if oldTitle != song.Title {
+		pe.titleLookup.Add(song)
+		pe.searchCache.Clear()
+	}
+	if oldRating != song.Rating && song.Rating > 0 {
+		pe.ratingTree.InsertSong(song, song.Rating)
+	}
+	if oldBPM != song.BPM {
+		pe.bpmIndex.Add(song)
+	}
+	if oldDuration != song.Duration {
+		pe.durationIndex.Insert(song.Duration, song)
+	}
+	if renamed {
+		pe.indexFuzzy(song)
+	}
+
+	pe.sortState = customSortState()
+	pe.activity.record(ActivityEdit, song.Title)
+	return song.Clone(), nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+)
+
+func stringPtr(s string) *string { return &s }
+
+func intPtr(n int) *int { return &n }
+
+func TestUpdateSong(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	songID, _ := engine.AddSong("Bohemian Rapsody", "Queen", "A Night at the Opera", "Rock", "Classic Rock", "Epic", 354, 72)
+	engine.AddSong("Another One Bites the Dust", "Queen", "The Game", "Rock", "Funk Rock", "Energetic", 215, 110)
+	engine.RateSong(songID, 3)
+
+	song, err := engine.UpdateSong(songID, SongUpdate{
+		Title:    stringPtr(" Bohemian Rhapsody "),
+		Genre:    stringPtr("Progressive"),
+		Mood:     stringPtr("Dramatic"),
+		Duration: intPtr(355),
+		Rating:   intPtr(5),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if song.ID != songID || song.Title != "Bohemian Rhapsody" || song.Album != "A Night at the Opera" {
+		t.Errorf("Expected the same song with a fixed title and unchanged album, got %+v", song)
+	}
+
+	if _, err := engine.SearchSongByTitle("Bohemian Rapsody"); err == nil {
+		t.Error("Expected the old title to be dropped from the title lookup")
+	}
+	if found, err := engine.SearchSongByTitle("Bohemian Rhapsody"); err != nil || len(found) != 1 || found[0].ID != songID {
+		t.Errorf("Expected the new title in the title lookup, got %v, %v", found, err)
+	}
+	if songs := engine.GetSongsByRating(5); len(songs) != 1 || songs[0].ID != songID {
+		t.Errorf("Expected the song under rating 5, got %v", songs)
+	}
+	if songs := engine.GetSongsByRating(3); len(songs) != 0 {
+		t.Errorf("Expected the song gone from rating 3, got %v", songs)
+	}
+	if songs := engine.GetPlaylistByExplorer("Progressive", "Classic Rock", "Dramatic", "Queen"); len(songs) != 1 {
+		t.Errorf("Expected the song re-filed in the explorer tree, got %v", songs)
+	}
+	if hits := engine.FuzzySearch("rhapsody", 10); len(hits) != 1 {
+		t.Errorf("Expected the new title in the fuzzy index, got %v", hits)
+	}
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes after the update, got %v", issues)
+	}
+}
+
+func TestUpdateSong_Invalid(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	songID, _ := engine.AddSong("Song 1", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	invalid := []SongUpdate{
+		{Title: stringPtr("  ")},
+		{Duration: intPtr(-1)},
+		{Rating: intPtr(6)},
+		{Title: stringPtr("song 2")}, // Duplicate of Song 2 under the default reject policy
+		{Genre: stringPtr("Rock"), Artist: stringPtr("")},
+	}
+	for _, update := range invalid {
+		if _, err := engine.UpdateSong(songID, update); err == nil {
+			t.Errorf("Expected error for update %+v", update)
+		}
+	}
+
+	song, _ := engine.SearchSongByID(songID)
+	if song.Title != "Song 1" || song.Artist != "Artist" || song.Duration != 200 {
+		t.Errorf("Expected a rejected update to leave the song untouched, got %+v", song)
+	}
+
+	if _, err := engine.UpdateSong("missing", SongUpdate{Title: stringPtr("x")}); err == nil {
+		t.Error("Expected error for an unknown song")
+	}
+
+	// Changing only the case of its own title isn't a duplicate of itself
+	if _, err := engine.UpdateSong(songID, SongUpdate{Title: stringPtr("SONG 1")}); err != nil {
+		t.Errorf("Expected renaming a song's own title to succeed, got %v", err)
+	}
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes, got %v", issues)
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"src/internal/models"
+	"strings"
+	"time"
+)
+
+// ExportFormat names a playlist file format understood by ExportPlaylist
+type ExportFormat string
+
+const (
+	ExportM3U  ExportFormat = "m3u"
+	ExportM3U8 ExportFormat = "m3u8"
+	ExportJSON ExportFormat = "json"
+)
+
+// ParseExportFormat resolves a format name, case-insensitively
+// Time Complexity: O(k) where k is the name length
+// Space Complexity: O(1)
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch format := ExportFormat(strings.ToLower(strings.TrimSpace(name))); format {
+	case ExportM3U, ExportM3U8, ExportJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown export format '%s', expected m3u, m3u8 or json", name)
+}
+
+// ContentType returns the MIME type served for the format
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportM3U:
+		return "audio/x-mpegurl"
+	case ExportM3U8:
+		return "audio/x-mpegurl; charset=utf-8"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// exportDocument is the top level of a JSON export; songs are streamed separately
+type exportDocument struct {
+	Name       string    `json:"name"`
+	ExportedAt time.Time `json:"exported_at"`
+	Count      int       `json:"count"`
+}
+
+// ExportPlaylist writes every song of the playlist to w in the given format
+// Songs are copied under the engine's read lock and written after it is released,
+// so a slow client never blocks other requests
+// Time Complexity: O(n)
+// Space Complexity: O(n) for the copied songs
+func ExportPlaylist(engine *PlaylistEngine, w io.Writer, format ExportFormat) error {
+	engine.mu.RLock()
+	name := engine.playlistName
+	songs := engine.copySongs()
+	engine.mu.RUnlock()
+
+	buffered := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case ExportM3U, ExportM3U8:
+		err = writeM3U(buffered, songs)
+	case ExportJSON:
+		err = writeJSON(buffered, name, songs)
+	default:
+		return fmt.Errorf("unknown export format '%s'", format)
+	}
+	if err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// writeM3U writes songs as an extended M3U playlist that ImportM3U can read back
+// Album and genre go in #EXTALB and #EXTGENRE directives; the path line is the song's
+// source URL, or "Artist - Title" when it has none
+// Time Complexity: O(n)
+// Space Complexity: O(1)
+func writeM3U(w *bufio.Writer, songs []*models.Song) error {
+	w.WriteString("#EXTM3U\n")
+	for _, song := range songs {
+		fmt.Fprintf(w, "#EXTINF:%d,%s - %s\n", song.Duration, m3uText(song.Artist), m3uText(song.Title))
+		if song.Album != "" {
+			fmt.Fprintf(w, "#EXTALB:%s\n", m3uText(song.Album))
+		}
+		if song.Genre != "" {
+			fmt.Fprintf(w, "#EXTGENRE:%s\n", m3uText(song.Genre))
+		}
+
+		path := song.SourceURL
+		if path == "" {
+			path = m3uText(song.Artist) + " - " + m3uText(song.Title)
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// m3uText flattens line breaks, which would otherwise end an M3U entry early
+// Time Complexity: O(k) where k is the text length
+// Space Complexity: O(k)
+func m3uText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// writeJSON streams {"name", "exported_at", "count", "songs": [...]} one song at a time
+// Time Complexity: O(n)
+// Space Complexity: O(1) beyond the encoder's per-song buffer
+func writeJSON(w *bufio.Writer, name string, songs []*models.Song) error {
+	header, err := json.Marshal(exportDocument{Name: name, ExportedAt: time.Now(), Count: len(songs)})
+	if err != nil {
+		return err
+	}
+
+	// Reopen the header object to append the songs array
+	w.Write(header[:len(header)-1])
+	w.WriteString(`,"songs":[`)
+	for i, song := range songs {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		encoded, err := json.Marshal(song)
+		if err != nil {
+			return fmt.Errorf("failed to encode song '%s': %w", song.ID, err)
+		}
+		w.Write(encoded)
+	}
+	_, err = w.WriteString("]}\n")
+	return err
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseExportFormat(t *testing.T) {
+	for _, name := range []string{"m3u", "M3U8", " json "} {
+		if _, err := ParseExportFormat(name); err != nil {
+			t.Errorf("ParseExportFormat(%q) returned error %v", name, err)
+		}
+	}
+	if _, err := ParseExportFormat("xspf"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestExportPlaylist_M3URoundTrip(t *testing.T) {
+	engine := NewPlaylistEngine("Road Trip")
+	engine.AddSong("Bohemian Rhapsody", "Queen", "A Night at the Opera", "Rock", "Classic Rock", "Epic", 354, 72)
+	engine.AddSongWithLinks("Around the World", "Daft Punk", "", "", "", "", 429, 121, "", "https://example.com/atw.mp3")
+
+	var out strings.Builder
+	if err := ExportPlaylist(engine, &out, ExportM3U); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := "#EXTM3U\n" +
+		"#EXTINF:354,Queen - Bohemian Rhapsody\n" +
+		"#EXTALB:A Night at the Opera\n" +
+		"#EXTGENRE:Rock\n" +
+		"Queen - Bohemian Rhapsody\n" +
+		"#EXTINF:429,Daft Punk - Around the World\n" +
+		"https://example.com/atw.mp3\n"
+	if out.String() != want {
+		t.Errorf("Unexpected M3U:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	imported := NewPlaylistEngine("Imported")
+	if added, errs := ImportM3U(imported, strings.NewReader(out.String())); added != 2 || len(errs) != 0 {
+		t.Errorf("Expected the export to import cleanly, got %d added, %v", added, errs)
+	}
+}
+
+func TestExportPlaylist_JSON(t *testing.T) {
+	engine := NewPlaylistEngine("Road Trip")
+	engine.AddSong("Song 1", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist", "Album", "Jazz", "Smooth Jazz", "Calm", 300, 90)
+	engine.RateSong(engine.GetCurrentPlaylist()[1].ID, 4)
+
+	var out strings.Builder
+	if err := ExportPlaylist(engine, &out, ExportJSON); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var document struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+		Songs []struct {
+			Title  string `json:"title"`
+			Rating int    `json:"rating"`
+		} `json:"songs"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &document); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, out.String())
+	}
+	if document.Name != "Road Trip" || document.Count != 2 || len(document.Songs) != 2 {
+		t.Errorf("Unexpected document %+v", document)
+	}
+	if document.Songs[1].Title != "Song 2" || document.Songs[1].Rating != 4 {
+		t.Errorf("Expected songs in playlist order with ratings, got %+v", document.Songs)
+	}
+
+	// An empty playlist still produces a valid document
+	out.Reset()
+	ExportPlaylist(NewPlaylistEngine("Empty"), &out, ExportJSON)
+	if !json.Valid([]byte(out.String())) || !strings.Contains(out.String(), `"songs":[]`) {
+		t.Errorf("Expected an empty songs array, got %s", out.String())
+	}
+}
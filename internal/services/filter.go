@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"src/internal/models"
+	"strings"
+	"time"
+)
+
+// SongFilter holds the criteria of a filtered playlist view; a song must meet all of them
+// Zero values leave a criterion out. A rating bound only matches rated songs and a BPM bound
+// only matches songs with a known BPM; a zero maximum leaves its range open upwards
+type SongFilter struct {
+	Genre        string
+	Mood         string
+	MinRating    int
+	MaxRating    int
+	MinBPM       int
+	MaxBPM       int
+	MinDuration  int
+	MaxDuration  int
+	MinPlayCount int
+	AddedAfter   time.Time
+}
+
+// hasRating reports whether the filter bounds the rating
+func (f SongFilter) hasRating() bool {
+	return f.MinRating > 0 || f.MaxRating > 0
+}
+
+// hasBPM reports whether the filter bounds the BPM
+func (f SongFilter) hasBPM() bool {
+	return f.MinBPM > 0 || f.MaxBPM > 0
+}
+
+// Validate checks that every bound is in range and no minimum exceeds its maximum
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (f SongFilter) Validate() error {
+	if f.MinRating < 0 || f.MinRating > 5 || f.MaxRating < 0 || f.MaxRating > 5 {
+		return fmt.Errorf("rating bounds must be between 1 and 5")
+	}
+	if f.MinBPM < 0 || f.MaxBPM < 0 || f.MinDuration < 0 || f.MaxDuration < 0 || f.MinPlayCount < 0 {
+		return fmt.Errorf("bpm, duration and play count bounds cannot be negative")
+	}
+	bounds := []struct {
+		name     string
+		min, max int
+	}{
+		{"rating", f.MinRating, f.MaxRating},
+		{"bpm", f.MinBPM, f.MaxBPM},
+		{"duration", f.MinDuration, f.MaxDuration},
+	}
+	for _, b := range bounds {
+		if b.max > 0 && b.min > b.max {
+			return fmt.Errorf("min_%s must not be greater than max_%s", b.name, b.name)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether song meets every criterion of the filter
+// Genre and mood compare case-insensitively
+// Time Complexity: O(k) where k is the length of the genre and mood
+// Space Complexity: O(1)
+func (f SongFilter) Matches(song *models.Song) bool {
+	if f.Genre != "" && !strings.EqualFold(strings.TrimSpace(song.Genre), strings.TrimSpace(f.Genre)) {
+		return false
+	}
+	if f.Mood != "" && !strings.EqualFold(strings.TrimSpace(song.Mood), strings.TrimSpace(f.Mood)) {
+		return false
+	}
+	if f.hasRating() && !inBounds(song.Rating, max(f.MinRating, 1), f.MaxRating) {
+		return false
+	}
+	if f.hasBPM() && !inBounds(song.BPM, max(f.MinBPM, 1), f.MaxBPM) {
+		return false
+	}
+	if !inBounds(song.Duration, f.MinDuration, f.MaxDuration) || song.PlayCount < f.MinPlayCount {
+		return false
+	}
+	return f.AddedAfter.IsZero() || song.AddedAt.After(f.AddedAfter)
+}
+
+// inBounds reports whether min <= value <= max, a zero max leaving the range open upwards
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func inBounds(value, min, max int) bool {
+	return value >= min && (max <= 0 || value <= max)
+}
+
+// FilterSongs returns the songs meeting every criterion of filter, oldest added first
+// Instead of scanning the playlist, candidates come from the most selective index that applies:
+// the rating tree for a rating range, the BPM index for a BPM range, the duration tree for a
+// duration range, or the explorer tree's genre or mood branches. The remaining criteria are
+// checked on those candidates only, so the full playlist is only walked when the filter has none of these criteria
+// Time Complexity: O(log n + c log c) where c is the number of candidates, O(n log n) with no indexed criterion
+// Space Complexity: O(c)
+func (pe *PlaylistEngine) FilterSongs(filter SongFilter) ([]*models.Song, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	candidates := pe.filterCandidates(filter)
+	matches := make([]*models.Song, 0, len(candidates))
+	for _, song := range candidates {
+		if filter.Matches(song) {
+			matches = append(matches, song)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].AddedAt.Before(matches[j].AddedAt)
+	})
+	return cloneSongs(matches), nil
+}
+
+// filterCandidates returns the smallest candidate set an index offers for filter
+// Every song matching filter is included; the playlist itself is the fallback
+// Time Complexity: O(log n + c) for the chosen source, plus O(t) for a mood-only tree walk
+// Space Complexity: O(c)
+func (pe *PlaylistEngine) filterCandidates(filter SongFilter) []*models.Song {
+	var candidates []*models.Song
+	indexed := false
+	consider := func(songs []*models.Song) {
+		if !indexed || len(songs) < len(candidates) {
+			candidates, indexed = songs, true
+		}
+	}
+
+	if filter.hasRating() {
+		maxRating := filter.MaxRating
+		if maxRating == 0 {
+			maxRating = 5
+		}
+		consider(pe.ratingTree.GetSongsByRatingRange(max(filter.MinRating, 1), maxRating))
+	}
+	// Counting first keeps a wide BPM range from being copied when a narrower source exists
+	if filter.hasBPM() && (!indexed || pe.bpmIndex.Count(max(filter.MinBPM, 1), filter.MaxBPM) < len(candidates)) {
+		consider(pe.bpmIndex.Range(max(filter.MinBPM, 1), filter.MaxBPM))
+	}
+	if filter.MinDuration > 0 || filter.MaxDuration > 0 {
+		maxDuration := filter.MaxDuration
+		if maxDuration == 0 {
+			maxDuration = math.MaxInt
+		}
+		consider(pe.durationIndex.Range(filter.MinDuration, maxDuration))
+	}
+	if filter.Genre != "" {
+		consider(pe.playlistTree.GetAllSongsInGenre(treeCategory(filter.Genre)))
+	} else if filter.Mood != "" {
+		consider(pe.playlistTree.GetAllSongsInMood(treeCategory(filter.Mood)))
+	}
+
+	if !indexed {
+		return pe.currentPlaylist.ToSlice()
+	}
+	return candidates
+}
+
+// treeCategory normalizes a genre or mood the way the explorer tree names its nodes
+// Time Complexity: O(k) where k is the length of name
+// Space Complexity: O(k)
+func treeCategory(name string) string {
+	return strings.Title(strings.ToLower(strings.TrimSpace(name)))
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func newFilterTestEngine() *PlaylistEngine {
+	engine := NewPlaylistEngine("Filter")
+	engine.config.IDMode = SongIDContentHash
+	songs := []struct {
+		title, genre, mood string
+		duration, bpm      int
+		rating, plays      int
+	}{
+		{"Calm Rock", "Rock", "Chill", 180, 90, 4, 0},
+		{"Fast Rock", "Rock", "Energetic", 240, 150, 5, 3},
+		{"Mid Rock", "rock", "Energetic", 300, 125, 2, 1},
+		{"Jazz", "Jazz", "Chill", 200, 0, 0, 2},
+	}
+	for _, s := range songs {
+		id, _ := engine.AddSong(s.title, "Artist", "Album", s.genre, "Sub", s.mood, s.duration, s.bpm)
+		if s.rating > 0 {
+			engine.RateSong(id, s.rating)
+		}
+		song, _ := engine.songLookup.Get(id)
+		song.PlayCount = s.plays
+		engine.playCountTotal += s.plays
+	}
+	return engine
+}
+
+func filterTitles(t *testing.T, engine *PlaylistEngine, filter SongFilter) []string {
+	t.Helper()
+	songs, err := engine.FilterSongs(filter)
+	if err != nil {
+		t.Fatalf("FilterSongs(%+v) returned %v", filter, err)
+	}
+	titles := make([]string, 0, len(songs))
+	for _, song := range songs {
+		titles = append(titles, song.Title)
+	}
+	return titles
+}
+
+func TestFilterSongs_Combined(t *testing.T) {
+	engine := newFilterTestEngine()
+
+	tests := []struct {
+		name   string
+		filter SongFilter
+		want   []string
+	}{
+		{"no criteria", SongFilter{}, []string{"Calm Rock", "Fast Rock", "Mid Rock", "Jazz"}},
+		{"genre ignores case", SongFilter{Genre: "ROCK"}, []string{"Calm Rock", "Fast Rock", "Mid Rock"}},
+		{"mood only", SongFilter{Mood: "chill"}, []string{"Calm Rock", "Jazz"}},
+		{"rating range skips unrated", SongFilter{MaxRating: 4}, []string{"Calm Rock", "Mid Rock"}},
+		{"bpm range skips unknown", SongFilter{MinBPM: 100}, []string{"Fast Rock", "Mid Rock"}},
+		{"bpm and rating", SongFilter{MinBPM: 100, MaxBPM: 160, MinRating: 3}, []string{"Fast Rock"}},
+		{"genre and mood", SongFilter{Genre: "rock", Mood: "energetic", MaxDuration: 250}, []string{"Fast Rock"}},
+		{"duration and plays", SongFilter{MinDuration: 200, MinPlayCount: 1}, []string{"Fast Rock", "Mid Rock", "Jazz"}},
+		{"nothing matches", SongFilter{Genre: "Pop"}, []string{}},
+	}
+	for _, tt := range tests {
+		got := filterTitles(t, engine, tt.filter)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFilterSongs_AddedAfterAndEdits(t *testing.T) {
+	engine := newFilterTestEngine()
+	cutoff := time.Now()
+	songs := engine.currentPlaylist.ToSlice()
+	songs[0].AddedAt = cutoff.Add(-time.Hour)
+	for _, song := range songs[1:] {
+		song.AddedAt = cutoff.Add(time.Minute)
+	}
+
+	if got := filterTitles(t, engine, SongFilter{AddedAfter: cutoff}); len(got) != 3 || got[0] == "Calm Rock" {
+		t.Errorf("Expected the 3 songs added after the cutoff, got %v", got)
+	}
+
+	// Editing a song's BPM re-files it in the BPM index
+	bpm := 200
+	engine.UpdateSong(songs[0].ID, SongUpdate{BPM: &bpm})
+	if got := filterTitles(t, engine, SongFilter{MinBPM: 190}); len(got) != 1 || got[0] != "Calm Rock" {
+		t.Errorf("Expected the edited song in the new BPM range, got %v", got)
+	}
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes after the edit, got %v", issues)
+	}
+
+	// Editing a song's duration re-files it in the duration tree
+	duration := 600
+	engine.UpdateSong(songs[0].ID, SongUpdate{Duration: &duration})
+	if got := filterTitles(t, engine, SongFilter{MinDuration: 500}); len(got) != 1 || got[0] != "Calm Rock" {
+		t.Errorf("Expected the edited song in the new duration range, got %v", got)
+	}
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes after the duration edit, got %v", issues)
+	}
+
+	engine.DeleteSong(0)
+	if got := filterTitles(t, engine, SongFilter{MinBPM: 190}); len(got) != 0 {
+		t.Errorf("Expected the deleted song gone from the BPM index, got %v", got)
+	}
+	if got := filterTitles(t, engine, SongFilter{MinDuration: 500}); len(got) != 0 {
+		t.Errorf("Expected the deleted song gone from the duration tree, got %v", got)
+	}
+}
+
+func TestFilterSongs_InvalidBounds(t *testing.T) {
+	engine := newFilterTestEngine()
+	for _, filter := range []SongFilter{
+		{MinRating: 4, MaxRating: 2},
+		{MaxRating: 6},
+		{MinBPM: 150, MaxBPM: 100},
+		{MinDuration: -1},
+	} {
+		if _, err := engine.FilterSongs(filter); err == nil {
+			t.Errorf("Expected an error for %+v", filter)
+		}
+	}
+}
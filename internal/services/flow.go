@@ -0,0 +1,197 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"src/internal/models"
+	"strings"
+)
+
+// FlowThresholds controls which transitions AnalyzeFlow flags
+type FlowThresholds struct {
+	// Largest allowed BPM change between adjacent songs, 0 disables the check
+	MaxBPMJump int `json:"max_bpm_jump"`
+	// Flag adjacent songs from different genres
+	FlagGenreChange bool `json:"flag_genre_change"`
+	// Flag a high-energy mood next to a low-energy one
+	FlagMoodClash bool `json:"flag_mood_clash"`
+}
+
+// DefaultFlowThresholds returns the thresholds used by AnalyzeFlow
+func DefaultFlowThresholds() FlowThresholds {
+	return FlowThresholds{
+		MaxBPMJump:      30,
+		FlagGenreChange: true,
+		FlagMoodClash:   true,
+	}
+}
+
+// FlowWarning describes a jarring transition between the songs at Position and Position+1
+type FlowWarning struct {
+	Position  int      `json:"position"`
+	FromTitle string   `json:"from_title"`
+	ToTitle   string   `json:"to_title"`
+	Reasons   []string `json:"reasons"`
+}
+
+// moodEnergy places known moods on a high (+1) or low (-1) energy side
+// Moods missing here are treated as neutral and never clash
+var moodEnergy = map[string]int{
+	"energetic":   1,
+	"aggressive":  1,
+	"upbeat":      1,
+	"uplifting":   1,
+	"happy":       1,
+	"epic":        1,
+	"intense":     1,
+	"relaxed":     -1,
+	"calm":        -1,
+	"peaceful":    -1,
+	"melancholic": -1,
+	"sad":         -1,
+	"dreamy":      -1,
+	"chill":       -1,
+	"mellow":      -1,
+}
+
+// AnalyzeFlow flags adjacent songs with large BPM jumps, genre changes or mood clashes
+// Time Complexity: O(n)
+// Space Complexity: O(n) for the playlist snapshot and warnings
+func (pe *PlaylistEngine) AnalyzeFlow() []FlowWarning {
+	return pe.AnalyzeFlowWithThresholds(DefaultFlowThresholds())
+}
+
+// AnalyzeFlowWithThresholds flags jarring transitions using custom thresholds
+// BPM jumps are only checked when both songs have a known BPM
+// Time Complexity: O(n)
+// Space Complexity: O(n) for the playlist snapshot and warnings
+func (pe *PlaylistEngine) AnalyzeFlowWithThresholds(thresholds FlowThresholds) []FlowWarning {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs := pe.currentPlaylist.ToSlice()
+	warnings := make([]FlowWarning, 0)
+
+	for i := 0; i+1 < len(songs); i++ {
+		from, to := songs[i], songs[i+1]
+		var reasons []string
+
+		if thresholds.MaxBPMJump > 0 && from.BPM > 0 && to.BPM > 0 {
+			jump := to.BPM - from.BPM
+			if jump < 0 {
+				jump = -jump
+			}
+			if jump > thresholds.MaxBPMJump {
+				reasons = append(reasons, fmt.Sprintf("BPM jumps by %d (%d to %d)", jump, from.BPM, to.BPM))
+			}
+		}
+
+		if thresholds.FlagGenreChange && from.Genre != "" && to.Genre != "" && !strings.EqualFold(from.Genre, to.Genre) {
+			reasons = append(reasons, fmt.Sprintf("genre changes from %s to %s", from.Genre, to.Genre))
+		}
+
+		if thresholds.FlagMoodClash && moodEnergy[strings.ToLower(from.Mood)]*moodEnergy[strings.ToLower(to.Mood)] < 0 {
+			reasons = append(reasons, fmt.Sprintf("mood clashes from %s to %s", from.Mood, to.Mood))
+		}
+
+		if len(reasons) > 0 {
+			warnings = append(warnings, FlowWarning{
+				Position:  i,
+				FromTitle: from.Title,
+				ToTitle:   to.Title,
+				Reasons:   reasons,
+			})
+		}
+	}
+
+	return warnings
+}
+
+// OrderByBPMProgression returns the playlist ordered for a gradual BPM ramp without changing it
+// Songs with a known BPM are sorted ascending, which keeps every step between neighbours as small as possible;
+// songs with an unknown BPM follow in their current order
+// Time Complexity: O(n log n)
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) OrderByBPMProgression() []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.orderByBPMProgression())
+}
+
+// orderByBPMProgression implements OrderByBPMProgression for callers already holding pe.mu
+func (pe *PlaylistEngine) orderByBPMProgression() []*models.Song {
+	songs := pe.currentPlaylist.ToSlice()
+
+	known := make([]*models.Song, 0, len(songs))
+	unknown := make([]*models.Song, 0)
+	for _, song := range songs {
+		if song.BPM > 0 {
+			known = append(known, song)
+		} else {
+			unknown = append(unknown, song)
+		}
+	}
+
+	sort.SliceStable(known, func(i, j int) bool {
+		return known[i].BPM < known[j].BPM
+	})
+
+	return append(known, unknown...)
+}
+
+// ApplyBPMRamp reorders the playlist by OrderByBPMProgression and returns the new order
+// Time Complexity: O(n log n)
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) ApplyBPMRamp() []*models.Song {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	songs := pe.orderByBPMProgression()
+
+	pe.currentPlaylist.Clear()
+	for _, song := range songs {
+		pe.currentPlaylist.AddSong(song)
+	}
+	pe.sortState = SortState{Criteria: "bpm_ramp", Description: "BPM ramp (unknown BPM last)"}
+
+	pe.activity.record(ActivitySort, "")
+	return cloneSongs(songs)
+}
+
+// GenerateMoodArc builds a listening journey through moods in the given order without changing the playlist
+// Up to perMood songs are taken from each mood in library order; moods with no songs are skipped
+// and a song already used earlier in the arc is not repeated
+// Time Complexity: O(m * n) where m is the number of moods and n is the number of tree nodes
+// Space Complexity: O(m * perMood)
+func (pe *PlaylistEngine) GenerateMoodArc(moods []string, perMood int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	journey := make([]*models.Song, 0)
+	if perMood <= 0 {
+		return journey
+	}
+
+	used := make(map[string]bool)
+	for _, mood := range moods {
+		mood = strings.Title(strings.ToLower(strings.TrimSpace(mood)))
+		if mood == "" {
+			continue
+		}
+
+		taken := 0
+		for _, song := range pe.playlistTree.GetAllSongsInMood(mood) {
+			if taken == perMood {
+				break
+			}
+			if used[song.ID] {
+				continue
+			}
+			used[song.ID] = true
+			journey = append(journey, song)
+			taken++
+		}
+	}
+	return cloneSongs(journey)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestAnalyzeFlow(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Happy", 200, 130)   // Smooth
+	engine.AddSong("Song 3", "Artist 3", "Album", "Rock", "Alternative", "Peaceful", 200, 70) // BPM jump and mood clash
+	engine.AddSong("Song 4", "Artist 4", "Album", "Jazz", "Smooth Jazz", "Relaxed", 200, 75)  // Genre change
+	engine.AddSong("Song 5", "Artist 5", "Album", "Jazz", "Smooth Jazz", "Relaxed", 200, 0)   // Unknown BPM isn't a jump
+
+	warnings := engine.AnalyzeFlow()
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %d: %+v", len(warnings), warnings)
+	}
+
+	if warnings[0].Position != 1 || warnings[0].FromTitle != "Song 2" || warnings[0].ToTitle != "Song 3" {
+		t.Errorf("Unexpected first warning %+v", warnings[0])
+	}
+	if len(warnings[0].Reasons) != 2 {
+		t.Errorf("Expected BPM and mood reasons, got %v", warnings[0].Reasons)
+	}
+	if warnings[1].Position != 2 || len(warnings[1].Reasons) != 1 {
+		t.Errorf("Expected a genre change at position 2, got %+v", warnings[1])
+	}
+}
+
+func TestAnalyzeFlowWithThresholds(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album", "Jazz", "Smooth Jazz", "Relaxed", 200, 100)
+
+	// Everything disabled flags nothing
+	if warnings := engine.AnalyzeFlowWithThresholds(FlowThresholds{}); len(warnings) != 0 {
+		t.Errorf("Expected no warnings with checks disabled, got %+v", warnings)
+	}
+
+	// Only a tight BPM limit
+	warnings := engine.AnalyzeFlowWithThresholds(FlowThresholds{MaxBPMJump: 10})
+	if len(warnings) != 1 || len(warnings[0].Reasons) != 1 {
+		t.Errorf("Expected only a BPM warning, got %+v", warnings)
+	}
+
+	if warnings := NewPlaylistEngine("Empty").AnalyzeFlow(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for an empty playlist, got %+v", warnings)
+	}
+}
+
+func TestOrderByBPMProgression(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 140)
+	engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Happy", 200, 0)
+	engine.AddSong("Song 3", "Artist 3", "Album", "Pop", "Dance", "Upbeat", 200, 95)
+	engine.AddSong("Song 4", "Artist 4", "Album", "Jazz", "Smooth Jazz", "Relaxed", 200, 120)
+	engine.AddSong("Song 5", "Artist 5", "Album", "Jazz", "Smooth Jazz", "Relaxed", 200, 0)
+	engine.AddSong("Song 6", "Artist 6", "Album", "Pop", "Dance", "Happy", 200, 120)
+
+	ordered := engine.OrderByBPMProgression()
+	if len(ordered) != 6 {
+		t.Fatalf("Expected 6 songs, got %d", len(ordered))
+	}
+	for i := 1; i < 4; i++ {
+		if ordered[i].BPM < ordered[i-1].BPM {
+			t.Errorf("BPM drops from %d to %d at position %d", ordered[i-1].BPM, ordered[i].BPM, i)
+		}
+	}
+	if ordered[1].Title != "Song 4" || ordered[2].Title != "Song 6" {
+		t.Errorf("Expected equal BPMs to keep playlist order, got %s then %s", ordered[1].Title, ordered[2].Title)
+	}
+	if ordered[4].Title != "Song 2" || ordered[5].Title != "Song 5" {
+		t.Errorf("Expected unknown BPMs last in playlist order, got %s then %s", ordered[4].Title, ordered[5].Title)
+	}
+
+	// Computing the order leaves the playlist alone
+	if first, _ := engine.currentPlaylist.GetSong(0); first.Title != "Song 1" {
+		t.Errorf("Expected playlist unchanged, first song is %s", first.Title)
+	}
+
+	engine.ApplyBPMRamp()
+	applied := engine.GetCurrentPlaylist()
+	for i, song := range applied {
+		if song.ID != ordered[i].ID {
+			t.Errorf("Position %d: expected %s, got %s", i, ordered[i].Title, song.Title)
+		}
+	}
+	if engine.GetPlaylistSize() != 6 {
+		t.Errorf("Expected 6 songs after applying, got %d", engine.GetPlaylistSize())
+	}
+}
+
+func TestGenerateMoodArc(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Calm 1", "Artist 1", "Album", "Jazz", "Smooth Jazz", "Calm", 200, 70)
+	engine.AddSong("Energetic 1", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 140)
+	engine.AddSong("Calm 2", "Artist 3", "Album", "Classical", "Piano", "Calm", 200, 60)
+	engine.AddSong("Happy 1", "Artist 4", "Album", "Pop", "Dance", "Happy", 200, 110)
+	engine.AddSong("Calm 3", "Artist 5", "Album", "Jazz", "Smooth Jazz", "Calm", 200, 75)
+	engine.AddSong("Energetic 2", "Artist 6", "Album", "Pop", "Dance", "Energetic", 200, 128)
+
+	arc := engine.GenerateMoodArc([]string{"calm", "Happy", "Melancholic", "ENERGETIC"}, 2)
+	if len(arc) != 5 {
+		t.Fatalf("Expected 5 songs (2 calm, 1 happy, 2 energetic), got %d", len(arc))
+	}
+
+	expectedMoods := []string{"Calm", "Calm", "Happy", "Energetic", "Energetic"}
+	for i, mood := range expectedMoods {
+		if arc[i].Mood != mood {
+			t.Errorf("Position %d: expected mood %s, got %s", i, mood, arc[i].Mood)
+		}
+	}
+
+	// Generating the arc leaves the playlist alone
+	if first, _ := engine.currentPlaylist.GetSong(0); first.Title != "Calm 1" {
+		t.Errorf("Expected playlist unchanged, first song is %s", first.Title)
+	}
+
+	if arc := engine.GenerateMoodArc([]string{"Calm", "Calm"}, 2); len(arc) != 3 {
+		t.Errorf("Expected a repeated mood not to repeat songs, got %d songs", len(arc))
+	}
+	if arc := engine.GenerateMoodArc([]string{"Calm"}, 0); len(arc) != 0 {
+		t.Errorf("Expected no songs for perMood 0, got %d", len(arc))
+	}
+}
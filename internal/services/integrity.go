@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"src/internal/datastructures"
+	"src/internal/models"
+)
+
+// VerifyIntegrity cross-checks every index and cached aggregate against the playlist
+// Returns one message per inconsistency found, empty when all structures agree
+// Time Complexity: O(n * t) where t is the explorer tree size, for the per-song tree paths
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) VerifyIntegrity() []string {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.verifyIntegrity()
+}
+
+// verifyIntegrity implements VerifyIntegrity for callers already holding pe.mu
+func (pe *PlaylistEngine) verifyIntegrity() []string {
+	issues := make([]string, 0)
+	songs := pe.currentPlaylist.ToSlice()
+
+	totalDuration, playCount, rated, withBPM := 0, 0, 0, 0
+	artists, albums := make(map[string]int), make(map[albumKey]int)
+	for _, song := range songs {
+		totalDuration += song.Duration
+		playCount += song.PlayCount
+		artists[normalizeArtist(song.Artist)]++
+		if album := albumKeyFor(song); album.album != "" {
+			albums[album]++
+		}
+		if song.BPM > 0 {
+			withBPM++
+		}
+
+		if indexed, err := pe.songLookup.Get(song.ID); err != nil || indexed != song {
+			issues = append(issues, fmt.Sprintf("song '%s' is missing from the ID lookup", song.ID))
+		}
+		if !pe.titleLookup.Contains(song) {
+			issues = append(issues, fmt.Sprintf("song '%s' is missing from the title lookup", song.ID))
+		}
+		if _, err := pe.playlistTree.FindSongPath(song.ID); err != nil {
+			issues = append(issues, fmt.Sprintf("song '%s' is missing from the explorer tree", song.ID))
+		}
+		if song.Rating > 0 {
+			rated++
+			if !pe.containsSong(pe.ratingTree.SearchByRating(song.Rating), song.ID) {
+				issues = append(issues, fmt.Sprintf("song '%s' is missing from rating %d in the rating tree", song.ID, song.Rating))
+			}
+		}
+	}
+
+	if size := pe.songLookup.GetSize(); size != len(songs) {
+		issues = append(issues, fmt.Sprintf("ID lookup holds %d songs, playlist has %d", size, len(songs)))
+	}
+	if indexed := pe.titleLookup.Len(); indexed != len(songs) {
+		issues = append(issues, fmt.Sprintf("title lookup holds %d songs, playlist has %d", indexed, len(songs)))
+	}
+	if total := pe.ratingTree.GetTotalSongs(); total != rated {
+		issues = append(issues, fmt.Sprintf("rating tree holds %d songs, playlist has %d rated", total, rated))
+	}
+	if indexed := pe.fuzzyIndex.Len(); indexed != len(songs) {
+		issues = append(issues, fmt.Sprintf("fuzzy search index holds %d songs, playlist has %d", indexed, len(songs)))
+	}
+	if indexed := pe.similarityIndex.Len(); indexed != len(songs) {
+		issues = append(issues, fmt.Sprintf("similarity index holds %d songs, playlist has %d", indexed, len(songs)))
+	}
+	if indexed := pe.bpmIndex.Len(); indexed != withBPM {
+		issues = append(issues, fmt.Sprintf("BPM index holds %d songs, playlist has %d with a BPM", indexed, withBPM))
+	}
+	if indexed := pe.durationIndex.Len(); indexed != len(songs) {
+		issues = append(issues, fmt.Sprintf("duration index holds %d songs, playlist has %d", indexed, len(songs)))
+	}
+	if total := pe.playlistTree.TotalSongs; total != len(songs) {
+		issues = append(issues, fmt.Sprintf("explorer tree holds %d songs, playlist has %d", total, len(songs)))
+	}
+	if pe.totalPlayTime != totalDuration {
+		issues = append(issues, fmt.Sprintf("cached total duration is %d, recomputed %d", pe.totalPlayTime, totalDuration))
+	}
+	if pe.playCountTotal != playCount {
+		issues = append(issues, fmt.Sprintf("cached play count is %d, recomputed %d", pe.playCountTotal, playCount))
+	}
+	if len(pe.artistIndex) != len(artists) {
+		issues = append(issues, fmt.Sprintf("artist index holds %d artists, playlist has %d", len(pe.artistIndex), len(artists)))
+	}
+	for artist, count := range artists {
+		if len(pe.artistIndex[artist]) != count {
+			issues = append(issues, fmt.Sprintf("artist index holds %d songs for '%s', playlist has %d", len(pe.artistIndex[artist]), artist, count))
+		}
+	}
+	if len(pe.albumIndex) != len(albums) {
+		issues = append(issues, fmt.Sprintf("album index holds %d albums, playlist has %d", len(pe.albumIndex), len(albums)))
+	}
+	for album, count := range albums {
+		if len(pe.albumIndex[album]) != count {
+			issues = append(issues, fmt.Sprintf("album index holds %d songs for '%s' by '%s', playlist has %d", len(pe.albumIndex[album]), album.album, album.artist, count))
+		}
+	}
+
+	return issues
+}
+
+// RepairIndexes rebuilds every index and cached aggregate from the playlist, the authoritative copy
+// Returns the issues VerifyIntegrity found before the repair
+// Time Complexity: O(n log n) for the rating tree inserts, plus the O(n * t) verification
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) RepairIndexes() []string {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	issues := pe.verifyIntegrity()
+
+	pe.songLookup = datastructures.NewSongHashMap(initialLookupCapacity)
+	pe.titleLookup = datastructures.NewTitleIndex(initialLookupCapacity)
+	pe.ratingTree = datastructures.NewSongRatingBST()
+	if pe.config.CacheRatingTree {
+		pe.ratingTree.EnableCache(true)
+	}
+	pe.playlistTree = datastructures.NewPlaylistExplorerTreeWithLabels(pe.config.TreeLabels)
+	pe.artistIndex = make(map[string][]*models.Song)
+	pe.albumIndex = make(map[albumKey][]*models.Song)
+	pe.searchCache.Clear()
+	pe.fuzzyIndex.Clear()
+	pe.similarityIndex.Clear()
+	pe.bpmIndex.Clear()
+	pe.durationIndex.Clear()
+	pe.totalPlayTime = 0
+	pe.playCountTotal = 0
+
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		pe.songLookup.Put(song)
+		pe.titleLookup.Add(song)
+		pe.indexFuzzy(song)
+		pe.similarityIndex.Add(song)
+		pe.bpmIndex.Add(song)
+		pe.durationIndex.Insert(song.Duration, song)
+		pe.playlistTree.AddSong(song)
+		if song.Rating > 0 {
+			pe.ratingTree.InsertSong(song, song.Rating)
+		}
+		pe.indexArtist(song)
+		pe.indexAlbum(song)
+		pe.totalPlayTime += song.Duration
+		pe.playCountTotal += song.PlayCount
+	}
+
+	return issues
+}
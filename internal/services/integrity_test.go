@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestVerifyIntegrity(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	id1, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album", "Jazz", "Smooth", "Relaxed", 300, 90)
+	engine.RateSong(id1, 5)
+	engine.PlaySong(1)
+	engine.DeleteSong(1)
+
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected a consistent engine, got %v", issues)
+	}
+}
+
+func TestRepairIndexes(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	id1, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	id2, _ := engine.AddSong("Song 2", "Artist 2", "Album", "Jazz", "Smooth", "Relaxed", 300, 90)
+	engine.RateSong(id1, 5)
+
+	// Corrupt the indexes behind the engine's back
+	engine.songLookup.Delete(id2)
+	engine.ratingTree.DeleteSong(id1)
+	engine.playlistTree.RemoveSong(id2)
+	engine.totalPlayTime = 0
+
+	issues := engine.VerifyIntegrity()
+	if len(issues) < 4 {
+		t.Fatalf("Expected at least 4 issues, got %v", issues)
+	}
+
+	if repaired := engine.RepairIndexes(); len(repaired) != len(issues) {
+		t.Errorf("Expected RepairIndexes to report the %d issues it fixed, got %v", len(issues), repaired)
+	}
+	if remaining := engine.VerifyIntegrity(); len(remaining) != 0 {
+		t.Errorf("Expected no issues after repair, got %v", remaining)
+	}
+
+	if _, err := engine.SearchSongByID(id2); err != nil {
+		t.Errorf("Expected Song 2 back in the ID lookup, got %v", err)
+	}
+	if songs := engine.GetSongsByRating(5); len(songs) != 1 || songs[0].ID != id1 {
+		t.Errorf("Expected Song 1 back in the rating tree, got %v", songs)
+	}
+	if engine.GetTotalDuration() != 500 {
+		t.Errorf("Expected total duration 500 after repair, got %d", engine.GetTotalDuration())
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ImportM3U reads an extended M3U playlist and adds each #EXTINF entry to the engine
+// Entries are expected as "#EXTINF:<seconds>,Artist - Title" followed by a path line
+// Genre, subgenre and mood are unknown in M3U, so imported songs land in the tree's Unknown buckets
+// Lines that can't be parsed or songs that can't be added are recorded in errs instead of aborting
+// Time Complexity: O(l * n) where l is the number of lines and n the playlist size (duplicate check)
+// Space Complexity: O(e) where e is the number of errors
+func ImportM3U(engine *PlaylistEngine, r io.Reader) (added int, errs []error) {
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	pendingInfo := false
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || line == "#EXTM3U" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#EXTINF:") {
+			// The following path line belongs to this entry even if it fails to import
+			pendingInfo = true
+
+			title, artist, duration, err := parseExtInf(strings.TrimPrefix(line, "#EXTINF:"))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("line %d: %v", lineNumber, err))
+				continue
+			}
+
+			if _, err := engine.AddSong(title, artist, "", "", "", "", duration, 0); err != nil {
+				errs = append(errs, fmt.Errorf("line %d: %v", lineNumber, err))
+				continue
+			}
+
+			added++
+			continue
+		}
+
+		// Other directives and comments are ignored
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Path line: fine after an #EXTINF entry, otherwise there is no metadata to import
+		if !pendingInfo {
+			errs = append(errs, fmt.Errorf("line %d: missing #EXTINF metadata for %q", lineNumber, line))
+		}
+		pendingInfo = false
+	}
+
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to read M3U: %v", err))
+	}
+
+	return added, errs
+}
+
+// parseExtInf parses the "<seconds>,Artist - Title" part of an #EXTINF line
+// A negative duration (unknown length) is treated as 0
+// Time Complexity: O(k) where k is the line length
+// Space Complexity: O(k)
+func parseExtInf(info string) (title, artist string, duration int, err error) {
+	durationStr, display, found := strings.Cut(info, ",")
+	if !found {
+		return "", "", 0, fmt.Errorf("malformed #EXTINF, expected '<seconds>,Artist - Title'")
+	}
+
+	// Attributes such as tvg-id may follow the duration, separated by spaces
+	if fields := strings.Fields(durationStr); len(fields) > 0 {
+		durationStr = fields[0]
+	}
+
+	duration, err = strconv.Atoi(strings.TrimSpace(durationStr))
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid duration %q", durationStr)
+	}
+	if duration < 0 {
+		duration = 0
+	}
+
+	artist, title, found = strings.Cut(display, " - ")
+	if !found || strings.TrimSpace(artist) == "" || strings.TrimSpace(title) == "" {
+		return "", "", 0, fmt.Errorf("expected 'Artist - Title', got %q", strings.TrimSpace(display))
+	}
+
+	return strings.TrimSpace(title), strings.TrimSpace(artist), duration, nil
+}
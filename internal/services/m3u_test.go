@@ -0,0 +1,79 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImportM3U(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	m3u := `#EXTM3U
+#EXTINF:354,Queen - Bohemian Rhapsody
+/music/queen/bohemian_rhapsody.mp3
+#EXTINF:-1,Daft Punk - Around the World
+around_the_world.mp3
+#EXTINF:abc,Broken - Duration
+broken.mp3
+#EXTINF:200,No Separator Here
+no_separator.mp3
+orphan.mp3
+`
+
+	added, errs := ImportM3U(engine, strings.NewReader(m3u))
+	if added != 2 {
+		t.Errorf("Expected 2 songs added, got %d", added)
+	}
+	if len(errs) != 3 {
+		t.Errorf("Expected 3 errors (bad duration, bad display, orphan path), got %d: %v", len(errs), errs)
+	}
+
+	songs := engine.GetCurrentPlaylist()
+	if len(songs) != 2 {
+		t.Fatalf("Expected 2 songs in playlist, got %d", len(songs))
+	}
+	if songs[0].Title != "Bohemian Rhapsody" || songs[0].Artist != "Queen" || songs[0].Duration != 354 {
+		t.Errorf("Unexpected first song: %+v", songs[0])
+	}
+	if songs[1].Duration != 0 {
+		t.Errorf("Expected unknown duration to import as 0, got %d", songs[1].Duration)
+	}
+
+	// Without genre info songs land in the tree's Unknown buckets
+	genres := engine.GetGenres()
+	if len(genres) != 1 || genres[0] != "Unknown Genre" {
+		t.Errorf("Expected only the Unknown Genre bucket, got %v", genres)
+	}
+	unknown := engine.GetPlaylistByExplorer("Unknown Genre", "Unknown Subgenre", "Unknown Mood", "Queen")
+	if len(unknown) != 1 || unknown[0].Title != "Bohemian Rhapsody" {
+		t.Errorf("Expected imported song under Unknown buckets, got %v", unknown)
+	}
+}
+
+func TestImportM3UDuplicates(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	m3u := "#EXTINF:100,Artist - Song\nsong.mp3\n#EXTINF:100,Artist - Song\nsong.mp3\n"
+
+	added, errs := ImportM3U(engine, strings.NewReader(m3u))
+	if added != 1 {
+		t.Errorf("Expected 1 song added, got %d", added)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected duplicate to be reported as an error, got %v", errs)
+	}
+}
+
+func TestParseExtInf(t *testing.T) {
+	title, artist, duration, err := parseExtInf(`180 tvg-id="x",AC/DC - Back In Black - Remastered`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if artist != "AC/DC" || title != "Back In Black - Remastered" || duration != 180 {
+		t.Errorf("Unexpected parse result: %q %q %d", artist, title, duration)
+	}
+
+	if _, _, _, err := parseExtInf("180"); err == nil {
+		t.Error("Expected error for missing display name")
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"src/internal/models"
+	"strings"
+)
+
+// DefaultMixBPMStep is the largest BPM change between consecutive songs of a mix when none is given
+const DefaultMixBPMStep = 10
+
+// MixOptions controls how GenerateMix sequences songs
+type MixOptions struct {
+	// Largest allowed BPM change between consecutive songs, 0 uses DefaultMixBPMStep
+	MaxBPMStep int
+	// ID of the song the mix opens with, empty starts from the slowest song
+	StartSongID string
+	// Maximum number of songs in the mix, 0 means as many as can be chained
+	Length int
+}
+
+// MixTransition describes the step from one song of a mix to the next
+type MixTransition struct {
+	FromTitle string `json:"from_title"`
+	ToTitle   string `json:"to_title"`
+	BPMChange int    `json:"bpm_change"`
+}
+
+// Mix is a DJ-style ordering of songs where every step stays within the BPM limit without a mood clash
+type Mix struct {
+	Songs       []*models.Song  `json:"songs"`
+	Transitions []MixTransition `json:"transitions"`
+	// Songs with a known BPM that couldn't be chained into the mix
+	Unplaced int `json:"unplaced"`
+	// Songs left out because their BPM is unknown
+	UnknownBPM int `json:"unknown_bpm"`
+}
+
+// moodsCompatible reports whether two moods can follow each other in a mix: a high-energy mood
+// never meets a low-energy one, as AnalyzeFlow would flag it, and neutral moods fit anywhere
+// Time Complexity: O(k) where k is the length of the moods
+// Space Complexity: O(1)
+func moodsCompatible(a, b string) bool {
+	return moodEnergy[strings.ToLower(a)]*moodEnergy[strings.ToLower(b)] >= 0
+}
+
+// GenerateMix sequences songs into a DJ mix without changing the playlist
+// Starting from the chosen song, each next song is picked greedily from the BPM index among the
+// unused songs within MaxBPMStep of the current tempo whose mood doesn't clash: the smallest step
+// up wins so the mix builds energy, then the smallest step down, preferring the same mood on ties.
+// The mix ends when no song can follow or Length is reached
+// Time Complexity: O(m * (log d + w)) where m is the mix length, d the number of distinct BPMs and
+// w the number of songs within one BPM window
+// Space Complexity: O(m)
+func (pe *PlaylistEngine) GenerateMix(options MixOptions) (*Mix, error) {
+	if options.MaxBPMStep < 0 || options.Length < 0 {
+		return nil, fmt.Errorf("max BPM step and length cannot be negative")
+	}
+	step := options.MaxBPMStep
+	if step == 0 {
+		step = DefaultMixBPMStep
+	}
+
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	var current *models.Song
+	if options.StartSongID != "" {
+		song, err := pe.songLookup.Get(options.StartSongID)
+		if err != nil {
+			return nil, err
+		}
+		if song.BPM <= 0 {
+			return nil, fmt.Errorf("song '%s' has no BPM to start a mix from", song.Title)
+		}
+		current = song
+	} else if slowest := pe.bpmIndex.Range(1, 0); len(slowest) > 0 {
+		current = slowest[0]
+	}
+
+	indexed := pe.bpmIndex.Len()
+	mix := &Mix{
+		Songs:       []*models.Song{},
+		Transitions: []MixTransition{},
+		UnknownBPM:  pe.songLookup.GetSize() - indexed,
+	}
+	used := make(map[string]bool)
+	for current != nil {
+		mix.Songs = append(mix.Songs, current)
+		used[current.ID] = true
+		if options.Length > 0 && len(mix.Songs) == options.Length {
+			break
+		}
+
+		next := pe.nextInMix(current, step, used)
+		if next != nil {
+			mix.Transitions = append(mix.Transitions, MixTransition{
+				FromTitle: current.Title,
+				ToTitle:   next.Title,
+				BPMChange: next.BPM - current.BPM,
+			})
+		}
+		current = next
+	}
+
+	mix.Unplaced = indexed - len(mix.Songs)
+	mix.Songs = cloneSongs(mix.Songs)
+	return mix, nil
+}
+
+// nextInMix picks the song to follow current in a mix, or nil when none fits
+// Time Complexity: O(log d + w) where w is the number of songs within step BPM of current
+// Space Complexity: O(w)
+func (pe *PlaylistEngine) nextInMix(current *models.Song, step int, used map[string]bool) *models.Song {
+	var best *models.Song
+	better := func(candidate *models.Song) bool {
+		if best == nil {
+			return true
+		}
+		up, bestUp := candidate.BPM >= current.BPM, best.BPM >= current.BPM
+		if up != bestUp {
+			return up
+		}
+		if delta, bestDelta := bpmDistance(candidate, current), bpmDistance(best, current); delta != bestDelta {
+			return delta < bestDelta
+		}
+		return strings.EqualFold(candidate.Mood, current.Mood) && !strings.EqualFold(best.Mood, current.Mood)
+	}
+
+	for _, candidate := range pe.bpmIndex.Range(max(current.BPM-step, 1), current.BPM+step) {
+		if used[candidate.ID] || !moodsCompatible(current.Mood, candidate.Mood) {
+			continue
+		}
+		if better(candidate) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// bpmDistance returns how many BPM apart two songs are
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func bpmDistance(a, b *models.Song) int {
+	if a.BPM > b.BPM {
+		return a.BPM - b.BPM
+	}
+	return b.BPM - a.BPM
+}
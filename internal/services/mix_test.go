@@ -0,0 +1,98 @@
+package services
+
+import "testing"
+
+func newMixTestEngine(songs []struct {
+	title, mood string
+	bpm         int
+}) *PlaylistEngine {
+	engine := NewPlaylistEngine("Mix")
+	for _, s := range songs {
+		engine.AddSong(s.title, "Artist", "Album", "House", "Deep", s.mood, 200, s.bpm)
+	}
+	return engine
+}
+
+func mixTitles(mix *Mix) []string {
+	titles := make([]string, 0, len(mix.Songs))
+	for _, song := range mix.Songs {
+		titles = append(titles, song.Title)
+	}
+	return titles
+}
+
+func TestGenerateMix_RampsWithinStep(t *testing.T) {
+	engine := newMixTestEngine([]struct {
+		title, mood string
+		bpm         int
+	}{
+		{"B", "Upbeat", 124},
+		{"A", "Upbeat", 118},
+		{"Clash", "Chill", 126},
+		{"C", "Energetic", 130},
+		{"Far", "Energetic", 170},
+		{"Unknown", "Energetic", 0},
+	})
+
+	mix, err := engine.GenerateMix(MixOptions{MaxBPMStep: 8})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := []string{"A", "B", "C"}
+	got := mixTitles(mix)
+	if len(got) != len(want) {
+		t.Fatalf("Expected mix %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected mix %v, got %v", want, got)
+		}
+	}
+	for _, transition := range mix.Transitions {
+		if transition.BPMChange > 8 || transition.BPMChange < -8 {
+			t.Errorf("Expected every step within 8 BPM, got %+v", transition)
+		}
+	}
+	if mix.Unplaced != 2 || mix.UnknownBPM != 1 {
+		t.Errorf("Expected 2 unplaced songs and 1 without a BPM, got %d and %d", mix.Unplaced, mix.UnknownBPM)
+	}
+}
+
+func TestGenerateMix_StartAndLength(t *testing.T) {
+	engine := newMixTestEngine([]struct {
+		title, mood string
+		bpm         int
+	}{
+		{"Slow", "Calm", 100},
+		{"Mid", "Calm", 105},
+		{"Top", "Calm", 110},
+		{"Silent", "Calm", 0},
+	})
+	songs := engine.GetCurrentPlaylist()
+
+	// From the top there's nothing faster, so the mix steps back down
+	mix, _ := engine.GenerateMix(MixOptions{StartSongID: songs[2].ID})
+	if got := mixTitles(mix); len(got) != 3 || got[0] != "Top" || got[1] != "Mid" || got[2] != "Slow" {
+		t.Errorf("Expected the mix to walk down from Top, got %v", got)
+	}
+
+	mix, _ = engine.GenerateMix(MixOptions{Length: 2})
+	if len(mix.Songs) != 2 || len(mix.Transitions) != 1 {
+		t.Errorf("Expected a 2 song mix with 1 transition, got %v", mixTitles(mix))
+	}
+
+	if _, err := engine.GenerateMix(MixOptions{StartSongID: songs[3].ID}); err == nil {
+		t.Error("Expected an error starting from a song without a BPM")
+	}
+	if _, err := engine.GenerateMix(MixOptions{StartSongID: "missing"}); err == nil {
+		t.Error("Expected an error starting from an unknown song")
+	}
+	if _, err := engine.GenerateMix(MixOptions{MaxBPMStep: -1}); err == nil {
+		t.Error("Expected an error for a negative BPM step")
+	}
+
+	if mix, _ := NewPlaylistEngine("Empty").GenerateMix(MixOptions{}); len(mix.Songs) != 0 {
+		t.Errorf("Expected an empty mix from an empty playlist, got %v", mixTitles(mix))
+	}
+}
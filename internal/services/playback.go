@@ -0,0 +1,182 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"src/internal/models"
+	"time"
+)
+
+// ErrNothingPlaying is returned by the pause, resume and seek controls when no song is loaded
+var ErrNothingPlaying = errors.New("nothing is playing")
+
+// PlaybackState is what the player is doing with the current song
+type PlaybackState string
+
+const (
+	PlaybackStopped PlaybackState = "stopped" // Nothing played yet, or the end of the playlist was reached
+	PlaybackPlaying PlaybackState = "playing"
+	PlaybackPaused  PlaybackState = "paused"
+)
+
+// NowPlaying describes the current song and how far into it playback is, in whole seconds
+type NowPlaying struct {
+	Song      *models.Song  `json:"song"`
+	State     PlaybackState `json:"state"`
+	Position  int           `json:"position"`
+	Remaining int           `json:"remaining"`
+}
+
+// GetNowPlaying returns the current song and playback position
+// When the current song has run out the player first advances, as NextSong would, to the
+// song that is playing by now
+// Time Complexity: O(1) average, plus any songs auto-advanced through since the last call
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) GetNowPlaying() NowPlaying {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.syncPlayback()
+	return pe.nowPlaying()
+}
+
+// PausePlayback pauses the current song, keeping its position; pausing twice is harmless
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) PausePlayback() (NowPlaying, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.syncPlayback()
+	p := pe.player
+	if p.state == PlaybackStopped {
+		return pe.nowPlaying(), ErrNothingPlaying
+	}
+	if p.state == PlaybackPlaying {
+		p.elapsed = pe.playbackPosition()
+		p.resumedAt = time.Time{}
+		p.state = PlaybackPaused
+	}
+	return pe.nowPlaying(), nil
+}
+
+// ResumePlayback continues a paused song from where it was paused; resuming while playing is harmless
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) ResumePlayback() (NowPlaying, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.syncPlayback()
+	p := pe.player
+	if p.state == PlaybackStopped {
+		return pe.nowPlaying(), ErrNothingPlaying
+	}
+	if p.state == PlaybackPaused {
+		p.resumedAt = p.clock()
+		p.state = PlaybackPlaying
+		pe.syncPlayback()
+	}
+	return pe.nowPlaying(), nil
+}
+
+// SeekPlayback moves to position seconds into the current song, keeping it playing or paused
+// Seeking to the very end of a playing song advances to the next one
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) SeekPlayback(position int) (NowPlaying, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.syncPlayback()
+	p := pe.player
+	if p.state == PlaybackStopped {
+		return pe.nowPlaying(), ErrNothingPlaying
+	}
+	song, err := pe.songLookup.Get(p.currentID)
+	if err != nil {
+		return pe.nowPlaying(), ErrNothingPlaying
+	}
+	if position < 0 || (song.Duration > 0 && position > song.Duration) {
+		return pe.nowPlaying(), fmt.Errorf("position must be between 0 and %d seconds", song.Duration)
+	}
+
+	p.elapsed = time.Duration(position) * time.Second
+	if p.state == PlaybackPlaying {
+		p.resumedAt = p.clock()
+		pe.syncPlayback()
+	}
+	return pe.nowPlaying(), nil
+}
+
+// syncPlayback catches the player up with the clock: while the current song has played past
+// its duration the next song starts, as NextSong would pick it, at the moment the previous one
+// ended; running out of songs stops the player at the end of the last one
+// Songs without a duration play until skipped, and a deleted current song stops the player
+// At most playerBackLimit songs are advanced through per call, so a long idle period stays cheap
+// Time Complexity: O(a) where a is the number of songs advanced through
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) syncPlayback() {
+	p := pe.player
+	for advanced := 0; p.state != PlaybackStopped && advanced < playerBackLimit; advanced++ {
+		song, err := pe.songLookup.Get(p.currentID)
+		if err != nil {
+			p.stop(0)
+			return
+		}
+		if p.state != PlaybackPlaying || song.Duration <= 0 {
+			return
+		}
+
+		length := time.Duration(song.Duration) * time.Second
+		if pe.playbackPosition() < length {
+			return
+		}
+		endedAt := p.resumedAt.Add(length - p.elapsed)
+
+		if _, err := pe.advance(endedAt); err != nil {
+			p.stop(length)
+			return
+		}
+	}
+}
+
+// playbackPosition returns how far into the current song playback is
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) playbackPosition() time.Duration {
+	p := pe.player
+	if p.state != PlaybackPlaying {
+		return p.elapsed
+	}
+	return p.elapsed + p.clock().Sub(p.resumedAt)
+}
+
+// nowPlaying builds the NowPlaying view of the player for callers already holding pe.mu
+// The song is a copy, so the view stays valid once the lock is released
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) nowPlaying() NowPlaying {
+	status := NowPlaying{State: pe.player.state}
+	song, err := pe.songLookup.Get(pe.player.currentID)
+	if err != nil {
+		return status
+	}
+
+	status.Song = song.Clone()
+	status.Position = int(pe.playbackPosition() / time.Second)
+	if song.Duration > 0 {
+		status.Position = min(status.Position, song.Duration)
+		status.Remaining = song.Duration - status.Position
+	}
+	return status
+}
+
+// stop halts playback with the position left at elapsed
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (p *player) stop(elapsed time.Duration) {
+	p.state = PlaybackStopped
+	p.elapsed = elapsed
+	p.resumedAt = time.Time{}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for the player
+type fakeClock struct {
+	now time.Time
+}
+
+func (fc *fakeClock) Now() time.Time {
+	return fc.now
+}
+
+func (fc *fakeClock) Advance(seconds int) {
+	fc.now = fc.now.Add(time.Duration(seconds) * time.Second)
+}
+
+func newPlaybackTestEngine(songs int) (*PlaylistEngine, *fakeClock) {
+	engine := newPlayerTestEngine(songs)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	engine.player.clock = clock.Now
+	return engine, clock
+}
+
+func TestPlayback_PauseResumeSeek(t *testing.T) {
+	engine, clock := newPlaybackTestEngine(2)
+
+	if _, err := engine.PausePlayback(); !errors.Is(err, ErrNothingPlaying) {
+		t.Errorf("Expected ErrNothingPlaying before anything is played, got %v", err)
+	}
+	if status := engine.GetNowPlaying(); status.State != PlaybackStopped || status.Song != nil {
+		t.Errorf("Expected a stopped player, got %+v", status)
+	}
+
+	engine.PlaySong(0)
+	clock.Advance(30)
+	if status := engine.GetNowPlaying(); status.State != PlaybackPlaying || status.Position != 30 || status.Remaining != 170 {
+		t.Errorf("Expected 30 seconds into Song 0, got %+v", status)
+	}
+
+	status, err := engine.PausePlayback()
+	if err != nil || status.State != PlaybackPaused || status.Position != 30 {
+		t.Errorf("Expected a pause at 30 seconds, got %+v, %v", status, err)
+	}
+	clock.Advance(1000)
+	if status := engine.GetNowPlaying(); status.Position != 30 || status.Song.Title != "Song 0" {
+		t.Errorf("Expected the position to hold while paused, got %+v", status)
+	}
+
+	if status, _ := engine.SeekPlayback(90); status.State != PlaybackPaused || status.Position != 90 {
+		t.Errorf("Expected seeking to keep the song paused at 90, got %+v", status)
+	}
+	if _, err := engine.SeekPlayback(201); err == nil {
+		t.Error("Expected an error seeking past the end of the song")
+	}
+	if _, err := engine.SeekPlayback(-1); err == nil {
+		t.Error("Expected an error seeking before the start of the song")
+	}
+
+	engine.ResumePlayback()
+	clock.Advance(10)
+	if status := engine.GetNowPlaying(); status.State != PlaybackPlaying || status.Position != 100 {
+		t.Errorf("Expected playback to continue from 90, got %+v", status)
+	}
+}
+
+func TestPlayback_AutoAdvance(t *testing.T) {
+	engine, clock := newPlaybackTestEngine(3)
+	queued := engine.GetCurrentPlaylist()[2]
+	engine.EnqueueSong(queued.ID)
+
+	engine.PlaySong(0)
+	clock.Advance(215)
+	status := engine.GetNowPlaying()
+	if status.Song.ID != queued.ID || status.Position != 15 {
+		t.Errorf("Expected the queued song 15 seconds in, got %+v", status)
+	}
+
+	// The queued song doesn't move the playlist position, so Song 1 follows it
+	clock.Advance(185)
+	status = engine.GetNowPlaying()
+	if status.Song.Title != "Song 1" || status.Position != 0 {
+		t.Errorf("Expected Song 1 to have just started, got %+v", status)
+	}
+	if played := engine.GetCurrentPlaylist()[1].PlayCount; played != 1 {
+		t.Errorf("Expected the auto-advanced song to be counted as played, got %d", played)
+	}
+
+	// Seeking to the end of a playing song finishes it
+	if status, _ := engine.SeekPlayback(200); status.Song.Title != "Song 2" || status.Position != 0 {
+		t.Errorf("Expected seeking to the end to start Song 2, got %+v", status)
+	}
+
+	// Running out of songs stops at the end of the last one
+	clock.Advance(500)
+	status = engine.GetNowPlaying()
+	if status.State != PlaybackStopped || status.Song.Title != "Song 2" || status.Remaining != 0 {
+		t.Errorf("Expected playback stopped at the end of Song 2, got %+v", status)
+	}
+	if _, err := engine.ResumePlayback(); !errors.Is(err, ErrNothingPlaying) {
+		t.Errorf("Expected ErrNothingPlaying after the playlist ended, got %v", err)
+	}
+}
+
+func TestPlayback_DeletedSongStops(t *testing.T) {
+	engine, _ := newPlaybackTestEngine(2)
+	engine.PlaySong(0)
+	engine.DeleteSong(0)
+
+	if status := engine.GetNowPlaying(); status.State != PlaybackStopped || status.Song != nil {
+		t.Errorf("Expected deleting the current song to stop playback, got %+v", status)
+	}
+
+	engine.PlaySong(0)
+	engine.ClearPlaylist()
+	if status := engine.GetNowPlaying(); status.State != PlaybackStopped {
+		t.Errorf("Expected clearing the playlist to stop playback, got %+v", status)
+	}
+}
+
+func TestPlayback_AutoAdvanceRecordsStartTime(t *testing.T) {
+	engine, clock := newPlaybackTestEngine(3)
+	start := clock.Now()
+
+	engine.PlaySong(0)
+	clock.Advance(450) // Song 1 started at 200s, Song 2 at 400s
+	engine.GetNowPlaying()
+
+	sessions := engine.GetSessions(time.Hour)
+	if len(sessions) != 1 || len(sessions[0]) != 3 {
+		t.Fatalf("Expected one session of 3 plays, got %+v", sessions)
+	}
+	for i, entry := range sessions[0] {
+		want := start.Add(time.Duration(i*200) * time.Second)
+		if !entry.PlayedAt.Equal(want) {
+			t.Errorf("Expected play %d at %v, got %v", i, want, entry.PlayedAt)
+		}
+		if entry.Song.LastPlayed == nil || !entry.Song.LastPlayed.Equal(want) {
+			t.Errorf("Expected %s last played at %v, got %v", entry.Song.Title, want, entry.Song.LastPlayed)
+		}
+	}
+}
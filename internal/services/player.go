@@ -0,0 +1,324 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"src/internal/models"
+	"strings"
+	"time"
+)
+
+// playerBackLimit caps how many songs PreviousSong can go back through
+const playerBackLimit = 100
+
+// ErrEndOfPlaylist is returned by NextSong when every song has been played and repeat is off
+var ErrEndOfPlaylist = errors.New("reached the end of the playlist")
+
+// ErrNoPreviousSong is returned by PreviousSong before anything has been played
+var ErrNoPreviousSong = errors.New("no previous song")
+
+// RepeatMode controls what NextSong does at the end of the playlist
+type RepeatMode string
+
+const (
+	RepeatOff RepeatMode = "off" // Stop after the last song
+	RepeatOne RepeatMode = "one" // Keep replaying the current song
+	RepeatAll RepeatMode = "all" // Start another pass over the playlist
+)
+
+// ParseRepeatMode resolves a repeat mode name, case-insensitively
+// Time Complexity: O(k) where k is the name length
+// Space Complexity: O(1)
+func ParseRepeatMode(name string) (RepeatMode, error) {
+	switch mode := RepeatMode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case RepeatOff, RepeatOne, RepeatAll:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown repeat mode '%s', expected off, one or all", name)
+}
+
+// PlayerStatus describes the player for clients building a playback UI
+type PlayerStatus struct {
+	Current  *models.Song `json:"current"`
+	Shuffle  bool         `json:"shuffle"`
+	Repeat   RepeatMode   `json:"repeat"`
+	Queued   int          `json:"queued"`
+	Previous int          `json:"previous"` // Songs PreviousSong can go back through
+}
+
+// player tracks sequential and shuffled playback through the playlist
+// Songs are referenced by ID so deleted songs are skipped instead of replayed
+type player struct {
+	currentID  string
+	positionID string // Last song played in playlist order; queued songs don't move it
+	shuffle    bool
+	repeat     RepeatMode
+
+	back     []string        // Songs played before the current one, most recent last
+	upcoming []string        // Shuffled songs left in this pass, next last
+	heard    map[string]bool // Songs played in this pass
+	rng      *rand.Rand
+
+	// Position in the current song: elapsed is banked at each pause and seek, resumedAt is
+	// when playback last started or resumed and only counts while playing
+	state     PlaybackState
+	elapsed   time.Duration
+	resumedAt time.Time
+	clock     func() time.Time // Replaced in tests to simulate time passing
+}
+
+// newPlayer creates a stopped player with shuffle and repeat off
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func newPlayer() *player {
+	return &player{
+		repeat: RepeatOff,
+		heard:  make(map[string]bool),
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		state:  PlaybackStopped,
+		clock:  time.Now,
+	}
+}
+
+// reset stops playback and forgets the current song and pass, keeping the shuffle and repeat settings
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (p *player) reset() {
+	p.currentID = ""
+	p.positionID = ""
+	p.back = nil
+	p.upcoming = nil
+	p.heard = make(map[string]bool)
+	p.stop(0)
+}
+
+// NextSong advances the player and plays the next song
+// Queued songs come first and leave the playlist position alone; then repeat one replays the
+// current song, shuffle draws from the songs not yet heard this pass and otherwise the song
+// after the playlist position is played
+// At the end of a pass repeat all starts a new one and repeat off returns ErrEndOfPlaylist
+// Time Complexity: O(1) average, O(n) when a new shuffled pass is drawn
+// Space Complexity: O(n) for the shuffled pass
+func (pe *PlaylistEngine) NextSong() (*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.syncPlayback()
+	song, err := pe.advance(pe.player.clock())
+	return song.Clone(), err
+}
+
+// advance implements NextSong for callers already holding pe.mu, starting the next song at startedAt
+// Time Complexity: O(1) average, O(n) when a new shuffled pass is drawn
+// Space Complexity: O(n) for the shuffled pass
+func (pe *PlaylistEngine) advance(startedAt time.Time) (*models.Song, error) {
+	if pe.playQueue.Size > 0 {
+		song, err := pe.playQueue.Dequeue()
+		if err != nil {
+			return nil, err
+		}
+		pe.advanceTo(song, false, startedAt)
+		return song, nil
+	}
+
+	song, err := pe.nextSong()
+	if err != nil {
+		return nil, err
+	}
+
+	pe.advanceTo(song, true, startedAt)
+	return song, nil
+}
+
+// nextSong picks the next playlist song for NextSong once the queue is empty
+// Time Complexity: O(1) average, O(n) when a new shuffled pass is drawn
+// Space Complexity: O(n) for the shuffled pass
+func (pe *PlaylistEngine) nextSong() (*models.Song, error) {
+	if pe.currentPlaylist.IsEmpty() {
+		return nil, fmt.Errorf("playlist is empty")
+	}
+
+	p := pe.player
+	if p.repeat == RepeatOne {
+		if song, err := pe.songLookup.Get(p.currentID); err == nil {
+			return song, nil
+		}
+	}
+
+	if p.shuffle {
+		if song := pe.drawShuffled(); song != nil {
+			return song, nil
+		}
+		if p.repeat != RepeatAll {
+			return nil, ErrEndOfPlaylist
+		}
+		p.heard = make(map[string]bool)
+		return pe.drawShuffled(), nil
+	}
+
+	if node, ok := pe.currentPlaylist.GetNodeByID(p.positionID); ok {
+		if node.Next != nil {
+			return node.Next.Song, nil
+		}
+		if p.repeat != RepeatAll {
+			return nil, ErrEndOfPlaylist
+		}
+		p.heard = make(map[string]bool)
+	}
+	return pe.currentPlaylist.GetSong(0)
+}
+
+// drawShuffled returns the next shuffled song of this pass, or nil when every song was heard
+// Songs deleted since the shuffle are skipped and songs added since are shuffled in once the
+// drawn ones run out
+// Time Complexity: O(1) average, O(n) when the pass is reshuffled
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) drawShuffled() *models.Song {
+	p := pe.player
+	for attempt := 0; attempt < 2; attempt++ {
+		for len(p.upcoming) > 0 {
+			id := p.upcoming[len(p.upcoming)-1]
+			p.upcoming = p.upcoming[:len(p.upcoming)-1]
+			if song, err := pe.songLookup.Get(id); err == nil && !p.heard[id] {
+				return song
+			}
+		}
+		pe.shuffleUnheard()
+	}
+	return nil
+}
+
+// shuffleUnheard refills the upcoming songs with a Fisher-Yates shuffle of the songs not heard
+// this pass; the current song is left out unless it is the only choice
+// Time Complexity: O(n)
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) shuffleUnheard() {
+	p := pe.player
+	p.upcoming = p.upcoming[:0]
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		if !p.heard[song.ID] && song.ID != p.currentID {
+			p.upcoming = append(p.upcoming, song.ID)
+		}
+	}
+	if len(p.upcoming) == 0 && !p.heard[p.currentID] {
+		if _, err := pe.songLookup.Get(p.currentID); err == nil {
+			p.upcoming = append(p.upcoming, p.currentID)
+		}
+	}
+
+	for i := len(p.upcoming) - 1; i > 0; i-- {
+		j := p.rng.Intn(i + 1)
+		p.upcoming[i], p.upcoming[j] = p.upcoming[j], p.upcoming[i]
+	}
+}
+
+// PreviousSong goes back to the song played before the current one and plays it again
+// Songs deleted since they were played are skipped; in shuffle mode the current song
+// goes back to the front of the pass so NextSong returns to it
+// Time Complexity: O(1) amortized
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) PreviousSong() (*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.syncPlayback()
+	p := pe.player
+	for len(p.back) > 0 {
+		id := p.back[len(p.back)-1]
+		p.back = p.back[:len(p.back)-1]
+
+		song, err := pe.songLookup.Get(id)
+		if err != nil {
+			continue
+		}
+
+		if p.shuffle && p.currentID != "" {
+			delete(p.heard, p.currentID)
+			p.upcoming = append(p.upcoming, p.currentID)
+		}
+		p.positionID = song.ID
+		pe.playCurrent(song, p.clock())
+		return song.Clone(), nil
+	}
+	return nil, ErrNoPreviousSong
+}
+
+// advanceTo plays song from startedAt, remembering the current one for PreviousSong
+// inPlaylistOrder moves the playlist position to song, so NextSong continues after it
+// Time Complexity: O(1) amortized
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) advanceTo(song *models.Song, inPlaylistOrder bool, startedAt time.Time) {
+	if inPlaylistOrder {
+		pe.player.positionID = song.ID
+	}
+	if pe.player.currentID != "" {
+		pe.player.back = append(pe.player.back, pe.player.currentID)
+		if len(pe.player.back) > playerBackLimit {
+			pe.player.back = pe.player.back[1:]
+		}
+	}
+	pe.playCurrent(song, startedAt)
+}
+
+// playCurrent makes song the player's current song, starts it from the beginning at startedAt
+// and records the play at that time
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) playCurrent(song *models.Song, startedAt time.Time) {
+	pe.player.currentID = song.ID
+	pe.player.heard[song.ID] = true
+	pe.player.state = PlaybackPlaying
+	pe.player.elapsed = 0
+	pe.player.resumedAt = startedAt
+	pe.recordPlay(song, startedAt)
+}
+
+// SetShuffle turns shuffle on or off
+// Turning it on shuffles the songs not yet heard this pass, so nothing repeats until the pass ends
+// Time Complexity: O(n) when enabling, O(1) otherwise
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) SetShuffle(enabled bool) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.player.shuffle = enabled
+	pe.player.upcoming = nil
+	if enabled {
+		pe.shuffleUnheard()
+	}
+	pe.activity.record(ActivityPlayerMode, "")
+}
+
+// SetRepeatMode sets what happens at the end of the playlist
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) SetRepeatMode(mode RepeatMode) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.player.repeat = mode
+	pe.activity.record(ActivityPlayerMode, "")
+}
+
+// GetPlayerStatus returns the current song and the player settings
+// Current is nil before anything is played or after the current song is deleted
+// Time Complexity: O(1) average, plus any songs auto-advanced through since the last call
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) GetPlayerStatus() PlayerStatus {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.syncPlayback()
+
+	status := PlayerStatus{
+		Shuffle:  pe.player.shuffle,
+		Repeat:   pe.player.repeat,
+		Queued:   pe.playQueue.Size,
+		Previous: len(pe.player.back),
+	}
+	if song, err := pe.songLookup.Get(pe.player.currentID); err == nil {
+		status.Current = song.Clone()
+	}
+	return status
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func newPlayerTestEngine(songs int) *PlaylistEngine {
+	engine := NewPlaylistEngine("Test")
+	for i := 0; i < songs; i++ {
+		engine.AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	}
+	return engine
+}
+
+func TestNextSong_Sequential(t *testing.T) {
+	engine := newPlayerTestEngine(3)
+
+	for i := 0; i < 3; i++ {
+		song, err := engine.NextSong()
+		if err != nil || song.Title != fmt.Sprintf("Song %d", i) {
+			t.Fatalf("Expected Song %d, got %v, %v", i, song, err)
+		}
+	}
+	if _, err := engine.NextSong(); !errors.Is(err, ErrEndOfPlaylist) {
+		t.Errorf("Expected ErrEndOfPlaylist with repeat off, got %v", err)
+	}
+
+	engine.SetRepeatMode(RepeatAll)
+	if song, _ := engine.NextSong(); song == nil || song.Title != "Song 0" {
+		t.Errorf("Expected repeat all to wrap to Song 0, got %v", song)
+	}
+
+	engine.SetRepeatMode(RepeatOne)
+	if song, _ := engine.NextSong(); song == nil || song.Title != "Song 0" {
+		t.Errorf("Expected repeat one to replay Song 0, got %v", song)
+	}
+	if song := engine.GetCurrentPlaylist()[0]; song.PlayCount != 3 {
+		t.Errorf("Expected every play to be counted, got %d", song.PlayCount)
+	}
+}
+
+func TestNextSong_QueueFirst(t *testing.T) {
+	engine := newPlayerTestEngine(3)
+	songs := engine.GetCurrentPlaylist()
+
+	engine.NextSong()
+	engine.EnqueueSong(songs[2].ID)
+	if song, _ := engine.NextSong(); song.ID != songs[2].ID {
+		t.Errorf("Expected the queued song first, got %s", song.Title)
+	}
+	if song, _ := engine.NextSong(); song.ID != songs[1].ID {
+		t.Errorf("Expected the queued song not to move the playlist position, got %s", song.Title)
+	}
+}
+
+func TestNextSong_Shuffle(t *testing.T) {
+	engine := newPlayerTestEngine(10)
+	engine.SetShuffle(true)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		song, err := engine.NextSong()
+		if err != nil {
+			t.Fatalf("Expected a song on play %d, got %v", i+1, err)
+		}
+		if seen[song.ID] {
+			t.Fatalf("Expected no repeats within a pass, %s played twice", song.Title)
+		}
+		seen[song.ID] = true
+	}
+	if _, err := engine.NextSong(); !errors.Is(err, ErrEndOfPlaylist) {
+		t.Errorf("Expected ErrEndOfPlaylist after every song was heard, got %v", err)
+	}
+
+	engine.SetRepeatMode(RepeatAll)
+	if _, err := engine.NextSong(); err != nil {
+		t.Errorf("Expected repeat all to start a new pass, got %v", err)
+	}
+}
+
+func TestNextSong_ShuffleSkipsDeletedAndAdded(t *testing.T) {
+	engine := newPlayerTestEngine(4)
+	engine.SetShuffle(true)
+
+	first, _ := engine.NextSong()
+	for _, song := range engine.GetCurrentPlaylist() {
+		if song.ID != first.ID {
+			engine.DeleteSongByID(song.ID)
+		}
+	}
+	engine.AddSong("Late Addition", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	if song, err := engine.NextSong(); err != nil || song.Title != "Late Addition" {
+		t.Errorf("Expected the song added after shuffling, got %v, %v", song, err)
+	}
+	if _, err := engine.NextSong(); !errors.Is(err, ErrEndOfPlaylist) {
+		t.Errorf("Expected ErrEndOfPlaylist, got %v", err)
+	}
+}
+
+func TestPreviousSong(t *testing.T) {
+	engine := newPlayerTestEngine(3)
+
+	if _, err := engine.PreviousSong(); !errors.Is(err, ErrNoPreviousSong) {
+		t.Errorf("Expected ErrNoPreviousSong before playing, got %v", err)
+	}
+
+	engine.NextSong()
+	engine.NextSong()
+	engine.NextSong()
+	if song, _ := engine.PreviousSong(); song == nil || song.Title != "Song 1" {
+		t.Errorf("Expected Song 1, got %v", song)
+	}
+	if song, _ := engine.NextSong(); song == nil || song.Title != "Song 2" {
+		t.Errorf("Expected next to return to Song 2, got %v", song)
+	}
+
+	status := engine.GetPlayerStatus()
+	if status.Current == nil || status.Current.Title != "Song 2" || status.Repeat != RepeatOff || status.Shuffle {
+		t.Errorf("Unexpected player status %+v", status)
+	}
+
+	engine.ClearPlaylist()
+	if status := engine.GetPlayerStatus(); status.Current != nil || status.Previous != 0 {
+		t.Errorf("Expected clearing the playlist to reset the player, got %+v", status)
+	}
+}
+
+func TestPlaySong_SetsCurrent(t *testing.T) {
+	engine := newPlayerTestEngine(3)
+
+	engine.PlaySong(1)
+	if song, _ := engine.NextSong(); song == nil || song.Title != "Song 2" {
+		t.Errorf("Expected NextSong to continue after the played index, got %v", song)
+	}
+}
+
+func TestParseRepeatMode(t *testing.T) {
+	if mode, err := ParseRepeatMode(" ALL "); err != nil || mode != RepeatAll {
+		t.Errorf("Expected RepeatAll, got %q, %v", mode, err)
+	}
+	if _, err := ParseRepeatMode("twice"); err == nil {
+		t.Error("Expected error for unknown repeat mode")
+	}
+}
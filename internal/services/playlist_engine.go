@@ -1,36 +1,174 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"sort"
 	"src/internal/datastructures"
 	"src/internal/models"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
+// MaxSongFieldLength caps the number of characters accepted for any text field of a song
+const MaxSongFieldLength = 256
+
+// ErrPlaylistFull is returned when adding a song would exceed EngineConfig.MaxSongs
+var ErrPlaylistFull = errors.New("playlist is full")
+
+// SongIDMode controls how the engine generates IDs for new songs
+type SongIDMode int
+
+const (
+	// SongIDTimestamp embeds the current time so every add gets a unique ID
+	SongIDTimestamp SongIDMode = iota
+	// SongIDContentHash hashes the normalized title, artist and album so identical songs share an ID
+	SongIDContentHash
+)
+
+// DuplicatePolicy controls whether AddSong accepts a song whose title and artist match one already in the playlist
+type DuplicatePolicy int
+
+const (
+	// DuplicateReject refuses any song with the title and artist of an existing song
+	DuplicateReject DuplicatePolicy = iota
+	// DuplicateAllow accepts repeated songs, giving each copy its own ID
+	DuplicateAllow
+	// DuplicateAllowIfDifferentAlbum accepts a repeat only when its album differs from every existing copy,
+	// so live and studio versions can sit side by side
+	DuplicateAllowIfDifferentAlbum
+)
+
+// duplicatePolicyNames maps config names to duplicate policies
+var duplicatePolicyNames = map[string]DuplicatePolicy{
+	"reject":                DuplicateReject,
+	"allow":                 DuplicateAllow,
+	"allow_different_album": DuplicateAllowIfDifferentAlbum,
+}
+
+// ParseDuplicatePolicy converts a config name such as "allow_different_album" to a DuplicatePolicy
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func ParseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	policy, ok := duplicatePolicyNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return DuplicateReject, fmt.Errorf("unknown duplicate policy: %s", name)
+	}
+	return policy, nil
+}
+
+// EngineConfig holds optional engine behaviour settings
+type EngineConfig struct {
+	IDMode SongIDMode
+	// Joins slug words and the uniqueness suffix in generated IDs; made of "-", ".", "_" and "~" so IDs
+	// stay URL-safe, empty or anything else uses DefaultIDSeparator
+	IDSeparator string
+	// Fallback explorer tree names for empty categories, empty fields use the defaults
+	TreeLabels datastructures.UnknownLabels
+	// Cache the rating tree's sorted output between mutations for read-heavy dashboards
+	CacheRatingTree bool
+	// Number of recent operations kept in the activity log, 0 uses DefaultActivityLogSize
+	ActivityLogSize int
+	// Number of days of listening statistics kept for the timeline, 0 uses DefaultTimelineDays
+	TimelineDays int
+	// Number of distinct fuzzy search queries cached, 0 uses the cache's default
+	SearchCacheSize int
+	// Maximum number of songs in the playlist, 0 means unlimited
+	MaxSongs int
+	// Number of deleted songs kept for RestoreFromTrash, 0 uses DefaultTrashSize
+	TrashSize int
+	// Drop deleted songs from playback history as part of every delete
+	PruneHistoryOnDelete bool
+	// Whether songs repeating an existing title and artist are accepted, the zero value rejects them
+	DuplicatePolicy DuplicatePolicy
+}
+
+// DefaultEngineConfig returns the configuration used by NewPlaylistEngine
+func DefaultEngineConfig() EngineConfig {
+	return EngineConfig{
+		IDMode:      SongIDTimestamp,
+		IDSeparator: DefaultIDSeparator,
+		TreeLabels:  datastructures.DefaultUnknownLabels(),
+	}
+}
+
 // PlaylistEngine represents the core music playlist management system
 // Integrates all data structures: DoublyLinkedList, Stack, BST, HashMap, Sorting
 // Time Complexity: Varies by operation, documented per method
 // Space Complexity: O(n) where n is the total number of songs
 type PlaylistEngine struct {
+	// Guards every field below: exported methods take the read or write lock themselves,
+	// so one engine can serve concurrent requests. Returned songs are copies, so callers
+	// can read or encode them after the lock is released while writers keep changing the originals
+	mu sync.RWMutex
+
 	// Main playlist storage
 	currentPlaylist *datastructures.DoublyLinkedList
 
 	// Playback history management
 	playbackHistory *datastructures.PlaybackHistoryStack
 
+	// Songs waiting to be played next
+	playQueue *datastructures.PlayQueue
+
+	// Current song, shuffle and repeat state for NextSong and PreviousSong
+	player *player
+
 	// Song rating system
 	ratingTree *datastructures.SongRatingBST
 
 	// Fast song lookup
 	songLookup  *datastructures.SongHashMap
-	titleLookup *datastructures.SongHashMap
+	titleLookup *datastructures.TitleIndex // Normalized title -> every song carrying it
+
+	// Fuzzy title search results, cleared whenever songs are added or removed
+	searchCache *datastructures.SearchCache
+
+	// Trigram index over titles, artists and albums for partial-match search
+	fuzzyIndex *datastructures.TrigramIndex
 
 	// Playlist organization
 	playlistTree *datastructures.PlaylistExplorerTree
 
 	// Sorting functionality
-	sorter *datastructures.PlaylistSorter
+	sorter    *datastructures.PlaylistSorter
+	sortState SortState // What the current playlist order is based on
+
+	// Recommendation tuning
+	similarityMode  models.SimilarityMode
+	similarityIndex *datastructures.SimilarityIndex // Songs bucketed by the attributes similarity compares
+
+	// Songs with a known BPM ordered by tempo, for BPM range filters
+	bpmIndex *datastructures.BPMIndex
+
+	// Songs keyed by duration in a self-balancing tree, for duration range filters
+	durationIndex *datastructures.AVLTree[int]
+
+	// Engine behaviour settings
+	config EngineConfig
+
+	// Bounded log of recent mutating operations
+	activity *activityLog
+
+	// Plays and listening time per day, kept for longer than the playback history
+	timeline *listeningTimeline
+
+	// Deleted songs that can still be restored by ID, most recently deleted first
+	trash *datastructures.LRUCache[string, *models.Song]
+
+	// Rule-based playlists over this playlist's songs, by ID and in creation order
+	smartPlaylists map[string]*smartPlaylist
+	smartOrder     []string
+
+	// Incrementally maintained aggregates so stats don't need a full scan
+	artistIndex    map[string][]*models.Song   // Normalized artist -> songs in insertion order
+	albumIndex     map[albumKey][]*models.Song // Normalized album and artist -> songs in insertion order
+	playCountTotal int
 
 	// Engine metadata
 	playlistName  string
@@ -42,53 +180,173 @@ type PlaylistEngine struct {
 // Time Complexity: O(1)
 // Space Complexity: O(1)
 func NewPlaylistEngine(playlistName string) *PlaylistEngine {
-	return &PlaylistEngine{
+	return NewPlaylistEngineWithConfig(playlistName, DefaultEngineConfig())
+}
+
+// NewPlaylistEngineWithConfig creates a new playlist engine instance with custom settings
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewPlaylistEngineWithConfig(playlistName string, config EngineConfig) *PlaylistEngine {
+	if !validIDSeparator(config.IDSeparator) {
+		config.IDSeparator = DefaultIDSeparator
+	}
+
+	engine := &PlaylistEngine{
 		currentPlaylist: datastructures.NewDoublyLinkedList(),
 		playbackHistory: datastructures.NewPlaybackHistoryStack(100), // Keep last 100 played songs
+		playQueue:       datastructures.NewPlayQueue(),
+		player:          newPlayer(),
 		ratingTree:      datastructures.NewSongRatingBST(),
-		songLookup:      datastructures.NewSongHashMap(64),
-		titleLookup:     datastructures.NewSongHashMap(64),
-		playlistTree:    datastructures.NewPlaylistExplorerTree(),
+		songLookup:      datastructures.NewSongHashMap(initialLookupCapacity),
+		titleLookup:     datastructures.NewTitleIndex(initialLookupCapacity),
+		searchCache:     datastructures.NewSearchCache(config.SearchCacheSize),
+		fuzzyIndex:      datastructures.NewTrigramIndex(),
+		playlistTree:    datastructures.NewPlaylistExplorerTreeWithLabels(config.TreeLabels),
 		sorter:          datastructures.NewPlaylistSorter(datastructures.SortByTitle),
+		sortState:       customSortState(),
+		similarityMode:  models.SimilarityDefault,
+		similarityIndex: datastructures.NewSimilarityIndex(),
+		bpmIndex:        datastructures.NewBPMIndex(),
+		durationIndex:   datastructures.NewAVLTree[int](),
+		config:          config,
+		activity:        newActivityLog(config.ActivityLogSize),
+		timeline:        newListeningTimeline(config.TimelineDays),
+		trash:           newTrash(config.TrashSize),
+		artistIndex:     make(map[string][]*models.Song),
+		albumIndex:      make(map[albumKey][]*models.Song),
+		smartPlaylists:  make(map[string]*smartPlaylist),
 		playlistName:    playlistName,
 		totalPlayTime:   0,
 		createdAt:       time.Now(),
 	}
+
+	if config.CacheRatingTree {
+		engine.ratingTree.EnableCache(true)
+	}
+
+	return engine
 }
 
 // AddSong adds a song to the playlist with full synchronization across all data structures
+// Returns the generated ID of the new song
+// Time Complexity: O(1) average for most operations, O(log n) for BST insertion
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) AddSong(title, artist, album, genre, subgenre, mood string, duration, bpm int) (string, error) {
+	return pe.AddSongWithLinks(title, artist, album, genre, subgenre, mood, duration, bpm, "", "")
+}
+
+// AddSongWithLinks adds a song like AddSong, also setting its artwork and source URLs
+// Non-empty URLs must be absolute http or https URLs, otherwise nothing is added
 // Time Complexity: O(1) average for most operations, O(log n) for BST insertion
 // Space Complexity: O(1)
-func (pe *PlaylistEngine) AddSong(title, artist, album, genre, subgenre, mood string, duration, bpm int) error {
-	if strings.TrimSpace(title) == "" || strings.TrimSpace(artist) == "" {
-		return fmt.Errorf("title and artist are required")
+func (pe *PlaylistEngine) AddSongWithLinks(title, artist, album, genre, subgenre, mood string, duration, bpm int, artworkURL, sourceURL string) (string, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	return pe.addSongWithLinks(title, artist, album, genre, subgenre, mood, duration, bpm, artworkURL, sourceURL)
+}
+
+// addSongWithLinks implements AddSongWithLinks for callers already holding pe.mu
+func (pe *PlaylistEngine) addSongWithLinks(title, artist, album, genre, subgenre, mood string, duration, bpm int, artworkURL, sourceURL string) (string, error) {
+	// Sanitize input by trimming surrounding whitespace
+	title = strings.TrimSpace(title)
+	artist = strings.TrimSpace(artist)
+	album = strings.TrimSpace(album)
+	genre = strings.TrimSpace(genre)
+	subgenre = strings.TrimSpace(subgenre)
+	mood = strings.TrimSpace(mood)
+
+	if title == "" || artist == "" {
+		return "", fmt.Errorf("title and artist are required")
 	}
 
-	// Check if song already exists by title and artist
-	normalizedTitle := strings.TrimSpace(strings.ToLower(title))
-	normalizedArtist := strings.TrimSpace(strings.ToLower(artist))
+	if err := validateSongFieldLengths(title, artist, album, genre, subgenre, mood); err != nil {
+		return "", err
+	}
 
-	// Check existing songs for duplicates
-	existingSongs := pe.currentPlaylist.ToSlice()
-	for _, existingSong := range existingSongs {
-		if strings.ToLower(existingSong.Title) == normalizedTitle &&
-			strings.ToLower(existingSong.Artist) == normalizedArtist {
-			return fmt.Errorf("song already exists in playlist")
-		}
+	if pe.config.MaxSongs > 0 && pe.currentPlaylist.Size() >= pe.config.MaxSongs {
+		return "", fmt.Errorf("%w: limit is %d songs", ErrPlaylistFull, pe.config.MaxSongs)
+	}
+
+	if err := pe.checkDuplicate(title, artist, album, ""); err != nil {
+		return "", err
 	}
 
 	// Generate unique ID for the song
-	songID := pe.generateSongID(title, artist)
+	songID := pe.generateSongID(title, artist, album)
+	if pe.songLookup.Contains(songID) {
+		if pe.config.DuplicatePolicy == DuplicateReject {
+			return "", fmt.Errorf("song already exists in playlist")
+		}
+		// Content hashes of accepted repeats collide, so later copies get a numbered ID
+		songID = pe.nextFreeSongID(songID)
+	}
 
 	// Create new song
 	song := models.NewSong(songID, title, artist, album, genre, subgenre, mood, duration, bpm)
+	if err := song.SetLinks(artworkURL, sourceURL); err != nil {
+		return "", err
+	}
+
+	pe.insertSong(song)
+
+	pe.activity.record(ActivityAdd, song.Title)
+	return songID, nil
+}
+
+// checkDuplicate applies the configured DuplicatePolicy to a song about to be added or edited
+// Title, artist and album are compared case-insensitively; the song with ignoreID, if any, is skipped
+// Only the songs sharing the title in the title index are compared
+// Time Complexity: O(k) where k is the number of songs sharing the title
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) checkDuplicate(title, artist, album, ignoreID string) error {
+	if pe.config.DuplicatePolicy == DuplicateAllow {
+		return nil
+	}
+
+	for _, existing := range pe.titleLookup.Get(title) {
+		if existing.ID == ignoreID {
+			continue
+		}
+		if !strings.EqualFold(existing.Title, title) || !strings.EqualFold(existing.Artist, artist) {
+			continue
+		}
+		if pe.config.DuplicatePolicy == DuplicateReject || strings.EqualFold(existing.Album, album) {
+			return fmt.Errorf("song already exists in playlist")
+		}
+	}
+	return nil
+}
+
+// nextFreeSongID returns songID with the lowest numeric suffix no song uses yet
+// Time Complexity: O(k) average where k is the number of copies sharing the ID
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) nextFreeSongID(songID string) string {
+	sep := pe.config.IDSeparator
+	for n := 2; ; n++ {
+		candidate := songID + sep + strconv.Itoa(n)
+		if !pe.songLookup.Contains(candidate) {
+			return candidate
+		}
+	}
+}
 
-	// Add to playlist (doubly linked list)
+// insertSong appends a song to the end of the playlist and adds it to every index
+// Time Complexity: O(1) average for most operations, O(log n) for BST insertion
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) insertSong(song *models.Song) {
+	// Add to playlist (doubly linked list); an appended song breaks any earlier sort
 	pe.currentPlaylist.AddSong(song)
+	pe.sortState = customSortState()
 
 	// Add to hash maps for fast lookup
 	pe.songLookup.Put(song)
-	pe.titleLookup.PutByTitle(song)
+	pe.titleLookup.Add(song)
+	pe.searchCache.Clear()
+	pe.indexFuzzy(song)
+	pe.similarityIndex.Add(song)
+	pe.bpmIndex.Add(song)
+	pe.durationIndex.Insert(song.Duration, song)
 
 	// Add to playlist explorer tree
 	pe.playlistTree.AddSong(song)
@@ -98,25 +356,47 @@ func (pe *PlaylistEngine) AddSong(title, artist, album, genre, subgenre, mood st
 		pe.ratingTree.InsertSong(song, song.Rating)
 	}
 
-	// Update total play time
-	pe.totalPlayTime += duration
-
-	return nil
+	// Update total play time and cached aggregates
+	pe.totalPlayTime += song.Duration
+	pe.indexArtist(song)
+	pe.indexAlbum(song)
+	pe.playCountTotal += song.PlayCount
 }
 
-// DeleteSong removes a song from the playlist by index
+// DeleteSong removes a song from the playlist by index, keeping it in the trash for RestoreFromTrash
 // Time Complexity: O(n) for playlist deletion, O(1) average for hash map operations
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) DeleteSong(index int) (*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
 	// Remove from playlist
 	song, err := pe.currentPlaylist.DeleteSong(index)
 	if err != nil {
 		return nil, err
 	}
 
+	pe.removeFromIndexes(song)
+	pe.moveToTrash(song)
+	pe.activity.record(ActivityDelete, song.Title)
+	if pe.config.PruneHistoryOnDelete {
+		pe.pruneHistoryOfDeleted()
+	}
+	return song.Clone(), nil
+}
+
+// removeFromIndexes drops a song that was unlinked from the playlist from every other structure
+// Time Complexity: O(1) average for hash map operations, O(n) worst case for tree removal
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) removeFromIndexes(song *models.Song) {
 	// Remove from hash maps
 	pe.songLookup.Delete(song.ID)
-	// Note: We don't remove from titleLookup as there might be multiple songs with same title
+	pe.titleLookup.Remove(song)
+	pe.searchCache.Clear()
+	pe.fuzzyIndex.Remove(song.ID)
+	pe.similarityIndex.Remove(song.ID)
+	pe.bpmIndex.Remove(song.ID)
+	pe.durationIndex.Remove(song.Duration, song.ID)
 
 	// Remove from rating tree if it was rated
 	if song.Rating > 0 {
@@ -126,59 +406,406 @@ func (pe *PlaylistEngine) DeleteSong(index int) (*models.Song, error) {
 	// Remove from playlist tree
 	pe.playlistTree.RemoveSong(song.ID)
 
-	// Update total play time
+	// A deleted song can no longer be played from the queue
+	pe.playQueue.Remove(song.ID)
+
+	// Update total play time and cached aggregates
 	pe.totalPlayTime -= song.Duration
+	pe.unindexArtist(song)
+	pe.unindexAlbum(song)
+	pe.playCountTotal -= song.PlayCount
+}
+
+// DeleteSongByID removes a song from the playlist by its ID, keeping it in the trash for RestoreFromTrash
+// Time Complexity: O(1) to unlink the song via the node index, then as DeleteSong
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) DeleteSongByID(songID string) (*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, err := pe.currentPlaylist.DeleteSongByID(songID)
+	if err != nil {
+		return nil, err
+	}
 
-	return song, nil
+	pe.removeFromIndexes(song)
+	pe.moveToTrash(song)
+	pe.activity.record(ActivityDelete, song.Title)
+	if pe.config.PruneHistoryOnDelete {
+		pe.pruneHistoryOfDeleted()
+	}
+	return song.Clone(), nil
+}
+
+// GetSongPositions returns the current playlist index of every song keyed by ID
+// Time Complexity: O(n)
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) GetSongPositions() map[string]int {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.currentPlaylist.GetSongPositions()
+}
+
+// GetPlaylistPage returns up to limit songs after the cursor song, starting from the beginning for an empty cursor
+// Time Complexity: O(k) where k is the limit
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) GetPlaylistPage(afterID string, limit int) ([]*models.Song, string, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs, next, err := pe.currentPlaylist.GetPageAfter(afterID, limit)
+	return cloneSongs(songs), next, err
+}
+
+// GetSongsRange returns up to count songs starting at index start, empty once start passes the end
+// Time Complexity: O(min(start, n - start - count) + count), walking from the closer end
+// Space Complexity: O(count)
+func (pe *PlaylistEngine) GetSongsRange(start, count int) ([]*models.Song, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs, err := pe.currentPlaylist.GetSongsRange(start, count)
+	return cloneSongs(songs), err
+}
+
+// GetNeighbors returns the songs within radius positions around a song in playlist order
+// center is the song's index within the returned slice
+// Time Complexity: O(r) where r is the radius, using the playlist's node index
+// Space Complexity: O(r)
+func (pe *PlaylistEngine) GetNeighbors(songID string, radius int) ([]*models.Song, int, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs, center, err := pe.currentPlaylist.GetNeighbors(songID, radius)
+	return cloneSongs(songs), center, err
+}
+
+// GetSongsWithSameMood returns every other song in the library that shares a song's mood
+// Moods are matched the way the explorer tree files them, so "chill " and "Chill" are the same mood
+// Time Complexity: O(n) where n is the total number of songs in the tree
+// Space Complexity: O(k) where k is the number of matching songs
+func (pe *PlaylistEngine) GetSongsWithSameMood(songID string) ([]*models.Song, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	seed, err := pe.songLookup.Get(songID)
+	if err != nil {
+		return nil, err
+	}
+
+	mood := strings.Title(strings.ToLower(strings.TrimSpace(seed.Mood)))
+	if mood == "" {
+		mood = pe.playlistTree.Labels.Mood
+	}
+
+	songs := make([]*models.Song, 0)
+	for _, song := range pe.playlistTree.GetAllSongsInMood(mood) {
+		if song.ID != seed.ID {
+			songs = append(songs, song)
+		}
+	}
+	return cloneSongs(songs), nil
 }
 
 // MoveSong moves a song from one position to another in the playlist
+// toIndex is the song's final position after the move
 // Time Complexity: O(n) where n is max(fromIndex, toIndex)
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) MoveSong(fromIndex, toIndex int) error {
-	return pe.currentPlaylist.MoveSong(fromIndex, toIndex)
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	if err := pe.currentPlaylist.MoveSong(fromIndex, toIndex); err != nil {
+		return err
+	}
+	pe.sortState = customSortState()
+
+	if song, err := pe.currentPlaylist.GetSong(toIndex); err == nil {
+		pe.activity.record(ActivityMove, song.Title)
+	}
+	return nil
+}
+
+// MoveBlock moves count consecutive songs starting at startIndex to just before the song at toIndex
+// toIndex equal to the playlist size moves the block to the end
+// Time Complexity: O(n) to locate the block and target
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) MoveBlock(startIndex, count, toIndex int) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	if err := pe.currentPlaylist.MoveBlock(startIndex, count, toIndex); err != nil {
+		return err
+	}
+	pe.sortState = customSortState()
+
+	pe.activity.record(ActivityMove, "")
+	return nil
+}
+
+// MoveToTop moves a song to the start of the playlist
+// Time Complexity: O(1) using the playlist's node index
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) MoveToTop(songID string) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, err := pe.currentPlaylist.DeleteSongByID(songID)
+	if err != nil {
+		return err
+	}
+
+	pe.currentPlaylist.AddSongToBeginning(song)
+	pe.sortState = customSortState()
+	pe.activity.record(ActivityMove, song.Title)
+	return nil
+}
+
+// MoveToBottom moves a song to the end of the playlist
+// Time Complexity: O(1) using the playlist's node index
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) MoveToBottom(songID string) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, err := pe.currentPlaylist.DeleteSongByID(songID)
+	if err != nil {
+		return err
+	}
+
+	pe.currentPlaylist.AddSong(song)
+	pe.sortState = customSortState()
+	pe.activity.record(ActivityMove, song.Title)
+	return nil
 }
 
 // ReversePlaylist reverses the entire playlist order
 // Time Complexity: O(n)
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) ReversePlaylist() {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
 	pe.currentPlaylist.ReversePlaylist()
+	pe.sortState = customSortState()
+	pe.activity.record(ActivityReverse, "")
 }
 
 // PlaySong simulates playing a song and adds it to playback history
+// The song becomes the player's current song, so NextSong continues from it
 // Time Complexity: O(n) for finding song by index, O(1) for history operations
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) PlaySong(index int) (*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
 	song, err := pe.currentPlaylist.GetSong(index)
 	if err != nil {
 		return nil, err
 	}
 
+	pe.advanceTo(song, true, pe.player.clock())
+	return song.Clone(), nil
+}
+
+// recordPlay updates a song's play statistics, pushes it onto the playback history and logs the play
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) recordPlay(song *models.Song, playedAt time.Time) {
 	// Update song's play statistics
-	song.Play()
+	song.PlayAt(playedAt)
+	pe.playCountTotal++
 
-	// Add to playback history
-	pe.playbackHistory.Push(song)
+	// Add to playback history and the listening timeline
+	pe.playbackHistory.PushAt(song, playedAt)
+	pe.timeline.record(song, playedAt)
 
 	// Update in hash maps to reflect new play statistics
 	pe.songLookup.UpdateSong(song)
-	pe.titleLookup.UpdateSong(song)
 
-	return song, nil
+	pe.activity.record(ActivityPlay, song.Title)
+}
+
+// PlayRecord is a single externally logged play, used to backfill listening history
+type PlayRecord struct {
+	SongID   string    `json:"song_id"`
+	PlayedAt time.Time `json:"played_at"`
+}
+
+// RecordPlays imports logged plays, incrementing play counts and adding them to history in chronological order
+// Records with unknown song IDs or missing timestamps are skipped and reported in the returned error
+// Time Complexity: O(r log r + r * h) where r is the number of records and h the history size
+// Space Complexity: O(r)
+func (pe *PlaylistEngine) RecordPlays(entries []PlayRecord) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	sorted := make([]PlayRecord, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].PlayedAt.Before(sorted[j].PlayedAt)
+	})
+
+	var errs []error
+	imported := 0
+	for _, entry := range sorted {
+		if entry.PlayedAt.IsZero() {
+			errs = append(errs, fmt.Errorf("play of song '%s' is missing a timestamp", entry.SongID))
+			continue
+		}
+
+		song, err := pe.songLookup.Get(entry.SongID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// Update play statistics without moving LastPlayed backwards
+		song.PlayCount++
+		if song.LastPlayed == nil || entry.PlayedAt.After(*song.LastPlayed) {
+			playedAt := entry.PlayedAt
+			song.LastPlayed = &playedAt
+		}
+		pe.playCountTotal++
+
+		pe.playbackHistory.PushAt(song, entry.PlayedAt)
+		pe.timeline.record(song, entry.PlayedAt)
+
+		// Update in hash maps to reflect new play statistics
+		pe.songLookup.UpdateSong(song)
+		imported++
+	}
+
+	// A single entry per batch so large imports don't flush the activity log
+	if imported > 0 {
+		pe.activity.record(ActivityImportPlays, "")
+	}
+
+	return errors.Join(errs...)
+}
+
+// EnqueueSong adds a playlist song to the back of the play queue
+// Time Complexity: O(1) average for hash map lookup and enqueue
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) EnqueueSong(songID string) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, err := pe.songLookup.Get(songID)
+	if err != nil {
+		return fmt.Errorf("song not found: %v", err)
+	}
+
+	if err := pe.playQueue.Enqueue(song); err != nil {
+		return err
+	}
+
+	pe.activity.record(ActivityEnqueue, song.Title)
+	return nil
+}
+
+// GetQueue returns the queued songs from front to back
+// Time Complexity: O(q) where q is the queue length
+// Space Complexity: O(q)
+func (pe *PlaylistEngine) GetQueue() []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.playQueue.ToSlice())
+}
+
+// ClearQueue removes every song from the play queue
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) ClearQueue() {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.playQueue.Clear()
+	pe.activity.record(ActivityClearQueue, "")
+}
+
+// ExtendQueueSmart appends up to count recommended songs that aren't already queued
+// Recommendations already leave out recently played songs
+// Returns the songs that were added
+// Time Complexity: O(n * w) for recommendations where n is the playlist size and w the history window
+// Space Complexity: O(count + q) where q is the queue length
+func (pe *PlaylistEngine) ExtendQueueSmart(count int) []*models.Song {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	added := make([]*models.Song, 0, count)
+	if count <= 0 {
+		return added
+	}
+
+	// Ask for enough recommendations to cover ones that are already queued
+	for _, song := range pe.smartRecommendations(RecOptions{Count: count + pe.playQueue.Size, HistoryWindow: DefaultRecommendationWindow}) {
+		if len(added) >= count {
+			break
+		}
+		if pe.playQueue.Contains(song.ID) {
+			continue
+		}
+
+		pe.playQueue.Enqueue(song)
+		added = append(added, song)
+	}
+
+	if len(added) > 0 {
+		pe.activity.record(ActivityExtendQueue, "")
+	}
+	return cloneSongs(added)
 }
 
 // UndoLastPlay removes the last played song from history and returns it
 // Time Complexity: O(1)
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) UndoLastPlay() (*models.Song, error) {
-	return pe.playbackHistory.UndoLastPlay()
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, err := pe.playbackHistory.UndoLastPlay()
+	if err != nil {
+		return nil, err
+	}
+
+	pe.activity.record(ActivityUndo, song.Title)
+	return song.Clone(), nil
+}
+
+// PruneHistoryOfDeleted removes playback history entries for songs no longer in the playlist
+// Returns the number of entries removed
+// Time Complexity: O(h) where h is the history size, with O(1) average lookups
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) PruneHistoryOfDeleted() int {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	return pe.pruneHistoryOfDeleted()
+}
+
+// pruneHistoryOfDeleted implements PruneHistoryOfDeleted for callers already holding pe.mu
+func (pe *PlaylistEngine) pruneHistoryOfDeleted() int {
+	removed := pe.playbackHistory.RemoveWhere(func(song *models.Song) bool {
+		_, err := pe.songLookup.Get(song.ID)
+		return err != nil
+	})
+
+	if removed > 0 {
+		pe.activity.record(ActivityPruneHistory, "")
+	}
+	return removed
 }
 
 // RateSong assigns a rating to a song and updates the rating tree
 // Time Complexity: O(log n) for BST operations, O(1) average for hash map updates
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) RateSong(songID string, rating int) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
 	if rating < 1 || rating > 5 {
 		return fmt.Errorf("rating must be between 1 and 5")
 	}
@@ -188,11 +815,18 @@ func (pe *PlaylistEngine) RateSong(songID string, rating int) error {
 		return fmt.Errorf("song not found: %v", err)
 	}
 
-	oldRating := song.Rating
+	pe.applyRating(song, rating)
+	pe.activity.record(ActivityRate, song.Title)
+	return nil
+}
 
+// applyRating moves a song into the rating tree bucket for a validated rating
+// Time Complexity: O(log n) for BST operations, O(1) average for hash map updates
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) applyRating(song *models.Song, rating int) {
 	// Remove from old rating bucket if previously rated
-	if oldRating > 0 {
-		pe.ratingTree.DeleteSong(songID)
+	if song.Rating > 0 {
+		pe.ratingTree.DeleteSong(song.ID)
 	}
 
 	// Update song rating
@@ -203,72 +837,944 @@ func (pe *PlaylistEngine) RateSong(songID string, rating int) error {
 
 	// Update in hash maps
 	pe.songLookup.UpdateSong(song)
-	pe.titleLookup.UpdateSong(song)
+}
+
+// UnrateSong clears a song's rating and removes it from the rating tree
+// Unrating a song that has no rating is a no-op
+// Time Complexity: O(log n) for BST operations, O(1) average for hash map updates
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) UnrateSong(songID string) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, err := pe.songLookup.Get(songID)
+	if err != nil {
+		return fmt.Errorf("song not found: %v", err)
+	}
 
+	if song.Rating == 0 {
+		return nil
+	}
+
+	pe.ratingTree.DeleteSong(songID)
+	song.Rating = 0
+
+	// Update in hash maps
+	pe.songLookup.UpdateSong(song)
+
+	pe.activity.record(ActivityUnrate, song.Title)
 	return nil
 }
 
+// SetSongNote attaches a free-form note to a song, an empty note clears it
+// Time Complexity: O(1) average for hash map lookup, O(k) for the note length
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) SetSongNote(songID, note string) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, err := pe.songLookup.Get(songID)
+	if err != nil {
+		return fmt.Errorf("song not found: %v", err)
+	}
+
+	if err := song.SetNote(note); err != nil {
+		return err
+	}
+
+	pe.activity.record(ActivityNote, song.Title)
+	return nil
+}
+
+// SetSongArtwork replaces a song's artwork and source URLs, empty values clear them
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) SetSongArtwork(songID, artworkURL, sourceURL string) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, err := pe.songLookup.Get(songID)
+	if err != nil {
+		return fmt.Errorf("song not found: %v", err)
+	}
+
+	if err := song.SetLinks(artworkURL, sourceURL); err != nil {
+		return err
+	}
+
+	pe.activity.record(ActivityArtwork, song.Title)
+	return nil
+}
+
+// TagSongsWhere adds a tag to every playlist song matching the predicate
+// Returns how many songs gained the tag; songs already carrying it are not counted
+// Time Complexity: O(n * t) where n is the playlist size and t the tags per song
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) TagSongsWhere(predicate func(*models.Song) bool, tag string) int {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	tagged := 0
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		if predicate(song) && song.AddTag(tag) {
+			tagged++
+		}
+	}
+
+	if tagged > 0 {
+		pe.activity.record(ActivityTag, "")
+	}
+	return tagged
+}
+
+// GetSongsByTag returns playlist songs carrying the given tag in playlist order
+// Time Complexity: O(n * t) where n is the playlist size and t the tags per song
+// Space Complexity: O(k) where k is the number of matching songs
+func (pe *PlaylistEngine) GetSongsByTag(tag string) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs := make([]*models.Song, 0)
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		if song.HasTag(tag) {
+			songs = append(songs, song)
+		}
+	}
+	return cloneSongs(songs)
+}
+
+// MinSkipInteractions is the number of plays plus skips a song needs before GetSkippedSongs considers it
+const MinSkipInteractions = 3
+
+// SkipSong records that the song at the given index was skipped
+// Skips don't enter playback history
+// Time Complexity: O(n) for finding song by index
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) SkipSong(index int) (*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, err := pe.currentPlaylist.GetSong(index)
+	if err != nil {
+		return nil, err
+	}
+
+	song.Skip()
+
+	// Update in hash maps to reflect new skip statistics
+	pe.songLookup.UpdateSong(song)
+
+	pe.activity.record(ActivitySkip, song.Title)
+	return song.Clone(), nil
+}
+
+// GetSkippedSongs returns songs whose play ratio is below threshold, lowest ratio first
+// Songs with fewer than MinSkipInteractions plays plus skips are excluded
+// Time Complexity: O(n log n) where n is the playlist size
+// Space Complexity: O(k) where k is the number of matching songs
+func (pe *PlaylistEngine) GetSkippedSongs(threshold float64) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	skipped := make([]*models.Song, 0)
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		if song.PlayCount+song.SkipCount < MinSkipInteractions {
+			continue
+		}
+		if song.PlayRatio() < threshold {
+			skipped = append(skipped, song)
+		}
+	}
+
+	sort.SliceStable(skipped, func(i, j int) bool {
+		return skipped[i].PlayRatio() < skipped[j].PlayRatio()
+	})
+
+	return cloneSongs(skipped)
+}
+
+// GetUnratedSongs returns playlist songs that have not been rated yet
+// Time Complexity: O(n) where n is the playlist size
+// Space Complexity: O(k) where k is the number of unrated songs
+func (pe *PlaylistEngine) GetUnratedSongs() []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	unrated := make([]*models.Song, 0)
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		if song.Rating == 0 {
+			unrated = append(unrated, song)
+		}
+	}
+	return cloneSongs(unrated)
+}
+
 // SearchSongByID provides O(1) song lookup by ID
 // Time Complexity: O(1) average
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) SearchSongByID(songID string) (*models.Song, error) {
-	return pe.songLookup.Get(songID)
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	song, err := pe.songLookup.Get(songID)
+	return song.Clone(), err
+}
+
+// SearchSongByTitle returns every song with the given title, in the order they were added
+// Titles compare case-insensitively with whitespace collapsed
+// Time Complexity: O(1) average plus O(k) for the k matches
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) SearchSongByTitle(title string) ([]*models.Song, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs := pe.titleLookup.Get(title)
+	if len(songs) == 0 {
+		return nil, fmt.Errorf("song with title '%s' not found", title)
+	}
+	return cloneSongs(songs), nil
+}
+
+// FuzzySearchByTitle returns up to limit songs whose titles contain or closely match the query
+// Substring matches rank first, then titles by edit distance; ties are ordered by title
+// Results are cached per normalized query until a song is added or removed
+// A non-positive limit returns every match
+// Time Complexity: O(n * q * t) on a cache miss where q and t are query and title lengths, O(1) average on a hit
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) FuzzySearchByTitle(query string, limit int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return []*models.Song{}
+	}
+
+	matches, cached := pe.searchCache.Get(query)
+	if !cached {
+		matches = pe.fuzzyMatchTitles(query)
+		pe.searchCache.Put(query, matches)
+	}
+
+	if limit <= 0 || limit > len(matches) {
+		limit = len(matches)
+	}
+	return cloneSongs(matches[:limit])
+}
+
+// fuzzyMatchTitles ranks every playlist song against a normalized query
+// Titles within a third of the query length in edit distance count as close matches
+// Time Complexity: O(n * q * t)
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) fuzzyMatchTitles(query string) []*models.Song {
+	type scoredSong struct {
+		song  *models.Song
+		score int
+	}
+
+	maxDistance := len([]rune(query)) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	var scored []scoredSong
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		title := strings.ToLower(song.Title)
+		if strings.Contains(title, query) {
+			scored = append(scored, scoredSong{song: song, score: 0})
+			continue
+		}
+		if distance := editDistance(query, title); distance <= maxDistance {
+			scored = append(scored, scoredSong{song: song, score: distance})
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score < scored[j].score
+		}
+		return strings.ToLower(scored[i].song.Title) < strings.ToLower(scored[j].song.Title)
+	})
+
+	matches := make([]*models.Song, len(scored))
+	for i, entry := range scored {
+		matches[i] = entry.song
+	}
+	return matches
+}
+
+// editDistance returns the Levenshtein distance between two strings
+// Time Complexity: O(a * b)
+// Space Complexity: O(b)
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(min(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+// GetSongsByRating returns all songs with a specific rating
+// Time Complexity: O(log n) average for BST search
+// Space Complexity: O(k) where k is the number of songs with that rating
+func (pe *PlaylistEngine) GetSongsByRating(rating int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.ratingTree.SearchByRating(rating))
+}
+
+// GetSongsByRatings unions the rating buckets for the given ratings into a flat list
+// Ratings are deduplicated, values outside 1-5 are ignored, and songs come in descending rating order
+// Time Complexity: O(r log n + k) where r is the number of ratings and k the number of songs returned
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) GetSongsByRatings(ratings []int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	requested := make(map[int]bool)
+	for _, rating := range ratings {
+		if rating >= 1 && rating <= 5 {
+			requested[rating] = true
+		}
+	}
+
+	songs := make([]*models.Song, 0)
+	for rating := 5; rating >= 1; rating-- {
+		if requested[rating] {
+			songs = append(songs, pe.ratingTree.SearchByRating(rating)...)
+		}
+	}
+
+	return cloneSongs(songs)
+}
+
+// GetSongsByRatingRange returns songs within a rating range
+// Time Complexity: O(n) worst case for range search
+// Space Complexity: O(k) where k is the number of matching songs
+func (pe *PlaylistEngine) GetSongsByRatingRange(minRating, maxRating int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.ratingTree.GetSongsByRatingRange(minRating, maxRating))
+}
+
+// GetSongsByRatingRangeDesc returns songs within a rating range, highest rating first
+// Time Complexity: O(n) worst case for range search
+// Space Complexity: O(k) where k is the number of matching songs
+func (pe *PlaylistEngine) GetSongsByRatingRangeDesc(minRating, maxRating int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.ratingTree.GetSongsByRatingRangeDesc(minRating, maxRating))
+}
+
+// DashboardTopK is the number of songs in each top list of the dashboard snapshot
+const DashboardTopK = 5
+
+// longerSong ranks songs by duration, longest first
+func longerSong(a, b *models.Song) bool {
+	return a.Duration > b.Duration
+}
+
+// morePlayedSong ranks songs by play count, most played first
+func morePlayedSong(a, b *models.Song) bool {
+	return a.PlayCount > b.PlayCount
+}
+
+// higherRatedSong ranks songs by rating, breaking ties by play count
+func higherRatedSong(a, b *models.Song) bool {
+	if a.Rating != b.Rating {
+		return a.Rating > b.Rating
+	}
+	return a.PlayCount > b.PlayCount
+}
+
+// GetLongestSongs returns the count longest songs, longest first
+// Time Complexity: O(n log k) where k is count
+// Space Complexity: O(k) for the heap
+func (pe *PlaylistEngine) GetLongestSongs(count int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	top := datastructures.NewTopK(count, longerSong)
+	pe.currentPlaylist.ForEach(top.Push)
+	return cloneSongs(top.Results())
+}
+
+// GetMostPlayedSongs returns the count most played songs, most played first
+// Time Complexity: O(n log k) where k is count
+// Space Complexity: O(k) for the heap
+func (pe *PlaylistEngine) GetMostPlayedSongs(count int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	top := datastructures.NewTopK(count, morePlayedSong)
+	pe.currentPlaylist.ForEach(top.Push)
+	return cloneSongs(top.Results())
+}
+
+// GetTopRatedSongs returns the count highest rated songs, ties broken by play count
+// Unrated songs are left out
+// Time Complexity: O(r log k) where r is the number of rated songs and k is count
+// Space Complexity: O(r)
+func (pe *PlaylistEngine) GetTopRatedSongs(count int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(datastructures.TopN(pe.ratingTree.GetSongsByRatingRange(1, 5), count, higherRatedSong))
+}
+
+// SortPlaylist sorts the current playlist using specified criteria and algorithm
+// Returns the algorithm actually used, which differs from the requested one after a fallback
+// Time Complexity: O(n log n)
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) SortPlaylist(criteria datastructures.SortCriteria, algorithm string) string {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.sorter.SetCriteria(criteria)
+	used := pe.sorter.SortPlaylist(pe.currentPlaylist, algorithm)
+	pe.sortState = SortState{
+		Criteria:    datastructures.SortCriteriaName(criteria),
+		Algorithm:   used,
+		Description: pe.sorter.GetSortCriteriaString(),
+	}
+	pe.activity.record(ActivitySort, "")
+	return used
+}
+
+// SortStateCustom is the SortState criteria for an order that no sort produced
+const SortStateCustom = "custom"
+
+// SortState describes the sort the current playlist order comes from
+// Criteria is SortStateCustom once songs are added or moved by hand after the last sort
+type SortState struct {
+	Criteria    string `json:"criteria"`
+	Algorithm   string `json:"algorithm,omitempty"`
+	Description string `json:"description"`
+}
+
+// customSortState returns the state for a playlist in a hand-made order
+func customSortState() SortState {
+	return SortState{Criteria: SortStateCustom, Description: "Custom order"}
+}
+
+// GetSortState returns the sort the current playlist order comes from
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) GetSortState() SortState {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.sortState
+}
+
+// GetSortedView returns the playlist ordered by several criteria without changing it
+// Earlier criteria take priority; songs equal on every criteria keep their playlist order
+// Time Complexity: O(c * n log n) where c is the number of criteria
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) GetSortedView(criteria []datastructures.SortCriteria) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs := pe.currentPlaylist.ToSlice()
+	if len(criteria) == 0 {
+		return cloneSongs(songs)
+	}
+
+	// A separate sorter leaves the engine's configured criteria untouched
+	sorter := datastructures.NewPlaylistSorter(criteria[0])
+	return cloneSongs(sorter.MultiCriteriaSort(songs, criteria))
+}
+
+// GetRecentlyPlayedSongs returns recently played songs from history
+// Time Complexity: O(min(n, count))
+// Space Complexity: O(min(n, count))
+func (pe *PlaylistEngine) GetRecentlyPlayedSongs(count int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.playbackHistory.GetRecentSongs(count))
+}
+
+// GetRecentlyPlayedSongsPaged returns a page of playback history, most recent first
+// Time Complexity: O(offset + count)
+// Space Complexity: O(count)
+func (pe *PlaylistEngine) GetRecentlyPlayedSongsPaged(offset, count int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.playbackHistory.GetRecentSongsPaged(offset, count))
+}
+
+// GetRecentlyAdded returns the n most recently added songs without reordering the playlist
+// Songs added at the same instant are ordered by title for determinism
+// Time Complexity: O(n log n) for sorting a copy of the playlist
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) GetRecentlyAdded(n int) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	if n <= 0 {
+		return []*models.Song{}
+	}
+
+	songs := pe.currentPlaylist.ToSlice()
+	sort.SliceStable(songs, func(i, j int) bool {
+		if !songs[i].AddedAt.Equal(songs[j].AddedAt) {
+			return songs[i].AddedAt.After(songs[j].AddedAt)
+		}
+		return songs[i].Title < songs[j].Title
+	})
+
+	if n < len(songs) {
+		songs = songs[:n]
+	}
+	return cloneSongs(songs)
+}
+
+// GetHistorySize returns the number of entries in the playback history
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) GetHistorySize() int {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.playbackHistory.GetSize()
+}
+
+// GetSongsPlayedSince returns songs played at or after the given time, most recent first
+// A song played several times appears once per play
+// Time Complexity: O(k) where k is the number of matching plays
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) GetSongsPlayedSince(since time.Time) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.songsPlayedSince(since))
 }
 
-// SearchSongByTitle provides O(1) song lookup by title
-// Time Complexity: O(1) average
-// Space Complexity: O(1)
-func (pe *PlaylistEngine) SearchSongByTitle(title string) (*models.Song, error) {
-	return pe.titleLookup.GetByTitle(title)
-}
+// songsPlayedSince implements GetSongsPlayedSince for callers already holding pe.mu
+func (pe *PlaylistEngine) songsPlayedSince(since time.Time) []*models.Song {
+	entries := pe.playbackHistory.GetEntriesSince(since)
+	songs := make([]*models.Song, 0, len(entries))
+	for _, entry := range entries {
+		songs = append(songs, entry.Song)
+	}
+	return songs
+}
+
+// GetSessions groups playback history into listening sessions separated by more than gap
+// Time Complexity: O(h) where h is the history size
+// Space Complexity: O(h)
+func (pe *PlaylistEngine) GetSessions(gap time.Duration) [][]datastructures.PlayedEntry {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	sessions := pe.playbackHistory.GetSessions(gap)
+	for _, session := range sessions {
+		for i := range session {
+			session[i].Song = session[i].Song.Clone()
+		}
+	}
+	return sessions
+}
+
+// GetTodayStats returns the number of plays and total listen time since local midnight
+// Time Complexity: O(k) where k is the number of plays today
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) GetTodayStats() map[string]interface{} {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	songs := pe.songsPlayedSince(midnight)
+	totalListenTime := 0
+	for _, song := range songs {
+		totalListenTime += song.Duration
+	}
+
+	return map[string]interface{}{
+		"since":             midnight,
+		"songs_played":      len(songs),
+		"total_listen_time": totalListenTime,
+	}
+}
+
+// GetPlaybackStatsRange returns playback statistics for plays between from and to inclusive
+// Only plays still in the bounded history are counted
+// Time Complexity: O(k) where k is the number of plays at or after from
+// Space Complexity: O(a + g) where a and g are the distinct artists and genres in range
+func (pe *PlaylistEngine) GetPlaybackStatsRange(from, to time.Time) map[string]interface{} {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	stats := pe.playbackHistory.GetPlaybackStatsRange(from, to)
+	stats["from"] = from
+	stats["to"] = to
+	return stats
+}
+
+// SongStats gathers a single song's listening statistics in one place
+type SongStats struct {
+	SongID     string     `json:"song_id"`
+	Title      string     `json:"title"`
+	PlayCount  int        `json:"play_count"`
+	SkipCount  int        `json:"skip_count"`
+	Rating     int        `json:"rating"` // 0 when unrated
+	AddedAt    time.Time  `json:"added_at"`
+	LastPlayed *time.Time `json:"last_played,omitempty"`
+	PlayRatio  float64    `json:"play_ratio"`
+}
+
+// GetSongStats returns the listening statistics of a single song
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) GetSongStats(songID string) (SongStats, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	song, err := pe.songLookup.Get(songID)
+	if err != nil {
+		return SongStats{}, err
+	}
+
+	return SongStats{
+		SongID:     song.ID,
+		Title:      song.Title,
+		PlayCount:  song.PlayCount,
+		SkipCount:  song.SkipCount,
+		Rating:     song.Rating,
+		AddedAt:    song.AddedAt,
+		LastPlayed: song.LastPlayed,
+		PlayRatio:  song.PlayRatio(),
+	}, nil
+}
+
+// GenreStat holds aggregated statistics for a single genre in the playlist
+type GenreStat struct {
+	SongCount       int     `json:"song_count"`
+	TotalDuration   int     `json:"total_duration"`
+	AverageDuration float64 `json:"average_duration"`
+	AverageRating   float64 `json:"average_rating"`
+}
+
+// GetGenreStatistics aggregates song count, duration and rating per genre over the current playlist
+// Average rating only considers rated songs and is 0 when a genre has none
+// Time Complexity: O(n) where n is the playlist size
+// Space Complexity: O(g) where g is the number of genres
+func (pe *PlaylistEngine) GetGenreStatistics() map[string]GenreStat {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	stats := make(map[string]GenreStat)
+	ratingTotals := make(map[string]int)
+	ratedCounts := make(map[string]int)
+
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		stat := stats[song.Genre]
+		stat.SongCount++
+		stat.TotalDuration += song.Duration
+		stats[song.Genre] = stat
+
+		if song.Rating > 0 {
+			ratingTotals[song.Genre] += song.Rating
+			ratedCounts[song.Genre]++
+		}
+	}
+
+	for genre, stat := range stats {
+		stat.AverageDuration = float64(stat.TotalDuration) / float64(stat.SongCount)
+		if ratedCounts[genre] > 0 {
+			stat.AverageRating = float64(ratingTotals[genre]) / float64(ratedCounts[genre])
+		}
+		stats[genre] = stat
+	}
+
+	return stats
+}
+
+// GetBPMHistogram counts songs per BPM range of bucketSize beats, labelled "60-80" for [60, 80)
+// Songs without a BPM go into the "unknown" bucket; a non-positive bucketSize yields an empty histogram
+// Time Complexity: O(n) where n is the playlist size
+// Space Complexity: O(b) where b is the number of buckets
+func (pe *PlaylistEngine) GetBPMHistogram(bucketSize int) map[string]int {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	histogram := make(map[string]int)
+	if bucketSize <= 0 {
+		return histogram
+	}
+
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		if song.BPM <= 0 {
+			histogram["unknown"]++
+			continue
+		}
+
+		lower := (song.BPM / bucketSize) * bucketSize
+		histogram[fmt.Sprintf("%d-%d", lower, lower+bucketSize)]++
+	}
+
+	return histogram
+}
+
+// GetDurationHistogram counts songs per duration band of bucketSeconds, labelled "120-180" for [120, 180)
+// Songs without a duration go into the "unknown" bucket, matching GetBPMHistogram
+// A non-positive bucketSeconds yields an empty histogram
+// Time Complexity: O(n) where n is the playlist size
+// Space Complexity: O(b) where b is the number of buckets
+func (pe *PlaylistEngine) GetDurationHistogram(bucketSeconds int) map[string]int {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	histogram := make(map[string]int)
+	if bucketSeconds <= 0 {
+		return histogram
+	}
+
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		if song.Duration <= 0 {
+			histogram["unknown"]++
+			continue
+		}
+
+		lower := (song.Duration / bucketSeconds) * bucketSeconds
+		histogram[fmt.Sprintf("%d-%d", lower, lower+bucketSeconds)]++
+	}
+
+	return histogram
+}
+
+// SortHistogramLabels returns histogram labels ordered by their lower bound, with "unknown" last
+// Time Complexity: O(b log b) where b is the number of buckets
+// Space Complexity: O(b)
+func SortHistogramLabels(histogram map[string]int) []string {
+	labels := make([]string, 0, len(histogram))
+	for label := range histogram {
+		labels = append(labels, label)
+	}
+
+	lowerBound := func(label string) int {
+		lower, _, _ := strings.Cut(label, "-")
+		value, err := strconv.Atoi(lower)
+		if err != nil {
+			return -1
+		}
+		return value
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		li, lj := lowerBound(labels[i]), lowerBound(labels[j])
+		if (li < 0) != (lj < 0) {
+			return lj < 0 // Non-numeric labels such as "unknown" sort last
+		}
+		if li != lj {
+			return li < lj
+		}
+		return labels[i] < labels[j]
+	})
 
-// GetSongsByRating returns all songs with a specific rating
-// Time Complexity: O(log n) average for BST search
-// Space Complexity: O(k) where k is the number of songs with that rating
-func (pe *PlaylistEngine) GetSongsByRating(rating int) []*models.Song {
-	return pe.ratingTree.SearchByRating(rating)
+	return labels
 }
 
-// GetSongsByRatingRange returns songs within a rating range
-// Time Complexity: O(n) worst case for range search
-// Space Complexity: O(k) where k is the number of matching songs
-func (pe *PlaylistEngine) GetSongsByRatingRange(minRating, maxRating int) []*models.Song {
-	return pe.ratingTree.GetSongsByRatingRange(minRating, maxRating)
+// InterleaveGenres alternates songs from genres a and b in playlist order, starting with a
+// Once one genre runs out the rest of the other is appended; the playlist itself is not changed
+// Genres match case-insensitively, and the same genre twice yields its songs once
+// Time Complexity: O(n) where n is the playlist size
+// Space Complexity: O(k) where k is the number of songs in both genres
+func (pe *PlaylistEngine) InterleaveGenres(a, b string) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	var songsA, songsB []*models.Song
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		if strings.EqualFold(song.Genre, a) {
+			songsA = append(songsA, song)
+		} else if strings.EqualFold(song.Genre, b) {
+			songsB = append(songsB, song)
+		}
+	}
+
+	interleaved := make([]*models.Song, 0, len(songsA)+len(songsB))
+	for i := 0; i < len(songsA) || i < len(songsB); i++ {
+		if i < len(songsA) {
+			interleaved = append(interleaved, songsA[i])
+		}
+		if i < len(songsB) {
+			interleaved = append(interleaved, songsB[i])
+		}
+	}
+
+	return cloneSongs(interleaved)
 }
 
-// SortPlaylist sorts the current playlist using specified criteria and algorithm
-// Time Complexity: O(n log n)
-// Space Complexity: O(n)
-func (pe *PlaylistEngine) SortPlaylist(criteria datastructures.SortCriteria, algorithm string) {
-	pe.sorter.SetCriteria(criteria)
-	pe.sorter.SortPlaylist(pe.currentPlaylist, algorithm)
+// distinctFields maps each field GetDistinctValues supports to its accessor
+var distinctFields = map[string]func(*models.Song) string{
+	"genre":    func(s *models.Song) string { return s.Genre },
+	"subgenre": func(s *models.Song) string { return s.SubGenre },
+	"mood":     func(s *models.Song) string { return s.Mood },
+	"artist":   func(s *models.Song) string { return s.Artist },
+	"album":    func(s *models.Song) string { return s.Album },
 }
 
-// GetRecentlyPlayedSongs returns recently played songs from history
-// Time Complexity: O(min(n, count))
-// Space Complexity: O(min(n, count))
-func (pe *PlaylistEngine) GetRecentlyPlayedSongs(count int) []*models.Song {
-	return pe.playbackHistory.GetRecentSongs(count)
+// GetDistinctValues counts the songs holding each distinct value of a field
+// Supported fields are genre, subgenre, mood, artist and album; empty values are not counted
+// Values are grouped case-insensitively like the explorer tree, keyed by the first spelling seen
+// Time Complexity: O(n)
+// Space Complexity: O(d) where d is the number of distinct values
+func (pe *PlaylistEngine) GetDistinctValues(field string) (map[string]int, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	value, ok := distinctFields[strings.ToLower(field)]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q: must be one of genre, subgenre, mood, artist or album", field)
+	}
+
+	counts := make(map[string]int)
+	spellings := make(map[string]string)
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		v := strings.TrimSpace(value(song))
+		if v == "" {
+			continue
+		}
+		key := treeCategory(v)
+		if first, ok := spellings[key]; ok {
+			v = first
+		} else {
+			spellings[key] = v
+		}
+		counts[v]++
+	}
+	return counts, nil
 }
 
 // GetPlaylistByExplorer returns songs from the hierarchical explorer
 // Time Complexity: O(1) for navigation
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) GetPlaylistByExplorer(genre, subgenre, mood, artist string) []*models.Song {
-	return pe.playlistTree.GetSongs(genre, subgenre, mood, artist)
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.playlistTree.GetSongs(genre, subgenre, mood, artist))
+}
+
+// GetTreeStructure returns the explorer tree as nested genre → subgenre → mood → artist → song count maps
+// Time Complexity: O(t) where t is the number of tree nodes
+// Space Complexity: O(t)
+func (pe *PlaylistEngine) GetTreeStructure() map[string]interface{} {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.playlistTree.GetTreeStructure()
+}
+
+// GetTreeStructureDepth returns the explorer tree limited to maxDepth levels, with song counts at the deepest level
+// Time Complexity: O(t) where t is the number of tree nodes
+// Space Complexity: O(m) where m is the number of nodes within maxDepth
+func (pe *PlaylistEngine) GetTreeStructureDepth(maxDepth int) map[string]interface{} {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.playlistTree.GetTreeStructureDepth(maxDepth)
 }
 
 // GetGenres returns all available genres from the explorer tree
 // Time Complexity: O(g) where g is the number of genres
 // Space Complexity: O(g)
 func (pe *PlaylistEngine) GetGenres() []string {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
 	return pe.playlistTree.GetGenres()
 }
 
+// RenameGenre changes every song in a genre, matched case-insensitively, to the new genre name
+// Returns how many songs changed; the explorer tree moves them and drops the emptied branch
+// Time Complexity: O(n + k * t) where k is the number of changed songs and t the tree size
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) RenameGenre(oldName, newName string) int {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	return pe.renameCategory(oldName, newName, func(song *models.Song) *string { return &song.Genre })
+}
+
+// RenameSubgenre changes every song in a subgenre, matched case-insensitively, to the new subgenre name
+// Time Complexity: O(n + k * t) where k is the number of changed songs and t the tree size
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) RenameSubgenre(oldName, newName string) int {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	return pe.renameCategory(oldName, newName, func(song *models.Song) *string { return &song.SubGenre })
+}
+
+// RenameMood changes every song with a mood, matched case-insensitively, to the new mood name
+// Time Complexity: O(n + k * t) where k is the number of changed songs and t the tree size
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) RenameMood(oldName, newName string) int {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	return pe.renameCategory(oldName, newName, func(song *models.Song) *string { return &song.Mood })
+}
+
+// renameCategory rewrites the field selected by field on every matching song and re-files it in the
+// explorer tree and similarity index
+// Time Complexity: O(n + k * t) where k is the number of changed songs and t the tree size
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) renameCategory(oldName, newName string, field func(*models.Song) *string) int {
+	oldName = strings.TrimSpace(oldName)
+	newName = strings.TrimSpace(newName)
+
+	renamed := 0
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		value := field(song)
+		if !strings.EqualFold(strings.TrimSpace(*value), oldName) || *value == newName {
+			continue
+		}
+
+		pe.playlistTree.RemoveSong(song.ID)
+		*value = newName
+		pe.playlistTree.AddSong(song)
+		pe.similarityIndex.Add(song)
+		renamed++
+	}
+
+	if renamed > 0 {
+		pe.activity.record(ActivityRenameCategory, "")
+	}
+	return renamed
+}
+
 // GetSubgenres returns subgenres for a specific genre
 // Time Complexity: O(s) where s is the number of subgenres
 // Space Complexity: O(s)
 func (pe *PlaylistEngine) GetSubgenres(genre string) []string {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
 	return pe.playlistTree.GetSubgenres(genre)
 }
 
@@ -276,6 +1782,9 @@ func (pe *PlaylistEngine) GetSubgenres(genre string) []string {
 // Time Complexity: O(m) where m is the number of moods
 // Space Complexity: O(m)
 func (pe *PlaylistEngine) GetMoods(genre, subgenre string) []string {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
 	return pe.playlistTree.GetMoods(genre, subgenre)
 }
 
@@ -283,64 +1792,158 @@ func (pe *PlaylistEngine) GetMoods(genre, subgenre string) []string {
 // Time Complexity: O(a) where a is the number of artists
 // Space Complexity: O(a)
 func (pe *PlaylistEngine) GetArtists(genre, subgenre, mood string) []string {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
 	return pe.playlistTree.GetArtists(genre, subgenre, mood)
 }
 
+// GetAllArtists returns the distinct artists across the whole playlist sorted A-Z
+// Artists are compared case-insensitively, keeping the spelling of the first song added
+// Time Complexity: O(a log a) where a is the number of distinct artists, using the artist index
+// Space Complexity: O(a)
+func (pe *PlaylistEngine) GetAllArtists() []string {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	artists := make([]string, 0, len(pe.artistIndex))
+	for _, songs := range pe.artistIndex {
+		artists = append(artists, songs[0].Artist)
+	}
+
+	sort.Slice(artists, func(i, j int) bool {
+		return strings.ToLower(artists[i]) < strings.ToLower(artists[j])
+	})
+
+	return artists
+}
+
+// DefaultRecommendationWindow is how many recent plays recommendations are based on by default
+const DefaultRecommendationWindow = 20
+
+// DefaultPlayScoreHalfLife is how long it takes a play to lose half its weight under play count decay
+const DefaultPlayScoreHalfLife = 30 * 24 * time.Hour
+
+// RecOptions tunes how smart recommendations are picked
+type RecOptions struct {
+	Count         int // 0 uses 10
+	HistoryWindow int // 0 uses DefaultRecommendationWindow
+	// Rank candidates by time-decayed play score instead of playlist order
+	DecayPlays bool
+	HalfLife   time.Duration // 0 uses DefaultPlayScoreHalfLife
+}
+
+// rankByDecayedPlays orders songs by descending DecayedPlayScore, keeping playlist order between ties
+// Time Complexity: O(n log n)
+// Space Complexity: O(n) for the precomputed scores
+func rankByDecayedPlays(songs []*models.Song, now time.Time, halfLife time.Duration) {
+	scores := make(map[string]float64, len(songs))
+	for _, song := range songs {
+		scores[song.ID] = song.DecayedPlayScore(now, halfLife)
+	}
+
+	sort.SliceStable(songs, func(i, j int) bool {
+		return scores[songs[i].ID] > scores[songs[j].ID]
+	})
+}
+
 // GetSmartRecommendations returns songs similar to recently played but not played recently
-// Time Complexity: O(n * h) where n is total songs and h is history size
+// Time Complexity: O(h * b) where h is history size and b the size of the similarity buckets
 // Space Complexity: O(k) where k is the number of recommendations
 func (pe *PlaylistEngine) GetSmartRecommendations(count int) []*models.Song {
+	return pe.GetSmartRecommendationsWindow(count, DefaultRecommendationWindow)
+}
+
+// GetSmartRecommendationsWindow returns recommendations based on the last historyWindow plays
+// A non-positive historyWindow falls back to DefaultRecommendationWindow
+// Time Complexity: O(w * b) where w is the history window and b the size of the similarity buckets
+// Space Complexity: O(k + w) where k is the number of recommendations
+func (pe *PlaylistEngine) GetSmartRecommendationsWindow(count, historyWindow int) []*models.Song {
+	return pe.GetSmartRecommendationsWithOptions(RecOptions{Count: count, HistoryWindow: historyWindow})
+}
+
+// GetSmartRecommendationsWithOptions returns recommendations picked according to opts
+// With DecayPlays, candidates are considered in order of DecayedPlayScore so recent favourites come first
+// Time Complexity: O(w * b) where w is the history window and b the size of the similarity buckets,
+// plus O(c log c) for c candidates with DecayPlays; O(n) when too few similar songs are found
+// Space Complexity: O(c) for the candidate list
+func (pe *PlaylistEngine) GetSmartRecommendationsWithOptions(opts RecOptions) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return cloneSongs(pe.smartRecommendations(opts))
+}
+
+// smartRecommendations implements GetSmartRecommendationsWithOptions for callers already holding pe.mu
+// Similar songs come from the similarity index, grouped by the most recently played song they match;
+// the playlist is only scanned when there is no history or too few similar songs to fill count
+func (pe *PlaylistEngine) smartRecommendations(opts RecOptions) []*models.Song {
+	count := opts.Count
 	if count <= 0 {
 		count = 10
 	}
+	historyWindow := opts.HistoryWindow
+	if historyWindow <= 0 {
+		historyWindow = DefaultRecommendationWindow
+	}
+	halfLife := opts.HalfLife
+	if halfLife <= 0 {
+		halfLife = DefaultPlayScoreHalfLife
+	}
 
-	recommendations := make([]*models.Song, 0, count)
-	recentSongs := pe.playbackHistory.GetRecentSongs(20) // Look at last 20 played songs
+	// Fill candidates come from the whole playlist, ranked the same way as the similar songs
+	playlistSongs := func() []*models.Song {
+		allSongs := pe.currentPlaylist.ToSlice()
+		if opts.DecayPlays {
+			rankByDecayedPlays(allSongs, time.Now(), halfLife)
+		}
+		return allSongs
+	}
 
+	recentSongs := pe.playbackHistory.GetRecentSongs(historyWindow)
 	if len(recentSongs) == 0 {
-		// No history, return random songs from playlist
-		allSongs := pe.currentPlaylist.ToSlice()
+		// No history, return the first songs from the playlist
+		allSongs := playlistSongs()
 		maxReturn := min(count, len(allSongs))
 		return allSongs[:maxReturn]
 	}
 
-	allSongs := pe.currentPlaylist.ToSlice()
-	recentSongIDs := make(map[string]bool)
-
 	// Create set of recently played song IDs
+	recentSongIDs := make(map[string]bool)
 	for _, song := range recentSongs {
 		recentSongIDs[song.ID] = true
 	}
 
-	// Find similar songs that haven't been played recently
-	for _, song := range allSongs {
+	// Decay ranking needs every similar song before the best ones can be picked
+	limit := count
+	if opts.DecayPlays {
+		limit = 0
+	}
+	similar := pe.indexedSimilarSongs(recentSongs, recentSongIDs, limit)
+	if opts.DecayPlays {
+		rankByDecayedPlays(similar, time.Now(), halfLife)
+	}
+
+	recommendations := make([]*models.Song, 0, count)
+	recommended := make(map[string]bool)
+	for _, song := range similar {
 		if len(recommendations) >= count {
 			break
 		}
-
-		// Skip if recently played
-		if recentSongIDs[song.ID] {
-			continue
-		}
-
-		// Check similarity with recent songs
-		for _, recentSong := range recentSongs {
-			if song.IsSimilar(recentSong) {
-				recommendations = append(recommendations, song)
-				break
-			}
-		}
+		recommendations = append(recommendations, song)
+		recommended[song.ID] = true
 	}
 
 	// If not enough similar songs, fill with unplayed songs
 	if len(recommendations) < count {
-		for _, song := range allSongs {
+		for _, song := range playlistSongs() {
 			if len(recommendations) >= count {
 				break
 			}
 
-			if !recentSongIDs[song.ID] && !pe.containsSong(recommendations, song.ID) {
+			if !recentSongIDs[song.ID] && !recommended[song.ID] {
 				recommendations = append(recommendations, song)
+				recommended[song.ID] = true
 			}
 		}
 	}
@@ -348,78 +1951,324 @@ func (pe *PlaylistEngine) GetSmartRecommendations(count int) []*models.Song {
 	return recommendations
 }
 
+// indexedSimilarSongs returns songs similar to any of recentSongs that are not in excluded, looking
+// only at the similarity index buckets of the recent songs; limit 0 collects every match
+// Songs similar to the most recent play come first, then in the order they were added
+// Time Complexity: O(w * b) where w is the number of recent songs and b the bucket size
+// Space Complexity: O(c) where c is the number of matches
+func (pe *PlaylistEngine) indexedSimilarSongs(recentSongs []*models.Song, excluded map[string]bool, limit int) []*models.Song {
+	similar := make([]*models.Song, 0)
+	seen := make(map[string]bool)
+	for _, recentSong := range recentSongs {
+		for _, song := range pe.similarityIndex.Candidates(recentSong, pe.similarityMode) {
+			if limit > 0 && len(similar) >= limit {
+				return similar
+			}
+			if excluded[song.ID] || seen[song.ID] || !song.IsSimilarWithMode(recentSong, pe.similarityMode) {
+				continue
+			}
+			seen[song.ID] = true
+			similar = append(similar, song)
+		}
+	}
+	return similar
+}
+
+// scannedSimilarSongs is the linear-scan counterpart of indexedSimilarSongs, comparing every song in
+// the playlist against every recent song; BenchmarkRecommendations uses it as the baseline
+// Time Complexity: O(n * w) where n is total songs and w is the number of recent songs
+// Space Complexity: O(c) where c is the number of matches
+func (pe *PlaylistEngine) scannedSimilarSongs(recentSongs []*models.Song, excluded map[string]bool, limit int) []*models.Song {
+	similar := make([]*models.Song, 0)
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		if limit > 0 && len(similar) >= limit {
+			break
+		}
+		if excluded[song.ID] {
+			continue
+		}
+		for _, recentSong := range recentSongs {
+			if song.IsSimilarWithMode(recentSong, pe.similarityMode) {
+				similar = append(similar, song)
+				break
+			}
+		}
+	}
+	return similar
+}
+
+// RecommendationBenchmark compares finding similar songs by scanning the playlist with the similarity index
+type RecommendationBenchmark struct {
+	Songs      int           `json:"songs"`
+	Seeds      int           `json:"seeds"` // Recent songs the recommendations were based on
+	Iterations int           `json:"iterations"`
+	Matches    int           `json:"matches"` // Similar songs found per lookup
+	Scan       time.Duration `json:"scan_ns"`
+	Indexed    time.Duration `json:"indexed_ns"`
+	Speedup    float64       `json:"speedup"` // Scan time divided by indexed time
+}
+
+// BenchmarkRecommendations times collecting every song similar to the recent history, iterations
+// times each, with a playlist scan and with the similarity index
+// Without any history the first DefaultRecommendationWindow songs of the playlist stand in for it
+// Time Complexity: O(i * n * w) for i iterations, dominated by the scan
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) BenchmarkRecommendations(iterations int) RecommendationBenchmark {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	if iterations <= 0 {
+		iterations = 1
+	}
+
+	seeds := pe.playbackHistory.GetRecentSongs(DefaultRecommendationWindow)
+	if len(seeds) == 0 {
+		allSongs := pe.currentPlaylist.ToSlice()
+		seeds = allSongs[:min(DefaultRecommendationWindow, len(allSongs))]
+	}
+	excluded := make(map[string]bool, len(seeds))
+	for _, song := range seeds {
+		excluded[song.ID] = true
+	}
+
+	result := RecommendationBenchmark{
+		Songs:      pe.currentPlaylist.Size(),
+		Seeds:      len(seeds),
+		Iterations: iterations,
+	}
+
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		result.Matches = len(pe.scannedSimilarSongs(seeds, excluded, 0))
+	}
+	result.Scan = time.Since(start)
+
+	start = time.Now()
+	for i := 0; i < iterations; i++ {
+		pe.indexedSimilarSongs(seeds, excluded, 0)
+	}
+	result.Indexed = time.Since(start)
+
+	if result.Indexed > 0 {
+		result.Speedup = float64(result.Scan) / float64(result.Indexed)
+	}
+	return result
+}
+
+// GetSimilarSongs returns up to count songs similar to the given song under the current similarity mode
+// Time Complexity: O(b) where b is the size of the song's similarity bucket
+// Space Complexity: O(k) where k is count
+func (pe *PlaylistEngine) GetSimilarSongs(songID string, count int) ([]*models.Song, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	target, err := pe.songLookup.Get(songID)
+	if err != nil {
+		return nil, fmt.Errorf("song not found: %v", err)
+	}
+
+	similar := make([]*models.Song, 0, count)
+	for _, song := range pe.similarityIndex.Candidates(target, pe.similarityMode) {
+		if len(similar) >= count {
+			break
+		}
+		if song.ID != target.ID && song.IsSimilarWithMode(target, pe.similarityMode) {
+			similar = append(similar, song)
+		}
+	}
+
+	return cloneSongs(similar), nil
+}
+
+// FindSongPath returns the explorer breadcrumb (genre, subgenre, mood, artist) for a song
+// Time Complexity: O(n) worst case
+// Space Complexity: O(d) where d is depth
+func (pe *PlaylistEngine) FindSongPath(songID string) ([]string, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.playlistTree.FindSongPath(songID)
+}
+
+// SetSimilarityMode changes how strictly recommendations match recently played songs
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) SetSimilarityMode(mode models.SimilarityMode) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.similarityMode = mode
+	pe.activity.record(ActivitySimilarityMode, "")
+}
+
+// GetSimilarityMode returns the similarity mode used for recommendations
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) GetSimilarityMode() models.SimilarityMode {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.similarityMode
+}
+
+// snapshotState is a point-in-time copy of the engine state needed to build a snapshot
+type snapshotState struct {
+	songs          []*models.Song
+	recentlyPlayed []*models.Song
+	ratingStats    map[int]int
+	treeStats      map[string]interface{}
+	playbackStats  map[string]interface{}
+	hashMapStats   map[string]interface{}
+	playlistName   string
+	totalPlayTime  int
+	createdAt      time.Time
+}
+
 // ExportSnapshot generates a live dashboard snapshot of the playlist state
-// Time Complexity: O(n) for statistics collection
+// The read lock is held for the whole export, including the top-K lists
+// Time Complexity: O(n log k) for the top-K lists, O(n) for statistics collection
 // Space Complexity: O(n) for the snapshot data
 func (pe *PlaylistEngine) ExportSnapshot() map[string]interface{} {
-	// Get top 5 longest songs
-	allSongs := pe.currentPlaylist.ToSlice()
-	pe.sorter.SetCriteria(datastructures.SortByDurationDesc)
-	sortedByDuration := pe.sorter.MergeSort(allSongs)
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
 
-	top5Longest := make([]*models.Song, 0, 5)
-	for i := 0; i < min(5, len(sortedByDuration)); i++ {
-		top5Longest = append(top5Longest, sortedByDuration[i])
-	}
+	return buildSnapshot(pe.copySnapshotState())
+}
 
-	// Get most recently played songs
-	recentlyPlayed := pe.playbackHistory.GetRecentSongs(10)
+// ExportSnapshotAsync generates the same snapshot as ExportSnapshot but only holds the read lock
+// while copying the required state, so writers aren't starved during dashboard loads
+// Time Complexity: O(n) under the lock, O(n log k) after releasing it
+// Space Complexity: O(n) for the snapshot data
+func (pe *PlaylistEngine) ExportSnapshotAsync() map[string]interface{} {
+	pe.mu.RLock()
+	state := pe.copySnapshotState()
+	pe.mu.RUnlock()
 
-	// Get song count by rating
-	ratingStats := pe.ratingTree.GetRatingStats()
+	return buildSnapshot(state)
+}
 
-	// Get playlist tree statistics
-	treeStats := pe.playlistTree.GetStats()
+// copySnapshotState copies the songs, slices and counters a snapshot needs
+// Callers must hold at least a read lock
+// Time Complexity: O(n)
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) copySnapshotState() snapshotState {
+	return snapshotState{
+		songs:          pe.copySongs(),
+		recentlyPlayed: cloneSongs(pe.playbackHistory.GetRecentSongs(10)),
+		ratingStats:    pe.ratingTree.GetRatingStats(),
+		treeStats:      pe.playlistTree.GetStats(),
+		playbackStats:  pe.playbackHistory.GetPlaybackStats(),
+		hashMapStats: map[string]interface{}{
+			"song_lookup_size":  pe.songLookup.GetSize(),
+			"song_lookup_load":  pe.songLookup.GetLoadFactor(),
+			"title_lookup_size": pe.titleLookup.TitleCount(),
+			"title_lookup_load": pe.titleLookup.GetLoadFactor(),
+		},
+		playlistName:  pe.playlistName,
+		totalPlayTime: pe.totalPlayTime,
+		createdAt:     pe.createdAt,
+	}
+}
 
-	// Get playback statistics
-	playbackStats := pe.playbackHistory.GetPlaybackStats()
+// buildSnapshot computes derived stats from copied state without touching the engine
+// The top lists by duration, play count and rating are filled in one pass with bounded heaps
+// Time Complexity: O(n log k) where k is DashboardTopK
+// Space Complexity: O(n)
+func buildSnapshot(state snapshotState) map[string]interface{} {
+	longest := datastructures.NewTopK(DashboardTopK, longerSong)
+	mostPlayed := datastructures.NewTopK(DashboardTopK, morePlayedSong)
+	topRated := datastructures.NewTopK(DashboardTopK, higherRatedSong)
+	for _, song := range state.songs {
+		longest.Push(song)
+		mostPlayed.Push(song)
+		if song.Rating > 0 {
+			topRated.Push(song)
+		}
+	}
 
 	return map[string]interface{}{
 		"playlist_info": map[string]interface{}{
-			"name":           pe.playlistName,
-			"total_songs":    pe.currentPlaylist.Size(),
-			"total_duration": pe.totalPlayTime,
-			"created_at":     pe.createdAt,
+			"name":           state.playlistName,
+			"total_songs":    len(state.songs),
+			"total_duration": state.totalPlayTime,
+			"created_at":     state.createdAt,
 			"last_updated":   time.Now(),
 		},
-		"top_longest_songs":   top5Longest,
-		"recently_played":     recentlyPlayed,
-		"rating_distribution": ratingStats,
-		"genre_stats":         treeStats,
-		"playback_stats":      playbackStats,
-		"hash_map_stats": map[string]interface{}{
-			"song_lookup_size":  pe.songLookup.GetSize(),
-			"song_lookup_load":  pe.songLookup.GetLoadFactor(),
-			"title_lookup_size": pe.titleLookup.GetSize(),
-			"title_lookup_load": pe.titleLookup.GetLoadFactor(),
-		},
+		"top_longest_songs":   longest.Results(),
+		"top_played_songs":    mostPlayed.Results(),
+		"top_rated_songs":     topRated.Results(),
+		"recently_played":     state.recentlyPlayed,
+		"rating_distribution": state.ratingStats,
+		"genre_stats":         state.treeStats,
+		"playback_stats":      state.playbackStats,
+		"hash_map_stats":      state.hashMapStats,
 	}
 }
 
 // GetPlaylistStats returns comprehensive statistics about the playlist
-// Time Complexity: O(n)
+// Play count and artist totals come from incrementally maintained counters
+// Time Complexity: O(r) where r is the number of distinct ratings
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) GetPlaylistStats() map[string]interface{} {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
 	return map[string]interface{}{
 		"total_songs":         pe.currentPlaylist.Size(),
 		"total_duration":      pe.totalPlayTime,
 		"average_song_length": pe.getAverageSongLength(),
 		"total_play_count":    pe.getTotalPlayCount(),
 		"unique_artists":      pe.getUniqueArtistCount(),
+		"unique_albums":       len(pe.albumIndex),
 		"unique_genres":       pe.playlistTree.GetStats()["genres"],
 		"rating_distribution": pe.ratingTree.GetRatingStats(),
 		"history_size":        pe.playbackHistory.GetSize(),
+		"tree_balance": map[string]datastructures.TreeBalance{
+			"rating":   pe.ratingTree.GetBalance(),
+			"duration": pe.durationIndex.Balance(),
+		},
 	}
 }
 
 // Helper methods
 
-// generateSongID creates a unique ID for a song
-func (pe *PlaylistEngine) generateSongID(title, artist string) string {
-	return fmt.Sprintf("%s-%s-%d",
-		strings.ReplaceAll(strings.ToLower(title), " ", "-"),
-		strings.ReplaceAll(strings.ToLower(artist), " ", "-"),
-		time.Now().UnixNano())
+// validateSongFieldLengths ensures no text field exceeds MaxSongFieldLength characters
+func validateSongFieldLengths(fields ...string) error {
+	for _, field := range fields {
+		if utf8.RuneCountInString(field) > MaxSongFieldLength {
+			return fmt.Errorf("song fields must be at most %d characters", MaxSongFieldLength)
+		}
+	}
+	return nil
+}
+
+// generateSongID creates an ID for a song according to the configured SongIDMode
+// The readable part is a slug of the title and artist; the suffix keeps IDs unique
+func (pe *PlaylistEngine) generateSongID(title, artist, album string) string {
+	title = strings.ToLower(strings.TrimSpace(title))
+	artist = strings.ToLower(strings.TrimSpace(artist))
+	album = strings.ToLower(strings.TrimSpace(album))
+
+	sep := pe.config.IDSeparator
+	var parts []string
+	for _, field := range []string{title, artist} {
+		if slug := slugify(field, sep); slug != "" {
+			parts = append(parts, slug)
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "song")
+	}
+
+	if pe.config.IDMode == SongIDContentHash {
+		normalized := strings.Join([]string{title, artist, album}, "\x00")
+		sum := sha256.Sum256([]byte(normalized))
+		parts = append(parts, hex.EncodeToString(sum[:8]))
+	} else {
+		parts = append(parts, strconv.FormatInt(time.Now().UnixNano(), 10))
+	}
+
+	return strings.Join(parts, sep)
 }
 
 // getAverageSongLength calculates the average song duration
@@ -430,24 +2279,72 @@ func (pe *PlaylistEngine) getAverageSongLength() float64 {
 	return float64(pe.totalPlayTime) / float64(pe.currentPlaylist.Size())
 }
 
-// getTotalPlayCount sums up play counts for all songs
+// getTotalPlayCount returns the cached sum of play counts for all songs
+// Time Complexity: O(1)
 func (pe *PlaylistEngine) getTotalPlayCount() int {
-	total := 0
-	songs := pe.currentPlaylist.ToSlice()
-	for _, song := range songs {
-		total += song.PlayCount
-	}
-	return total
+	return pe.playCountTotal
 }
 
-// getUniqueArtistCount counts unique artists in the playlist
+// getUniqueArtistCount returns the cached number of distinct artists
+// Time Complexity: O(1)
 func (pe *PlaylistEngine) getUniqueArtistCount() int {
-	artistSet := make(map[string]bool)
-	songs := pe.currentPlaylist.ToSlice()
-	for _, song := range songs {
-		artistSet[song.Artist] = true
+	return len(pe.artistIndex)
+}
+
+// SearchSongsByArtist returns every song by an artist, compared case-insensitively, in the order they were added
+// Time Complexity: O(k) where k is the number of songs by the artist
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) SearchSongsByArtist(artist string) []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs := pe.artistIndex[normalizeArtist(artist)]
+	return cloneSongs(songs)
+}
+
+// normalizeArtist returns the artist index key for an artist name
+// Time Complexity: O(m) where m is the name length
+// Space Complexity: O(m)
+func normalizeArtist(artist string) string {
+	return strings.ToLower(strings.TrimSpace(artist))
+}
+
+// indexArtist appends a song to its artist's bucket
+// Time Complexity: O(1) amortized
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) indexArtist(song *models.Song) {
+	key := normalizeArtist(song.Artist)
+	pe.artistIndex[key] = append(pe.artistIndex[key], song)
+}
+
+// unindexArtist removes a song from its artist's bucket, dropping the bucket once empty
+// Time Complexity: O(k) where k is the number of songs by the artist
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) unindexArtist(song *models.Song) {
+	removeFromBucket(pe.artistIndex, normalizeArtist(song.Artist), song.ID)
+}
+
+// removeFromBucket removes a song from one bucket of a grouping index, dropping the bucket once empty
+// Time Complexity: O(k) where k is the number of songs in the bucket
+// Space Complexity: O(1)
+func removeFromBucket[K comparable](index map[K][]*models.Song, key K, songID string) {
+	songs, ok := index[key]
+	if !ok {
+		return
+	}
+
+	for i, indexed := range songs {
+		if indexed.ID == songID {
+			songs = append(songs[:i], songs[i+1:]...)
+			break
+		}
+	}
+
+	if len(songs) == 0 {
+		delete(index, key)
+		return
 	}
-	return len(artistSet)
+	index[key] = songs
 }
 
 // containsSong checks if a song ID exists in a slice of songs
@@ -472,20 +2369,49 @@ func min(a, b int) int {
 // Time Complexity: O(n)
 // Space Complexity: O(n)
 func (pe *PlaylistEngine) GetCurrentPlaylist() []*models.Song {
-	return pe.currentPlaylist.ToSlice()
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.copySongs()
 }
 
 // GetPlaylistSize returns the size of the current playlist
 // Time Complexity: O(1)
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) GetPlaylistSize() int {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
 	return pe.currentPlaylist.Size()
 }
 
+// GetTotalDuration returns the combined duration of all songs in seconds
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) GetTotalDuration() int {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.totalPlayTime
+}
+
+// GetTotalPlayCount returns the sum of play counts across all songs
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) GetTotalPlayCount() int {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.getTotalPlayCount()
+}
+
 // GetPlaylistName returns the name of the playlist
 // Time Complexity: O(1)
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) GetPlaylistName() string {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
 	return pe.playlistName
 }
 
@@ -493,25 +2419,65 @@ func (pe *PlaylistEngine) GetPlaylistName() string {
 // Time Complexity: O(1)
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) SetPlaylistName(name string) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
 	pe.playlistName = name
+	pe.activity.record(ActivityRename, "")
 }
 
 // ClearPlaylist removes all songs from the playlist
 // Time Complexity: O(1)
 // Space Complexity: O(1)
 func (pe *PlaylistEngine) ClearPlaylist() {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.clearSongs()
+	pe.activity.record(ActivityClear, "")
+}
+
+// clearSongs empties the playlist and every index built from it, keeping history and the trash
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) clearSongs() {
 	pe.currentPlaylist.Clear()
 	pe.ratingTree.Clear()
 	pe.songLookup.Clear()
 	pe.titleLookup.Clear()
-	pe.playlistTree = datastructures.NewPlaylistExplorerTree()
+	pe.searchCache.Clear()
+	pe.fuzzyIndex.Clear()
+	pe.similarityIndex.Clear()
+	pe.bpmIndex.Clear()
+	pe.durationIndex.Clear()
+	pe.playlistTree = datastructures.NewPlaylistExplorerTreeWithLabels(pe.config.TreeLabels)
+	pe.playQueue.Clear()
+	pe.player.reset()
 	pe.totalPlayTime = 0
+	pe.artistIndex = make(map[string][]*models.Song)
+	pe.albumIndex = make(map[albumKey][]*models.Song)
+	pe.playCountTotal = 0
+	pe.sortState = customSortState()
+}
+
+// GetActivityLog returns up to limit recent mutating operations, newest first
+// A non-positive limit returns every retained entry
+// Time Complexity: O(k) where k is the number of returned entries
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) GetActivityLog(limit int) []Activity {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.activity.recent(limit)
 }
 
 // BenchmarkSort compares the performance of different sorting algorithms
 // Time Complexity: O(n log n) for each algorithm tested
 // Space Complexity: O(n) for creating copies
 func (pe *PlaylistEngine) BenchmarkSort() map[string]time.Duration {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
 	songs := pe.currentPlaylist.ToSlice()
 	return pe.sorter.BenchmarkSort(songs)
 }
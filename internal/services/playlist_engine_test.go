@@ -1,10 +1,16 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
 	"src/internal/datastructures"
 	"src/internal/models"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -51,11 +57,15 @@ func TestAddSong(t *testing.T) {
 	engine := NewPlaylistEngine("Test")
 
 	// Test valid song addition
-	err := engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	songID, err := engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
+	if song, err := engine.SearchSongByID(songID); err != nil || song.Title != "Test Song" {
+		t.Errorf("Expected returned ID %q to resolve to the new song", songID)
+	}
+
 	if engine.GetPlaylistSize() != 1 {
 		t.Errorf("Expected playlist size 1, got %d", engine.GetPlaylistSize())
 	}
@@ -65,7 +75,7 @@ func TestAddSong(t *testing.T) {
 	}
 
 	// Test duplicate song addition
-	err = engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	_, err = engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
 	if err == nil {
 		t.Error("Expected error for duplicate song")
 	}
@@ -74,22 +84,112 @@ func TestAddSong(t *testing.T) {
 	}
 
 	// Test empty title
-	err = engine.AddSong("", "Artist", "Album", "Genre", "Subgenre", "Mood", 180, 100)
+	_, err = engine.AddSong("", "Artist", "Album", "Genre", "Subgenre", "Mood", 180, 100)
 	if err == nil {
 		t.Error("Expected error for empty title")
 	}
 
 	// Test empty artist
-	err = engine.AddSong("Title", "", "Album", "Genre", "Subgenre", "Mood", 180, 100)
+	_, err = engine.AddSong("Title", "", "Album", "Genre", "Subgenre", "Mood", 180, 100)
 	if err == nil {
 		t.Error("Expected error for empty artist")
 	}
 
 	// Test whitespace-only title and artist
-	err = engine.AddSong("   ", "   ", "Album", "Genre", "Subgenre", "Mood", 180, 100)
+	_, err = engine.AddSong("   ", "   ", "Album", "Genre", "Subgenre", "Mood", 180, 100)
 	if err == nil {
 		t.Error("Expected error for whitespace-only title and artist")
 	}
+
+	// Test over-length fields
+	_, err = engine.AddSong(strings.Repeat("a", MaxSongFieldLength+1), "Artist", "Album", "Genre", "Subgenre", "Mood", 180, 100)
+	if err == nil {
+		t.Error("Expected error for over-length title")
+	}
+	_, err = engine.AddSong("Title", "Artist", strings.Repeat("b", MaxSongFieldLength+1), "Genre", "Subgenre", "Mood", 180, 100)
+	if err == nil {
+		t.Error("Expected error for over-length album")
+	}
+
+	// Test fields at the limit are accepted and surrounding whitespace is trimmed
+	_, err = engine.AddSong("  "+strings.Repeat("c", MaxSongFieldLength)+"  ", "Artist", "Album", "Genre", "Subgenre", "Mood", 180, 100)
+	if err != nil {
+		t.Errorf("Expected no error for title at the length limit, got %v", err)
+	}
+	songs := engine.GetCurrentPlaylist()
+	if songs[len(songs)-1].Title != strings.Repeat("c", MaxSongFieldLength) {
+		t.Error("Expected title whitespace to be trimmed")
+	}
+}
+
+func TestAddSongDuplicatePolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         DuplicatePolicy
+		identicalAdded bool
+		otherAlbum     bool
+	}{
+		{"reject", DuplicateReject, false, false},
+		{"allow", DuplicateAllow, true, true},
+		{"allow if different album", DuplicateAllowIfDifferentAlbum, false, true},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range []SongIDMode{SongIDTimestamp, SongIDContentHash} {
+			t.Run(fmt.Sprintf("%s/mode %d", tt.name, mode), func(t *testing.T) {
+				config := DefaultEngineConfig()
+				config.IDMode = mode
+				config.DuplicatePolicy = tt.policy
+				engine := NewPlaylistEngineWithConfig("Test", config)
+
+				firstID, err := engine.AddSong("Hotel California", "Eagles", "Hotel California", "Rock", "Classic Rock", "Relaxed", 390, 75)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				identicalID, err := engine.AddSong("hotel california", "EAGLES", "hotel california", "Rock", "Classic Rock", "Relaxed", 390, 75)
+				if (err == nil) != tt.identicalAdded {
+					t.Errorf("Identical song: expected added %v, got error %v", tt.identicalAdded, err)
+				}
+				if err == nil && identicalID == firstID {
+					t.Errorf("Expected the repeated song to get its own ID, both are %s", firstID)
+				}
+
+				_, err = engine.AddSong("Hotel California", "Eagles", "Hell Freezes Over", "Rock", "Classic Rock", "Relaxed", 430, 75)
+				if (err == nil) != tt.otherAlbum {
+					t.Errorf("Other album: expected added %v, got error %v", tt.otherAlbum, err)
+				}
+
+				expectedSize := 1
+				if tt.identicalAdded {
+					expectedSize++
+				}
+				if tt.otherAlbum {
+					expectedSize++
+				}
+				if engine.GetPlaylistSize() != expectedSize {
+					t.Errorf("Expected %d songs, got %d", expectedSize, engine.GetPlaylistSize())
+				}
+				for _, song := range engine.GetCurrentPlaylist() {
+					if found, err := engine.SearchSongByID(song.ID); err != nil || !reflect.DeepEqual(found, song) {
+						t.Errorf("Expected %s to resolve to its own song", song.ID)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	if policy, err := ParseDuplicatePolicy("allow_different_album"); err != nil || policy != DuplicateAllowIfDifferentAlbum {
+		t.Errorf("Expected DuplicateAllowIfDifferentAlbum, got %v %v", policy, err)
+	}
+	if policy, err := ParseDuplicatePolicy(" Allow "); err != nil || policy != DuplicateAllow {
+		t.Errorf("Expected DuplicateAllow, got %v %v", policy, err)
+	}
+	if policy, err := ParseDuplicatePolicy("sometimes"); err == nil || policy != DuplicateReject {
+		t.Errorf("Expected an error and DuplicateReject, got %v %v", policy, err)
+	}
 }
 
 func TestDeleteSong(t *testing.T) {
@@ -134,6 +234,46 @@ func TestDeleteSong(t *testing.T) {
 	}
 }
 
+func TestDeleteSongByID(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album 2", "Pop", "Mainstream", "Happy", 200, 110)
+
+	songs := engine.GetCurrentPlaylist()
+	engine.RateSong(songs[1].ID, 4)
+
+	deletedSong, err := engine.DeleteSongByID(songs[1].ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if deletedSong.Title != "Song 2" {
+		t.Errorf("Expected deleted song 'Song 2', got %s", deletedSong.Title)
+	}
+
+	// All structures should be in sync
+	if engine.GetPlaylistSize() != 1 {
+		t.Errorf("Expected playlist size 1, got %d", engine.GetPlaylistSize())
+	}
+	if _, err := engine.SearchSongByID(songs[1].ID); err == nil {
+		t.Error("Deleted song should not be found by ID")
+	}
+	if len(engine.GetSongsByRating(4)) != 0 {
+		t.Error("Deleted song should be removed from rating tree")
+	}
+	if len(engine.GetPlaylistByExplorer("Pop", "Mainstream", "Happy", "Artist 2")) != 0 {
+		t.Error("Deleted song should be removed from explorer tree")
+	}
+	if engine.totalPlayTime != 240 {
+		t.Errorf("Expected total play time 240, got %d", engine.totalPlayTime)
+	}
+
+	// Unknown ID
+	if _, err := engine.DeleteSongByID("missing"); err == nil {
+		t.Error("Expected error for unknown song ID")
+	}
+}
+
 func TestMoveSong(t *testing.T) {
 	engine := NewPlaylistEngine("Test")
 
@@ -164,6 +304,20 @@ func TestMoveSong(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for invalid to index")
 	}
+
+	// Moving backward to the first position
+	engine.MoveSong(2, 0)
+	songs = engine.GetCurrentPlaylist()
+	if songs[0].Title != "Song 1" {
+		t.Errorf("Expected 'Song 1' at position 0, got %s", songs[0].Title)
+	}
+
+	// Moving forward to the last position
+	engine.MoveSong(0, engine.GetPlaylistSize()-1)
+	songs = engine.GetCurrentPlaylist()
+	if songs[len(songs)-1].Title != "Song 1" {
+		t.Errorf("Expected 'Song 1' at last position, got %s", songs[len(songs)-1].Title)
+	}
 }
 
 func TestReversePlaylist(t *testing.T) {
@@ -358,10 +512,10 @@ func TestSearchSongByTitle(t *testing.T) {
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if foundSong == nil {
+	if len(foundSong) != 1 {
 		t.Fatal("Expected found song")
 	}
-	if foundSong.Title != "Test Song" {
+	if foundSong[0].Title != "Test Song" {
 		t.Error("Found song title should match search title")
 	}
 
@@ -372,6 +526,37 @@ func TestSearchSongByTitle(t *testing.T) {
 	}
 }
 
+func TestSearchSongByTitle_SharedTitles(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	first, _ := engine.AddSong("Hurt", "Nine Inch Nails", "The Downward Spiral", "Rock", "Industrial", "Dark", 373, 90)
+	second, _ := engine.AddSong("Hurt", "Johnny Cash", "American IV", "Country", "Folk", "Sad", 218, 94)
+	engine.AddSong("Hurt Me", "Other", "Album", "Pop", "Mainstream", "Sad", 200, 100)
+
+	songs, err := engine.SearchSongByTitle("  hurt ")
+	if err != nil || len(songs) != 2 || songs[0].ID != first || songs[1].ID != second {
+		t.Fatalf("Expected both songs titled Hurt in insertion order, got %v, %v", songs, err)
+	}
+
+	// Deleting and renaming keep the index in step
+	engine.DeleteSongByID(first)
+	if songs, _ := engine.SearchSongByTitle("Hurt"); len(songs) != 1 || songs[0].ID != second {
+		t.Errorf("Expected only the remaining song after a delete, got %v", songs)
+	}
+	title := "Hurt (Live)"
+	if _, err := engine.UpdateSong(second, SongUpdate{Title: &title}); err != nil {
+		t.Fatalf("Expected the rename to succeed, got %v", err)
+	}
+	if _, err := engine.SearchSongByTitle("Hurt"); err == nil {
+		t.Error("Expected no song left under the old title")
+	}
+	if songs, _ := engine.SearchSongByTitle("hurt (live)"); len(songs) != 1 {
+		t.Errorf("Expected the renamed song under its new title, got %v", songs)
+	}
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes, got %v", issues)
+	}
+}
+
 func TestGetSongsByRating(t *testing.T) {
 	engine := NewPlaylistEngine("Test")
 
@@ -458,7 +643,9 @@ func TestSortPlaylist(t *testing.T) {
 	}
 
 	// Test sorting by duration (ascending)
-	engine.SortPlaylist(datastructures.SortByDurationAsc, "quick")
+	if used := engine.SortPlaylist(datastructures.SortByDurationAsc, "quick"); used != "quick" {
+		t.Errorf("Expected quick sort to be used, got %s", used)
+	}
 
 	songs = engine.GetCurrentPlaylist()
 	expectedDurations := []int{180, 240, 300}
@@ -468,6 +655,52 @@ func TestSortPlaylist(t *testing.T) {
 			t.Errorf("Position %d: expected duration %d, got %d", i, expectedDuration, songs[i].Duration)
 		}
 	}
+
+	// Unknown algorithms fall back to merge sort but still sort
+	if used := engine.SortPlaylist(datastructures.SortByTitle, "unknown"); used != "merge" {
+		t.Errorf("Expected fallback to merge sort, got %s", used)
+	}
+	if songs = engine.GetCurrentPlaylist(); songs[0].Title != "Alpha" {
+		t.Errorf("Expected playlist sorted by title after fallback, got %s first", songs[0].Title)
+	}
+}
+
+func TestSortState(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	engine.AddSong("Zebra", "Artist C", "Album 3", "Rock", "Alternative", "Energetic", 300, 120)
+	engine.AddSong("Alpha", "Artist A", "Album 1", "Pop", "Mainstream", "Happy", 180, 110)
+
+	if state := engine.GetSortState(); state.Criteria != SortStateCustom {
+		t.Errorf("Expected a new playlist to be in custom order, got %s", state.Criteria)
+	}
+
+	engine.SortPlaylist(datastructures.SortByDurationAsc, "quick")
+	state := engine.GetSortState()
+	if state.Criteria != "duration_asc" || state.Algorithm != "quick" {
+		t.Errorf("Expected duration_asc via quick, got %s via %s", state.Criteria, state.Algorithm)
+	}
+	if state.Description != "Duration (Shortest First)" {
+		t.Errorf("Expected the sorter description, got %q", state.Description)
+	}
+
+	if err := engine.MoveSong(0, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if state := engine.GetSortState(); state.Criteria != SortStateCustom || state.Algorithm != "" {
+		t.Errorf("Expected custom order after a move, got %+v", state)
+	}
+
+	engine.SortPlaylist(datastructures.SortByTitle, "merge")
+	engine.ReversePlaylist()
+	if state := engine.GetSortState(); state.Criteria != SortStateCustom {
+		t.Errorf("Expected custom order after a reverse, got %s", state.Criteria)
+	}
+
+	engine.SortPlaylist(datastructures.SortByTitle, "merge")
+	engine.AddSong("Beta", "Artist B", "Album 2", "Jazz", "Smooth", "Relaxed", 240, 90)
+	if state := engine.GetSortState(); state.Criteria != SortStateCustom {
+		t.Errorf("Expected custom order after appending a song, got %s", state.Criteria)
+	}
 }
 
 func TestGetRecentlyPlayedSongs(t *testing.T) {
@@ -506,6 +739,44 @@ func TestGetRecentlyPlayedSongs(t *testing.T) {
 	}
 }
 
+func TestGetSongsPlayedSince(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	// No plays yet returns zeros
+	stats := engine.GetTodayStats()
+	if stats["songs_played"].(int) != 0 || stats["total_listen_time"].(int) != 0 {
+		t.Error("Expected zero stats with no plays today")
+	}
+
+	engine.AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album 2", "Pop", "Mainstream", "Happy", 200, 110)
+
+	before := time.Now()
+	engine.PlaySong(0)
+	engine.PlaySong(1)
+	engine.PlaySong(0)
+
+	played := engine.GetSongsPlayedSince(before)
+	if len(played) != 3 {
+		t.Errorf("Expected 3 plays since start, got %d", len(played))
+	}
+	if played[0].Title != "Song 1" || played[1].Title != "Song 2" {
+		t.Error("Expected plays in most recent first order")
+	}
+
+	if len(engine.GetSongsPlayedSince(time.Now().Add(time.Hour))) != 0 {
+		t.Error("Expected no plays in the future")
+	}
+
+	stats = engine.GetTodayStats()
+	if stats["songs_played"].(int) != 3 {
+		t.Errorf("Expected 3 songs played today, got %v", stats["songs_played"])
+	}
+	if stats["total_listen_time"].(int) != 680 {
+		t.Errorf("Expected 680 seconds listened today, got %v", stats["total_listen_time"])
+	}
+}
+
 func TestPlaylistExplorerMethods(t *testing.T) {
 	engine := NewPlaylistEngine("Test")
 
@@ -585,6 +856,31 @@ func TestGetSmartRecommendations(t *testing.T) {
 	}
 }
 
+func TestGetSmartRecommendationsSimilarityModes(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Seed", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	engine.AddSong("Same Genre", "Artist 2", "Album", "Rock", "Classic Rock", "Calm", 600, 90)
+	engine.AddSong("Same Subgenre", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 600, 130)
+	engine.PlaySong(0)
+
+	if engine.GetSimilarityMode() != models.SimilarityDefault {
+		t.Error("Expected default similarity mode")
+	}
+
+	engine.SetSimilarityMode(models.SimilarityLoose)
+	loose := engine.GetSmartRecommendations(1)
+	if len(loose) != 1 || loose[0].Title != "Same Genre" {
+		t.Errorf("Expected loose mode to recommend 'Same Genre', got %v", loose)
+	}
+
+	engine.SetSimilarityMode(models.SimilarityStrict)
+	strict := engine.GetSmartRecommendations(1)
+	if len(strict) != 1 || strict[0].Title != "Same Subgenre" {
+		t.Errorf("Expected strict mode to recommend 'Same Subgenre', got %v", strict)
+	}
+}
+
 func TestExportSnapshot(t *testing.T) {
 	engine := NewPlaylistEngine("Test Playlist")
 
@@ -664,6 +960,65 @@ func TestExportSnapshot(t *testing.T) {
 	}
 }
 
+func TestExportSnapshotAsync(t *testing.T) {
+	engine := NewPlaylistEngine("Test Playlist")
+
+	engine.AddSong("Long Song", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 400, 120)
+	engine.AddSong("Short Song", "Artist 2", "Album 2", "Pop", "Mainstream", "Happy", 150, 130)
+	engine.PlaySong(1)
+
+	full := engine.ExportSnapshot()
+	async := engine.ExportSnapshotAsync()
+
+	for _, key := range []string{"playlist_info", "top_longest_songs", "recently_played", "rating_distribution", "genre_stats", "playback_stats", "hash_map_stats"} {
+		if _, exists := async[key]; !exists {
+			t.Errorf("Async snapshot should contain %s", key)
+		}
+	}
+
+	if async["playlist_info"].(map[string]interface{})["total_songs"] != full["playlist_info"].(map[string]interface{})["total_songs"] {
+		t.Error("Async snapshot should report the same song count")
+	}
+
+	topLongest := async["top_longest_songs"].([]*models.Song)
+	if len(topLongest) != 2 || topLongest[0].Title != "Long Song" {
+		t.Error("Async snapshot should order longest songs first")
+	}
+
+	if len(async["recently_played"].([]*models.Song)) != 1 {
+		t.Error("Async snapshot should contain recently played songs")
+	}
+}
+
+func TestExportSnapshot_TopLists(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	for i := 0; i < DashboardTopK+2; i++ {
+		engine.AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 100+i*10, 120)
+	}
+	engine.PlaySong(2)
+	engine.PlaySong(2)
+	engine.PlaySong(4)
+	songs := engine.GetCurrentPlaylist()
+	engine.RateSong(songs[1].ID, 3)
+	engine.RateSong(songs[3].ID, 5)
+
+	snapshot := engine.ExportSnapshot()
+	longest := snapshot["top_longest_songs"].([]*models.Song)
+	if len(longest) != DashboardTopK || longest[0].Title != fmt.Sprintf("Song %d", DashboardTopK+1) {
+		t.Errorf("Expected the %d longest songs, longest first, got %d", DashboardTopK, len(longest))
+	}
+
+	played := snapshot["top_played_songs"].([]*models.Song)
+	if len(played) != DashboardTopK || played[0].Title != "Song 2" || played[1].Title != "Song 4" {
+		t.Error("Expected the most played songs first in top_played_songs")
+	}
+
+	rated := snapshot["top_rated_songs"].([]*models.Song)
+	if len(rated) != 2 || rated[0].Title != "Song 3" || rated[1].Title != "Song 1" {
+		t.Errorf("Expected only rated songs, highest first, got %d", len(rated))
+	}
+}
+
 func TestGetPlaylistStats(t *testing.T) {
 	engine := NewPlaylistEngine("Test")
 
@@ -719,6 +1074,35 @@ func TestGetPlaylistStats(t *testing.T) {
 	if !exists {
 		t.Error("Stats should contain rating_distribution")
 	}
+
+	// Sequential ratings would degrade a plain BST; both trees report their balance
+	balance := stats["tree_balance"].(map[string]datastructures.TreeBalance)
+	if rating := balance["rating"]; !rating.Balanced || rating.Nodes != 2 || rating.Height != 2 {
+		t.Errorf("Expected a balanced rating tree of 2 nodes, got %+v", rating)
+	}
+	if duration := balance["duration"]; !duration.Balanced || duration.Nodes != 3 || duration.Height != 2 {
+		t.Errorf("Expected a balanced duration tree of 3 nodes, got %+v", duration)
+	}
+}
+
+func TestGetTotals(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	if engine.GetTotalDuration() != 0 || engine.GetTotalPlayCount() != 0 {
+		t.Error("Expected zero totals for empty playlist")
+	}
+
+	engine.AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 240, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album 2", "Pop", "Mainstream", "Happy", 200, 110)
+	engine.PlaySong(0)
+	engine.PlaySong(1)
+
+	if engine.GetTotalDuration() != 440 {
+		t.Errorf("Expected total duration 440, got %d", engine.GetTotalDuration())
+	}
+	if engine.GetTotalPlayCount() != 2 {
+		t.Errorf("Expected total play count 2, got %d", engine.GetTotalPlayCount())
+	}
 }
 
 func TestPlaylistNameOperations(t *testing.T) {
@@ -814,9 +1198,9 @@ func TestGenerateSongID(t *testing.T) {
 	engine := NewPlaylistEngine("Test")
 
 	// Generate IDs for same song at different times
-	id1 := engine.generateSongID("Test Song", "Test Artist")
+	id1 := engine.generateSongID("Test Song", "Test Artist", "Test Album")
 	time.Sleep(1 * time.Millisecond) // Ensure different timestamp
-	id2 := engine.generateSongID("Test Song", "Test Artist")
+	id2 := engine.generateSongID("Test Song", "Test Artist", "Test Album")
 
 	// IDs should be different due to timestamp
 	if id1 == id2 {
@@ -832,6 +1216,37 @@ func TestGenerateSongID(t *testing.T) {
 	}
 }
 
+func TestGenerateSongIDContentHash(t *testing.T) {
+	engine := NewPlaylistEngineWithConfig("Test", EngineConfig{IDMode: SongIDContentHash})
+
+	id1 := engine.generateSongID("Test Song", "Test Artist", "Test Album")
+	id2 := engine.generateSongID("  test song ", "TEST ARTIST", "test album")
+	if id1 != id2 {
+		t.Errorf("Expected identical songs to share an ID, got %s and %s", id1, id2)
+	}
+
+	id3 := engine.generateSongID("Test Song", "Test Artist", "Other Album")
+	if id1 == id3 {
+		t.Error("Expected a different album to produce a different ID")
+	}
+
+	firstID, err := engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if firstID != id1 {
+		t.Errorf("Expected AddSong to return %s, got %s", id1, firstID)
+	}
+
+	_, err = engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	if err == nil {
+		t.Error("Expected second identical add to be rejected")
+	}
+	if engine.GetPlaylistSize() != 1 {
+		t.Errorf("Expected playlist size 1, got %d", engine.GetPlaylistSize())
+	}
+}
+
 func TestIntegrationScenario(t *testing.T) {
 	// Full integration test simulating real usage
 	engine := NewPlaylistEngine("My Awesome Playlist")
@@ -849,7 +1264,7 @@ func TestIntegrationScenario(t *testing.T) {
 	}
 
 	for _, song := range songs {
-		err := engine.AddSong(song.title, song.artist, song.album, song.genre, song.subgenre, song.mood, song.duration, song.bpm)
+		_, err := engine.AddSong(song.title, song.artist, song.album, song.genre, song.subgenre, song.mood, song.duration, song.bpm)
 		if err != nil {
 			t.Errorf("Failed to add song %s: %v", song.title, err)
 		}
@@ -873,7 +1288,7 @@ func TestIntegrationScenario(t *testing.T) {
 
 	// Test search functionality
 	queen, err := engine.SearchSongByTitle("Bohemian Rhapsody")
-	if err != nil || queen.Artist != "Queen" {
+	if err != nil || queen[0].Artist != "Queen" {
 		t.Error("Failed to search Queen song")
 	}
 
@@ -937,3 +1352,1314 @@ func init() {
 	// This ensures fmt is available for sprintf operations in tests
 	_ = fmt.Sprintf
 }
+
+func TestLoadSampleDataAppliesRatings(t *testing.T) {
+	engine := NewPlaylistEngine("Test Playlist")
+	loader := NewSampleDataLoader()
+
+	if err := loader.LoadSampleData(engine); err != nil {
+		t.Fatalf("Expected no error loading sample data, got %v", err)
+	}
+
+	topRated := engine.GetSongsByRating(5)
+	if len(topRated) == 0 {
+		t.Error("Expected sample songs with a rating of 5 after loading sample data")
+	}
+	for _, song := range topRated {
+		if song.Rating != 5 {
+			t.Errorf("Expected rating 5 for %s, got %d", song.Title, song.Rating)
+		}
+	}
+}
+
+func TestLoadSampleDataFiltered(t *testing.T) {
+	engine := NewPlaylistEngine("Test Playlist")
+	loader := NewSampleDataLoader()
+
+	ignored, err := loader.LoadSampleDataFiltered(engine, []string{"jazz", "Polka"}, 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(ignored) != 1 || ignored[0] != "Polka" {
+		t.Errorf("Expected Polka to be ignored, got %v", ignored)
+	}
+	if engine.GetPlaylistSize() != 5 {
+		t.Errorf("Expected 5 songs, got %d", engine.GetPlaylistSize())
+	}
+}
+
+func TestGetGenreStatistics(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	if len(engine.GetGenreStatistics()) != 0 {
+		t.Error("Expected empty statistics for empty playlist")
+	}
+
+	rockID, _ := engine.AddSong("Rock Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Rock Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 100, 120)
+	engine.AddSong("Jazz Song", "Artist 3", "Album", "Jazz", "Smooth Jazz", "Relaxed", 300, 90)
+	engine.RateSong(rockID, 4)
+
+	stats := engine.GetGenreStatistics()
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 genres, got %d", len(stats))
+	}
+
+	rock := stats["Rock"]
+	if rock.SongCount != 2 || rock.TotalDuration != 300 || rock.AverageDuration != 150 {
+		t.Errorf("Unexpected Rock stats: %+v", rock)
+	}
+	if rock.AverageRating != 4 {
+		t.Errorf("Expected Rock average rating 4 (unrated songs ignored), got %f", rock.AverageRating)
+	}
+	if stats["Jazz"].AverageRating != 0 {
+		t.Errorf("Expected Jazz average rating 0, got %f", stats["Jazz"].AverageRating)
+	}
+}
+
+func TestUnrateSong(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	songID, _ := engine.AddSong("Test Song", "Test Artist", "Test Album", "Rock", "Alternative", "Energetic", 240, 120)
+	engine.RateSong(songID, 4)
+
+	if len(engine.GetUnratedSongs()) != 0 {
+		t.Error("Expected no unrated songs after rating")
+	}
+
+	if err := engine.UnrateSong(songID); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(engine.GetSongsByRating(4)) != 0 {
+		t.Error("Song should have been removed from the rating tree")
+	}
+
+	unrated := engine.GetUnratedSongs()
+	if len(unrated) != 1 || unrated[0].ID != songID || unrated[0].Rating != 0 {
+		t.Error("Song should appear in unrated songs with rating 0")
+	}
+
+	// Unrating an unrated song is a no-op
+	if err := engine.UnrateSong(songID); err != nil {
+		t.Errorf("Expected no error for already unrated song, got %v", err)
+	}
+
+	// Song can be rated again afterwards
+	engine.RateSong(songID, 2)
+	if len(engine.GetSongsByRating(2)) != 1 {
+		t.Error("Song should be rateable after unrating")
+	}
+
+	if err := engine.UnrateSong("missing"); err == nil {
+		t.Error("Expected error for unknown song")
+	}
+}
+
+// newSnapshotBenchmarkEngine builds an engine large enough for snapshot lock timing to matter
+func newSnapshotBenchmarkEngine() *PlaylistEngine {
+	engine := NewPlaylistEngine("Benchmark")
+	for i := 0; i < 5000; i++ {
+		engine.AddSong(fmt.Sprintf("Song %d", i), fmt.Sprintf("Artist %d", i%50), "Album", "Rock", "Alternative", "Energetic", 100+i%300, 120)
+	}
+	return engine
+}
+
+// benchmarkWriterWait times a writer while export runs in a loop on other goroutines
+// A waiting writer blocks new readers, so each write waits out at most one export's read lock hold
+func benchmarkWriterWait(b *testing.B, export func(*PlaylistEngine) map[string]interface{}) {
+	engine := newSnapshotBenchmarkEngine()
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	var started sync.WaitGroup
+	for r := 0; r < 2; r++ {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			export(engine)
+			started.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					export(engine)
+				}
+			}
+		}()
+	}
+
+	started.Wait()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Let the readers take the lock again before the next timed write
+		b.StopTimer()
+		time.Sleep(100 * time.Microsecond)
+		b.StartTimer()
+		engine.SetPlaylistName("Benchmark")
+	}
+	b.StopTimer()
+	close(stop)
+	wg.Wait()
+}
+
+// BenchmarkExportSnapshotLockHold measures how long a writer waits while ExportSnapshot runs
+func BenchmarkExportSnapshotLockHold(b *testing.B) {
+	benchmarkWriterWait(b, (*PlaylistEngine).ExportSnapshot)
+}
+
+// BenchmarkExportSnapshotAsyncLockHold measures how long a writer waits while ExportSnapshotAsync runs
+func BenchmarkExportSnapshotAsyncLockHold(b *testing.B) {
+	benchmarkWriterWait(b, (*PlaylistEngine).ExportSnapshotAsync)
+}
+
+func TestGetAllArtists(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	if len(engine.GetAllArtists()) != 0 {
+		t.Error("Expected no artists for empty playlist")
+	}
+
+	engine.AddSong("Song 1", "beta Band", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Alpha", "Album", "Pop", "Mainstream", "Happy", 200, 120)
+	engine.AddSong("Song 3", "Beta band", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 4", "charlie", "Album", "Jazz", "Smooth", "Relaxed", 200, 120)
+
+	artists := engine.GetAllArtists()
+	expected := []string{"Alpha", "beta Band", "charlie"}
+	if len(artists) != len(expected) {
+		t.Fatalf("Expected %d artists, got %v", len(expected), artists)
+	}
+	for i, artist := range expected {
+		if artists[i] != artist {
+			t.Errorf("Expected artist %d to be %s, got %s", i, artist, artists[i])
+		}
+	}
+}
+
+func TestGetRecentlyAdded(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("First", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Second", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Third", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	songs := engine.currentPlaylist.ToSlice()
+	base := time.Now()
+	songs[0].AddedAt = base
+	songs[1].AddedAt = base.Add(time.Minute)
+	songs[2].AddedAt = base.Add(time.Minute)
+
+	recent := engine.GetRecentlyAdded(2)
+	if len(recent) != 2 {
+		t.Fatalf("Expected 2 songs, got %d", len(recent))
+	}
+	// Ties on AddedAt break by title
+	if recent[0].Title != "Second" || recent[1].Title != "Third" {
+		t.Errorf("Expected [Second Third], got [%s %s]", recent[0].Title, recent[1].Title)
+	}
+
+	// Playlist order is untouched
+	current := engine.GetCurrentPlaylist()
+	if current[0].Title != "First" || current[2].Title != "Third" {
+		t.Error("GetRecentlyAdded should not reorder the playlist")
+	}
+
+	if len(engine.GetRecentlyAdded(10)) != 3 {
+		t.Error("Expected all songs when n exceeds playlist size")
+	}
+	if len(engine.GetRecentlyAdded(0)) != 0 {
+		t.Error("Expected no songs for n <= 0")
+	}
+}
+
+func TestGetSongPositions(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	id1, _ := engine.AddSong("Song 1", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	id2, _ := engine.AddSong("Song 2", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	id3, _ := engine.AddSong("Song 3", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	engine.ReversePlaylist()
+	engine.DeleteSongByID(id2)
+
+	positions := engine.GetSongPositions()
+	if len(positions) != 2 || positions[id3] != 0 || positions[id1] != 1 {
+		t.Errorf("Unexpected positions after reverse and delete: %v", positions)
+	}
+}
+
+func TestGetSongsByRatings(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	id1, _ := engine.AddSong("Song 1", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	id2, _ := engine.AddSong("Song 2", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	id3, _ := engine.AddSong("Song 3", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 4", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.RateSong(id1, 3)
+	engine.RateSong(id2, 5)
+	engine.RateSong(id3, 1)
+
+	// Overlapping requests are deduplicated and ordered by descending rating
+	songs := engine.GetSongsByRatings([]int{3, 5, 3, 5})
+	if len(songs) != 2 {
+		t.Fatalf("Expected 2 songs, got %d", len(songs))
+	}
+	if songs[0].Rating != 5 || songs[1].Rating != 3 {
+		t.Errorf("Expected ratings [5 3], got [%d %d]", songs[0].Rating, songs[1].Rating)
+	}
+
+	// Invalid ratings are ignored
+	if songs := engine.GetSongsByRatings([]int{0, 6, -1}); len(songs) != 0 {
+		t.Errorf("Expected no songs for invalid ratings, got %d", len(songs))
+	}
+	if songs := engine.GetSongsByRatings([]int{1, 9}); len(songs) != 1 {
+		t.Errorf("Expected 1 song, got %d", len(songs))
+	}
+}
+
+func TestCachedStatsMatchRecomputation(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	rng := rand.New(rand.NewSource(42))
+
+	verify := func(step int) {
+		t.Helper()
+		artists := make(map[string]bool)
+		playCount := 0
+		for _, song := range engine.GetCurrentPlaylist() {
+			artists[song.Artist] = true
+			playCount += song.PlayCount
+		}
+
+		stats := engine.GetPlaylistStats()
+		if stats["unique_artists"] != len(artists) {
+			t.Fatalf("step %d: cached unique artists %v, recomputed %d", step, stats["unique_artists"], len(artists))
+		}
+		if stats["total_play_count"] != playCount {
+			t.Fatalf("step %d: cached play count %v, recomputed %d", step, stats["total_play_count"], playCount)
+		}
+	}
+
+	for step := 0; step < 500; step++ {
+		size := engine.GetPlaylistSize()
+		switch op := rng.Intn(10); {
+		case op < 4:
+			engine.AddSong(fmt.Sprintf("Song %d", step), fmt.Sprintf("Artist %d", rng.Intn(8)), "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		case op < 7 && size > 0:
+			engine.PlaySong(rng.Intn(size))
+		case op < 9 && size > 0:
+			engine.DeleteSong(rng.Intn(size))
+		case op == 9 && size > 0:
+			engine.DeleteSongByID(engine.GetCurrentPlaylist()[rng.Intn(size)].ID)
+		}
+		verify(step)
+	}
+
+	engine.ClearPlaylist()
+	verify(-1)
+}
+
+func TestGetSimilarSongs(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	id1, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 210, 125)
+	engine.AddSong("Song 3", "Artist 3", "Album", "Jazz", "Smooth", "Relaxed", 300, 90)
+
+	similar, err := engine.GetSimilarSongs(id1, 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(similar) != 1 || similar[0].Title != "Song 2" {
+		t.Errorf("Expected only Song 2 to be similar, got %v", similar)
+	}
+
+	if _, err := engine.GetSimilarSongs("missing", 5); err == nil {
+		t.Error("Expected error for unknown song")
+	}
+
+	path, err := engine.FindSongPath(id1)
+	if err != nil || len(path) != 4 || path[0] != "Rock" {
+		t.Errorf("Expected explorer path starting with Rock, got %v (%v)", path, err)
+	}
+}
+
+func TestSmartRecommendations_IndexFollowsChanges(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Seed", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Match", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 210, 120)
+	swingID, _ := engine.AddSong("Swing", "Artist 3", "Album", "Jazz", "Smooth", "Energetic", 200, 120)
+	engine.AddSong("Filler", "Artist 4", "Album", "Pop", "Mainstream", "Happy", 200, 120)
+	engine.PlaySong(0)
+
+	recs := engine.GetSmartRecommendations(1)
+	if len(recs) != 1 || recs[0].Title != "Match" {
+		t.Errorf("Expected the indexed match, got %v", recs)
+	}
+
+	// Renaming a genre re-files the songs in the similarity index
+	engine.RenameGenre("Jazz", "Rock")
+	engine.RenameSubgenre("Smooth", "Alternative")
+	engine.DeleteSong(1)
+	recs = engine.GetSmartRecommendations(1)
+	if len(recs) != 1 || recs[0].ID != swingID {
+		t.Errorf("Expected the renamed song to become the match, got %v", recs)
+	}
+
+	// Editing the song away leaves only the playlist fill
+	pop := "Pop"
+	if _, err := engine.UpdateSong(swingID, SongUpdate{Genre: &pop}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if similar, _ := engine.GetSimilarSongs(engine.GetCurrentPlaylist()[0].ID, 5); len(similar) != 0 {
+		t.Errorf("Expected no similar songs after the edit, got %v", similar)
+	}
+	recs = engine.GetSmartRecommendations(2)
+	if len(recs) != 2 || recs[0].Title != "Swing" || recs[1].Title != "Filler" {
+		t.Errorf("Expected the fill to follow playlist order, got %v", recs)
+	}
+
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes, got %v", issues)
+	}
+}
+
+func TestBenchmarkRecommendations(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	for i := 0; i < 50; i++ {
+		genre := []string{"Rock", "Jazz", "Pop"}[i%3]
+		engine.AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", genre, "Sub", "Chill", 200, 100)
+	}
+
+	// Without history the first songs stand in as seeds
+	result := engine.BenchmarkRecommendations(3)
+	if result.Songs != 50 || result.Seeds != DefaultRecommendationWindow || result.Iterations != 3 {
+		t.Errorf("Unexpected benchmark setup: %+v", result)
+	}
+	if result.Matches != 30 {
+		t.Errorf("Expected the 30 unseeded songs to match, got %d", result.Matches)
+	}
+	if result.Scan < 0 || result.Indexed < 0 {
+		t.Errorf("Benchmark times cannot be negative: %+v", result)
+	}
+
+	engine.PlaySong(0)
+	if result = engine.BenchmarkRecommendations(0); result.Seeds != 1 || result.Iterations != 1 || result.Matches != 16 {
+		t.Errorf("Expected one seed, one iteration and 16 Rock matches, got %+v", result)
+	}
+}
+
+func TestGetSmartRecommendationsWindow(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Pop Song", "Artist 1", "Album", "Pop", "Mainstream", "Happy", 200, 120)
+	engine.AddSong("Jazz Song", "Artist 2", "Album", "Jazz", "Smooth", "Relaxed", 300, 90)
+	engine.AddSong("Old Jazz Play", "Artist 3", "Album", "Jazz", "Smooth", "Relaxed", 300, 90)
+	engine.AddSong("Rock Song 1", "Artist 4", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+	engine.AddSong("Rock Song 2", "Artist 5", "Album", "Rock", "Alternative", "Energetic", 240, 120)
+
+	engine.PlaySong(2) // Older jazz play
+	engine.PlaySong(3)
+	engine.PlaySong(4)
+
+	// With a window of 2 only the rock plays count, so no similar songs exist and we fall back to playlist order
+	narrow := engine.GetSmartRecommendationsWindow(1, 2)
+	if len(narrow) != 1 || narrow[0].Title != "Pop Song" {
+		t.Errorf("Expected fallback to Pop Song with narrow window, got %v", narrow)
+	}
+
+	// A larger window reaches the older jazz play and recommends the similar jazz song
+	wide := engine.GetSmartRecommendationsWindow(1, 3)
+	if len(wide) != 1 || wide[0].Title != "Jazz Song" {
+		t.Errorf("Expected Jazz Song with wide window, got %v", wide)
+	}
+
+	// Non-positive windows use the default
+	if len(engine.GetSmartRecommendationsWindow(1, 0)) != 1 {
+		t.Error("Expected default window to still return recommendations")
+	}
+}
+
+func TestRecordPlays(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	id1, _ := engine.AddSong("Song 1", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	id2, _ := engine.AddSong("Song 2", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.PlaySong(0) // A live play that is newer than every imported one
+
+	base := time.Now().Add(-24 * time.Hour)
+	err := engine.RecordPlays([]PlayRecord{
+		{SongID: id2, PlayedAt: base.Add(2 * time.Hour)},
+		{SongID: "missing", PlayedAt: base},
+		{SongID: id1, PlayedAt: base},
+		{SongID: id2, PlayedAt: base.Add(time.Hour)},
+		{SongID: id1},
+	})
+	if err == nil {
+		t.Error("Expected errors for unknown ID and missing timestamp")
+	}
+
+	song1, _ := engine.SearchSongByID(id1)
+	song2, _ := engine.SearchSongByID(id2)
+	if song1.PlayCount != 2 || song2.PlayCount != 2 {
+		t.Errorf("Expected play counts 2 and 2, got %d and %d", song1.PlayCount, song2.PlayCount)
+	}
+	if engine.GetTotalPlayCount() != 4 {
+		t.Errorf("Expected total play count 4, got %d", engine.GetTotalPlayCount())
+	}
+
+	// Live play stays most recent; imported plays follow newest first
+	history := engine.GetRecentlyPlayedSongs(10)
+	expected := []string{id1, id2, id2, id1}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d history entries, got %d", len(expected), len(history))
+	}
+	for i, id := range expected {
+		if history[i].ID != id {
+			t.Errorf("History[%d] = %s, want %s", i, history[i].ID, id)
+		}
+	}
+
+	// Backfilled plays don't move LastPlayed backwards
+	if song1.LastPlayed == nil || song1.LastPlayed.Before(time.Now().Add(-time.Minute)) {
+		t.Error("Expected LastPlayed to keep the most recent live play")
+	}
+}
+
+func TestGetBPMHistogram(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Slow", "Artist 1", "Album", "Jazz", "Smooth Jazz", "Relaxed", 200, 65)
+	engine.AddSong("Slowish", "Artist 2", "Album", "Jazz", "Smooth Jazz", "Relaxed", 200, 79)
+	engine.AddSong("Medium", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 200, 80)
+	engine.AddSong("Fast", "Artist 4", "Album", "Electronic", "House", "Energetic", 200, 128)
+	engine.AddSong("No Tempo", "Artist 5", "Album", "Ambient", "Drone", "Calm", 200, 0)
+
+	histogram := engine.GetBPMHistogram(20)
+	expected := map[string]int{"60-80": 2, "80-100": 1, "120-140": 1, "unknown": 1}
+	if len(histogram) != len(expected) {
+		t.Errorf("Expected %d buckets, got %v", len(expected), histogram)
+	}
+	for label, count := range expected {
+		if histogram[label] != count {
+			t.Errorf("Expected bucket %s to have %d songs, got %d", label, count, histogram[label])
+		}
+	}
+
+	if len(engine.GetBPMHistogram(0)) != 0 {
+		t.Error("Expected empty histogram for non-positive bucket size")
+	}
+}
+
+func TestGetDurationHistogram(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Interlude", "Artist 1", "Album", "Jazz", "Smooth Jazz", "Relaxed", 45, 90)
+	engine.AddSong("Single", "Artist 2", "Album", "Pop", "Dance", "Happy", 200, 120)
+	engine.AddSong("Single 2", "Artist 3", "Album", "Pop", "Dance", "Happy", 239, 120)
+	engine.AddSong("Epic", "Artist 4", "Album", "Rock", "Progressive", "Epic", 600, 100)
+	engine.AddSong("Empty", "Artist 5", "Album", "Ambient", "Drone", "Calm", 0, 0)
+
+	histogram := engine.GetDurationHistogram(60)
+	expected := map[string]int{"0-60": 1, "180-240": 2, "600-660": 1, "unknown": 1}
+	if len(histogram) != len(expected) {
+		t.Errorf("Expected %d buckets, got %v", len(expected), histogram)
+	}
+	for label, count := range expected {
+		if histogram[label] != count {
+			t.Errorf("Expected bucket %s to have %d songs, got %d", label, count, histogram[label])
+		}
+	}
+
+	labels := SortHistogramLabels(histogram)
+	wantLabels := []string{"0-60", "180-240", "600-660", "unknown"}
+	if strings.Join(labels, ",") != strings.Join(wantLabels, ",") {
+		t.Errorf("Expected labels %v, got %v", wantLabels, labels)
+	}
+
+	if len(engine.GetDurationHistogram(-60)) != 0 {
+		t.Error("Expected empty histogram for non-positive bucket size")
+	}
+}
+
+func TestTagSongsWhere(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	jazz1, _ := engine.AddSong("Jazz 1", "Artist 1", "Album", "Jazz", "Smooth Jazz", "Relaxed", 200, 90)
+	jazz2, _ := engine.AddSong("Jazz 2", "Artist 2", "Album", "Jazz", "Bebop", "Energetic", 200, 160)
+	rock, _ := engine.AddSong("Rock 1", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.RateSong(jazz1, 5)
+	engine.RateSong(jazz2, 3)
+	engine.RateSong(rock, 5)
+
+	fiveStarJazz := func(song *models.Song) bool {
+		return song.Genre == "Jazz" && song.Rating == 5
+	}
+	if tagged := engine.TagSongsWhere(fiveStarJazz, " Favorites-Jazz "); tagged != 1 {
+		t.Errorf("Expected 1 song tagged, got %d", tagged)
+	}
+
+	// Tagging again is deduplicated per song
+	if tagged := engine.TagSongsWhere(fiveStarJazz, "favorites-jazz"); tagged != 0 {
+		t.Errorf("Expected re-tagging to be a no-op, got %d", tagged)
+	}
+
+	byGenre := func(song *models.Song) bool { return song.Genre == "Jazz" }
+	if tagged := engine.TagSongsWhere(byGenre, "jazz"); tagged != 2 {
+		t.Errorf("Expected 2 jazz songs tagged, got %d", tagged)
+	}
+
+	favorites := engine.GetSongsByTag("FAVORITES-JAZZ")
+	if len(favorites) != 1 || favorites[0].ID != jazz1 {
+		t.Errorf("Expected only %s tagged favorites-jazz, got %v", jazz1, favorites)
+	}
+	if songs := engine.GetSongsByTag("jazz"); len(songs) != 2 {
+		t.Errorf("Expected 2 songs tagged jazz, got %d", len(songs))
+	}
+
+	song, _ := engine.SearchSongByID(jazz1)
+	if len(song.Tags) != 2 || song.Tags[0] != "favorites-jazz" || song.Tags[1] != "jazz" {
+		t.Errorf("Expected normalized, deduplicated tags, got %v", song.Tags)
+	}
+
+	if tagged := engine.TagSongsWhere(byGenre, "   "); tagged != 0 {
+		t.Errorf("Expected empty tag to tag nothing, got %d", tagged)
+	}
+}
+
+func TestGetSkippedSongs(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Skippy", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Loved", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Barely Heard", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Untouched", "Artist 4", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Skippier", "Artist 5", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	// Skippy: 1 play, 3 skips
+	engine.PlaySong(0)
+	for i := 0; i < 3; i++ {
+		engine.SkipSong(0)
+	}
+	// Loved: 3 plays, 1 skip
+	for i := 0; i < 3; i++ {
+		engine.PlaySong(1)
+	}
+	engine.SkipSong(1)
+	// Barely Heard: 2 skips, below the interaction minimum
+	engine.SkipSong(2)
+	engine.SkipSong(2)
+	// Skippier: 4 skips
+	for i := 0; i < 4; i++ {
+		engine.SkipSong(4)
+	}
+
+	skipped := engine.GetSkippedSongs(0.5)
+	if len(skipped) != 2 {
+		t.Fatalf("Expected 2 skippy songs, got %d", len(skipped))
+	}
+	if skipped[0].Title != "Skippier" || skipped[1].Title != "Skippy" {
+		t.Errorf("Expected lowest ratio first (Skippier, Skippy), got %s, %s", skipped[0].Title, skipped[1].Title)
+	}
+
+	if _, err := engine.SkipSong(10); err == nil {
+		t.Error("Expected error skipping an out-of-range index")
+	}
+	if plays := engine.GetPlaylistStats()["total_play_count"]; plays != 4 {
+		t.Errorf("Skips should not count as plays, got %v", plays)
+	}
+	if engine.GetHistorySize() != 4 {
+		t.Errorf("Skips should not enter playback history, got %d entries", engine.GetHistorySize())
+	}
+}
+
+func TestMoveToTopAndBottom(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	var ids []string
+	for i := 0; i < 4; i++ {
+		id, _ := engine.AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		ids = append(ids, id)
+	}
+
+	if err := engine.MoveToTop(ids[2]); err != nil {
+		t.Fatalf("MoveToTop() error = %v", err)
+	}
+	if err := engine.MoveToBottom(ids[0]); err != nil {
+		t.Fatalf("MoveToBottom() error = %v", err)
+	}
+
+	want := []string{ids[2], ids[1], ids[3], ids[0]}
+	playlist := engine.GetCurrentPlaylist()
+	for i, song := range playlist {
+		if song.ID != want[i] {
+			t.Errorf("Position %d: expected %s, got %s", i, want[i], song.ID)
+		}
+	}
+
+	// Indexes stay in sync after the moves
+	positions := engine.GetSongPositions()
+	for i, id := range want {
+		if positions[id] != i {
+			t.Errorf("GetSongPositions()[%s] = %d, want %d", id, positions[id], i)
+		}
+	}
+	if song, err := engine.SearchSongByID(ids[0]); err != nil || song.ID != ids[0] {
+		t.Errorf("Moved song should still be searchable, got %v, %v", song, err)
+	}
+
+	if err := engine.MoveToTop("missing"); err == nil {
+		t.Error("Expected error moving unknown song to top")
+	}
+	if err := engine.MoveToBottom("missing"); err == nil {
+		t.Error("Expected error moving unknown song to bottom")
+	}
+}
+
+func TestInterleaveGenres(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Rock 1", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Pop 1", "Artist", "Album", "Pop", "Dance", "Happy", 200, 120)
+	engine.AddSong("Rock 2", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Jazz 1", "Artist", "Album", "Jazz", "Smooth Jazz", "Relaxed", 200, 90)
+	engine.AddSong("Rock 3", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Rock 4", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	titles := func(songs []*models.Song) string {
+		names := make([]string, 0, len(songs))
+		for _, song := range songs {
+			names = append(names, song.Title)
+		}
+		return strings.Join(names, ",")
+	}
+
+	// Uneven sizes: the remainder of the larger genre is appended
+	if got, want := titles(engine.InterleaveGenres("Rock", "pop")), "Rock 1,Pop 1,Rock 2,Rock 3,Rock 4"; got != want {
+		t.Errorf("InterleaveGenres(Rock, pop) = %s, want %s", got, want)
+	}
+	if got, want := titles(engine.InterleaveGenres("Pop", "Rock")), "Pop 1,Rock 1,Rock 2,Rock 3,Rock 4"; got != want {
+		t.Errorf("InterleaveGenres(Pop, Rock) = %s, want %s", got, want)
+	}
+	if got, want := titles(engine.InterleaveGenres("Jazz", "Rock")), "Jazz 1,Rock 1,Rock 2,Rock 3,Rock 4"; got != want {
+		t.Errorf("InterleaveGenres(Jazz, Rock) = %s, want %s", got, want)
+	}
+	if got := engine.InterleaveGenres("Metal", "Country"); len(got) != 0 {
+		t.Errorf("Expected no songs for unknown genres, got %s", titles(got))
+	}
+	if got := engine.InterleaveGenres("Rock", "Rock"); len(got) != 4 {
+		t.Errorf("Expected the same genre twice to return its 4 songs once, got %d", len(got))
+	}
+
+	// The playlist order is unchanged
+	if got, want := titles(engine.GetCurrentPlaylist()), "Rock 1,Pop 1,Rock 2,Jazz 1,Rock 3,Rock 4"; got != want {
+		t.Errorf("Playlist should not be mutated, got %s", got)
+	}
+}
+
+func TestExtendQueueSmart(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	var ids []string
+	for i := 0; i < 6; i++ {
+		id, _ := engine.AddSong(fmt.Sprintf("Rock %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		ids = append(ids, id)
+	}
+	engine.PlaySong(0)
+	engine.EnqueueSong(ids[1])
+	engine.EnqueueSong(ids[2])
+
+	added := engine.ExtendQueueSmart(2)
+	if len(added) != 2 {
+		t.Fatalf("Expected 2 songs added, got %d", len(added))
+	}
+	for _, song := range added {
+		if song.ID == ids[0] {
+			t.Error("Recently played song should not be added to the queue")
+		}
+		if song.ID == ids[1] || song.ID == ids[2] {
+			t.Errorf("Already queued song %s should not be re-added", song.ID)
+		}
+	}
+
+	queue := engine.GetQueue()
+	if len(queue) != 4 {
+		t.Fatalf("Expected queue of 4 songs, got %d", len(queue))
+	}
+	seen := make(map[string]bool)
+	for _, song := range queue {
+		if seen[song.ID] {
+			t.Errorf("Song %s appears twice in the queue", song.ID)
+		}
+		seen[song.ID] = true
+	}
+
+	// Only ids[5] is left to recommend
+	if added := engine.ExtendQueueSmart(3); len(added) != 1 || added[0].ID != ids[5] {
+		t.Errorf("Expected only %s to be added, got %v", ids[5], added)
+	}
+	if added := engine.ExtendQueueSmart(3); len(added) != 0 {
+		t.Errorf("Expected nothing left to add, got %d songs", len(added))
+	}
+
+	// Deleting a song drops it from the queue
+	engine.DeleteSongByID(ids[1])
+	for _, song := range engine.GetQueue() {
+		if song.ID == ids[1] {
+			t.Error("Deleted song should be removed from the queue")
+		}
+	}
+
+	if err := engine.EnqueueSong("missing"); err == nil {
+		t.Error("Expected error enqueueing unknown song")
+	}
+
+	engine.ClearQueue()
+	if len(engine.GetQueue()) != 0 {
+		t.Error("Expected empty queue after ClearQueue")
+	}
+}
+
+func TestFuzzySearchByTitle(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	engine.AddSong("Bohemian Rhapsody", "Queen", "Album", "Rock", "Classic Rock", "Epic", 355, 72)
+	engine.AddSong("Hotel California", "Eagles", "Album", "Rock", "Classic Rock", "Nostalgic", 391, 75)
+	engine.AddSong("Imagine", "John Lennon", "Album", "Pop", "Soft Rock", "Peaceful", 183, 76)
+
+	// Substring match, case-insensitive
+	if songs := engine.FuzzySearchByTitle("  RHAPSODY ", 0); len(songs) != 1 || songs[0].Title != "Bohemian Rhapsody" {
+		t.Errorf("Expected Bohemian Rhapsody, got %v", songs)
+	}
+
+	// Typo within edit distance
+	if songs := engine.FuzzySearchByTitle("imagin", 0); len(songs) != 1 || songs[0].Title != "Imagine" {
+		t.Errorf("Expected Imagine for a substring, got %v", songs)
+	}
+	if songs := engine.FuzzySearchByTitle("imgaine", 0); len(songs) != 1 || songs[0].Title != "Imagine" {
+		t.Errorf("Expected Imagine for a typo, got %v", songs)
+	}
+
+	if songs := engine.FuzzySearchByTitle("o", 1); len(songs) != 1 {
+		t.Errorf("Expected limit to cap results at 1, got %d", len(songs))
+	}
+	if songs := engine.FuzzySearchByTitle("", 0); len(songs) != 0 {
+		t.Errorf("Expected no results for an empty query, got %v", songs)
+	}
+}
+
+func TestFuzzySearchByTitle_Cache(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	engine.AddSong("Hotel California", "Eagles", "Album", "Rock", "Classic Rock", "Nostalgic", 391, 75)
+
+	engine.FuzzySearchByTitle("hotel", 0)
+	engine.FuzzySearchByTitle("HOTEL", 0) // Same normalized query
+	if hits, misses := engine.searchCache.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %d, %d", hits, misses)
+	}
+
+	// Adding a song invalidates cached results
+	engine.AddSong("Hotel Yorba", "The White Stripes", "Album", "Rock", "Garage Rock", "Upbeat", 130, 120)
+	if songs := engine.FuzzySearchByTitle("hotel", 0); len(songs) != 2 {
+		t.Errorf("Expected 2 results after AddSong, got %d", len(songs))
+	}
+	if _, misses := engine.searchCache.Stats(); misses != 2 {
+		t.Errorf("Expected a cache miss after AddSong, got %d misses", misses)
+	}
+
+	// So does deleting one
+	engine.DeleteSong(0)
+	if songs := engine.FuzzySearchByTitle("hotel", 0); len(songs) != 1 || songs[0].Title != "Hotel Yorba" {
+		t.Errorf("Expected only Hotel Yorba after delete, got %v", songs)
+	}
+
+	// Callers can't corrupt the cached slice
+	songs := engine.FuzzySearchByTitle("hotel", 0)
+	songs[0] = nil
+	if again := engine.FuzzySearchByTitle("hotel", 0); again[0] == nil {
+		t.Error("Cached results should not be shared with callers")
+	}
+}
+
+func TestGetTopSongs(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	shortID, _ := engine.AddSong("Short", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 120, 120)
+	longID, _ := engine.AddSong("Long", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 400, 120)
+	engine.AddSong("Medium", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 250, 120)
+
+	longest := engine.GetLongestSongs(2)
+	if len(longest) != 2 || longest[0].Title != "Long" || longest[1].Title != "Medium" {
+		t.Errorf("Expected Long, Medium, got %v", longest)
+	}
+
+	engine.PlaySong(0)
+	engine.PlaySong(0)
+	engine.PlaySong(2)
+	if mostPlayed := engine.GetMostPlayedSongs(1); len(mostPlayed) != 1 || mostPlayed[0].Title != "Short" {
+		t.Errorf("Expected Short as most played, got %v", mostPlayed)
+	}
+
+	// Equal ratings fall back to play count, unrated songs are left out
+	engine.RateSong(shortID, 4)
+	engine.RateSong(longID, 4)
+	topRated := engine.GetTopRatedSongs(5)
+	if len(topRated) != 2 || topRated[0].Title != "Short" || topRated[1].Title != "Long" {
+		t.Errorf("Expected Short, Long, got %v", topRated)
+	}
+}
+
+func TestGetPlaybackStatsRange(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	songID, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	thisWeek := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	lastWeek := thisWeek.Add(-7 * 24 * time.Hour)
+	engine.RecordPlays([]PlayRecord{
+		{SongID: songID, PlayedAt: lastWeek.Add(time.Hour)},
+		{SongID: songID, PlayedAt: thisWeek.Add(time.Hour)},
+		{SongID: songID, PlayedAt: thisWeek.Add(2 * time.Hour)},
+	})
+
+	current := engine.GetPlaybackStatsRange(thisWeek, thisWeek.Add(7*24*time.Hour))
+	previous := engine.GetPlaybackStatsRange(lastWeek, thisWeek.Add(-time.Nanosecond))
+
+	if current["total_songs"] != 2 || current["total_duration"] != 400 {
+		t.Errorf("Expected 2 plays this week, got %v", current)
+	}
+	if previous["total_songs"] != 1 {
+		t.Errorf("Expected 1 play last week, got %v", previous)
+	}
+	if current["from"] != thisWeek {
+		t.Errorf("Expected the range in the stats, got from=%v", current["from"])
+	}
+}
+
+func TestMaxSongs(t *testing.T) {
+	config := DefaultEngineConfig()
+	config.MaxSongs = 2
+	engine := NewPlaylistEngineWithConfig("Test", config)
+
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	if _, err := engine.AddSong("Song 3", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 200, 120); !errors.Is(err, ErrPlaylistFull) {
+		t.Errorf("Expected ErrPlaylistFull, got %v", err)
+	}
+	if engine.GetPlaylistSize() != 2 {
+		t.Errorf("Expected playlist to stay at 2 songs, got %d", engine.GetPlaylistSize())
+	}
+
+	// Deleting frees a slot
+	engine.DeleteSong(0)
+	if _, err := engine.AddSong("Song 3", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 200, 120); err != nil {
+		t.Errorf("Expected add to succeed after delete, got %v", err)
+	}
+}
+
+func TestPruneHistoryOfDeleted(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	engine.PlaySong(0)
+	engine.PlaySong(1)
+	engine.DeleteSong(0)
+
+	// Without the config option the deleted song stays in history until pruned
+	if engine.GetHistorySize() != 2 {
+		t.Fatalf("Expected 2 history entries before pruning, got %d", engine.GetHistorySize())
+	}
+	if removed := engine.PruneHistoryOfDeleted(); removed != 1 {
+		t.Errorf("Expected 1 entry pruned, got %d", removed)
+	}
+	recent := engine.GetRecentlyPlayedSongs(10)
+	if len(recent) != 1 || recent[0].Title != "Song 2" {
+		t.Errorf("Expected only Song 2 in recent history, got %v", recent)
+	}
+}
+
+func TestPruneHistoryOnDelete(t *testing.T) {
+	config := DefaultEngineConfig()
+	config.PruneHistoryOnDelete = true
+	engine := NewPlaylistEngineWithConfig("Test", config)
+	songID, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	engine.PlaySong(0)
+	engine.DeleteSongByID(songID)
+
+	if engine.GetHistorySize() != 0 {
+		t.Errorf("Expected history pruned on delete, got %d entries", engine.GetHistorySize())
+	}
+}
+
+func TestGetSongStats(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	songID, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	engine.PlaySong(0)
+	engine.PlaySong(0)
+	engine.PlaySong(0)
+	engine.SkipSong(0)
+	engine.RateSong(songID, 4)
+
+	stats, err := engine.GetSongStats(songID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stats.PlayCount != 3 || stats.SkipCount != 1 || stats.Rating != 4 || stats.PlayRatio != 0.75 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if stats.LastPlayed == nil || stats.AddedAt.IsZero() {
+		t.Errorf("Expected added and last played times, got %+v", stats)
+	}
+
+	if _, err := engine.GetSongStats("missing"); err == nil {
+		t.Error("Expected error for unknown song ID")
+	}
+}
+
+func TestArtistIndexMatchesScan(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	rng := rand.New(rand.NewSource(7))
+	spellings := []string{"Artist %d", "artist %d", " ARTIST %d "}
+
+	verify := func(step int) {
+		t.Helper()
+		scanned := make(map[string][]string)
+		for _, song := range engine.GetCurrentPlaylist() {
+			key := normalizeArtist(song.Artist)
+			scanned[key] = append(scanned[key], song.ID)
+		}
+
+		if len(engine.artistIndex) != len(scanned) || engine.getUniqueArtistCount() != len(scanned) {
+			t.Fatalf("step %d: index has %d artists, scan found %d", step, len(engine.artistIndex), len(scanned))
+		}
+		for key, ids := range scanned {
+			indexed := make(map[string]bool)
+			for _, song := range engine.SearchSongsByArtist(key) {
+				indexed[song.ID] = true
+			}
+			if len(indexed) != len(ids) {
+				t.Fatalf("step %d: artist %q has %d indexed songs, scan found %d", step, key, len(indexed), len(ids))
+			}
+			for _, id := range ids {
+				if !indexed[id] {
+					t.Fatalf("step %d: song %s missing from artist %q", step, id, key)
+				}
+			}
+		}
+	}
+
+	for step := 0; step < 500; step++ {
+		size := engine.GetPlaylistSize()
+		switch op := rng.Intn(10); {
+		case op < 5:
+			artist := fmt.Sprintf(spellings[rng.Intn(len(spellings))], rng.Intn(6))
+			engine.AddSong(fmt.Sprintf("Song %d", step), artist, "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		case op < 8 && size > 0:
+			engine.DeleteSong(rng.Intn(size))
+		case op < 9 && size > 0:
+			engine.DeleteSongByID(engine.GetCurrentPlaylist()[rng.Intn(size)].ID)
+		case op == 9 && size > 1:
+			engine.MoveSong(rng.Intn(size), rng.Intn(size))
+		}
+		verify(step)
+	}
+
+	engine.ClearPlaylist()
+	verify(-1)
+}
+
+func TestSearchSongsByArtist(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	engine.AddSong("Song 1", "Queen", "Album", "Rock", "Classic Rock", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Eagles", "Album", "Rock", "Classic Rock", "Nostalgic", 200, 120)
+	engine.AddSong("Song 3", "queen", "Album", "Rock", "Classic Rock", "Energetic", 200, 120)
+
+	songs := engine.SearchSongsByArtist(" QUEEN ")
+	if len(songs) != 2 || songs[0].Title != "Song 1" || songs[1].Title != "Song 3" {
+		t.Errorf("Expected Song 1 and Song 3 in add order, got %v", songs)
+	}
+
+	// The result is a copy that can't corrupt the index
+	songs[0] = nil
+	if engine.SearchSongsByArtist("queen")[0] == nil {
+		t.Error("Modifying the result should not affect the index")
+	}
+	if songs := engine.SearchSongsByArtist("Unknown"); len(songs) != 0 {
+		t.Errorf("Expected no songs for unknown artist, got %v", songs)
+	}
+}
+
+func TestGetSmartRecommendationsWithOptions_DecayPlays(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	oldID, _ := engine.AddSong("Old Favourite", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	recentID, _ := engine.AddSong("Recent Pick", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Seed", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	lastYear := time.Now().Add(-365 * 24 * time.Hour)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	var plays []PlayRecord
+	for i := 0; i < 10; i++ {
+		plays = append(plays, PlayRecord{SongID: oldID, PlayedAt: lastYear.Add(time.Duration(i) * time.Minute)})
+	}
+	plays = append(plays,
+		PlayRecord{SongID: recentID, PlayedAt: yesterday},
+		PlayRecord{SongID: recentID, PlayedAt: yesterday.Add(time.Minute)},
+	)
+	engine.RecordPlays(plays)
+	engine.PlaySong(2) // Only the seed is inside a window of 1
+
+	plain := engine.GetSmartRecommendationsWithOptions(RecOptions{Count: 1, HistoryWindow: 1})
+	if len(plain) != 1 || plain[0].Title != "Old Favourite" {
+		t.Errorf("Expected playlist order without decay, got %v", plain)
+	}
+
+	decayed := engine.GetSmartRecommendationsWithOptions(RecOptions{Count: 1, HistoryWindow: 1, DecayPlays: true})
+	if len(decayed) != 1 || decayed[0].Title != "Recent Pick" {
+		t.Errorf("Expected the recently played song first under decay, got %v", decayed)
+	}
+
+	// Decay ranking never reorders the playlist itself
+	if engine.GetCurrentPlaylist()[0].Title != "Old Favourite" {
+		t.Error("Ranking should not modify the playlist order")
+	}
+}
+
+func TestRenameGenre(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	engine.AddSong("Song 1", "Artist 1", "Album", "Hiphop", "Trap", "Energetic", 200, 140)
+	engine.AddSong("Song 2", "Artist 2", "Album", "hiphop", "Boom Bap", "Chill", 200, 90)
+	engine.AddSong("Song 3", "Artist 3", "Album", "Hip Hop", "Trap", "Energetic", 200, 140)
+	engine.AddSong("Song 4", "Artist 4", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	if renamed := engine.RenameGenre("HIPHOP", "Hip Hop"); renamed != 2 {
+		t.Errorf("Expected 2 songs renamed, got %d", renamed)
+	}
+
+	genres := engine.GetGenres()
+	sort.Strings(genres)
+	if len(genres) != 2 || genres[0] != "Hip Hop" || genres[1] != "Rock" {
+		t.Errorf("Expected Hip Hop and Rock, got %v", genres)
+	}
+
+	songs := engine.GetPlaylistByExplorer("Hip Hop", "Trap", "Energetic", "Artist 1")
+	if len(songs) != 1 || songs[0].Genre != "Hip Hop" {
+		t.Errorf("Expected Song 1 moved under Hip Hop, got %v", songs)
+	}
+	if subgenres := engine.GetSubgenres("Hip Hop"); len(subgenres) != 2 {
+		t.Errorf("Expected Trap and Boom Bap under Hip Hop, got %v", subgenres)
+	}
+
+	// Subgenre and mood variants, and renaming a missing category
+	if renamed := engine.RenameSubgenre("trap", "Southern Trap"); renamed != 2 {
+		t.Errorf("Expected 2 subgenre renames, got %d", renamed)
+	}
+	if renamed := engine.RenameMood("chill", "Relaxed"); renamed != 1 {
+		t.Errorf("Expected 1 mood rename, got %d", renamed)
+	}
+	if renamed := engine.RenameGenre("Polka", "Folk"); renamed != 0 {
+		t.Errorf("Expected no songs renamed, got %d", renamed)
+	}
+}
+
+func TestSongArtwork(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	if _, err := engine.AddSongWithLinks("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120, "cover.jpg", ""); err == nil {
+		t.Error("Expected an error for a relative artwork URL")
+	}
+	if engine.GetPlaylistSize() != 0 {
+		t.Error("A song with an invalid URL should not be added")
+	}
+
+	songID, err := engine.AddSongWithLinks("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120, "https://example.com/cover.jpg", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := engine.SetSongArtwork(songID, "https://example.com/new.jpg", "https://example.com/track"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	song, _ := engine.SearchSongByID(songID)
+	if song.ArtworkURL != "https://example.com/new.jpg" || song.SourceURL != "https://example.com/track" {
+		t.Errorf("Expected updated links, got %q and %q", song.ArtworkURL, song.SourceURL)
+	}
+
+	if err := engine.SetSongArtwork(songID, "ftp://example.com/new.jpg", ""); err == nil {
+		t.Error("Expected an error for a non-http artwork URL")
+	}
+	if err := engine.SetSongArtwork("missing", "", ""); err == nil {
+		t.Error("Expected an error for an unknown song")
+	}
+}
+
+func TestMoveBlock(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	for i := 0; i < 5; i++ {
+		engine.AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	}
+
+	if err := engine.MoveBlock(0, 3, 5); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := []string{"Song 3", "Song 4", "Song 0", "Song 1", "Song 2"}
+	for i, song := range engine.GetCurrentPlaylist() {
+		if song.Title != want[i] {
+			t.Errorf("Position %d = %s, want %s", i, song.Title, want[i])
+		}
+	}
+
+	// Positions looked up by ID follow the move
+	if index, _ := engine.currentPlaylist.FindSongByID(engine.GetCurrentPlaylist()[2].ID); index != 2 {
+		t.Errorf("Expected moved song at index 2, got %d", index)
+	}
+	if err := engine.MoveBlock(0, 2, 1); err == nil {
+		t.Error("Expected an error for a target inside the block")
+	}
+}
+
+func TestGetSongsWithSameMood(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	seedID, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Chill", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album", "Jazz", "Smooth", "chill ", 200, 90)
+	engine.AddSong("Song 3", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 200, 140)
+
+	songs, err := engine.GetSongsWithSameMood(seedID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(songs) != 1 || songs[0].Title != "Song 2" {
+		t.Errorf("Expected only Song 2 across genres, got %v", songs)
+	}
+
+	if _, err := engine.GetSongsWithSameMood("missing"); err == nil {
+		t.Error("Expected error for unknown song ID")
+	}
+
+	// A song alone in its mood has no matches
+	loneID, _ := engine.AddSong("Song 4", "Artist 4", "Album", "Pop", "Dance", "Dreamy", 200, 110)
+	if songs, _ := engine.GetSongsWithSameMood(loneID); len(songs) != 0 {
+		t.Errorf("Expected no matches, got %v", songs)
+	}
+}
+
+func TestGetDistinctValues(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	engine.AddSong("Song 1", "Artist 1", "Album A", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 1", "Album A", "Rock", "Classic Rock", "Happy", 200, 120)
+	engine.AddSong("Song 3", "Artist 2", "", "Jazz", "Smooth Jazz", "Energetic", 200, 90)
+
+	tests := []struct {
+		field string
+		want  map[string]int
+	}{
+		{"genre", map[string]int{"Rock": 2, "Jazz": 1}},
+		{"subgenre", map[string]int{"Alternative": 1, "Classic Rock": 1, "Smooth Jazz": 1}},
+		{"mood", map[string]int{"Energetic": 2, "Happy": 1}},
+		{"artist", map[string]int{"Artist 1": 2, "Artist 2": 1}},
+		{"album", map[string]int{"Album A": 2}},
+		{"GENRE", map[string]int{"Rock": 2, "Jazz": 1}},
+	}
+
+	for _, tt := range tests {
+		got, err := engine.GetDistinctValues(tt.field)
+		if err != nil {
+			t.Errorf("GetDistinctValues(%q) returned error %v", tt.field, err)
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("GetDistinctValues(%q) = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+
+	if _, err := engine.GetDistinctValues("year"); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+
+	engine.AddSong("Song 4", "artist 1", "", "rock ", "Grunge", "energetic", 200, 100)
+	got, err := engine.GetDistinctValues("genre")
+	if err != nil {
+		t.Fatalf("GetDistinctValues(genre) returned error %v", err)
+	}
+	if want := map[string]int{"Rock": 3, "Jazz": 1}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("GetDistinctValues(genre) = %v, want %v after adding a differently cased genre", got, want)
+	}
+	got, _ = engine.GetDistinctValues("artist")
+	if want := map[string]int{"Artist 1": 3, "Artist 2": 1}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("GetDistinctValues(artist) = %v, want %v after adding a differently cased artist", got, want)
+	}
+}
+
+// TestConcurrentEngineAccess mixes readers and writers on one engine; run with -race to check locking
+func TestConcurrentEngineAccess(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	for i := 0; i < 20; i++ {
+		engine.AddSong(fmt.Sprintf("Song %d", i), fmt.Sprintf("Artist %d", i%5), "Album", "Rock", "Alternative", "Energetic", 180+i, 100+i)
+	}
+
+	const workers = 8
+	const iterations = 50
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				switch (w + i) % 8 {
+				case 0:
+					engine.AddSong(fmt.Sprintf("Extra %d-%d", w, i), "Artist X", "Album", "Jazz", "Smooth Jazz", "Calm", 200, 90)
+				case 1:
+					engine.PlaySong(i % 10)
+				case 2:
+					engine.SortPlaylist(datastructures.SortByTitle, "merge")
+				case 3:
+					// Results are encoded after the lock is released, as the handlers do
+					json.Marshal(engine.GetCurrentPlaylist())
+					json.Marshal(engine.GetSongsByRating(i % 6))
+				case 4:
+					engine.FuzzySearchByTitle("song", 5)
+					engine.Search("artist")
+				case 5:
+					if songs := engine.GetCurrentPlaylist(); len(songs) > 0 {
+						engine.RateSong(songs[i%len(songs)].ID, i%5+1)
+					}
+				case 6:
+					json.Marshal(engine.ExportSnapshotAsync())
+					engine.GetSmartRecommendations(3)
+				case 7:
+					engine.MoveSong(0, 1)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes after concurrent access, got %v", issues)
+	}
+}
+
+func TestReadsReturnCopies(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	id, _ := engine.AddSong("Song", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	song, _ := engine.SearchSongByID(id)
+	playlist := engine.GetCurrentPlaylist()
+	engine.PlaySong(0)
+	engine.RateSong(id, 5)
+
+	if song.PlayCount != 0 || song.Rating != 0 || playlist[0].PlayCount != 0 {
+		t.Errorf("Expected earlier results to be unaffected by later writes, got %+v", song)
+	}
+	song.Title = "Changed"
+	if current, _ := engine.SearchSongByID(id); current.Title != "Song" || current.PlayCount != 1 || current.Rating != 5 {
+		t.Errorf("Expected the engine's song to be unaffected by changes to a result, got %+v", current)
+	}
+}
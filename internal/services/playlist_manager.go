@@ -0,0 +1,212 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrPlaylistNotFound is returned when no playlist has the requested ID
+var ErrPlaylistNotFound = errors.New("playlist not found")
+
+// ErrLastPlaylist is returned when deleting the only remaining playlist
+var ErrLastPlaylist = errors.New("cannot delete the last playlist")
+
+// PlaylistSummary describes one managed playlist for listings
+type PlaylistSummary struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	Active bool   `json:"active"`
+}
+
+// PlaylistManager owns several named playlists, each backed by its own PlaylistEngine
+// One playlist is always active; it serves requests that don't name a playlist
+// Playlist IDs are slugs of the name at creation time and stay fixed across renames
+// Time Complexity: O(1) average for lookups, O(p) for listing p playlists
+// Space Complexity: O(p + n) where n is the total number of songs
+type PlaylistManager struct {
+	mu        sync.RWMutex
+	config    EngineConfig
+	playlists map[string]*PlaylistEngine
+	order     []string // IDs in creation order
+	activeID  string
+}
+
+// NewPlaylistManager creates a manager with one active playlist of the given name
+// Every playlist the manager creates uses config
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewPlaylistManager(defaultName string, config EngineConfig) *PlaylistManager {
+	pm := &PlaylistManager{
+		config:    config,
+		playlists: make(map[string]*PlaylistEngine),
+	}
+
+	id, _ := pm.Create(defaultName)
+	pm.activeID = id
+	return pm
+}
+
+// Create adds an empty playlist and returns its ID
+// The ID is the slugified name, numbered when another playlist already uses it
+// Time Complexity: O(k) where k is the name length, plus O(c) for c playlists sharing the slug
+// Space Complexity: O(1)
+func (pm *PlaylistManager) Create(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("playlist name cannot be empty")
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	id := pm.newID(name)
+	pm.playlists[id] = NewPlaylistEngineWithConfig(name, pm.config)
+	pm.order = append(pm.order, id)
+	return id, nil
+}
+
+// newID slugifies name into an unused playlist ID for callers already holding pm.mu
+// Time Complexity: O(k) where k is the name length, plus O(c) for c playlists sharing the slug
+// Space Complexity: O(k)
+func (pm *PlaylistManager) newID(name string) string {
+	return uniqueSlug(name, "playlist", func(id string) bool { return pm.playlists[id] != nil })
+}
+
+// Get returns the engine of the playlist with the given ID
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pm *PlaylistManager) Get(id string) (*PlaylistEngine, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	engine, ok := pm.playlists[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPlaylistNotFound, id)
+	}
+	return engine, nil
+}
+
+// Active returns the engine of the active playlist
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pm *PlaylistManager) Active() *PlaylistEngine {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.playlists[pm.activeID]
+}
+
+// ActiveID returns the ID of the active playlist
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pm *PlaylistManager) ActiveID() string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.activeID
+}
+
+// Switch makes the playlist with the given ID the active one
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pm *PlaylistManager) Switch(id string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if _, ok := pm.playlists[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrPlaylistNotFound, id)
+	}
+	pm.activeID = id
+	return nil
+}
+
+// Delete removes the playlist with the given ID and all of its songs
+// Deleting the active playlist activates the oldest remaining one; the last playlist can't be deleted
+// Time Complexity: O(p) where p is the number of playlists
+// Space Complexity: O(1)
+func (pm *PlaylistManager) Delete(id string) error {
+	_, err := pm.Detach(id)
+	return err
+}
+
+// Detach removes the playlist with the given ID and returns its engine, songs intact, for Attach
+// Detaching the active playlist activates the oldest remaining one; the last playlist can't be detached
+// Time Complexity: O(p) where p is the number of playlists
+// Space Complexity: O(1)
+func (pm *PlaylistManager) Detach(id string) (*PlaylistEngine, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	engine, ok := pm.playlists[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPlaylistNotFound, id)
+	}
+	if len(pm.playlists) == 1 {
+		return nil, ErrLastPlaylist
+	}
+
+	delete(pm.playlists, id)
+	for i, existing := range pm.order {
+		if existing == id {
+			pm.order = append(pm.order[:i], pm.order[i+1:]...)
+			break
+		}
+	}
+	if pm.activeID == id {
+		pm.activeID = pm.order[0]
+	}
+	return engine, nil
+}
+
+// Attach adds an existing engine as a new, inactive playlist and returns its ID
+// The ID is generated from the engine's playlist name the same way Create does
+// Time Complexity: O(k) where k is the name length, plus O(c) for c playlists sharing the slug
+// Space Complexity: O(1)
+func (pm *PlaylistManager) Attach(engine *PlaylistEngine) string {
+	name := engine.GetPlaylistName()
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	id := pm.newID(name)
+	pm.playlists[id] = engine
+	pm.order = append(pm.order, id)
+	return id
+}
+
+// AttachWithID adds an existing engine as a new, inactive playlist under the given ID,
+// such as a playlist loaded from a snapshot saved under that ID
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pm *PlaylistManager) AttachWithID(id string, engine *PlaylistEngine) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if _, exists := pm.playlists[id]; exists {
+		return fmt.Errorf("playlist '%s' already exists", id)
+	}
+	pm.playlists[id] = engine
+	pm.order = append(pm.order, id)
+	return nil
+}
+
+// List summarizes every playlist in creation order
+// Time Complexity: O(p) where p is the number of playlists
+// Space Complexity: O(p)
+func (pm *PlaylistManager) List() []PlaylistSummary {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	summaries := make([]PlaylistSummary, 0, len(pm.order))
+	for _, id := range pm.order {
+		engine := pm.playlists[id]
+		summaries = append(summaries, PlaylistSummary{
+			ID:     id,
+			Name:   engine.GetPlaylistName(),
+			Size:   engine.GetPlaylistSize(),
+			Active: id == pm.activeID,
+		})
+	}
+	return summaries
+}
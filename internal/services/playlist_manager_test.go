@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlaylistManager(t *testing.T) {
+	manager := NewPlaylistManager("My Playlist", DefaultEngineConfig())
+
+	if manager.ActiveID() != "my-playlist" {
+		t.Errorf("Expected the default playlist to be active, got %s", manager.ActiveID())
+	}
+
+	roadTrip, err := manager.Create("Road Trip")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	again, _ := manager.Create("road trip!")
+	if roadTrip != "road-trip" || again != "road-trip-2" {
+		t.Errorf("Expected road-trip and road-trip-2, got %s and %s", roadTrip, again)
+	}
+	if _, err := manager.Create("   "); err == nil {
+		t.Error("Expected an error for an empty name")
+	}
+
+	// Each playlist has its own engine
+	engine, err := manager.Get(roadTrip)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	if manager.Active().GetPlaylistSize() != 0 {
+		t.Error("Expected the active playlist to be unaffected")
+	}
+
+	if err := manager.Switch(roadTrip); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if manager.Active() != engine {
+		t.Error("Expected the switched playlist to be active")
+	}
+	if err := manager.Switch("missing"); !errors.Is(err, ErrPlaylistNotFound) {
+		t.Errorf("Expected ErrPlaylistNotFound, got %v", err)
+	}
+
+	summaries := manager.List()
+	if len(summaries) != 3 || summaries[1].ID != roadTrip || !summaries[1].Active || summaries[1].Size != 1 {
+		t.Errorf("Unexpected summaries %+v", summaries)
+	}
+
+	// Deleting the active playlist activates the oldest remaining one
+	if err := manager.Delete(roadTrip); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if manager.ActiveID() != "my-playlist" {
+		t.Errorf("Expected my-playlist to become active, got %s", manager.ActiveID())
+	}
+	if _, err := manager.Get(roadTrip); !errors.Is(err, ErrPlaylistNotFound) {
+		t.Errorf("Expected the deleted playlist to be gone, got %v", err)
+	}
+
+	manager.Delete(again)
+	if err := manager.Delete("my-playlist"); !errors.Is(err, ErrLastPlaylist) {
+		t.Errorf("Expected ErrLastPlaylist, got %v", err)
+	}
+}
+
+func TestPlaylistManager_DetachAttach(t *testing.T) {
+	source := NewPlaylistManager("My Playlist", DefaultEngineConfig())
+	target := NewPlaylistManager("My Playlist", DefaultEngineConfig())
+
+	id, _ := source.Create("Road Trip")
+	source.Switch(id)
+	engine, err := source.Detach(id)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if source.ActiveID() != "my-playlist" {
+		t.Errorf("Expected my-playlist to become active, got %s", source.ActiveID())
+	}
+
+	if attached := target.Attach(engine); attached != "road-trip" {
+		t.Errorf("Expected road-trip, got %s", attached)
+	}
+	if attached := target.Attach(NewPlaylistEngine("My Playlist")); attached != "my-playlist-2" {
+		t.Errorf("Expected a numbered ID for a taken name, got %s", attached)
+	}
+	if err := target.AttachWithID("saved-mix", NewPlaylistEngine("Renamed Mix")); err != nil {
+		t.Errorf("Expected the engine attached under its saved ID, got %v", err)
+	}
+	if err := target.AttachWithID("road-trip", NewPlaylistEngine("Road Trip")); err == nil {
+		t.Error("Expected an error attaching under a taken ID")
+	}
+	if target.ActiveID() != "my-playlist" {
+		t.Errorf("Expected attaching to leave the active playlist alone, got %s", target.ActiveID())
+	}
+	if _, err := source.Detach("my-playlist"); !errors.Is(err, ErrLastPlaylist) {
+		t.Errorf("Expected ErrLastPlaylist, got %v", err)
+	}
+}
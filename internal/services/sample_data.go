@@ -1,7 +1,9 @@
 package services
 
 import (
+	"errors"
 	"src/internal/models"
+	"strings"
 )
 
 // SampleDataLoader provides sample songs for demonstration
@@ -18,23 +20,62 @@ func NewSampleDataLoader() *SampleDataLoader {
 
 // LoadSampleData loads sample songs into the playlist engine
 func (sdl *SampleDataLoader) LoadSampleData(engine *PlaylistEngine) error {
+	_, err := sdl.LoadSampleDataFiltered(engine, nil, 0)
+	return err
+}
+
+// LoadSampleDataFiltered loads only sample songs from the given genres, up to limit songs
+// An empty genre list loads every genre and a limit <= 0 loads every matching song
+// Returns the requested genre names that don't exist in the sample data
+func (sdl *SampleDataLoader) LoadSampleDataFiltered(engine *PlaylistEngine, genres []string, limit int) ([]string, error) {
+	allowedGenres := make(map[string]bool)
+	ignoredGenres := make([]string, 0)
+
+	if len(genres) > 0 {
+		knownGenres := make(map[string]string)
+		for genre := range sdl.GetSamplePlaylistsByGenre() {
+			knownGenres[strings.ToLower(genre)] = genre
+		}
+
+		for _, genre := range genres {
+			if known, exists := knownGenres[strings.ToLower(strings.TrimSpace(genre))]; exists {
+				allowedGenres[known] = true
+			} else {
+				ignoredGenres = append(ignoredGenres, genre)
+			}
+		}
+	}
+
+	loaded := 0
 	for _, song := range sdl.songs {
-		err := engine.AddSong(
+		if limit > 0 && loaded >= limit {
+			break
+		}
+		if len(genres) > 0 && !allowedGenres[song.Genre] {
+			continue
+		}
+
+		songID, err := engine.AddSong(
 			song.Title, song.Artist, song.Album,
 			song.Genre, song.SubGenre, song.Mood,
 			song.Duration, song.BPM,
 		)
+		if errors.Is(err, ErrPlaylistFull) {
+			// No later song can fit either
+			break
+		}
 		if err != nil {
 			// Continue loading other songs even if one fails
 			continue
 		}
+		loaded++
 
 		// Set rating if provided
 		if song.Rating > 0 {
-			engine.RateSong(song.ID, song.Rating)
+			engine.RateSong(songID, song.Rating)
 		}
 	}
-	return nil
+	return ignoredGenres, nil
 }
 
 // GetSampleSongs returns all sample songs
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"sort"
+	"src/internal/models"
+	"strings"
+)
+
+// Match kinds scored by Search, a stronger match on the same field always ranks higher
+const (
+	matchContains = 1
+	matchPrefix   = 2
+	matchExact    = 3
+)
+
+// searchFields ranks the fields Search scans, so a title that only contains the query
+// still outranks an exact match in the notes
+var searchFields = []struct {
+	field  string
+	weight int
+	values func(song *models.Song) []string
+}{
+	{"title", 5, func(song *models.Song) []string { return []string{song.Title} }},
+	{"artist", 4, func(song *models.Song) []string { return []string{song.Artist} }},
+	{"album", 3, func(song *models.Song) []string { return []string{song.Album} }},
+	{"tags", 2, func(song *models.Song) []string { return song.Tags }},
+	{"genre", 2, func(song *models.Song) []string { return []string{song.Genre} }},
+	{"subgenre", 2, func(song *models.Song) []string { return []string{song.SubGenre} }},
+	{"mood", 2, func(song *models.Song) []string { return []string{song.Mood} }},
+	{"notes", 1, func(song *models.Song) []string { return []string{song.Notes} }},
+}
+
+// SearchHit is a song matched by Search with the field that matched best and its relevance
+type SearchHit struct {
+	Song  *models.Song `json:"song"`
+	Field string       `json:"field"`
+	Score int          `json:"score"`
+}
+
+// Search matches the query case-insensitively against every searchable field of every song
+// Each song appears at most once, scored by its best field: the field weight times the match kind
+// (exact > prefix > contains); hits are ordered by score, then by title
+// Time Complexity: O(n * f * k) where f is the number of fields and k their length
+// Space Complexity: O(n) for the hits
+func (pe *PlaylistEngine) Search(query string) []SearchHit {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	hits := make([]SearchHit, 0)
+	if query == "" {
+		return hits
+	}
+
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		best := SearchHit{Song: song}
+		for _, field := range searchFields {
+			for _, value := range field.values(song) {
+				if score := field.weight * matchKind(strings.ToLower(value), query); score > best.Score {
+					best.Field = field.field
+					best.Score = score
+				}
+			}
+		}
+		if best.Score > 0 {
+			hits = append(hits, best)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return strings.ToLower(hits[i].Song.Title) < strings.ToLower(hits[j].Song.Title)
+	})
+	for i := range hits {
+		hits[i].Song = hits[i].Song.Clone()
+	}
+	return hits
+}
+
+// FuzzyMinScore is the fraction of a query's trigrams a field must contain to match in FuzzySearch
+const FuzzyMinScore = 0.5
+
+// fuzzyFields are the song fields kept in the trigram index
+var fuzzyFields = []struct {
+	field string
+	value func(song *models.Song) string
+}{
+	{"title", func(song *models.Song) string { return song.Title }},
+	{"artist", func(song *models.Song) string { return song.Artist }},
+	{"album", func(song *models.Song) string { return song.Album }},
+}
+
+// FuzzyHit is a song matched by FuzzySearch with its best field and the fraction of the query found there
+type FuzzyHit struct {
+	Song  *models.Song `json:"song"`
+	Field string       `json:"field"`
+	Score float64      `json:"score"`
+}
+
+// FuzzySearch returns up to limit songs whose title, artist or album partially match the query
+// Text is compared by character trigrams, so word prefixes, reordered words and small typos still
+// match; hits are ordered by score, then by the shortest matching field
+// A non-positive limit returns every hit
+// Time Complexity: O(g * p + m log m) where g is the number of query trigrams, p the average
+// posting list length and m the number of matches
+// Space Complexity: O(m)
+func (pe *PlaylistEngine) FuzzySearch(query string, limit int) []FuzzyHit {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	matches := pe.fuzzyIndex.Search(query, FuzzyMinScore)
+	if limit <= 0 || limit > len(matches) {
+		limit = len(matches)
+	}
+
+	hits := make([]FuzzyHit, 0, limit)
+	for _, match := range matches[:limit] {
+		song, err := pe.songLookup.Get(match.ID)
+		if err != nil {
+			continue
+		}
+		hits = append(hits, FuzzyHit{Song: song.Clone(), Field: match.Field, Score: match.Score})
+	}
+	return hits
+}
+
+// indexFuzzy adds a song's title, artist and album to the trigram index
+// Time Complexity: O(k) where k is the total length of the fields
+// Space Complexity: O(k)
+func (pe *PlaylistEngine) indexFuzzy(song *models.Song) {
+	for _, field := range fuzzyFields {
+		pe.fuzzyIndex.Add(song.ID, field.field, field.value(song))
+	}
+}
+
+// matchKind classifies how a lowercase value matches a lowercase query, 0 means no match
+func matchKind(value, query string) int {
+	switch {
+	case value == query:
+		return matchExact
+	case strings.HasPrefix(value, query):
+		return matchPrefix
+	case strings.Contains(value, query):
+		return matchContains
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"testing"
+
+	"src/internal/models"
+)
+
+func TestSearch(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	noteID, _ := engine.AddSong("Quiet Evening", "Artist 1", "Album 1", "Jazz", "Smooth Jazz", "Relaxed", 200, 80)
+	engine.SetSongNote(noteID, "sunset")
+	engine.AddSong("Sunset Boulevard", "Artist 2", "Album 2", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("After the Sunset", "Artist 3", "Album 3", "Pop", "Dance", "Happy", 200, 110)
+	engine.AddSong("Morning", "Sunset Riders", "Album 4", "Pop", "Dance", "Happy", 200, 110)
+	tagged, _ := engine.AddSong("Unrelated", "Artist 5", "Album 5", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.TagSongsWhere(func(song *models.Song) bool { return song.ID == tagged }, "sunset")
+	engine.AddSong("No Match", "Artist 6", "Album 6", "Rock", "Alternative", "Energetic", 200, 120)
+
+	hits := engine.Search("SUNSET")
+	expected := []struct {
+		title string
+		field string
+	}{
+		{"Sunset Boulevard", "title"},
+		{"Morning", "artist"},
+		{"Unrelated", "tags"},
+		{"After the Sunset", "title"},
+		{"Quiet Evening", "notes"},
+	}
+
+	if len(hits) != len(expected) {
+		t.Fatalf("Expected %d hits, got %d", len(expected), len(hits))
+	}
+	for i, want := range expected {
+		if hits[i].Song.Title != want.title || hits[i].Field != want.field {
+			t.Errorf("Hit %d: expected %s on %s, got %s on %s (score %d)",
+				i, want.title, want.field, hits[i].Song.Title, hits[i].Field, hits[i].Score)
+		}
+	}
+
+	// A title that only contains the query outranks an exact note match
+	if hits[3].Score <= hits[4].Score {
+		t.Errorf("Expected title contains (%d) above note exact (%d)", hits[3].Score, hits[4].Score)
+	}
+
+	if hits := engine.Search("   "); len(hits) != 0 {
+		t.Errorf("Expected no hits for a blank query, got %d", len(hits))
+	}
+}
+
+func TestFuzzySearch(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	engine.AddSong("Bohemian Rhapsody", "Queen", "A Night at the Opera", "Rock", "Classic Rock", "Epic", 354, 72)
+	engine.AddSong("Killer Queen", "Queen", "Sheer Heart Attack", "Rock", "Classic Rock", "Happy", 180, 117)
+	engine.AddSong("Night Fever", "Bee Gees", "Saturday Night Fever", "Disco", "Funk", "Energetic", 213, 109)
+
+	hits := engine.FuzzySearch("bohemain rapsody", 10)
+	if len(hits) != 1 || hits[0].Song.Title != "Bohemian Rhapsody" || hits[0].Field != "title" {
+		t.Errorf("Expected the misspelled title to match, got %+v", hits)
+	}
+
+	// A full title match outranks an album sharing only one of the words
+	hits = engine.FuzzySearch("night fever", 10)
+	if len(hits) != 2 || hits[0].Song.Title != "Night Fever" || hits[0].Field != "title" || hits[0].Score != 1 {
+		t.Errorf("Expected Night Fever by title first, got %+v", hits)
+	}
+
+	if hits = engine.FuzzySearch("opera", 10); len(hits) != 1 || hits[0].Field != "album" {
+		t.Errorf("Expected an album match, got %+v", hits)
+	}
+
+	if hits = engine.FuzzySearch("queen", 1); len(hits) != 1 {
+		t.Errorf("Expected the limit to cap hits, got %d", len(hits))
+	}
+
+	songs := engine.GetCurrentPlaylist()
+	engine.DeleteSongByID(songs[0].ID)
+	if hits = engine.FuzzySearch("rhapsody", 10); len(hits) != 0 {
+		t.Errorf("Expected deleted songs to drop out of the index, got %+v", hits)
+	}
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes, got %v", issues)
+	}
+}
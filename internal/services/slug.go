@@ -0,0 +1,74 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultIDSeparator joins the words and parts of generated song IDs when EngineConfig.IDSeparator is empty
+const DefaultIDSeparator = "-"
+
+// validIDSeparator reports whether sep can join ID parts without breaking :id path params:
+// a non-empty run of the URL-safe punctuation "-", ".", "_" and "~"
+// Time Complexity: O(k) where k is the length of sep
+// Space Complexity: O(1)
+func validIDSeparator(sep string) bool {
+	return sep != "" && strings.Trim(sep, "-._~") == ""
+}
+
+// slugify turns free text into a lowercase, URL-safe slug whose words are joined by sep
+// Accents are stripped ("Beyoncé" becomes "beyonce"), apostrophes are dropped so "Don't" becomes "dont",
+// "&" becomes "and", and every other run of punctuation or whitespace collapses into a single sep
+// Letters from scripts without a Latin form are kept as they are
+// Time Complexity: O(k) where k is the length of the text
+// Space Complexity: O(k)
+func slugify(text, sep string) string {
+	var builder strings.Builder
+	pendingSep := false
+
+	writeWord := func(word string) {
+		if pendingSep && builder.Len() > 0 {
+			builder.WriteString(sep)
+		}
+		pendingSep = false
+		builder.WriteString(word)
+	}
+
+	for _, r := range norm.NFD.String(text) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining accents left over from decomposition
+		case r == '\'' || r == '’' || r == '‘':
+			// Apostrophes join the word instead of splitting it
+		case r == '&':
+			pendingSep = true
+			writeWord("and")
+			pendingSep = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			writeWord(string(unicode.ToLower(r)))
+		default:
+			pendingSep = true
+		}
+	}
+
+	return builder.String()
+}
+
+// uniqueSlug slugifies name with DefaultIDSeparator, using fallback when nothing is left of it, and
+// numbers the slug ("road-trip-2") until taken reports it free
+// Time Complexity: O(k + c) where k is the name length and c the number of taken slugs tried
+// Space Complexity: O(k)
+func uniqueSlug(name, fallback string, taken func(string) bool) string {
+	base := slugify(name, DefaultIDSeparator)
+	if base == "" {
+		base = fallback
+	}
+	id := base
+	for n := 2; taken(id); n++ {
+		id = base + DefaultIDSeparator + strconv.Itoa(n)
+	}
+	return id
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		sep  string
+		want string
+	}{
+		{"spaces", "Test Song", "-", "test-song"},
+		{"apostrophe", "Don't Start Now", "-", "dont-start-now"},
+		{"curly apostrophe", "Don’t Stop Me Now", "-", "dont-stop-me-now"},
+		{"ampersand", "Simon & Garfunkel", "-", "simon-and-garfunkel"},
+		{"ampersand without spaces", "Rock&Roll", "-", "rock-and-roll"},
+		{"accents", "Beyoncé", "-", "beyonce"},
+		{"accented words", "Sigur Rós – Hoppípolla", "-", "sigur-ros-hoppipolla"},
+		{"non-latin script", "東京 Night", "-", "東京-night"},
+		{"repeated punctuation", "Hello!!! -- World???", "-", "hello-world"},
+		{"leading and trailing punctuation", "...Ready For It?", "-", "ready-for-it"},
+		{"custom separator", "Don't Start Now", "_", "dont_start_now"},
+		{"only punctuation", "?!", "-", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slugify(tt.text, tt.sep); got != tt.want {
+				t.Errorf("slugify(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSongIDSlug(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+
+	id := engine.generateSongID("Don't Start Now", "Dua Lipa", "Future Nostalgia")
+	if !strings.HasPrefix(id, "dont-start-now-dua-lipa-") {
+		t.Errorf("Expected a clean slug prefix, got %s", id)
+	}
+	if url.PathEscape(id) != id {
+		t.Errorf("Expected a URL-safe ID, got %s", id)
+	}
+
+	id = engine.generateSongID("Rhythm & Blues", "Beyoncé", "")
+	if !strings.HasPrefix(id, "rhythm-and-blues-beyonce-") {
+		t.Errorf("Expected ampersand and accents to be normalized, got %s", id)
+	}
+
+	id = engine.generateSongID("???", "!!!", "")
+	if !strings.HasPrefix(id, "song-") {
+		t.Errorf("Expected a fallback slug for punctuation-only fields, got %s", id)
+	}
+}
+
+func TestGenerateSongIDSeparator(t *testing.T) {
+	config := DefaultEngineConfig()
+	config.IDSeparator = "_"
+	engine := NewPlaylistEngineWithConfig("Test", config)
+
+	id, err := engine.AddSong("Don't Start Now", "Dua Lipa", "", "Pop", "Dance", "Happy", 183, 124)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(id, "dont_start_now_dua_lipa_") {
+		t.Errorf("Expected underscore separated ID, got %s", id)
+	}
+	if _, err := engine.SearchSongByID(id); err != nil {
+		t.Errorf("Expected the song to be found by its ID, got %v", err)
+	}
+
+	// Separators that would break :id path params fall back to the default
+	for _, sep := range []string{"/", "?", "#", " ", "-/", "x"} {
+		config.IDSeparator = sep
+		engine := NewPlaylistEngineWithConfig("Test", config)
+		if id, _ := engine.AddSong("Levitating", "Dua Lipa", "", "Pop", "Dance", "Happy", 203, 103); !strings.HasPrefix(id, "levitating-dua-lipa-") {
+			t.Errorf("Expected the default separator for %q, got %s", sep, id)
+		}
+	}
+}
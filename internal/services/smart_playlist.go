@@ -0,0 +1,465 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"src/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSmartPlaylistNotFound is returned when no smart playlist has the requested ID
+var ErrSmartPlaylistNotFound = errors.New("smart playlist not found")
+
+// SmartMatch controls whether a song must meet all or any of a smart playlist's rules
+type SmartMatch string
+
+const (
+	SmartMatchAll SmartMatch = "all"
+	SmartMatchAny SmartMatch = "any"
+)
+
+// SmartRule is one condition of a smart playlist, such as rating >= 4
+// Value is a number for numeric fields, a string for text fields and an RFC 3339 timestamp for added_at;
+// numbers given as strings are accepted too, as the query syntax produces them
+type SmartRule struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+// SmartPlaylist is the persisted definition of a playlist whose songs are the ones meeting its rules
+type SmartPlaylist struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Match     SmartMatch  `json:"match"`
+	Rules     []SmartRule `json:"rules"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// SmartPlaylistSummary is a smart playlist definition with its current number of songs
+type SmartPlaylistSummary struct {
+	SmartPlaylist
+	Count int `json:"count"`
+}
+
+// smartPlaylist pairs a definition with its compiled rules and cached membership
+// Membership is re-evaluated when the activity log's version has moved since it was computed,
+// which every song mutation causes by recording its activity
+type smartPlaylist struct {
+	definition SmartPlaylist
+	matches    func(*models.Song) bool
+	members    []*models.Song
+	version    uint64
+	evaluated  bool
+}
+
+// smartTextFields and smartNumberFields read the song fields rules can compare
+var smartTextFields = map[string]func(*models.Song) string{
+	"title":    func(s *models.Song) string { return s.Title },
+	"artist":   func(s *models.Song) string { return s.Artist },
+	"album":    func(s *models.Song) string { return s.Album },
+	"genre":    func(s *models.Song) string { return s.Genre },
+	"subgenre": func(s *models.Song) string { return s.SubGenre },
+	"mood":     func(s *models.Song) string { return s.Mood },
+}
+
+var smartNumberFields = map[string]func(*models.Song) int{
+	"rating":     func(s *models.Song) int { return s.Rating },
+	"play_count": func(s *models.Song) int { return s.PlayCount },
+	"skip_count": func(s *models.Song) int { return s.SkipCount },
+	"bpm":        func(s *models.Song) int { return s.BPM },
+	"duration":   func(s *models.Song) int { return s.Duration },
+}
+
+// compareInts applies a numeric comparison operator, reporting false for unknown operators
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func compareInts(a int, operator string, b int) bool {
+	switch operator {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// isComparison reports whether operator is one of the ordering or equality operators
+func isComparison(operator string) bool {
+	switch operator {
+	case "=", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// compile turns a rule into a predicate, checking the field, operator and value
+// Text fields and tags compare case-insensitively and support =, != and contains;
+// numeric fields and added_at support =, !=, <, <=, > and >=
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (r SmartRule) compile() (func(*models.Song) bool, error) {
+	field := strings.ToLower(strings.TrimSpace(r.Field))
+	operator := strings.ToLower(strings.TrimSpace(r.Operator))
+
+	if read, ok := smartTextFields[field]; ok || field == "tag" {
+		value, isText := r.Value.(string)
+		if !isText {
+			return nil, fmt.Errorf("%s needs a text value", field)
+		}
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		var test func(string) bool
+		switch operator {
+		case "=", "!=":
+			test = func(s string) bool { return strings.ToLower(strings.TrimSpace(s)) == value }
+		case "contains":
+			test = func(s string) bool { return strings.Contains(strings.ToLower(s), value) }
+		default:
+			return nil, fmt.Errorf("%s supports =, != and contains, not '%s'", field, r.Operator)
+		}
+
+		matches := func(song *models.Song) bool { return test(read(song)) }
+		if field == "tag" {
+			matches = func(song *models.Song) bool {
+				for _, tag := range song.Tags {
+					if test(tag) {
+						return true
+					}
+				}
+				return false
+			}
+		}
+		if operator == "!=" {
+			return func(song *models.Song) bool { return !matches(song) }, nil
+		}
+		return matches, nil
+	}
+
+	if !isComparison(operator) {
+		return nil, fmt.Errorf("%s supports =, !=, <, <=, > and >=, not '%s'", field, r.Operator)
+	}
+
+	if read, ok := smartNumberFields[field]; ok {
+		var value int
+		switch v := r.Value.(type) {
+		case float64:
+			if v != float64(int(v)) {
+				return nil, fmt.Errorf("%s needs a whole number", field)
+			}
+			value = int(v)
+		case int:
+			value = v
+		case string:
+			parsed, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("%s needs a whole number", field)
+			}
+			value = parsed
+		default:
+			return nil, fmt.Errorf("%s needs a whole number", field)
+		}
+		return func(song *models.Song) bool { return compareInts(read(song), operator, value) }, nil
+	}
+
+	if field == "added_at" {
+		text, _ := r.Value.(string)
+		value, err := time.Parse(time.RFC3339, strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("added_at needs an RFC 3339 timestamp such as 2024-01-02T15:04:05Z")
+		}
+		return func(song *models.Song) bool { return compareInts(song.AddedAt.Compare(value), operator, 0) }, nil
+	}
+
+	return nil, fmt.Errorf("unknown rule field '%s'", r.Field)
+}
+
+// compileSmartRules validates a definition's match mode and rules and combines them into one predicate
+// An empty match defaults to SmartMatchAll
+// Time Complexity: O(r) where r is the number of rules
+// Space Complexity: O(r)
+func compileSmartRules(match SmartMatch, rules []SmartRule) (SmartMatch, func(*models.Song) bool, error) {
+	if match == "" {
+		match = SmartMatchAll
+	}
+	if match != SmartMatchAll && match != SmartMatchAny {
+		return match, nil, fmt.Errorf("match must be 'all' or 'any'")
+	}
+	if len(rules) == 0 {
+		return match, nil, fmt.Errorf("a smart playlist needs at least one rule")
+	}
+
+	predicates := make([]func(*models.Song) bool, 0, len(rules))
+	for i, rule := range rules {
+		predicate, err := rule.compile()
+		if err != nil {
+			return match, nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		predicates = append(predicates, predicate)
+	}
+
+	want := match == SmartMatchAny
+	return match, func(song *models.Song) bool {
+		for _, predicate := range predicates {
+			if predicate(song) == want {
+				return want
+			}
+		}
+		return !want
+	}, nil
+}
+
+// smartClause matches one "field operator value" clause of the query syntax
+var smartClause = regexp.MustCompile(`(?i)^\s*([a-z_]+)\s*(<=|>=|!=|=|<|>|\bcontains\b)\s*(.+?)\s*$`)
+
+// smartAnd matches the AND joining two clauses at the start of the rest of a query
+var smartAnd = regexp.MustCompile(`(?i)^\s+and(?:\s+|$)`)
+
+// smartClauseStart matches the field and operator a clause begins with
+var smartClauseStart = regexp.MustCompile(`(?i)^([a-z_]+)\s*(<=|>=|!=|=|<|>|\bcontains\b)`)
+
+// ParseSmartQuery parses rules written as "rating >= 4 AND genre = Rock AND play_count < 3"
+// Clauses are joined with AND; values may be wrapped in single or double quotes
+// Time Complexity: O(k) where k is the query length
+// Space Complexity: O(k)
+func ParseSmartQuery(query string) ([]SmartRule, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+
+	clauses := splitSmartClauses(strings.TrimSpace(query))
+	rules := make([]SmartRule, 0, len(clauses))
+	for _, clause := range clauses {
+		parts := smartClause.FindStringSubmatch(clause)
+		if parts == nil {
+			return nil, fmt.Errorf("can't parse '%s', expected field, operator and value", strings.TrimSpace(clause))
+		}
+		value := parts[3]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		rules = append(rules, SmartRule{Field: parts[1], Operator: parts[2], Value: value})
+	}
+	return rules, nil
+}
+
+// splitSmartClauses splits a query on the ANDs joining its clauses
+// An AND inside a quoted value, or one not followed by another clause as in "artist = Simon and
+// Garfunkel", is part of the value; a trailing AND still splits, leaving an empty clause to report
+// A quote only opens a quoted value at the start of a word, so apostrophes in values are literal
+// Time Complexity: O(k) where k is the query length
+// Space Complexity: O(k)
+func splitSmartClauses(query string) []string {
+	var clauses []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" \t\n=<>", query[i-1]) >= 0 {
+				quote = c
+			}
+		default:
+			loc := smartAnd.FindStringIndex(query[i:])
+			if loc == nil {
+				continue
+			}
+			rest := query[i+loc[1]:]
+			if rest == "" || smartClauseStart.MatchString(rest) {
+				clauses = append(clauses, query[start:i])
+				start = i + loc[1]
+				i = start - 1
+			}
+		}
+	}
+	return append(clauses, query[start:])
+}
+
+// CreateSmartPlaylist adds a smart playlist and returns its definition
+// The ID is the slugified name, numbered when another smart playlist already uses it
+// Time Complexity: O(r) where r is the number of rules
+// Space Complexity: O(r)
+func (pe *PlaylistEngine) CreateSmartPlaylist(name string, match SmartMatch, rules []SmartRule) (SmartPlaylist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return SmartPlaylist{}, fmt.Errorf("smart playlist name cannot be empty")
+	}
+	match, matches, err := compileSmartRules(match, rules)
+	if err != nil {
+		return SmartPlaylist{}, err
+	}
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	now := time.Now()
+	definition := SmartPlaylist{
+		ID:        uniqueSlug(name, "smart", func(id string) bool { return pe.smartPlaylists[id] != nil }),
+		Name:      name,
+		Match:     match,
+		Rules:     append([]SmartRule(nil), rules...),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	pe.smartPlaylists[definition.ID] = &smartPlaylist{definition: definition, matches: matches}
+	pe.smartOrder = append(pe.smartOrder, definition.ID)
+
+	pe.activity.record(ActivitySmartPlaylist, "")
+	return definition, nil
+}
+
+// UpdateSmartPlaylist replaces the name, match mode and rules of a smart playlist, keeping its ID
+// Time Complexity: O(r) where r is the number of rules
+// Space Complexity: O(r)
+func (pe *PlaylistEngine) UpdateSmartPlaylist(id, name string, match SmartMatch, rules []SmartRule) (SmartPlaylist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return SmartPlaylist{}, fmt.Errorf("smart playlist name cannot be empty")
+	}
+	match, matches, err := compileSmartRules(match, rules)
+	if err != nil {
+		return SmartPlaylist{}, err
+	}
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	smart, ok := pe.smartPlaylists[id]
+	if !ok {
+		return SmartPlaylist{}, fmt.Errorf("%w: %s", ErrSmartPlaylistNotFound, id)
+	}
+	smart.definition.Name = name
+	smart.definition.Match = match
+	smart.definition.Rules = append([]SmartRule(nil), rules...)
+	smart.definition.UpdatedAt = time.Now()
+	smart.matches = matches
+	smart.evaluated = false
+
+	pe.activity.record(ActivitySmartPlaylist, "")
+	return smart.definition, nil
+}
+
+// DeleteSmartPlaylist removes a smart playlist; its songs stay in the playlist
+// Time Complexity: O(s) where s is the number of smart playlists
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) DeleteSmartPlaylist(id string) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	if _, ok := pe.smartPlaylists[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrSmartPlaylistNotFound, id)
+	}
+	delete(pe.smartPlaylists, id)
+	for i, existing := range pe.smartOrder {
+		if existing == id {
+			pe.smartOrder = append(pe.smartOrder[:i], pe.smartOrder[i+1:]...)
+			break
+		}
+	}
+
+	pe.activity.record(ActivitySmartPlaylist, "")
+	return nil
+}
+
+// GetSmartPlaylist returns a smart playlist's definition and its songs in playlist order
+// Time Complexity: O(1) when no song changed since the last evaluation, O(n * r) otherwise
+// Space Complexity: O(m) where m is the number of matching songs
+func (pe *PlaylistEngine) GetSmartPlaylist(id string) (SmartPlaylist, []*models.Song, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	smart, ok := pe.smartPlaylists[id]
+	if !ok {
+		return SmartPlaylist{}, nil, fmt.Errorf("%w: %s", ErrSmartPlaylistNotFound, id)
+	}
+	return smart.definition, cloneSongs(pe.smartMembers(smart)), nil
+}
+
+// ListSmartPlaylists summarizes every smart playlist in creation order
+// Time Complexity: O(s) when no song changed since the last evaluation, O(s * n * r) otherwise
+// Space Complexity: O(s)
+func (pe *PlaylistEngine) ListSmartPlaylists() []SmartPlaylistSummary {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	summaries := make([]SmartPlaylistSummary, 0, len(pe.smartOrder))
+	for _, id := range pe.smartOrder {
+		smart := pe.smartPlaylists[id]
+		summaries = append(summaries, SmartPlaylistSummary{
+			SmartPlaylist: smart.definition,
+			Count:         len(pe.smartMembers(smart)),
+		})
+	}
+	return summaries
+}
+
+// smartMembers returns a smart playlist's songs, re-evaluating its rules over the playlist when
+// any song changed since they were last evaluated; callers must hold pe.mu for writing
+// Time Complexity: O(1) cached, O(n * r) when re-evaluated
+// Space Complexity: O(m)
+func (pe *PlaylistEngine) smartMembers(smart *smartPlaylist) []*models.Song {
+	if smart.evaluated && smart.version == pe.activity.version {
+		return smart.members
+	}
+
+	members := make([]*models.Song, 0)
+	for _, song := range pe.currentPlaylist.ToSlice() {
+		if smart.matches(song) {
+			members = append(members, song)
+		}
+	}
+	smart.members = members
+	smart.version = pe.activity.version
+	smart.evaluated = true
+	return members
+}
+
+// exportSmartPlaylists copies every smart playlist definition in creation order for ExportState
+// Time Complexity: O(s * r)
+// Space Complexity: O(s * r)
+func (pe *PlaylistEngine) exportSmartPlaylists() []SmartPlaylist {
+	definitions := make([]SmartPlaylist, 0, len(pe.smartOrder))
+	for _, id := range pe.smartOrder {
+		definition := pe.smartPlaylists[id].definition
+		definition.Rules = append([]SmartRule(nil), definition.Rules...)
+		definitions = append(definitions, definition)
+	}
+	return definitions
+}
+
+// compileSmartPlaylists validates saved definitions for RestoreState, keyed by ID
+// Time Complexity: O(s * r)
+// Space Complexity: O(s * r)
+func compileSmartPlaylists(definitions []SmartPlaylist) (map[string]*smartPlaylist, []string, error) {
+	compiled := make(map[string]*smartPlaylist, len(definitions))
+	order := make([]string, 0, len(definitions))
+	for _, definition := range definitions {
+		if definition.ID == "" || compiled[definition.ID] != nil {
+			return nil, nil, fmt.Errorf("smart playlist '%s' has a missing or repeated ID", definition.Name)
+		}
+		match, matches, err := compileSmartRules(definition.Match, definition.Rules)
+		if err != nil {
+			return nil, nil, fmt.Errorf("smart playlist '%s': %w", definition.ID, err)
+		}
+		definition.Match = match
+		compiled[definition.ID] = &smartPlaylist{definition: definition, matches: matches}
+		order = append(order, definition.ID)
+	}
+	return compiled, order, nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"src/internal/models"
+	"testing"
+)
+
+func smartTitles(t *testing.T, engine *PlaylistEngine, id string) []string {
+	t.Helper()
+	_, songs, err := engine.GetSmartPlaylist(id)
+	if err != nil {
+		t.Fatalf("GetSmartPlaylist(%s) returned %v", id, err)
+	}
+	titles := make([]string, 0, len(songs))
+	for _, song := range songs {
+		titles = append(titles, song.Title)
+	}
+	return titles
+}
+
+func TestParseSmartQuery(t *testing.T) {
+	rules, err := ParseSmartQuery(`rating >= 4 AND genre = "Hard Rock" and title contains love`)
+	if err != nil {
+		t.Fatalf("Expected the query to parse, got %v", err)
+	}
+	want := []SmartRule{
+		{Field: "rating", Operator: ">=", Value: "4"},
+		{Field: "genre", Operator: "=", Value: "Hard Rock"},
+		{Field: "title", Operator: "contains", Value: "love"},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, rules)
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("Rule %d: expected %+v, got %+v", i, want[i], rules[i])
+		}
+	}
+
+	// AND inside a quoted value, or not followed by another clause, belongs to the value
+	joined := map[string][]SmartRule{
+		`genre = "Drum and Bass" AND rating >= 4`: {
+			{Field: "genre", Operator: "=", Value: "Drum and Bass"},
+			{Field: "rating", Operator: ">=", Value: "4"},
+		},
+		`artist = Simon and Garfunkel`: {
+			{Field: "artist", Operator: "=", Value: "Simon and Garfunkel"},
+		},
+		`title = 'Rock and Roll' and artist = Don't Stop AND mood = Happy`: {
+			{Field: "title", Operator: "=", Value: "Rock and Roll"},
+			{Field: "artist", Operator: "=", Value: "Don't Stop"},
+			{Field: "mood", Operator: "=", Value: "Happy"},
+		},
+	}
+	for query, want := range joined {
+		rules, err := ParseSmartQuery(query)
+		if err != nil || fmt.Sprint(rules) != fmt.Sprint(want) {
+			t.Errorf("ParseSmartQuery(%q) = %+v, %v, want %+v", query, rules, err, want)
+		}
+	}
+
+	for _, query := range []string{"", "rating", "rating >= 4 AND", "rating ~ 4"} {
+		if _, err := ParseSmartQuery(query); err == nil {
+			t.Errorf("Expected an error parsing %q", query)
+		}
+	}
+}
+
+func TestSmartPlaylist_FollowsSongChanges(t *testing.T) {
+	engine := NewPlaylistEngine("Smart")
+	rockID, _ := engine.AddSong("Rock Song", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Jazz Song", "Artist", "Album", "Jazz", "Bebop", "Chill", 200, 100)
+	engine.RateSong(rockID, 5)
+
+	rules, _ := ParseSmartQuery("rating >= 4 AND genre = rock AND play_count < 2")
+	smart, err := engine.CreateSmartPlaylist("Fresh Favourites", "", rules)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if smart.ID != "fresh-favourites" || smart.Match != SmartMatchAll {
+		t.Errorf("Unexpected definition %+v", smart)
+	}
+	if got := smartTitles(t, engine, smart.ID); len(got) != 1 || got[0] != "Rock Song" {
+		t.Errorf("Expected only the rated rock song, got %v", got)
+	}
+
+	// Playing the song twice takes it over the play count limit
+	engine.PlaySong(0)
+	engine.PlaySong(0)
+	if got := smartTitles(t, engine, smart.ID); len(got) != 0 {
+		t.Errorf("Expected the played song to drop out, got %v", got)
+	}
+
+	// Songs added or edited later join when they match
+	newID, _ := engine.AddSong("New Rock", "Artist", "Album", "Rock", "Grunge", "Energetic", 200, 130)
+	engine.RateSong(newID, 4)
+	if got := smartTitles(t, engine, smart.ID); len(got) != 1 || got[0] != "New Rock" {
+		t.Errorf("Expected the newly rated rock song, got %v", got)
+	}
+	genre := "Pop"
+	engine.UpdateSong(newID, SongUpdate{Genre: &genre})
+	if got := smartTitles(t, engine, smart.ID); len(got) != 0 {
+		t.Errorf("Expected the re-genred song to drop out, got %v", got)
+	}
+}
+
+func TestSmartPlaylist_MatchAnyAndTags(t *testing.T) {
+	engine := NewPlaylistEngine("Smart")
+	engine.AddSong("Long", "Artist", "Album", "Ambient", "Drone", "Calm", 600, 60)
+	tagged, _ := engine.AddSong("Tagged", "Artist", "Album", "Pop", "Synth", "Happy", 180, 120)
+	engine.AddSong("Neither", "Artist", "Album", "Pop", "Synth", "Happy", 180, 120)
+	engine.TagSongsWhere(func(song *models.Song) bool { return song.ID == tagged }, "Workout")
+
+	smart, err := engine.CreateSmartPlaylist("Mixed", SmartMatchAny, []SmartRule{
+		{Field: "duration", Operator: ">", Value: float64(500)},
+		{Field: "tag", Operator: "=", Value: "workout"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := smartTitles(t, engine, smart.ID); len(got) != 2 || got[0] != "Long" || got[1] != "Tagged" {
+		t.Errorf("Expected Long and Tagged, got %v", got)
+	}
+
+	if summaries := engine.ListSmartPlaylists(); len(summaries) != 1 || summaries[0].Count != 2 {
+		t.Errorf("Expected one smart playlist of 2 songs, got %+v", summaries)
+	}
+}
+
+func TestSmartPlaylist_CRUDAndPersistence(t *testing.T) {
+	engine := NewPlaylistEngine("Smart")
+	engine.AddSong("Song", "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	invalid := [][]SmartRule{
+		nil,
+		{{Field: "colour", Operator: "=", Value: "red"}},
+		{{Field: "rating", Operator: "contains", Value: float64(4)}},
+		{{Field: "rating", Operator: ">=", Value: "four"}},
+		{{Field: "genre", Operator: "=", Value: float64(1)}},
+		{{Field: "added_at", Operator: ">", Value: "yesterday"}},
+	}
+	for _, rules := range invalid {
+		if _, err := engine.CreateSmartPlaylist("Bad", "", rules); err == nil {
+			t.Errorf("Expected an error for rules %+v", rules)
+		}
+	}
+	if _, err := engine.CreateSmartPlaylist("Bad", "some", []SmartRule{{Field: "bpm", Operator: ">", Value: float64(1)}}); err == nil {
+		t.Error("Expected an error for an unknown match mode")
+	}
+
+	rules := []SmartRule{{Field: "bpm", Operator: ">=", Value: float64(100)}}
+	smart, _ := engine.CreateSmartPlaylist("Fast", "", rules)
+	if duplicate, _ := engine.CreateSmartPlaylist("Fast", "", rules); duplicate.ID != "fast-2" {
+		t.Errorf("Expected a numbered ID for a repeated name, got %s", duplicate.ID)
+	}
+
+	updated, err := engine.UpdateSmartPlaylist(smart.ID, "Very Fast", "", []SmartRule{{Field: "bpm", Operator: ">=", Value: float64(150)}})
+	if err != nil || updated.ID != smart.ID || updated.Name != "Very Fast" {
+		t.Errorf("Expected the renamed smart playlist, got %+v, %v", updated, err)
+	}
+	if got := smartTitles(t, engine, smart.ID); len(got) != 0 {
+		t.Errorf("Expected the new rules to apply, got %v", got)
+	}
+
+	// Definitions survive a save and restore
+	restored := NewPlaylistEngine("Restored")
+	if err := restored.RestoreState(engine.ExportState()); err != nil {
+		t.Fatalf("Expected the state to restore, got %v", err)
+	}
+	if summaries := restored.ListSmartPlaylists(); len(summaries) != 2 || summaries[0].Name != "Very Fast" {
+		t.Errorf("Expected both smart playlists restored, got %+v", summaries)
+	}
+	if got := smartTitles(t, restored, "fast-2"); len(got) != 1 {
+		t.Errorf("Expected the restored smart playlist to match, got %v", got)
+	}
+
+	if err := engine.DeleteSmartPlaylist(smart.ID); err != nil {
+		t.Errorf("Expected no error deleting, got %v", err)
+	}
+	if _, _, err := engine.GetSmartPlaylist(smart.ID); !errors.Is(err, ErrSmartPlaylistNotFound) {
+		t.Errorf("Expected ErrSmartPlaylistNotFound, got %v", err)
+	}
+	if err := engine.DeleteSmartPlaylist(smart.ID); !errors.Is(err, ErrSmartPlaylistNotFound) {
+		t.Errorf("Expected ErrSmartPlaylistNotFound deleting twice, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"fmt"
+	"src/internal/models"
+	"time"
+)
+
+// EngineState is the persistent part of a playlist engine: its name, songs with their ratings
+// and play statistics, playback history, smart playlist definitions and the listening timeline
+// Indexes, the queue, the trash and the activity log are rebuilt or start empty on restore
+type EngineState struct {
+	Name           string          `json:"name"`
+	Songs          []*models.Song  `json:"songs"`
+	History        []PlayRecord    `json:"history"`
+	SmartPlaylists []SmartPlaylist `json:"smart_playlists,omitempty"`
+	Timeline       []TimelineDay   `json:"timeline,omitempty"`
+}
+
+// ExportState copies the engine's persistent state, songs in playlist order and history oldest first
+// Time Complexity: O(n + h) where h is the history size
+// Space Complexity: O(n + h)
+func (pe *PlaylistEngine) ExportState() EngineState {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs := pe.copySongs()
+
+	entries := pe.playbackHistory.GetEntriesSince(time.Time{})
+	history := make([]PlayRecord, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		history = append(history, PlayRecord{SongID: entries[i].Song.ID, PlayedAt: entries[i].PlayedAt})
+	}
+
+	return EngineState{
+		Name:           pe.playlistName,
+		Songs:          songs,
+		History:        history,
+		SmartPlaylists: pe.exportSmartPlaylists(),
+		Timeline:       pe.timeline.export(),
+	}
+}
+
+// copySongs returns copies of the songs in playlist order, safe to read after the lock is released
+// Time Complexity: O(n)
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) copySongs() []*models.Song {
+	return cloneSongs(pe.currentPlaylist.ToSlice())
+}
+
+// cloneSongs copies each song of a result, so callers can read them after the lock is released
+// while writers keep changing the engine's own songs; a nil slice stays nil
+// Time Complexity: O(k) where k is the number of songs
+// Space Complexity: O(k)
+func cloneSongs(songs []*models.Song) []*models.Song {
+	if songs == nil {
+		return nil
+	}
+	clones := make([]*models.Song, len(songs))
+	for i, song := range songs {
+		clones[i] = song.Clone()
+	}
+	return clones
+}
+
+// RestoreState replaces the engine's songs, name and playback history with a saved state
+// Play counts and ratings come from the saved songs, so history entries are not counted again;
+// entries for songs that are no longer in the playlist are dropped
+// Smart playlists are replaced by the saved definitions; a state saved without a timeline
+// rebuilds it from the restored history
+// The state is validated first and the engine is left untouched when it is invalid
+// Time Complexity: O(n + h log h) where h is the history size
+// Space Complexity: O(n + h)
+func (pe *PlaylistEngine) RestoreState(state EngineState) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	seen := make(map[string]bool, len(state.Songs))
+	for i, song := range state.Songs {
+		if song == nil || song.ID == "" {
+			return fmt.Errorf("song %d has no ID", i+1)
+		}
+		if seen[song.ID] {
+			return fmt.Errorf("song ID '%s' appears more than once", song.ID)
+		}
+		if song.Rating < 0 || song.Rating > 5 {
+			return fmt.Errorf("song '%s' has invalid rating %d", song.ID, song.Rating)
+		}
+		seen[song.ID] = true
+	}
+	smartPlaylists, smartOrder, err := compileSmartPlaylists(state.SmartPlaylists)
+	if err != nil {
+		return err
+	}
+
+	pe.clearSongs()
+	pe.playbackHistory.Clear()
+	pe.trash.Clear()
+	pe.smartPlaylists, pe.smartOrder = smartPlaylists, smartOrder
+	if state.Name != "" {
+		pe.playlistName = state.Name
+	}
+
+	for _, song := range state.Songs {
+		copied := *song
+		pe.insertSong(&copied)
+	}
+
+	pe.timeline.restore(state.Timeline)
+	for _, entry := range state.History {
+		if song, err := pe.songLookup.Get(entry.SongID); err == nil && !entry.PlayedAt.IsZero() {
+			pe.playbackHistory.PushAt(song, entry.PlayedAt)
+			if len(state.Timeline) == 0 {
+				pe.timeline.record(song, entry.PlayedAt)
+			}
+		}
+	}
+
+	pe.activity.record(ActivityRestoreState, "")
+	return nil
+}
+
+// SetChangeHook registers a function called after every mutating operation, nil removes it
+// The hook runs synchronously inside the operation, so it must not call back into the engine;
+// hand the work to another goroutine instead
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) SetChangeHook(hook func()) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.activity.onRecord = hook
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"src/internal/models"
+)
+
+func TestExportRestoreState(t *testing.T) {
+	engine := NewPlaylistEngine("Road Trip")
+	first, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	second, _ := engine.AddSong("Song 2", "Artist 2", "Album", "Jazz", "Smooth Jazz", "Relaxed", 300, 90)
+	engine.RateSong(second, 4)
+	engine.SetSongNote(first, "great opener")
+	engine.PlaySong(0)
+	engine.PlaySong(1)
+	engine.PlaySong(0)
+
+	state := engine.ExportState()
+	if state.Name != "Road Trip" || len(state.Songs) != 2 || len(state.History) != 3 {
+		t.Fatalf("Unexpected state %+v", state)
+	}
+	if state.History[0].SongID != first || state.History[1].SongID != second {
+		t.Errorf("Expected history oldest first, got %+v", state.History)
+	}
+
+	restored := NewPlaylistEngine("Other")
+	restored.AddSong("Leftover", "Artist", "Album", "Pop", "Dance", "Happy", 100, 100)
+	if err := restored.RestoreState(state); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if restored.GetPlaylistName() != "Road Trip" || restored.GetPlaylistSize() != 2 {
+		t.Errorf("Expected the saved name and 2 songs, got %s with %d", restored.GetPlaylistName(), restored.GetPlaylistSize())
+	}
+	if _, err := restored.SearchSongByTitle("Leftover"); err == nil {
+		t.Error("Expected songs from before the restore to be gone")
+	}
+
+	song, err := restored.SearchSongByID(first)
+	if err != nil {
+		t.Fatalf("Expected the song to be indexed by ID, got %v", err)
+	}
+	if song.PlayCount != 2 || song.Notes != "great opener" {
+		t.Errorf("Expected play count 2 and the note, got %d %q", song.PlayCount, song.Notes)
+	}
+	if rated := restored.GetSongsByRating(4); len(rated) != 1 || rated[0].ID != second {
+		t.Errorf("Expected the rating tree to be rebuilt, got %v", rated)
+	}
+	if recent := restored.GetRecentlyPlayedSongs(3); len(recent) != 3 || recent[0].ID != first {
+		t.Errorf("Expected history restored newest first, got %d entries", len(recent))
+	}
+	if restored.totalPlayTime != 500 {
+		t.Errorf("Expected total play time 500, got %d", restored.totalPlayTime)
+	}
+
+	// The exported songs are copies
+	state.Songs[0].Title = "Changed"
+	if song, _ := engine.SearchSongByID(first); song.Title != "Song 1" {
+		t.Error("Expected exporting to copy songs")
+	}
+}
+
+func TestRestoreStateInvalid(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	duplicate := models.NewSong("same-id", "A", "Artist", "", "Rock", "", "", 100, 100)
+	state := EngineState{Songs: []*models.Song{duplicate, duplicate}}
+	if err := engine.RestoreState(state); err == nil {
+		t.Error("Expected an error for duplicate song IDs")
+	}
+	if engine.GetPlaylistSize() != 1 {
+		t.Errorf("Expected the engine unchanged after a failed restore, got %d songs", engine.GetPlaylistSize())
+	}
+
+	// History entries for unknown songs are dropped
+	state = EngineState{
+		Songs:   []*models.Song{duplicate},
+		History: []PlayRecord{{SongID: "missing", PlayedAt: time.Now()}, {SongID: "same-id", PlayedAt: time.Now()}},
+	}
+	if err := engine.RestoreState(state); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if recent := engine.GetRecentlyPlayedSongs(10); len(recent) != 1 {
+		t.Errorf("Expected 1 history entry, got %d", len(recent))
+	}
+}
+
+func TestSetChangeHook(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	changes := 0
+	engine.SetChangeHook(func() { changes++ })
+
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.ReversePlaylist()
+	engine.GetCurrentPlaylist()
+	if changes != 2 {
+		t.Errorf("Expected 2 changes, got %d", changes)
+	}
+
+	engine.SetChangeHook(nil)
+	engine.ClearPlaylist()
+	if changes != 2 {
+		t.Errorf("Expected no calls after removing the hook, got %d", changes)
+	}
+}
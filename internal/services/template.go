@@ -0,0 +1,90 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TemplateSong is the shareable metadata of a song, without play counts, ratings or notes
+type TemplateSong struct {
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album"`
+	Genre    string `json:"genre"`
+	SubGenre string `json:"subgenre"`
+	Mood     string `json:"mood"`
+	Duration int    `json:"duration"`
+	BPM      int    `json:"bpm"`
+
+	ArtworkURL string `json:"artwork_url,omitempty"`
+	SourceURL  string `json:"source_url,omitempty"`
+}
+
+// PlaylistTemplate describes a playlist's structure for sharing
+type PlaylistTemplate struct {
+	Name  string         `json:"name"`
+	Songs []TemplateSong `json:"songs"`
+}
+
+// ExportTemplate returns the playlist as a JSON template holding only song metadata in playlist order
+// Unlike ExportSnapshot, personal stats such as play counts and ratings are left out
+// Time Complexity: O(n) where n is the playlist size
+// Space Complexity: O(n)
+func (pe *PlaylistEngine) ExportTemplate() []byte {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs := pe.currentPlaylist.ToSlice()
+
+	template := PlaylistTemplate{
+		Name:  pe.playlistName,
+		Songs: make([]TemplateSong, 0, len(songs)),
+	}
+	for _, song := range songs {
+		template.Songs = append(template.Songs, TemplateSong{
+			Title:    song.Title,
+			Artist:   song.Artist,
+			Album:    song.Album,
+			Genre:    song.Genre,
+			SubGenre: song.SubGenre,
+			Mood:     song.Mood,
+			Duration: song.Duration,
+			BPM:      song.BPM,
+
+			ArtworkURL: song.ArtworkURL,
+			SourceURL:  song.SourceURL,
+		})
+	}
+
+	// Marshalling only strings and ints cannot fail
+	data, _ := json.Marshal(template)
+	return data
+}
+
+// ImportTemplate adds every song of a JSON playlist template to the engine
+// Imported songs start with zero play counts and no rating
+// Songs that can't be added are recorded in errs instead of aborting
+// Time Complexity: O(s * n) where s is the number of template songs and n the playlist size (duplicate check)
+// Space Complexity: O(s)
+func ImportTemplate(engine *PlaylistEngine, data []byte) (added int, errs []error) {
+	var template PlaylistTemplate
+	if err := json.Unmarshal(data, &template); err != nil {
+		return 0, []error{fmt.Errorf("invalid template: %v", err)}
+	}
+
+	for i, song := range template.Songs {
+		if song.Title == "" || song.Artist == "" {
+			errs = append(errs, fmt.Errorf("song %d: title and artist are required", i+1))
+			continue
+		}
+
+		if _, err := engine.AddSongWithLinks(song.Title, song.Artist, song.Album, song.Genre, song.SubGenre, song.Mood, song.Duration, song.BPM, song.ArtworkURL, song.SourceURL); err != nil {
+			errs = append(errs, fmt.Errorf("song %d: %v", i+1, err))
+			continue
+		}
+
+		added++
+	}
+
+	return added, errs
+}
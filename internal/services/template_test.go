@@ -0,0 +1,90 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportTemplate(t *testing.T) {
+	engine := NewPlaylistEngine("Road Trip")
+
+	songID, _ := engine.AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album 2", "Jazz", "Smooth Jazz", "Relaxed", 300, 90)
+	engine.RateSong(songID, 5)
+	engine.PlaySong(0)
+	engine.SetSongNote(songID, "great for intros")
+
+	data := engine.ExportTemplate()
+
+	// Personal stats must not appear in the template at all
+	for _, field := range []string{"play_count", "rating", "notes", "last_played", "id"} {
+		if strings.Contains(string(data), `"`+field+`"`) {
+			t.Errorf("Template should not contain %q field: %s", field, data)
+		}
+	}
+
+	var template PlaylistTemplate
+	if err := json.Unmarshal(data, &template); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if template.Name != "Road Trip" {
+		t.Errorf("Expected template name 'Road Trip', got %s", template.Name)
+	}
+	if len(template.Songs) != 2 {
+		t.Fatalf("Expected 2 template songs, got %d", len(template.Songs))
+	}
+	first := template.Songs[0]
+	if first.Title != "Song 1" || first.Genre != "Rock" || first.Duration != 200 || first.BPM != 120 {
+		t.Errorf("Unexpected first template song: %+v", first)
+	}
+}
+
+func TestImportTemplate(t *testing.T) {
+	source := NewPlaylistEngine("Source")
+	songID, _ := source.AddSong("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 200, 120)
+	source.AddSong("Song 2", "Artist 2", "Album 2", "Jazz", "Smooth Jazz", "Relaxed", 300, 90)
+	source.RateSong(songID, 5)
+	source.PlaySong(0)
+
+	engine := NewPlaylistEngine("Copy")
+	added, errs := ImportTemplate(engine, source.ExportTemplate())
+	if added != 2 || len(errs) != 0 {
+		t.Fatalf("Expected 2 songs added without errors, got %d, %v", added, errs)
+	}
+
+	for _, song := range engine.GetCurrentPlaylist() {
+		if song.PlayCount != 0 || song.Rating != 0 {
+			t.Errorf("Imported song %s should start with no plays or rating, got %d plays, rating %d", song.Title, song.PlayCount, song.Rating)
+		}
+	}
+	if len(engine.GetGenres()) != 2 {
+		t.Errorf("Expected imported songs in the explorer tree, got genres %v", engine.GetGenres())
+	}
+
+	// Invalid entries are reported without aborting
+	added, errs = ImportTemplate(engine, []byte(`{"songs":[{"title":"","artist":"X"},{"title":"New","artist":"Y","duration":100}]}`))
+	if added != 1 || len(errs) != 1 {
+		t.Errorf("Expected 1 song added and 1 error, got %d, %v", added, errs)
+	}
+
+	if _, errs := ImportTemplate(engine, []byte("not json")); len(errs) != 1 {
+		t.Errorf("Expected a single error for malformed JSON, got %v", errs)
+	}
+}
+
+func TestTemplateKeepsLinks(t *testing.T) {
+	source := NewPlaylistEngine("Source")
+	source.AddSongWithLinks("Song 1", "Artist 1", "Album 1", "Rock", "Alternative", "Energetic", 200, 120,
+		"https://example.com/cover.jpg", "https://example.com/track")
+
+	engine := NewPlaylistEngine("Copy")
+	if added, errs := ImportTemplate(engine, source.ExportTemplate()); added != 1 || len(errs) != 0 {
+		t.Fatalf("Expected 1 song added without errors, got %d, %v", added, errs)
+	}
+
+	song := engine.GetCurrentPlaylist()[0]
+	if song.ArtworkURL != "https://example.com/cover.jpg" || song.SourceURL != "https://example.com/track" {
+		t.Errorf("Expected links to survive export and import, got %q and %q", song.ArtworkURL, song.SourceURL)
+	}
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"sort"
+	"src/internal/models"
+	"time"
+)
+
+// DefaultTimelineDays is the number of days of listening statistics the timeline keeps
+const DefaultTimelineDays = 182
+
+// ArtistPlays counts the plays of one artist
+type ArtistPlays struct {
+	Artist string `json:"artist"`
+	Plays  int    `json:"plays"`
+}
+
+// TimelineDay holds the listening statistics of one UTC day
+type TimelineDay struct {
+	Date             string        `json:"date"` // YYYY-MM-DD
+	Plays            int           `json:"plays"`
+	ListeningSeconds int           `json:"listening_seconds"`
+	Artists          []ArtistPlays `json:"artists,omitempty"`
+}
+
+// TimelineWeek holds the listening statistics of one week, Monday to Sunday in UTC
+// ChangePercent compares ListeningSeconds with the week before and is nil when that week had none
+type TimelineWeek struct {
+	WeekStart        string        `json:"week_start"` // YYYY-MM-DD of the Monday
+	Plays            int           `json:"plays"`
+	ListeningSeconds int           `json:"listening_seconds"`
+	ChangePercent    *float64      `json:"change_percent"`
+	TopArtists       []ArtistPlays `json:"top_artists"`
+}
+
+// ListeningTimeline reports listening over the last days and weeks, oldest first
+type ListeningTimeline struct {
+	PlaysPerDay []TimelineDay  `json:"plays_per_day"`
+	Weeks       []TimelineWeek `json:"weeks"`
+	// Trend compares the listening time of the latest full period of len(PlaysPerDay) days with the one before
+	Trend ListeningTrend `json:"trend"`
+}
+
+// ListeningTrend compares listening time between two equally long periods
+type ListeningTrend struct {
+	CurrentSeconds  int      `json:"current_seconds"`
+	PreviousSeconds int      `json:"previous_seconds"`
+	ChangePercent   *float64 `json:"change_percent"`
+	Direction       string   `json:"direction"` // up, down or steady
+}
+
+// timelineBucket is one day of the timeline ring
+type timelineBucket struct {
+	day     int64 // Days since the Unix epoch, 0 for an unused bucket
+	plays   int
+	seconds int
+	artists map[string]*ArtistPlays // Normalized artist -> plays, named as first heard
+}
+
+// listeningTimeline records every play into per-day buckets kept in a ring indexed by day number,
+// so recording is O(1) and memory stays constant: a day's bucket is reused once it falls out of
+// the retained window
+// Time Complexity: O(1) per record
+// Space Complexity: O(d * a) where d is the number of retained days and a the artists heard per day
+type listeningTimeline struct {
+	buckets []timelineBucket
+	now     func() time.Time
+}
+
+// newListeningTimeline creates an empty timeline keeping days days of statistics
+// Time Complexity: O(d)
+// Space Complexity: O(d)
+func newListeningTimeline(days int) *listeningTimeline {
+	if days <= 0 {
+		days = DefaultTimelineDays
+	}
+	return &listeningTimeline{
+		buckets: make([]timelineBucket, days),
+		now:     time.Now,
+	}
+}
+
+// dayNumber returns the number of UTC days between the Unix epoch and t
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func dayNumber(t time.Time) int64 {
+	return t.UTC().Unix() / int64(24*time.Hour/time.Second)
+}
+
+// dayDate returns the YYYY-MM-DD date of a day number
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func dayDate(day int64) string {
+	return time.Unix(day*int64(24*time.Hour/time.Second), 0).UTC().Format("2006-01-02")
+}
+
+// bucket returns the bucket of a day, clearing it when it still holds an older day;
+// nil when the day is already out of the retained window
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (lt *listeningTimeline) bucket(day int64) *timelineBucket {
+	b := &lt.buckets[int(day%int64(len(lt.buckets)))]
+	if b.day > day {
+		return nil
+	}
+	if b.day != day {
+		*b = timelineBucket{day: day, artists: make(map[string]*ArtistPlays)}
+	}
+	return b
+}
+
+// lookup returns the bucket of a day without modifying the ring, nil when nothing was recorded that day
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (lt *listeningTimeline) lookup(day int64) *timelineBucket {
+	b := &lt.buckets[int(day%int64(len(lt.buckets)))]
+	if b.day != day {
+		return nil
+	}
+	return b
+}
+
+// record counts one play of song at playedAt; plays older than the retained window are ignored
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (lt *listeningTimeline) record(song *models.Song, playedAt time.Time) {
+	b := lt.bucket(dayNumber(playedAt))
+	if b == nil {
+		return
+	}
+
+	b.plays++
+	b.seconds += song.Duration
+	key := normalizeArtist(song.Artist)
+	if artist, ok := b.artists[key]; ok {
+		artist.Plays++
+	} else {
+		b.artists[key] = &ArtistPlays{Artist: song.Artist, Plays: 1}
+	}
+}
+
+// clear forgets every recorded play
+// Time Complexity: O(d)
+// Space Complexity: O(d)
+func (lt *listeningTimeline) clear() {
+	lt.buckets = make([]timelineBucket, len(lt.buckets))
+}
+
+// export copies the days with plays, oldest first, for persistence
+// Time Complexity: O(d log d + d * a)
+// Space Complexity: O(d * a)
+func (lt *listeningTimeline) export() []TimelineDay {
+	days := make([]TimelineDay, 0)
+	for _, b := range lt.buckets {
+		if b.day != 0 && b.plays > 0 {
+			days = append(days, TimelineDay{
+				Date:             dayDate(b.day),
+				Plays:            b.plays,
+				ListeningSeconds: b.seconds,
+				Artists:          rankArtists(b.artists, 0),
+			})
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
+
+// restore replaces the recorded plays with exported days; days outside the window or with
+// an invalid date are skipped
+// Time Complexity: O(d * a)
+// Space Complexity: O(d * a)
+func (lt *listeningTimeline) restore(days []TimelineDay) {
+	lt.clear()
+	for _, day := range days {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		b := lt.bucket(dayNumber(date))
+		if b == nil {
+			continue
+		}
+		b.plays += day.Plays
+		b.seconds += day.ListeningSeconds
+		for _, artist := range day.Artists {
+			key := normalizeArtist(artist.Artist)
+			if existing, ok := b.artists[key]; ok {
+				existing.Plays += artist.Plays
+			} else {
+				b.artists[key] = &ArtistPlays{Artist: artist.Artist, Plays: artist.Plays}
+			}
+		}
+	}
+}
+
+// rankArtists orders artists by plays, most first and then by name, keeping the top limit (all when limit <= 0)
+// Time Complexity: O(a log a)
+// Space Complexity: O(a)
+func rankArtists(artists map[string]*ArtistPlays, limit int) []ArtistPlays {
+	ranked := make([]ArtistPlays, 0, len(artists))
+	for _, artist := range artists {
+		ranked = append(ranked, *artist)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Plays != ranked[j].Plays {
+			return ranked[i].Plays > ranked[j].Plays
+		}
+		return ranked[i].Artist < ranked[j].Artist
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// changePercent returns the percentage change from previous to current, nil when previous is 0
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func changePercent(previous, current int) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := float64(current-previous) / float64(previous) * 100
+	return &change
+}
+
+// report builds the timeline of the last days days and weeks weeks, including today and this week
+// Weekly entries merge the day buckets of each week and list its top topArtists artists
+// Time Complexity: O(days + weeks * 7 * a log a)
+// Space Complexity: O(days + weeks * a)
+func (lt *listeningTimeline) report(days, weeks, topArtists int) ListeningTimeline {
+	today := dayNumber(lt.now())
+	timeline := ListeningTimeline{
+		PlaysPerDay: make([]TimelineDay, 0, days),
+		Weeks:       make([]TimelineWeek, 0, weeks),
+	}
+
+	for day := today - int64(days) + 1; day <= today; day++ {
+		entry := TimelineDay{Date: dayDate(day)}
+		if b := lt.lookup(day); b != nil {
+			entry.Plays = b.plays
+			entry.ListeningSeconds = b.seconds
+		}
+		timeline.PlaysPerDay = append(timeline.PlaysPerDay, entry)
+		timeline.Trend.CurrentSeconds += entry.ListeningSeconds
+	}
+	for day := today - 2*int64(days) + 1; day <= today-int64(days); day++ {
+		if b := lt.lookup(day); b != nil {
+			timeline.Trend.PreviousSeconds += b.seconds
+		}
+	}
+	timeline.Trend.ChangePercent = changePercent(timeline.Trend.PreviousSeconds, timeline.Trend.CurrentSeconds)
+	switch {
+	case timeline.Trend.CurrentSeconds > timeline.Trend.PreviousSeconds:
+		timeline.Trend.Direction = "up"
+	case timeline.Trend.CurrentSeconds < timeline.Trend.PreviousSeconds:
+		timeline.Trend.Direction = "down"
+	default:
+		timeline.Trend.Direction = "steady"
+	}
+
+	// The Unix epoch was a Thursday, so day 4 was the first Monday
+	monday := today - (today-4)%7
+	previousSeconds := -1
+	for week := int64(weeks); week >= 0; week-- {
+		start := monday - week*7
+		entry := TimelineWeek{WeekStart: dayDate(start)}
+		artists := make(map[string]*ArtistPlays)
+		for day := start; day < start+7 && day <= today; day++ {
+			b := lt.lookup(day)
+			if b == nil {
+				continue
+			}
+			entry.Plays += b.plays
+			entry.ListeningSeconds += b.seconds
+			for key, artist := range b.artists {
+				if existing, ok := artists[key]; ok {
+					existing.Plays += artist.Plays
+				} else {
+					copied := *artist
+					artists[key] = &copied
+				}
+			}
+		}
+
+		// The extra oldest week only seeds the first week's change
+		if week < int64(weeks) {
+			if previousSeconds >= 0 {
+				entry.ChangePercent = changePercent(previousSeconds, entry.ListeningSeconds)
+			}
+			entry.TopArtists = rankArtists(artists, topArtists)
+			timeline.Weeks = append(timeline.Weeks, entry)
+		}
+		previousSeconds = entry.ListeningSeconds
+	}
+
+	return timeline
+}
+
+// GetListeningTimeline reports plays and listening time per day over the last days days, plays,
+// listening time and top artists per week over the last weeks weeks, and the listening trend
+// against the days before; every play is counted, not just those still in the playback history
+// Time Complexity: O(days + weeks * 7 * a log a) where a is the number of artists heard per day
+// Space Complexity: O(days + weeks * a)
+func (pe *PlaylistEngine) GetListeningTimeline(days, weeks, topArtists int) ListeningTimeline {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return pe.timeline.report(days, weeks, topArtists)
+}
+
+// TimelineDays returns how many days of listening statistics are retained
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) TimelineDays() int {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	return len(pe.timeline.buckets)
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"src/internal/models"
+	"testing"
+	"time"
+)
+
+// 2024-01-10 is a Wednesday
+var timelineNow = time.Date(2024, 1, 10, 18, 0, 0, 0, time.UTC)
+
+func newTestTimeline(days int) *listeningTimeline {
+	timeline := newListeningTimeline(days)
+	timeline.now = func() time.Time { return timelineNow }
+	return timeline
+}
+
+func daysAgo(days int) time.Time {
+	return timelineNow.AddDate(0, 0, -days)
+}
+
+func TestListeningTimeline_PlaysPerDayAndTrend(t *testing.T) {
+	timeline := newTestTimeline(30)
+	song := models.NewSong("1", "Song", "Artist", "Album", "Rock", "Alternative", "Chill", 200, 120)
+
+	timeline.record(song, daysAgo(0))
+	timeline.record(song, daysAgo(0))
+	timeline.record(song, daysAgo(2))
+	timeline.record(song, daysAgo(4))   // The previous 3 day period
+	timeline.record(song, daysAgo(400)) // Outside the window, ignored
+
+	report := timeline.report(3, 1, 3)
+	if len(report.PlaysPerDay) != 3 {
+		t.Fatalf("Expected 3 days, got %+v", report.PlaysPerDay)
+	}
+	want := []TimelineDay{
+		{Date: "2024-01-08", Plays: 1, ListeningSeconds: 200},
+		{Date: "2024-01-09"},
+		{Date: "2024-01-10", Plays: 2, ListeningSeconds: 400},
+	}
+	for i, day := range want {
+		got := report.PlaysPerDay[i]
+		if got.Date != day.Date || got.Plays != day.Plays || got.ListeningSeconds != day.ListeningSeconds {
+			t.Errorf("Day %d: expected %+v, got %+v", i, day, got)
+		}
+	}
+
+	trend := report.Trend
+	if trend.CurrentSeconds != 600 || trend.PreviousSeconds != 200 || trend.Direction != "up" || *trend.ChangePercent != 200 {
+		t.Errorf("Expected listening to triple, got %+v", trend)
+	}
+}
+
+func TestListeningTimeline_TopArtistsPerWeek(t *testing.T) {
+	timeline := newTestTimeline(30)
+	first := models.NewSong("1", "One", "First Artist", "Album", "Rock", "Alternative", "Chill", 100, 120)
+	second := models.NewSong("2", "Two", "second artist", "Album", "Rock", "Alternative", "Chill", 300, 120)
+	secondAgain := models.NewSong("3", "Three", "Second Artist", "Album", "Rock", "Alternative", "Chill", 300, 120)
+
+	// This week started on Monday 2024-01-08
+	timeline.record(first, daysAgo(1))
+	timeline.record(second, daysAgo(2))
+	timeline.record(secondAgain, daysAgo(0))
+	// Last week
+	timeline.record(first, daysAgo(5))
+
+	report := timeline.report(7, 2, 1)
+	if len(report.Weeks) != 2 {
+		t.Fatalf("Expected 2 weeks, got %+v", report.Weeks)
+	}
+
+	last, this := report.Weeks[0], report.Weeks[1]
+	if last.WeekStart != "2024-01-01" || last.Plays != 1 || last.ChangePercent != nil {
+		t.Errorf("Unexpected last week %+v", last)
+	}
+	if this.WeekStart != "2024-01-08" || this.Plays != 3 || this.ListeningSeconds != 700 {
+		t.Errorf("Unexpected this week %+v", this)
+	}
+	if len(this.TopArtists) != 1 || this.TopArtists[0].Plays != 2 || this.TopArtists[0].Artist != "second artist" {
+		t.Errorf("Expected the second artist on top with 2 plays, got %+v", this.TopArtists)
+	}
+	if this.ChangePercent == nil || *this.ChangePercent != 600 {
+		t.Errorf("Expected a 600%% rise in listening time, got %v", this.ChangePercent)
+	}
+}
+
+func TestListeningTimeline_RingReusesOldDays(t *testing.T) {
+	timeline := newTestTimeline(7)
+	song := models.NewSong("1", "Song", "Artist", "Album", "Rock", "Alternative", "Chill", 60, 120)
+
+	timeline.record(song, daysAgo(7))
+	timeline.record(song, daysAgo(0)) // Same bucket, a week later
+	timeline.record(song, daysAgo(7)) // Now out of the window
+
+	days := timeline.export()
+	if len(days) != 1 || days[0].Date != "2024-01-10" || days[0].Plays != 1 {
+		t.Errorf("Expected only today's play to be kept, got %+v", days)
+	}
+
+	restored := newTestTimeline(7)
+	restored.restore(days)
+	if report := restored.report(1, 1, 1); report.PlaysPerDay[0].Plays != 1 || report.Weeks[0].TopArtists[0].Artist != "Artist" {
+		t.Errorf("Expected the restored timeline to match, got %+v", report)
+	}
+}
+
+func TestGetListeningTimeline_RecordsPlaysAndSurvivesRestore(t *testing.T) {
+	engine := newPlayerTestEngine(2)
+	engine.PlaySong(0)
+	engine.PlaySong(1)
+	engine.RecordPlays([]PlayRecord{{SongID: engine.GetCurrentPlaylist()[0].ID, PlayedAt: time.Now().AddDate(0, 0, -1)}})
+
+	report := engine.GetListeningTimeline(2, 1, 5)
+	if report.PlaysPerDay[0].Plays != 1 || report.PlaysPerDay[1].Plays != 2 {
+		t.Errorf("Expected 1 play yesterday and 2 today, got %+v", report.PlaysPerDay)
+	}
+
+	restored := NewPlaylistEngine("Restored")
+	if err := restored.RestoreState(engine.ExportState()); err != nil {
+		t.Fatalf("Expected the state to restore, got %v", err)
+	}
+	if got := restored.GetListeningTimeline(2, 1, 5); got.Trend.CurrentSeconds != report.Trend.CurrentSeconds {
+		t.Errorf("Expected the restored timeline to match, got %+v", got.Trend)
+	}
+
+	// States saved before the timeline existed rebuild it from history
+	state := engine.ExportState()
+	state.Timeline = nil
+	restored.RestoreState(state)
+	if got := restored.GetListeningTimeline(2, 1, 5); got.PlaysPerDay[1].Plays != 2 {
+		t.Errorf("Expected the timeline rebuilt from history, got %+v", got.PlaysPerDay)
+	}
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tracklist renders the playlist as a numbered plain-text list for sharing
+// Each line reads "1. Artist - Title (mm:ss)", followed by a footer with the song count and total duration
+// Time Complexity: O(n)
+// Space Complexity: O(n) for the text
+func (pe *PlaylistEngine) Tracklist() string {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	songs := pe.currentPlaylist.ToSlice()
+	if len(songs) == 0 {
+		return fmt.Sprintf("%s: empty playlist\n", pe.playlistName)
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s\n\n", pe.playlistName)
+
+	total := 0
+	for i, song := range songs {
+		fmt.Fprintf(&builder, "%d. %s - %s (%s)\n", i+1, song.Artist, song.Title, song.DurationString())
+		total += song.Duration
+	}
+
+	noun := "songs"
+	if len(songs) == 1 {
+		noun = "song"
+	}
+	fmt.Fprintf(&builder, "\n%d %s, %s total\n", len(songs), noun, formatTotalDuration(total))
+	return builder.String()
+}
+
+// formatTotalDuration formats seconds as mm:ss, or h:mm:ss once the total reaches an hour
+func formatTotalDuration(seconds int) string {
+	hours := seconds / 3600
+	minutes := seconds % 3600 / 60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", minutes, seconds%60)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestTracklist(t *testing.T) {
+	engine := NewPlaylistEngine("Road Trip")
+
+	if got := engine.Tracklist(); got != "Road Trip: empty playlist\n" {
+		t.Errorf("Unexpected empty tracklist %q", got)
+	}
+
+	engine.AddSong("Bohemian Rhapsody", "Queen", "A Night at the Opera", "Rock", "Classic Rock", "Epic", 355, 72)
+	engine.AddSong("Levitating", "Dua Lipa", "Future Nostalgia", "Pop", "Dance", "Happy", 203, 103)
+
+	expected := "Road Trip\n\n" +
+		"1. Queen - Bohemian Rhapsody (05:55)\n" +
+		"2. Dua Lipa - Levitating (03:23)\n" +
+		"\n2 songs, 09:18 total\n"
+	if got := engine.Tracklist(); got != expected {
+		t.Errorf("Unexpected tracklist:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestFormatTotalDuration(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{0, "00:00"},
+		{65, "01:05"},
+		{3599, "59:59"},
+		{3600, "1:00:00"},
+		{7384, "2:03:04"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTotalDuration(tt.seconds); got != tt.want {
+			t.Errorf("formatTotalDuration(%d) = %s, want %s", tt.seconds, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"src/internal/datastructures"
+	"src/internal/models"
+)
+
+// DefaultTrashSize is the number of deleted songs kept when EngineConfig.TrashSize is unset
+const DefaultTrashSize = 100
+
+// ErrNotInTrash is returned when restoring a song that isn't in the trash
+var ErrNotInTrash = errors.New("song not found in trash")
+
+// newTrash creates an empty trash keyed by song ID holding at most capacity songs
+// Once full, deleting another song permanently discards the least recently deleted one
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func newTrash(capacity int) *datastructures.LRUCache[string, *models.Song] {
+	if capacity <= 0 {
+		capacity = DefaultTrashSize
+	}
+	return datastructures.NewLRUCache[string, *models.Song](capacity, nil)
+}
+
+// moveToTrash keeps a song removed from the playlist so it can be restored later
+// A previous trashed copy with the same ID is replaced so each ID appears once
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) moveToTrash(song *models.Song) {
+	pe.trash.Put(song.ID, song)
+}
+
+// GetTrash returns the deleted songs that can still be restored, most recently deleted first
+// Time Complexity: O(t) where t is the number of trashed songs
+// Space Complexity: O(t)
+func (pe *PlaylistEngine) GetTrash() []*models.Song {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	ids := pe.trash.Keys()
+	songs := make([]*models.Song, 0, len(ids))
+	for _, id := range ids {
+		song, _ := pe.trash.Peek(id)
+		songs = append(songs, song.Clone())
+	}
+	return songs
+}
+
+// RestoreFromTrash puts a deleted song back at the end of the playlist with its stats intact
+// The song stays in the trash if the playlist is full, already has its ID, or the DuplicatePolicy rejects it
+// Time Complexity: O(k) average for the duplicate check, where k is the number of songs sharing the title
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) RestoreFromTrash(songID string) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	song, ok := pe.trash.Peek(songID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotInTrash, songID)
+	}
+
+	if pe.config.MaxSongs > 0 && pe.currentPlaylist.Size() >= pe.config.MaxSongs {
+		return fmt.Errorf("%w: limit is %d songs", ErrPlaylistFull, pe.config.MaxSongs)
+	}
+	if pe.songLookup.Contains(song.ID) {
+		return fmt.Errorf("song already exists in playlist")
+	}
+	if err := pe.checkDuplicate(song.Title, song.Artist, song.Album, ""); err != nil {
+		return err
+	}
+
+	pe.trash.Remove(song.ID)
+	pe.insertSong(song)
+	pe.activity.record(ActivityRestore, song.Title)
+	return nil
+}
+
+// EmptyTrash permanently discards every trashed song and returns how many there were
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (pe *PlaylistEngine) EmptyTrash() int {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	count := pe.trash.Len()
+	pe.trash.Clear()
+
+	if count > 0 {
+		pe.activity.record(ActivityEmptyTrash, "")
+	}
+	return count
+}
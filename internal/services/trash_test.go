@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTrashRestoreRoundTrip(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	songID, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.AddSong("Song 2", "Artist 2", "Album", "Jazz", "Smooth Jazz", "Relaxed", 180, 90)
+
+	engine.PlaySong(0)
+	engine.PlaySong(0)
+	engine.RateSong(songID, 4)
+
+	if _, err := engine.DeleteSong(0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	trash := engine.GetTrash()
+	if len(trash) != 1 || trash[0].ID != songID {
+		t.Fatalf("Expected the deleted song in the trash, got %v", trash)
+	}
+	if _, err := engine.SearchSongByID(songID); err == nil {
+		t.Error("Expected the trashed song to be gone from the lookup")
+	}
+
+	if err := engine.RestoreFromTrash(songID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	song, err := engine.SearchSongByID(songID)
+	if err != nil {
+		t.Fatalf("Expected the restored song to be found, got %v", err)
+	}
+	if song.PlayCount != 2 || song.Rating != 4 {
+		t.Errorf("Expected play count 2 and rating 4, got %d and %d", song.PlayCount, song.Rating)
+	}
+	if last, _ := engine.currentPlaylist.GetSong(1); last.ID != songID {
+		t.Errorf("Expected the restored song at the end, got %s", last.Title)
+	}
+	if rated := engine.GetSongsByRating(4); len(rated) != 1 {
+		t.Errorf("Expected the rating tree to hold the restored song, got %v", rated)
+	}
+	if engine.GetTotalPlayCount() != 2 || engine.GetTotalDuration() != 380 {
+		t.Errorf("Expected aggregates to include the restored song, got %d plays and %d seconds", engine.GetTotalPlayCount(), engine.GetTotalDuration())
+	}
+	if len(engine.GetTrash()) != 0 {
+		t.Error("Expected the trash to be empty after restoring")
+	}
+	if issues := engine.VerifyIntegrity(); len(issues) != 0 {
+		t.Errorf("Expected consistent indexes after restoring, got %v", issues)
+	}
+}
+
+func TestRestoreFromTrashErrors(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	songID, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	if err := engine.RestoreFromTrash("missing"); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("Expected ErrNotInTrash, got %v", err)
+	}
+
+	// A re-added copy blocks the restore and the trashed song is kept
+	engine.DeleteSongByID(songID)
+	engine.AddSong("song 1", "artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	if err := engine.RestoreFromTrash(songID); err == nil || errors.Is(err, ErrNotInTrash) {
+		t.Errorf("Expected a duplicate error, got %v", err)
+	}
+	if len(engine.GetTrash()) != 1 {
+		t.Error("Expected the song to stay in the trash after a failed restore")
+	}
+}
+
+func TestEmptyTrash(t *testing.T) {
+	engine := NewPlaylistEngine("Test")
+	first, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	second, _ := engine.AddSong("Song 2", "Artist 2", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	third, _ := engine.AddSong("Song 3", "Artist 3", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	engine.DeleteSongByID(first)
+	engine.DeleteSongs([]string{second, third})
+
+	trash := engine.GetTrash()
+	if len(trash) != 3 || trash[0].ID != third || trash[2].ID != first {
+		t.Fatalf("Expected 3 songs newest first, got %v", trash)
+	}
+
+	if removed := engine.EmptyTrash(); removed != 3 {
+		t.Errorf("Expected 3 songs removed, got %d", removed)
+	}
+	if err := engine.RestoreFromTrash(first); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("Expected ErrNotInTrash after emptying, got %v", err)
+	}
+	if removed := engine.EmptyTrash(); removed != 0 {
+		t.Errorf("Expected an empty trash, got %d", removed)
+	}
+}
+
+func TestTrashIsBounded(t *testing.T) {
+	config := DefaultEngineConfig()
+	config.TrashSize = 2
+	engine := NewPlaylistEngineWithConfig("Test", config)
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, _ := engine.AddSong(fmt.Sprintf("Song %d", i), "Artist", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+		ids = append(ids, id)
+	}
+
+	engine.DeleteSongs(ids)
+	trash := engine.GetTrash()
+	if len(trash) != 2 || trash[0].ID != ids[2] || trash[1].ID != ids[1] {
+		t.Fatalf("Expected the 2 most recently deleted songs, got %v", trash)
+	}
+	if err := engine.RestoreFromTrash(ids[0]); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("Expected the oldest deleted song to be discarded, got %v", err)
+	}
+}
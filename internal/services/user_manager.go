@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultUserID identifies the user whose playlists serve requests that don't name a user
+const DefaultUserID = "default"
+
+// defaultPlaylistName is the name of the playlist every new user starts with
+const defaultPlaylistName = "My Playlist"
+
+// ErrUserNotFound is returned when no user has the requested ID
+var ErrUserNotFound = errors.New("user not found")
+
+// UserSummary describes one user for listings
+type UserSummary struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Playlists int       `json:"playlists"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// user is one account with its own playlists, and with them its own ratings, history and recommendations
+type user struct {
+	name      string
+	createdAt time.Time
+	playlists *PlaylistManager
+}
+
+// UserManager keeps a separate PlaylistManager for every user so nothing is shared between them
+// The default user always exists and owns the playlists of requests that don't name a user
+// User IDs are slugs of the name at creation time
+// Time Complexity: O(1) average for lookups, O(u) for listing u users
+// Space Complexity: O(u + n) where n is the total number of songs
+type UserManager struct {
+	mu     sync.RWMutex
+	config EngineConfig
+	users  map[string]*user
+	order  []string // IDs in creation order
+}
+
+// NewUserManager creates a manager whose default user owns defaultPlaylists
+// Every user created later starts with one empty playlist named defaultPlaylistName, built with config
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewUserManager(defaultPlaylists *PlaylistManager, config EngineConfig) *UserManager {
+	return &UserManager{
+		config: config,
+		users: map[string]*user{
+			DefaultUserID: {name: "Default", createdAt: time.Now(), playlists: defaultPlaylists},
+		},
+		order: []string{DefaultUserID},
+	}
+}
+
+// Create adds a user with one empty playlist and returns the user's ID
+// The ID is the slugified name, numbered when another user already uses it
+// Time Complexity: O(k) where k is the name length, plus O(c) for c users sharing the slug
+// Space Complexity: O(1)
+func (um *UserManager) Create(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("user name cannot be empty")
+	}
+
+	um.mu.Lock()
+	defer um.mu.Unlock()
+
+	id := uniqueSlug(name, "user", func(id string) bool { return um.users[id] != nil })
+	um.users[id] = &user{
+		name:      name,
+		createdAt: time.Now(),
+		playlists: NewPlaylistManager(defaultPlaylistName, um.config),
+	}
+	um.order = append(um.order, id)
+	return id, nil
+}
+
+// Playlists returns the playlists of the user with the given ID
+// Time Complexity: O(1) average
+// Space Complexity: O(1)
+func (um *UserManager) Playlists(userID string) (*PlaylistManager, error) {
+	um.mu.RLock()
+	defer um.mu.RUnlock()
+
+	u, ok := um.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+	}
+	return u.playlists, nil
+}
+
+// List summarizes every user in creation order, the default user first
+// Time Complexity: O(u) where u is the number of users
+// Space Complexity: O(u)
+func (um *UserManager) List() []UserSummary {
+	um.mu.RLock()
+	defer um.mu.RUnlock()
+
+	summaries := make([]UserSummary, 0, len(um.order))
+	for _, id := range um.order {
+		u := um.users[id]
+		summaries = append(summaries, UserSummary{
+			ID:        id,
+			Name:      u.name,
+			Playlists: len(u.playlists.List()),
+			CreatedAt: u.createdAt,
+		})
+	}
+	return summaries
+}
+
+// MigratePlaylist moves a playlist with its songs, ratings and history from one user to another
+// Returns the playlist's ID under the new owner, which differs when that user already has one by
+// the same name; the last playlist of a user can't be moved away
+// Time Complexity: O(p) where p is the number of playlists of either user
+// Space Complexity: O(1)
+func (um *UserManager) MigratePlaylist(fromUserID, playlistID, toUserID string) (string, error) {
+	from, err := um.Playlists(fromUserID)
+	if err != nil {
+		return "", err
+	}
+	to, err := um.Playlists(toUserID)
+	if err != nil {
+		return "", err
+	}
+	if fromUserID == toUserID {
+		return "", fmt.Errorf("playlist already belongs to user '%s'", toUserID)
+	}
+
+	engine, err := from.Detach(playlistID)
+	if err != nil {
+		return "", err
+	}
+	return to.Attach(engine), nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUserManager(t *testing.T) {
+	shared := NewPlaylistManager("My Playlist", DefaultEngineConfig())
+	users := NewUserManager(shared, DefaultEngineConfig())
+
+	if playlists, err := users.Playlists(DefaultUserID); err != nil || playlists != shared {
+		t.Fatalf("Expected the default user to own the shared playlists, got %v", err)
+	}
+
+	alice, err := users.Create("Alice")
+	if err != nil || alice != "alice" {
+		t.Fatalf("Expected user alice, got %s, %v", alice, err)
+	}
+	if again, _ := users.Create("Default"); again != "default-2" {
+		t.Errorf("Expected the reserved ID to be numbered, got %s", again)
+	}
+	if _, err := users.Create("  "); err == nil {
+		t.Error("Expected an error for an empty name")
+	}
+	if _, err := users.Playlists("missing"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+
+	// Each user rates and plays in their own engine
+	playlists, _ := users.Playlists(alice)
+	songID, _ := playlists.Active().AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	playlists.Active().RateSong(songID, 5)
+	if shared.Active().GetPlaylistSize() != 0 {
+		t.Error("Expected the default user's playlist to be unaffected")
+	}
+
+	summaries := users.List()
+	if len(summaries) != 3 || summaries[0].ID != DefaultUserID || summaries[1].ID != alice || summaries[1].Playlists != 1 {
+		t.Errorf("Unexpected summaries %+v", summaries)
+	}
+}
+
+func TestUserManager_MigratePlaylist(t *testing.T) {
+	shared := NewPlaylistManager("My Playlist", DefaultEngineConfig())
+	users := NewUserManager(shared, DefaultEngineConfig())
+	alice, _ := users.Create("Alice")
+
+	roadTrip, _ := shared.Create("Road Trip")
+	engine, _ := shared.Get(roadTrip)
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	newID, err := users.MigratePlaylist(DefaultUserID, roadTrip, alice)
+	if err != nil || newID != roadTrip {
+		t.Fatalf("Expected road-trip under alice, got %s, %v", newID, err)
+	}
+	if _, err := shared.Get(roadTrip); !errors.Is(err, ErrPlaylistNotFound) {
+		t.Errorf("Expected the playlist to leave the default user, got %v", err)
+	}
+	playlists, _ := users.Playlists(alice)
+	if moved, _ := playlists.Get(newID); moved != engine || moved.GetPlaylistSize() != 1 {
+		t.Error("Expected alice to own the same engine with its songs")
+	}
+
+	// A user's last playlist can't be moved away
+	if _, err := users.MigratePlaylist(DefaultUserID, shared.ActiveID(), alice); !errors.Is(err, ErrLastPlaylist) {
+		t.Errorf("Expected ErrLastPlaylist, got %v", err)
+	}
+	if _, err := users.MigratePlaylist(alice, newID, "missing"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := users.MigratePlaylist(alice, newID, alice); err == nil {
+		t.Error("Expected an error migrating a playlist to its owner")
+	}
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"log"
+	"src/internal/services"
+	"sync"
+)
+
+// AutoSaver saves playlists in the background after they change
+// Notifications arriving while a save is running are coalesced, so a burst of
+// mutations costs at most one extra save per playlist
+// Time Complexity: O(1) per notification, O(n) per save
+// Space Complexity: O(p) where p is the number of playlists with pending saves
+type AutoSaver struct {
+	store  *FileStore
+	lookup func(playlistID string) (*services.PlaylistEngine, error)
+
+	mu      sync.Mutex
+	pending map[string]bool
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewAutoSaver starts a background saver writing to store
+// lookup resolves playlist IDs at save time, so playlists deleted in the meantime are skipped
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewAutoSaver(store *FileStore, lookup func(playlistID string) (*services.PlaylistEngine, error)) *AutoSaver {
+	as := &AutoSaver{
+		store:   store,
+		lookup:  lookup,
+		pending: make(map[string]bool),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go as.run()
+	return as
+}
+
+// Watch saves the engine under playlistID whenever it records a change
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (as *AutoSaver) Watch(playlistID string, engine *services.PlaylistEngine) {
+	engine.SetChangeHook(func() { as.Notify(playlistID) })
+}
+
+// Notify schedules a save of the playlist without blocking the caller
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (as *AutoSaver) Notify(playlistID string) {
+	as.mu.Lock()
+	as.pending[playlistID] = true
+	as.mu.Unlock()
+
+	select {
+	case as.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Stop saves any pending playlists and stops the background goroutine
+// Time Complexity: O(p * n) for the final saves
+// Space Complexity: O(n)
+func (as *AutoSaver) Stop() {
+	close(as.done)
+	<-as.stopped
+}
+
+// run saves pending playlists each time it is woken until Stop is called
+func (as *AutoSaver) run() {
+	defer close(as.stopped)
+	for {
+		select {
+		case <-as.wake:
+			as.flush()
+		case <-as.done:
+			as.flush()
+			return
+		}
+	}
+}
+
+// flush saves every pending playlist, logging failures since there is no caller to report them to
+func (as *AutoSaver) flush() {
+	as.mu.Lock()
+	pending := as.pending
+	as.pending = make(map[string]bool)
+	as.mu.Unlock()
+
+	for playlistID := range pending {
+		engine, err := as.lookup(playlistID)
+		if err != nil {
+			continue
+		}
+		if err := as.store.Save(playlistID, engine); err != nil {
+			log.Printf("auto-save of playlist %s failed: %v", playlistID, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"src/internal/services"
+)
+
+func TestAutoSaver(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	manager := services.NewPlaylistManager("My Playlist", services.DefaultEngineConfig())
+	saver := NewAutoSaver(store, manager.Get)
+
+	engine := manager.Active()
+	for i := 0; i < 20; i++ {
+		engine.AddSong("Song", "Artist "+string(rune('A'+i)), "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	}
+	saver.Watch(manager.ActiveID(), engine)
+	engine.SetPlaylistName("Saved")
+
+	// A deleted playlist is skipped instead of failing the flush
+	saver.Notify("deleted")
+	saver.Stop()
+
+	loaded := services.NewPlaylistEngine("Empty")
+	if err := store.Load(manager.ActiveID(), loaded); err != nil {
+		t.Fatalf("Expected the playlist to be saved, got %v", err)
+	}
+	if loaded.GetPlaylistName() != "Saved" || loaded.GetPlaylistSize() != 20 {
+		t.Errorf("Expected the renamed playlist with 20 songs, got %s with %d", loaded.GetPlaylistName(), loaded.GetPlaylistSize())
+	}
+	if err := store.Load("deleted", loaded); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("Expected no snapshot for an unknown playlist, got %v", err)
+	}
+}
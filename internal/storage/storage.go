@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"src/internal/services"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the file format version written by Save
+const SnapshotVersion = 1
+
+// ErrNoSnapshot is returned when loading a playlist that has never been saved
+var ErrNoSnapshot = errors.New("no saved snapshot")
+
+// snapshotFile is the JSON document written for each playlist
+type snapshotFile struct {
+	Version int                  `json:"version"`
+	SavedAt time.Time            `json:"saved_at"`
+	State   services.EngineState `json:"state"`
+}
+
+// FileStore keeps one JSON snapshot per playlist in a directory
+// Files are named after the playlist ID, which is already a filesystem-safe slug
+// Time Complexity: O(n) per save or load where n is the number of songs
+// Space Complexity: O(n) for the encoded snapshot
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store writing snapshots to dir, which is created on the first save
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// path returns the snapshot file of a playlist
+func (fs *FileStore) path(playlistID string) string {
+	return filepath.Join(fs.dir, playlistID+".json")
+}
+
+// Save writes the engine's state to the playlist's snapshot file
+// The snapshot is written to a temporary file and renamed over the old one,
+// so a crash mid-save never leaves a truncated snapshot behind
+// Time Complexity: O(n + h) where h is the history size
+// Space Complexity: O(n + h)
+func (fs *FileStore) Save(playlistID string, engine *services.PlaylistEngine) error {
+	data, err := json.MarshalIndent(snapshotFile{
+		Version: SnapshotVersion,
+		SavedAt: time.Now(),
+		State:   engine.ExportState(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(fs.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(fs.dir, playlistID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), fs.path(playlistID)); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	return nil
+}
+
+// List returns the IDs of every saved playlist in sorted order; a missing directory holds none
+// Time Complexity: O(f log f) where f is the number of files in the directory
+// Space Complexity: O(f)
+func (fs *FileStore) List() ([]string, error) {
+	entries, err := os.ReadDir(fs.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data directory: %w", err)
+	}
+
+	ids := []string{}
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasSuffix(name, ".json") {
+			ids = append(ids, strings.TrimSuffix(name, ".json"))
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete removes the playlist's snapshot file; a playlist that was never saved is not an error
+// Time Complexity: O(1)
+// Space Complexity: O(1)
+func (fs *FileStore) Delete(playlistID string) error {
+	if err := os.Remove(fs.path(playlistID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load replaces the engine's state with the playlist's saved snapshot
+// Returns ErrNoSnapshot when the playlist has no snapshot file; the engine is unchanged on any error
+// Time Complexity: O(n + h log h) where h is the history size
+// Space Complexity: O(n + h)
+func (fs *FileStore) Load(playlistID string, engine *services.PlaylistEngine) error {
+	data, err := os.ReadFile(fs.path(playlistID))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w for playlist '%s'", ErrNoSnapshot, playlistID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot snapshotFile
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if snapshot.Version != SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+
+	return engine.RestoreState(snapshot.State)
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"src/internal/services"
+)
+
+func TestFileStoreSaveLoad(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "data"))
+
+	engine := services.NewPlaylistEngine("Road Trip")
+	songID, _ := engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+	engine.RateSong(songID, 5)
+	engine.PlaySong(0)
+
+	if err := store.Save("road-trip", engine); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	loaded := services.NewPlaylistEngine("Empty")
+	if err := store.Load("road-trip", loaded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.GetPlaylistName() != "Road Trip" || loaded.GetPlaylistSize() != 1 {
+		t.Errorf("Expected the saved playlist, got %s with %d songs", loaded.GetPlaylistName(), loaded.GetPlaylistSize())
+	}
+	song, err := loaded.SearchSongByID(songID)
+	if err != nil || song.Rating != 5 || song.PlayCount != 1 {
+		t.Errorf("Expected rating and play count to survive, got %+v %v", song, err)
+	}
+	if recent := loaded.GetRecentlyPlayedSongs(10); len(recent) != 1 {
+		t.Errorf("Expected 1 history entry, got %d", len(recent))
+	}
+
+	// Saving again replaces the snapshot without leaving temporary files behind
+	engine.AddSong("Song 2", "Artist 2", "Album", "Pop", "Dance", "Happy", 180, 110)
+	if err := store.Save("road-trip", engine); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	entries, _ := os.ReadDir(store.dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected a single snapshot file, got %d entries", len(entries))
+	}
+}
+
+func TestFileStoreListDelete(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "data"))
+	if ids, err := store.List(); err != nil || len(ids) != 0 {
+		t.Errorf("Expected no playlists before the first save, got %v, %v", ids, err)
+	}
+
+	engine := services.NewPlaylistEngine("Test")
+	for _, id := range []string{"road-trip", "my-playlist"} {
+		if err := store.Save(id, engine); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	os.WriteFile(filepath.Join(store.dir, "notes.txt"), []byte("not a snapshot"), 0o644)
+
+	if ids, err := store.List(); err != nil || len(ids) != 2 || ids[0] != "my-playlist" || ids[1] != "road-trip" {
+		t.Errorf("Expected both saved playlists in order, got %v, %v", ids, err)
+	}
+
+	if err := store.Delete("road-trip"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.Delete("road-trip"); err != nil {
+		t.Errorf("Expected deleting a missing snapshot to succeed, got %v", err)
+	}
+	if ids, _ := store.List(); len(ids) != 1 || ids[0] != "my-playlist" {
+		t.Errorf("Expected only my-playlist left, got %v", ids)
+	}
+}
+
+func TestFileStoreLoadErrors(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	engine := services.NewPlaylistEngine("Test")
+	engine.AddSong("Song 1", "Artist 1", "Album", "Rock", "Alternative", "Energetic", 200, 120)
+
+	if err := store.Load("missing", engine); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("Expected ErrNoSnapshot, got %v", err)
+	}
+
+	os.WriteFile(store.path("corrupt"), []byte("{not json"), 0o644)
+	if err := store.Load("corrupt", engine); err == nil {
+		t.Error("Expected an error for a corrupt snapshot")
+	}
+
+	os.WriteFile(store.path("future"), []byte(`{"version": 99, "state": {"songs": []}}`), 0o644)
+	if err := store.Load("future", engine); err == nil {
+		t.Error("Expected an error for an unsupported version")
+	}
+
+	if engine.GetPlaylistSize() != 1 {
+		t.Errorf("Expected the engine unchanged after failed loads, got %d songs", engine.GetPlaylistSize())
+	}
+}